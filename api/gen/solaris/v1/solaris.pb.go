@@ -9,6 +9,8 @@ package solaris
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -21,6 +23,113 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// AppendMode defines how AppendRecords handles the batch, which could be written only partially
+type AppendMode int32
+
+const (
+	// APPEND_MODE_DEFAULT means the mode is chosen by the server settings
+	AppendMode_APPEND_MODE_DEFAULT AppendMode = 0
+	// APPEND_MODE_PARTIAL means the records written before the error are kept, the result is marked as partial
+	AppendMode_APPEND_MODE_PARTIAL AppendMode = 1
+	// APPEND_MODE_ATOMIC means either all the records are written or none of them, the written
+	// records are rolled back and the error is returned if the whole batch could not be written
+	AppendMode_APPEND_MODE_ATOMIC AppendMode = 2
+)
+
+// Enum value maps for AppendMode.
+var (
+	AppendMode_name = map[int32]string{
+		0: "APPEND_MODE_DEFAULT",
+		1: "APPEND_MODE_PARTIAL",
+		2: "APPEND_MODE_ATOMIC",
+	}
+	AppendMode_value = map[string]int32{
+		"APPEND_MODE_DEFAULT": 0,
+		"APPEND_MODE_PARTIAL": 1,
+		"APPEND_MODE_ATOMIC":  2,
+	}
+)
+
+func (x AppendMode) Enum() *AppendMode {
+	p := new(AppendMode)
+	*p = x
+	return p
+}
+
+func (x AppendMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AppendMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_solaris_proto_enumTypes[0].Descriptor()
+}
+
+func (AppendMode) Type() protoreflect.EnumType {
+	return &file_solaris_proto_enumTypes[0]
+}
+
+func (x AppendMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AppendMode.Descriptor instead.
+func (AppendMode) EnumDescriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{0}
+}
+
+// RejectReason describes why the request records were not added to the log
+type RejectReason int32
+
+const (
+	// REJECT_REASON_ERROR means the records were not added because of an error, see AppendRecordsResult.partialError
+	RejectReason_REJECT_REASON_ERROR RejectReason = 0
+	// REJECT_REASON_EXHAUSTED means the log reached its quota, for example, the maximum number of its chunks
+	RejectReason_REJECT_REASON_EXHAUSTED RejectReason = 1
+	// REJECT_REASON_TOO_LARGE means the first rejected record is too large to be stored
+	RejectReason_REJECT_REASON_TOO_LARGE RejectReason = 2
+)
+
+// Enum value maps for RejectReason.
+var (
+	RejectReason_name = map[int32]string{
+		0: "REJECT_REASON_ERROR",
+		1: "REJECT_REASON_EXHAUSTED",
+		2: "REJECT_REASON_TOO_LARGE",
+	}
+	RejectReason_value = map[string]int32{
+		"REJECT_REASON_ERROR":     0,
+		"REJECT_REASON_EXHAUSTED": 1,
+		"REJECT_REASON_TOO_LARGE": 2,
+	}
+)
+
+func (x RejectReason) Enum() *RejectReason {
+	p := new(RejectReason)
+	*p = x
+	return p
+}
+
+func (x RejectReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RejectReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_solaris_proto_enumTypes[1].Descriptor()
+}
+
+func (RejectReason) Type() protoreflect.EnumType {
+	return &file_solaris_proto_enumTypes[1]
+}
+
+func (x RejectReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RejectReason.Descriptor instead.
+func (RejectReason) EnumDescriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{1}
+}
+
 // Record represents one record of a log
 type Record struct {
 	state         protoimpl.MessageState
@@ -36,6 +145,19 @@ type Record struct {
 	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
 	// payload is the record data
 	Payload []byte `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	// ageMs is the record age (the time passed since the record was added) in milliseconds at the query time.
+	// It is filled only if the QueryRecordsRequest.withAge is true
+	AgeMs int64 `protobuf:"varint,5,opt,name=ageMs,proto3" json:"ageMs,omitempty"`
+	// seq is the record sequence number in the log. The log records are numbered 1, 2, 3... without gaps
+	// in the order they were appended. Zero value means the record is not numbered (the sequences are disabled).
+	Seq int64 `protobuf:"varint,6,opt,name=seq,proto3" json:"seq,omitempty"`
+	// any contains the record payload packed with the log payloadTypeURL (see Log.payloadTypeURL). It is filled
+	// instead of the payload only if the QueryRecordsRequest.asAny is true and the record log has the payloadTypeURL.
+	Any *anypb.Any `protobuf:"bytes,7,opt,name=any,proto3" json:"any,omitempty"`
+	// attributes is a map of the small key/value metadata of the record (e.g. severity, source). The attributes are
+	// stored with the record as is, they are not encrypted or compressed, and the records may be selected by them
+	// in the QueryRecordsRequest.condition, e.g. 'attr("severity") = "error"'
+	Attributes map[string]string `protobuf:"bytes,8,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
 func (x *Record) Reset() {
@@ -98,6 +220,34 @@ func (x *Record) GetPayload() []byte {
 	return nil
 }
 
+func (x *Record) GetAgeMs() int64 {
+	if x != nil {
+		return x.AgeMs
+	}
+	return 0
+}
+
+func (x *Record) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Record) GetAny() *anypb.Any {
+	if x != nil {
+		return x.Any
+	}
+	return nil
+}
+
+func (x *Record) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
 // Log describes a log in the database. Logs are distinguished by their IDs only
 type Log struct {
 	state         protoimpl.MessageState
@@ -112,6 +262,36 @@ type Log struct {
 	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
 	// updatedAt is the timestamp when the log was updated (new records added or tags are applied)
 	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+	// validateUTF8 specifies that the log records payloads must be valid UTF-8 text. If it is true,
+	// AppendRecords rejects the records with the payloads that are not valid UTF-8.
+	ValidateUTF8 bool `protobuf:"varint,5,opt,name=validateUTF8,proto3" json:"validateUTF8,omitempty"`
+	// payloadTypeURL is the type URL of the protobuf messages the log records payloads contain, for example
+	// "type.googleapis.com/google.protobuf.Timestamp". If it is not empty, the log records may be queried
+	// as google.protobuf.Any values (see QueryRecordsRequest.asAny), so the clients could unmarshal them by type.
+	PayloadTypeURL string `protobuf:"bytes,6,opt,name=payloadTypeURL,proto3" json:"payloadTypeURL,omitempty"`
+	// maxRecords defines the maximum number of records the log keeps. If it is positive, the oldest log records
+	// are removed by the appends, so only the last maxRecords records are kept (the log is a ring buffer). The
+	// appends remove the records by whole chunks mostly, so the log may keep a few more records (see the server
+	// MaxRecordsSlackPct setting), which are removed by the background retention sweeper then.
+	MaxRecords int64 `protobuf:"varint,7,opt,name=maxRecords,proto3" json:"maxRecords,omitempty"`
+	// payloadHash is the hash function the log records payloads are indexed by, "sha256" or "fnv64a". If it is
+	// not empty, the records appended to the log are indexed by their payloads hashes, so the records with a
+	// payload could be found by its hash (see GetRecordByHash). Empty value means the index is not maintained.
+	PayloadHash string `protobuf:"bytes,8,opt,name=payloadHash,proto3" json:"payloadHash,omitempty"`
+	// retentionMaxAge defines how long the log records are kept. The chunks with the records older than
+	// retentionMaxAge only are removed by the background retention sweeper. Zero value means keep forever.
+	RetentionMaxAge *durationpb.Duration `protobuf:"bytes,9,opt,name=retentionMaxAge,proto3" json:"retentionMaxAge,omitempty"`
+	// retentionMaxRecords defines the maximum number of records the background retention sweeper leaves
+	// in the log, the oldest records above the limit are removed. Zero value means keep forever. If both
+	// maxRecords and retentionMaxRecords are positive, the lesser one wins: the sweeper trims the log to it.
+	RetentionMaxRecords int64 `protobuf:"varint,10,opt,name=retentionMaxRecords,proto3" json:"retentionMaxRecords,omitempty"`
+	// owner is the ID of the client the log belongs to. When the requests authentication is enabled, the owner
+	// of the new log is the client created it, and the owner is never changed after that. The owner and the clients
+	// listed in the grants may read and append the log records, but only the owner may update or delete the log.
+	// The logs without the owner are available to all the clients.
+	Owner string `protobuf:"bytes,11,opt,name=owner,proto3" json:"owner,omitempty"`
+	// grants contains the IDs of the clients, which may read and append the log records besides the owner
+	Grants []string `protobuf:"bytes,12,rep,name=grants,proto3" json:"grants,omitempty"`
 }
 
 func (x *Log) Reset() {
@@ -174,22 +354,76 @@ func (x *Log) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
-// AppendRecordsRequest describes the parameters for AppendRecords() call
-type AppendRecordsRequest struct {
+func (x *Log) GetValidateUTF8() bool {
+	if x != nil {
+		return x.ValidateUTF8
+	}
+	return false
+}
+
+func (x *Log) GetPayloadTypeURL() string {
+	if x != nil {
+		return x.PayloadTypeURL
+	}
+	return ""
+}
+
+func (x *Log) GetMaxRecords() int64 {
+	if x != nil {
+		return x.MaxRecords
+	}
+	return 0
+}
+
+func (x *Log) GetPayloadHash() string {
+	if x != nil {
+		return x.PayloadHash
+	}
+	return ""
+}
+
+func (x *Log) GetRetentionMaxAge() *durationpb.Duration {
+	if x != nil {
+		return x.RetentionMaxAge
+	}
+	return nil
+}
+
+func (x *Log) GetRetentionMaxRecords() int64 {
+	if x != nil {
+		return x.RetentionMaxRecords
+	}
+	return 0
+}
+
+func (x *Log) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Log) GetGrants() []string {
+	if x != nil {
+		return x.Grants
+	}
+	return nil
+}
+
+// CreateLogsRequest describes the request for CreateLogs
+type CreateLogsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// logID is where the new records will be added
-	LogID string `protobuf:"bytes,1,opt,name=logID,proto3" json:"logID,omitempty"`
-	// records the list of records to be added
-	Records []*Record `protobuf:"bytes,2,rep,name=records,proto3" json:"records,omitempty"`
-	// expandIDs if true - response will contain list of inserted message IDs
-	ExpandIDs bool `protobuf:"varint,3,opt,name=expandIDs,proto3" json:"expandIDs,omitempty"`
+	// logs contains the logs to be created. Unlike CreateLog, the log IDs specified are kept,
+	// the empty ones are generated. The request fails with ALREADY_EXISTS and nothing is created
+	// if a log with the same ID exists already or the ID is repeated in the list.
+	Logs []*Log `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
 }
 
-func (x *AppendRecordsRequest) Reset() {
-	*x = AppendRecordsRequest{}
+func (x *CreateLogsRequest) Reset() {
+	*x = CreateLogsRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -197,13 +431,13 @@ func (x *AppendRecordsRequest) Reset() {
 	}
 }
 
-func (x *AppendRecordsRequest) String() string {
+func (x *CreateLogsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AppendRecordsRequest) ProtoMessage() {}
+func (*CreateLogsRequest) ProtoMessage() {}
 
-func (x *AppendRecordsRequest) ProtoReflect() protoreflect.Message {
+func (x *CreateLogsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -215,46 +449,30 @@ func (x *AppendRecordsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AppendRecordsRequest.ProtoReflect.Descriptor instead.
-func (*AppendRecordsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CreateLogsRequest.ProtoReflect.Descriptor instead.
+func (*CreateLogsRequest) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *AppendRecordsRequest) GetLogID() string {
-	if x != nil {
-		return x.LogID
-	}
-	return ""
-}
-
-func (x *AppendRecordsRequest) GetRecords() []*Record {
+func (x *CreateLogsRequest) GetLogs() []*Log {
 	if x != nil {
-		return x.Records
+		return x.Logs
 	}
 	return nil
 }
 
-func (x *AppendRecordsRequest) GetExpandIDs() bool {
-	if x != nil {
-		return x.ExpandIDs
-	}
-	return false
-}
-
-// AppendRecordsResult contains the number or records added to the log
-type AppendRecordsResult struct {
+// CreateLogsResult describes the result of CreateLogs
+type CreateLogsResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// added is number of records added to database. If this value is less than passed ID list
-	Added int64 `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
-	// list of inserted ids. Returned only if expandIDs of request set to true
-	RecordIDs []string `protobuf:"bytes,2,rep,name=recordIDs,proto3" json:"recordIDs,omitempty"`
+	// logs contains the created logs in the order of the request
+	Logs []*Log `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
 }
 
-func (x *AppendRecordsResult) Reset() {
-	*x = AppendRecordsResult{}
+func (x *CreateLogsResult) Reset() {
+	*x = CreateLogsResult{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -262,13 +480,13 @@ func (x *AppendRecordsResult) Reset() {
 	}
 }
 
-func (x *AppendRecordsResult) String() string {
+func (x *CreateLogsResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AppendRecordsResult) ProtoMessage() {}
+func (*CreateLogsResult) ProtoMessage() {}
 
-func (x *AppendRecordsResult) ProtoReflect() protoreflect.Message {
+func (x *CreateLogsResult) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -280,41 +498,30 @@ func (x *AppendRecordsResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AppendRecordsResult.ProtoReflect.Descriptor instead.
-func (*AppendRecordsResult) Descriptor() ([]byte, []int) {
+// Deprecated: Use CreateLogsResult.ProtoReflect.Descriptor instead.
+func (*CreateLogsResult) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *AppendRecordsResult) GetAdded() int64 {
-	if x != nil {
-		return x.Added
-	}
-	return 0
-}
-
-func (x *AppendRecordsResult) GetRecordIDs() []string {
+func (x *CreateLogsResult) GetLogs() []*Log {
 	if x != nil {
-		return x.RecordIDs
+		return x.Logs
 	}
 	return nil
 }
 
-// QueryLogsRequest allows to read multiple Log objects per one request
-type QueryLogsRequest struct {
+// GetLogRequest describes the request for GetLog
+type GetLogRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// condition describes the log filter condition
-	Condition string `protobuf:"bytes,1,opt,name=condition,proto3" json:"condition,omitempty"`
-	// pageID is provided for paginated results
-	PageID string `protobuf:"bytes,2,opt,name=pageID,proto3" json:"pageID,omitempty"`
-	// limit contains tha maximum number of Log objects in the result
-	Limit int64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// ID is the log identifier
+	ID string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
 }
 
-func (x *QueryLogsRequest) Reset() {
-	*x = QueryLogsRequest{}
+func (x *GetLogRequest) Reset() {
+	*x = GetLogRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -322,13 +529,13 @@ func (x *QueryLogsRequest) Reset() {
 	}
 }
 
-func (x *QueryLogsRequest) String() string {
+func (x *GetLogRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueryLogsRequest) ProtoMessage() {}
+func (*GetLogRequest) ProtoMessage() {}
 
-func (x *QueryLogsRequest) ProtoReflect() protoreflect.Message {
+func (x *GetLogRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -340,48 +547,47 @@ func (x *QueryLogsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueryLogsRequest.ProtoReflect.Descriptor instead.
-func (*QueryLogsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetLogRequest.ProtoReflect.Descriptor instead.
+func (*GetLogRequest) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *QueryLogsRequest) GetCondition() string {
-	if x != nil {
-		return x.Condition
-	}
-	return ""
-}
-
-func (x *QueryLogsRequest) GetPageID() string {
+func (x *GetLogRequest) GetID() string {
 	if x != nil {
-		return x.PageID
+		return x.ID
 	}
 	return ""
 }
 
-func (x *QueryLogsRequest) GetLimit() int64 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-// QueryLogsResult describes the response for QueryLogsRequest
-type QueryLogsResult struct {
+// AppendRecordsRequest describes the parameters for AppendRecords() call
+type AppendRecordsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// logs is the list of Log objects in the result
-	Logs []*Log `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
-	// nextPageID contains the pageID for reading next portion of the records if any
-	NextPageID string `protobuf:"bytes,2,opt,name=nextPageID,proto3" json:"nextPageID,omitempty"`
-	// total is the number of records matched to the result
-	Total int64 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	// logID is where the new records will be added
+	LogID string `protobuf:"bytes,1,opt,name=logID,proto3" json:"logID,omitempty"`
+	// records the list of records to be added
+	Records []*Record `protobuf:"bytes,2,rep,name=records,proto3" json:"records,omitempty"`
+	// expandIDs if true - response will contain list of inserted message IDs
+	ExpandIDs bool `protobuf:"varint,3,opt,name=expandIDs,proto3" json:"expandIDs,omitempty"`
+	// idempotencyKey allows to retry the request safely. The server keeps the key of the last append to the log,
+	// so the retry with the same key returns the result of the committed append without writing anything.
+	// The keys must grow from one append to another (ULIDs, for example), the requests with the keys less than
+	// the last committed one are considered as already applied and are ignored. Empty value disables the check.
+	IdempotencyKey string `protobuf:"bytes,4,opt,name=idempotencyKey,proto3" json:"idempotencyKey,omitempty"`
+	// mode defines what happens if only some of the records could be written, see AppendMode
+	Mode AppendMode `protobuf:"varint,5,opt,name=mode,proto3,enum=solaris.v1.AppendMode" json:"mode,omitempty"`
+	// checksumAlgorithm is the hash function the checksum is calculated by ("sha256" or "fnv64a")
+	ChecksumAlgorithm string `protobuf:"bytes,6,opt,name=checksumAlgorithm,proto3" json:"checksumAlgorithm,omitempty"`
+	// checksum is the hex-encoded hash of the records payloads, each payload is preceded by its length
+	// as 8 bytes big-endian integer. If specified, the server verifies it before writing the records
+	// and rejects the whole batch on mismatch. Empty value disables the check.
+	Checksum string `protobuf:"bytes,7,opt,name=checksum,proto3" json:"checksum,omitempty"`
 }
 
-func (x *QueryLogsResult) Reset() {
-	*x = QueryLogsResult{}
+func (x *AppendRecordsRequest) Reset() {
+	*x = AppendRecordsRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -389,13 +595,13 @@ func (x *QueryLogsResult) Reset() {
 	}
 }
 
-func (x *QueryLogsResult) String() string {
+func (x *AppendRecordsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueryLogsResult) ProtoMessage() {}
+func (*AppendRecordsRequest) ProtoMessage() {}
 
-func (x *QueryLogsResult) ProtoReflect() protoreflect.Message {
+func (x *AppendRecordsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -407,43 +613,74 @@ func (x *QueryLogsResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueryLogsResult.ProtoReflect.Descriptor instead.
-func (*QueryLogsResult) Descriptor() ([]byte, []int) {
+// Deprecated: Use AppendRecordsRequest.ProtoReflect.Descriptor instead.
+func (*AppendRecordsRequest) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *QueryLogsResult) GetLogs() []*Log {
+func (x *AppendRecordsRequest) GetLogID() string {
 	if x != nil {
-		return x.Logs
+		return x.LogID
+	}
+	return ""
+}
+
+func (x *AppendRecordsRequest) GetRecords() []*Record {
+	if x != nil {
+		return x.Records
 	}
 	return nil
 }
 
-func (x *QueryLogsResult) GetNextPageID() string {
+func (x *AppendRecordsRequest) GetExpandIDs() bool {
 	if x != nil {
-		return x.NextPageID
+		return x.ExpandIDs
 	}
-	return ""
+	return false
 }
 
-func (x *QueryLogsResult) GetTotal() int64 {
+func (x *AppendRecordsRequest) GetIdempotencyKey() string {
 	if x != nil {
-		return x.Total
+		return x.IdempotencyKey
 	}
-	return 0
+	return ""
 }
 
-// DeleteLogsRequest specifies the condition for the deleted logs
-type DeleteLogsRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *AppendRecordsRequest) GetMode() AppendMode {
+	if x != nil {
+		return x.Mode
+	}
+	return AppendMode_APPEND_MODE_DEFAULT
+}
 
-	Condition string `protobuf:"bytes,1,opt,name=condition,proto3" json:"condition,omitempty"`
+func (x *AppendRecordsRequest) GetChecksumAlgorithm() string {
+	if x != nil {
+		return x.ChecksumAlgorithm
+	}
+	return ""
 }
 
-func (x *DeleteLogsRequest) Reset() {
-	*x = DeleteLogsRequest{}
+func (x *AppendRecordsRequest) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+// AppendRejected describes the request records, which were not added to the log
+type AppendRejected struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// count is the number of the rejected records, they are always the last records of the request
+	Count int64 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	// reason tells why the records were rejected
+	Reason RejectReason `protobuf:"varint,2,opt,name=reason,proto3,enum=solaris.v1.RejectReason" json:"reason,omitempty"`
+}
+
+func (x *AppendRejected) Reset() {
+	*x = AppendRejected{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -451,13 +688,13 @@ func (x *DeleteLogsRequest) Reset() {
 	}
 }
 
-func (x *DeleteLogsRequest) String() string {
+func (x *AppendRejected) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteLogsRequest) ProtoMessage() {}
+func (*AppendRejected) ProtoMessage() {}
 
-func (x *DeleteLogsRequest) ProtoReflect() protoreflect.Message {
+func (x *AppendRejected) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -469,29 +706,54 @@ func (x *DeleteLogsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteLogsRequest.ProtoReflect.Descriptor instead.
-func (*DeleteLogsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AppendRejected.ProtoReflect.Descriptor instead.
+func (*AppendRejected) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *DeleteLogsRequest) GetCondition() string {
+func (x *AppendRejected) GetCount() int64 {
 	if x != nil {
-		return x.Condition
+		return x.Count
 	}
-	return ""
+	return 0
 }
 
-// DeleteLogsResult describes the response for DeleteLogsRequest
-type DeleteLogsResult struct {
+func (x *AppendRejected) GetReason() RejectReason {
+	if x != nil {
+		return x.Reason
+	}
+	return RejectReason_REJECT_REASON_ERROR
+}
+
+// AppendRecordsResult contains the number or records added to the log
+type AppendRecordsResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeletedIDs []string `protobuf:"bytes,1,rep,name=deletedIDs,proto3" json:"deletedIDs,omitempty"`
+	// added is number of records added to database. If this value is less than passed ID list
+	Added int64 `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	// list of inserted ids. Returned only if expandIDs of request set to true
+	RecordIDs []string `protobuf:"bytes,2,rep,name=recordIDs,proto3" json:"recordIDs,omitempty"`
+	// warnings contains the notes about the log state the client may want to react to, for example, the log
+	// is close to its quota, so the next appends may be rejected soon
+	Warnings []string `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// bytesWritten is the total size of the added records payloads as they are persisted
+	BytesWritten int64 `protobuf:"varint,4,opt,name=bytesWritten,proto3" json:"bytesWritten,omitempty"`
+	// startID is the ID of the first added record. Empty if no records were added
+	StartID string `protobuf:"bytes,5,opt,name=startID,proto3" json:"startID,omitempty"`
+	// lastID is the ID of the last added record. Empty if no records were added
+	LastID string `protobuf:"bytes,6,opt,name=lastID,proto3" json:"lastID,omitempty"`
+	// partial is true if only some of the request records were written because of an error
+	Partial bool `protobuf:"varint,7,opt,name=partial,proto3" json:"partial,omitempty"`
+	// partialError describes the error, which stopped the partial write
+	PartialError string `protobuf:"bytes,8,opt,name=partialError,proto3" json:"partialError,omitempty"`
+	// rejected describes the request records, which were not added, if the write is partial
+	Rejected *AppendRejected `protobuf:"bytes,9,opt,name=rejected,proto3" json:"rejected,omitempty"`
 }
 
-func (x *DeleteLogsResult) Reset() {
-	*x = DeleteLogsResult{}
+func (x *AppendRecordsResult) Reset() {
+	*x = AppendRecordsResult{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -499,13 +761,13 @@ func (x *DeleteLogsResult) Reset() {
 	}
 }
 
-func (x *DeleteLogsResult) String() string {
+func (x *AppendRecordsResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteLogsResult) ProtoMessage() {}
+func (*AppendRecordsResult) ProtoMessage() {}
 
-func (x *DeleteLogsResult) ProtoReflect() protoreflect.Message {
+func (x *AppendRecordsResult) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -517,32 +779,96 @@ func (x *DeleteLogsResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteLogsResult.ProtoReflect.Descriptor instead.
-func (*DeleteLogsResult) Descriptor() ([]byte, []int) {
+// Deprecated: Use AppendRecordsResult.ProtoReflect.Descriptor instead.
+func (*AppendRecordsResult) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *DeleteLogsResult) GetDeletedIDs() []string {
+func (x *AppendRecordsResult) GetAdded() int64 {
 	if x != nil {
-		return x.DeletedIDs
+		return x.Added
+	}
+	return 0
+}
+
+func (x *AppendRecordsResult) GetRecordIDs() []string {
+	if x != nil {
+		return x.RecordIDs
 	}
 	return nil
 }
 
-// CountResult returns a counted number of an operation
-type CountResult struct {
+func (x *AppendRecordsResult) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *AppendRecordsResult) GetBytesWritten() int64 {
+	if x != nil {
+		return x.BytesWritten
+	}
+	return 0
+}
+
+func (x *AppendRecordsResult) GetStartID() string {
+	if x != nil {
+		return x.StartID
+	}
+	return ""
+}
+
+func (x *AppendRecordsResult) GetLastID() string {
+	if x != nil {
+		return x.LastID
+	}
+	return ""
+}
+
+func (x *AppendRecordsResult) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *AppendRecordsResult) GetPartialError() string {
+	if x != nil {
+		return x.PartialError
+	}
+	return ""
+}
+
+func (x *AppendRecordsResult) GetRejected() *AppendRejected {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+// QueryLogsRequest allows to read multiple Log objects per one request
+type QueryLogsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// total contains total number of messages in all affected logs
-	Total int64 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
-	// count contains number of messages matching the condition
-	Count int64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// condition describes the log filter condition
+	Condition string `protobuf:"bytes,1,opt,name=condition,proto3" json:"condition,omitempty"`
+	// pageID is the ID of the last log of the previous page (see QueryLogsResult.nextPageID), the logs are
+	// returned after it. The logs are ordered by their IDs, so the logs created between the pages requests
+	// don't shift the pages.
+	PageID string `protobuf:"bytes,2,opt,name=pageID,proto3" json:"pageID,omitempty"`
+	// limit contains tha maximum number of Log objects in the result
+	Limit int64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// createdAfter allows to select the logs created at or after the time
+	CreatedAfter *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=createdAfter,proto3" json:"createdAfter,omitempty"`
+	// createdBefore allows to select the logs created at or before the time
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=createdBefore,proto3" json:"createdBefore,omitempty"`
 }
 
-func (x *CountResult) Reset() {
-	*x = CountResult{}
+func (x *QueryLogsRequest) Reset() {
+	*x = QueryLogsRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -550,13 +876,13 @@ func (x *CountResult) Reset() {
 	}
 }
 
-func (x *CountResult) String() string {
+func (x *QueryLogsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CountResult) ProtoMessage() {}
+func (*QueryLogsRequest) ProtoMessage() {}
 
-func (x *CountResult) ProtoReflect() protoreflect.Message {
+func (x *QueryLogsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -568,51 +894,62 @@ func (x *CountResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CountResult.ProtoReflect.Descriptor instead.
-func (*CountResult) Descriptor() ([]byte, []int) {
+// Deprecated: Use QueryLogsRequest.ProtoReflect.Descriptor instead.
+func (*QueryLogsRequest) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *CountResult) GetTotal() int64 {
+func (x *QueryLogsRequest) GetCondition() string {
 	if x != nil {
-		return x.Total
+		return x.Condition
 	}
-	return 0
+	return ""
 }
 
-func (x *CountResult) GetCount() int64 {
+func (x *QueryLogsRequest) GetPageID() string {
 	if x != nil {
-		return x.Count
+		return x.PageID
+	}
+	return ""
+}
+
+func (x *QueryLogsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
 	}
 	return 0
 }
 
-// QueryRecordsRequest contains arguments for requesting Log(s) records
-type QueryRecordsRequest struct {
+func (x *QueryLogsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *QueryLogsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+// QueryLogsResult describes the response for QueryLogsRequest
+type QueryLogsResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// logsCondition allows to specify the filter condition for selecting logs.
-	LogsCondition string `protobuf:"bytes,1,opt,name=logsCondition,proto3" json:"logsCondition,omitempty"`
-	// condition allows to specify the filter for the records.
-	Condition string `protobuf:"bytes,2,opt,name=condition,proto3" json:"condition,omitempty"`
-	// logIDs allows to specify the list of logs explicitly. If it is provided, then the logsCondition will be ignored.
-	LogIDs []string `protobuf:"bytes,3,rep,name=logIDs,proto3" json:"logIDs,omitempty"`
-	// descending specifies that the result should be sorted in the record ID descending order.
-	Descending bool `protobuf:"varint,4,opt,name=descending,proto3" json:"descending,omitempty"`
-	// startRecordID defines the first record ID the result set may start from. In case of ascending order, the
-	// result will contain records with IDs are equal or GREATER the startRecordID. If the descending order
-	// is true, the result will contain records with IDs are equal or LOWER the startRecordID.
-	// If the startRecordID is empty the result will start from either first or latest record in the log, depending
-	// on the descending flag value.
-	StartRecordID string `protobuf:"bytes,5,opt,name=startRecordID,proto3" json:"startRecordID,omitempty"`
-	// limit contains the number of records to be returned
-	Limit int64 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	// logs is the list of Log objects in the result
+	Logs []*Log `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+	// nextPageID contains the pageID for reading next portion of the logs if any, it is the last returned log ID
+	NextPageID string `protobuf:"bytes,2,opt,name=nextPageID,proto3" json:"nextPageID,omitempty"`
+	// total is the number of records matched to the result
+	Total int64 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
 }
 
-func (x *QueryRecordsRequest) Reset() {
-	*x = QueryRecordsRequest{}
+func (x *QueryLogsResult) Reset() {
+	*x = QueryLogsResult{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -620,13 +957,13 @@ func (x *QueryRecordsRequest) Reset() {
 	}
 }
 
-func (x *QueryRecordsRequest) String() string {
+func (x *QueryLogsResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueryRecordsRequest) ProtoMessage() {}
+func (*QueryLogsResult) ProtoMessage() {}
 
-func (x *QueryRecordsRequest) ProtoReflect() protoreflect.Message {
+func (x *QueryLogsResult) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -638,67 +975,52 @@ func (x *QueryRecordsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueryRecordsRequest.ProtoReflect.Descriptor instead.
-func (*QueryRecordsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use QueryLogsResult.ProtoReflect.Descriptor instead.
+func (*QueryLogsResult) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *QueryRecordsRequest) GetLogsCondition() string {
-	if x != nil {
-		return x.LogsCondition
-	}
-	return ""
-}
-
-func (x *QueryRecordsRequest) GetCondition() string {
-	if x != nil {
-		return x.Condition
-	}
-	return ""
-}
-
-func (x *QueryRecordsRequest) GetLogIDs() []string {
+func (x *QueryLogsResult) GetLogs() []*Log {
 	if x != nil {
-		return x.LogIDs
+		return x.Logs
 	}
 	return nil
 }
 
-func (x *QueryRecordsRequest) GetDescending() bool {
-	if x != nil {
-		return x.Descending
-	}
-	return false
-}
-
-func (x *QueryRecordsRequest) GetStartRecordID() string {
+func (x *QueryLogsResult) GetNextPageID() string {
 	if x != nil {
-		return x.StartRecordID
+		return x.NextPageID
 	}
 	return ""
 }
 
-func (x *QueryRecordsRequest) GetLimit() int64 {
+func (x *QueryLogsResult) GetTotal() int64 {
 	if x != nil {
-		return x.Limit
+		return x.Total
 	}
 	return 0
 }
 
-// QueryRecordsResult describes the result for the records request
-type QueryRecordsResult struct {
+// QueryActiveLogsRequest describes the logs with the records in the time window
+type QueryActiveLogsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// records is the list of records matched for the request
-	Records []*Record `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
-	// nextPageID contains the next page ID for retrieving the next portion of records
-	NextPageID string `protobuf:"bytes,2,opt,name=nextPageID,proto3" json:"nextPageID,omitempty"`
+	// condition allows to specify the filter for selecting logs, see QueryLogsRequest.condition
+	Condition string `protobuf:"bytes,1,opt,name=condition,proto3" json:"condition,omitempty"`
+	// pageID is the log ID the logs are checked after, see QueryActiveLogsResult.nextPageID
+	PageID string `protobuf:"bytes,2,opt,name=pageID,proto3" json:"pageID,omitempty"`
+	// limit contains the maximum number of the log IDs in the result. Zero value means the default limit of 1000.
+	Limit int64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// createdAfter and createdBefore define the window (inclusive) the logs records were created in. Zero values
+	// mean the window is not limited, so the logs with any records are returned.
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=createdAfter,proto3" json:"createdAfter,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=createdBefore,proto3" json:"createdBefore,omitempty"`
 }
 
-func (x *QueryRecordsResult) Reset() {
-	*x = QueryRecordsResult{}
+func (x *QueryActiveLogsRequest) Reset() {
+	*x = QueryActiveLogsRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_solaris_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -706,13 +1028,13 @@ func (x *QueryRecordsResult) Reset() {
 	}
 }
 
-func (x *QueryRecordsResult) String() string {
+func (x *QueryActiveLogsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueryRecordsResult) ProtoMessage() {}
+func (*QueryActiveLogsRequest) ProtoMessage() {}
 
-func (x *QueryRecordsResult) ProtoReflect() protoreflect.Message {
+func (x *QueryActiveLogsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_solaris_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -724,143 +1046,2255 @@ func (x *QueryRecordsResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueryRecordsResult.ProtoReflect.Descriptor instead.
-func (*QueryRecordsResult) Descriptor() ([]byte, []int) {
+// Deprecated: Use QueryActiveLogsRequest.ProtoReflect.Descriptor instead.
+func (*QueryActiveLogsRequest) Descriptor() ([]byte, []int) {
 	return file_solaris_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *QueryRecordsResult) GetRecords() []*Record {
+func (x *QueryActiveLogsRequest) GetCondition() string {
 	if x != nil {
-		return x.Records
+		return x.Condition
 	}
-	return nil
+	return ""
 }
 
-func (x *QueryRecordsResult) GetNextPageID() string {
+func (x *QueryActiveLogsRequest) GetPageID() string {
 	if x != nil {
-		return x.NextPageID
+		return x.PageID
 	}
 	return ""
 }
 
-var File_solaris_proto protoreflect.FileDescriptor
+func (x *QueryActiveLogsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
 
-var file_solaris_proto_rawDesc = []byte{
-	0x0a, 0x0d, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x0a, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x82, 0x01, 0x0a,
-	0x06, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x12, 0x38, 0x0a,
-	0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f,
-	0x61, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
-	0x64, 0x22, 0xf1, 0x01, 0x0a, 0x03, 0x4c, 0x6f, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x2d, 0x0a, 0x04, 0x74, 0x61, 0x67,
-	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69,
-	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x38, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+func (x *QueryActiveLogsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *QueryActiveLogsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+// QueryActiveLogsResult contains the IDs of the logs with the records in the time window
+type QueryActiveLogsResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// logIDs is the list of the active logs IDs
+	LogIDs []string `protobuf:"bytes,1,rep,name=logIDs,proto3" json:"logIDs,omitempty"`
+	// nextPageID contains the pageID for checking the next portion of the logs if any
+	NextPageID string `protobuf:"bytes,2,opt,name=nextPageID,proto3" json:"nextPageID,omitempty"`
+}
+
+func (x *QueryActiveLogsResult) Reset() {
+	*x = QueryActiveLogsResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryActiveLogsResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryActiveLogsResult) ProtoMessage() {}
+
+func (x *QueryActiveLogsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryActiveLogsResult.ProtoReflect.Descriptor instead.
+func (*QueryActiveLogsResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *QueryActiveLogsResult) GetLogIDs() []string {
+	if x != nil {
+		return x.LogIDs
+	}
+	return nil
+}
+
+func (x *QueryActiveLogsResult) GetNextPageID() string {
+	if x != nil {
+		return x.NextPageID
+	}
+	return ""
+}
+
+// LatestPerLogRequest describes the logs the latest records are requested for
+type LatestPerLogRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// condition allows to specify the filter for selecting logs, see QueryLogsRequest.condition. No more
+	// than 1000 logs may match the condition.
+	Condition string `protobuf:"bytes,1,opt,name=condition,proto3" json:"condition,omitempty"`
+}
+
+func (x *LatestPerLogRequest) Reset() {
+	*x = LatestPerLogRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LatestPerLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatestPerLogRequest) ProtoMessage() {}
+
+func (x *LatestPerLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatestPerLogRequest.ProtoReflect.Descriptor instead.
+func (*LatestPerLogRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LatestPerLogRequest) GetCondition() string {
+	if x != nil {
+		return x.Condition
+	}
+	return ""
+}
+
+// LatestPerLogResult contains the latest records of the logs
+type LatestPerLogResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// records contains the latest record by the log ID, the logs without records are not in the map
+	Records map[string]*Record `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *LatestPerLogResult) Reset() {
+	*x = LatestPerLogResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LatestPerLogResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatestPerLogResult) ProtoMessage() {}
+
+func (x *LatestPerLogResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatestPerLogResult.ProtoReflect.Descriptor instead.
+func (*LatestPerLogResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LatestPerLogResult) GetRecords() map[string]*Record {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+// GetRecordByHashRequest describes the request for GetRecordByHash
+type GetRecordByHashRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// logID is the log the records are searched in
+	LogID string `protobuf:"bytes,1,opt,name=logID,proto3" json:"logID,omitempty"`
+	// hash is the hex-encoded payload hash made by the log payloadHash function
+	Hash string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (x *GetRecordByHashRequest) Reset() {
+	*x = GetRecordByHashRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRecordByHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecordByHashRequest) ProtoMessage() {}
+
+func (x *GetRecordByHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecordByHashRequest.ProtoReflect.Descriptor instead.
+func (*GetRecordByHashRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetRecordByHashRequest) GetLogID() string {
+	if x != nil {
+		return x.LogID
+	}
+	return ""
+}
+
+func (x *GetRecordByHashRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+// GetRecordByHashResult contains the IDs of the records found by the payload hash
+type GetRecordByHashResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// recordIDs contains the IDs of the log records with the payload hash in ascending order. Different payloads
+	// may have the same hash, so the records payloads should be compared to be sure they are the same.
+	RecordIDs []string `protobuf:"bytes,1,rep,name=recordIDs,proto3" json:"recordIDs,omitempty"`
+}
+
+func (x *GetRecordByHashResult) Reset() {
+	*x = GetRecordByHashResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRecordByHashResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecordByHashResult) ProtoMessage() {}
+
+func (x *GetRecordByHashResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecordByHashResult.ProtoReflect.Descriptor instead.
+func (*GetRecordByHashResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetRecordByHashResult) GetRecordIDs() []string {
+	if x != nil {
+		return x.RecordIDs
+	}
+	return nil
+}
+
+// RotateLogKeyRequest describes the request for RotateLogKey
+type RotateLogKeyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// logID is the ID of the log which key is rotated
+	LogID string `protobuf:"bytes,1,opt,name=logID,proto3" json:"logID,omitempty"`
+}
+
+func (x *RotateLogKeyRequest) Reset() {
+	*x = RotateLogKeyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RotateLogKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateLogKeyRequest) ProtoMessage() {}
+
+func (x *RotateLogKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateLogKeyRequest.ProtoReflect.Descriptor instead.
+func (*RotateLogKeyRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RotateLogKeyRequest) GetLogID() string {
+	if x != nil {
+		return x.LogID
+	}
+	return ""
+}
+
+// RotateLogKeyResult describes the response for RotateLogKeyRequest
+type RotateLogKeyResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// keyID is the ID of the new log key
+	KeyID string `protobuf:"bytes,1,opt,name=keyID,proto3" json:"keyID,omitempty"`
+}
+
+func (x *RotateLogKeyResult) Reset() {
+	*x = RotateLogKeyResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RotateLogKeyResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateLogKeyResult) ProtoMessage() {}
+
+func (x *RotateLogKeyResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateLogKeyResult.ProtoReflect.Descriptor instead.
+func (*RotateLogKeyResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RotateLogKeyResult) GetKeyID() string {
+	if x != nil {
+		return x.KeyID
+	}
+	return ""
+}
+
+// DeleteLogsRequest specifies the condition for the deleted logs
+type DeleteLogsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Condition string `protobuf:"bytes,1,opt,name=condition,proto3" json:"condition,omitempty"`
+	// hardDelete specifies that the logs and their chunks are deleted physically. Otherwise,
+	// the logs are marked for deletion only.
+	HardDelete bool `protobuf:"varint,2,opt,name=hardDelete,proto3" json:"hardDelete,omitempty"`
+	// logIDs contains the IDs of the logs to be deleted. If specified, the condition
+	// is disregarded. The logs, which don't exist or are already deleted, are skipped,
+	// so the request may be repeated safely.
+	LogIDs []string `protobuf:"bytes,3,rep,name=logIDs,proto3" json:"logIDs,omitempty"`
+}
+
+func (x *DeleteLogsRequest) Reset() {
+	*x = DeleteLogsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLogsRequest) ProtoMessage() {}
+
+func (x *DeleteLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLogsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLogsRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeleteLogsRequest) GetCondition() string {
+	if x != nil {
+		return x.Condition
+	}
+	return ""
+}
+
+func (x *DeleteLogsRequest) GetHardDelete() bool {
+	if x != nil {
+		return x.HardDelete
+	}
+	return false
+}
+
+func (x *DeleteLogsRequest) GetLogIDs() []string {
+	if x != nil {
+		return x.LogIDs
+	}
+	return nil
+}
+
+// SetReadOnlyRequest describes the request for SetReadOnly
+type SetReadOnlyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// readOnly specifies whether the read-only mode is turned on or off
+	ReadOnly bool `protobuf:"varint,1,opt,name=readOnly,proto3" json:"readOnly,omitempty"`
+}
+
+func (x *SetReadOnlyRequest) Reset() {
+	*x = SetReadOnlyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetReadOnlyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetReadOnlyRequest) ProtoMessage() {}
+
+func (x *SetReadOnlyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetReadOnlyRequest.ProtoReflect.Descriptor instead.
+func (*SetReadOnlyRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SetReadOnlyRequest) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+// SetReadOnlyResult describes the response for SetReadOnlyRequest
+type SetReadOnlyResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// wasReadOnly contains the read-only mode before the request
+	WasReadOnly bool `protobuf:"varint,1,opt,name=wasReadOnly,proto3" json:"wasReadOnly,omitempty"`
+}
+
+func (x *SetReadOnlyResult) Reset() {
+	*x = SetReadOnlyResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetReadOnlyResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetReadOnlyResult) ProtoMessage() {}
+
+func (x *SetReadOnlyResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetReadOnlyResult.ProtoReflect.Descriptor instead.
+func (*SetReadOnlyResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SetReadOnlyResult) GetWasReadOnly() bool {
+	if x != nil {
+		return x.WasReadOnly
+	}
+	return false
+}
+
+// GetStorageLayoutRequest describes the request for GetStorageLayout
+type GetStorageLayoutRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetStorageLayoutRequest) Reset() {
+	*x = GetStorageLayoutRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStorageLayoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStorageLayoutRequest) ProtoMessage() {}
+
+func (x *GetStorageLayoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStorageLayoutRequest.ProtoReflect.Descriptor instead.
+func (*GetStorageLayoutRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{21}
+}
+
+// StorageLayout describes the response for GetStorageLayoutRequest
+type StorageLayout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// currentVersion is the format version the new chunks are written in
+	CurrentVersion int32 `protobuf:"varint,1,opt,name=currentVersion,proto3" json:"currentVersion,omitempty"`
+	// versions contains the chunks stats by the format versions found, ordered by the version ascending
+	Versions []*FormatVersionStats `protobuf:"bytes,2,rep,name=versions,proto3" json:"versions,omitempty"`
+	// migration contains the status of the background migration of the chunks to the current format
+	Migration *FormatMigration `protobuf:"bytes,3,opt,name=migration,proto3" json:"migration,omitempty"`
+}
+
+func (x *StorageLayout) Reset() {
+	*x = StorageLayout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageLayout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageLayout) ProtoMessage() {}
+
+func (x *StorageLayout) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageLayout.ProtoReflect.Descriptor instead.
+func (*StorageLayout) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *StorageLayout) GetCurrentVersion() int32 {
+	if x != nil {
+		return x.CurrentVersion
+	}
+	return 0
+}
+
+func (x *StorageLayout) GetVersions() []*FormatVersionStats {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+func (x *StorageLayout) GetMigration() *FormatMigration {
+	if x != nil {
+		return x.Migration
+	}
+	return nil
+}
+
+// FormatMigration describes the status of the chunks format migration
+type FormatMigration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// enabled is true if the migration is turned on by the server config
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// running is true if a pass over the logs is in progress
+	Running bool `protobuf:"varint,2,opt,name=running,proto3" json:"running,omitempty"`
+	// nextLogID is the ID of the log the current pass continues after
+	NextLogID string `protobuf:"bytes,3,opt,name=nextLogID,proto3" json:"nextLogID,omitempty"`
+	// migrated contains the number of chunks migrated by the current (or the last) pass
+	Migrated int64 `protobuf:"varint,4,opt,name=migrated,proto3" json:"migrated,omitempty"`
+	// failedLogs contains the number of logs, which could not be migrated by the current (or the last) pass
+	FailedLogs int64 `protobuf:"varint,5,opt,name=failedLogs,proto3" json:"failedLogs,omitempty"`
+	// finishedAt is the time the last pass was finished
+	FinishedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=finishedAt,proto3" json:"finishedAt,omitempty"`
+}
+
+func (x *FormatMigration) Reset() {
+	*x = FormatMigration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FormatMigration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FormatMigration) ProtoMessage() {}
+
+func (x *FormatMigration) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FormatMigration.ProtoReflect.Descriptor instead.
+func (*FormatMigration) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *FormatMigration) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *FormatMigration) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *FormatMigration) GetNextLogID() string {
+	if x != nil {
+		return x.NextLogID
+	}
+	return ""
+}
+
+func (x *FormatMigration) GetMigrated() int64 {
+	if x != nil {
+		return x.Migrated
+	}
+	return 0
+}
+
+func (x *FormatMigration) GetFailedLogs() int64 {
+	if x != nil {
+		return x.FailedLogs
+	}
+	return 0
+}
+
+func (x *FormatMigration) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+// FormatVersionStats contains the number and the size of the chunks stored in one format version
+type FormatVersionStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version int32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// chunks contains the number of the chunks of the version
+	Chunks int64 `protobuf:"varint,2,opt,name=chunks,proto3" json:"chunks,omitempty"`
+	// size contains the chunks files size in bytes
+	Size int64 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+}
+
+func (x *FormatVersionStats) Reset() {
+	*x = FormatVersionStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FormatVersionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FormatVersionStats) ProtoMessage() {}
+
+func (x *FormatVersionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FormatVersionStats.ProtoReflect.Descriptor instead.
+func (*FormatVersionStats) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *FormatVersionStats) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *FormatVersionStats) GetChunks() int64 {
+	if x != nil {
+		return x.Chunks
+	}
+	return 0
+}
+
+func (x *FormatVersionStats) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// DeleteLogsResult describes the response for DeleteLogsRequest
+type DeleteLogsResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeletedIDs []string `protobuf:"bytes,1,rep,name=deletedIDs,proto3" json:"deletedIDs,omitempty"`
+	// freedBytes contains the size of the deleted chunks files
+	FreedBytes int64 `protobuf:"varint,2,opt,name=freedBytes,proto3" json:"freedBytes,omitempty"`
+}
+
+func (x *DeleteLogsResult) Reset() {
+	*x = DeleteLogsResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteLogsResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLogsResult) ProtoMessage() {}
+
+func (x *DeleteLogsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLogsResult.ProtoReflect.Descriptor instead.
+func (*DeleteLogsResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeleteLogsResult) GetDeletedIDs() []string {
+	if x != nil {
+		return x.DeletedIDs
+	}
+	return nil
+}
+
+func (x *DeleteLogsResult) GetFreedBytes() int64 {
+	if x != nil {
+		return x.FreedBytes
+	}
+	return 0
+}
+
+// CountResult returns a counted number of an operation
+type CountResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// total contains total number of messages in all affected logs
+	Total int64 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	// count contains number of messages matching the condition
+	Count int64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// exact is false if the count is estimated, because the QueryRecordsRequest.countBudgetMs is over
+	Exact bool `protobuf:"varint,3,opt,name=exact,proto3" json:"exact,omitempty"`
+}
+
+func (x *CountResult) Reset() {
+	*x = CountResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CountResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountResult) ProtoMessage() {}
+
+func (x *CountResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountResult.ProtoReflect.Descriptor instead.
+func (*CountResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CountResult) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *CountResult) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *CountResult) GetExact() bool {
+	if x != nil {
+		return x.Exact
+	}
+	return false
+}
+
+// QueryRecordsRequest contains arguments for requesting Log(s) records
+type QueryRecordsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// logsCondition allows to specify the filter condition for selecting logs.
+	LogsCondition string `protobuf:"bytes,1,opt,name=logsCondition,proto3" json:"logsCondition,omitempty"`
+	// condition allows to specify the filter for the records.
+	Condition string `protobuf:"bytes,2,opt,name=condition,proto3" json:"condition,omitempty"`
+	// logIDs allows to specify the list of logs explicitly. If it is provided, then the logsCondition will be ignored.
+	LogIDs []string `protobuf:"bytes,3,rep,name=logIDs,proto3" json:"logIDs,omitempty"`
+	// descending specifies that the result should be sorted in the record ID descending order.
+	Descending bool `protobuf:"varint,4,opt,name=descending,proto3" json:"descending,omitempty"`
+	// startRecordID defines the first record ID the result set may start from. In case of ascending order, the
+	// result will contain records with IDs are equal or GREATER the startRecordID. If the descending order
+	// is true, the result will contain records with IDs are equal or LOWER the startRecordID.
+	// If the startRecordID is empty the result will start from either first or latest record in the log, depending
+	// on the descending flag value.
+	StartRecordID string `protobuf:"bytes,5,opt,name=startRecordID,proto3" json:"startRecordID,omitempty"`
+	// limit contains the number of records to be returned
+	Limit int64 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	// minPayloadLen allows to select the records with the payload length (in bytes) equal or greater than the value.
+	// Zero value means no lower limit.
+	MinPayloadLen int64 `protobuf:"varint,7,opt,name=minPayloadLen,proto3" json:"minPayloadLen,omitempty"`
+	// maxPayloadLen allows to select the records with the payload length (in bytes) equal or less than the value.
+	// Zero value means no upper limit.
+	MaxPayloadLen int64 `protobuf:"varint,8,opt,name=maxPayloadLen,proto3" json:"maxPayloadLen,omitempty"`
+	// conditionHandle allows to refer to the condition compiled by CompileCondition. If it is provided,
+	// then the condition will be ignored.
+	ConditionHandle string `protobuf:"bytes,9,opt,name=conditionHandle,proto3" json:"conditionHandle,omitempty"`
+	// withAge specifies that the records in the result should contain their age (see Record.ageMs),
+	// calculated by the server clock
+	WithAge bool `protobuf:"varint,10,opt,name=withAge,proto3" json:"withAge,omitempty"`
+	// startSeq defines the sequence number (see Record.seq) of the record the result set may start from. It works
+	// the same way as the startRecordID, but it may be used for one log only. If it is provided, then
+	// the startRecordID will be ignored.
+	StartSeq int64 `protobuf:"varint,11,opt,name=startSeq,proto3" json:"startSeq,omitempty"`
+	// maxPerLog limits the number of records every log contributes to the result, so a few very active logs
+	// could not push the records of the quiet ones out of the result. The records are still merged in the requested
+	// order and the limit is applied to the merged result. The log records over the maxPerLog are skipped, the next
+	// page (see QueryRecordsResult.nextPageID) starts after the last returned record, so the skipped records are not
+	// returned on the next pages either. Zero value means no limit.
+	MaxPerLog int64 `protobuf:"varint,12,opt,name=maxPerLog,proto3" json:"maxPerLog,omitempty"`
+	// asAny specifies that the records of the logs with the payloadTypeURL (see Log.payloadTypeURL) are returned
+	// with the payload packed into the Record.any field. The records of other logs are returned as is.
+	AsAny bool `protobuf:"varint,13,opt,name=asAny,proto3" json:"asAny,omitempty"`
+	// createdAfter and createdBefore define the window (inclusive) the selected records were created in. The window
+	// may be combined with the descending order and the startRecordID, so the pages are read within the window only,
+	// newest-first or oldest-first, and the nextPageID is empty when the window end is reached.
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=createdAfter,proto3" json:"createdAfter,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=createdBefore,proto3" json:"createdBefore,omitempty"`
+	// startExclusive specifies that the record with the startRecordID is not included into the result in both
+	// directions, so the next page may be requested by the ID of the last record of the previous page. The
+	// startSeq is not affected.
+	StartExclusive bool `protobuf:"varint,16,opt,name=startExclusive,proto3" json:"startExclusive,omitempty"`
+	// skipCorrupted specifies that the corrupted parts of the logs are skipped, so the records stored before a
+	// corrupted record are returned and the records stored after it are read from the next part of the log. The
+	// skipped records are not returned on the next pages either. By default, the request fails on the first
+	// corrupted record.
+	SkipCorrupted bool `protobuf:"varint,17,opt,name=skipCorrupted,proto3" json:"skipCorrupted,omitempty"`
+	// countBudgetMs limits the time (in milliseconds) CountRecords spends on scanning the logs. When the time is over,
+	// the rest of the logs parts are not scanned, but their counts are estimated, so the approximate count is returned
+	// (see CountResult.exact). Zero value means no limit. The field is not used by other requests.
+	CountBudgetMs int64 `protobuf:"varint,18,opt,name=countBudgetMs,proto3" json:"countBudgetMs,omitempty"`
+	// dedup specifies that the records with the same ID found in several logs (the replicated records, for example)
+	// are returned once, the first of them is kept. The records are compared by their full IDs, so the records
+	// created at the same millisecond with different IDs are all returned.
+	Dedup bool `protobuf:"varint,19,opt,name=dedup,proto3" json:"dedup,omitempty"`
+}
+
+func (x *QueryRecordsRequest) Reset() {
+	*x = QueryRecordsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRecordsRequest) ProtoMessage() {}
+
+func (x *QueryRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRecordsRequest.ProtoReflect.Descriptor instead.
+func (*QueryRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *QueryRecordsRequest) GetLogsCondition() string {
+	if x != nil {
+		return x.LogsCondition
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetCondition() string {
+	if x != nil {
+		return x.Condition
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetLogIDs() []string {
+	if x != nil {
+		return x.LogIDs
+	}
+	return nil
+}
+
+func (x *QueryRecordsRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *QueryRecordsRequest) GetStartRecordID() string {
+	if x != nil {
+		return x.StartRecordID
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *QueryRecordsRequest) GetMinPayloadLen() int64 {
+	if x != nil {
+		return x.MinPayloadLen
+	}
+	return 0
+}
+
+func (x *QueryRecordsRequest) GetMaxPayloadLen() int64 {
+	if x != nil {
+		return x.MaxPayloadLen
+	}
+	return 0
+}
+
+func (x *QueryRecordsRequest) GetConditionHandle() string {
+	if x != nil {
+		return x.ConditionHandle
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetWithAge() bool {
+	if x != nil {
+		return x.WithAge
+	}
+	return false
+}
+
+func (x *QueryRecordsRequest) GetStartSeq() int64 {
+	if x != nil {
+		return x.StartSeq
+	}
+	return 0
+}
+
+func (x *QueryRecordsRequest) GetMaxPerLog() int64 {
+	if x != nil {
+		return x.MaxPerLog
+	}
+	return 0
+}
+
+func (x *QueryRecordsRequest) GetAsAny() bool {
+	if x != nil {
+		return x.AsAny
+	}
+	return false
+}
+
+func (x *QueryRecordsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *QueryRecordsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *QueryRecordsRequest) GetStartExclusive() bool {
+	if x != nil {
+		return x.StartExclusive
+	}
+	return false
+}
+
+func (x *QueryRecordsRequest) GetSkipCorrupted() bool {
+	if x != nil {
+		return x.SkipCorrupted
+	}
+	return false
+}
+
+func (x *QueryRecordsRequest) GetCountBudgetMs() int64 {
+	if x != nil {
+		return x.CountBudgetMs
+	}
+	return 0
+}
+
+func (x *QueryRecordsRequest) GetDedup() bool {
+	if x != nil {
+		return x.Dedup
+	}
+	return false
+}
+
+// StreamRecordsRequest describes the request for streaming records
+type StreamRecordsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// query defines the records to be streamed. The query limit is the total number of records to be streamed,
+	// zero value means all the records matched to the query will be streamed.
+	Query *QueryRecordsRequest `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// maxMessageBytes defines the maximum total payload size (in bytes) of the records in one message. A record is
+	// never split between messages, so a record with the payload bigger than the value is sent in its own message.
+	// Zero value means the default size of 1MiB.
+	MaxMessageBytes int64 `protobuf:"varint,2,opt,name=maxMessageBytes,proto3" json:"maxMessageBytes,omitempty"`
+}
+
+func (x *StreamRecordsRequest) Reset() {
+	*x = StreamRecordsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRecordsRequest) ProtoMessage() {}
+
+func (x *StreamRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRecordsRequest.ProtoReflect.Descriptor instead.
+func (*StreamRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *StreamRecordsRequest) GetQuery() *QueryRecordsRequest {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *StreamRecordsRequest) GetMaxMessageBytes() int64 {
+	if x != nil {
+		return x.MaxMessageBytes
+	}
+	return 0
+}
+
+// CompileConditionRequest contains the records condition to be compiled
+type CompileConditionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// condition is the records filter condition, see QueryRecordsRequest.condition
+	Condition string `protobuf:"bytes,1,opt,name=condition,proto3" json:"condition,omitempty"`
+}
+
+func (x *CompileConditionRequest) Reset() {
+	*x = CompileConditionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompileConditionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompileConditionRequest) ProtoMessage() {}
+
+func (x *CompileConditionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompileConditionRequest.ProtoReflect.Descriptor instead.
+func (*CompileConditionRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CompileConditionRequest) GetCondition() string {
+	if x != nil {
+		return x.Condition
+	}
+	return ""
+}
+
+// CompiledCondition describes the compiled records condition
+type CompiledCondition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// handle is the compiled condition identifier, which may be used in QueryRecordsRequest.conditionHandle.
+	// The same condition is always compiled to the same handle.
+	Handle string `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	// expiresAt is the time when the handle is expired. The handle may be expired earlier if the server
+	// needs the room for other compiled conditions, so the client should compile the condition again
+	// if the handle is not found.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expiresAt,proto3" json:"expiresAt,omitempty"`
+}
+
+func (x *CompiledCondition) Reset() {
+	*x = CompiledCondition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompiledCondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompiledCondition) ProtoMessage() {}
+
+func (x *CompiledCondition) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompiledCondition.ProtoReflect.Descriptor instead.
+func (*CompiledCondition) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CompiledCondition) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *CompiledCondition) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+// InvalidateConditionRequest specifies the compiled condition to be removed
+type InvalidateConditionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Handle string `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+}
+
+func (x *InvalidateConditionRequest) Reset() {
+	*x = InvalidateConditionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InvalidateConditionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidateConditionRequest) ProtoMessage() {}
+
+func (x *InvalidateConditionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidateConditionRequest.ProtoReflect.Descriptor instead.
+func (*InvalidateConditionRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *InvalidateConditionRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+// InvalidateConditionResult describes the response for InvalidateConditionRequest
+type InvalidateConditionResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// invalidated is true if the compiled condition was found and removed
+	Invalidated bool `protobuf:"varint,1,opt,name=invalidated,proto3" json:"invalidated,omitempty"`
+}
+
+func (x *InvalidateConditionResult) Reset() {
+	*x = InvalidateConditionResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InvalidateConditionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidateConditionResult) ProtoMessage() {}
+
+func (x *InvalidateConditionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidateConditionResult.ProtoReflect.Descriptor instead.
+func (*InvalidateConditionResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *InvalidateConditionResult) GetInvalidated() bool {
+	if x != nil {
+		return x.Invalidated
+	}
+	return false
+}
+
+// FieldStatsRequest describes the request for the log fields statistics
+type FieldStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// logID is the log identifier the statistics is collected for
+	LogID string `protobuf:"bytes,1,opt,name=logID,proto3" json:"logID,omitempty"`
+	// sampleSize is the number of the latest log records to be sampled. Zero value means the default
+	// sample size, the value is also limited by the server settings.
+	SampleSize int64 `protobuf:"varint,2,opt,name=sampleSize,proto3" json:"sampleSize,omitempty"`
+	// topN is the number of the most frequent values to be returned per field. Zero value means 10,
+	// the values greater than the server limit are rejected.
+	TopN int64 `protobuf:"varint,3,opt,name=topN,proto3" json:"topN,omitempty"`
+}
+
+func (x *FieldStatsRequest) Reset() {
+	*x = FieldStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FieldStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldStatsRequest) ProtoMessage() {}
+
+func (x *FieldStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldStatsRequest.ProtoReflect.Descriptor instead.
+func (*FieldStatsRequest) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *FieldStatsRequest) GetLogID() string {
+	if x != nil {
+		return x.LogID
+	}
+	return ""
+}
+
+func (x *FieldStatsRequest) GetSampleSize() int64 {
+	if x != nil {
+		return x.SampleSize
+	}
+	return 0
+}
+
+func (x *FieldStatsRequest) GetTopN() int64 {
+	if x != nil {
+		return x.TopN
+	}
+	return 0
+}
+
+// FieldStatsResult contains the statistics of the log fields found in the sample
+type FieldStatsResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// sampleSize is the number of records actually sampled
+	SampleSize int64 `protobuf:"varint,1,opt,name=sampleSize,proto3" json:"sampleSize,omitempty"`
+	// structured is the number of the sampled records with JSON object payloads
+	Structured int64 `protobuf:"varint,2,opt,name=structured,proto3" json:"structured,omitempty"`
+	// fields contains the statistics per field sorted by the field names
+	Fields []*FieldStats `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+}
+
+func (x *FieldStatsResult) Reset() {
+	*x = FieldStatsResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FieldStatsResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldStatsResult) ProtoMessage() {}
+
+func (x *FieldStatsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldStatsResult.ProtoReflect.Descriptor instead.
+func (*FieldStatsResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FieldStatsResult) GetSampleSize() int64 {
+	if x != nil {
+		return x.SampleSize
+	}
+	return 0
+}
+
+func (x *FieldStatsResult) GetStructured() int64 {
+	if x != nil {
+		return x.Structured
+	}
+	return 0
+}
+
+func (x *FieldStatsResult) GetFields() []*FieldStats {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+// FieldStats describes the statistics of one field
+type FieldStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name is the field name
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// count is the number of sampled records containing the field
+	Count int64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// cardinality is the estimated number of the distinct field values (HyperLogLog)
+	Cardinality int64 `protobuf:"varint,3,opt,name=cardinality,proto3" json:"cardinality,omitempty"`
+	// topValues are the most frequent field values with their estimated counts (count-min sketch)
+	// sorted by the count descending
+	TopValues []*ValueCount `protobuf:"bytes,4,rep,name=topValues,proto3" json:"topValues,omitempty"`
+}
+
+func (x *FieldStats) Reset() {
+	*x = FieldStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FieldStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldStats) ProtoMessage() {}
+
+func (x *FieldStats) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldStats.ProtoReflect.Descriptor instead.
+func (*FieldStats) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *FieldStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FieldStats) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *FieldStats) GetCardinality() int64 {
+	if x != nil {
+		return x.Cardinality
+	}
+	return 0
+}
+
+func (x *FieldStats) GetTopValues() []*ValueCount {
+	if x != nil {
+		return x.TopValues
+	}
+	return nil
+}
+
+// ValueCount is a field value with its estimated count
+type ValueCount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// value is the string representation of the value. The strings are unquoted, the other
+	// values are represented by their JSON text
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Count int64  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *ValueCount) Reset() {
+	*x = ValueCount{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValueCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueCount) ProtoMessage() {}
+
+func (x *ValueCount) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueCount.ProtoReflect.Descriptor instead.
+func (*ValueCount) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ValueCount) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *ValueCount) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// QueryRecordsResult describes the result for the records request
+type QueryRecordsResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// records is the list of records matched for the request
+	Records []*Record `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	// nextPageID contains the next page ID for retrieving the next portion of records
+	NextPageID string `protobuf:"bytes,2,opt,name=nextPageID,proto3" json:"nextPageID,omitempty"`
+}
+
+func (x *QueryRecordsResult) Reset() {
+	*x = QueryRecordsResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_solaris_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryRecordsResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRecordsResult) ProtoMessage() {}
+
+func (x *QueryRecordsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_solaris_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRecordsResult.ProtoReflect.Descriptor instead.
+func (*QueryRecordsResult) Descriptor() ([]byte, []int) {
+	return file_solaris_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *QueryRecordsResult) GetRecords() []*Record {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *QueryRecordsResult) GetNextPageID() string {
+	if x != nil {
+		return x.NextPageID
+	}
+	return ""
+}
+
+var File_solaris_proto protoreflect.FileDescriptor
+
+var file_solaris_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0a, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x1a, 0x19, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd5, 0x02, 0x0a, 0x06, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x12, 0x38, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
 	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
-	0x41, 0x74, 0x12, 0x38, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x1a, 0x37, 0x0a, 0x09,
-	0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x78, 0x0a, 0x14, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52,
-	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
-	0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f,
-	0x67, 0x49, 0x44, 0x12, 0x2c, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76,
-	0x31, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64,
-	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x49, 0x44, 0x73, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x49, 0x44, 0x73, 0x22,
-	0x49, 0x0a, 0x13, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73,
-	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09,
-	0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
-	0x09, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x73, 0x22, 0x5e, 0x0a, 0x10, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c,
-	0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06,
-	0x70, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61,
-	0x67, 0x65, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x6c, 0x0a, 0x0f, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x23, 0x0a,
-	0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x6f,
-	0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x04, 0x6c, 0x6f,
-	0x67, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65,
-	0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0x31, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65,
-	0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a,
+	0x41, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x61, 0x67, 0x65, 0x4d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x61, 0x67, 0x65,
+	0x4d, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x03, 0x73, 0x65, 0x71, 0x12, 0x26, 0x0a, 0x03, 0x61, 0x6e, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x03, 0x61, 0x6e, 0x79, 0x12, 0x42, 0x0a, 0x0a,
+	0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x22, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73,
+	0x1a, 0x3d, 0x0a, 0x0f, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0xa4, 0x04, 0x0a, 0x03, 0x4c, 0x6f, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x2d, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x38, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x38, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x76, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x55, 0x54, 0x46, 0x38, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0c, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x55, 0x54, 0x46, 0x38, 0x12, 0x26,
+	0x0a, 0x0e, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x54, 0x79, 0x70, 0x65, 0x55, 0x52, 0x4c,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x54,
+	0x79, 0x70, 0x65, 0x55, 0x52, 0x4c, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x48, 0x61, 0x73, 0x68, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x48, 0x61, 0x73, 0x68, 0x12, 0x43, 0x0a, 0x0f, 0x72, 0x65, 0x74, 0x65,
+	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x78, 0x41, 0x67, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x72, 0x65,
+	0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x78, 0x41, 0x67, 0x65, 0x12, 0x30, 0x0a,
+	0x13, 0x72, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x78, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x72, 0x65, 0x74, 0x65,
+	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x78, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x73, 0x18,
+	0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x73, 0x1a, 0x37, 0x0a,
+	0x09, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x38, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x6c,
+	0x6f, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61,
+	0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73,
+	0x22, 0x37, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x67, 0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x22, 0x1f, 0x0a, 0x0d, 0x47, 0x65, 0x74,
+	0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x22, 0x96, 0x02, 0x0a, 0x14, 0x41,
+	0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x12, 0x2c, 0x0a, 0x07, 0x72, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6f, 0x6c,
+	0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x07,
+	0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x70, 0x61, 0x6e,
+	0x64, 0x49, 0x44, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x78, 0x70, 0x61,
+	0x6e, 0x64, 0x49, 0x44, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74,
+	0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69,
+	0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x12, 0x2a, 0x0a,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x73, 0x6f,
+	0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x4d,
+	0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x2c, 0x0a, 0x11, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x73, 0x75, 0x6d, 0x41, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x41, 0x6c,
+	0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x75, 0x6d, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x75, 0x6d, 0x22, 0x58, 0x0a, 0x0e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x6a,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x30, 0x0a, 0x06, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x73, 0x6f,
+	0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0xb1, 0x02,
+	0x0a, 0x13, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09,
+	0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x61, 0x72,
+	0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x77, 0x61, 0x72,
+	0x6e, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x62, 0x79, 0x74, 0x65, 0x73, 0x57, 0x72,
+	0x69, 0x74, 0x74, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x49, 0x44, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x44, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x61, 0x72,
+	0x74, 0x69, 0x61, 0x6c, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x36, 0x0a, 0x08, 0x72, 0x65, 0x6a,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x6f,
+	0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52,
+	0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x22, 0xe0, 0x01, 0x0a, 0x10, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4c, 0x6f, 0x67, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61, 0x67, 0x65, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x12, 0x3e, 0x0a, 0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x66, 0x74,
+	0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x66, 0x74,
+	0x65, 0x72, 0x12, 0x40, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x22, 0x6c, 0x0a, 0x0f, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4c, 0x6f, 0x67,
+	0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x23, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x12, 0x1e, 0x0a, 0x0a,
+	0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x22, 0xe6, 0x01, 0x0a, 0x16, 0x51, 0x75, 0x65, 0x72, 0x79, 0x41, 0x63, 0x74, 0x69,
+	0x76, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a,
 	0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x32, 0x0a, 0x10, 0x44,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
-	0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x49, 0x44, 0x73, 0x22,
-	0x39, 0x0a, 0x0b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14,
-	0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x74,
-	0x6f, 0x74, 0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xcd, 0x01, 0x0a, 0x13, 0x51,
-	0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x6c, 0x6f, 0x67, 0x73, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74,
-	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6c, 0x6f, 0x67, 0x73, 0x43,
-	0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64,
-	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e,
-	0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x73,
-	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x73, 0x12, 0x1e,
-	0x0a, 0x0a, 0x64, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x0a, 0x64, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x24,
-	0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x63, 0x6f,
-	0x72, 0x64, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x62, 0x0a, 0x12, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x12, 0x2c, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52,
-	0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x1e,
-	0x0a, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44, 0x32, 0xe9,
-	0x03, 0x0a, 0x07, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2d, 0x0a, 0x09, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x12, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69,
-	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x1a, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72,
-	0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x12, 0x2d, 0x0a, 0x09, 0x55, 0x70, 0x64,
-	0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x12, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73,
-	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x1a, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69,
-	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x12, 0x46, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x72,
-	0x79, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x1c, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e,
-	0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31,
-	0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x12, 0x49, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x1d,
-	0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65,
-	0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e,
-	0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x52, 0x0a, 0x0d, 0x41,
-	0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x20, 0x2e, 0x73,
-	0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64,
-	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
-	0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65,
-	0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
-	0x4f, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12,
-	0x1f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65,
-	0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x1e, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x12, 0x48, 0x0a, 0x0c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73,
-	0x12, 0x1f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x17, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43,
-	0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x16, 0x5a, 0x14, 0x2e, 0x2f,
-	0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x73, 0x6f, 0x6c, 0x61, 0x72,
-	0x69, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x70,
+	0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61, 0x67,
+	0x65, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x3e, 0x0a, 0x0c, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12, 0x40, 0x0a, 0x0d, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x22, 0x4f, 0x0a, 0x15, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x73, 0x12, 0x1e, 0x0a, 0x0a,
+	0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44, 0x22, 0x33, 0x0a, 0x13,
+	0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x50, 0x65, 0x72, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0xab, 0x01, 0x0a, 0x12, 0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x50, 0x65, 0x72, 0x4c,
+	0x6f, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x45, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x73, 0x6f, 0x6c, 0x61,
+	0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x50, 0x65, 0x72,
+	0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x1a,
+	0x4e, 0x0a, 0x0c, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x28, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0x42, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x79, 0x48, 0x61,
+	0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67,
+	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x12,
+	0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68,
+	0x61, 0x73, 0x68, 0x22, 0x35, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x42, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x09, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x73, 0x22, 0x2b, 0x0a, 0x13, 0x52, 0x6f,
+	0x74, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x22, 0x2a, 0x0a, 0x12, 0x52, 0x6f, 0x74, 0x61, 0x74,
+	0x65, 0x4c, 0x6f, 0x67, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x6b, 0x65, 0x79, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6b, 0x65,
+	0x79, 0x49, 0x44, 0x22, 0x69, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e,
+	0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x68, 0x61, 0x72, 0x64, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x68, 0x61, 0x72, 0x64,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x73, 0x22, 0x30,
+	0x0a, 0x12, 0x53, 0x65, 0x74, 0x52, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79,
+	0x22, 0x35, 0x0a, 0x11, 0x53, 0x65, 0x74, 0x52, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x77, 0x61, 0x73, 0x52, 0x65, 0x61, 0x64,
+	0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x77, 0x61, 0x73, 0x52,
+	0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x22, 0x19, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x53, 0x74,
+	0x6f, 0x72, 0x61, 0x67, 0x65, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x22, 0xae, 0x01, 0x0a, 0x0d, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4c, 0x61,
+	0x79, 0x6f, 0x75, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x3a, 0x0a, 0x08,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e,
+	0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x08,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x39, 0x0a, 0x09, 0x6d, 0x69, 0x67, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x6f,
+	0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x4d,
+	0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6d, 0x69, 0x67, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x22, 0xdb, 0x01, 0x0a, 0x0f, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x4d, 0x69,
+	0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x1c, 0x0a, 0x09, 0x6e,
+	0x65, 0x78, 0x74, 0x4c, 0x6f, 0x67, 0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x6e, 0x65, 0x78, 0x74, 0x4c, 0x6f, 0x67, 0x49, 0x44, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x69, 0x67,
+	0x72, 0x61, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x69, 0x67,
+	0x72, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x4c,
+	0x6f, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x66, 0x61, 0x69, 0x6c, 0x65,
+	0x64, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x3a, 0x0a, 0x0a, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65,
+	0x64, 0x41, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x41,
+	0x74, 0x22, 0x5a, 0x0a, 0x12, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x52, 0x0a,
+	0x10, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x49, 0x44, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x49, 0x44,
+	0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x66, 0x72, 0x65, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x66, 0x72, 0x65, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x22, 0x4f, 0x0a, 0x0b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x65, 0x78, 0x61, 0x63, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x78, 0x61,
+	0x63, 0x74, 0x22, 0xb9, 0x05, 0x0a, 0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x6c, 0x6f,
+	0x67, 0x73, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x6c, 0x6f, 0x67, 0x73, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16,
+	0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06,
+	0x6c, 0x6f, 0x67, 0x49, 0x44, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x73, 0x63, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x64, 0x65, 0x73, 0x63,
+	0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x24, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x6d, 0x69, 0x6e, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x4c, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x6d, 0x69, 0x6e, 0x50, 0x61,
+	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x65, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x50,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x65, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0d, 0x6d, 0x61, 0x78, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x65, 0x6e, 0x12, 0x28,
+	0x0a, 0x0f, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x61, 0x6e, 0x64, 0x6c,
+	0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x69, 0x74, 0x68,
+	0x41, 0x67, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x77, 0x69, 0x74, 0x68, 0x41,
+	0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x53, 0x65, 0x71, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x53, 0x65, 0x71, 0x12, 0x1c,
+	0x0a, 0x09, 0x6d, 0x61, 0x78, 0x50, 0x65, 0x72, 0x4c, 0x6f, 0x67, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x50, 0x65, 0x72, 0x4c, 0x6f, 0x67, 0x12, 0x14, 0x0a, 0x05,
+	0x61, 0x73, 0x41, 0x6e, 0x79, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x61, 0x73, 0x41,
+	0x6e, 0x79, 0x12, 0x3e, 0x0a, 0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x66, 0x74,
+	0x65, 0x72, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x66, 0x74,
+	0x65, 0x72, 0x12, 0x40, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x74, 0x61, 0x72, 0x74, 0x45, 0x78, 0x63,
+	0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x45, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x12, 0x24, 0x0a, 0x0d,
+	0x73, 0x6b, 0x69, 0x70, 0x43, 0x6f, 0x72, 0x72, 0x75, 0x70, 0x74, 0x65, 0x64, 0x18, 0x11, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0d, 0x73, 0x6b, 0x69, 0x70, 0x43, 0x6f, 0x72, 0x72, 0x75, 0x70, 0x74,
+	0x65, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x42, 0x75, 0x64, 0x67, 0x65,
+	0x74, 0x4d, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x4d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x64, 0x75,
+	0x70, 0x18, 0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x64, 0x65, 0x64, 0x75, 0x70, 0x22, 0x77,
+	0x0a, 0x14, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x35, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x28, 0x0a,
+	0x0f, 0x6d, 0x61, 0x78, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6d, 0x61, 0x78, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x37, 0x0a, 0x17, 0x43, 0x6f, 0x6d, 0x70, 0x69,
+	0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x22, 0x65, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x64, 0x43, 0x6f, 0x6e, 0x64,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x38, 0x0a,
+	0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22, 0x34, 0x0a, 0x1a, 0x49, 0x6e, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x22, 0x3d, 0x0a,
+	0x19, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x64, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x6e,
+	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0b, 0x69, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x64, 0x22, 0x5d, 0x0a, 0x11,
+	0x46, 0x69, 0x65, 0x6c, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x44, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x61, 0x6d,
+	0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x70, 0x4e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x70, 0x4e, 0x22, 0x82, 0x01, 0x0a, 0x10,
+	0x46, 0x69, 0x65, 0x6c, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x64,
+	0x12, 0x2e, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69,
+	0x65, 0x6c, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73,
+	0x22, 0x8e, 0x01, 0x0a, 0x0a, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x61, 0x72,
+	0x64, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b,
+	0x63, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x34, 0x0a, 0x09, 0x74,
+	0x6f, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x09, 0x74, 0x6f, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x22, 0x38, 0x0a, 0x0a, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x62, 0x0a, 0x12, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x2c, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12,
+	0x1e, 0x0a, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x49, 0x44, 0x2a,
+	0x56, 0x0a, 0x0a, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x17, 0x0a,
+	0x13, 0x41, 0x50, 0x50, 0x45, 0x4e, 0x44, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x44, 0x45, 0x46,
+	0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x41, 0x50, 0x50, 0x45, 0x4e, 0x44,
+	0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x41, 0x4c, 0x10, 0x01, 0x12,
+	0x16, 0x0a, 0x12, 0x41, 0x50, 0x50, 0x45, 0x4e, 0x44, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x41,
+	0x54, 0x4f, 0x4d, 0x49, 0x43, 0x10, 0x02, 0x2a, 0x61, 0x0a, 0x0c, 0x52, 0x65, 0x6a, 0x65, 0x63,
+	0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x13, 0x52, 0x45, 0x4a, 0x45, 0x43,
+	0x54, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x00,
+	0x12, 0x1b, 0x0a, 0x17, 0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f,
+	0x4e, 0x5f, 0x45, 0x58, 0x48, 0x41, 0x55, 0x53, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x1b, 0x0a,
+	0x17, 0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x54,
+	0x4f, 0x4f, 0x5f, 0x4c, 0x41, 0x52, 0x47, 0x45, 0x10, 0x02, 0x32, 0x9c, 0x0c, 0x0a, 0x07, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2d, 0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x4c, 0x6f, 0x67, 0x12, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x6f, 0x67, 0x1a, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x12, 0x49, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c,
+	0x6f, 0x67, 0x73, 0x12, 0x1d, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x2d, 0x0a, 0x09, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x12, 0x0f, 0x2e,
+	0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x1a, 0x0f,
+	0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x12,
+	0x34, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x12, 0x19, 0x2e, 0x73, 0x6f, 0x6c, 0x61,
+	0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x12, 0x46, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4c, 0x6f,
+	0x67, 0x73, 0x12, 0x1c, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1b, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x49, 0x0a,
+	0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x1d, 0x2e, 0x73, 0x6f,
+	0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c,
+	0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x6f, 0x6c,
+	0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f,
+	0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x52, 0x0a, 0x0d, 0x41, 0x70, 0x70, 0x65,
+	0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x20, 0x2e, 0x73, 0x6f, 0x6c, 0x61,
+	0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x6f,
+	0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x5a, 0x0a, 0x13,
+	0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x12, 0x20, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x28, 0x01, 0x12, 0x4f, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x1f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72,
+	0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x6f, 0x6c, 0x61,
+	0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x48, 0x0a, 0x0c, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x1f, 0x2e, 0x73, 0x6f, 0x6c, 0x61,
+	0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x73, 0x6f, 0x6c,
+	0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x53, 0x0a, 0x0d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x12, 0x20, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x30, 0x01, 0x12, 0x56, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x70,
+	0x69, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x2e, 0x73,
+	0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c,
+	0x65, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x64, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x64, 0x0a, 0x13, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f,
+	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43,
+	0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x25, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x49, 0x0a, 0x0a, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x1d, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x4c, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x52, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79,
+	0x12, 0x1e, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
+	0x74, 0x52, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1d, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
+	0x74, 0x52, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x52, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4c, 0x61, 0x79,
+	0x6f, 0x75, 0x74, 0x12, 0x23, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4c, 0x61, 0x79, 0x6f, 0x75,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72,
+	0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4c, 0x61, 0x79,
+	0x6f, 0x75, 0x74, 0x12, 0x58, 0x0a, 0x0f, 0x51, 0x75, 0x65, 0x72, 0x79, 0x41, 0x63, 0x74, 0x69,
+	0x76, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x22, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x4c,
+	0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x73, 0x6f, 0x6c,
+	0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x41, 0x63, 0x74,
+	0x69, 0x76, 0x65, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x4f, 0x0a,
+	0x0c, 0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x50, 0x65, 0x72, 0x4c, 0x6f, 0x67, 0x12, 0x1f, 0x2e,
+	0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x74, 0x65, 0x73,
+	0x74, 0x50, 0x65, 0x72, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e,
+	0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x74, 0x65,
+	0x73, 0x74, 0x50, 0x65, 0x72, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x58,
+	0x0a, 0x0f, 0x47, 0x65, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x79, 0x48, 0x61, 0x73,
+	0x68, 0x12, 0x22, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x79, 0x48, 0x61,
+	0x73, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x4f, 0x0a, 0x0c, 0x52, 0x6f, 0x74, 0x61,
+	0x74, 0x65, 0x4c, 0x6f, 0x67, 0x4b, 0x65, 0x79, 0x12, 0x1f, 0x2e, 0x73, 0x6f, 0x6c, 0x61, 0x72,
+	0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x4b,
+	0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x6f, 0x6c, 0x61,
+	0x72, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67,
+	0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x16, 0x5a, 0x14, 0x2e, 0x2f, 0x73,
+	0x6f, 0x6c, 0x61, 0x72, 0x69, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x73, 0x6f, 0x6c, 0x61, 0x72, 0x69,
+	0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -875,49 +3309,132 @@ func file_solaris_proto_rawDescGZIP() []byte {
 	return file_solaris_proto_rawDescData
 }
 
-var file_solaris_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_solaris_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_solaris_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_solaris_proto_goTypes = []interface{}{
-	(*Record)(nil),                // 0: solaris.v1.Record
-	(*Log)(nil),                   // 1: solaris.v1.Log
-	(*AppendRecordsRequest)(nil),  // 2: solaris.v1.AppendRecordsRequest
-	(*AppendRecordsResult)(nil),   // 3: solaris.v1.AppendRecordsResult
-	(*QueryLogsRequest)(nil),      // 4: solaris.v1.QueryLogsRequest
-	(*QueryLogsResult)(nil),       // 5: solaris.v1.QueryLogsResult
-	(*DeleteLogsRequest)(nil),     // 6: solaris.v1.DeleteLogsRequest
-	(*DeleteLogsResult)(nil),      // 7: solaris.v1.DeleteLogsResult
-	(*CountResult)(nil),           // 8: solaris.v1.CountResult
-	(*QueryRecordsRequest)(nil),   // 9: solaris.v1.QueryRecordsRequest
-	(*QueryRecordsResult)(nil),    // 10: solaris.v1.QueryRecordsResult
-	nil,                           // 11: solaris.v1.Log.TagsEntry
-	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(AppendMode)(0),                    // 0: solaris.v1.AppendMode
+	(RejectReason)(0),                  // 1: solaris.v1.RejectReason
+	(*Record)(nil),                     // 2: solaris.v1.Record
+	(*Log)(nil),                        // 3: solaris.v1.Log
+	(*CreateLogsRequest)(nil),          // 4: solaris.v1.CreateLogsRequest
+	(*CreateLogsResult)(nil),           // 5: solaris.v1.CreateLogsResult
+	(*GetLogRequest)(nil),              // 6: solaris.v1.GetLogRequest
+	(*AppendRecordsRequest)(nil),       // 7: solaris.v1.AppendRecordsRequest
+	(*AppendRejected)(nil),             // 8: solaris.v1.AppendRejected
+	(*AppendRecordsResult)(nil),        // 9: solaris.v1.AppendRecordsResult
+	(*QueryLogsRequest)(nil),           // 10: solaris.v1.QueryLogsRequest
+	(*QueryLogsResult)(nil),            // 11: solaris.v1.QueryLogsResult
+	(*QueryActiveLogsRequest)(nil),     // 12: solaris.v1.QueryActiveLogsRequest
+	(*QueryActiveLogsResult)(nil),      // 13: solaris.v1.QueryActiveLogsResult
+	(*LatestPerLogRequest)(nil),        // 14: solaris.v1.LatestPerLogRequest
+	(*LatestPerLogResult)(nil),         // 15: solaris.v1.LatestPerLogResult
+	(*GetRecordByHashRequest)(nil),     // 16: solaris.v1.GetRecordByHashRequest
+	(*GetRecordByHashResult)(nil),      // 17: solaris.v1.GetRecordByHashResult
+	(*RotateLogKeyRequest)(nil),        // 18: solaris.v1.RotateLogKeyRequest
+	(*RotateLogKeyResult)(nil),         // 19: solaris.v1.RotateLogKeyResult
+	(*DeleteLogsRequest)(nil),          // 20: solaris.v1.DeleteLogsRequest
+	(*SetReadOnlyRequest)(nil),         // 21: solaris.v1.SetReadOnlyRequest
+	(*SetReadOnlyResult)(nil),          // 22: solaris.v1.SetReadOnlyResult
+	(*GetStorageLayoutRequest)(nil),    // 23: solaris.v1.GetStorageLayoutRequest
+	(*StorageLayout)(nil),              // 24: solaris.v1.StorageLayout
+	(*FormatMigration)(nil),            // 25: solaris.v1.FormatMigration
+	(*FormatVersionStats)(nil),         // 26: solaris.v1.FormatVersionStats
+	(*DeleteLogsResult)(nil),           // 27: solaris.v1.DeleteLogsResult
+	(*CountResult)(nil),                // 28: solaris.v1.CountResult
+	(*QueryRecordsRequest)(nil),        // 29: solaris.v1.QueryRecordsRequest
+	(*StreamRecordsRequest)(nil),       // 30: solaris.v1.StreamRecordsRequest
+	(*CompileConditionRequest)(nil),    // 31: solaris.v1.CompileConditionRequest
+	(*CompiledCondition)(nil),          // 32: solaris.v1.CompiledCondition
+	(*InvalidateConditionRequest)(nil), // 33: solaris.v1.InvalidateConditionRequest
+	(*InvalidateConditionResult)(nil),  // 34: solaris.v1.InvalidateConditionResult
+	(*FieldStatsRequest)(nil),          // 35: solaris.v1.FieldStatsRequest
+	(*FieldStatsResult)(nil),           // 36: solaris.v1.FieldStatsResult
+	(*FieldStats)(nil),                 // 37: solaris.v1.FieldStats
+	(*ValueCount)(nil),                 // 38: solaris.v1.ValueCount
+	(*QueryRecordsResult)(nil),         // 39: solaris.v1.QueryRecordsResult
+	nil,                                // 40: solaris.v1.Record.AttributesEntry
+	nil,                                // 41: solaris.v1.Log.TagsEntry
+	nil,                                // 42: solaris.v1.LatestPerLogResult.RecordsEntry
+	(*timestamppb.Timestamp)(nil),      // 43: google.protobuf.Timestamp
+	(*anypb.Any)(nil),                  // 44: google.protobuf.Any
+	(*durationpb.Duration)(nil),        // 45: google.protobuf.Duration
 }
 var file_solaris_proto_depIdxs = []int32{
-	12, // 0: solaris.v1.Record.createdAt:type_name -> google.protobuf.Timestamp
-	11, // 1: solaris.v1.Log.tags:type_name -> solaris.v1.Log.TagsEntry
-	12, // 2: solaris.v1.Log.createdAt:type_name -> google.protobuf.Timestamp
-	12, // 3: solaris.v1.Log.updatedAt:type_name -> google.protobuf.Timestamp
-	0,  // 4: solaris.v1.AppendRecordsRequest.records:type_name -> solaris.v1.Record
-	1,  // 5: solaris.v1.QueryLogsResult.logs:type_name -> solaris.v1.Log
-	0,  // 6: solaris.v1.QueryRecordsResult.records:type_name -> solaris.v1.Record
-	1,  // 7: solaris.v1.Service.CreateLog:input_type -> solaris.v1.Log
-	1,  // 8: solaris.v1.Service.UpdateLog:input_type -> solaris.v1.Log
-	4,  // 9: solaris.v1.Service.QueryLogs:input_type -> solaris.v1.QueryLogsRequest
-	6,  // 10: solaris.v1.Service.DeleteLogs:input_type -> solaris.v1.DeleteLogsRequest
-	2,  // 11: solaris.v1.Service.AppendRecords:input_type -> solaris.v1.AppendRecordsRequest
-	9,  // 12: solaris.v1.Service.QueryRecords:input_type -> solaris.v1.QueryRecordsRequest
-	9,  // 13: solaris.v1.Service.CountRecords:input_type -> solaris.v1.QueryRecordsRequest
-	1,  // 14: solaris.v1.Service.CreateLog:output_type -> solaris.v1.Log
-	1,  // 15: solaris.v1.Service.UpdateLog:output_type -> solaris.v1.Log
-	5,  // 16: solaris.v1.Service.QueryLogs:output_type -> solaris.v1.QueryLogsResult
-	7,  // 17: solaris.v1.Service.DeleteLogs:output_type -> solaris.v1.DeleteLogsResult
-	3,  // 18: solaris.v1.Service.AppendRecords:output_type -> solaris.v1.AppendRecordsResult
-	10, // 19: solaris.v1.Service.QueryRecords:output_type -> solaris.v1.QueryRecordsResult
-	8,  // 20: solaris.v1.Service.CountRecords:output_type -> solaris.v1.CountResult
-	14, // [14:21] is the sub-list for method output_type
-	7,  // [7:14] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	43, // 0: solaris.v1.Record.createdAt:type_name -> google.protobuf.Timestamp
+	44, // 1: solaris.v1.Record.any:type_name -> google.protobuf.Any
+	40, // 2: solaris.v1.Record.attributes:type_name -> solaris.v1.Record.AttributesEntry
+	41, // 3: solaris.v1.Log.tags:type_name -> solaris.v1.Log.TagsEntry
+	43, // 4: solaris.v1.Log.createdAt:type_name -> google.protobuf.Timestamp
+	43, // 5: solaris.v1.Log.updatedAt:type_name -> google.protobuf.Timestamp
+	45, // 6: solaris.v1.Log.retentionMaxAge:type_name -> google.protobuf.Duration
+	3,  // 7: solaris.v1.CreateLogsRequest.logs:type_name -> solaris.v1.Log
+	3,  // 8: solaris.v1.CreateLogsResult.logs:type_name -> solaris.v1.Log
+	2,  // 9: solaris.v1.AppendRecordsRequest.records:type_name -> solaris.v1.Record
+	0,  // 10: solaris.v1.AppendRecordsRequest.mode:type_name -> solaris.v1.AppendMode
+	1,  // 11: solaris.v1.AppendRejected.reason:type_name -> solaris.v1.RejectReason
+	8,  // 12: solaris.v1.AppendRecordsResult.rejected:type_name -> solaris.v1.AppendRejected
+	43, // 13: solaris.v1.QueryLogsRequest.createdAfter:type_name -> google.protobuf.Timestamp
+	43, // 14: solaris.v1.QueryLogsRequest.createdBefore:type_name -> google.protobuf.Timestamp
+	3,  // 15: solaris.v1.QueryLogsResult.logs:type_name -> solaris.v1.Log
+	43, // 16: solaris.v1.QueryActiveLogsRequest.createdAfter:type_name -> google.protobuf.Timestamp
+	43, // 17: solaris.v1.QueryActiveLogsRequest.createdBefore:type_name -> google.protobuf.Timestamp
+	42, // 18: solaris.v1.LatestPerLogResult.records:type_name -> solaris.v1.LatestPerLogResult.RecordsEntry
+	26, // 19: solaris.v1.StorageLayout.versions:type_name -> solaris.v1.FormatVersionStats
+	25, // 20: solaris.v1.StorageLayout.migration:type_name -> solaris.v1.FormatMigration
+	43, // 21: solaris.v1.FormatMigration.finishedAt:type_name -> google.protobuf.Timestamp
+	43, // 22: solaris.v1.QueryRecordsRequest.createdAfter:type_name -> google.protobuf.Timestamp
+	43, // 23: solaris.v1.QueryRecordsRequest.createdBefore:type_name -> google.protobuf.Timestamp
+	29, // 24: solaris.v1.StreamRecordsRequest.query:type_name -> solaris.v1.QueryRecordsRequest
+	43, // 25: solaris.v1.CompiledCondition.expiresAt:type_name -> google.protobuf.Timestamp
+	37, // 26: solaris.v1.FieldStatsResult.fields:type_name -> solaris.v1.FieldStats
+	38, // 27: solaris.v1.FieldStats.topValues:type_name -> solaris.v1.ValueCount
+	2,  // 28: solaris.v1.QueryRecordsResult.records:type_name -> solaris.v1.Record
+	2,  // 29: solaris.v1.LatestPerLogResult.RecordsEntry.value:type_name -> solaris.v1.Record
+	3,  // 30: solaris.v1.Service.CreateLog:input_type -> solaris.v1.Log
+	4,  // 31: solaris.v1.Service.CreateLogs:input_type -> solaris.v1.CreateLogsRequest
+	3,  // 32: solaris.v1.Service.UpdateLog:input_type -> solaris.v1.Log
+	6,  // 33: solaris.v1.Service.GetLog:input_type -> solaris.v1.GetLogRequest
+	10, // 34: solaris.v1.Service.QueryLogs:input_type -> solaris.v1.QueryLogsRequest
+	20, // 35: solaris.v1.Service.DeleteLogs:input_type -> solaris.v1.DeleteLogsRequest
+	7,  // 36: solaris.v1.Service.AppendRecords:input_type -> solaris.v1.AppendRecordsRequest
+	7,  // 37: solaris.v1.Service.AppendRecordsStream:input_type -> solaris.v1.AppendRecordsRequest
+	29, // 38: solaris.v1.Service.QueryRecords:input_type -> solaris.v1.QueryRecordsRequest
+	29, // 39: solaris.v1.Service.CountRecords:input_type -> solaris.v1.QueryRecordsRequest
+	30, // 40: solaris.v1.Service.StreamRecords:input_type -> solaris.v1.StreamRecordsRequest
+	31, // 41: solaris.v1.Service.CompileCondition:input_type -> solaris.v1.CompileConditionRequest
+	33, // 42: solaris.v1.Service.InvalidateCondition:input_type -> solaris.v1.InvalidateConditionRequest
+	35, // 43: solaris.v1.Service.FieldStats:input_type -> solaris.v1.FieldStatsRequest
+	21, // 44: solaris.v1.Service.SetReadOnly:input_type -> solaris.v1.SetReadOnlyRequest
+	23, // 45: solaris.v1.Service.GetStorageLayout:input_type -> solaris.v1.GetStorageLayoutRequest
+	12, // 46: solaris.v1.Service.QueryActiveLogs:input_type -> solaris.v1.QueryActiveLogsRequest
+	14, // 47: solaris.v1.Service.LatestPerLog:input_type -> solaris.v1.LatestPerLogRequest
+	16, // 48: solaris.v1.Service.GetRecordByHash:input_type -> solaris.v1.GetRecordByHashRequest
+	18, // 49: solaris.v1.Service.RotateLogKey:input_type -> solaris.v1.RotateLogKeyRequest
+	3,  // 50: solaris.v1.Service.CreateLog:output_type -> solaris.v1.Log
+	5,  // 51: solaris.v1.Service.CreateLogs:output_type -> solaris.v1.CreateLogsResult
+	3,  // 52: solaris.v1.Service.UpdateLog:output_type -> solaris.v1.Log
+	3,  // 53: solaris.v1.Service.GetLog:output_type -> solaris.v1.Log
+	11, // 54: solaris.v1.Service.QueryLogs:output_type -> solaris.v1.QueryLogsResult
+	27, // 55: solaris.v1.Service.DeleteLogs:output_type -> solaris.v1.DeleteLogsResult
+	9,  // 56: solaris.v1.Service.AppendRecords:output_type -> solaris.v1.AppendRecordsResult
+	9,  // 57: solaris.v1.Service.AppendRecordsStream:output_type -> solaris.v1.AppendRecordsResult
+	39, // 58: solaris.v1.Service.QueryRecords:output_type -> solaris.v1.QueryRecordsResult
+	28, // 59: solaris.v1.Service.CountRecords:output_type -> solaris.v1.CountResult
+	39, // 60: solaris.v1.Service.StreamRecords:output_type -> solaris.v1.QueryRecordsResult
+	32, // 61: solaris.v1.Service.CompileCondition:output_type -> solaris.v1.CompiledCondition
+	34, // 62: solaris.v1.Service.InvalidateCondition:output_type -> solaris.v1.InvalidateConditionResult
+	36, // 63: solaris.v1.Service.FieldStats:output_type -> solaris.v1.FieldStatsResult
+	22, // 64: solaris.v1.Service.SetReadOnly:output_type -> solaris.v1.SetReadOnlyResult
+	24, // 65: solaris.v1.Service.GetStorageLayout:output_type -> solaris.v1.StorageLayout
+	13, // 66: solaris.v1.Service.QueryActiveLogs:output_type -> solaris.v1.QueryActiveLogsResult
+	15, // 67: solaris.v1.Service.LatestPerLog:output_type -> solaris.v1.LatestPerLogResult
+	17, // 68: solaris.v1.Service.GetRecordByHash:output_type -> solaris.v1.GetRecordByHashResult
+	19, // 69: solaris.v1.Service.RotateLogKey:output_type -> solaris.v1.RotateLogKeyResult
+	50, // [50:70] is the sub-list for method output_type
+	30, // [30:50] is the sub-list for method input_type
+	30, // [30:30] is the sub-list for extension type_name
+	30, // [30:30] is the sub-list for extension extendee
+	0,  // [0:30] is the sub-list for field type_name
 }
 
 func init() { file_solaris_proto_init() }
@@ -938,8 +3455,56 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Log); i {
+		file_solaris_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Log); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateLogsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateLogsResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLogRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AppendRecordsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -950,8 +3515,8 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AppendRecordsRequest); i {
+		file_solaris_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AppendRejected); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -962,7 +3527,7 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_solaris_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AppendRecordsResult); i {
 			case 0:
 				return &v.state
@@ -974,7 +3539,7 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_solaris_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*QueryLogsRequest); i {
 			case 0:
 				return &v.state
@@ -986,7 +3551,7 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_solaris_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*QueryLogsResult); i {
 			case 0:
 				return &v.state
@@ -998,7 +3563,103 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_solaris_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryActiveLogsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryActiveLogsResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LatestPerLogRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LatestPerLogResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRecordByHashRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRecordByHashResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RotateLogKeyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RotateLogKeyResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DeleteLogsRequest); i {
 			case 0:
 				return &v.state
@@ -1010,7 +3671,79 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_solaris_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetReadOnlyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetReadOnlyResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetStorageLayoutRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageLayout); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FormatMigration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FormatVersionStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DeleteLogsResult); i {
 			case 0:
 				return &v.state
@@ -1022,7 +3755,7 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_solaris_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CountResult); i {
 			case 0:
 				return &v.state
@@ -1034,7 +3767,7 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_solaris_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*QueryRecordsRequest); i {
 			case 0:
 				return &v.state
@@ -1046,7 +3779,115 @@ func file_solaris_proto_init() {
 				return nil
 			}
 		}
-		file_solaris_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_solaris_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamRecordsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompileConditionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompiledCondition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InvalidateConditionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InvalidateConditionResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FieldStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FieldStatsResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FieldStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValueCount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_solaris_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*QueryRecordsResult); i {
 			case 0:
 				return &v.state
@@ -1064,13 +3905,14 @@ func file_solaris_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_solaris_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   12,
+			NumEnums:      2,
+			NumMessages:   41,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_solaris_proto_goTypes,
 		DependencyIndexes: file_solaris_proto_depIdxs,
+		EnumInfos:         file_solaris_proto_enumTypes,
 		MessageInfos:      file_solaris_proto_msgTypes,
 	}.Build()
 	File_solaris_proto = out.File
@@ -19,13 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Service_CreateLog_FullMethodName     = "/solaris.v1.Service/CreateLog"
-	Service_UpdateLog_FullMethodName     = "/solaris.v1.Service/UpdateLog"
-	Service_QueryLogs_FullMethodName     = "/solaris.v1.Service/QueryLogs"
-	Service_DeleteLogs_FullMethodName    = "/solaris.v1.Service/DeleteLogs"
-	Service_AppendRecords_FullMethodName = "/solaris.v1.Service/AppendRecords"
-	Service_QueryRecords_FullMethodName  = "/solaris.v1.Service/QueryRecords"
-	Service_CountRecords_FullMethodName  = "/solaris.v1.Service/CountRecords"
+	Service_CreateLog_FullMethodName           = "/solaris.v1.Service/CreateLog"
+	Service_CreateLogs_FullMethodName          = "/solaris.v1.Service/CreateLogs"
+	Service_UpdateLog_FullMethodName           = "/solaris.v1.Service/UpdateLog"
+	Service_GetLog_FullMethodName              = "/solaris.v1.Service/GetLog"
+	Service_QueryLogs_FullMethodName           = "/solaris.v1.Service/QueryLogs"
+	Service_DeleteLogs_FullMethodName          = "/solaris.v1.Service/DeleteLogs"
+	Service_AppendRecords_FullMethodName       = "/solaris.v1.Service/AppendRecords"
+	Service_AppendRecordsStream_FullMethodName = "/solaris.v1.Service/AppendRecordsStream"
+	Service_QueryRecords_FullMethodName        = "/solaris.v1.Service/QueryRecords"
+	Service_CountRecords_FullMethodName        = "/solaris.v1.Service/CountRecords"
+	Service_StreamRecords_FullMethodName       = "/solaris.v1.Service/StreamRecords"
+	Service_CompileCondition_FullMethodName    = "/solaris.v1.Service/CompileCondition"
+	Service_InvalidateCondition_FullMethodName = "/solaris.v1.Service/InvalidateCondition"
+	Service_FieldStats_FullMethodName          = "/solaris.v1.Service/FieldStats"
+	Service_SetReadOnly_FullMethodName         = "/solaris.v1.Service/SetReadOnly"
+	Service_GetStorageLayout_FullMethodName    = "/solaris.v1.Service/GetStorageLayout"
+	Service_QueryActiveLogs_FullMethodName     = "/solaris.v1.Service/QueryActiveLogs"
+	Service_LatestPerLog_FullMethodName        = "/solaris.v1.Service/LatestPerLog"
+	Service_GetRecordByHash_FullMethodName     = "/solaris.v1.Service/GetRecordByHash"
+	Service_RotateLogKey_FullMethodName        = "/solaris.v1.Service/RotateLogKey"
 )
 
 // ServiceClient is the client API for Service service.
@@ -34,19 +47,61 @@ const (
 type ServiceClient interface {
 	// CreateLog creates then new log
 	CreateLog(ctx context.Context, in *Log, opts ...grpc.CallOption) (*Log, error)
-	// UpdateLog changes the log settings (tags)
+	// CreateLogs creates the logs in one transaction, either all the logs are created or none of them
+	CreateLogs(ctx context.Context, in *CreateLogsRequest, opts ...grpc.CallOption) (*CreateLogsResult, error)
+	// UpdateLog changes the log settings (tags, validateUTF8, payloadTypeURL, maxRecords, payloadHash and the retention policy)
 	UpdateLog(ctx context.Context, in *Log, opts ...grpc.CallOption) (*Log, error)
+	// GetLog returns the log by its ID
+	GetLog(ctx context.Context, in *GetLogRequest, opts ...grpc.CallOption) (*Log, error)
 	// QueryLogs requests list of logs by the query request ordered by the log IDs ascending order
 	QueryLogs(ctx context.Context, in *QueryLogsRequest, opts ...grpc.CallOption) (*QueryLogsResult, error)
 	// DeleteLogs removes one or more logs
 	DeleteLogs(ctx context.Context, in *DeleteLogsRequest, opts ...grpc.CallOption) (*DeleteLogsResult, error)
 	// AppendRecords appends a bunch of records to the log
 	AppendRecords(ctx context.Context, in *AppendRecordsRequest, opts ...grpc.CallOption) (*AppendRecordsResult, error)
+	// AppendRecordsStream appends the batches of records received from the stream one by one, the same way
+	// AppendRecords does, and returns the total result when the stream is closed. The batches may be appended
+	// to different logs. If a batch is not appended, the error tells which one, the previous batches stay added.
+	AppendRecordsStream(ctx context.Context, opts ...grpc.CallOption) (Service_AppendRecordsStreamClient, error)
 	// QueryRecords read records from one or many logs, merging them together into the result set
 	// sorted in ascending or descending order by the records IDs (timestamps)
 	QueryRecords(ctx context.Context, in *QueryRecordsRequest, opts ...grpc.CallOption) (*QueryRecordsResult, error)
 	// CountRecords allows to count the number of records that matches QueryRecordsRequest
 	CountRecords(ctx context.Context, in *QueryRecordsRequest, opts ...grpc.CallOption) (*CountResult, error)
+	// StreamRecords reads records the same way as QueryRecords does, but sends the result set as a stream of
+	// messages, every message carries no more than the requested number of payload bytes
+	StreamRecords(ctx context.Context, in *StreamRecordsRequest, opts ...grpc.CallOption) (Service_StreamRecordsClient, error)
+	// CompileCondition parses the records condition and keeps the result on the server side for a while, so
+	// the condition may be referred by the returned handle in QueryRecords and CountRecords requests
+	// without parsing it every time
+	CompileCondition(ctx context.Context, in *CompileConditionRequest, opts ...grpc.CallOption) (*CompiledCondition, error)
+	// InvalidateCondition removes the compiled condition by its handle
+	InvalidateCondition(ctx context.Context, in *InvalidateConditionRequest, opts ...grpc.CallOption) (*InvalidateConditionResult, error)
+	// FieldStats samples the log records and returns the estimated statistics of the records fields. The fields
+	// are the top-level keys of the records with JSON object payloads, the other records are not considered.
+	FieldStats(ctx context.Context, in *FieldStatsRequest, opts ...grpc.CallOption) (*FieldStatsResult, error)
+	// SetReadOnly turns the server read-only mode on or off. In the read-only mode the requests changing
+	// the logs or their records (CreateLog, UpdateLog, DeleteLogs and AppendRecords) are rejected with
+	// the FailedPrecondition error, the other requests are served as usual. If the authentication is enabled,
+	// only the admin clients may run the request.
+	SetReadOnly(ctx context.Context, in *SetReadOnlyRequest, opts ...grpc.CallOption) (*SetReadOnlyResult, error)
+	// GetStorageLayout returns the chunks format versions found in the server local storage and the status
+	// of the chunks format migration, so it could be checked whether the data is stored in the current format.
+	// If the authentication is enabled, only the admin clients may run the request.
+	GetStorageLayout(ctx context.Context, in *GetStorageLayoutRequest, opts ...grpc.CallOption) (*StorageLayout, error)
+	// QueryActiveLogs returns the IDs of the logs, which match the logs condition and have at least one record
+	// created in the time window, ordered by the log IDs ascending order
+	QueryActiveLogs(ctx context.Context, in *QueryActiveLogsRequest, opts ...grpc.CallOption) (*QueryActiveLogsResult, error)
+	// LatestPerLog returns the most recent record of every log matching the logs condition, the logs
+	// without records are skipped
+	LatestPerLog(ctx context.Context, in *LatestPerLogRequest, opts ...grpc.CallOption) (*LatestPerLogResult, error)
+	// GetRecordByHash returns the IDs of the log records with the payload hash provided. The log must have
+	// the payload hash index enabled (see Log.payloadHash). The records removed from the log are not returned.
+	GetRecordByHash(ctx context.Context, in *GetRecordByHashRequest, opts ...grpc.CallOption) (*GetRecordByHashResult, error)
+	// RotateLogKey makes a new key for the log records encryption. The records appended after the request are
+	// written into the new chunks encrypted with the new key, the existing records are still read with the keys
+	// they were written with. If the authentication is enabled, only the admin clients may run the request.
+	RotateLogKey(ctx context.Context, in *RotateLogKeyRequest, opts ...grpc.CallOption) (*RotateLogKeyResult, error)
 }
 
 type serviceClient struct {
@@ -66,6 +121,15 @@ func (c *serviceClient) CreateLog(ctx context.Context, in *Log, opts ...grpc.Cal
 	return out, nil
 }
 
+func (c *serviceClient) CreateLogs(ctx context.Context, in *CreateLogsRequest, opts ...grpc.CallOption) (*CreateLogsResult, error) {
+	out := new(CreateLogsResult)
+	err := c.cc.Invoke(ctx, Service_CreateLogs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) UpdateLog(ctx context.Context, in *Log, opts ...grpc.CallOption) (*Log, error) {
 	out := new(Log)
 	err := c.cc.Invoke(ctx, Service_UpdateLog_FullMethodName, in, out, opts...)
@@ -75,6 +139,15 @@ func (c *serviceClient) UpdateLog(ctx context.Context, in *Log, opts ...grpc.Cal
 	return out, nil
 }
 
+func (c *serviceClient) GetLog(ctx context.Context, in *GetLogRequest, opts ...grpc.CallOption) (*Log, error) {
+	out := new(Log)
+	err := c.cc.Invoke(ctx, Service_GetLog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) QueryLogs(ctx context.Context, in *QueryLogsRequest, opts ...grpc.CallOption) (*QueryLogsResult, error) {
 	out := new(QueryLogsResult)
 	err := c.cc.Invoke(ctx, Service_QueryLogs_FullMethodName, in, out, opts...)
@@ -102,6 +175,40 @@ func (c *serviceClient) AppendRecords(ctx context.Context, in *AppendRecordsRequ
 	return out, nil
 }
 
+func (c *serviceClient) AppendRecordsStream(ctx context.Context, opts ...grpc.CallOption) (Service_AppendRecordsStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Service_ServiceDesc.Streams[0], Service_AppendRecordsStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceAppendRecordsStreamClient{stream}
+	return x, nil
+}
+
+type Service_AppendRecordsStreamClient interface {
+	Send(*AppendRecordsRequest) error
+	CloseAndRecv() (*AppendRecordsResult, error)
+	grpc.ClientStream
+}
+
+type serviceAppendRecordsStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceAppendRecordsStreamClient) Send(m *AppendRecordsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serviceAppendRecordsStreamClient) CloseAndRecv() (*AppendRecordsResult, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(AppendRecordsResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *serviceClient) QueryRecords(ctx context.Context, in *QueryRecordsRequest, opts ...grpc.CallOption) (*QueryRecordsResult, error) {
 	out := new(QueryRecordsResult)
 	err := c.cc.Invoke(ctx, Service_QueryRecords_FullMethodName, in, out, opts...)
@@ -120,25 +227,180 @@ func (c *serviceClient) CountRecords(ctx context.Context, in *QueryRecordsReques
 	return out, nil
 }
 
+func (c *serviceClient) StreamRecords(ctx context.Context, in *StreamRecordsRequest, opts ...grpc.CallOption) (Service_StreamRecordsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Service_ServiceDesc.Streams[1], Service_StreamRecords_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceStreamRecordsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Service_StreamRecordsClient interface {
+	Recv() (*QueryRecordsResult, error)
+	grpc.ClientStream
+}
+
+type serviceStreamRecordsClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceStreamRecordsClient) Recv() (*QueryRecordsResult, error) {
+	m := new(QueryRecordsResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceClient) CompileCondition(ctx context.Context, in *CompileConditionRequest, opts ...grpc.CallOption) (*CompiledCondition, error) {
+	out := new(CompiledCondition)
+	err := c.cc.Invoke(ctx, Service_CompileCondition_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) InvalidateCondition(ctx context.Context, in *InvalidateConditionRequest, opts ...grpc.CallOption) (*InvalidateConditionResult, error) {
+	out := new(InvalidateConditionResult)
+	err := c.cc.Invoke(ctx, Service_InvalidateCondition_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) FieldStats(ctx context.Context, in *FieldStatsRequest, opts ...grpc.CallOption) (*FieldStatsResult, error) {
+	out := new(FieldStatsResult)
+	err := c.cc.Invoke(ctx, Service_FieldStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) SetReadOnly(ctx context.Context, in *SetReadOnlyRequest, opts ...grpc.CallOption) (*SetReadOnlyResult, error) {
+	out := new(SetReadOnlyResult)
+	err := c.cc.Invoke(ctx, Service_SetReadOnly_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetStorageLayout(ctx context.Context, in *GetStorageLayoutRequest, opts ...grpc.CallOption) (*StorageLayout, error) {
+	out := new(StorageLayout)
+	err := c.cc.Invoke(ctx, Service_GetStorageLayout_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) QueryActiveLogs(ctx context.Context, in *QueryActiveLogsRequest, opts ...grpc.CallOption) (*QueryActiveLogsResult, error) {
+	out := new(QueryActiveLogsResult)
+	err := c.cc.Invoke(ctx, Service_QueryActiveLogs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) LatestPerLog(ctx context.Context, in *LatestPerLogRequest, opts ...grpc.CallOption) (*LatestPerLogResult, error) {
+	out := new(LatestPerLogResult)
+	err := c.cc.Invoke(ctx, Service_LatestPerLog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetRecordByHash(ctx context.Context, in *GetRecordByHashRequest, opts ...grpc.CallOption) (*GetRecordByHashResult, error) {
+	out := new(GetRecordByHashResult)
+	err := c.cc.Invoke(ctx, Service_GetRecordByHash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) RotateLogKey(ctx context.Context, in *RotateLogKeyRequest, opts ...grpc.CallOption) (*RotateLogKeyResult, error) {
+	out := new(RotateLogKeyResult)
+	err := c.cc.Invoke(ctx, Service_RotateLogKey_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServiceServer is the server API for Service service.
 // All implementations must embed UnimplementedServiceServer
 // for forward compatibility
 type ServiceServer interface {
 	// CreateLog creates then new log
 	CreateLog(context.Context, *Log) (*Log, error)
-	// UpdateLog changes the log settings (tags)
+	// CreateLogs creates the logs in one transaction, either all the logs are created or none of them
+	CreateLogs(context.Context, *CreateLogsRequest) (*CreateLogsResult, error)
+	// UpdateLog changes the log settings (tags, validateUTF8, payloadTypeURL, maxRecords, payloadHash and the retention policy)
 	UpdateLog(context.Context, *Log) (*Log, error)
+	// GetLog returns the log by its ID
+	GetLog(context.Context, *GetLogRequest) (*Log, error)
 	// QueryLogs requests list of logs by the query request ordered by the log IDs ascending order
 	QueryLogs(context.Context, *QueryLogsRequest) (*QueryLogsResult, error)
 	// DeleteLogs removes one or more logs
 	DeleteLogs(context.Context, *DeleteLogsRequest) (*DeleteLogsResult, error)
 	// AppendRecords appends a bunch of records to the log
 	AppendRecords(context.Context, *AppendRecordsRequest) (*AppendRecordsResult, error)
+	// AppendRecordsStream appends the batches of records received from the stream one by one, the same way
+	// AppendRecords does, and returns the total result when the stream is closed. The batches may be appended
+	// to different logs. If a batch is not appended, the error tells which one, the previous batches stay added.
+	AppendRecordsStream(Service_AppendRecordsStreamServer) error
 	// QueryRecords read records from one or many logs, merging them together into the result set
 	// sorted in ascending or descending order by the records IDs (timestamps)
 	QueryRecords(context.Context, *QueryRecordsRequest) (*QueryRecordsResult, error)
 	// CountRecords allows to count the number of records that matches QueryRecordsRequest
 	CountRecords(context.Context, *QueryRecordsRequest) (*CountResult, error)
+	// StreamRecords reads records the same way as QueryRecords does, but sends the result set as a stream of
+	// messages, every message carries no more than the requested number of payload bytes
+	StreamRecords(*StreamRecordsRequest, Service_StreamRecordsServer) error
+	// CompileCondition parses the records condition and keeps the result on the server side for a while, so
+	// the condition may be referred by the returned handle in QueryRecords and CountRecords requests
+	// without parsing it every time
+	CompileCondition(context.Context, *CompileConditionRequest) (*CompiledCondition, error)
+	// InvalidateCondition removes the compiled condition by its handle
+	InvalidateCondition(context.Context, *InvalidateConditionRequest) (*InvalidateConditionResult, error)
+	// FieldStats samples the log records and returns the estimated statistics of the records fields. The fields
+	// are the top-level keys of the records with JSON object payloads, the other records are not considered.
+	FieldStats(context.Context, *FieldStatsRequest) (*FieldStatsResult, error)
+	// SetReadOnly turns the server read-only mode on or off. In the read-only mode the requests changing
+	// the logs or their records (CreateLog, UpdateLog, DeleteLogs and AppendRecords) are rejected with
+	// the FailedPrecondition error, the other requests are served as usual. If the authentication is enabled,
+	// only the admin clients may run the request.
+	SetReadOnly(context.Context, *SetReadOnlyRequest) (*SetReadOnlyResult, error)
+	// GetStorageLayout returns the chunks format versions found in the server local storage and the status
+	// of the chunks format migration, so it could be checked whether the data is stored in the current format.
+	// If the authentication is enabled, only the admin clients may run the request.
+	GetStorageLayout(context.Context, *GetStorageLayoutRequest) (*StorageLayout, error)
+	// QueryActiveLogs returns the IDs of the logs, which match the logs condition and have at least one record
+	// created in the time window, ordered by the log IDs ascending order
+	QueryActiveLogs(context.Context, *QueryActiveLogsRequest) (*QueryActiveLogsResult, error)
+	// LatestPerLog returns the most recent record of every log matching the logs condition, the logs
+	// without records are skipped
+	LatestPerLog(context.Context, *LatestPerLogRequest) (*LatestPerLogResult, error)
+	// GetRecordByHash returns the IDs of the log records with the payload hash provided. The log must have
+	// the payload hash index enabled (see Log.payloadHash). The records removed from the log are not returned.
+	GetRecordByHash(context.Context, *GetRecordByHashRequest) (*GetRecordByHashResult, error)
+	// RotateLogKey makes a new key for the log records encryption. The records appended after the request are
+	// written into the new chunks encrypted with the new key, the existing records are still read with the keys
+	// they were written with. If the authentication is enabled, only the admin clients may run the request.
+	RotateLogKey(context.Context, *RotateLogKeyRequest) (*RotateLogKeyResult, error)
 	mustEmbedUnimplementedServiceServer()
 }
 
@@ -149,9 +411,15 @@ type UnimplementedServiceServer struct {
 func (UnimplementedServiceServer) CreateLog(context.Context, *Log) (*Log, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateLog not implemented")
 }
+func (UnimplementedServiceServer) CreateLogs(context.Context, *CreateLogsRequest) (*CreateLogsResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateLogs not implemented")
+}
 func (UnimplementedServiceServer) UpdateLog(context.Context, *Log) (*Log, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateLog not implemented")
 }
+func (UnimplementedServiceServer) GetLog(context.Context, *GetLogRequest) (*Log, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLog not implemented")
+}
 func (UnimplementedServiceServer) QueryLogs(context.Context, *QueryLogsRequest) (*QueryLogsResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryLogs not implemented")
 }
@@ -161,12 +429,45 @@ func (UnimplementedServiceServer) DeleteLogs(context.Context, *DeleteLogsRequest
 func (UnimplementedServiceServer) AppendRecords(context.Context, *AppendRecordsRequest) (*AppendRecordsResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AppendRecords not implemented")
 }
+func (UnimplementedServiceServer) AppendRecordsStream(Service_AppendRecordsStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method AppendRecordsStream not implemented")
+}
 func (UnimplementedServiceServer) QueryRecords(context.Context, *QueryRecordsRequest) (*QueryRecordsResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryRecords not implemented")
 }
 func (UnimplementedServiceServer) CountRecords(context.Context, *QueryRecordsRequest) (*CountResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CountRecords not implemented")
 }
+func (UnimplementedServiceServer) StreamRecords(*StreamRecordsRequest, Service_StreamRecordsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRecords not implemented")
+}
+func (UnimplementedServiceServer) CompileCondition(context.Context, *CompileConditionRequest) (*CompiledCondition, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompileCondition not implemented")
+}
+func (UnimplementedServiceServer) InvalidateCondition(context.Context, *InvalidateConditionRequest) (*InvalidateConditionResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvalidateCondition not implemented")
+}
+func (UnimplementedServiceServer) FieldStats(context.Context, *FieldStatsRequest) (*FieldStatsResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FieldStats not implemented")
+}
+func (UnimplementedServiceServer) SetReadOnly(context.Context, *SetReadOnlyRequest) (*SetReadOnlyResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetReadOnly not implemented")
+}
+func (UnimplementedServiceServer) GetStorageLayout(context.Context, *GetStorageLayoutRequest) (*StorageLayout, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStorageLayout not implemented")
+}
+func (UnimplementedServiceServer) QueryActiveLogs(context.Context, *QueryActiveLogsRequest) (*QueryActiveLogsResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryActiveLogs not implemented")
+}
+func (UnimplementedServiceServer) LatestPerLog(context.Context, *LatestPerLogRequest) (*LatestPerLogResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LatestPerLog not implemented")
+}
+func (UnimplementedServiceServer) GetRecordByHash(context.Context, *GetRecordByHashRequest) (*GetRecordByHashResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecordByHash not implemented")
+}
+func (UnimplementedServiceServer) RotateLogKey(context.Context, *RotateLogKeyRequest) (*RotateLogKeyResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateLogKey not implemented")
+}
 func (UnimplementedServiceServer) mustEmbedUnimplementedServiceServer() {}
 
 // UnsafeServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -198,6 +499,24 @@ func _Service_CreateLog_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_CreateLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).CreateLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_CreateLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).CreateLogs(ctx, req.(*CreateLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Service_UpdateLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Log)
 	if err := dec(in); err != nil {
@@ -216,6 +535,24 @@ func _Service_UpdateLog_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_GetLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetLog(ctx, req.(*GetLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Service_QueryLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(QueryLogsRequest)
 	if err := dec(in); err != nil {
@@ -270,6 +607,32 @@ func _Service_AppendRecords_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_AppendRecordsStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServiceServer).AppendRecordsStream(&serviceAppendRecordsStreamServer{stream})
+}
+
+type Service_AppendRecordsStreamServer interface {
+	SendAndClose(*AppendRecordsResult) error
+	Recv() (*AppendRecordsRequest, error)
+	grpc.ServerStream
+}
+
+type serviceAppendRecordsStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceAppendRecordsStreamServer) SendAndClose(m *AppendRecordsResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serviceAppendRecordsStreamServer) Recv() (*AppendRecordsRequest, error) {
+	m := new(AppendRecordsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Service_QueryRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(QueryRecordsRequest)
 	if err := dec(in); err != nil {
@@ -306,6 +669,189 @@ func _Service_CountRecords_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_StreamRecords_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRecordsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceServer).StreamRecords(m, &serviceStreamRecordsServer{stream})
+}
+
+type Service_StreamRecordsServer interface {
+	Send(*QueryRecordsResult) error
+	grpc.ServerStream
+}
+
+type serviceStreamRecordsServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceStreamRecordsServer) Send(m *QueryRecordsResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Service_CompileCondition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompileConditionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).CompileCondition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_CompileCondition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).CompileCondition(ctx, req.(*CompileConditionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_InvalidateCondition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateConditionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).InvalidateCondition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_InvalidateCondition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).InvalidateCondition(ctx, req.(*InvalidateConditionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_FieldStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FieldStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).FieldStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_FieldStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).FieldStats(ctx, req.(*FieldStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_SetReadOnly_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetReadOnlyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).SetReadOnly(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_SetReadOnly_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).SetReadOnly(ctx, req.(*SetReadOnlyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetStorageLayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStorageLayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetStorageLayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetStorageLayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetStorageLayout(ctx, req.(*GetStorageLayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_QueryActiveLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryActiveLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).QueryActiveLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_QueryActiveLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).QueryActiveLogs(ctx, req.(*QueryActiveLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_LatestPerLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LatestPerLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).LatestPerLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_LatestPerLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).LatestPerLog(ctx, req.(*LatestPerLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetRecordByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecordByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetRecordByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetRecordByHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetRecordByHash(ctx, req.(*GetRecordByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_RotateLogKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateLogKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).RotateLogKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_RotateLogKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).RotateLogKey(ctx, req.(*RotateLogKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Service_ServiceDesc is the grpc.ServiceDesc for Service service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -317,10 +863,18 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateLog",
 			Handler:    _Service_CreateLog_Handler,
 		},
+		{
+			MethodName: "CreateLogs",
+			Handler:    _Service_CreateLogs_Handler,
+		},
 		{
 			MethodName: "UpdateLog",
 			Handler:    _Service_UpdateLog_Handler,
 		},
+		{
+			MethodName: "GetLog",
+			Handler:    _Service_GetLog_Handler,
+		},
 		{
 			MethodName: "QueryLogs",
 			Handler:    _Service_QueryLogs_Handler,
@@ -341,7 +895,54 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CountRecords",
 			Handler:    _Service_CountRecords_Handler,
 		},
+		{
+			MethodName: "CompileCondition",
+			Handler:    _Service_CompileCondition_Handler,
+		},
+		{
+			MethodName: "InvalidateCondition",
+			Handler:    _Service_InvalidateCondition_Handler,
+		},
+		{
+			MethodName: "FieldStats",
+			Handler:    _Service_FieldStats_Handler,
+		},
+		{
+			MethodName: "SetReadOnly",
+			Handler:    _Service_SetReadOnly_Handler,
+		},
+		{
+			MethodName: "GetStorageLayout",
+			Handler:    _Service_GetStorageLayout_Handler,
+		},
+		{
+			MethodName: "QueryActiveLogs",
+			Handler:    _Service_QueryActiveLogs_Handler,
+		},
+		{
+			MethodName: "LatestPerLog",
+			Handler:    _Service_LatestPerLog_Handler,
+		},
+		{
+			MethodName: "GetRecordByHash",
+			Handler:    _Service_GetRecordByHash_Handler,
+		},
+		{
+			MethodName: "RotateLogKey",
+			Handler:    _Service_RotateLogKey_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AppendRecordsStream",
+			Handler:       _Service_AppendRecordsStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamRecords",
+			Handler:       _Service_StreamRecords_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "solaris.proto",
 }
@@ -19,20 +19,72 @@ import (
 	"github.com/oapi-codegen/runtime"
 )
 
+// Defines values for AppendRejectedReason.
+const (
+	RejectedError     AppendRejectedReason = "error"
+	RejectedExhausted AppendRejectedReason = "exhausted"
+	RejectedTooLarge  AppendRejectedReason = "tooLarge"
+)
+
+// AppendRejected The records, which were not added, if the result is partial.
+type AppendRejected struct {
+	// Count The number of the rejected records, they are always the last records of the request.
+	Count int64 `json:"count"`
+
+	// Reason Why the records were rejected: "error" means an error, see partialError, "exhausted" means the log reached its quota, "tooLarge" means the first rejected record is too large to be stored.
+	Reason AppendRejectedReason `json:"reason"`
+}
+
+// AppendRejectedReason Why the records were rejected: "error" means an error, see partialError, "exhausted" means the log reached its quota, "tooLarge" means the first rejected record is too large to be stored.
+type AppendRejectedReason string
+
+// Attributes The record attributes, the small key/value metadata the records may be selected by.
+type Attributes map[string]string
+
 // CreateLogRequest The request object to create log.
 type CreateLogRequest struct {
+	// Grants The IDs of the clients, which may read and append the log records besides the log owner.
+	Grants *Grants `json:"grants,omitempty"`
+
+	// MaxRecords If positive, the log keeps only the last maxRecords records, the oldest records are removed by the appends. The log may keep a few more records, cause the records are removed by whole chunks mostly.
+	MaxRecords *MaxRecords `json:"maxRecords,omitempty"`
+
+	// PayloadHash The hash function ("sha256" or "fnv64a") the log records payloads are indexed by. Empty value means the records are not indexed.
+	PayloadHash *PayloadHash `json:"payloadHash,omitempty"`
+
+	// PayloadTypeURL The type URL of the protobuf messages the log records payloads contain (see google.protobuf.Any).
+	PayloadTypeURL *PayloadTypeURL `json:"payloadTypeURL,omitempty"`
+
+	// RetentionMaxAgeMs If positive, the log records older than retentionMaxAgeMs milliseconds are removed in background. Zero value means keep forever.
+	RetentionMaxAgeMs *RetentionMaxAgeMs `json:"retentionMaxAgeMs,omitempty"`
+
+	// RetentionMaxRecords If positive, the oldest log records above the limit are removed in background. Zero value means keep forever. If both maxRecords and retentionMaxRecords are positive, the lesser one wins.
+	RetentionMaxRecords *RetentionMaxRecords `json:"retentionMaxRecords,omitempty"`
+
 	// Tags The log tags.
 	Tags Tags `json:"tags"`
+
+	// ValidateUTF8 If true, the log records payloads must be valid UTF-8 text, the records with other payloads are rejected.
+	ValidateUTF8 *ValidateUTF8 `json:"validateUTF8,omitempty"`
 }
 
 // CreateRecordRequest The request object to create a record.
 type CreateRecordRequest struct {
+	// Attributes The record attributes, the small key/value metadata the records may be selected by.
+	Attributes *Attributes `json:"attributes,omitempty"`
+
 	// Payload The record payload.
 	Payload []byte `json:"payload"`
 }
 
 // CreateRecordsRequest The request object to create records.
 type CreateRecordsRequest struct {
+	// Atomic If true, either all the records are created or none of them. If false, the records created before an error are kept. The server settings are applied if not specified.
+	Atomic *bool `json:"atomic,omitempty"`
+
+	// IdempotencyKey The key allows to retry the request safely, the retry with the key of the last committed request returns its result without adding the records again. The keys must grow from one request to another.
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+
 	// Records The list of records to be created.
 	Records []CreateRecordRequest `json:"records"`
 }
@@ -41,6 +93,27 @@ type CreateRecordsRequest struct {
 type CreateRecordsResponse struct {
 	// Added The number of records added.
 	Added int `json:"added"`
+
+	// BytesWritten The total size of the added records payloads.
+	BytesWritten *int64 `json:"bytesWritten,omitempty"`
+
+	// LastId The identifier of the last added record.
+	LastId *string `json:"lastId,omitempty"`
+
+	// Partial True if only some of the records were added because of an error.
+	Partial *bool `json:"partial,omitempty"`
+
+	// PartialError The error, which stopped adding the records, if the result is partial.
+	PartialError *string `json:"partialError,omitempty"`
+
+	// Rejected The records, which were not added, if the result is partial.
+	Rejected *AppendRejected `json:"rejected,omitempty"`
+
+	// StartId The identifier of the first added record.
+	StartId *string `json:"startId,omitempty"`
+
+	// Warnings The notes about the log state, for example, the log is close to its quota.
+	Warnings *[]string `json:"warnings,omitempty"`
 }
 
 // DeleteLogsRequest The request object to delete logs.
@@ -55,21 +128,57 @@ type DeleteLogsResponse struct {
 	Deleted int `json:"deleted"`
 }
 
+// Grants The IDs of the clients, which may read and append the log records besides the log owner.
+type Grants = []string
+
 // Log The log object.
 type Log struct {
 	// CreatedAt The timestamp when the log was created.
 	CreatedAt time.Time `json:"createdAt"`
 
+	// Grants The IDs of the clients, which may read and append the log records besides the log owner.
+	Grants *Grants `json:"grants,omitempty"`
+
 	// Id The log identifier.
 	Id string `json:"id"`
 
+	// MaxRecords If positive, the log keeps only the last maxRecords records, the oldest records are removed by the appends. The log may keep a few more records, cause the records are removed by whole chunks mostly.
+	MaxRecords *MaxRecords `json:"maxRecords,omitempty"`
+
+	// Owner The ID of the client the log belongs to. The logs without the owner are available to all the clients.
+	Owner *string `json:"owner,omitempty"`
+
+	// PayloadHash The hash function ("sha256" or "fnv64a") the log records payloads are indexed by. Empty value means the records are not indexed.
+	PayloadHash *PayloadHash `json:"payloadHash,omitempty"`
+
+	// PayloadTypeURL The type URL of the protobuf messages the log records payloads contain (see google.protobuf.Any).
+	PayloadTypeURL *PayloadTypeURL `json:"payloadTypeURL,omitempty"`
+
+	// RetentionMaxAgeMs If positive, the log records older than retentionMaxAgeMs milliseconds are removed in background. Zero value means keep forever.
+	RetentionMaxAgeMs *RetentionMaxAgeMs `json:"retentionMaxAgeMs,omitempty"`
+
+	// RetentionMaxRecords If positive, the oldest log records above the limit are removed in background. Zero value means keep forever. If both maxRecords and retentionMaxRecords are positive, the lesser one wins.
+	RetentionMaxRecords *RetentionMaxRecords `json:"retentionMaxRecords,omitempty"`
+
 	// Tags The log tags.
 	Tags Tags `json:"tags"`
 
 	// UpdatedAt The timestamp when the log was updated (new records added or tags are applied).
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// ValidateUTF8 If true, the log records payloads must be valid UTF-8 text, the records with other payloads are rejected.
+	ValidateUTF8 ValidateUTF8 `json:"validateUTF8"`
 }
 
+// MaxRecords If positive, the log keeps only the last maxRecords records, the oldest records are removed by the appends. The log may keep a few more records, cause the records are removed by whole chunks mostly.
+type MaxRecords = int64
+
+// PayloadHash The hash function ("sha256" or "fnv64a") the log records payloads are indexed by. Empty value means the records are not indexed.
+type PayloadHash = string
+
+// PayloadTypeURL The type URL of the protobuf messages the log records payloads contain (see google.protobuf.Any).
+type PayloadTypeURL = string
+
 // QueryLogsResult The response object to the query logs request.
 type QueryLogsResult struct {
 	// Items The list of found logs.
@@ -96,6 +205,12 @@ type QueryRecordsResult struct {
 
 // Record The record object.
 type Record struct {
+	// AgeMs The record age in milliseconds at the query time. Returned only if the withAge flag is set.
+	AgeMs *int64 `json:"ageMs,omitempty"`
+
+	// Attributes The record attributes, the small key/value metadata the records may be selected by.
+	Attributes *Attributes `json:"attributes,omitempty"`
+
 	// CreatedAt The timestamp when the record was created.
 	CreatedAt time.Time `json:"createdAt"`
 
@@ -107,23 +222,68 @@ type Record struct {
 
 	// Payload The record payload.
 	Payload []byte `json:"payload"`
+
+	// Seq The record sequence number in the log. Returned only if the log records are numbered.
+	Seq *int64 `json:"seq,omitempty"`
 }
 
+// RetentionMaxAgeMs If positive, the log records older than retentionMaxAgeMs milliseconds are removed in background. Zero value means keep forever.
+type RetentionMaxAgeMs = int64
+
+// RetentionMaxRecords If positive, the oldest log records above the limit are removed in background. Zero value means keep forever. If both maxRecords and retentionMaxRecords are positive, the lesser one wins.
+type RetentionMaxRecords = int64
+
 // Tags The log tags.
 type Tags map[string]string
 
 // UpdateLogRequest The request object to update log.
 type UpdateLogRequest struct {
+	// Grants The IDs of the clients, which may read and append the log records besides the log owner.
+	Grants *Grants `json:"grants,omitempty"`
+
+	// MaxRecords If positive, the log keeps only the last maxRecords records, the oldest records are removed by the appends. The log may keep a few more records, cause the records are removed by whole chunks mostly.
+	MaxRecords *MaxRecords `json:"maxRecords,omitempty"`
+
+	// PayloadHash The hash function ("sha256" or "fnv64a") the log records payloads are indexed by. Empty value means the records are not indexed.
+	PayloadHash *PayloadHash `json:"payloadHash,omitempty"`
+
+	// PayloadTypeURL The type URL of the protobuf messages the log records payloads contain (see google.protobuf.Any).
+	PayloadTypeURL *PayloadTypeURL `json:"payloadTypeURL,omitempty"`
+
+	// RetentionMaxAgeMs If positive, the log records older than retentionMaxAgeMs milliseconds are removed in background. Zero value means keep forever.
+	RetentionMaxAgeMs *RetentionMaxAgeMs `json:"retentionMaxAgeMs,omitempty"`
+
+	// RetentionMaxRecords If positive, the oldest log records above the limit are removed in background. Zero value means keep forever. If both maxRecords and retentionMaxRecords are positive, the lesser one wins.
+	RetentionMaxRecords *RetentionMaxRecords `json:"retentionMaxRecords,omitempty"`
+
 	// Tags The log tags.
 	Tags Tags `json:"tags"`
+
+	// ValidateUTF8 If true, the log records payloads must be valid UTF-8 text, the records with other payloads are rejected.
+	ValidateUTF8 *ValidateUTF8 `json:"validateUTF8,omitempty"`
 }
 
+// ValidateUTF8 If true, the log records payloads must be valid UTF-8 text, the records with other payloads are rejected.
+type ValidateUTF8 = bool
+
+// CreatedAfter defines model for CreatedAfter.
+type CreatedAfter = time.Time
+
+// CreatedBefore defines model for CreatedBefore.
+type CreatedBefore = time.Time
+
 // Desc defines model for Desc.
 type Desc = bool
 
+// FromPageExclusive defines model for FromPageExclusive.
+type FromPageExclusive = bool
+
 // FromPageId defines model for FromPageId.
 type FromPageId = string
 
+// FromSeq defines model for FromSeq.
+type FromSeq = int64
+
 // Limit defines model for Limit.
 type Limit = int
 
@@ -136,9 +296,21 @@ type LogIds = []string
 // LogsCondFilter defines model for LogsCondFilter.
 type LogsCondFilter = string
 
+// MaxPayloadLen defines model for MaxPayloadLen.
+type MaxPayloadLen = int
+
+// MaxPerLog defines model for MaxPerLog.
+type MaxPerLog = int
+
+// MinPayloadLen defines model for MinPayloadLen.
+type MinPayloadLen = int
+
 // RecordsCondFilter defines model for RecordsCondFilter.
 type RecordsCondFilter = string
 
+// WithAge defines model for WithAge.
+type WithAge = bool
+
 // QueryLogsParams defines parameters for QueryLogs.
 type QueryLogsParams struct {
 	// LogsCondFilter The condition for filtering the logs.
@@ -149,6 +321,12 @@ type QueryLogsParams struct {
 
 	// Limit The max number of objects to return per page.
 	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// CreatedAfter Select the objects created at or after the time.
+	CreatedAfter *CreatedAfter `form:"createdAfter,omitempty" json:"createdAfter,omitempty"`
+
+	// CreatedBefore Select the objects created at or before the time.
+	CreatedBefore *CreatedBefore `form:"createdBefore,omitempty" json:"createdBefore,omitempty"`
 }
 
 // QueryRecordsParams defines parameters for QueryRecords.
@@ -170,6 +348,30 @@ type QueryRecordsParams struct {
 
 	// Limit The max number of objects to return per page.
 	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// MinPayloadLen The minimum payload length (in bytes) of the records to return.
+	MinPayloadLen *MinPayloadLen `form:"minPayloadLen,omitempty" json:"minPayloadLen,omitempty"`
+
+	// MaxPayloadLen The maximum payload length (in bytes) of the records to return.
+	MaxPayloadLen *MaxPayloadLen `form:"maxPayloadLen,omitempty" json:"maxPayloadLen,omitempty"`
+
+	// WithAge The flag specifies that the records age should be returned.
+	WithAge *WithAge `form:"withAge,omitempty" json:"withAge,omitempty"`
+
+	// FromSeq The sequence number of the record to start returning the results from. It may be used for one log only, the fromPageId is ignored if it is specified.
+	FromSeq *FromSeq `form:"fromSeq,omitempty" json:"fromSeq,omitempty"`
+
+	// MaxPerLog The maximum number of records every log contributes to the result. The log records over the limit are skipped, the next page starts after the last returned record.
+	MaxPerLog *MaxPerLog `form:"maxPerLog,omitempty" json:"maxPerLog,omitempty"`
+
+	// CreatedAfter Select the objects created at or after the time.
+	CreatedAfter *CreatedAfter `form:"createdAfter,omitempty" json:"createdAfter,omitempty"`
+
+	// CreatedBefore Select the objects created at or before the time.
+	CreatedBefore *CreatedBefore `form:"createdBefore,omitempty" json:"createdBefore,omitempty"`
+
+	// FromPageExclusive If true, the record with the fromPageId is not returned, so the next page may be requested by the last record ID of the previous one.
+	FromPageExclusive *FromPageExclusive `form:"fromPageExclusive,omitempty" json:"fromPageExclusive,omitempty"`
 }
 
 // DeleteLogsJSONRequestBody defines body for DeleteLogs for application/json ContentType.
@@ -263,6 +465,22 @@ func (siw *ServerInterfaceWrapper) QueryLogs(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "createdAfter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdAfter", c.Request.URL.Query(), &params.CreatedAfter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter createdAfter: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "createdBefore" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdBefore", c.Request.URL.Query(), &params.CreatedBefore)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter createdBefore: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -403,6 +621,70 @@ func (siw *ServerInterfaceWrapper) QueryRecords(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "minPayloadLen" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minPayloadLen", c.Request.URL.Query(), &params.MinPayloadLen)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minPayloadLen: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "maxPayloadLen" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxPayloadLen", c.Request.URL.Query(), &params.MaxPayloadLen)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxPayloadLen: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "withAge" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "withAge", c.Request.URL.Query(), &params.WithAge)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter withAge: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "fromSeq" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fromSeq", c.Request.URL.Query(), &params.FromSeq)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fromSeq: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "maxPerLog" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxPerLog", c.Request.URL.Query(), &params.MaxPerLog)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxPerLog: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "createdAfter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdAfter", c.Request.URL.Query(), &params.CreatedAfter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter createdAfter: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "createdBefore" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdBefore", c.Request.URL.Query(), &params.CreatedBefore)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter createdBefore: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "fromPageExclusive" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fromPageExclusive", c.Request.URL.Query(), &params.FromPageExclusive)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fromPageExclusive: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -452,29 +734,56 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/9RZT2/buBP9KgR/v8MuoLXdbU++tQmKLdBD2m1PRYEy4khmVyIVkmpqBP7uiyEli7JI",
-	"W3H+oHsKEpHDN/Nm3gyZO5qrulESpDV0fUcbplkNFrT77RJMjj85mFyLxgol6Zp+2gApKlYS00AuCgGG",
-	"2A0QXASSC1kSpTloUihNGlYKyXDjgmZU4PabFvSWZlSyGuja2aYZNfkGaoaH2W2Df79WqgIm6W6X0bda",
-	"1VeshHc8jkZwogoHomElEKuIsUxbosG2WiIi/KbBtJU1pNCqTqEphpMimIzVQpYO0ntRCxtHU7OfRLb1",
-	"NWhEpa6/Q24NgvJwSAMuLpDCUDnTkeOFtFCC9uerMhWNSpVEcJAWudH7UxpmN8Ehbn9GNdy0QgOna6tb",
-	"OOEz7jEpCkzPQaVK526upBEc9IK8K/a5wjO35hsuulCSvxWVBf2NCENEKZUGngyLPz2EKCzUJoI16//A",
-	"tGbbHntwXtyHXEku8HeXuoVb2ScP4j2CLLR9PIgfIVeaPwiL9iZScPTkhGOIdv1HF8gLDczCe1V+hJsW",
-	"TCLDtf/Y5bbj2u3DKCGqRqsGtBXgyWGl+/l/DQVd0/8tB8lZdkcvP+EaxDLk4xe/8eueTH8asutR+kie",
-	"B5R1QZyibdi2Uoyn7OEm0q3BzYXSNbOoV1sLNIsQHnrUGz/llDnPqyAxxk51HxJ6IdBW0e9Ge9fQmfTV",
-	"2JfZMQZjnMQKMQxHD2tGOEyjpIFUPPzXICBYJuOg9FGbBodxDgm+BxnvrbjFi4HoUJND17zRmGOXUIEr",
-	"sfuSzN3GvRKNnfAKcdGrRqJru0WDtixOJuyh2VMOnUFT4FaaI7/oJEvOSLd2Bkm91ZhX71WZbq9+2RRm",
-	"VzSvE5xaUYOxrG7I7QZk31fILTNhue0lhTMLf+CeKU0ZFbO7/7Q5zhbkjLYNP9Ojbif5TcLtuHyI0gQx",
-	"EKaBsKapBPDf53p+QKHAIcY5lAXRD3HHyP2AHbPL2Lay98pX121PpOteMdNqW6hW8mGsmCOxmJITSc2o",
-	"hJ923nSMK/ej5zQtlGVVgmb8FNTZGPyJKvO+9faTdAxSfx4jJ3V+NinhfDWHF4/8l6ImcOHB7HTuHRuI",
-	"Hk8PO4OPKomdzROqWN3nRjXZ/aRjoxO5/sbWnxTqXYy2T53KM+57N6uuxmP5oQtxv1FbA48H+5+dwt7/",
-	"quCV+XmuCrhMyEI528JW+O1vVTEtzOUb8vrqHc3oD9DG432xWC1W6JpqQLJG0DV9uVgtXrqg241DtkTR",
-	"GyaSqdOX4ykNvXPvH5hbwajUXb3B2DeKb12tKGlBujC6npi7bcvvxk9zww3uWHCmw+VuHCq85bs/eCV1",
-	"rvy5Wj0JgG4YdAiiyWVIzWy+6e+1h+MpuQUNwzjnrqptXTO9HccZKSshkoAf9q16SsV+BHDsDq9eX+L+",
-	"DUuWB08Ju+zkjuD9asZq/7S0+/qELB3OPwmKfGNFLTZtnoMxRVsd8jDE2MmgignBxeh1YMzD/sXhiSpi",
-	"8qIxqyBePNr5bmhLVsC40Y0jO0TNfXDKs7xzbWDnXiraSKg/j9R1HOq9Yp+T8pi7X5+GokkneWbNmkFR",
-	"d51YYI6/Wr1Kjwm4WCrrx7BDRgdypowugzeao1UUjHaxSurG6F+M4ujj1jNXYvxFKUF8f5twLWgo0Iew",
-	"P+bPZ0CDs9f6Lt6+LjaQ/0OEvyAY0D9AE4G5SBiO+K2UQkbK/AptPrBcIq/EU48R/Inu8Bewym5Ijp54",
-	"j4M0P9Kzk0keXhWfoXNPX+vnNHD/n4oZK90/2P6zA8T4xv7gGWIojN3u3wAAAP//naIr7pgcAAA=",
+	"H4sIAAAAAAAC/+xbX2/cNhL/KoTuHhJAWadtrij85uZPa5wDuK7TAlcXKFcaSawlUiGpXe8F+90PQ4oS",
+	"taJW8ibp9SFv8YpDzv/5zZD5ECWiqgUHrlV0/iGqqaQVaJDmr5cSqIb0ItMg8e8UVCJZrZng0Xn0M5SQ",
+	"aKILIGL9JyRakcQSEKqJkIQinfmuWQWrKI4Y0r1vQO6iOOK0gug8SvxD4kglBVQUT8uErKiOzqOUaniG",
+	"W0RxpHc1EiktGc+j/T52TH4PmZBwApdrQ7iUzfaYE/h8BSoZs3dbAMlKmhNVQ8IyBspwgouAp4znRMgU",
+	"JMmEJDXNGadIOMUkkg14a9lYC1EC5YaPN1JU1zSH1w9J2Si2CejsMiNaNhAbViQkQqZky3Rh/s5a+suU",
+	"MEW40ESCbiSHNCZKmCUcHjRyC6SiO7LGPd43oFDn651ZUVKl3c6Xr4jIzK+1hA0TjSKCT9ohG7G/UN7L",
+	"NKx9lnbHI8daEKWpdFKhBawWVFNqZaSfY+0yDfHkuQKy9DO8D/OjUFc8AcKbag3SMdcqawl75FI7xTcK",
+	"UuM7ggMpRU4EL3dxwI4s50JCSlhGmMYfnD+mx6RFIYKhwLj+9kUfBoxryEEa4a9YxXRY9Io+eFK7cNWi",
+	"FZbUYIJg0jdKs3VA94PzRT7lCqgglgLXKLjsTqmpLrxDDH0coU8zCWl0bmLlqMHNmWrK/5SzcSlyI24i",
+	"uGIpyBW5zHpDWLP9gYteCp6+YaUG+YdnvEm12NN9FpmGSgV47SxGpaQ7x7t3XliGRPCU4d/G1zKz0rkm",
+	"8nuEM3/v40p8Sx+u6a4UNL0CPulArGoqUtt1pASe64I8YZysdxrU02E0eb41xWE1OHTGtZBDkFciP85d",
+	"7+KODdiA3Bn3SwTXkq0bDYa5PrZXxHmoIxKbtroatydUAlH3rK6dp/RZ2GQM5dXjNv3atN1ueEwDVqg5",
+	"6RmftQ/jn9o+g0NnOLyxu36MN7eMTbEjRycc9+lfmS4ucliIC6geKMeYthBNmdoaa805xdq2Pepoudy7",
+	"ryY5XNQ18PQGMA/DRM5smYnJtmBJQbYgwYACmqboiCzzfBhzVU2lZrRELmspapCagTksEQ2fqAuHldDy",
+	"0x+tC9gZ/6fllu7UIcJQPaWBIXj2bKWKIwlUiYAj/1rsBmYwIjumzsldBFIKeReRCihXhHJifoiJAnDi",
+	"v7a/3EXwUNBGaUi79boLc5oUWJG1Iu8boSku10JcUZnDYHXGpNIdBy1fqGstBClxOcbRGojSpkzcYaAA",
+	"b6ro/DfLbBT3jERxd0r0+yGQjaOHZ0j5bEMl+pXCLZyDvG636v72tnS/3XZb7/d+Df2ttX+n9v5oiwPQ",
+	"Ihfa5Ua0Ck1tlNLyeuBIo5I25bSEdvvZjKkqWpbkHnZnG1o2QCrQNKWaDszdIitl+gqDaFdRgFfbllyJ",
+	"/MY63VT0mI8t1jG139ChB4xDJJe07dP+KSGLzqN/nPXt21kbuGc/2FX7GFN3m/LmaN72K/dx1KbnH6kq",
+	"5givvaU95e2uhnc3VwuJ3WrjEBo46uctfbjI4e0s4zcjgoNdFirgJkCCaIjms6S3uGYfRxtaspRqeHf7",
+	"5rs5ml/8tYeRYA79fdKnLHunuRX1iv3Qt+ggto7x7kVhb/FjxcGV+0HexXof7JN9TbjN55ShTtOGV8wP",
+	"lSEqlhxpjIHpAiTBdDGoyBK66YKQhAsObe2pDJbPaKkGbXU/jGjHEK5amK3uoW5xnwKJaE+B1ozn9iBa",
+	"1yWzLRsW3EHDdljY44ilUNVCA092/4ZdWFH3WEbLUmwd8JI7v24SRTNw7aP92k0FkFRkfeVNRFUxbcuR",
+	"Jbb4RJl61sIBpBaNwQoH+IrQnDJuZb+HnSJVozTJpdiavtW0s25jLQjlAu2xGjtU7CDZRMfHlPaBuK2T",
+	"rU1wu65ROhYRobgMtVK+azu2Fri2qgVXMOXb9qvn3LpwEnRiecDnwNERpc2Brs4muHgVxEoGvf8q0eIT",
+	"0F8LTUui2H9dRNjtus3bUFcLwRk62fREx7XwA5f0zwt6SovNApvKBjDMcHhClKjgsE0xENDuv4aENsqs",
+	"cLEcDkgfCYblaGGjBdZKC2ztArFyHGQH4qGH80fT/BD8Y2egqXyE0i0sndX6lpox1kSAcqFBEbrGNOGQ",
+	"sdJUQ2yaM3igVV1C3H1jiiSlUAbxdsh5EMjzEw8/TG2AhIL0FZRgAN5ji09qCLu5yDAgbbf50nWgE42h",
+	"WdT3qavZQnq47ZxAJ6QcT6zpfGMXzWYcs0m7dhUeI/rSuV1DUv3QYebxgZevutYwKRl6v4s2BPkSaEoo",
+	"T7HQAk+9xswG/RoUS6Fv2MSWg3yMq5nZ2vQk0soQaJTbC5MJh9OsAqVpVZNtAbzjbkuVX9eWXFrEj244",
+	"2OLB6uioU3sVo/Up8w6t2+liDaXgZtTaTdVUB0ZwkdnUgqwNZSVdlyahOLjXOstEEfnSOz2+d2rq9ESf",
+	"binJEw7bIVIhQhLkwQfLT5f7/idr55gZqaCgsRe7vswHh4XS2NAao66kFopptvEK4T1ArSxi6RBQH2OD",
+	"2RkRZQretAz1JaESm/6+zqZA1Q+hMUHiEYSSDLakEtLDIxYBHTZG3pbbQpRAkqLh94pUQulytxD3XQ/D",
+	"a+wqBVUFyRqe4G/kyV2kCvr1v769i9Ad7qKMb759Qe+ip6N07gCo4ZXxFB7scIe8rmq9I24i5IZuvmRc",
+	"aEcRzAnXo8gOuPiuBvLu5qq/CxVarJuMVKAUzUFNM5wIrinj5IkCILkQeQkrR7664LunQaZ+wvFwW+2b",
+	"Uj+q1pvR8kyp76rgdNeViYan/QXRklYLK2agkOJdx7JL3u5WJKgU06Uca2B6jDJkfgahWNnc/qEAN+bo",
+	"W77TLDLb7y02in/PscQulvO/lWk8ET7aOq14x4ZcU3CNulI8SUlzTDikYmXJFCCqV4Rqz6zmXQq5cfd1",
+	"Jqm3PV97q2PvivDaHpZeb5w68Hs8/HQvSE5BoOy40mdAZfmYu/4pKPeJRptxpOD90b0OX36wDulMGN+v",
+	"BlQ6OkgXeUAIpbi3DU5y39rhsAhAzgXgxDGNwEPixSYnI/R6EA8egMDbYprc5xLDfEX+A1IMyrPBJZmQ",
+	"eKu+MBhuwqh3RpQWNw3MsBYbOLiVP4lvHBevhS580EZNVhtxag45UDIoBfbVz5bxpUO12xaxf8TNGuoC",
+	"sW7wTuxdnZ50J2aR8pc7sS93YmOf+uXghCMvGYPg2VwsrIEYTsm72zfPviMaHvTwlsbcc5g7hmGf4Iap",
+	"oREvisB4JkzkMF3ix59FSSVTr74nF9eX2PeBVJbTr1bPV89RIFEDpzWLzqNvVs9X35hsrAuj8TNEm/0Y",
+	"bSzuq+FoEePEvBe9TLuPiPjb12ug9Pci3dnHFxxtjf80nXJiyM7+bB9A9C9GjhlwPBHdD82IpjA/WAhr",
+	"RPn6+fPPwoA9wnIQTFN4k6+Twg3TD2eqdqbfzSBxG9VUFZW7oZ7NmAwCqeynrkcam6LrvaJ48OT6t7B8",
+	"/ZKzg9d4+3iWwnv/umC1fZ25YOHgbfjy9e376f3vn9ENDjvbCR+w2BpRqWqSBJTKmvLQ0L0RTSYXoZr1",
+	"cvBiY2jo7hXIZwq50SuTRRH31Sc737TjkyE2hPxDzfZaMx9Majv7YADoHk+tm4Cq39XppKo7cHFKTF2m",
+	"rUt+ehONQM9fnBQXmKidRK7Qx188fzHdMOFiLrRtsA8t2htnbNEz7xb+aBR5TXsokhwM+XuZOPgU5S+O",
+	"xPCbgQnDD+6t+wD9GOsP7Wc9oMY24fxDuD6+LCC5d51s+76FoS/aNqfheCs89oNr+wzxo8Ll8K40qCNk",
+	"fqY6/Ai01AVJUBIrsefmR0DBpJP7Q8C/ABqM30Pv42WhpJasNP/j6f+HUIbv0ZcQ0IfHEbjX2wtlxP8t",
+	"s5AN++D+88Ow5cbp/7/V58duwzH4R8O3Pift9/8bAPXPbcbxOAAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -13,6 +13,11 @@
 // limitations under the License.
 package lru
 
+import (
+	"fmt"
+	"math"
+)
+
 // Cache implements container with limited size capacity and LRU (Least Recently Used) pull out discipline.
 // The elements can be created automatically if they are not found in the pool via the createNewF function call,
 // which is provided via the Cache creation (see NewCache)
@@ -31,3 +36,22 @@ func NewCache[K comparable, V any](maxSize int, createNewF CreatePoolElemF[K, V]
 	}
 	return &Cache[K, V]{eCache}, nil
 }
+
+// NewSizedCache creates new pool object bounded by the total size of the elements instead of their number.
+// The elements sizes are calculated by sizeF, when the elements are created, and the least recently used
+// elements are deleted from the pool while the total size is above maxTotalSize.
+func NewSizedCache[K comparable, V any](maxTotalSize int64, sizeF SizeElemF[V], createNewF CreatePoolElemF[K, V], onDeleteF OnDeleteElemF[K, V]) (*Cache[K, V], error) {
+	if maxTotalSize < 1 {
+		return nil, fmt.Errorf("NewSizedCache(): the maxTotalSize=%d, but it cannot be less than 1", maxTotalSize)
+	}
+	if sizeF == nil {
+		return nil, fmt.Errorf("NewSizedCache(): sizeF must not be nil")
+	}
+	eCache, err := NewECache(math.MaxInt, directT[K], createNewF, onDeleteF)
+	if err != nil {
+		return nil, err
+	}
+	eCache.sizeF = sizeF
+	eCache.maxTotalSize = maxTotalSize
+	return &Cache[K, V]{eCache}, nil
+}
@@ -14,6 +14,7 @@
 package lru
 
 import (
+	"fmt"
 	"math/rand"
 	"os"
 	"sync"
@@ -247,3 +248,55 @@ func TestCache_Clear(t *testing.T) {
 	assert.Equal(t, 10, p.Clear())
 	assert.Equal(t, 0, p.items.Len())
 }
+
+func TestCache_Stats(t *testing.T) {
+	p, err := NewCache[int, int](2, func(k int) (int, error) {
+		if k < 0 {
+			return 0, fmt.Errorf("negative")
+		}
+		return k, nil
+	}, nil)
+	assert.Nil(t, err)
+	for _, k := range []int{1, 2, 1, 3, 1, 2, -1} {
+		p.GetOrCreate(k)
+	}
+	// 2 is evicted by 3, and 3 is evicted by 2 then
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 5, Evictions: 2}, p.Stats())
+	p.Remove(1)
+	assert.Equal(t, int64(2), p.Stats().Evictions)
+}
+
+func TestSizedCache(t *testing.T) {
+	_, err := NewSizedCache[int, []int](0, func(v []int) int64 { return int64(len(v)) }, nil, nil)
+	assert.NotNil(t, err)
+	_, err = NewSizedCache[int, []int](10, nil, func(k int) ([]int, error) { return nil, nil }, nil)
+	assert.NotNil(t, err)
+
+	var deleted []int
+	p, err := NewSizedCache[int, []int](10, func(v []int) int64 { return int64(len(v)) }, func(k int) ([]int, error) {
+		return make([]int, k), nil
+	}, func(k int, v []int) { deleted = append(deleted, k) })
+	assert.Nil(t, err)
+	for _, k := range []int{3, 4, 3, 2} {
+		p.GetOrCreate(k)
+	}
+	assert.Equal(t, int64(9), p.TotalSize())
+	assert.Nil(t, deleted)
+
+	// the least recently used 4 is evicted, the rest fits the size
+	p.GetOrCreate(5)
+	assert.Equal(t, []int{4}, deleted)
+	assert.Equal(t, int64(10), p.TotalSize())
+
+	// the element bigger than the cache is not kept at all
+	v, err := p.GetOrCreate(11)
+	assert.Nil(t, err)
+	assert.Len(t, v, 11)
+	assert.Equal(t, []int{4, 3, 2, 5, 11}, deleted)
+	assert.Equal(t, int64(0), p.TotalSize())
+
+	p.GetOrCreate(6)
+	p.Remove(6)
+	assert.Equal(t, int64(0), p.TotalSize())
+	assert.Equal(t, int64(5), p.Stats().Evictions)
+}
@@ -31,11 +31,27 @@ type ECache[PK any, K comparable, V any] struct {
 	createNewF     CreatePoolElemF[PK, V]
 	onDeleteF      OnDeleteElemF[PK, V]
 	mapToInnerKeyF MapToInnerKeyF[PK, K]
+	stats          CacheStats
+	// sizeF is not nil if the cache is bounded by the total size of the elements (see NewSizedCache)
+	sizeF        SizeElemF[V]
+	maxTotalSize int64
+	totalSize    int64
+}
+
+// CacheStats contains the ECache counters
+type CacheStats struct {
+	// Hits is the number of the elements found in the cache
+	Hits int64
+	// Misses is the number of the elements created by the cache requests
+	Misses int64
+	// Evictions is the number of the least recently used elements deleted when the cache is full
+	Evictions int64
 }
 
 type pair[PK any, V any] struct {
-	pk PK
-	v  V
+	pk   PK
+	v    V
+	size int64
 }
 
 // CreatePoolElemF function type for creating new pool elements
@@ -43,6 +59,9 @@ type CreatePoolElemF[K any, V any] func(k K) (V, error)
 type OnDeleteElemF[K any, V any] func(k K, v V)
 type MapToInnerKeyF[V any, K any] func(V) K
 
+// SizeElemF function type for calculating the pool element size
+type SizeElemF[V any] func(v V) int64
+
 // NewECache creates new pool object. It expects the maximum pull size (maxSize) and the create new
 // element function in the parameters
 func NewECache[PK any, K comparable, V any](maxSize int, toComparableF MapToInnerKeyF[PK, K], createNewF CreatePoolElemF[PK, V], onDeleteF OnDeleteElemF[PK, V]) (*ECache[PK, K, V], error) {
@@ -71,6 +90,7 @@ func (p *ECache[PK, K, V]) GetOrCreate(pk PK) (V, error) {
 			// make it recently used, but adding to the end of the list ...
 			p.items.Remove(k)
 			p.items.Add(k, res)
+			p.stats.Hits++
 			p.lock.Unlock()
 			return res.v, nil
 		}
@@ -93,12 +113,21 @@ func (p *ECache[PK, K, V]) GetOrCreate(pk PK) (V, error) {
 		p.lock.Lock()
 		close(ch)
 		delete(p.inflight, k)
+		p.stats.Misses++
 		if err == nil {
-			p.items.Add(k, pair[PK, V]{pk, v})
-			if p.maxSize < p.items.Len() {
+			e := pair[PK, V]{pk: pk, v: v}
+			if p.sizeF != nil {
+				e.size = p.sizeF(v)
+			}
+			p.items.Add(k, e)
+			p.totalSize += e.size
+			// the new element is evicted as well, if it doesn't fit the cache alone
+			for p.overflows() {
 				k, _ := p.items.First()
 				v, _ := p.items.Get(k)
 				p.items.Remove(k)
+				p.totalSize -= v.size
+				p.stats.Evictions++
 				if p.onDeleteF != nil {
 					p.onDeleteF(v.pk, v.v)
 				}
@@ -122,6 +151,7 @@ func (p *ECache[PK, K, V]) Remove(pk PK) bool {
 		return false
 	}
 	p.items.Remove(k)
+	p.totalSize -= v.size
 	if p.onDeleteF != nil {
 		p.onDeleteF(v.pk, v.v)
 	}
@@ -140,6 +170,7 @@ func (p *ECache[PK, K, V]) Clear() int {
 			continue
 		}
 		p.items.Remove(e.Key)
+		p.totalSize -= e.Value.size
 		if p.onDeleteF != nil {
 			p.onDeleteF(e.Value.pk, e.Value.v)
 		}
@@ -147,3 +178,26 @@ func (p *ECache[PK, K, V]) Clear() int {
 	}
 	return removed
 }
+
+// Stats returns the cache counters
+func (p *ECache[PK, K, V]) Stats() CacheStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.stats
+}
+
+// TotalSize returns the total size of the cache elements, it is always 0 if the cache is not
+// bounded by the elements size
+func (p *ECache[PK, K, V]) TotalSize() int64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.totalSize
+}
+
+// overflows returns true if the cache elements should be evicted
+func (p *ECache[PK, K, V]) overflows() bool {
+	if p.items.Len() == 0 {
+		return false
+	}
+	return p.maxSize < p.items.Len() || (p.sizeF != nil && p.maxTotalSize < p.totalSize)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/solarisdb/solaris/golibs/container/iterable"
 	"github.com/solarisdb/solaris/golibs/errors"
 	"sync"
+	"time"
 )
 
 type (
@@ -29,21 +30,44 @@ type (
 	// must be released as soon as it's not used anymore. The object can be kept in the cache and retrieved again or been
 	// deleted if the capacity of the cache is reached its maximum. The retrieved objects will not be deleted until
 	// they are released, so the cache clients must follow the protocol and release any object retrieved from the cache.
+	//
+	// The objects retrieved more than once since they were created, or created again soon after they were
+	// deleted, are hot. The hot objects are deleted after the others, so the frequently used objects are kept
+	// in the cache while many other objects are retrieved one by one.
 	ReleasableCache[K comparable, V any] struct {
-		lock       sync.Mutex
-		maxSize    int
-		allKnown   map[K]*rHolder[V]
-		lruCache   *iterable.Map[K, V]
+		lock     sync.Mutex
+		maxSize  int
+		allKnown map[K]*rHolder[V]
+		lruCache *iterable.Map[K, V]
+		// ghosts keeps up to maxSize keys of the objects deleted to free the room last
+		ghosts     *iterable.Map[K, struct{}]
 		inflight   map[K]chan struct{}
 		createNewF CreateCtxPoolElemF[K, V]
 		onDeleteF  OnDeleteElemF[K, V]
 		waiter     chan struct{}
 		closed     bool
+		stats      ReleasableCacheStats
 	}
 
 	rHolder[V any] struct {
 		value      V
 		refCounter int
+		// hot is true if the object was retrieved more than once
+		hot bool
+		// releasedAt is the time the object was released last time
+		releasedAt time.Time
+	}
+
+	// ReleasableCacheStats contains the ReleasableCache counters
+	ReleasableCacheStats struct {
+		// Hits is the number of the objects retrieved from the cache
+		Hits int64
+		// Misses is the number of the objects created by the cache requests
+		Misses int64
+		// Evictions is the number of the not used objects deleted to free the room for the new ones
+		Evictions int64
+		// Expirations is the number of the objects deleted because they were not used for a long time
+		Expirations int64
 	}
 
 	// Releasable struct represents an object retrieved from the cache. Clients can obtain the object
@@ -69,6 +93,7 @@ func NewReleasableCache[K comparable, V any](maxSize int, createNewF CreateCtxPo
 	r := new(ReleasableCache[K, V])
 	r.allKnown = make(map[K]*rHolder[V])
 	r.lruCache = iterable.NewMap[K, V]()
+	r.ghosts = iterable.NewMap[K, struct{}]()
 	r.inflight = make(map[K]chan struct{})
 	r.maxSize = maxSize
 	r.createNewF = createNewF
@@ -89,9 +114,11 @@ func (r *ReleasableCache[K, V]) GetOrCreate(ctx context.Context, k K) (Releasabl
 		}
 		if rh, ok := r.allKnown[k]; ok {
 			rh.refCounter++
+			rh.hot = true
 			if rh.refCounter == 1 {
 				r.lruCache.Remove(k)
 			}
+			r.stats.Hits++
 			r.lock.Unlock()
 			return Releasable[V]{k: k, rh: rh}, nil
 		}
@@ -141,9 +168,12 @@ func (r *ReleasableCache[K, V]) GetOrCreate(ctx context.Context, k K) (Releasabl
 
 		close(ch)
 		delete(r.inflight, k)
+		r.stats.Misses++
 		var rh *rHolder[V]
 		if err == nil {
-			rh = &rHolder[V]{refCounter: 1, value: v}
+			_, ghost := r.ghosts.Get(k)
+			r.ghosts.Remove(k)
+			rh = &rHolder[V]{refCounter: 1, value: v, hot: ghost}
 			r.allKnown[k] = rh
 		}
 		r.lock.Unlock()
@@ -168,6 +198,7 @@ func (r *ReleasableCache[K, V]) Release(rlsbl *Releasable[V]) {
 			}
 			return
 		}
+		rlsbl.rh.releasedAt = time.Now()
 		r.lruCache.Add((rlsbl.k).(K), rlsbl.rh.value)
 		if r.waiter != nil {
 			r.sweep(r.maxSize)
@@ -181,6 +212,33 @@ func (r *ReleasableCache[K, V]) Release(rlsbl *Releasable[V]) {
 	return
 }
 
+// Remove deletes the object by the key k from the cache if it is not borrowed. The function returns
+// false if the object is borrowed at the time, so it could not be removed.
+func (r *ReleasableCache[K, V]) Remove(k K) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return true
+	}
+	rh, ok := r.allKnown[k]
+	if !ok {
+		return true
+	}
+	if rh.refCounter > 0 {
+		return false
+	}
+	r.lruCache.Remove(k)
+	if r.onDeleteF != nil {
+		r.onDeleteF(k, rh.value)
+	}
+	delete(r.allKnown, k)
+	if r.waiter != nil {
+		close(r.waiter)
+		r.waiter = nil
+	}
+	return true
+}
+
 // Close removes all not borrowed objects. The objects that are not released yet will be deleted after the
 // Release() call. After the Close() call the new objects cannot be created
 func (r *ReleasableCache[K, V]) Close() error {
@@ -202,28 +260,93 @@ func (r *ReleasableCache[K, V]) Close() error {
 	r.inflight = nil
 	r.allKnown = nil
 	r.lruCache = nil
+	r.ghosts = nil
 	return nil
 }
 
+// Stats returns the cache counters
+func (r *ReleasableCache[K, V]) Stats() ReleasableCacheStats {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.stats
+}
+
+// SweepIdle deletes the not used objects, which were released more than maxIdle ago.
+// The function returns the number of the deleted objects.
+func (r *ReleasableCache[K, V]) SweepIdle(maxIdle time.Duration) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return 0
+	}
+	var idle []K
+	deadline := time.Now().Add(-maxIdle)
+	it := r.lruCache.Iterator()
+	for it.HasNext() {
+		e, ok := it.Next()
+		if !ok {
+			continue
+		}
+		if !r.allKnown[e.Key].releasedAt.After(deadline) {
+			idle = append(idle, e.Key)
+		}
+	}
+	it.Close()
+	for _, k := range idle {
+		r.delete(k)
+	}
+	r.stats.Expirations += int64(len(idle))
+	if len(idle) > 0 && r.waiter != nil {
+		close(r.waiter)
+		r.waiter = nil
+	}
+	return len(idle)
+}
+
 // used returns how many keys are created and how many are in flight so far. The function must
 // be called under the lock
 func (r *ReleasableCache[K, V]) used() int {
 	return len(r.allKnown) + len(r.inflight)
 }
 
-// sweep allows to remove not borrowed objects
+// sweep allows to remove not borrowed objects, the least recently used objects retrieved once are removed
+// first, and the hot ones are removed only if there are no others
 func (r *ReleasableCache[K, V]) sweep(maxAllowed int) {
 	for r.lruCache.Len() > 0 && r.used() >= maxAllowed {
-		k, _ := r.lruCache.First()
-		r.lruCache.Remove(k)
-		if r.onDeleteF != nil {
-			v := r.allKnown[k].value
-			r.onDeleteF(k, v)
+		k := r.victim()
+		r.delete(k)
+		r.stats.Evictions++
+		r.ghosts.Add(k, struct{}{})
+		if r.ghosts.Len() > r.maxSize {
+			gk, _ := r.ghosts.First()
+			r.ghosts.Remove(gk)
 		}
-		delete(r.allKnown, k)
 	}
 }
 
+// victim returns the key of the not borrowed object to be removed first
+func (r *ReleasableCache[K, V]) victim() K {
+	it := r.lruCache.Iterator()
+	defer it.Close()
+	for it.HasNext() {
+		if e, ok := it.Next(); ok && !r.allKnown[e.Key].hot {
+			return e.Key
+		}
+	}
+	k, _ := r.lruCache.First()
+	return k
+}
+
+// delete removes the not borrowed object by the key k
+func (r *ReleasableCache[K, V]) delete(k K) {
+	r.lruCache.Remove(k)
+	if r.onDeleteF != nil {
+		v := r.allKnown[k].value
+		r.onDeleteF(k, v)
+	}
+	delete(r.allKnown, k)
+}
+
 // Value returns the object value associated with Releasable. The function must not be called after the rlsbl
 // is released back to the cache
 func (rlsbl Releasable[V]) Value() V {
@@ -121,6 +121,37 @@ func TestReleasableCache_GetOrCreateSimple(t *testing.T) {
 	assert.Equal(t, 1, p.lruCache.Len())
 }
 
+func TestReleasableCache_Remove(t *testing.T) {
+	cnt := 0
+	deleted := []string{}
+	p, err := NewReleasableCache[string, int](2, func(_ context.Context, k string) (int, error) {
+		cnt++
+		return cnt, nil
+	}, func(k string, v int) {
+		deleted = append(deleted, k)
+	})
+	assert.Nil(t, err)
+	assert.True(t, p.Remove("aa"))
+
+	rl, err := p.GetOrCreate(context.Background(), "aa")
+	assert.Nil(t, err)
+	// borrowed
+	assert.False(t, p.Remove("aa"))
+	assert.Equal(t, 0, len(deleted))
+	p.Release(&rl)
+
+	assert.True(t, p.Remove("aa"))
+	assert.Equal(t, []string{"aa"}, deleted)
+	assert.Equal(t, 0, len(p.allKnown))
+	assert.Equal(t, 0, p.lruCache.Len())
+
+	// created again
+	rl, err = p.GetOrCreate(context.Background(), "aa")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, rl.Value())
+	p.Release(&rl)
+}
+
 func TestReleasableCache_GetOrCreate(t *testing.T) {
 	ch := make(chan struct{})
 	cnt := int32(0)
@@ -356,3 +387,70 @@ func TestReleasableCache_Close1(t *testing.T) {
 	_, err = p.GetOrCreate(context.Background(), 1)
 	assert.True(t, errors.Is(err, errors.ErrClosed))
 }
+
+func TestReleasableCache_HotKept(t *testing.T) {
+	var deleted []string
+	p, err := NewReleasableCache[string, string](3, func(_ context.Context, k string) (string, error) {
+		return k, nil
+	}, func(k, _ string) {
+		deleted = append(deleted, k)
+	})
+	assert.Nil(t, err)
+	get := func(k string) {
+		rl, err := p.GetOrCreate(context.Background(), k)
+		assert.Nil(t, err)
+		p.Release(&rl)
+	}
+
+	// the hot object is the least recently used one, but the cold ones are deleted first
+	get("hot")
+	get("hot")
+	for _, k := range []string{"c1", "c2", "c3", "c4"} {
+		get(k)
+	}
+	assert.Equal(t, []string{"c1", "c2"}, deleted)
+	_, ok := p.allKnown["hot"]
+	assert.True(t, ok)
+
+	// the hot object is deleted if there are no cold ones
+	get("c3")
+	get("c4")
+	get("c5")
+	assert.Equal(t, []string{"c1", "c2", "hot"}, deleted)
+
+	assert.Equal(t, ReleasableCacheStats{Hits: 3, Misses: 6, Evictions: 3}, p.Stats())
+
+	// the object created again soon after it was deleted is hot
+	deleted = nil
+	get("hot")
+	for _, k := range []string{"c6", "c7", "c8"} {
+		get(k)
+	}
+	assert.Equal(t, []string{"c5", "c3", "c6", "c7"}, deleted)
+	_, ok = p.allKnown["hot"]
+	assert.True(t, ok)
+}
+
+func TestReleasableCache_SweepIdle(t *testing.T) {
+	p, err := NewReleasableCache[string, string](2, func(_ context.Context, k string) (string, error) {
+		return k, nil
+	}, nil)
+	assert.Nil(t, err)
+	rl1, err := p.GetOrCreate(context.Background(), "aa")
+	assert.Nil(t, err)
+	rl2, err := p.GetOrCreate(context.Background(), "bb")
+	assert.Nil(t, err)
+	p.Release(&rl1)
+
+	// the borrowed objects are not deleted
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, p.SweepIdle(time.Minute))
+	assert.Equal(t, 1, p.SweepIdle(5*time.Millisecond))
+	assert.Equal(t, 1, len(p.allKnown))
+	assert.Equal(t, 0, p.lruCache.Len())
+
+	p.Release(&rl2)
+	assert.Equal(t, 1, p.SweepIdle(0))
+	assert.Equal(t, 0, len(p.allKnown))
+	assert.Equal(t, int64(2), p.Stats().Expirations)
+}
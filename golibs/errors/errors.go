@@ -68,6 +68,9 @@ var (
 	// be reported when the data is "partially" changed and the changes may not be
 	// rolled back.
 	ErrDataLoss = fmt.Errorf("data loss")
+	// ErrCorrupted indicates that the stored data doesn't match its checksum, so it was damaged
+	// after it had been written. The error is a kind of ErrDataLoss.
+	ErrCorrupted = fmt.Errorf("data corrupted: %w", ErrDataLoss)
 	// ErrCommunication - the error indicates any problem with the components' communication
 	// in the distributed system
 	ErrCommunication = fmt.Errorf("system communication error")
@@ -100,6 +103,12 @@ func Is(err, target error) bool {
 	return errors.Is(FromGRPCError(err), target)
 }
 
+// As finds the first error in err's chain that matches target, and if one is found, sets
+// target to that error value and returns true. See errors.As for details.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
 const jsonErrorMarker = "\x1bjson"
 
 // EmbedObject allows to add json-marshaled version of the object o into the error err and returns the new error. The
@@ -14,6 +14,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -46,6 +47,9 @@ var errorsToCode = map[error]codes.Code{
 	ErrUnimplemented: codes.Unimplemented,
 	ErrConflict:      codes.FailedPrecondition,
 	ErrCanceled:      codes.Canceled,
+	// the requests interrupted by the clients or by their deadlines
+	context.Canceled:         codes.Canceled,
+	context.DeadlineExceeded: codes.DeadlineExceeded,
 }
 
 // FromGRPCError receives a gRPC error (code-based) and returns the  one of the
@@ -267,3 +267,16 @@ func TestIsDirEmpty(t *testing.T) {
 	assert.False(t, e)
 	assert.Nil(t, err)
 }
+
+func TestFreeSpace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freeSpace")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	free, err := FreeSpace(dir)
+	assert.Nil(t, err)
+	assert.True(t, free > 0)
+
+	_, err = FreeSpace(filepath.Join(dir, "notExist"))
+	assert.NotNil(t, err)
+}
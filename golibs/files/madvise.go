@@ -0,0 +1,36 @@
+// Copyright 2023 The acquirecloud Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package files
+
+import (
+	"os"
+	"syscall"
+)
+
+// WillNeed advises the kernel the mapped region [offs..offs+size) will be read soon, so the
+// kernel may start reading the file pages ahead in the background. The call doesn't block
+// for the pages read.
+func (mmf *MMFile) WillNeed(offs int64, size int) error {
+	if mmf.mf == nil || size <= 0 || offs < 0 || offs >= mmf.size {
+		return nil
+	}
+	// the advised address must be page aligned, the mapped region starts from a page
+	ps := int64(os.Getpagesize())
+	start := offs / ps * ps
+	end := min(offs+int64(size), mmf.size)
+	return syscall.Madvise(mmf.mf[start:end], syscall.MADV_WILLNEED)
+}
@@ -148,8 +148,7 @@ func (mmf *MMFile) Flush() error {
 	if mmf.f == nil {
 		return errors.ErrClosed
 	}
-	mmf.mf.Flush()
-	return nil
+	return mmf.mf.Flush()
 }
 
 // Buffer returns Mapped memory slice to be read and written.
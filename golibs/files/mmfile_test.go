@@ -108,6 +108,24 @@ func TestGrowMMFile(t *testing.T) {
 	assert.Equal(t, buf, res)
 }
 
+func TestMMFile_WillNeed(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestMMFile_WillNeed")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir) // clean up
+
+	fn := path.Join(dir, "testFile")
+	assert.Nil(t, EnsureFileExists(fn))
+	mmf, err := NewMMFile(fn, 8*4096)
+	assert.Nil(t, err)
+	defer mmf.Close()
+
+	// the not aligned and the out of bounds regions are fine
+	assert.Nil(t, mmf.WillNeed(4093, 3*4096))
+	assert.Nil(t, mmf.WillNeed(7*4096, 10*4096))
+	assert.Nil(t, mmf.WillNeed(8*4096, 10))
+	assert.Nil(t, mmf.WillNeed(0, 0))
+}
+
 func TestSyncMMFile(t *testing.T) {
 	dir, err := os.MkdirTemp("", "TestSyncMMFile")
 	assert.Nil(t, err)
@@ -0,0 +1,99 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+)
+
+// CertLoader keeps the server certificate loaded from the files, so the certificate
+// may be reloaded (renewed) without restarting the server
+type CertLoader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":        tls.NoClientCert,
+	"none":    tls.NoClientCert,
+	"request": tls.RequestClientCert,
+	"verify":  tls.VerifyClientCertIfGiven,
+	"require": tls.RequireAndVerifyClientCert,
+}
+
+// TLSEnabled returns true if the transport connections must be encrypted
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
+// NewServerTLSConfig returns the server tls.Config and the CertLoader its certificate is
+// taken from. It returns errors.ErrInvalid if the config is not consistent, or the files
+// could not be read.
+func NewServerTLSConfig(cfg Config) (*tls.Config, *CertLoader, error) {
+	if !cfg.TLSEnabled() {
+		return nil, nil, fmt.Errorf("TLSCertFile must be specified: %w", errors.ErrInvalid)
+	}
+	if cfg.TLSKeyFile == "" {
+		return nil, nil, fmt.Errorf("TLSKeyFile must be specified for TLSCertFile=%s: %w", cfg.TLSCertFile, errors.ErrInvalid)
+	}
+	auth, ok := clientAuthTypes[cfg.TLSClientAuth]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown TLSClientAuth=%q, expected one of none, request, verify or require: %w",
+			cfg.TLSClientAuth, errors.ErrInvalid)
+	}
+	cl := &CertLoader{certFile: cfg.TLSCertFile, keyFile: cfg.TLSKeyFile}
+	if err := cl.Reload(); err != nil {
+		return nil, nil, err
+	}
+	tc := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: auth,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return cl.cert.Load(), nil
+		},
+	}
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read TLSCAFile=%s: %v: %w", cfg.TLSCAFile, err, errors.ErrInvalid)
+		}
+		tc.ClientCAs = x509.NewCertPool()
+		if !tc.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificates found in TLSCAFile=%s: %w", cfg.TLSCAFile, errors.ErrInvalid)
+		}
+	} else if auth == tls.VerifyClientCertIfGiven || auth == tls.RequireAndVerifyClientCert {
+		return nil, nil, fmt.Errorf("TLSCAFile must be specified for TLSClientAuth=%s: %w", cfg.TLSClientAuth, errors.ErrInvalid)
+	}
+	return tc, cl, nil
+}
+
+// Reload reads the certificate and the key files again. The previous certificate
+// is kept if the files could not be read.
+func (cl *CertLoader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(cl.certFile, cl.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load the certificate from TLSCertFile=%s and TLSKeyFile=%s: %v: %w",
+			cl.certFile, cl.keyFile, err, errors.ErrInvalid)
+	}
+	cl.cert.Store(&cert)
+	return nil
+}
@@ -31,6 +31,17 @@ type Config struct {
 
 	// Port is the port the server will listen on
 	Port int
+
+	// TLSCertFile is the path to the PEM encoded server certificate. If it is empty, the
+	// connections are not encrypted
+	TLSCertFile string
+	// TLSKeyFile is the path to the PEM encoded private key of the TLSCertFile certificate
+	TLSKeyFile string
+	// TLSCAFile is the path to the PEM encoded CA certificates the client certificates are verified by
+	TLSCAFile string
+	// TLSClientAuth defines whether the client certificates are requested and verified (mTLS):
+	// "none" (the default), "request", "verify" (verified if given) or "require" (required and verified)
+	TLSClientAuth string
 }
 
 // Addr returns the address string for the transport
@@ -90,4 +101,16 @@ func (c *Config) Apply(other *Config) {
 	if other.Port > 0 {
 		c.Port = other.Port
 	}
+	if other.TLSCertFile != "" {
+		c.TLSCertFile = other.TLSCertFile
+	}
+	if other.TLSKeyFile != "" {
+		c.TLSKeyFile = other.TLSKeyFile
+	}
+	if other.TLSCAFile != "" {
+		c.TLSCAFile = other.TLSCAFile
+	}
+	if other.TLSClientAuth != "" {
+		c.TLSClientAuth = other.TLSClientAuth
+	}
 }
@@ -16,6 +16,7 @@ package ulidutils
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/oklog/ulid/v2"
@@ -74,3 +75,19 @@ func PrevID(ulidID string) string {
 	}
 	return uID.String()
 }
+
+// MinIDAt returns the lowest ULID which may be generated at the time t (in milliseconds precision).
+// The value may be used as the inclusive lower bound when searching IDs created at or after t
+func MinIDAt(t time.Time) string {
+	var uID ulid.ULID
+	_ = uID.SetTime(ulid.Timestamp(t))
+	return uID.String()
+}
+
+// MaxIDAt returns the highest ULID which may be generated at the time t (in milliseconds precision).
+// The value may be used as the inclusive upper bound when searching IDs created at or before t
+func MaxIDAt(t time.Time) string {
+	uID := MaxULID
+	_ = uID.SetTime(ulid.Timestamp(t))
+	return uID.String()
+}
@@ -15,6 +15,9 @@ package ulidutils
 
 import (
 	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -47,6 +50,16 @@ func TestPrevIDInvalid(t *testing.T) {
 	})
 }
 
+func TestMinMaxIDAt(t *testing.T) {
+	now := time.Now()
+	id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
+	assert.True(t, MinIDAt(now) <= id)
+	assert.True(t, MaxIDAt(now) >= id)
+	assert.True(t, MaxIDAt(now.Add(-time.Millisecond)) < id)
+	assert.True(t, MinIDAt(now.Add(time.Millisecond)) > id)
+	assert.Equal(t, MinIDAt(now.Add(time.Millisecond)), NextID(MaxIDAt(now)))
+}
+
 func TestNewUUID(t *testing.T) {
 	assert.Equal(t, 16, len(NewUUID()))
 }
@@ -0,0 +1,174 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission limits the number of the requests served at a time. The same Limiter is used
+// for the gRPC and the HTTP requests, so the server could not be overloaded through any of the APIs.
+package admission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"google.golang.org/grpc"
+)
+
+type (
+	// Config defines how many requests the server serves at a time
+	Config struct {
+		// MaxConcurrent defines how many unary (the gRPC unary and the HTTP) requests may be served at a time.
+		// Zero value means no limit.
+		MaxConcurrent int
+		// MaxConcurrentStreams defines how many gRPC streams may be served at a time. The streams may last
+		// long, so they are counted separately and don't take the slots of the unary requests.
+		// Zero value means no limit.
+		MaxConcurrentStreams int
+		// MaxQueued defines how many requests of one method may wait for the concurrent requests
+		// to be finished. The requests above the limit are rejected right away.
+		MaxQueued int
+		// QueueTimeout defines how long a request may wait, the request is rejected when the time is over
+		QueueTimeout time.Duration
+	}
+
+	// Limiter limits the number of the requests served at a time. The requests above the limit wait
+	// in the per method queues, so a burst of one method requests could not push out the others.
+	Limiter struct {
+		exempt  []string
+		unary   *gate
+		streams *gate
+	}
+
+	// gate is the semaphore with the per method queues
+	gate struct {
+		cfg Config
+		sem chan struct{}
+
+		lock   sync.Mutex
+		queued map[string]int
+	}
+)
+
+// NewLimiter returns the Limiter for the cfg. The methods (the HTTP paths) starting from any of
+// the exempt prefixes are never limited, so the orchestrator could see the server is alive under the load.
+func NewLimiter(cfg Config, exempt ...string) *Limiter {
+	l := &Limiter{exempt: exempt}
+	if cfg.MaxConcurrent > 0 {
+		l.unary = newGate(cfg, cfg.MaxConcurrent)
+	}
+	if cfg.MaxConcurrentStreams > 0 {
+		l.streams = newGate(cfg, cfg.MaxConcurrentStreams)
+	}
+	return l
+}
+
+// Unary implements grpc.UnaryServerInterceptor
+func (l *Limiter) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	release, err := l.acquire(ctx, l.unary, info.FullMethod)
+	if err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+// Stream implements grpc.StreamServerInterceptor
+func (l *Limiter) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	release, err := l.acquire(ss.Context(), l.streams, info.FullMethod)
+	if err != nil {
+		return errors.GRPCWrap(err)
+	}
+	defer release()
+	return handler(srv, ss)
+}
+
+// HTTP returns the gin handler, which limits the HTTP requests the same way (and by the same slots)
+// as the gRPC unary ones. The requests are queued by their routes, the rejected requests get
+// the 429 (Too Many Requests) status.
+func (l *Limiter) HTTP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.FullPath()
+		if method == "" {
+			method = c.Request.URL.Path
+		}
+		release, err := l.acquire(c.Request.Context(), l.unary, method)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
+
+// acquire returns the function to be called when the request is served, or the ErrExhausted error
+// if the request could not be served within the QueueTimeout or its method queue is full
+func (l *Limiter) acquire(ctx context.Context, g *gate, method string) (func(), error) {
+	if g == nil || l.exempted(method) {
+		return func() {}, nil
+	}
+	return g.acquire(ctx, method)
+}
+
+func (l *Limiter) exempted(method string) bool {
+	for _, e := range l.exempt {
+		if strings.HasPrefix(method, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func newGate(cfg Config, size int) *gate {
+	return &gate{cfg: cfg, sem: make(chan struct{}, size), queued: make(map[string]int)}
+}
+
+func (g *gate) acquire(ctx context.Context, method string) (func(), error) {
+	release := func() { <-g.sem }
+	select {
+	case g.sem <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	g.lock.Lock()
+	if g.queued[method] >= g.cfg.MaxQueued {
+		g.lock.Unlock()
+		return nil, fmt.Errorf("too many requests %s, %d of them are waiting already: %w", method, g.cfg.MaxQueued, errors.ErrExhausted)
+	}
+	g.queued[method]++
+	g.lock.Unlock()
+	defer func() {
+		g.lock.Lock()
+		if g.queued[method]--; g.queued[method] == 0 {
+			delete(g.queued, method)
+		}
+		g.lock.Unlock()
+	}()
+
+	timer := time.NewTimer(g.cfg.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case g.sem <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("the request %s could not be served within %s, too many requests: %w", method, g.cfg.QueueTimeout, errors.ErrExhausted)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const healthPrefix = "/grpc.health.v1.Health/"
+
+func TestLimiter_Limit(t *testing.T) {
+	l := NewLimiter(Config{MaxConcurrent: 2, MaxQueued: 1, QueueTimeout: 50 * time.Millisecond}, healthPrefix)
+	g := l.unary
+	r1, err := l.acquire(context.Background(), g, "/a")
+	assert.Nil(t, err)
+	_, err = l.acquire(context.Background(), g, "/b")
+	assert.Nil(t, err)
+
+	// the request waits in the queue no longer than the timeout
+	start := time.Now()
+	_, err = l.acquire(context.Background(), g, "/a")
+	assert.True(t, errors.Is(err, errors.ErrExhausted))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// the method queue is full, so the next request of the method is rejected right away,
+	// but the other method requests may wait
+	g.cfg.QueueTimeout = time.Minute
+	done := make(chan error)
+	go func() {
+		r, err := l.acquire(context.Background(), g, "/a")
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+	assert.Eventually(t, func() bool {
+		g.lock.Lock()
+		defer g.lock.Unlock()
+		return g.queued["/a"] == 1
+	}, time.Second, time.Millisecond)
+	_, err = l.acquire(context.Background(), g, "/a")
+	assert.True(t, errors.Is(err, errors.ErrExhausted))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.acquire(ctx, g, "/b")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// the waiting request is served when a slot is released
+	r1()
+	assert.Nil(t, <-done)
+	assert.Empty(t, g.queued)
+
+	// the exempted methods are not limited
+	_, err = l.acquire(context.Background(), g, healthPrefix+"Check")
+	assert.Nil(t, err)
+}
+
+func TestLimiter_Interceptors(t *testing.T) {
+	l := NewLimiter(Config{MaxConcurrent: 1, MaxConcurrentStreams: 1, MaxQueued: 0, QueueTimeout: time.Second})
+	info := &grpc.UnaryServerInfo{FullMethod: "/a"}
+	sinfo := &grpc.StreamServerInfo{FullMethod: "/b"}
+	_, err := l.Unary(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		// the only unary slot is taken by the request
+		_, err := l.Unary(ctx, nil, info, func(ctx context.Context, req any) (any, error) { return nil, nil })
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		// but the streams are counted separately
+		return nil, l.Stream(nil, &testServerStream{ctx: ctx}, sinfo, func(srv any, stream grpc.ServerStream) error {
+			// the only stream slot is taken by the stream
+			err := l.Stream(nil, &testServerStream{ctx: ctx}, sinfo, func(srv any, stream grpc.ServerStream) error { return nil })
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+
+	// the slots are released
+	_, err = l.Unary(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) { return nil, nil })
+	assert.Nil(t, err)
+	err = l.Stream(nil, &testServerStream{ctx: context.Background()}, sinfo, func(srv any, stream grpc.ServerStream) error { return nil })
+	assert.Nil(t, err)
+
+	// the streams are not limited if MaxConcurrentStreams is not set
+	l = NewLimiter(Config{MaxConcurrent: 1, QueueTimeout: time.Second})
+	assert.Nil(t, l.streams)
+}
+
+func TestLimiter_HTTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l := NewLimiter(Config{MaxConcurrent: 1, MaxQueued: 0, QueueTimeout: time.Second}, "/healthz")
+	g := gin.New()
+	g.Use(l.HTTP())
+	call := func(path string) int {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+	g.GET("/logs/:id", func(c *gin.Context) {
+		// the only slot is taken by the request
+		assert.Equal(t, http.StatusTooManyRequests, call("/logs/2"))
+		assert.Equal(t, http.StatusOK, call("/healthz"))
+		c.String(http.StatusOK, "ok")
+	})
+	g.GET("/healthz", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	assert.Equal(t, http.StatusOK, call("/logs/1"))
+	// the slot is released
+	assert.Equal(t, http.StatusOK, call("/logs/1"))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *testServerStream) Context() context.Context {
+	return ss.ctx
+}
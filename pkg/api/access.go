@@ -0,0 +1,150 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/auth"
+	"github.com/solarisdb/solaris/pkg/storage"
+)
+
+// The logs access is checked for the requests of the authenticated clients only (see auth.Principal), so
+// the requests are not limited if the authentication is disabled. The log owner and the clients the log is
+// granted to may read and append the log records, only the owner may update and delete the log. The logs
+// without the owner are available to everyone.
+
+// deleteLogsPageSize is the number of logs DeleteLogs reads at a time to find the logs the client owns
+const deleteLogsPageSize = 1000
+
+// canAccess returns true if the principal may read and append the log records
+func canAccess(p auth.Principal, log *solaris.Log) bool {
+	return canOwn(p, log) || slices.Contains(log.Grants, p.ID)
+}
+
+// canOwn returns true if the principal may update and delete the log
+func canOwn(p auth.Principal, log *solaris.Log) bool {
+	return log.Owner == "" || log.Owner == p.ID
+}
+
+// checkAccess returns errors.ErrNotAuthorized if the ctx client could not access the log, the owner
+// specifies whether the client must be the log owner
+func checkAccess(ctx context.Context, log *solaris.Log, owner bool) error {
+	p, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if (owner && !canOwn(p, log)) || (!owner && !canAccess(p, log)) {
+		return fmt.Errorf("the client %s could not access the log ID=%s: %w", p.ID, log.ID, errors.ErrNotAuthorized)
+	}
+	return nil
+}
+
+// setOwner sets the ctx client as the log owner, the logs created without the authentication keep the owner provided
+func setOwner(ctx context.Context, log *solaris.Log) {
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		log.Owner = p.ID
+	}
+}
+
+// accessibleLogs returns the logs the ctx client may access
+func accessibleLogs(ctx context.Context, logs []*solaris.Log, owner bool) []*solaris.Log {
+	if _, ok := auth.PrincipalFromContext(ctx); !ok {
+		return logs
+	}
+	res := make([]*solaris.Log, 0, len(logs))
+	for _, l := range logs {
+		if checkAccess(ctx, l, owner) == nil {
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+// accessibleLogIDs returns the IDs of the logs the ctx client may access. The request for one log fails
+// with errors.ErrNotAuthorized, if the client could not access it, but the inaccessible logs of the request
+// for many logs are skipped. The logs, which don't exist, are skipped as well, but the only log ID is returned
+// as is, so the request fails or returns nothing the same way as without the authentication.
+func (s *Service) accessibleLogIDs(ctx context.Context, logIDs []string, owner bool) ([]string, error) {
+	if _, ok := auth.PrincipalFromContext(ctx); !ok {
+		return logIDs, nil
+	}
+	res := make([]string, 0, len(logIDs))
+	for _, id := range logIDs {
+		log, err := s.LogsStorage.GetLogByID(ctx, id)
+		if errors.Is(err, errors.ErrNotExist) {
+			if len(logIDs) == 1 {
+				res = append(res, id)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = checkAccess(ctx, log, owner); err != nil {
+			if len(logIDs) == 1 {
+				return nil, err
+			}
+			continue
+		}
+		res = append(res, id)
+	}
+	return res, nil
+}
+
+// ownedLogIDs returns the IDs of the logs matching the condition, which the ctx client owns
+func (s *Service) ownedLogIDs(ctx context.Context, cond string) ([]string, error) {
+	var res []string
+	qr := storage.QueryLogsRequest{Condition: cond, Limit: deleteLogsPageSize}
+	for {
+		ls, err := s.LogsStorage.QueryLogs(ctx, qr)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range accessibleLogs(ctx, ls.Logs, true) {
+			res = append(res, l.ID)
+		}
+		if ls.NextPageID == "" {
+			return res, nil
+		}
+		qr.Page = ls.NextPageID
+	}
+}
+
+// checkAdmin returns errors.ErrNotAuthorized if the ctx client is not one of the admins, who may run
+// the administrative requests
+func checkAdmin(ctx context.Context, admins []string) error {
+	p, ok := auth.PrincipalFromContext(ctx)
+	if !ok || slices.Contains(admins, p.ID) {
+		return nil
+	}
+	return fmt.Errorf("the client %s is not an admin: %w", p.ID, errors.ErrNotAuthorized)
+}
+
+// checkLogAccess returns errors.ErrNotAuthorized if the ctx client could not access the log by its ID
+func (s *Service) checkLogAccess(ctx context.Context, logID string, owner bool) error {
+	if _, ok := auth.PrincipalFromContext(ctx); !ok {
+		return nil
+	}
+	log, err := s.LogsStorage.GetLogByID(ctx, logID)
+	if err != nil {
+		return err
+	}
+	return checkAccess(ctx, log, owner)
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestService_LogsAccess(t *testing.T) {
+	svc, _, closeF := newTestLocalService(t, t.TempDir())
+	defer closeF()
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{ID: "owner"})
+	granted := auth.WithPrincipal(context.Background(), auth.Principal{ID: "granted"})
+	denied := auth.WithPrincipal(context.Background(), auth.Principal{ID: "denied"})
+
+	// the owner is the client created the log, whatever is requested
+	private, err := svc.CreateLog(owner, &solaris.Log{Owner: "denied", Tags: map[string]string{"t": "1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "owner", private.Owner)
+	private.Grants = []string{"granted"}
+	shared, err := svc.UpdateLog(owner, private)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"granted"}, shared.Grants)
+	// the logs created without the authentication are available to everyone
+	public, err := svc.CreateLog(context.Background(), &solaris.Log{Tags: map[string]string{"t": "1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "", public.Owner)
+
+	appendRec := func(ctx context.Context, logID string) error {
+		_, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: logID, Records: []*solaris.Record{{Payload: []byte(logID)}}})
+		return err
+	}
+	assert.Nil(t, appendRec(owner, private.ID))
+	assert.Nil(t, appendRec(granted, private.ID))
+	assert.Equal(t, codes.PermissionDenied, status.Code(appendRec(denied, private.ID)))
+	assert.Nil(t, appendRec(denied, public.ID))
+
+	// the single log is denied explicitly, but the inaccessible logs of many are skipped
+	payloads := func(ctx context.Context, req *solaris.QueryRecordsRequest) []string {
+		res, err := svc.QueryRecords(ctx, req)
+		assert.Nil(t, err)
+		var ps []string
+		for _, r := range res.Records {
+			ps = append(ps, string(r.Payload))
+		}
+		slices.Sort(ps)
+		return ps
+	}
+	both := []string{private.ID, private.ID, public.ID}
+	slices.Sort(both)
+	for _, ctx := range []context.Context{owner, granted} {
+		assert.Equal(t, []string{private.ID, private.ID}, payloads(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{private.ID}, Limit: 10}))
+		assert.Equal(t, both, payloads(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{private.ID, public.ID}, Limit: 10}))
+		assert.Equal(t, both, payloads(ctx, &solaris.QueryRecordsRequest{LogsCondition: "tag('t') = '1'", Limit: 10}))
+	}
+	_, err = svc.QueryRecords(denied, &solaris.QueryRecordsRequest{LogIDs: []string{private.ID}, Limit: 10})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, []string{public.ID}, payloads(denied, &solaris.QueryRecordsRequest{LogIDs: []string{private.ID, public.ID}, Limit: 10}))
+	// the missing logs are skipped like the inaccessible ones, so the client doesn't learn which logs exist
+	svc.cfg.CheckLogsExist = true
+	assert.Equal(t, []string{public.ID}, payloads(denied, &solaris.QueryRecordsRequest{LogIDs: []string{"missing", public.ID}, Limit: 10}))
+	cnt, err := svc.CountRecords(denied, &solaris.QueryRecordsRequest{LogIDs: []string{"missing", private.ID, public.ID}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), cnt.Total)
+	svc.cfg.CheckLogsExist = false
+	assert.Equal(t, []string{public.ID}, payloads(denied, &solaris.QueryRecordsRequest{LogsCondition: "tag('t') = '1'", Limit: 10}))
+	cnt, err = svc.CountRecords(denied, &solaris.QueryRecordsRequest{LogsCondition: "tag('t') = '1'"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), cnt.Total)
+	// no principal, no limits
+	assert.Equal(t, both, payloads(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{private.ID, public.ID}, Limit: 10}))
+
+	ql, err := svc.QueryLogs(denied, &solaris.QueryLogsRequest{Condition: "tag('t') = '1'", Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ql.Logs))
+	_, err = svc.GetLog(denied, &solaris.GetLogRequest{ID: private.ID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = svc.GetLog(granted, &solaris.GetLogRequest{ID: private.ID})
+	assert.Nil(t, err)
+
+	// only the owner may update and delete the log
+	_, err = svc.UpdateLog(granted, shared)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = svc.DeleteLogs(granted, &solaris.DeleteLogsRequest{LogIDs: []string{private.ID}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	dr, err := svc.DeleteLogs(granted, &solaris.DeleteLogsRequest{Condition: "tag('t') = '1'"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{public.ID}, dr.DeletedIDs)
+	dr, err = svc.DeleteLogs(owner, &solaris.DeleteLogsRequest{LogIDs: []string{private.ID, public.ID}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{private.ID}, dr.DeletedIDs)
+}
+
+func TestService_AdminAccess(t *testing.T) {
+	cfg := GetDefaultConfig()
+	cfg.AdminPrincipals = []string{"admin"}
+	svc := NewService(cfg)
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{ID: "admin"})
+	client := auth.WithPrincipal(context.Background(), auth.Principal{ID: "client"})
+
+	_, err := svc.SetReadOnly(client, &solaris.SetReadOnlyRequest{ReadOnly: true})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = svc.GetStorageLayout(client, &solaris.GetStorageLayoutRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Nil(t, svc.checkWritable())
+
+	ro, err := svc.SetReadOnly(admin, &solaris.SetReadOnlyRequest{ReadOnly: true})
+	assert.Nil(t, err)
+	assert.False(t, ro.WasReadOnly)
+	// the requests are not limited without the authentication
+	ro, err = svc.SetReadOnly(context.Background(), &solaris.SetReadOnlyRequest{ReadOnly: false})
+	assert.Nil(t, err)
+	assert.True(t, ro.WasReadOnly)
+	_, err = svc.GetStorageLayout(admin, &solaris.GetStorageLayoutRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/solarisdb/solaris/golibs/container/lru"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/ql"
+)
+
+type (
+	// conditions is the LRU cache of the compiled records conditions, which are referred by their handles
+	conditions struct {
+		cache *lru.ECache[condKey, string, compiledCond]
+		ttl   time.Duration
+		parse func(cond string) (*ql.Expression, error)
+	}
+
+	// condKey is the conditions cache key. The cond is empty when the compiled condition is looked up
+	// by the handle only, so it could not be created if it is not in the cache.
+	condKey struct {
+		handle string
+		cond   string
+	}
+
+	compiledCond struct {
+		cond      string
+		expr      *ql.Expression
+		expiresAt time.Time
+	}
+)
+
+func newConditions(maxSize int, ttl time.Duration, parse func(cond string) (*ql.Expression, error)) (*conditions, error) {
+	c := &conditions{ttl: ttl, parse: parse}
+	cache, err := lru.NewECache[condKey, string, compiledCond](maxSize, func(k condKey) string { return k.handle }, c.create, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.cache = cache
+	return c, nil
+}
+
+// compile returns the handle and the compiled condition for cond. The condition is parsed only if it is not
+// in the cache yet or the cached one is expired.
+func (c *conditions) compile(cond string) (string, compiledCond, error) {
+	if len(cond) == 0 {
+		return "", compiledCond{}, fmt.Errorf("the condition must be specified: %w", errors.ErrInvalid)
+	}
+	k := condKey{handle: condHandle(cond), cond: cond}
+	cc, err := c.cache.GetOrCreate(k)
+	if err == nil && cc.expired() {
+		c.cache.Remove(k)
+		cc, err = c.cache.GetOrCreate(k)
+	}
+	return k.handle, cc, err
+}
+
+// get returns the compiled condition by its handle. It returns errors.ErrNotExist if the handle
+// is unknown or expired.
+func (c *conditions) get(handle string) (compiledCond, error) {
+	k := condKey{handle: handle}
+	cc, err := c.cache.GetOrCreate(k)
+	if err != nil {
+		return compiledCond{}, err
+	}
+	if cc.expired() {
+		c.cache.Remove(k)
+		return compiledCond{}, fmt.Errorf("the condition handle %q is expired: %w", handle, errors.ErrNotExist)
+	}
+	return cc, nil
+}
+
+// invalidate removes the compiled condition by its handle. It returns true if the condition was in the cache.
+func (c *conditions) invalidate(handle string) bool {
+	return c.cache.Remove(condKey{handle: handle})
+}
+
+func (c *conditions) create(k condKey) (compiledCond, error) {
+	if len(k.cond) == 0 {
+		return compiledCond{}, fmt.Errorf("the condition handle %q is not found: %w", k.handle, errors.ErrNotExist)
+	}
+	expr, err := c.parse(k.cond)
+	if err != nil {
+		return compiledCond{}, fmt.Errorf("condition=%q parse error: %w", k.cond, err)
+	}
+	return compiledCond{cond: k.cond, expr: expr, expiresAt: time.Now().Add(c.ttl)}, nil
+}
+
+func (cc compiledCond) expired() bool {
+	return !time.Now().Before(cc.expiresAt)
+}
+
+// condHandle returns the handle for the condition, the same condition always has the same handle
+func condHandle(cond string) string {
+	h := sha256.Sum256([]byte(cond))
+	return hex.EncodeToString(h[:16])
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"time"
+
+	"github.com/solarisdb/solaris/golibs/files"
+	"github.com/solarisdb/solaris/pkg/ql"
+)
+
+type (
+	// Config defines the Service settings
+	Config struct {
+		// LogsCondLimits defines the limits for the logs conditions, which are used for querying the logs catalog
+		LogsCondLimits ql.Limits
+		// MaxCompiledConditions defines how many compiled records conditions may be kept at a time
+		MaxCompiledConditions int
+		// CompiledConditionTTL defines how long a compiled records condition is kept since it was compiled
+		CompiledConditionTTL time.Duration
+		// CheckLogsExist specifies that QueryRecords and CountRecords return errors.ErrNotExist if a log
+		// requested by its ID doesn't exist. Otherwise, the missing logs are read as the empty ones.
+		CheckLogsExist bool
+		// MaxRecordsLimit defines the maximum number of records QueryRecords may return for one log at a time
+		MaxRecordsLimit int
+		// MaxBunchSize defines the maximum total size (in bytes) of the records payloads QueryRecords may return
+		// for one log at a time
+		MaxBunchSize int
+		// DefaultFieldStatsSample defines the number of records sampled by FieldStats if the request doesn't specify it
+		DefaultFieldStatsSample int
+		// MaxFieldStatsSample defines the maximum number of records FieldStats may sample
+		MaxFieldStatsSample int
+		// MaxFieldStatsTopN defines the maximum number of the most frequent values FieldStats may return per field,
+		// the requests asking for more are rejected
+		MaxFieldStatsTopN int
+		// MaxRecordsSlackPct defines how many records over the log maxRecords (in percents of it) the appends may
+		// leave in the capped log, so the oldest log chunk is not rewritten by every append. The retention sweeper
+		// trims the log to maxRecords exactly.
+		MaxRecordsSlackPct int
+		// AdminPrincipals contains the IDs of the authenticated clients (see auth.Principal), which may run the
+		// administrative requests (SetReadOnly, GetStorageLayout). The requests are not limited if the
+		// authentication is disabled.
+		AdminPrincipals []string
+		// ReadOnly specifies that the Service is started in the read-only mode, when the requests changing
+		// the logs or their records are rejected. The mode may be changed by SetReadOnly at runtime.
+		ReadOnly bool
+	}
+)
+
+// GetDefaultConfig returns the default Service config
+func GetDefaultConfig() Config {
+	return Config{
+		LogsCondLimits: ql.Limits{
+			MaxLength: 16 * 1024,
+			MaxNodes:  2000,
+			MaxDepth:  20,
+		},
+		MaxCompiledConditions:   1000,
+		CompiledConditionTTL:    time.Hour,
+		CheckLogsExist:          true,
+		MaxRecordsLimit:         10000,
+		MaxBunchSize:            2000 * files.BlockSize,
+		DefaultFieldStatsSample: 1000,
+		MaxFieldStatsSample:     10000,
+		MaxFieldStatsTopN:       100,
+		MaxRecordsSlackPct:      10,
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+)
+
+const (
+	// hllPrecision defines the number of the HyperLogLog registers (2^hllPrecision),
+	// the standard error of the estimation is 1.04/sqrt(2^hllPrecision) ~ 1.6%
+	hllPrecision = 12
+	// cmsWidth and cmsDepth define the count-min sketch size. The overestimation of a value count
+	// is not greater than e/cmsWidth of the total count with the probability 1-e^-cmsDepth
+	cmsWidth = 2048
+	cmsDepth = 4
+	// maxStatsFields is the maximum number of fields the statistics is collected for,
+	// the fields found after the limit is reached are not considered
+	maxStatsFields = 256
+)
+
+type (
+	// fieldsStats collects the statistics of the fields of the records with JSON object payloads.
+	// The fields are the top-level keys of the objects.
+	fieldsStats struct {
+		topN       int
+		sampled    int64
+		structured int64
+		fields     map[string]*fieldStats
+	}
+
+	// fieldStats collects the statistics of one field values
+	fieldStats struct {
+		count int64
+		hll   *hll
+		cms   *cms
+		// top contains the candidates to the most frequent values with their estimated counts
+		top map[string]int64
+	}
+
+	// hll is the HyperLogLog cardinality estimator
+	hll struct {
+		regs []uint8
+	}
+
+	// cms is the count-min sketch, which estimates the values frequencies
+	cms struct {
+		counts [cmsDepth][cmsWidth]uint32
+	}
+)
+
+func newFieldsStats(topN int) *fieldsStats {
+	return &fieldsStats{topN: topN, fields: make(map[string]*fieldStats)}
+}
+
+// add considers the record in the statistics. The records with not JSON object payloads are
+// counted as sampled, but they don't contribute to the fields statistics.
+func (fs *fieldsStats) add(r *solaris.Record) {
+	fs.sampled++
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(r.Payload, &obj); err != nil || obj == nil {
+		return
+	}
+	fs.structured++
+	for name, raw := range obj {
+		f, ok := fs.fields[name]
+		if !ok {
+			if len(fs.fields) >= maxStatsFields {
+				continue
+			}
+			f = &fieldStats{hll: newHLL(), cms: new(cms), top: make(map[string]int64)}
+			fs.fields[name] = f
+		}
+		f.add(fieldValue(raw), fs.topN)
+	}
+}
+
+// result returns the collected statistics, the fields are sorted by their names
+func (fs *fieldsStats) result() *solaris.FieldStatsResult {
+	res := &solaris.FieldStatsResult{SampleSize: fs.sampled, Structured: fs.structured}
+	for name, f := range fs.fields {
+		res.Fields = append(res.Fields, &solaris.FieldStats{
+			Name:        name,
+			Count:       f.count,
+			Cardinality: f.hll.estimate(),
+			TopValues:   f.topValues(fs.topN),
+		})
+	}
+	sort.Slice(res.Fields, func(i, j int) bool {
+		return res.Fields[i].Name < res.Fields[j].Name
+	})
+	return res
+}
+
+func (f *fieldStats) add(v string, topN int) {
+	f.count++
+	h := hash64(v)
+	f.hll.add(h)
+	cnt := f.cms.add(h)
+	if _, ok := f.top[v]; ok || len(f.top) < topCandidates(topN) {
+		f.top[v] = cnt
+		return
+	}
+	minV, minCnt := "", int64(math.MaxInt64)
+	for tv, tc := range f.top {
+		if tc < minCnt {
+			minV, minCnt = tv, tc
+		}
+	}
+	if cnt > minCnt {
+		delete(f.top, minV)
+		f.top[v] = cnt
+	}
+}
+
+func (f *fieldStats) topValues(topN int) []*solaris.ValueCount {
+	res := make([]*solaris.ValueCount, 0, len(f.top))
+	for v, c := range f.top {
+		res = append(res, &solaris.ValueCount{Value: v, Count: c})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count == res[j].Count {
+			return res[i].Value < res[j].Value
+		}
+		return res[i].Count > res[j].Count
+	})
+	if len(res) > topN {
+		res = res[:topN]
+	}
+	return res
+}
+
+// topCandidates returns how many top values candidates are tracked to return topN values. Keeping
+// more candidates than needed lowers the chance to miss a frequent value, which is met late in the sample.
+func topCandidates(topN int) int {
+	return max(4*topN, 32)
+}
+
+// fieldValue returns the string representation of the JSON value: strings are unquoted,
+// the other values are represented by their compacted JSON text
+func fieldValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var bb bytes.Buffer
+	if err := json.Compact(&bb, raw); err != nil {
+		return string(raw)
+	}
+	return bb.String()
+}
+
+func newHLL() *hll {
+	return &hll{regs: make([]uint8, 1<<hllPrecision)}
+}
+
+func (h *hll) add(x uint64) {
+	idx := x >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > h.regs[idx] {
+		h.regs[idx] = rank
+	}
+}
+
+func (h *hll) estimate() int64 {
+	m := float64(len(h.regs))
+	var sum float64
+	zeros := 0
+	for _, r := range h.regs {
+		sum += 1.0 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	est := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		// the linear counting is more accurate for the small cardinalities
+		est = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(est))
+}
+
+// add increments the counters for the value hash x and returns the value count estimation
+func (c *cms) add(x uint64) int64 {
+	h1, h2 := uint32(x), uint32(x>>32)|1
+	res := uint32(math.MaxUint32)
+	for i := range c.counts {
+		idx := (h1 + uint32(i)*h2) % cmsWidth
+		c.counts[i][idx]++
+		res = min(res, c.counts[i][idx])
+	}
+	return int64(res)
+}
+
+// hash64 returns the well mixed 64-bit hash of the string, the FNV-1a hash is finalized by the
+// splitmix64 mixer to spread the short strings hashes over all the bits
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
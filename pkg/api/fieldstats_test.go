@@ -0,0 +1,103 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHLL_Estimate(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 5000, 100000} {
+		h := newHLL()
+		for i := 0; i < n; i++ {
+			h.add(hash64(fmt.Sprint("value", i)))
+			h.add(hash64(fmt.Sprint("value", i))) // duplicates are not counted
+		}
+		est := h.estimate()
+		// 3 standard errors
+		assert.InDelta(t, float64(n), float64(est), 3*1.04/math.Sqrt(1<<hllPrecision)*float64(n)+1, "n=%d", n)
+	}
+}
+
+func TestCMS_Add(t *testing.T) {
+	c := new(cms)
+	counts := map[string]int64{}
+	total := 0
+	for i := 0; i < 10000; i++ {
+		v := fmt.Sprint(i % 3000)
+		if i%2 == 0 {
+			v = "frequent"
+		}
+		counts[v]++
+		total++
+		c.add(hash64(v))
+	}
+	for v, cnt := range counts {
+		h := hash64(v)
+		est := c.add(h) - 1
+		assert.True(t, est >= cnt, "the count-min sketch never underestimates, value=%s", v)
+		assert.True(t, est <= cnt+int64(math.E*float64(total)/cmsWidth), "value=%s", v)
+	}
+}
+
+func TestFieldsStats(t *testing.T) {
+	fs := newFieldsStats(3)
+	levels := []string{"error", "error", "error", "error", "error", "warn", "warn", "warn", "info", "info"}
+	for i := 0; i < 20000; i++ {
+		payload := fmt.Sprintf(`{"user": "user%d", "level": %q, "code": %d}`, i%5000, levels[i%len(levels)], i%7)
+		if i%4 == 0 {
+			payload = fmt.Sprintf(`{"user": "user%d", "nested": {"a": [1, 2]}}`, i%5000)
+		}
+		fs.add(&solaris.Record{Payload: []byte(payload)})
+	}
+	fs.add(&solaris.Record{Payload: []byte("plain text")})
+	fs.add(&solaris.Record{Payload: []byte("[1, 2, 3]")})
+
+	res := fs.result()
+	assert.Equal(t, int64(20002), res.SampleSize)
+	assert.Equal(t, int64(20000), res.Structured)
+	assert.Equal(t, []string{"code", "level", "nested", "user"}, fieldNames(res))
+
+	code, level, nested, user := res.Fields[0], res.Fields[1], res.Fields[2], res.Fields[3]
+	assert.Equal(t, int64(15000), code.Count)
+	assert.Equal(t, int64(7), code.Cardinality)
+	assert.Equal(t, int64(15000), level.Count)
+	assert.Equal(t, int64(3), level.Cardinality)
+	assert.Equal(t, int64(20000), user.Count)
+	assert.InDelta(t, 5000, user.Cardinality, 5000*0.05)
+	assert.Equal(t, int64(5000), nested.Count)
+	assert.Equal(t, int64(1), nested.Cardinality)
+	assert.Equal(t, `{"a":[1,2]}`, nested.TopValues[0].Value)
+
+	// the values counts are estimated, but they are exact for the small cardinalities
+	assert.Equal(t, []*solaris.ValueCount{{Value: "error", Count: 7000}, {Value: "warn", Count: 5000}, {Value: "info", Count: 3000}}, level.TopValues)
+	assert.Len(t, user.TopValues, 3)
+	for _, vc := range user.TopValues {
+		assert.InDelta(t, 4, vc.Count, math.E*20000/cmsWidth)
+	}
+}
+
+func fieldNames(res *solaris.FieldStatsResult) []string {
+	var names []string
+	for _, f := range res.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
@@ -15,6 +15,7 @@
 package api
 
 import (
+	"container/heap"
 	"context"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/container/iterable"
@@ -22,45 +23,208 @@ import (
 	"github.com/solarisdb/solaris/pkg/storage"
 )
 
+type (
+	// mixer merges the records of many logs. The next record of every log is kept in the heap,
+	// so selecting the next record of the result takes O(log(logs)).
+	mixer struct {
+		ctx    context.Context
+		cancel context2.CancelErrFunc
+		its    []iterable.Iterator[*solaris.Record]
+		cits   []*cappedIterator
+		h      recordsHeap
+		init   bool
+		dedup  bool
+	}
+
+	// recordsHeap implements heap.Interface for the next records of the logs iterators
+	recordsHeap struct {
+		srcs []mixerSrc
+		less func(r1, r2 *solaris.Record) bool
+	}
+
+	// mixerSrc is the next record of a log iterator
+	mixerSrc struct {
+		r  *solaris.Record
+		it iterable.Iterator[*solaris.Record]
+	}
+
+	// cappedIterator stops the iteration after the maximum number of records is returned
+	cappedIterator struct {
+		iterable.Iterator[*solaris.Record]
+		left int64
+	}
+)
+
+var _ iterable.Iterator[*solaris.Record] = (*mixer)(nil)
+
 // newMixer returns an iterator which mixes a bunch of iterators around the slice logIDs and mix them together to
-// retrieve records either in ascending or descending order.
-func newMixer(ctx context.Context, cancel context2.CancelErrFunc, ls storage.Log, baseQuery storage.QueryRecordsRequest, logIDs []string) iterable.Iterator[*solaris.Record] {
+// retrieve records either in ascending or descending order. If maxPerLog > 0, every log contributes no more than
+// maxPerLog records to the result, the log records over the limit are skipped, so the other logs records could
+// get into the result limited by the baseQuery.Limit. The logs are read lazily, page by page, when their records
+// are selected. The iteration stops as soon as the ctx is closed, and the ctx is canceled by Close, so the
+// logs reads with the ctx are interrupted. If dedup is true, the records with the same ID are returned once.
+func newMixer(ctx context.Context, cancel context2.CancelErrFunc, ls storage.Log, baseQuery storage.QueryRecordsRequest, logIDs []string, maxPerLog int64, dedup bool) *mixer {
+	m := &mixer{ctx: ctx, cancel: cancel, its: make([]iterable.Iterator[*solaris.Record], len(logIDs)), dedup: dedup}
+	m.h.less = ascendingRecords
+	if baseQuery.Descending {
+		m.h.less = descendingRecords
+	}
 	if len(logIDs) == 0 {
-		return &iterable.EmptyIterator[*solaris.Record]{}
+		return m
 	}
-	mxs := make([]iterable.Iterator[*solaris.Record], len(logIDs))
-	pits := make([]*rIterator, len(mxs))
-	i := 0
 
-	for _, lid := range logIDs {
+	// every log is read by small pages first, so the total number of records requested from all
+	// the logs initially is about the baseQuery.Limit. The page size grows if the log is read actively.
+	initPageSize := max(1, baseQuery.Limit/int64(len(logIDs)))
+	for i, lid := range logIDs {
 		baseQuery.LogID = lid
-		pits[i] = newRIterator(ctx, cancel, ls, baseQuery)
-		mxs[i] = pits[i]
-		i++
+		rit := newRIterator(ctx, cancel, ls, baseQuery)
+		rit.pageSize = min(rit.pageSize, initPageSize)
+		m.its[i] = rit
+		if maxPerLog > 0 {
+			rit.pageSize = min(rit.pageSize, maxPerLog)
+			cit := &cappedIterator{Iterator: rit, left: maxPerLog}
+			m.cits = append(m.cits, cit)
+			m.its[i] = cit
+		}
 	}
+	return m
+}
 
-	var cmpF iterable.SelectF[*solaris.Record]
-	if baseQuery.Descending {
-		cmpF = descendingRecords
+// HasNext implements iterable.Iterator
+func (m *mixer) HasNext() bool {
+	if m.ctx.Err() != nil {
+		return false
+	}
+	m.fill()
+	return m.h.Len() > 0
+}
+
+// Next implements iterable.Iterator
+func (m *mixer) Next() (*solaris.Record, bool) {
+	if m.ctx.Err() != nil {
+		return nil, false
+	}
+	m.fill()
+	if m.h.Len() == 0 {
+		return nil, false
+	}
+	res := m.h.srcs[0].r
+	m.advance()
+	// the records with the same ID follow each other, so all of them are skipped at once
+	// and the heap top is always the next record to be returned
+	for m.dedup && m.h.Len() > 0 && m.h.srcs[0].r.ID == res.ID {
+		m.advance()
+	}
+	return res, true
+}
+
+// advance replaces the heap top record by the next record of its log
+func (m *mixer) advance() {
+	src := &m.h.srcs[0]
+	if r, ok := next(src.it); ok {
+		src.r = r
+		heap.Fix(&m.h, 0)
 	} else {
-		cmpF = ascendingRecords
+		heap.Pop(&m.h)
+	}
+}
+
+// Close implements iterable.Iterator
+func (m *mixer) Close() error {
+	if m.cancel != nil {
+		m.cancel(nil)
+	}
+	var err error
+	for _, it := range m.its {
+		if cerr := it.Close(); err == nil {
+			err = cerr
+		}
 	}
+	m.h.srcs = nil
+	return err
+}
 
-	// mixing the iterator until only one left
-	for len(mxs) > 1 {
-		for i := 0; i < len(mxs)-1; i += 2 {
-			m := &iterable.Mixer[*solaris.Record]{}
-			m.Init(cmpF, mxs[i], mxs[i+1])
-			mxs[i/2] = m
+// fill reads the first record of every log into the heap, if it is not done yet
+func (m *mixer) fill() {
+	if m.init {
+		return
+	}
+	m.init = true
+	m.h.srcs = make([]mixerSrc, 0, len(m.its))
+	for _, it := range m.its {
+		if r, ok := next(it); ok {
+			m.h.srcs = append(m.h.srcs, mixerSrc{r: r, it: it})
 		}
-		if len(mxs)&1 == 1 {
-			mxs[len(mxs)/2] = mxs[len(mxs)-1]
-			mxs = mxs[:len(mxs)/2+1]
-		} else {
-			mxs = mxs[:len(mxs)/2]
+	}
+	heap.Init(&m.h)
+}
+
+func next(it iterable.Iterator[*solaris.Record]) (*solaris.Record, bool) {
+	if !it.HasNext() {
+		return nil, false
+	}
+	return it.Next()
+}
+
+// Len implements heap.Interface
+func (h *recordsHeap) Len() int {
+	return len(h.srcs)
+}
+
+// Less implements heap.Interface
+func (h *recordsHeap) Less(i, j int) bool {
+	return h.less(h.srcs[i].r, h.srcs[j].r)
+}
+
+// Swap implements heap.Interface
+func (h *recordsHeap) Swap(i, j int) {
+	h.srcs[i], h.srcs[j] = h.srcs[j], h.srcs[i]
+}
+
+// Push implements heap.Interface
+func (h *recordsHeap) Push(x any) {
+	h.srcs = append(h.srcs, x.(mixerSrc))
+}
+
+// Pop implements heap.Interface
+func (h *recordsHeap) Pop() any {
+	n := len(h.srcs) - 1
+	res := h.srcs[n]
+	h.srcs[n] = mixerSrc{}
+	h.srcs = h.srcs[:n]
+	return res
+}
+
+// capped returns true if some log records were skipped, cause the log reached its maximum number of records
+func (m *mixer) capped() bool {
+	for _, cit := range m.cits {
+		if cit.capped() {
+			return true
 		}
 	}
-	return mxs[0]
+	return false
+}
+
+// HasNext implements iterable.Iterator
+func (ci *cappedIterator) HasNext() bool {
+	return ci.left > 0 && ci.Iterator.HasNext()
+}
+
+// Next implements iterable.Iterator
+func (ci *cappedIterator) Next() (*solaris.Record, bool) {
+	if ci.left <= 0 {
+		return nil, false
+	}
+	r, ok := ci.Iterator.Next()
+	if ok {
+		ci.left--
+	}
+	return r, ok
+}
+
+func (ci *cappedIterator) capped() bool {
+	return ci.left <= 0 && ci.Iterator.HasNext()
 }
 
 func ascendingRecords(r1, r2 *solaris.Record) bool {
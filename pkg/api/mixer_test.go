@@ -23,11 +23,12 @@ import (
 	"github.com/solarisdb/solaris/golibs/context"
 	"github.com/solarisdb/solaris/pkg/storage"
 	"github.com/stretchr/testify/assert"
+	"slices"
 	"testing"
 )
 
 func TestMixer_NoLogs(t *testing.T) {
-	mx := newMixer(context2.Background(), nil, nil, storage.QueryRecordsRequest{}, nil)
+	mx := newMixer(context2.Background(), nil, nil, storage.QueryRecordsRequest{}, nil, 0, false)
 	assert.False(t, mx.HasNext())
 	_, ok := mx.Next()
 	assert.False(t, ok)
@@ -43,7 +44,7 @@ func TestMixer_OneIterator(t *testing.T) {
 
 	ctx, cancel := context.WithCancelError(context2.Background())
 	baseQuery := storage.QueryRecordsRequest{Limit: 100}
-	mx := newMixer(ctx, cancel, ls, baseQuery, []string{"1"})
+	mx := newMixer(ctx, cancel, ls, baseQuery, []string{"1"}, 0, false)
 	idx := 0
 	for mx.HasNext() {
 		r, ok := mx.Next()
@@ -54,7 +55,7 @@ func TestMixer_OneIterator(t *testing.T) {
 	}
 
 	baseQuery = storage.QueryRecordsRequest{LogID: "1", Limit: 1, StartID: recs[5].ID}
-	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"1"})
+	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"1"}, 0, false)
 	idx = 5
 	for mx.HasNext() {
 		r, ok := mx.Next()
@@ -64,7 +65,7 @@ func TestMixer_OneIterator(t *testing.T) {
 	}
 
 	baseQuery = storage.QueryRecordsRequest{LogID: "1", Limit: 1, Descending: true, StartID: recs[5].ID}
-	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"1"})
+	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"1"}, 0, false)
 	idx = 5
 	for mx.HasNext() {
 		r, ok := mx.Next()
@@ -90,30 +91,101 @@ func TestMixer_ThreeIterators(t *testing.T) {
 
 	ctx, cancel := context.WithCancelError(context2.Background())
 	baseQuery := storage.QueryRecordsRequest{Limit: 100}
-	mx := newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2", "1"})
+	mx := newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2", "1"}, 0, false)
 	ids := testPayloads(t, mx, []string{"0", "1", "2", "3", "4"})
 
 	baseQuery = storage.QueryRecordsRequest{StartID: ids[2], Limit: 100}
-	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2", "1"})
+	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2", "1"}, 0, false)
 	_ = testPayloads(t, mx, []string{"2", "3", "4"})
 
 	baseQuery = storage.QueryRecordsRequest{Descending: true, Limit: 100}
-	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2", "1"})
+	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2", "1"}, 0, false)
 	testPayloads(t, mx, []string{"4", "3", "2", "1", "0"})
 
 	baseQuery = storage.QueryRecordsRequest{Descending: true, StartID: ids[2], Limit: 100}
-	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2", "1"})
+	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2", "1"}, 0, false)
 	_ = testPayloads(t, mx, []string{"2", "1", "0"})
 
 	baseQuery = storage.QueryRecordsRequest{Limit: 100}
-	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "1"})
+	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "1"}, 0, false)
 	testPayloads(t, mx, []string{"0", "1", "2", "3"})
 
 	baseQuery = storage.QueryRecordsRequest{Limit: 1}
-	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2"})
+	mx = newMixer(ctx, cancel, ls, baseQuery, []string{"0", "2"}, 0, false)
 	testPayloads(t, mx, []string{"0", "1", "4"})
 }
 
+func TestMixer_MaxPerLog(t *testing.T) {
+	ls := storage.NewLogHelper()
+	for lid, n := range map[string]int{"quiet1": 2, "quiet2": 1, "active": 20} {
+		recs := make([]*solaris.Record, n)
+		for i := range recs {
+			recs[i] = &solaris.Record{Payload: []byte(lid)}
+		}
+		ls.AppendRecords(context2.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: lid})
+	}
+
+	ctx, cancel := context.WithCancelError(context2.Background())
+	for _, desc := range []bool{false, true} {
+		mx := newMixer(ctx, cancel, ls, storage.QueryRecordsRequest{Limit: 10, Descending: desc}, []string{"quiet1", "active", "quiet2"}, 3, false)
+		cnts := map[string]int{}
+		for mx.HasNext() {
+			r, ok := mx.Next()
+			assert.True(t, ok)
+			cnts[string(r.Payload)]++
+		}
+		assert.Equal(t, map[string]int{"quiet1": 2, "quiet2": 1, "active": 3}, cnts)
+		assert.True(t, mx.capped())
+	}
+
+	// the logs under the limit are not capped
+	mx := newMixer(ctx, cancel, ls, storage.QueryRecordsRequest{Limit: 10}, []string{"quiet1", "quiet2"}, 3, false)
+	cnt := 0
+	for mx.HasNext() {
+		_, ok := mx.Next()
+		assert.True(t, ok)
+		cnt++
+	}
+	assert.Equal(t, 3, cnt)
+	assert.False(t, mx.capped())
+}
+
+func TestMixer_Dedup(t *testing.T) {
+	ls := &aliasLog{Log: storage.NewLogHelper(), aliases: map[string]string{"replica": "1"}}
+	for i := 0; i < 5; i++ {
+		for _, lid := range []string{"1", "2"} {
+			ls.AppendRecords(context2.Background(), &solaris.AppendRecordsRequest{Records: []*solaris.Record{{Payload: []byte(lid)}}, LogID: lid})
+		}
+	}
+
+	ctx, cancel := context.WithCancelError(context2.Background())
+	defer cancel(nil)
+	for _, desc := range []bool{false, true} {
+		var all, dedup []string
+		for _, d := range []bool{false, true} {
+			mx := newMixer(ctx, cancel, ls, storage.QueryRecordsRequest{Limit: 100, Descending: desc}, []string{"1", "replica", "2"}, 0, d)
+			var ids []string
+			for mx.HasNext() {
+				r, ok := mx.Next()
+				assert.True(t, ok)
+				ids = append(ids, r.ID)
+			}
+			if d {
+				dedup = ids
+			} else {
+				all = ids
+			}
+		}
+		assert.Equal(t, 15, len(all))
+		assert.Equal(t, slices.Compact(all), dedup)
+		assert.Equal(t, 10, len(dedup))
+		if desc {
+			slices.Reverse(dedup)
+		}
+		assert.True(t, slices.IsSorted(dedup))
+	}
+}
+
 func testPayloads(t *testing.T, it iterable.Iterator[*solaris.Record], payloads []string) []string {
 	ids := []string{}
 	for _, p := range payloads {
@@ -126,3 +198,75 @@ func testPayloads(t *testing.T, it iterable.Iterator[*solaris.Record], payloads
 	assert.False(t, it.HasNext())
 	return ids
 }
+
+func TestMixer_SmallPages(t *testing.T) {
+	ls := &countingLog{Log: storage.NewLogHelper()}
+	logIDs := make([]string, 100)
+	for i := range logIDs {
+		logIDs[i] = fmt.Sprintf("%d", i)
+		recs := make([]*solaris.Record, 50)
+		for j := range recs {
+			recs[j] = &solaris.Record{Payload: []byte(fmt.Sprintf("%d-%d", i, j))}
+		}
+		ls.AppendRecords(context2.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: logIDs[i]})
+	}
+
+	ctx, cancel := context.WithCancelError(context2.Background())
+	mx := newMixer(ctx, cancel, ls, storage.QueryRecordsRequest{Limit: 10}, logIDs, 0, false)
+	for i := 0; i < 10; i++ {
+		_, ok := mx.Next()
+		assert.True(t, ok)
+	}
+	// every log is requested for 1 record first, some of them are requested twice
+	assert.Less(t, ls.read, 2*len(logIDs))
+}
+
+func BenchmarkMixer_ReadAmplification(b *testing.B) {
+	ls := &countingLog{Log: storage.NewLogHelper()}
+	logIDs := make([]string, 1000)
+	for i := range logIDs {
+		logIDs[i] = fmt.Sprintf("%d", i)
+		recs := make([]*solaris.Record, 100)
+		for j := range recs {
+			recs[j] = &solaris.Record{Payload: []byte("payload")}
+		}
+		ls.AppendRecords(context2.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: logIDs[i]})
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		ctx, cancel := context.WithCancelError(context2.Background())
+		mx := newMixer(ctx, cancel, ls, storage.QueryRecordsRequest{Limit: 100}, logIDs, 0, false)
+		for i := 0; i < 100 && mx.HasNext(); i++ {
+			mx.Next()
+		}
+		mx.Close()
+		cancel(nil)
+	}
+	b.ReportMetric(float64(ls.read)/float64(b.N), "recs_read/op")
+}
+
+// countingLog counts the number of records returned by QueryRecords
+type countingLog struct {
+	storage.Log
+	read int
+}
+
+func (cl *countingLog) QueryRecords(ctx context2.Context, request storage.QueryRecordsRequest) ([]*solaris.Record, bool, error) {
+	res, more, err := cl.Log.QueryRecords(ctx, request)
+	cl.read += len(res)
+	return res, more, err
+}
+
+// aliasLog reads the records of the aliased logs, so the same records are returned for several logs
+type aliasLog struct {
+	storage.Log
+	aliases map[string]string
+}
+
+func (al *aliasLog) QueryRecords(ctx context2.Context, request storage.QueryRecordsRequest) ([]*solaris.Record, bool, error) {
+	if lid, ok := al.aliases[request.LogID]; ok {
+		request.LogID = lid
+	}
+	return al.Log.QueryRecords(ctx, request)
+}
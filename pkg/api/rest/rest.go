@@ -23,6 +23,7 @@ import (
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/logging"
 	"github.com/solarisdb/solaris/pkg/api"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"net/http"
 )
 
@@ -53,7 +54,10 @@ func (r *Rest) CreateLog(c *gin.Context) {
 	if r.errorResponse(c, BindAppJson(c, &rReq), "") {
 		return
 	}
-	sLog, err := r.svc.CreateLog(c, &solaris.Log{Tags: rReq.Tags})
+	sLog, err := r.svc.CreateLog(c, &solaris.Log{Tags: rReq.Tags, ValidateUTF8: cast.Bool(rReq.ValidateUTF8, false),
+		PayloadTypeURL: cast.String(rReq.PayloadTypeURL, ""), MaxRecords: cast.Int64(rReq.MaxRecords, 0),
+		PayloadHash: cast.String(rReq.PayloadHash, ""), RetentionMaxAge: msToDuration(cast.Int64(rReq.RetentionMaxAgeMs, 0)),
+		RetentionMaxRecords: cast.Int64(rReq.RetentionMaxRecords, 0), Grants: cast.Value(rReq.Grants, nil)})
 	if r.errorResponse(c, err, "") {
 		return
 	}
@@ -65,7 +69,10 @@ func (r *Rest) UpdateLog(c *gin.Context, logId restapi.LogId) {
 	if r.errorResponse(c, BindAppJson(c, &rReq), "") {
 		return
 	}
-	sLog, err := r.svc.UpdateLog(c, &solaris.Log{ID: logId, Tags: rReq.Tags})
+	sLog, err := r.svc.UpdateLog(c, &solaris.Log{ID: logId, Tags: rReq.Tags, ValidateUTF8: cast.Bool(rReq.ValidateUTF8, false),
+		PayloadTypeURL: cast.String(rReq.PayloadTypeURL, ""), MaxRecords: cast.Int64(rReq.MaxRecords, 0),
+		PayloadHash: cast.String(rReq.PayloadHash, ""), RetentionMaxAge: msToDuration(cast.Int64(rReq.RetentionMaxAgeMs, 0)),
+		RetentionMaxRecords: cast.Int64(rReq.RetentionMaxRecords, 0), Grants: cast.Value(rReq.Grants, nil)})
 	if r.errorResponse(c, err, "") {
 		return
 	}
@@ -89,6 +96,12 @@ func (r *Rest) QueryLogs(c *gin.Context, params restapi.QueryLogsParams) {
 	sReq.Condition = cast.String(params.LogsCondFilter, "")
 	sReq.Limit = int64(cast.Int(params.Limit, 0))
 	sReq.PageID = cast.String(params.FromPageId, "")
+	if params.CreatedAfter != nil {
+		sReq.CreatedAfter = timestamppb.New(*params.CreatedAfter)
+	}
+	if params.CreatedBefore != nil {
+		sReq.CreatedBefore = timestamppb.New(*params.CreatedBefore)
+	}
 
 	sRes, err := r.svc.QueryLogs(c, sReq)
 	if r.errorResponse(c, err, "") {
@@ -109,11 +122,31 @@ func (r *Rest) CreateRecords(c *gin.Context, logId restapi.LogId) {
 	sReq := new(solaris.AppendRecordsRequest)
 	sReq.LogID = logId
 	sReq.Records = createRecsToSvc(rReq.Records)
+	sReq.IdempotencyKey = cast.String(rReq.IdempotencyKey, "")
+	if rReq.Atomic != nil {
+		sReq.Mode = solaris.AppendMode_APPEND_MODE_PARTIAL
+		if *rReq.Atomic {
+			sReq.Mode = solaris.AppendMode_APPEND_MODE_ATOMIC
+		}
+	}
 	sRes, err := r.svc.AppendRecords(c, sReq)
 	if r.errorResponse(c, err, "") {
 		return
 	}
-	c.JSON(http.StatusCreated, restapi.CreateRecordsResponse{Added: int(sRes.Added)})
+	rRes := restapi.CreateRecordsResponse{Added: int(sRes.Added), BytesWritten: cast.Ptr(sRes.BytesWritten)}
+	if sRes.Added > 0 {
+		rRes.StartId = cast.Ptr(sRes.StartID)
+		rRes.LastId = cast.Ptr(sRes.LastID)
+	}
+	if sRes.Partial {
+		rRes.Partial = cast.Ptr(true)
+		rRes.PartialError = cast.Ptr(sRes.PartialError)
+		rRes.Rejected = rejectedToRest(sRes.Rejected)
+	}
+	if len(sRes.Warnings) > 0 {
+		rRes.Warnings = cast.Ptr(sRes.Warnings)
+	}
+	c.JSON(http.StatusCreated, rRes)
 }
 
 func (r *Rest) QueryRecords(c *gin.Context, params restapi.QueryRecordsParams) {
@@ -123,7 +156,19 @@ func (r *Rest) QueryRecords(c *gin.Context, params restapi.QueryRecordsParams) {
 	sReq.LogIDs = cast.Value(params.LogIds, nil)
 	sReq.Descending = cast.Bool(params.Desc, false)
 	sReq.StartRecordID = cast.String(params.FromPageId, "")
+	sReq.StartExclusive = cast.Bool(params.FromPageExclusive, false)
 	sReq.Limit = int64(cast.Int(params.Limit, 0))
+	sReq.MinPayloadLen = int64(cast.Int(params.MinPayloadLen, 0))
+	sReq.MaxPayloadLen = int64(cast.Int(params.MaxPayloadLen, 0))
+	sReq.WithAge = cast.Bool(params.WithAge, false)
+	sReq.StartSeq = cast.Value(params.FromSeq, 0)
+	sReq.MaxPerLog = int64(cast.Int(params.MaxPerLog, 0))
+	if params.CreatedAfter != nil {
+		sReq.CreatedAfter = timestamppb.New(*params.CreatedAfter)
+	}
+	if params.CreatedBefore != nil {
+		sReq.CreatedBefore = timestamppb.New(*params.CreatedBefore)
+	}
 
 	sResQ, err := r.svc.QueryRecords(c, sReq)
 	if r.errorResponse(c, err, "") {
@@ -133,7 +178,7 @@ func (r *Rest) QueryRecords(c *gin.Context, params restapi.QueryRecordsParams) {
 	if r.errorResponse(c, err, "") {
 		return
 	}
-	rRes := restapi.QueryRecordsResult{Items: recsToRest(sResQ.Records), Total: int(sResC.Count)}
+	rRes := restapi.QueryRecordsResult{Items: recsToRest(sResQ.Records, sReq.WithAge), Total: int(sResC.Count)}
 	if len(sResQ.NextPageID) > 0 {
 		rRes.NextPageId = cast.Ptr(sResQ.NextPageID)
 	}
@@ -17,12 +17,45 @@ package rest
 import (
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	restapi "github.com/solarisdb/solaris/api/genpublic/v1"
+	"github.com/solarisdb/solaris/golibs/cast"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"time"
 )
 
+// msToDuration returns the duration of the milliseconds provided, or nil if it is zero
+func msToDuration(ms int64) *durationpb.Duration {
+	if ms == 0 {
+		return nil
+	}
+	return durationpb.New(time.Duration(ms) * time.Millisecond)
+}
+
 func logToRest(sLog *solaris.Log) restapi.Log {
 	var rLog restapi.Log
 	rLog.Id = sLog.ID
 	rLog.Tags = sLog.Tags
+	rLog.ValidateUTF8 = sLog.ValidateUTF8
+	if sLog.PayloadTypeURL != "" {
+		rLog.PayloadTypeURL = cast.Ptr(sLog.PayloadTypeURL)
+	}
+	if sLog.MaxRecords > 0 {
+		rLog.MaxRecords = cast.Ptr(sLog.MaxRecords)
+	}
+	if sLog.PayloadHash != "" {
+		rLog.PayloadHash = cast.Ptr(sLog.PayloadHash)
+	}
+	if maxAge := sLog.RetentionMaxAge.AsDuration(); maxAge > 0 {
+		rLog.RetentionMaxAgeMs = cast.Ptr(maxAge.Milliseconds())
+	}
+	if sLog.RetentionMaxRecords > 0 {
+		rLog.RetentionMaxRecords = cast.Ptr(sLog.RetentionMaxRecords)
+	}
+	if sLog.Owner != "" {
+		rLog.Owner = cast.Ptr(sLog.Owner)
+	}
+	if len(sLog.Grants) > 0 {
+		rLog.Grants = cast.Ptr(sLog.Grants)
+	}
 	if sLog.CreatedAt != nil {
 		rLog.CreatedAt = sLog.CreatedAt.AsTime()
 	}
@@ -40,9 +73,30 @@ func logsToRest(sLogs []*solaris.Log) []restapi.Log {
 	return rLogs
 }
 
+// rejectReasons maps the append records rejection reasons to the REST ones
+var rejectReasons = map[solaris.RejectReason]restapi.AppendRejectedReason{
+	solaris.RejectReason_REJECT_REASON_ERROR:     restapi.RejectedError,
+	solaris.RejectReason_REJECT_REASON_EXHAUSTED: restapi.RejectedExhausted,
+	solaris.RejectReason_REJECT_REASON_TOO_LARGE: restapi.RejectedTooLarge,
+}
+
+func rejectedToRest(sRej *solaris.AppendRejected) *restapi.AppendRejected {
+	if sRej == nil {
+		return nil
+	}
+	reason, ok := rejectReasons[sRej.Reason]
+	if !ok {
+		reason = restapi.RejectedError
+	}
+	return &restapi.AppendRejected{Count: sRej.Count, Reason: reason}
+}
+
 func createRecToSvc(rRec restapi.CreateRecordRequest) *solaris.Record {
 	sRec := new(solaris.Record)
 	sRec.Payload = rRec.Payload
+	if rRec.Attributes != nil {
+		sRec.Attributes = *rRec.Attributes
+	}
 	return sRec
 }
 
@@ -54,7 +108,7 @@ func createRecsToSvc(rRecs []restapi.CreateRecordRequest) []*solaris.Record {
 	return sRecs
 }
 
-func recToRest(sRec *solaris.Record) restapi.Record {
+func recToRest(sRec *solaris.Record, withAge bool) restapi.Record {
 	var rRec restapi.Record
 	rRec.Id = sRec.ID
 	rRec.LogId = sRec.LogID
@@ -62,13 +116,22 @@ func recToRest(sRec *solaris.Record) restapi.Record {
 	if sRec.CreatedAt != nil {
 		rRec.CreatedAt = sRec.CreatedAt.AsTime()
 	}
+	if withAge {
+		rRec.AgeMs = cast.Ptr(sRec.AgeMs)
+	}
+	if sRec.Seq > 0 {
+		rRec.Seq = cast.Ptr(sRec.Seq)
+	}
+	if len(sRec.Attributes) > 0 {
+		rRec.Attributes = cast.Ptr(restapi.Attributes(sRec.Attributes))
+	}
 	return rRec
 }
 
-func recsToRest(sRecs []*solaris.Record) []restapi.Record {
+func recsToRest(sRecs []*solaris.Record, withAge bool) []restapi.Record {
 	var rRecs []restapi.Record
 	for _, r := range sRecs {
-		rRecs = append(rRecs, recToRest(r))
+		rRecs = append(rRecs, recToRest(r, withAge))
 	}
 	return rRecs
 }
@@ -31,11 +31,17 @@ type rIterator struct {
 	// baseQuery contains some parameters like condition, direction etc.
 	baseQuery storage.QueryRecordsRequest
 	nextID    string // the ID of record will be returned next, if any
-	buf       []*solaris.Record
-	bPos      int
-	eof       bool
+	// pageSize is the number of records requested by the next fillBuf() call. It starts
+	// from a small value and grows up to maxPageSize with every read
+	pageSize int64
+	buf      []*solaris.Record
+	bPos     int
+	eof      bool
 }
 
+// maxPageSize defines the maximum number of records an rIterator reads from its log at a time
+const maxPageSize = 100
+
 var _ iterable.Iterator[*solaris.Record] = (*rIterator)(nil)
 
 func newRIterator(ctx context.Context, cf context2.CancelErrFunc, ls storage.Log, baseQuery storage.QueryRecordsRequest) *rIterator {
@@ -45,6 +51,7 @@ func newRIterator(ctx context.Context, cf context2.CancelErrFunc, ls storage.Log
 	ri.ls = ls
 	ri.baseQuery = baseQuery
 	ri.nextID = baseQuery.StartID
+	ri.pageSize = min(maxPageSize, baseQuery.Limit)
 	return ri
 }
 
@@ -88,8 +95,13 @@ func (ri *rIterator) fillBuf() error {
 		return ri.ctx.Err()
 	}
 
+	// the closed context stops the reading before the next page is requested
+	if err := ri.ctx.Err(); err != nil {
+		ri.eof = true
+		return err
+	}
 	q := ri.baseQuery
-	q.Limit = min(100, ri.baseQuery.Limit)
+	q.Limit = ri.pageSize
 	q.StartID = ri.nextID
 	ri.buf = nil
 	mr, _, err := ri.ls.QueryRecords(ri.ctx, q)
@@ -101,6 +113,9 @@ func (ri *rIterator) fillBuf() error {
 	if mr != nil {
 		ri.buf = mr
 	}
+	// the next pages start from the IDs, which must be read
+	ri.baseQuery.StartExclusive = false
+	ri.pageSize = min(ri.pageSize*2, maxPageSize, ri.baseQuery.Limit)
 	ri.bPos = 0
 	ri.eof = ri.bPos >= len(ri.buf)
 	return nil
@@ -16,37 +16,112 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
+	"github.com/oklog/ulid/v2"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	context2 "github.com/solarisdb/solaris/golibs/context"
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/logging"
 	"github.com/solarisdb/solaris/golibs/ulidutils"
+	"github.com/solarisdb/solaris/pkg/auth"
+	"github.com/solarisdb/solaris/pkg/ql"
 	"github.com/solarisdb/solaris/pkg/storage"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
+	"github.com/solarisdb/solaris/pkg/storage/logfs"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Service implements the grpc public API (see solaris.ServiceServer)
 type Service struct {
 	solaris.UnimplementedServiceServer
 	logger logging.Logger
+	cfg    Config
+	conds  *conditions
+	// parse is the records condition parser, the field allows to watch the parser calls in tests
+	parse func(cond string) (*ql.Expression, error)
+	// readOnly is the current read-only mode, see Config.ReadOnly
+	readOnly atomic.Bool
 
-	LogsStorage storage.Logs `inject:""`
-	LogStorage  storage.Log  `inject:""`
+	LogsStorage  storage.Logs      `inject:""`
+	LogStorage   storage.Log       `inject:""`
+	ChnkProvider *chunkfs.Provider `inject:",optional"`
+	Migrator     *logfs.Migrator   `inject:",optional"`
+	// RecordHashes is optional, if provided the logs records may be indexed by their payloads hashes
+	RecordHashes storage.RecordHashes `inject:",optional"`
+	// LogsActivity is optional, if provided the logs activity is checked by the logs metadata first
+	LogsActivity storage.LogsActivity `inject:",optional"`
+	// LogKeys is optional, if provided the logs records encryption keys may be rotated
+	LogKeys storage.LogKeys `inject:",optional"`
 }
 
-const maxLogsToMerge = 1000
+const (
+	maxLogsToMerge = 1000
+	// maxLogsToCreate is the maximum number of logs CreateLogs creates in one transaction
+	maxLogsToCreate = 1000
+	// defaultStreamMessageBytes is the default maximum payload size of the records in one StreamRecords message
+	defaultStreamMessageBytes = 1024 * 1024
+	// streamPageSize is the number of records StreamRecords reads at a time
+	streamPageSize = 1000
+	// defaultFieldStatsTopN is the number of the most frequent values FieldStats returns per field by default
+	defaultFieldStatsTopN = 10
+	// defaultActiveLogsLimit is the maximum number of the log IDs QueryActiveLogs returns by default
+	defaultActiveLogsLimit = 1000
+	// activeLogsPageSize is the number of logs QueryActiveLogs reads from the logs storage at a time
+	activeLogsPageSize = 100
+)
 
 var _ solaris.ServiceServer = (*Service)(nil)
 
-func NewService() *Service {
-	return &Service{
+// payloadHashes contains the hash functions the logs records payloads may be indexed by (see Log.PayloadHash)
+var payloadHashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"fnv64a": func() hash.Hash { return fnv.New64a() },
+}
+
+func NewService(cfg Config) *Service {
+	s := &Service{
 		logger: logging.NewLogger("api.Service"),
+		cfg:    cfg,
+		parse:  ql.Parse,
 	}
+	s.readOnly.Store(cfg.ReadOnly)
+	if cfg.MaxCompiledConditions < 1 {
+		s.cfg.MaxCompiledConditions = GetDefaultConfig().MaxCompiledConditions
+	}
+	// the error is not possible, the size is positive
+	s.conds, _ = newConditions(s.cfg.MaxCompiledConditions, s.cfg.CompiledConditionTTL, func(cond string) (*ql.Expression, error) {
+		return s.parse(cond)
+	})
+	return s
 }
 
 func (s *Service) CreateLog(ctx context.Context, log *solaris.Log) (*solaris.Log, error) {
 	s.logger.Infof("create new log: %v", log)
+	if err := s.checkWritable(); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := s.checkPayloadHash(log.PayloadHash); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := checkRetention(log); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	setOwner(ctx, log)
 	res, err := s.LogsStorage.CreateLog(ctx, log)
 	if err != nil {
 		s.logger.Warnf("could not create log=%v: %v", log, err)
@@ -54,8 +129,47 @@ func (s *Service) CreateLog(ctx context.Context, log *solaris.Log) (*solaris.Log
 	return res, errors.GRPCWrap(err)
 }
 
+// CreateLogs creates the logs in one transaction, so either all the logs are created or none of them
+func (s *Service) CreateLogs(ctx context.Context, request *solaris.CreateLogsRequest) (*solaris.CreateLogsResult, error) {
+	s.logger.Infof("create %d new logs", len(request.Logs))
+	if err := s.checkWritable(); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if len(request.Logs) > maxLogsToCreate {
+		return nil, errors.GRPCWrap(fmt.Errorf("too many logs to create %d, the maximum is %d: %w",
+			len(request.Logs), maxLogsToCreate, errors.ErrInvalid))
+	}
+	for _, log := range request.Logs {
+		if err := s.checkPayloadHash(log.PayloadHash); err != nil {
+			return nil, errors.GRPCWrap(err)
+		}
+		if err := checkRetention(log); err != nil {
+			return nil, errors.GRPCWrap(err)
+		}
+		setOwner(ctx, log)
+	}
+	logs, err := s.LogsStorage.CreateLogs(ctx, request.Logs)
+	if err != nil {
+		s.logger.Warnf("could not create %d logs: %v", len(request.Logs), err)
+		return nil, errors.GRPCWrap(err)
+	}
+	return &solaris.CreateLogsResult{Logs: logs}, nil
+}
+
 func (s *Service) UpdateLog(ctx context.Context, log *solaris.Log) (*solaris.Log, error) {
 	s.logger.Infof("updating log: %v", log)
+	if err := s.checkWritable(); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := s.checkPayloadHash(log.PayloadHash); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := checkRetention(log); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := s.checkLogAccess(ctx, log.ID, true); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
 	res, err := s.LogsStorage.UpdateLog(ctx, log)
 	if err != nil {
 		s.logger.Warnf("could not update log=%v: %v", log, err)
@@ -63,63 +177,179 @@ func (s *Service) UpdateLog(ctx context.Context, log *solaris.Log) (*solaris.Log
 	return res, errors.GRPCWrap(err)
 }
 
+// GetLog returns the log by its ID, the logs marked for deletion are not found
+func (s *Service) GetLog(ctx context.Context, request *solaris.GetLogRequest) (*solaris.Log, error) {
+	if request.ID == "" {
+		return nil, errors.GRPCWrap(fmt.Errorf("the log ID must be specified: %w", errors.ErrInvalid))
+	}
+	log, err := s.LogsStorage.GetLogByID(ctx, request.ID)
+	if err != nil {
+		return nil, errors.GRPCWrap(fmt.Errorf("could not get the log ID=%s: %w", request.ID, err))
+	}
+	if err := checkAccess(ctx, log, false); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	return log, nil
+}
+
 func (s *Service) QueryLogs(ctx context.Context, request *solaris.QueryLogsRequest) (*solaris.QueryLogsResult, error) {
-	res, err := s.LogsStorage.QueryLogs(ctx, storage.QueryLogsRequest{Condition: request.Condition, Page: request.PageID, Limit: request.Limit})
+	if err := s.cfg.LogsCondLimits.Check(request.Condition); err != nil {
+		s.logger.Warnf("rejecting the query logs request: %v", err)
+		return nil, errors.GRPCWrap(embedParseError(err))
+	}
+	res, err := s.LogsStorage.QueryLogs(ctx, storage.QueryLogsRequest{Condition: request.Condition, Page: request.PageID, Limit: request.Limit,
+		CreatedAfter: timeOrZero(request.CreatedAfter), CreatedBefore: timeOrZero(request.CreatedBefore)})
 	if err != nil {
 		s.logger.Warnf("could not query=%v: %v", request, err)
+	} else {
+		res.Logs = accessibleLogs(ctx, res.Logs, false)
 	}
-	return res, errors.GRPCWrap(err)
+	return res, errors.GRPCWrap(embedParseError(err))
 }
 
 func (s *Service) DeleteLogs(ctx context.Context, request *solaris.DeleteLogsRequest) (*solaris.DeleteLogsResult, error) {
 	s.logger.Infof("delete logs: %v", request)
-	res, err := s.LogsStorage.DeleteLogs(ctx, storage.DeleteLogsRequest{Condition: request.Condition, MarkOnly: true})
+	if err := s.checkWritable(); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := s.cfg.LogsCondLimits.Check(request.Condition); err != nil {
+		s.logger.Warnf("rejecting the delete logs request: %v", err)
+		return nil, errors.GRPCWrap(err)
+	}
+	dr := storage.DeleteLogsRequest{IDs: request.LogIDs, Condition: request.Condition, MarkOnly: !request.HardDelete}
+	if _, ok := auth.PrincipalFromContext(ctx); ok {
+		// the client deletes the logs it owns only, so they are selected by the IDs
+		var err error
+		if len(dr.IDs) > 0 {
+			dr.IDs, err = s.accessibleLogIDs(ctx, dr.IDs, true)
+		} else {
+			dr.IDs, err = s.ownedLogIDs(ctx, dr.Condition)
+		}
+		if err != nil {
+			return nil, errors.GRPCWrap(err)
+		}
+		if len(dr.IDs) == 0 {
+			return &solaris.DeleteLogsResult{}, nil
+		}
+	}
+	res, err := s.LogsStorage.DeleteLogs(ctx, dr)
 	if err != nil {
 		s.logger.Warnf("could not delete logs for the request=%v: %v", err)
 	} else {
-		s.logger.Infof("%d records marked for delete for request=%v", len(res.DeletedIDs), request)
+		s.logger.Infof("%d logs deleted (%d bytes freed) for request=%v", len(res.DeletedIDs), res.FreedBytes, request)
 	}
 	return res, errors.GRPCWrap(err)
 }
 
 func (s *Service) AppendRecords(ctx context.Context, request *solaris.AppendRecordsRequest) (*solaris.AppendRecordsResult, error) {
-	_, err := s.LogsStorage.GetLogByID(ctx, request.LogID)
+	res, err := s.appendRecords(ctx, request)
+	return res, errors.GRPCWrap(err)
+}
+
+// AppendRecordsStream appends the records batches received from the stream one by one and returns the total
+// result when the stream is closed. The stream is stopped on the first batch, which is not appended completely,
+// the previous batches stay appended, and the error or the partial result tell the batch number. The rejected records
+// of the partial result are the records of that batch.
+func (s *Service) AppendRecordsStream(stream solaris.Service_AppendRecordsStreamServer) error {
+	total := &solaris.AppendRecordsResult{}
+	for batch := 0; ; batch++ {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(total)
+		}
+		if err != nil {
+			return err
+		}
+		res, err := s.appendRecords(stream.Context(), request)
+		if err != nil {
+			return errors.GRPCWrap(fmt.Errorf("could not append the batch %d to logID=%s, %d records of the previous batches are added: %w",
+				batch, request.LogID, total.Added, err))
+		}
+		addAppendResult(total, res)
+		if res.Partial {
+			total.PartialError = fmt.Sprintf("the batch %d to logID=%s is appended partially: %s", batch, request.LogID, res.PartialError)
+			return stream.SendAndClose(total)
+		}
+	}
+}
+
+func (s *Service) appendRecords(ctx context.Context, request *solaris.AppendRecordsRequest) (*solaris.AppendRecordsResult, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := checkBatchChecksum(request); err != nil {
+		s.logger.Warnf("rejecting the records for logID=%s: %v", request.LogID, err)
+		return nil, err
+	}
+	log, err := s.LogsStorage.GetLogByID(ctx, request.LogID)
 	if err != nil {
-		return nil, errors.GRPCWrap(err)
+		return nil, err
+	}
+	if err := checkAccess(ctx, log, false); err != nil {
+		return nil, err
+	}
+	if log.ValidateUTF8 {
+		if err := checkUTF8(request.Records); err != nil {
+			s.logger.Warnf("rejecting the records for logID=%s: %v", request.LogID, err)
+			return nil, err
+		}
 	}
+	var hashes []string
+	if log.PayloadHash != "" && s.RecordHashes != nil {
+		if hashes, err = hashPayloads(log.PayloadHash, request.Records); err != nil {
+			return nil, err
+		}
+	}
+	// the added records IDs are needed to index them by the payloads hashes
+	expandIDs := request.ExpandIDs
+	request.ExpandIDs = expandIDs || hashes != nil
 	res, err := s.LogStorage.AppendRecords(ctx, request)
+	request.ExpandIDs = expandIDs
+	if err == nil && hashes != nil {
+		s.addRecordHashes(ctx, request.LogID, res.RecordIDs, hashes)
+		if !expandIDs {
+			res.RecordIDs = nil
+		}
+	}
 	if err != nil {
 		s.logger.Warnf("could not append records to logID=%s: %v", request.LogID, err)
+	} else if log.MaxRecords > 0 {
+		// the records are appended already, so the append succeeds even if the log is not trimmed, and the log
+		// is trimmed even if the request is cancelled. The slack allows to trim the log by whole chunks mostly.
+		slack := log.MaxRecords * int64(s.cfg.MaxRecordsSlackPct) / 100
+		if _, terr := s.LogStorage.TrimRecords(context.WithoutCancel(ctx), request.LogID, log.MaxRecords, slack); terr != nil {
+			s.logger.Warnf("could not trim logID=%s to %d records: %v", request.LogID, log.MaxRecords, terr)
+		}
 	}
-	return res, errors.GRPCWrap(err)
+	return res, err
 }
 
 func (s *Service) QueryRecords(ctx context.Context, request *solaris.QueryRecordsRequest) (*solaris.QueryRecordsResult, error) {
-	logIDs := request.LogIDs
-	if len(logIDs) == 0 {
-		// requesting maxLogsToMerge+1 to be sure that if we have more than the maximum, will interrupt the procedure
-		qr, err := s.LogsStorage.QueryLogs(ctx, storage.QueryLogsRequest{Condition: request.LogsCondition, Limit: int64(maxLogsToMerge + 1)})
-		if err != nil {
-			return nil, errors.GRPCWrap(err)
-		}
-		logIDs = make([]string, len(qr.Logs))
-		for i, l := range qr.Logs {
-			logIDs[i] = l.ID
-		}
+	logIDs, err := s.recordsLogIDs(ctx, request)
+	if err != nil {
+		return nil, errors.GRPCWrap(err)
 	}
-	if len(logIDs) > maxLogsToMerge {
-		return nil, errors.GRPCWrap(fmt.Errorf("could not merge more than %d logs together: %w", maxLogsToMerge, errors.ErrExhausted))
+	query, err := s.recordsQuery(request)
+	if err != nil {
+		return nil, errors.GRPCWrap(err)
 	}
 
 	if len(logIDs) == 1 {
-		res, more, err := s.LogStorage.QueryRecords(ctx, storage.QueryRecordsRequest{Condition: request.Condition,
-			LogID: logIDs[0], Descending: request.Descending, StartID: request.StartRecordID, Limit: request.Limit})
+		query.LogID = logIDs[0]
+		query.Limit = min(query.Limit, int64(s.cfg.MaxRecordsLimit))
+		if request.MaxPerLog > 0 {
+			query.Limit = min(query.Limit, request.MaxPerLog)
+		}
+		res, more, err := s.readLogRecords(ctx, query)
 		if err != nil {
 			return nil, errors.GRPCWrap(err)
 		}
 		nextID := ""
 		if more {
-			nextID = ulidutils.NextID(res[len(res)-1].ID)
+			nextID = windowPageID(query, nextPageID(res[len(res)-1].ID, request.Descending))
+		}
+		if err := s.formatRecords(ctx, request, res); err != nil {
+			return nil, errors.GRPCWrap(err)
 		}
 		return &solaris.QueryRecordsResult{Records: res, NextPageID: nextID}, nil
 	}
@@ -127,9 +357,7 @@ func (s *Service) QueryRecords(ctx context.Context, request *solaris.QueryRecord
 	ctx, cancel := context2.WithCancelError(ctx)
 	defer cancel(nil)
 
-	baseQuery := storage.QueryRecordsRequest{Condition: request.Condition,
-		Descending: request.Descending, StartID: request.StartRecordID, Limit: request.Limit}
-	mx := newMixer(ctx, cancel, s.LogStorage, baseQuery, logIDs)
+	mx := newMixer(ctx, cancel, s.LogStorage, query, logIDs, request.MaxPerLog, request.Dedup)
 	defer mx.Close()
 
 	lim := request.Limit
@@ -149,52 +377,782 @@ func (s *Service) QueryRecords(ctx context.Context, request *solaris.QueryRecord
 		if r, ok := mx.Next(); ok {
 			nextID = r.ID
 		}
+	} else if len(res) > 0 && mx.capped() {
+		// the capped logs still have records, the next page starts right after the last returned record
+		nextID = windowPageID(query, nextPageID(res[len(res)-1].ID, request.Descending))
 	}
 
 	// while the iteration above we could get an error, so check it out
-	err := ctx.Err()
+	err = ctx.Err()
 	if err != nil {
 		s.logger.Errorf("could not read data for the request=%v: %v", request, err)
+	} else {
+		err = s.formatRecords(ctx, request, res)
 	}
 	return &solaris.QueryRecordsResult{Records: res, NextPageID: nextID}, errors.GRPCWrap(err)
 }
 
 func (s *Service) CountRecords(ctx context.Context, request *solaris.QueryRecordsRequest) (*solaris.CountResult, error) {
-	logIDs := request.LogIDs
-	if len(logIDs) == 0 {
-		// requesting maxLogsToMerge+1 to be sure that if we have more than the maximum, will interrupt the procedure
-		qr, err := s.LogsStorage.QueryLogs(ctx, storage.QueryLogsRequest{Condition: request.LogsCondition, Limit: int64(maxLogsToMerge + 1)})
-		if err != nil {
-			return nil, errors.GRPCWrap(err)
-		}
-		logIDs = make([]string, len(qr.Logs))
-		for i, l := range qr.Logs {
-			logIDs[i] = l.ID
-		}
+	logIDs, err := s.recordsLogIDs(ctx, request)
+	if err != nil {
+		return nil, errors.GRPCWrap(err)
 	}
-	if len(logIDs) > maxLogsToMerge {
-		return nil, errors.GRPCWrap(fmt.Errorf("could not merge more than %d logs together: %w", maxLogsToMerge, errors.ErrExhausted))
+	cond, expr, err := s.recordsCondition(request)
+	if err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := checkWindow(request.CreatedAfter, request.CreatedBefore); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+
+	var deadline time.Time
+	if request.CountBudgetMs > 0 {
+		deadline = time.Now().Add(time.Duration(request.CountBudgetMs) * time.Millisecond)
 	}
 
 	var total uint64
 	var count uint64
+	exact := true
 	for idx := range logIDs {
-		t, c, err := s.LogStorage.CountRecords(ctx, storage.QueryRecordsRequest{
-			Condition: request.Condition,
+		t, c, e, err := s.LogStorage.CountRecords(ctx, storage.QueryRecordsRequest{
+			Condition: cond,
+			Expr:      expr,
 			LogID:     logIDs[idx], Descending: request.Descending,
-			StartID: request.StartRecordID,
-			Limit:   request.Limit},
+			StartID:        request.StartRecordID,
+			StartExclusive: request.StartExclusive,
+			StartSeq:       request.StartSeq,
+			Limit:          request.Limit,
+			PayloadLen:     payloadLenRange(request),
+			CreatedAfter:   timeOrZero(request.CreatedAfter),
+			CreatedBefore:  timeOrZero(request.CreatedBefore),
+			CountDeadline:  deadline},
 		)
 		if err != nil {
-			return nil, err
+			return nil, errors.GRPCWrap(err)
 		}
 
 		total += t
 		count += c
+		exact = exact && e
 	}
 
 	return &solaris.CountResult{
 		Total: int64(total),
 		Count: int64(count),
+		Exact: exact,
 	}, nil
 }
+
+// StreamRecords reads the records and sends them to the stream. The records of one log are read by the records
+// iterator at once, the records of many logs are read page by page. The records are split into the messages,
+// so every message contains no more than request.MaxMessageBytes of the records payloads.
+func (s *Service) StreamRecords(request *solaris.StreamRecordsRequest, stream solaris.Service_StreamRecordsServer) error {
+	if request.Query == nil {
+		return errors.GRPCWrap(fmt.Errorf("the query must be specified: %w", errors.ErrInvalid))
+	}
+	maxBytes := request.MaxMessageBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultStreamMessageBytes
+	}
+
+	logIDs, err := s.recordsLogIDs(stream.Context(), request.Query)
+	if err != nil {
+		return errors.GRPCWrap(err)
+	}
+	if len(logIDs) == 1 {
+		query, err := s.recordsQuery(request.Query)
+		if err != nil {
+			return errors.GRPCWrap(err)
+		}
+		query.LogID = logIDs[0]
+		if mpl := request.Query.MaxPerLog; mpl > 0 && (query.Limit <= 0 || query.Limit > mpl) {
+			query.Limit = mpl
+		}
+		return s.streamLogRecords(request.Query, query, maxBytes, stream)
+	}
+
+	query := proto.Clone(request.Query).(*solaris.QueryRecordsRequest)
+	total := request.Query.Limit
+	for {
+		query.Limit = streamPageSize
+		if total > 0 {
+			query.Limit = min(total, streamPageSize)
+		}
+		res, err := s.QueryRecords(stream.Context(), query)
+		if err != nil {
+			return err
+		}
+		for _, recs := range splitByPayloadSize(res.Records, maxBytes) {
+			if err := stream.Send(&solaris.QueryRecordsResult{Records: recs}); err != nil {
+				return err
+			}
+		}
+		if total > 0 {
+			if total -= int64(len(res.Records)); total <= 0 {
+				return nil
+			}
+		}
+		if res.NextPageID == "" {
+			return nil
+		}
+		query.StartRecordID = res.NextPageID
+		query.StartSeq = 0
+		query.StartExclusive = false
+	}
+}
+
+// streamLogRecords sends the records of the query log to the stream. The log is read by the records iterator
+// chunk by chunk, and the records are sent by the messages of no more than streamPageSize records and maxBytes
+// of their payloads. The iterator is closed as soon as the stream is over or broken.
+func (s *Service) streamLogRecords(request *solaris.QueryRecordsRequest, query storage.QueryRecordsRequest, maxBytes int64,
+	stream solaris.Service_StreamRecordsServer) error {
+	it, err := s.LogStorage.OpenRecordIterator(stream.Context(), query)
+	if err != nil {
+		return errors.GRPCWrap(err)
+	}
+	defer it.Close()
+
+	var recs []*solaris.Record
+	var size int64
+	send := func() error {
+		if err := s.formatRecords(stream.Context(), request, recs); err != nil {
+			return errors.GRPCWrap(err)
+		}
+		err := stream.Send(&solaris.QueryRecordsResult{Records: recs})
+		recs, size = nil, 0
+		return err
+	}
+	for it.HasNext() {
+		r, err := it.Next()
+		if err != nil {
+			return errors.GRPCWrap(err)
+		}
+		ln := int64(len(r.Payload))
+		if len(recs) > 0 && (len(recs) >= streamPageSize || size+ln > maxBytes) {
+			if err := send(); err != nil {
+				return err
+			}
+		}
+		recs = append(recs, r)
+		size += ln
+	}
+	if len(recs) > 0 {
+		return send()
+	}
+	return nil
+}
+
+// CompileCondition parses the records condition and puts the result into the compiled conditions cache
+func (s *Service) CompileCondition(ctx context.Context, request *solaris.CompileConditionRequest) (*solaris.CompiledCondition, error) {
+	handle, cc, err := s.conds.compile(request.Condition)
+	if err != nil {
+		s.logger.Warnf("could not compile the condition=%q: %v", request.Condition, err)
+		return nil, errors.GRPCWrap(embedParseError(err))
+	}
+	return &solaris.CompiledCondition{Handle: handle, ExpiresAt: timestamppb.New(cc.expiresAt)}, nil
+}
+
+// InvalidateCondition removes the compiled condition from the compiled conditions cache
+func (s *Service) InvalidateCondition(ctx context.Context, request *solaris.InvalidateConditionRequest) (*solaris.InvalidateConditionResult, error) {
+	return &solaris.InvalidateConditionResult{Invalidated: s.conds.invalidate(request.Handle)}, nil
+}
+
+// FieldStats reads up to the requested number of the latest log records and collects the statistics of their
+// fields. The statistics values are estimations, see the fieldsStats for the details.
+func (s *Service) FieldStats(ctx context.Context, request *solaris.FieldStatsRequest) (*solaris.FieldStatsResult, error) {
+	if request.LogID == "" {
+		return nil, errors.GRPCWrap(fmt.Errorf("the logID must be specified: %w", errors.ErrInvalid))
+	}
+	if log, err := s.LogsStorage.GetLogByID(ctx, request.LogID); err != nil {
+		return nil, errors.GRPCWrap(err)
+	} else if err = checkAccess(ctx, log, false); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	sample := request.SampleSize
+	if sample <= 0 {
+		sample = int64(s.cfg.DefaultFieldStatsSample)
+	}
+	if s.cfg.MaxFieldStatsSample > 0 {
+		sample = min(sample, int64(s.cfg.MaxFieldStatsSample))
+	}
+	topN := int(request.TopN)
+	if topN <= 0 {
+		topN = defaultFieldStatsTopN
+	}
+	if s.cfg.MaxFieldStatsTopN > 0 && request.TopN > int64(s.cfg.MaxFieldStatsTopN) {
+		return nil, errors.GRPCWrap(fmt.Errorf("the topN=%d must not be greater than %d: %w",
+			request.TopN, s.cfg.MaxFieldStatsTopN, errors.ErrInvalid))
+	}
+
+	fs := newFieldsStats(topN)
+	qr := storage.QueryRecordsRequest{LogID: request.LogID, Descending: true}
+	for fs.sampled < sample {
+		qr.Limit = sample - fs.sampled
+		recs, more, err := s.LogStorage.QueryRecords(ctx, qr)
+		if err != nil {
+			return nil, errors.GRPCWrap(err)
+		}
+		for _, r := range recs {
+			fs.add(r)
+		}
+		if !more || len(recs) == 0 {
+			break
+		}
+		qr.StartID = ulidutils.PrevID(recs[len(recs)-1].ID)
+	}
+	return fs.result(), nil
+}
+
+func (s *Service) SetReadOnly(ctx context.Context, request *solaris.SetReadOnlyRequest) (*solaris.SetReadOnlyResult, error) {
+	if err := checkAdmin(ctx, s.cfg.AdminPrincipals); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	was := s.readOnly.Swap(request.ReadOnly)
+	s.logger.Infof("the read-only mode is changed from %t to %t", was, request.ReadOnly)
+	return &solaris.SetReadOnlyResult{WasReadOnly: was}, nil
+}
+
+func (s *Service) GetStorageLayout(ctx context.Context, request *solaris.GetStorageLayoutRequest) (*solaris.StorageLayout, error) {
+	if err := checkAdmin(ctx, s.cfg.AdminPrincipals); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if s.ChnkProvider == nil {
+		return nil, errors.GRPCWrap(fmt.Errorf("the server has no local chunks storage: %w", errors.ErrUnimplemented))
+	}
+	l, err := s.ChnkProvider.Layout(ctx)
+	if err != nil {
+		s.logger.Warnf("could not get the storage layout: %v", err)
+		return nil, errors.GRPCWrap(err)
+	}
+	res := &solaris.StorageLayout{CurrentVersion: int32(l.Current)}
+	for v, vs := range l.Versions {
+		res.Versions = append(res.Versions, &solaris.FormatVersionStats{Version: int32(v), Chunks: vs.Chunks, Size: vs.Size})
+	}
+	sort.Slice(res.Versions, func(i, j int) bool {
+		return res.Versions[i].Version < res.Versions[j].Version
+	})
+	if s.Migrator != nil {
+		ms := s.Migrator.Status()
+		res.Migration = &solaris.FormatMigration{Enabled: ms.Enabled, Running: ms.Running, NextLogID: ms.NextLogID,
+			Migrated: ms.Migrated, FailedLogs: ms.FailedLogs}
+		if !ms.FinishedAt.IsZero() {
+			res.Migration.FinishedAt = timestamppb.New(ms.FinishedAt)
+		}
+	}
+	return res, nil
+}
+
+// QueryActiveLogs returns the IDs of the logs, which match the request condition and have records in the request
+// time window. The log records could not be created before the log, so the logs created after the window are
+// not checked at all. The logs are checked by their chunks records IDs ranges first (see storage.LogsActivity),
+// so the log records are read only if the window is between the records of one chunk.
+func (s *Service) QueryActiveLogs(ctx context.Context, request *solaris.QueryActiveLogsRequest) (*solaris.QueryActiveLogsResult, error) {
+	if err := s.cfg.LogsCondLimits.Check(request.Condition); err != nil {
+		s.logger.Warnf("rejecting the query active logs request: %v", err)
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := checkWindow(request.CreatedAfter, request.CreatedBefore); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	limit := int(request.Limit)
+	if limit <= 0 {
+		limit = defaultActiveLogsLimit
+	}
+	after, before := timeOrZero(request.CreatedAfter), timeOrZero(request.CreatedBefore)
+
+	res := &solaris.QueryActiveLogsResult{}
+	page := request.PageID
+	// last is the ID of the last checked log, the next page starts after it
+	last := page
+	for {
+		qr, err := s.LogsStorage.QueryLogs(ctx, storage.QueryLogsRequest{Condition: request.Condition, Page: page,
+			Limit: activeLogsPageSize, CreatedBefore: before})
+		if err != nil {
+			return nil, errors.GRPCWrap(err)
+		}
+		for _, l := range accessibleLogs(ctx, qr.Logs, false) {
+			if len(res.LogIDs) == limit {
+				res.NextPageID = last
+				return res, nil
+			}
+			last = l.ID
+			active, err := s.hasRecords(ctx, l.ID, after, before)
+			if err != nil {
+				return nil, errors.GRPCWrap(err)
+			}
+			if active {
+				res.LogIDs = append(res.LogIDs, l.ID)
+			}
+		}
+		if qr.NextPageID == "" {
+			return res, nil
+		}
+		page = qr.NextPageID
+	}
+}
+
+// hasRecords returns true if the log has records created in the window
+func (s *Service) hasRecords(ctx context.Context, logID string, after, before time.Time) (bool, error) {
+	if s.LogsActivity != nil {
+		return s.LogsActivity.HasRecords(ctx, logID, after, before)
+	}
+	recs, _, err := s.LogStorage.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: logID, Limit: 1,
+		CreatedAfter: after, CreatedBefore: before})
+	return len(recs) > 0, err
+}
+
+// LatestPerLog returns the latest record of every log matching the request condition. Only the last
+// record of a log is read, so the log chunks before the last not empty one are not opened at all.
+func (s *Service) LatestPerLog(ctx context.Context, request *solaris.LatestPerLogRequest) (*solaris.LatestPerLogResult, error) {
+	if err := s.cfg.LogsCondLimits.Check(request.Condition); err != nil {
+		s.logger.Warnf("rejecting the latest per log request: %v", err)
+		return nil, errors.GRPCWrap(err)
+	}
+	// requesting maxLogsToMerge+1 to be sure that if we have more than the maximum, will interrupt the procedure
+	qr, err := s.LogsStorage.QueryLogs(ctx, storage.QueryLogsRequest{Condition: request.Condition, Limit: int64(maxLogsToMerge + 1)})
+	if err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	// the logs storage may cap the limit, so the next page means there are more logs than requested as well
+	if len(qr.Logs) > maxLogsToMerge || qr.NextPageID != "" {
+		return nil, errors.GRPCWrap(fmt.Errorf("could not read the latest records of more than %d logs: %w", maxLogsToMerge, errors.ErrExhausted))
+	}
+	res := &solaris.LatestPerLogResult{Records: make(map[string]*solaris.Record, len(qr.Logs))}
+	for _, l := range accessibleLogs(ctx, qr.Logs, false) {
+		recs, _, err := s.LogStorage.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: l.ID, Descending: true, Limit: 1})
+		if err != nil {
+			return nil, errors.GRPCWrap(err)
+		}
+		if len(recs) > 0 {
+			recs[0].LogID = l.ID
+			res.Records[l.ID] = recs[0]
+		}
+	}
+	return res, nil
+}
+
+// GetRecordByHash returns the IDs of the log records with the payload hash. The removed records are removed
+// from the index after the log chunks, so the IDs found are checked and the IDs of the removed records are skipped.
+func (s *Service) GetRecordByHash(ctx context.Context, request *solaris.GetRecordByHashRequest) (*solaris.GetRecordByHashResult, error) {
+	if request.Hash == "" {
+		return nil, errors.GRPCWrap(fmt.Errorf("the payload hash must be specified: %w", errors.ErrInvalid))
+	}
+	log, err := s.LogsStorage.GetLogByID(ctx, request.LogID)
+	if err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if err := checkAccess(ctx, log, false); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if log.PayloadHash == "" || s.RecordHashes == nil {
+		return nil, errors.GRPCWrap(fmt.Errorf("the payload hash index is not enabled for logID=%s: %w", request.LogID, errors.ErrInvalid))
+	}
+	ids, err := s.RecordHashes.GetRecordIDsByHash(ctx, request.LogID, strings.ToLower(request.Hash))
+	if err != nil || len(ids) == 0 {
+		return &solaris.GetRecordByHashResult{}, errors.GRPCWrap(err)
+	}
+	res := &solaris.GetRecordByHashResult{}
+	for _, id := range ids {
+		_, err := s.LogStorage.GetRecordByID(ctx, request.LogID, id)
+		if errors.Is(err, errors.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.GRPCWrap(err)
+		}
+		res.RecordIDs = append(res.RecordIDs, id)
+	}
+	return res, nil
+}
+
+func (s *Service) RotateLogKey(ctx context.Context, request *solaris.RotateLogKeyRequest) (*solaris.RotateLogKeyResult, error) {
+	if err := checkAdmin(ctx, s.cfg.AdminPrincipals); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	if s.LogKeys == nil {
+		return nil, errors.GRPCWrap(fmt.Errorf("the records encryption is not supported: %w", errors.ErrUnimplemented))
+	}
+	if _, err := s.LogsStorage.GetLogByID(ctx, request.LogID); err != nil {
+		return nil, errors.GRPCWrap(err)
+	}
+	keyID, err := s.LogKeys.RotateLogKey(ctx, request.LogID)
+	if err != nil {
+		s.logger.Warnf("could not rotate the key of logID=%s: %v", request.LogID, err)
+		return nil, errors.GRPCWrap(err)
+	}
+	return &solaris.RotateLogKeyResult{KeyID: keyID}, nil
+}
+
+// addRecordHashes adds the appended records to the log index by the payloads hashes. The records are
+// written already, so the error is only logged and the records are not found by the hashes.
+func (s *Service) addRecordHashes(ctx context.Context, logID string, ids, hashes []string) {
+	rhs := make([]storage.RecordHash, 0, len(ids))
+	for i, id := range ids {
+		rhs = append(rhs, storage.RecordHash{Hash: hashes[i], RecordID: id})
+	}
+	if len(rhs) == 0 {
+		return
+	}
+	if err := s.RecordHashes.AddRecordHashes(ctx, logID, rhs); err != nil {
+		s.logger.Errorf("could not index %d records of logID=%s by the payloads hashes: %v", len(rhs), logID, err)
+	}
+}
+
+// checkPayloadHash returns an error if the logs records could not be indexed by the payload hash function
+func (s *Service) checkPayloadHash(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := payloadHashes[name]; !ok {
+		return fmt.Errorf("unknown payload hash function %q: %w", name, errors.ErrInvalid)
+	}
+	if s.RecordHashes == nil {
+		return fmt.Errorf("the records payload hash index is not supported: %w", errors.ErrUnimplemented)
+	}
+	return nil
+}
+
+// checkRetention returns errors.ErrInvalid if the log retention policy is negative
+func checkRetention(log *solaris.Log) error {
+	if log.RetentionMaxAge.AsDuration() < 0 || log.RetentionMaxRecords < 0 {
+		return fmt.Errorf("the retention policy (maxAge=%s, maxRecords=%d) must not be negative: %w",
+			log.RetentionMaxAge.AsDuration(), log.RetentionMaxRecords, errors.ErrInvalid)
+	}
+	return nil
+}
+
+// checkWritable returns errors.ErrConflict if the Service is in the read-only mode
+func (s *Service) checkWritable() error {
+	if s.readOnly.Load() {
+		return fmt.Errorf("the server is in read-only mode: %w", errors.ErrConflict)
+	}
+	return nil
+}
+
+// checkLogsExist returns errors.ErrNotExist if any of the logs doesn't exist. The logs are requested by their IDs
+// all together, the storage may cap the page size, so the pages are read until all the logs are found.
+func (s *Service) checkLogsExist(ctx context.Context, logIDs []string) error {
+	missing := make(map[string]struct{}, len(logIDs))
+	for _, id := range logIDs {
+		missing[id] = struct{}{}
+	}
+	qr := storage.QueryLogsRequest{IDs: logIDs, Limit: int64(len(missing))}
+	for len(missing) > 0 {
+		res, err := s.LogsStorage.QueryLogs(ctx, qr)
+		if err != nil {
+			return err
+		}
+		for _, l := range res.Logs {
+			delete(missing, l.ID)
+		}
+		if res.NextPageID == "" {
+			break
+		}
+		qr.Page = res.NextPageID
+	}
+	for _, id := range logIDs {
+		if _, ok := missing[id]; ok {
+			return fmt.Errorf("the log with ID=%s is not found: %w", id, errors.ErrNotExist)
+		}
+	}
+	return nil
+}
+
+// recordsCondition returns the records condition of the request and its AST. If the request refers
+// to a compiled condition by the handle, the AST is taken from the compiled conditions cache, otherwise
+// the condition is parsed once for all the logs of the request.
+func (s *Service) recordsCondition(request *solaris.QueryRecordsRequest) (string, *ql.Expression, error) {
+	if request.ConditionHandle != "" {
+		cc, err := s.conds.get(request.ConditionHandle)
+		return cc.cond, cc.expr, embedParseError(err)
+	}
+	if len(strings.TrimSpace(request.Condition)) == 0 {
+		return request.Condition, nil, nil
+	}
+	expr, err := s.parse(request.Condition)
+	if err != nil {
+		return "", nil, embedParseError(fmt.Errorf("condition=%q parse error: %w", request.Condition, err))
+	}
+	return request.Condition, expr, nil
+}
+
+// recordsLogIDs returns the IDs of the logs the records request reads. The logs are either requested by their
+// IDs or selected by the request logs condition, the logs the client could not access are skipped.
+func (s *Service) recordsLogIDs(ctx context.Context, request *solaris.QueryRecordsRequest) ([]string, error) {
+	logIDs := request.LogIDs
+	if len(logIDs) > 0 {
+		// the inaccessible logs are filtered out first, so the client doesn't learn whether they exist
+		var err error
+		if logIDs, err = s.accessibleLogIDs(ctx, logIDs, false); err != nil {
+			return nil, err
+		}
+		if len(logIDs) > 0 && s.cfg.CheckLogsExist {
+			if err = s.checkLogsExist(ctx, logIDs); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if err := s.cfg.LogsCondLimits.Check(request.LogsCondition); err != nil {
+			return nil, err
+		}
+		// requesting maxLogsToMerge+1 to be sure that if we have more than the maximum, will interrupt the procedure
+		qr, err := s.LogsStorage.QueryLogs(ctx, storage.QueryLogsRequest{Condition: request.LogsCondition, Limit: int64(maxLogsToMerge + 1)})
+		if err != nil {
+			return nil, err
+		}
+		logs := accessibleLogs(ctx, qr.Logs, false)
+		logIDs = make([]string, len(logs))
+		for i, l := range logs {
+			logIDs[i] = l.ID
+		}
+	}
+	if len(logIDs) > maxLogsToMerge {
+		return nil, fmt.Errorf("could not merge more than %d logs together: %w", maxLogsToMerge, errors.ErrExhausted)
+	}
+	if request.StartSeq > 0 && len(logIDs) > 1 {
+		return nil, fmt.Errorf("the startSeq could be used for one log only, but %d logs are requested: %w", len(logIDs), errors.ErrInvalid)
+	}
+	return logIDs, nil
+}
+
+// recordsQuery returns the storage query of the records request, the query log ID is not set
+func (s *Service) recordsQuery(request *solaris.QueryRecordsRequest) (storage.QueryRecordsRequest, error) {
+	cond, expr, err := s.recordsCondition(request)
+	if err != nil {
+		return storage.QueryRecordsRequest{}, err
+	}
+	if request.MaxPerLog < 0 {
+		return storage.QueryRecordsRequest{}, fmt.Errorf("the maxPerLog=%d must not be negative: %w", request.MaxPerLog, errors.ErrInvalid)
+	}
+	if err := checkWindow(request.CreatedAfter, request.CreatedBefore); err != nil {
+		return storage.QueryRecordsRequest{}, err
+	}
+	return storage.QueryRecordsRequest{Condition: cond, Expr: expr,
+		Descending: request.Descending, StartID: request.StartRecordID, StartSeq: request.StartSeq,
+		StartExclusive: request.StartExclusive, Limit: request.Limit, PayloadLen: payloadLenRange(request), CreatedAfter: timeOrZero(request.CreatedAfter),
+		CreatedBefore: timeOrZero(request.CreatedBefore), SkipCorrupted: request.SkipCorrupted}, nil
+}
+
+// readLogRecords reads the page of the query log records by the records iterator, so the log chunks are read
+// only until the page is full. The page is limited by the query Limit and by the MaxBunchSize of the records
+// payloads. The returned flag is true if the log has more records after the page.
+func (s *Service) readLogRecords(ctx context.Context, query storage.QueryRecordsRequest) ([]*solaris.Record, bool, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		return nil, false, nil
+	}
+	// the iterator is not limited, so it tells whether there are more records after the page
+	query.Limit = 0
+	it, err := s.LogStorage.OpenRecordIterator(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+	defer it.Close()
+
+	var res []*solaris.Record
+	size := 0
+	for int64(len(res)) < limit && size < s.cfg.MaxBunchSize && it.HasNext() {
+		r, err := it.Next()
+		if err != nil {
+			return nil, false, err
+		}
+		size += len(r.Payload)
+		res = append(res, r)
+	}
+	return res, it.HasNext(), nil
+}
+
+// formatRecords sets the records age and moves their payloads to the any values, if the request asks for that
+func (s *Service) formatRecords(ctx context.Context, request *solaris.QueryRecordsRequest, recs []*solaris.Record) error {
+	if request.WithAge {
+		setAge(recs, time.Now())
+	}
+	if request.AsAny {
+		return s.packAny(ctx, recs)
+	}
+	return nil
+}
+
+// embedParseError embeds the ql.ParseError position of the condition parse error into err, so the
+// clients may extract it from the gRPC error message with errors.ExtractObject. Other errors are
+// returned as is.
+func embedParseError(err error) error {
+	var pe *ql.ParseError
+	if errors.As(err, &pe) {
+		return errors.EmbedObject(pe, err)
+	}
+	return err
+}
+
+// nextPageID returns the ID the next page of records starts from, if the lastID record is the last one
+// of the current page. The descending pages start from the previous ID, so the lastID record is not read again.
+func nextPageID(lastID string, descending bool) string {
+	if descending {
+		return ulidutils.PrevID(lastID)
+	}
+	return ulidutils.NextID(lastID)
+}
+
+// windowPageID returns the pageID if it is within the query window, or empty string otherwise, so the
+// paging stops at the window end
+func windowPageID(query storage.QueryRecordsRequest, pageID string) string {
+	if pageID == "" || !query.InWindow(pageID) {
+		return ""
+	}
+	return pageID
+}
+
+// splitByPayloadSize splits recs into the groups with the total payload size not greater than maxBytes.
+// A record with the payload bigger than maxBytes forms its own group.
+func splitByPayloadSize(recs []*solaris.Record, maxBytes int64) [][]*solaris.Record {
+	var res [][]*solaris.Record
+	start := 0
+	var size int64
+	for i, r := range recs {
+		ln := int64(len(r.Payload) + len(r.GetAny().GetValue()))
+		if i > start && size+ln > maxBytes {
+			res = append(res, recs[start:i])
+			start, size = i, 0
+		}
+		size += ln
+	}
+	if start < len(recs) {
+		res = append(res, recs[start:])
+	}
+	return res
+}
+
+// setAge sets the records age at the time now. The age is calculated by the record ID timestamp.
+func setAge(recs []*solaris.Record, now time.Time) {
+	for _, r := range recs {
+		id, err := ulid.Parse(r.ID)
+		if err != nil {
+			continue
+		}
+		r.AgeMs = now.Sub(ulid.Time(id.Time())).Milliseconds()
+	}
+}
+
+// packAny moves the payloads of the records, which logs have the payloadTypeURL, to the records any field,
+// so the payloads are returned as google.protobuf.Any values
+func (s *Service) packAny(ctx context.Context, recs []*solaris.Record) error {
+	urls := make(map[string]string)
+	for _, r := range recs {
+		url, ok := urls[r.LogID]
+		if !ok {
+			log, err := s.LogsStorage.GetLogByID(ctx, r.LogID)
+			if err != nil && !errors.Is(err, errors.ErrNotExist) {
+				return err
+			}
+			if err == nil {
+				url = log.PayloadTypeURL
+			}
+			urls[r.LogID] = url
+		}
+		if url != "" {
+			r.Any = &anypb.Any{TypeUrl: url, Value: r.Payload}
+			r.Payload = nil
+		}
+	}
+	return nil
+}
+
+// checkUTF8 returns errors.ErrInvalid if a record payload is not a valid UTF-8 text
+func checkUTF8(recs []*solaris.Record) error {
+	for i, r := range recs {
+		if !utf8.Valid(r.Payload) {
+			return fmt.Errorf("the record %d payload is not a valid UTF-8 text: %w", i, errors.ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// addAppendResult adds the batch append result res to the total one
+func addAppendResult(total, res *solaris.AppendRecordsResult) {
+	if res.Added > 0 {
+		if total.StartID == "" {
+			total.StartID = res.StartID
+		}
+		total.LastID = res.LastID
+	}
+	total.Added += res.Added
+	total.BytesWritten += res.BytesWritten
+	total.RecordIDs = append(total.RecordIDs, res.RecordIDs...)
+	for _, w := range res.Warnings {
+		if !slices.Contains(total.Warnings, w) {
+			total.Warnings = append(total.Warnings, w)
+		}
+	}
+	total.Partial = total.Partial || res.Partial
+	if res.Rejected != nil {
+		total.Rejected = res.Rejected
+	}
+}
+
+// hashPayloads returns the hex-encoded hashes of the records payloads made by the hash function name
+func hashPayloads(name string, recs []*solaris.Record) ([]string, error) {
+	newHash, ok := payloadHashes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown payload hash function %q: %w", name, errors.ErrInvalid)
+	}
+	hashes := make([]string, len(recs))
+	h := newHash()
+	for i, r := range recs {
+		h.Reset()
+		h.Write(r.Payload)
+		hashes[i] = hex.EncodeToString(h.Sum(nil))
+	}
+	return hashes, nil
+}
+
+func payloadLenRange(request *solaris.QueryRecordsRequest) storage.PayloadLenRange {
+	return storage.PayloadLenRange{Min: request.MinPayloadLen, Max: request.MaxPayloadLen}
+}
+
+// checkWindow checks the records window is not inverted
+func checkWindow(after, before *timestamppb.Timestamp) error {
+	if after != nil && before != nil && after.AsTime().After(before.AsTime()) {
+		return fmt.Errorf("the createdAfter=%s must not be after the createdBefore=%s: %w", after.AsTime(),
+			before.AsTime(), errors.ErrInvalid)
+	}
+	return nil
+}
+
+func timeOrZero(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// checkBatchChecksum returns errors.ErrInvalid if the request checksum is specified, but
+// doesn't match the records payloads (see AppendRecordsRequest.Checksum)
+func checkBatchChecksum(request *solaris.AppendRecordsRequest) error {
+	if request.Checksum == "" && request.ChecksumAlgorithm == "" {
+		return nil
+	}
+	sum, err := batchChecksum(request.ChecksumAlgorithm, request.Records)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(sum, request.Checksum) {
+		return fmt.Errorf("the batch checksum mismatch, expected %q, but calculated %q: %w", request.Checksum, sum, errors.ErrInvalid)
+	}
+	return nil
+}
+
+// batchChecksum returns the hex-encoded hash of the records payloads, each preceded by its length
+func batchChecksum(name string, recs []*solaris.Record) (string, error) {
+	newHash, ok := payloadHashes[name]
+	if !ok {
+		return "", fmt.Errorf("unknown checksum algorithm %q: %w", name, errors.ErrInvalid)
+	}
+	h := newHash()
+	var l [8]byte
+	for _, r := range recs {
+		binary.BigEndian.PutUint64(l[:], uint64(len(r.Payload)))
+		h.Write(l[:])
+		h.Write(r.Payload)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,1520 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/oklog/ulid/v2"
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/files"
+	"github.com/solarisdb/solaris/golibs/sss/inmem"
+	"github.com/solarisdb/solaris/golibs/ulidutils"
+	"github.com/solarisdb/solaris/pkg/ql"
+	"github.com/solarisdb/solaris/pkg/storage"
+	"github.com/solarisdb/solaris/pkg/storage/buntdb"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
+	"github.com/solarisdb/solaris/pkg/storage/logfs"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_QueryLogsConditionLimits(t *testing.T) {
+	ls := &countingLogs{}
+	svc := NewService(GetDefaultConfig())
+	svc.LogsStorage = ls
+
+	_, err := svc.QueryLogs(context.Background(), &solaris.QueryLogsRequest{Condition: "tag('a') = 'b'"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, ls.queries)
+
+	cond := strings.Repeat("tag('a') = 'b' OR ", 10000) + "tag('a') = 'b'"
+	_, err = svc.QueryLogs(context.Background(), &solaris.QueryLogsRequest{Condition: cond})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+	_, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogsCondition: cond})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+	_, err = svc.DeleteLogs(context.Background(), &solaris.DeleteLogsRequest{Condition: cond})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+	assert.Equal(t, 1, ls.queries)
+}
+
+func TestService_StreamRecords(t *testing.T) {
+	ls := storage.NewLogHelper()
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogStorage = ls
+
+	var recs []*solaris.Record
+	for i := 0; i < 2*streamPageSize+10; i++ {
+		recs = append(recs, &solaris.Record{Payload: make([]byte, 1+i%50)})
+	}
+	recs = append(recs, &solaris.Record{Payload: make([]byte, 500)})
+	ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs[:len(recs)/2], LogID: "1"})
+	ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs[len(recs)/2:], LogID: "2"})
+
+	for _, logIDs := range [][]string{{"1"}, {"1", "2"}} {
+		ts := &testStream{ctx: context.Background()}
+		err := svc.StreamRecords(&solaris.StreamRecordsRequest{Query: &solaris.QueryRecordsRequest{LogIDs: logIDs}, MaxMessageBytes: 100}, ts)
+		assert.Nil(t, err)
+		cnt := 0
+		for _, m := range ts.msgs {
+			size := 0
+			for _, r := range m.Records {
+				size += len(r.Payload)
+			}
+			assert.True(t, size <= 100 || len(m.Records) == 1)
+			cnt += len(m.Records)
+		}
+		if len(logIDs) == 1 {
+			assert.Equal(t, len(recs)/2, cnt)
+		} else {
+			assert.Equal(t, len(recs), cnt)
+		}
+	}
+
+	ts := &testStream{ctx: context.Background()}
+	err := svc.StreamRecords(&solaris.StreamRecordsRequest{Query: &solaris.QueryRecordsRequest{LogIDs: []string{"1", "2"}, Limit: 1500}}, ts)
+	assert.Nil(t, err)
+	cnt := 0
+	for _, m := range ts.msgs {
+		cnt += len(m.Records)
+	}
+	assert.Equal(t, 1500, cnt)
+
+	err = svc.StreamRecords(&solaris.StreamRecordsRequest{}, ts)
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+}
+
+func TestService_CompiledCondition(t *testing.T) {
+	ls := &queriedLog{Log: storage.NewLogHelper()}
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogStorage = ls
+	parses := 0
+	svc.parse = func(cond string) (*ql.Expression, error) {
+		parses++
+		return ql.Parse(cond)
+	}
+	ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: []*solaris.Record{{Payload: []byte("a")}, {Payload: []byte("b")}}, LogID: "1"})
+	ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: []*solaris.Record{{Payload: []byte("c")}}, LogID: "2"})
+
+	cond := "ctime > '2024-01-01T00:00:00Z'"
+	var inline []*solaris.QueryRecordsResult
+	for _, logIDs := range [][]string{{"1"}, {"1", "2"}} {
+		res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Condition: cond, Limit: 10})
+		assert.Nil(t, err)
+		inline = append(inline, res)
+	}
+	assert.Equal(t, 2, parses)
+	inlineReqs := ls.reqs
+	ls.reqs = nil
+
+	cc, err := svc.CompileCondition(context.Background(), &solaris.CompileConditionRequest{Condition: cond})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, parses)
+	cc2, err := svc.CompileCondition(context.Background(), &solaris.CompileConditionRequest{Condition: cond})
+	assert.Nil(t, err)
+	assert.Equal(t, cc.Handle, cc2.Handle)
+	for i, logIDs := range [][]string{{"1"}, {"1", "2"}} {
+		res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, ConditionHandle: cc.Handle, Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, inline[i], res)
+	}
+	cnt, err := svc.CountRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1", "2"}, ConditionHandle: cc.Handle})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), cnt.Total)
+	assert.Equal(t, 3, parses)
+	// the logs get the same condition and its AST
+	assert.Equal(t, inlineReqs, ls.reqs[:len(inlineReqs)])
+
+	// invalidate
+	ir, err := svc.InvalidateCondition(context.Background(), &solaris.InvalidateConditionRequest{Handle: cc.Handle})
+	assert.Nil(t, err)
+	assert.True(t, ir.Invalidated)
+	_, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1"}, ConditionHandle: cc.Handle})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrNotExist))
+
+	// expiration
+	svc.conds.ttl = 0
+	cc, err = svc.CompileCondition(context.Background(), &solaris.CompileConditionRequest{Condition: cond})
+	assert.Nil(t, err)
+	_, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1"}, ConditionHandle: cc.Handle})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrNotExist))
+
+	_, err = svc.CompileCondition(context.Background(), &solaris.CompileConditionRequest{Condition: "ctime >"})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+}
+
+func TestService_QueryRecordsStartSeq(t *testing.T) {
+	ls := &queriedLog{Log: storage.NewLogHelper()}
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogStorage = ls
+	var recs []*solaris.Record
+	for i := 0; i < streamPageSize+10; i++ {
+		recs = append(recs, &solaris.Record{Payload: []byte("a")})
+	}
+	ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: "1"})
+
+	_, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1"}, StartSeq: 5, Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), ls.reqs[0].StartSeq)
+
+	// the sequence numbers are per log
+	_, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1", "2"}, StartSeq: 5})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+	_, err = svc.CountRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1", "2"}, StartSeq: 5})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+
+	// the log is streamed by one iterator from the startSeq
+	ls.reqs = nil
+	err = svc.StreamRecords(&solaris.StreamRecordsRequest{Query: &solaris.QueryRecordsRequest{LogIDs: []string{"1"}, StartSeq: 5}},
+		&testStream{ctx: context.Background()})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ls.reqs))
+	assert.Equal(t, int64(5), ls.reqs[0].StartSeq)
+}
+
+func TestService_QueryRecordsIterator(t *testing.T) {
+	ls := &queriedLog{Log: storage.NewLogHelper()}
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	cfg.MaxRecordsLimit = 20
+	svc := NewService(cfg)
+	svc.LogStorage = ls
+	var recs []*solaris.Record
+	for i := 0; i < 50; i++ {
+		recs = append(recs, &solaris.Record{Payload: []byte("a")})
+	}
+	ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: "1"})
+
+	// the page is read by the iterator, which is closed when the page is full
+	res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1"}, Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(res.Records))
+	assert.NotEmpty(t, res.NextPageID)
+	assert.Equal(t, 1, ls.opened)
+	assert.Equal(t, 1, ls.closed)
+
+	// the page is limited by the config
+	res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1"}, Limit: 100})
+	assert.Nil(t, err)
+	assert.Equal(t, 20, len(res.Records))
+	assert.NotEmpty(t, res.NextPageID)
+
+	// the last page
+	res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"1"}, StartRecordID: recs[45].ID, Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(res.Records))
+	assert.Empty(t, res.NextPageID)
+
+	// the stream is not limited by the config, and the iterator is closed when the stream is broken
+	ts := &testStream{ctx: context.Background()}
+	err = svc.StreamRecords(&solaris.StreamRecordsRequest{Query: &solaris.QueryRecordsRequest{LogIDs: []string{"1"}}, MaxMessageBytes: 10}, ts)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(ts.msgs))
+	ts = &testStream{ctx: context.Background(), failAfter: 2}
+	err = svc.StreamRecords(&solaris.StreamRecordsRequest{Query: &solaris.QueryRecordsRequest{LogIDs: []string{"1"}}, MaxMessageBytes: 10}, ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(ts.msgs))
+	assert.Equal(t, 5, ls.opened)
+	assert.Equal(t, ls.opened, ls.closed)
+}
+
+func TestService_QueryRecordsMaxPerLog(t *testing.T) {
+	ls := storage.NewLogHelper()
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogStorage = ls
+	// the quiet logs records are older than the active log ones
+	for _, lid := range []string{"quiet1", "quiet2", "active"} {
+		n := 1
+		if lid == "active" {
+			n = 20
+		}
+		recs := make([]*solaris.Record, n)
+		for i := range recs {
+			recs[i] = &solaris.Record{Payload: []byte(lid)}
+		}
+		ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: lid})
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	logIDs := []string{"quiet1", "quiet2", "active"}
+	res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Descending: true, Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(res.Records))
+	assert.Equal(t, "active", string(res.Records[9].Payload))
+
+	res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Descending: true, Limit: 10, MaxPerLog: 3})
+	assert.Nil(t, err)
+	var payloads []string
+	for _, r := range res.Records {
+		payloads = append(payloads, string(r.Payload))
+	}
+	assert.Equal(t, []string{"active", "active", "active", "quiet2", "quiet1"}, payloads)
+	// the active log records over the limit are skipped, and the next page starts after the last returned record
+	assert.Equal(t, ulidutils.PrevID(res.Records[4].ID), res.NextPageID)
+	res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Descending: true, Limit: 10,
+		MaxPerLog: 3, StartRecordID: res.NextPageID})
+	assert.Nil(t, err)
+	assert.Empty(t, res.Records)
+	assert.Empty(t, res.NextPageID)
+
+	// the global limit is applied to the capped result
+	res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Descending: true, Limit: 4, MaxPerLog: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(res.Records))
+	assert.Equal(t, "quiet2", string(res.Records[3].Payload))
+	// the next page starts from the next record of the merged result
+	assert.NotEmpty(t, res.NextPageID)
+	assert.True(t, res.NextPageID < res.Records[3].ID)
+
+	res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"active"}, Limit: 10, MaxPerLog: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(res.Records))
+
+	_, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, MaxPerLog: -1})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+}
+
+func TestService_AppendRecordsValidateUTF8(t *testing.T) {
+	svc := NewService(GetDefaultConfig())
+	svc.LogsStorage = &testLogs{logs: map[string]*solaris.Log{"text": {ID: "text", ValidateUTF8: true}, "bin": {ID: "bin"}}}
+	svc.LogStorage = storage.NewLogHelper()
+
+	valid := []*solaris.Record{{Payload: []byte("hello")}, {Payload: []byte("привет, 世界")}, {Payload: []byte{}}}
+	invalid := []*solaris.Record{{Payload: []byte("hello")}, {Payload: []byte{0xff, 0xfe, 0xfd}}}
+
+	res, err := svc.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: "text", Records: valid})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), res.Added)
+
+	_, err = svc.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: "text", Records: invalid})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+	assert.Contains(t, err.Error(), "record 1")
+	cnt, err := svc.CountRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"text"}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), cnt.Total)
+
+	// the option is disabled for the log
+	res, err = svc.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: "bin", Records: invalid})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), res.Added)
+}
+
+func TestService_QueryRecordsManyLogs(t *testing.T) {
+	ls := storage.NewLogHelper()
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogStorage = ls
+
+	// the logs records are interleaved
+	logIDs := make([]string, 500)
+	for i := range logIDs {
+		logIDs[i] = fmt.Sprintf("l%d", i)
+	}
+	for j := 0; j < 3; j++ {
+		for i, lid := range logIDs {
+			if (i+j)%4 == 0 {
+				continue
+			}
+			_, err := ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: lid,
+				Records: []*solaris.Record{{Payload: []byte(lid)}}})
+			assert.Nil(t, err)
+		}
+	}
+	var ids []string
+	for _, lid := range logIDs {
+		recs, _, err := ls.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: lid, Limit: 10})
+		assert.Nil(t, err)
+		for _, r := range recs {
+			ids = append(ids, r.ID)
+		}
+	}
+	slices.Sort(ids)
+
+	for _, desc := range []bool{false, true} {
+		var read []string
+		pageID := ""
+		for pages := 0; pages < 100; pages++ {
+			res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Limit: 77,
+				Descending: desc, StartRecordID: pageID})
+			assert.Nil(t, err)
+			for _, r := range res.Records {
+				read = append(read, r.ID)
+			}
+			if res.NextPageID == "" {
+				break
+			}
+			pageID = res.NextPageID
+		}
+		exp := slices.Clone(ids)
+		if desc {
+			slices.Reverse(exp)
+		}
+		assert.Equal(t, exp, read)
+
+		// the pages are read by the last record IDs of the previous ones
+		read, pageID = nil, ""
+		for pages := 0; pages < 100; pages++ {
+			res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Limit: 77,
+				Descending: desc, StartRecordID: pageID, StartExclusive: pageID != ""})
+			assert.Nil(t, err)
+			for _, r := range res.Records {
+				read = append(read, r.ID)
+			}
+			if res.NextPageID == "" {
+				break
+			}
+			pageID = res.Records[len(res.Records)-1].ID
+		}
+		assert.Equal(t, exp, read)
+	}
+}
+
+func TestService_QueryRecordsCanceled(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ls := &cancelingLog{Log: storage.NewLogHelper(), cancel: cancel, left: 5}
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogStorage = ls
+
+	logIDs := make([]string, 20)
+	for i := range logIDs {
+		logIDs[i] = fmt.Sprintf("l%d", i)
+		_, err := ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: logIDs[i],
+			Records: []*solaris.Record{{Payload: []byte("a")}, {Payload: []byte("b")}}})
+		assert.Nil(t, err)
+	}
+
+	// the request is canceled while the logs are read, the blocked read is interrupted and no more reads are done
+	start := time.Now()
+	_, err := svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: logIDs, Limit: 100})
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, 6, ls.calls)
+}
+
+func TestService_AppendRecordsMaxRecords(t *testing.T) {
+	svc := NewService(GetDefaultConfig())
+	svc.LogsStorage = &testLogs{logs: map[string]*solaris.Log{"capped": {ID: "capped", MaxRecords: 3}, "l1": {ID: "l1"}}}
+	svc.LogStorage = storage.NewLogHelper()
+
+	for _, lid := range []string{"capped", "l1"} {
+		for i := 0; i < 5; i++ {
+			_, err := svc.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: lid,
+				Records: []*solaris.Record{{Payload: []byte(fmt.Sprintf("%d", i))}}})
+			assert.Nil(t, err)
+		}
+	}
+	res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"capped"}, Limit: 10})
+	assert.Nil(t, err)
+	assert.Len(t, res.Records, 3)
+	for i, r := range res.Records {
+		assert.Equal(t, fmt.Sprintf("%d", i+2), string(r.Payload))
+	}
+	cnt, err := svc.CountRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"l1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), cnt.Total)
+	assert.True(t, cnt.Exact)
+
+	// the log may keep the slack records over the maxRecords
+	svc.LogsStorage.(*testLogs).logs["slack"] = &solaris.Log{ID: "slack", MaxRecords: 20}
+	count := func() int64 {
+		cnt, err := svc.CountRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"slack"}})
+		assert.Nil(t, err)
+		return cnt.Total
+	}
+	for i := 0; i < 22; i++ {
+		_, err := svc.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: "slack",
+			Records: []*solaris.Record{{Payload: []byte(fmt.Sprintf("%d", i))}}})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int64(22), count())
+	_, err = svc.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: "slack",
+		Records: []*solaris.Record{{Payload: []byte("22")}}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), count())
+}
+
+func TestService_QueryRecordsPages(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestService_QueryRecordsPages")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	// the chunks are small, so the pages cross the chunks boundaries
+	p := chunkfs.NewProvider(dir, 10, chunkfs.Config{NewSize: files.BlockSize, MaxChunkSize: 2 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize})
+	p.CA = chunkfs.NewChunkAccessor()
+	p.Replicator = chunkfs.NewReplicator(p.GetFileNameByID, chunkfs.GetDefaultReplicatorConfig())
+	p.Replicator.CA = p.CA
+	p.Replicator.Storage = inmem.NewStorage()
+	defer p.Close()
+	lms := buntdb.NewStorage(buntdb.Config{})
+	assert.Nil(t, lms.Init(ctx))
+	defer lms.Shutdown()
+	ll := logfs.NewLocalLog(logfs.GetDefaultConfig())
+	ll.LMStorage = lms
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogsStorage = lms
+	svc.LogStorage = ll
+
+	logIDs := []string{}
+	for i := 0; i < 2; i++ {
+		l, err := lms.CreateLog(ctx, &solaris.Log{})
+		assert.Nil(t, err)
+		logIDs = append(logIDs, l.ID)
+	}
+	for i := 0; i < 30; i++ {
+		_, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: logIDs[i%3/2],
+			Records: []*solaris.Record{{Payload: make([]byte, 3000)}}})
+		assert.Nil(t, err)
+	}
+	cis, err := lms.GetChunks(ctx, logIDs[0])
+	assert.Nil(t, err)
+	assert.True(t, len(cis) > 3)
+
+	for _, lids := range [][]string{logIDs[:1], logIDs} {
+		for _, desc := range []bool{false, true} {
+			all, err := svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: lids, Limit: 100, Descending: desc})
+			assert.Nil(t, err)
+			assert.Empty(t, all.NextPageID)
+
+			var read []*solaris.Record
+			pageID := ""
+			for pages := 0; pages < 100; pages++ {
+				res, err := svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: lids, Limit: 7, Descending: desc,
+					StartRecordID: pageID})
+				assert.Nil(t, err)
+				read = append(read, res.Records...)
+				if res.NextPageID == "" {
+					break
+				}
+				pageID = res.NextPageID
+			}
+			assert.Equal(t, len(all.Records), len(read))
+			for i, r := range read {
+				assert.Equal(t, all.Records[i].ID, r.ID)
+			}
+		}
+	}
+}
+
+// newTestLocalService creates the Service with the small local chunks in the dir and the in-memory logs storage.
+// The returned function releases the Service resources.
+func newTestLocalService(t *testing.T, dir string) (*Service, *buntdb.Storage, func()) {
+	p := chunkfs.NewProvider(dir, 10, chunkfs.Config{NewSize: files.BlockSize, MaxChunkSize: 2 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize})
+	p.CA = chunkfs.NewChunkAccessor()
+	p.Replicator = chunkfs.NewReplicator(p.GetFileNameByID, chunkfs.GetDefaultReplicatorConfig())
+	p.Replicator.CA = p.CA
+	p.Replicator.Storage = inmem.NewStorage()
+	lms := buntdb.NewStorage(buntdb.Config{})
+	assert.Nil(t, lms.Init(context.Background()))
+	ll := logfs.NewLocalLog(logfs.GetDefaultConfig())
+	ll.LMStorage = lms
+	ll.ChnkProvider = p
+	ll.RecordHashes = lms
+
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogsStorage = lms
+	svc.LogStorage = ll
+	svc.RecordHashes = lms
+	svc.LogsActivity = ll
+	return svc, lms, func() {
+		ll.Shutdown()
+		lms.Shutdown()
+		p.Close()
+	}
+}
+
+func TestService_ConditionParseError(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestService_ConditionParseError")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+	l, err := lms.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+
+	checkErr := func(err error, offset int, token string) {
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.True(t, errors.Is(err, errors.ErrInvalid))
+		var pe ql.ParseError
+		if assert.True(t, errors.ExtractObject(err, &pe), err.Error()) {
+			assert.Equal(t, offset, pe.Offset)
+			assert.Equal(t, 1, pe.Line)
+			assert.Equal(t, offset+1, pe.Column)
+			assert.Equal(t, token, pe.Token)
+		}
+	}
+
+	_, err = svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{l.ID}, Condition: "ctime > 'a' 'b'"})
+	checkErr(err, 12, "'b'")
+	_, err = svc.CountRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{l.ID}, Condition: "ctime >"})
+	checkErr(err, 7, "")
+	_, err = svc.CompileCondition(ctx, &solaris.CompileConditionRequest{Condition: "ctime ~ 'a'"})
+	checkErr(err, 6, "~")
+	_, err = svc.QueryLogs(ctx, &solaris.QueryLogsRequest{Condition: "tag('a') IN ('a',)"})
+	checkErr(err, 17, ")")
+}
+
+func TestService_CountRecordsError(t *testing.T) {
+	ctx := context.Background()
+	svc, lms, closeF := newTestLocalService(t, t.TempDir())
+	defer closeF()
+	l, err := lms.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: l.ID, Records: []*solaris.Record{{Payload: []byte("a")}}})
+	assert.Nil(t, err)
+
+	// the log count errors are returned as the gRPC ones
+	_, err = svc.CountRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{l.ID}, StartRecordID: "wrong"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestService_QueryRecordsWindow(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestService_QueryRecordsWindow")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+
+	logIDs := []string{}
+	for i := 0; i < 3; i++ {
+		l, err := lms.CreateLog(ctx, &solaris.Log{})
+		assert.Nil(t, err)
+		logIDs = append(logIDs, l.ID)
+	}
+	// the records before, within and after the window are written in the different milliseconds
+	window := map[string][]string{}
+	var wIDs []string
+	for b := 0; b < 3; b++ {
+		time.Sleep(2 * time.Millisecond)
+		for i := 0; i < 20; i++ {
+			res, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: logIDs[i%len(logIDs)],
+				Records: []*solaris.Record{{Payload: make([]byte, 1000)}}})
+			assert.Nil(t, err)
+			if b == 1 {
+				lid := logIDs[i%len(logIDs)]
+				window[lid] = append(window[lid], res.StartID)
+				wIDs = append(wIDs, res.StartID)
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	slices.Sort(wIDs)
+	after := timestamppb.New(ulid.Time(ulid.MustParse(wIDs[0]).Time()))
+	before := timestamppb.New(ulid.Time(ulid.MustParse(wIDs[len(wIDs)-1]).Time()))
+
+	for _, lids := range [][]string{logIDs[:1], logIDs} {
+		var exp []string
+		for _, lid := range lids {
+			exp = append(exp, window[lid]...)
+		}
+		slices.Sort(exp)
+		slices.Reverse(exp)
+
+		// newest-first within the window
+		var read []string
+		pageID := ""
+		for pages := 0; pages < 100; pages++ {
+			res, err := svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: lids, Limit: 3, Descending: true,
+				StartRecordID: pageID, CreatedAfter: after, CreatedBefore: before})
+			assert.Nil(t, err)
+			for _, r := range res.Records {
+				read = append(read, r.ID)
+			}
+			if res.NextPageID == "" {
+				break
+			}
+			pageID = res.NextPageID
+		}
+		assert.Equal(t, exp, read)
+
+		cnt, err := svc.CountRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: lids, CreatedAfter: after, CreatedBefore: before})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(exp)), cnt.Count)
+	}
+
+	// the window records may be written within one millisecond, so the reversed window is made wider
+	_, err = svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: logIDs, Limit: 3,
+		CreatedAfter: timestamppb.New(before.AsTime().Add(time.Millisecond)), CreatedBefore: after})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
+func TestService_QueryActiveLogs(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestService_QueryActiveLogs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+
+	logs := map[string]string{}
+	for _, name := range []string{"before", "within1", "gap", "within2", "empty", "after"} {
+		l, err := lms.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"name": name}})
+		assert.Nil(t, err)
+		logs[name] = l.ID
+	}
+	appendTo := func(names ...string) (first, last time.Time) {
+		time.Sleep(2 * time.Millisecond)
+		for i := 0; i < 10; i++ {
+			for _, n := range names {
+				res, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: logs[n],
+					Records: []*solaris.Record{{Payload: make([]byte, 1000)}}})
+				assert.Nil(t, err)
+				tm := ulid.Time(ulid.MustParse(res.StartID).Time())
+				if first.IsZero() {
+					first = tm
+				}
+				last = tm
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		return first, last
+	}
+	// the "gap" log has the records before and after the window, but not within it
+	appendTo("before", "gap")
+	after, before := appendTo("within1", "within2")
+	appendTo("gap", "after")
+
+	query := func(req *solaris.QueryActiveLogsRequest) []string {
+		var res []string
+		for {
+			qr, err := svc.QueryActiveLogs(ctx, req)
+			assert.Nil(t, err)
+			assert.True(t, req.Limit == 0 || len(qr.LogIDs) <= int(req.Limit))
+			res = append(res, qr.LogIDs...)
+			if qr.NextPageID == "" {
+				return res
+			}
+			req.PageID = qr.NextPageID
+		}
+	}
+	expected := func(names ...string) []string {
+		var res []string
+		for _, n := range names {
+			res = append(res, logs[n])
+		}
+		slices.Sort(res)
+		return res
+	}
+
+	assert.Equal(t, expected("within1", "within2"), query(&solaris.QueryActiveLogsRequest{
+		CreatedAfter: timestamppb.New(after), CreatedBefore: timestamppb.New(before)}))
+	assert.Equal(t, expected("within1", "within2"), query(&solaris.QueryActiveLogsRequest{
+		CreatedAfter: timestamppb.New(after), CreatedBefore: timestamppb.New(before), Limit: 1}))
+	assert.Equal(t, expected("gap", "within1", "within2"), query(&solaris.QueryActiveLogsRequest{
+		Condition: "tag('name') like 'within%' OR tag('name') = 'gap'", CreatedAfter: timestamppb.New(after), Limit: 1}))
+	assert.Equal(t, expected("before", "gap", "within1", "within2"), query(&solaris.QueryActiveLogsRequest{
+		CreatedBefore: timestamppb.New(before), Limit: 2}))
+
+	_, err = svc.QueryActiveLogs(ctx, &solaris.QueryActiveLogsRequest{CreatedAfter: timestamppb.New(before),
+		CreatedBefore: timestamppb.New(after.Add(-time.Millisecond))})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
+func TestService_LatestPerLog(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestService_LatestPerLog")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+
+	logs := map[string]string{}
+	for _, name := range []string{"a", "b", "c", "empty"} {
+		l, err := lms.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"name": name}})
+		assert.Nil(t, err)
+		logs[name] = l.ID
+	}
+	// the records of the logs are interleaved, the "a" log has several chunks
+	latest := map[string]string{}
+	for i := 0; i < 20; i++ {
+		for _, n := range []string{"a", "b", "c"} {
+			if n != "a" && i%5 != 0 {
+				continue
+			}
+			res, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: logs[n],
+				Records: []*solaris.Record{{Payload: make([]byte, 1000)}, {Payload: []byte(n)}}})
+			assert.Nil(t, err)
+			latest[logs[n]] = res.LastID
+		}
+	}
+	cis, err := lms.GetChunks(ctx, logs["a"])
+	assert.Nil(t, err)
+	assert.True(t, len(cis) > 1)
+
+	check := func(cond string, names ...string) {
+		res, err := svc.LatestPerLog(ctx, &solaris.LatestPerLogRequest{Condition: cond})
+		assert.Nil(t, err)
+		assert.Equal(t, len(names), len(res.Records), cond)
+		for _, n := range names {
+			r := res.Records[logs[n]]
+			if assert.NotNil(t, r, n) {
+				assert.Equal(t, latest[logs[n]], r.ID)
+				assert.Equal(t, logs[n], r.LogID)
+				assert.Equal(t, []byte(n), r.Payload)
+			}
+		}
+	}
+	check("", "a", "b", "c")
+	check("tag('name') IN ('a', 'c', 'empty')", "a", "c")
+	check("tag('name') = 'empty'")
+
+	// too many logs
+	for i := 0; i < maxLogsToMerge; i++ {
+		_, err := lms.CreateLog(ctx, &solaris.Log{})
+		assert.Nil(t, err)
+	}
+	_, err = svc.LatestPerLog(ctx, &solaris.LatestPerLogRequest{})
+	assert.True(t, errors.Is(err, errors.ErrExhausted))
+}
+
+func TestService_GetRecordByHash(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestService_GetRecordByHash")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+
+	_, err = svc.CreateLog(ctx, &solaris.Log{PayloadHash: "md5"})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	log, err := svc.CreateLog(ctx, &solaris.Log{PayloadHash: "sha256", MaxRecords: 4})
+	assert.Nil(t, err)
+	plain, err := svc.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+
+	hashOf := func(p string) string {
+		hs, err := hashPayloads("sha256", []*solaris.Record{{Payload: []byte(p)}})
+		assert.Nil(t, err)
+		return hs[0]
+	}
+	lookup := func(p string) []string {
+		res, err := svc.GetRecordByHash(ctx, &solaris.GetRecordByHashRequest{LogID: log.ID, Hash: hashOf(p)})
+		assert.Nil(t, err)
+		return res.RecordIDs
+	}
+
+	// the duplicate payloads are found by the same hash
+	res, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: log.ID,
+		Records: []*solaris.Record{{Payload: []byte("a")}, {Payload: []byte("b")}, {Payload: []byte("a")}}})
+	assert.Nil(t, err)
+	assert.Nil(t, res.RecordIDs)
+	res2, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: log.ID, ExpandIDs: true,
+		Records: []*solaris.Record{{Payload: []byte("a")}}})
+	assert.Nil(t, err)
+	assert.Len(t, res2.RecordIDs, 1)
+	assert.Equal(t, []string{res.StartID, res.LastID, res2.LastID}, lookup("a"))
+	assert.Len(t, lookup("b"), 1)
+	assert.Empty(t, lookup("c"))
+	upper, err := svc.GetRecordByHash(ctx, &solaris.GetRecordByHashRequest{LogID: log.ID, Hash: strings.ToUpper(hashOf("a"))})
+	assert.Nil(t, err)
+	assert.Equal(t, lookup("a"), upper.RecordIDs)
+
+	// the records removed from the log are not returned
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: log.ID, Records: []*solaris.Record{{Payload: []byte("c")}}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{res.LastID, res2.LastID}, lookup("a"))
+	assert.Len(t, lookup("c"), 1)
+	ids, err := lms.GetRecordIDsByHash(ctx, log.ID, hashOf("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{res.LastID, res2.LastID}, ids, "the trimmed records are removed from the index")
+
+	// the records removed from the middle of the log are not returned
+	n, err := svc.LogStorage.DeleteRecords(ctx, log.ID, res.LastID, res.LastID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, []string{res2.LastID}, lookup("a"))
+	ids, err = lms.GetRecordIDsByHash(ctx, log.ID, hashOf("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{res2.LastID}, ids)
+
+	_, err = svc.GetRecordByHash(ctx, &solaris.GetRecordByHashRequest{LogID: plain.ID, Hash: hashOf("a")})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	_, err = svc.GetRecordByHash(ctx, &solaris.GetRecordByHashRequest{LogID: log.ID})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+
+	// the index is not available
+	svc.RecordHashes = nil
+	_, err = svc.UpdateLog(ctx, &solaris.Log{ID: plain.ID, PayloadHash: "fnv64a"})
+	assert.True(t, errors.Is(err, errors.ErrUnimplemented))
+}
+
+func TestService_AppendRecordsStream(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestService_AppendRecordsStream")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+
+	l1, err := lms.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+	l2, err := lms.CreateLog(ctx, &solaris.Log{ValidateUTF8: true})
+	assert.Nil(t, err)
+	recs := func(ps ...string) []*solaris.Record {
+		var res []*solaris.Record
+		for _, p := range ps {
+			res = append(res, &solaris.Record{Payload: []byte(p)})
+		}
+		return res
+	}
+	count := func(logID string) int {
+		res, err := svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{logID}, Limit: 100})
+		assert.Nil(t, err)
+		return len(res.Records)
+	}
+
+	// the batches to different logs are accumulated
+	ts := &testAppendStream{reqs: []*solaris.AppendRecordsRequest{
+		{LogID: l1.ID, Records: recs("a", "bb"), ExpandIDs: true},
+		{LogID: l2.ID, Records: recs("c")},
+		{LogID: l1.ID, Records: recs("d")},
+	}}
+	assert.Nil(t, svc.AppendRecordsStream(ts))
+	assert.Equal(t, int64(4), ts.res.Added)
+	assert.Equal(t, int64(5), ts.res.BytesWritten)
+	assert.Len(t, ts.res.RecordIDs, 2)
+	assert.Equal(t, ts.res.RecordIDs[0], ts.res.StartID)
+	assert.True(t, ts.res.StartID < ts.res.LastID)
+	assert.False(t, ts.res.Partial)
+	assert.Equal(t, 3, count(l1.ID))
+	assert.Equal(t, 1, count(l2.ID))
+
+	// the failed batch is reported, the previous ones stay appended
+	ts = &testAppendStream{reqs: []*solaris.AppendRecordsRequest{
+		{LogID: l1.ID, Records: recs("e")},
+		{LogID: l2.ID, Records: []*solaris.Record{{Payload: []byte{0xff}}}},
+		{LogID: l1.ID, Records: recs("f")},
+	}}
+	err = svc.AppendRecordsStream(ts)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), fmt.Sprintf("batch 1 to logID=%s, 1 records", l2.ID))
+	assert.Nil(t, ts.res)
+	assert.Equal(t, 4, count(l1.ID))
+	assert.Equal(t, 1, count(l2.ID))
+
+	// the empty stream
+	ts = &testAppendStream{}
+	assert.Nil(t, svc.AppendRecordsStream(ts))
+	assert.Equal(t, int64(0), ts.res.Added)
+}
+
+func TestService_GetLog(t *testing.T) {
+	svc := NewService(GetDefaultConfig())
+	svc.LogsStorage = &testLogs{logs: map[string]*solaris.Log{"l1": {ID: "l1", Tags: map[string]string{"a": "b"}}}}
+
+	log, err := svc.GetLog(context.Background(), &solaris.GetLogRequest{ID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "l1", log.ID)
+	assert.Equal(t, map[string]string{"a": "b"}, log.Tags)
+
+	_, err = svc.GetLog(context.Background(), &solaris.GetLogRequest{ID: "l2"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = svc.GetLog(context.Background(), &solaris.GetLogRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestService_CreateLogs(t *testing.T) {
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "TestService_CreateLogs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+
+	res, err := svc.CreateLogs(ctx, &solaris.CreateLogsRequest{Logs: []*solaris.Log{{ID: "l1"}, {Tags: map[string]string{"a": "b"}}}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res.Logs))
+	assert.Equal(t, "l1", res.Logs[0].ID)
+	_, err = lms.GetLogByID(ctx, res.Logs[1].ID)
+	assert.Nil(t, err)
+
+	// the duplicate ID fails the whole request
+	_, err = svc.CreateLogs(ctx, &solaris.CreateLogsRequest{Logs: []*solaris.Log{{ID: "l2"}, {ID: "l1"}}})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = lms.GetLogByID(ctx, "l2")
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+
+	_, err = svc.CreateLogs(ctx, &solaris.CreateLogsRequest{Logs: []*solaris.Log{{ID: "l3", PayloadHash: "md5"}}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestService_LogRetention(t *testing.T) {
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "TestService_LogRetention")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	svc, _, closeF := newTestLocalService(t, dir)
+	defer closeF()
+
+	_, err = svc.CreateLog(ctx, &solaris.Log{RetentionMaxAge: durationpb.New(-time.Hour)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	log, err := svc.CreateLog(ctx, &solaris.Log{RetentionMaxAge: durationpb.New(time.Hour)})
+	assert.Nil(t, err)
+	_, err = svc.UpdateLog(ctx, &solaris.Log{ID: log.ID, RetentionMaxRecords: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the zero policy means keep forever
+	_, err = svc.UpdateLog(ctx, &solaris.Log{ID: log.ID, RetentionMaxRecords: 10})
+	assert.Nil(t, err)
+	log, err = svc.GetLog(ctx, &solaris.GetLogRequest{ID: log.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), log.RetentionMaxAge.AsDuration())
+	assert.Equal(t, int64(10), log.RetentionMaxRecords)
+}
+
+func TestService_DeleteLogsByIDs(t *testing.T) {
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "TestService_DeleteLogsByIDs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+	l1, err := lms.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"a": "b"}})
+	assert.Nil(t, err)
+	l2, err := lms.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"a": "b"}})
+	assert.Nil(t, err)
+
+	// the condition is disregarded, the unknown IDs are skipped
+	res, err := svc.DeleteLogs(ctx, &solaris.DeleteLogsRequest{LogIDs: []string{l1.ID, "unknown"}, Condition: "tag('a') = 'b'", HardDelete: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{l1.ID}, res.DeletedIDs)
+
+	// the request may be repeated
+	res, err = svc.DeleteLogs(ctx, &solaris.DeleteLogsRequest{LogIDs: []string{l1.ID, l2.ID}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{l2.ID}, res.DeletedIDs)
+	res, err = svc.DeleteLogs(ctx, &solaris.DeleteLogsRequest{LogIDs: []string{l1.ID, l2.ID}})
+	assert.Nil(t, err)
+	assert.Empty(t, res.DeletedIDs)
+
+	_, err = lms.GetLogByID(ctx, l2.ID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+}
+
+func TestService_AppendRecordsChecksum(t *testing.T) {
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "TestService_AppendRecordsChecksum")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	svc, lms, closeF := newTestLocalService(t, dir)
+	defer closeF()
+	l, err := lms.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+
+	recs := []*solaris.Record{{Payload: []byte("ab")}, {Payload: []byte("c")}}
+	h := sha256.New()
+	h.Write([]byte{0, 0, 0, 0, 0, 0, 0, 2, 'a', 'b', 0, 0, 0, 0, 0, 0, 0, 1, 'c'})
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	// the corrupted batch is rejected before writing
+	corrupted := []*solaris.Record{{Payload: []byte("a")}, {Payload: []byte("bc")}}
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: l.ID, Records: corrupted, ChecksumAlgorithm: "sha256", Checksum: sum})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: l.ID, Records: recs, ChecksumAlgorithm: "md5", Checksum: sum})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: l.ID, Records: recs, ChecksumAlgorithm: "sha256"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	cnt, err := svc.CountRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{l.ID}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), cnt.Count)
+
+	res, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: l.ID, Records: recs, ChecksumAlgorithm: "sha256", Checksum: strings.ToUpper(sum)})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), res.Added)
+}
+
+func TestService_ReadOnly(t *testing.T) {
+	cfg := GetDefaultConfig()
+	cfg.ReadOnly = true
+	svc := NewService(cfg)
+	tl := &testLogs{logs: map[string]*solaris.Log{"l1": {ID: "l1"}}}
+	svc.LogsStorage = tl
+	svc.LogStorage = storage.NewLogHelper()
+	ctx := context.Background()
+	recs := []*solaris.Record{{Payload: []byte("hello")}}
+
+	// the mutations are rejected
+	_, err := svc.CreateLog(ctx, &solaris.Log{ID: "l2"})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrConflict))
+	_, err = svc.UpdateLog(ctx, &solaris.Log{ID: "l1"})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrConflict))
+	_, err = svc.DeleteLogs(ctx, &solaris.DeleteLogsRequest{Condition: "tag('a') = 'b'"})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrConflict))
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: "l1", Records: recs})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrConflict))
+	assert.Len(t, tl.logs, 1)
+
+	// the reads are served
+	res, err := svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{"l1"}})
+	assert.Nil(t, err)
+	assert.Empty(t, res.Records)
+	cnt, err := svc.CountRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{"l1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), cnt.Total)
+
+	// the writes are restored
+	ro, err := svc.SetReadOnly(ctx, &solaris.SetReadOnlyRequest{ReadOnly: false})
+	assert.Nil(t, err)
+	assert.True(t, ro.WasReadOnly)
+	_, err = svc.CreateLog(ctx, &solaris.Log{ID: "l2"})
+	assert.Nil(t, err)
+	ar, err := svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: "l1", Records: recs})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), ar.Added)
+
+	ro, err = svc.SetReadOnly(ctx, &solaris.SetReadOnlyRequest{ReadOnly: true})
+	assert.Nil(t, err)
+	assert.False(t, ro.WasReadOnly)
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: "l1", Records: recs})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrConflict))
+}
+
+func TestService_QueryRecordsAsAny(t *testing.T) {
+	ts := timestamppb.New(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	payload, err := proto.Marshal(ts)
+	assert.Nil(t, err)
+	typeURL := "type.googleapis.com/google.protobuf.Timestamp"
+
+	svc := NewService(GetDefaultConfig())
+	svc.LogsStorage = &testLogs{logs: map[string]*solaris.Log{"typed": {ID: "typed", PayloadTypeURL: typeURL}, "raw": {ID: "raw"}}}
+	svc.LogStorage = storage.NewLogHelper()
+	ctx := context.Background()
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: "typed", Records: []*solaris.Record{{Payload: payload}}})
+	assert.Nil(t, err)
+	_, err = svc.AppendRecords(ctx, &solaris.AppendRecordsRequest{LogID: "raw", Records: []*solaris.Record{{Payload: []byte("raw")}}})
+	assert.Nil(t, err)
+
+	res, err := svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{"typed"}, Limit: 10, AsAny: true})
+	assert.Nil(t, err)
+	if !assert.Equal(t, 1, len(res.Records)) {
+		return
+	}
+	r := res.Records[0]
+	assert.Nil(t, r.Payload)
+	if !assert.NotNil(t, r.Any) {
+		return
+	}
+	assert.Equal(t, typeURL, r.Any.TypeUrl)
+	var ts2 timestamppb.Timestamp
+	assert.Nil(t, r.Any.UnmarshalTo(&ts2))
+	assert.True(t, proto.Equal(ts, &ts2))
+
+	// the records of the logs without the type URL are returned as is
+	res, err = svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{"typed", "raw"}, Limit: 10, AsAny: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res.Records))
+	for _, r := range res.Records {
+		if r.LogID == "raw" {
+			assert.Nil(t, r.Any)
+			assert.Equal(t, []byte("raw"), r.Payload)
+		} else {
+			assert.Equal(t, typeURL, r.Any.GetTypeUrl())
+		}
+	}
+
+	// the option is not requested
+	res, err = svc.QueryRecords(ctx, &solaris.QueryRecordsRequest{LogIDs: []string{"typed"}, Limit: 10})
+	assert.Nil(t, err)
+	assert.Nil(t, res.Records[0].Any)
+	assert.Equal(t, payload, res.Records[0].Payload)
+}
+
+func TestService_QueryRecordsWithAge(t *testing.T) {
+	ls := storage.NewLogHelper()
+	cfg := GetDefaultConfig()
+	cfg.CheckLogsExist = false
+	svc := NewService(cfg)
+	svc.LogStorage = ls
+	for i := 0; i < 4; i++ {
+		ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: []*solaris.Record{{Payload: []byte("a")}}, LogID: fmt.Sprint(i)})
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"0", "1", "2", "3"}, Limit: 10})
+	assert.Nil(t, err)
+	assert.Len(t, res.Records, 4)
+	for _, r := range res.Records {
+		assert.Equal(t, int64(0), r.AgeMs)
+	}
+
+	for _, logIDs := range [][]string{{"0"}, {"0", "1", "2", "3"}} {
+		start := time.Now()
+		res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Limit: 10, WithAge: true})
+		end := time.Now()
+		assert.Nil(t, err)
+		for i, r := range res.Records {
+			id, err := ulid.Parse(r.ID)
+			assert.Nil(t, err)
+			ts := ulid.Time(id.Time())
+			assert.True(t, start.Sub(ts).Milliseconds() <= r.AgeMs && r.AgeMs <= end.Sub(ts).Milliseconds())
+			if i > 0 {
+				assert.True(t, r.AgeMs < res.Records[i-1].AgeMs)
+			}
+		}
+	}
+}
+
+func TestService_CheckLogsExist(t *testing.T) {
+	svc := NewService(GetDefaultConfig())
+	svc.LogsStorage = &testLogs{logs: map[string]*solaris.Log{"empty": {ID: "empty"}, "full": {ID: "full"}}}
+	ls := storage.NewLogHelper()
+	ls.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: "full", Records: []*solaris.Record{{Payload: []byte("a")}}})
+	svc.LogStorage = ls
+
+	res, err := svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"empty"}, Limit: 10})
+	assert.Nil(t, err)
+	assert.Empty(t, res.Records)
+	cnt, err := svc.CountRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"empty"}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), cnt.Total)
+
+	for _, logIDs := range [][]string{{"missing"}, {"full", "missing"}} {
+		_, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs, Limit: 10})
+		assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrNotExist))
+		_, err = svc.CountRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: logIDs})
+		assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrNotExist))
+	}
+
+	// the logs are checked by one request
+	tl := svc.LogsStorage.(*testLogs)
+	tl.queries = 0
+	res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"full", "empty"}, Limit: 10})
+	assert.Nil(t, err)
+	assert.Len(t, res.Records, 1)
+	assert.Equal(t, 1, tl.queries)
+
+	// the missing logs are read as the empty ones if the check is disabled
+	svc.cfg.CheckLogsExist = false
+	res, err = svc.QueryRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"full", "missing"}, Limit: 10})
+	assert.Nil(t, err)
+	assert.Len(t, res.Records, 1)
+	cnt, err = svc.CountRecords(context.Background(), &solaris.QueryRecordsRequest{LogIDs: []string{"missing"}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), cnt.Total)
+}
+
+func TestService_FieldStats(t *testing.T) {
+	cfg := GetDefaultConfig()
+	cfg.MaxFieldStatsSample = 50
+	svc := NewService(cfg)
+	svc.LogsStorage = &testLogs{logs: map[string]*solaris.Log{"l1": {ID: "l1"}}}
+	ql := &queriedLog{Log: storage.NewLogHelper()}
+	svc.LogStorage = ql
+
+	var recs []*solaris.Record
+	for i := 0; i < 100; i++ {
+		recs = append(recs, &solaris.Record{Payload: []byte(fmt.Sprintf(`{"idx": %d}`, i))})
+	}
+	_, err := ql.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{LogID: "l1", Records: recs})
+	assert.Nil(t, err)
+
+	res, err := svc.FieldStats(context.Background(), &solaris.FieldStatsRequest{LogID: "l1", SampleSize: 20, TopN: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), res.SampleSize)
+	assert.Len(t, res.Fields, 1)
+	assert.Equal(t, int64(20), res.Fields[0].Cardinality)
+	assert.Len(t, res.Fields[0].TopValues, 2)
+	assert.True(t, ql.reqs[0].Descending)
+
+	// the sample is limited by the config
+	res, err = svc.FieldStats(context.Background(), &solaris.FieldStatsRequest{LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50), res.SampleSize)
+
+	// the topN is limited by the config
+	_, err = svc.FieldStats(context.Background(), &solaris.FieldStatsRequest{LogID: "l1", TopN: int64(cfg.MaxFieldStatsTopN) + 1})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+
+	_, err = svc.FieldStats(context.Background(), &solaris.FieldStatsRequest{LogID: "missing"})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrNotExist))
+	_, err = svc.FieldStats(context.Background(), &solaris.FieldStatsRequest{})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrInvalid))
+}
+
+func TestSplitByPayloadSize(t *testing.T) {
+	recs := []*solaris.Record{{Payload: make([]byte, 30)}, {Payload: make([]byte, 70)}, {Payload: make([]byte, 150)},
+		{Payload: make([]byte, 10)}, {Payload: make([]byte, 95)}}
+	res := splitByPayloadSize(recs, 100)
+	assert.Equal(t, [][]*solaris.Record{recs[0:2], recs[2:3], recs[3:4], recs[4:5]}, res)
+	assert.Nil(t, splitByPayloadSize(nil, 100))
+}
+
+// testStream collects the messages sent to the stream
+type testStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []*solaris.QueryRecordsResult
+	// failAfter is the number of the messages sent before the stream is broken, zero means it is never broken
+	failAfter int
+}
+
+func (ts *testStream) Send(m *solaris.QueryRecordsResult) error {
+	if ts.failAfter > 0 && len(ts.msgs) == ts.failAfter {
+		return io.ErrClosedPipe
+	}
+	ts.msgs = append(ts.msgs, m)
+	return nil
+}
+
+func (ts *testStream) Context() context.Context {
+	return ts.ctx
+}
+
+// testAppendStream returns the requests one by one and keeps the result sent
+type testAppendStream struct {
+	grpc.ServerStream
+	reqs []*solaris.AppendRecordsRequest
+	res  *solaris.AppendRecordsResult
+}
+
+func (ts *testAppendStream) Recv() (*solaris.AppendRecordsRequest, error) {
+	if len(ts.reqs) == 0 {
+		return nil, io.EOF
+	}
+	r := ts.reqs[0]
+	ts.reqs = ts.reqs[1:]
+	return r, nil
+}
+
+func (ts *testAppendStream) SendAndClose(res *solaris.AppendRecordsResult) error {
+	ts.res = res
+	return nil
+}
+
+func (ts *testAppendStream) Context() context.Context {
+	return context.Background()
+}
+
+// queriedLog keeps the requests the records are queried with
+type queriedLog struct {
+	storage.Log
+	reqs []storage.QueryRecordsRequest
+	// opened and closed are the numbers of the records iterators opened and closed
+	opened int
+	closed int
+}
+
+func (l *queriedLog) QueryRecords(ctx context.Context, request storage.QueryRecordsRequest) ([]*solaris.Record, bool, error) {
+	l.reqs = append(l.reqs, request)
+	return l.Log.QueryRecords(ctx, request)
+}
+
+func (l *queriedLog) OpenRecordIterator(ctx context.Context, request storage.QueryRecordsRequest) (storage.RecordIterator, error) {
+	l.reqs = append(l.reqs, request)
+	it, err := l.Log.OpenRecordIterator(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	l.opened++
+	return &closedIterator{RecordIterator: it, closed: &l.closed}, nil
+}
+
+// closedIterator counts the iterator Close calls
+type closedIterator struct {
+	storage.RecordIterator
+	closed *int
+}
+
+func (it *closedIterator) Close() {
+	*it.closed++
+	it.RecordIterator.Close()
+}
+
+// cancelingLog cancels the request after the left number of the records queries, and the
+// next query is blocked until its context is closed
+type cancelingLog struct {
+	storage.Log
+	cancel context.CancelFunc
+	left   int
+	calls  int
+}
+
+func (l *cancelingLog) QueryRecords(ctx context.Context, request storage.QueryRecordsRequest) ([]*solaris.Record, bool, error) {
+	l.calls++
+	if l.left == 0 {
+		l.cancel()
+		<-ctx.Done()
+		return nil, false, ctx.Err()
+	}
+	l.left--
+	return l.Log.QueryRecords(ctx, request)
+}
+
+// testLogs returns the logs by their IDs
+type testLogs struct {
+	storage.Logs
+	logs map[string]*solaris.Log
+	// queries is the number of the QueryLogs calls
+	queries int
+}
+
+func (tl *testLogs) QueryLogs(ctx context.Context, qr storage.QueryLogsRequest) (*solaris.QueryLogsResult, error) {
+	tl.queries++
+	ids := slices.Clone(qr.IDs)
+	slices.Sort(ids)
+	res := &solaris.QueryLogsResult{}
+	for _, id := range slices.Compact(ids) {
+		if l, ok := tl.logs[id]; ok && id > qr.Page {
+			res.Logs = append(res.Logs, l)
+		}
+	}
+	return res, nil
+}
+
+func (tl *testLogs) GetLogByID(ctx context.Context, id string) (*solaris.Log, error) {
+	if l, ok := tl.logs[id]; ok {
+		return l, nil
+	}
+	return nil, errors.ErrNotExist
+}
+
+func (tl *testLogs) CreateLog(ctx context.Context, log *solaris.Log) (*solaris.Log, error) {
+	tl.logs[log.ID] = log
+	return log, nil
+}
+
+// countingLogs counts the QueryLogs calls
+type countingLogs struct {
+	storage.Logs
+	queries int
+}
+
+func (cl *countingLogs) QueryLogs(ctx context.Context, qr storage.QueryLogsRequest) (*solaris.QueryLogsResult, error) {
+	cl.queries++
+	return &solaris.QueryLogsResult{}, nil
+}
+
+func TestService_GetStorageLayout(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestService_GetStorageLayout")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	svc := NewService(GetDefaultConfig())
+	_, err = svc.GetStorageLayout(ctx, &solaris.GetStorageLayoutRequest{})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrUnimplemented))
+
+	p := chunkfs.NewProvider(dir, 10, chunkfs.GetDefaultConfig())
+	p.CA = chunkfs.NewChunkAccessor()
+	p.Replicator = chunkfs.NewReplicator(p.GetFileNameByID, chunkfs.GetDefaultReplicatorConfig())
+	p.Replicator.CA = p.CA
+	p.Replicator.Storage = inmem.NewStorage()
+	defer p.Close()
+	svc.ChnkProvider = p
+
+	rc, err := p.GetOpenedChunk(ctx, ulidutils.NewID(), true)
+	assert.Nil(t, err)
+	_, err = rc.Value().AppendRecords([]*solaris.Record{{Payload: []byte("hello")}})
+	assert.Nil(t, err)
+	p.ReleaseChunk(&rc)
+	// the chunk written in the first format version (without the records checksums)
+	fn := p.GetFileNameByID(ulidutils.NewID())
+	assert.Nil(t, files.EnsureFileExists(fn))
+	hdr := make([]byte, files.BlockSize)
+	copy(hdr, []byte{'S', 'O', 'L', 'A', 'R', 'I', 'S', 1})
+	assert.Nil(t, os.WriteFile(fn, hdr, 0640))
+
+	res, err := svc.GetStorageLayout(ctx, &solaris.GetStorageLayoutRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(chunkfs.CurrentVersion()), res.CurrentVersion)
+	assert.Len(t, res.Versions, 2)
+	assert.Equal(t, int32(1), res.Versions[0].Version)
+	assert.Equal(t, int64(1), res.Versions[0].Chunks)
+	assert.Equal(t, int64(files.BlockSize), res.Versions[0].Size)
+	assert.Equal(t, int32(chunkfs.CurrentVersion()), res.Versions[1].Version)
+	assert.Equal(t, int64(1), res.Versions[1].Chunks)
+	assert.Nil(t, res.Migration)
+
+	svc.Migrator = logfs.NewMigrator(logfs.GetDefaultMigratorConfig())
+	res, err = svc.GetStorageLayout(ctx, &solaris.GetStorageLayoutRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, &solaris.FormatMigration{}, res.Migration)
+}
+
+type testLogKeys struct {
+	rotated []string
+}
+
+func (tk *testLogKeys) RotateLogKey(_ context.Context, logID string) (string, error) {
+	tk.rotated = append(tk.rotated, logID)
+	return fmt.Sprintf("k%d", len(tk.rotated)), nil
+}
+
+func TestService_RotateLogKey(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(GetDefaultConfig())
+	svc.LogsStorage = &testLogs{logs: map[string]*solaris.Log{"l1": {ID: "l1"}}}
+	_, err := svc.RotateLogKey(ctx, &solaris.RotateLogKeyRequest{LogID: "l1"})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrUnimplemented))
+
+	tk := &testLogKeys{}
+	svc.LogKeys = tk
+	res, err := svc.RotateLogKey(ctx, &solaris.RotateLogKeyRequest{LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "k1", res.KeyID)
+	_, err = svc.RotateLogKey(ctx, &solaris.RotateLogKeyRequest{LogID: "l2"})
+	assert.True(t, errors.Is(errors.FromGRPCError(err), errors.ErrNotExist))
+	assert.Equal(t, []string{"l1"}, tk.rotated)
+}
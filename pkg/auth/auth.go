@@ -0,0 +1,221 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth contains the requests authentication by the bearer tokens. The tokens are verified
+// by an Authenticator, and the authenticated Principal is attached to the request context.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// Principal describes the authenticated client
+	Principal struct {
+		// ID identifies the client
+		ID string
+		// Claims contains the client attributes provided by the Authenticator, if any
+		Claims map[string]string
+	}
+
+	// Authenticator verifies the bearer tokens
+	Authenticator interface {
+		// Authenticate returns the Principal the token is issued to, or the errors.ErrNotAuthorized
+		// error if the token is not valid
+		Authenticate(ctx context.Context, token string) (Principal, error)
+	}
+
+	// Interceptor authenticates the gRPC and the HTTP requests by the tokens provided in
+	// the "authorization: Bearer <token>" metadata (header)
+	Interceptor struct {
+		a      Authenticator
+		exempt []string
+	}
+
+	// staticTokens is the Authenticator with the fixed set of tokens
+	staticTokens map[string]string
+
+	// jwtAuthenticator verifies the JWT tokens by the verify hook
+	jwtAuthenticator struct {
+		verify JWTVerifier
+	}
+
+	// JWTVerifier checks the JWT token signature, expiration etc. and returns the token claims. It is the hook
+	// for the JWT libraries, so the server is not bound to any of them.
+	JWTVerifier func(ctx context.Context, token string) (map[string]string, error)
+
+	principalKey struct{}
+)
+
+// HealthMethods is the prefix of the gRPC health service methods, which are usually exempted
+// from the authentication, so the orchestrator could check the server without the token
+const HealthMethods = "/grpc.health.v1.Health/"
+
+// NewInterceptor returns the Interceptor, which authenticates the requests by a. The methods (the HTTP paths)
+// starting from any of the exempt prefixes are served without the authentication.
+func NewInterceptor(a Authenticator, exempt ...string) *Interceptor {
+	return &Interceptor{a: a, exempt: exempt}
+}
+
+// NewStaticAuthenticator returns the Authenticator, which accepts the tokens provided only. The tokens map
+// contains the principals IDs by their tokens.
+func NewStaticAuthenticator(tokens map[string]string) Authenticator {
+	return staticTokens(tokens)
+}
+
+// NewJWTAuthenticator returns the Authenticator, which verifies the JWT tokens by verify. The principal ID
+// is taken from the token "sub" claim.
+func NewJWTAuthenticator(verify JWTVerifier) Authenticator {
+	return &jwtAuthenticator{verify: verify}
+}
+
+// WithPrincipal returns the context with the principal attached
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal attached to the ctx, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Unary implements grpc.UnaryServerInterceptor
+func (i *Interceptor) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if i.exempted(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	ctx, err := i.authenticate(ctx, tokenFromMetadata(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream implements grpc.StreamServerInterceptor
+func (i *Interceptor) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if i.exempted(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	ctx, err := i.authenticate(ss.Context(), tokenFromMetadata(ss.Context()))
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// HTTP returns the gin handler, which authenticates the HTTP requests by the Authorization header.
+// The principal is attached to the request context.
+func (i *Interceptor) HTTP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if i.exempted(c.Request.URL.Path) {
+			return
+		}
+		ctx, err := i.authenticate(c.Request.Context(), bearerToken(c.GetHeader("Authorization")))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errors.FromGRPCErrorMsg(err)})
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+	}
+}
+
+func (i *Interceptor) exempted(method string) bool {
+	for _, e := range i.exempt {
+		if strings.HasPrefix(method, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate returns the ctx with the principal of the token, or the Unauthenticated gRPC error
+func (i *Interceptor) authenticate(ctx context.Context, token string) (context.Context, error) {
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "the bearer token is not provided")
+	}
+	p, err := i.a.Authenticate(ctx, token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "the request is not authenticated: %v", err)
+	}
+	return WithPrincipal(ctx, p), nil
+}
+
+func tokenFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if token := bearerToken(v); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+func bearerToken(v string) string {
+	const prefix = "bearer "
+	if len(v) <= len(prefix) || !strings.EqualFold(v[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(v[len(prefix):])
+}
+
+// authStream overrides the stream context by the one with the principal
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream
+func (as *authStream) Context() context.Context {
+	return as.ctx
+}
+
+// Authenticate implements Authenticator. All the tokens are compared in the constant time,
+// so the time doesn't disclose a valid token.
+func (st staticTokens) Authenticate(_ context.Context, token string) (Principal, error) {
+	var id string
+	found := false
+	for t, pid := range st {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			id, found = pid, true
+		}
+	}
+	if !found {
+		return Principal{}, fmt.Errorf("unknown token: %w", errors.ErrNotAuthorized)
+	}
+	return Principal{ID: id}, nil
+}
+
+// Authenticate implements Authenticator
+func (ja *jwtAuthenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	claims, err := ja.verify(ctx, token)
+	if err != nil {
+		return Principal{}, fmt.Errorf("the token is not valid: %v: %w", err, errors.ErrNotAuthorized)
+	}
+	if claims["sub"] == "" {
+		return Principal{}, fmt.Errorf("the token subject is not specified: %w", errors.ErrNotAuthorized)
+	}
+	return Principal{ID: claims["sub"], Claims: claims}, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestInterceptor_Unary(t *testing.T) {
+	ai := NewInterceptor(NewStaticAuthenticator(map[string]string{"t1": "client1"}), HealthMethods)
+	var principal Principal
+	handler := func(ctx context.Context, req any) (any, error) {
+		principal, _ = PrincipalFromContext(ctx)
+		return "ok", nil
+	}
+	call := func(method string, md ...string) (any, error) {
+		principal = Principal{}
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(md...))
+		return ai.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	res, err := call("/solaris.v1.Service/QueryRecords", "authorization", "Bearer t1")
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", res)
+	assert.Equal(t, "client1", principal.ID)
+
+	for _, md := range [][]string{nil, {"authorization", "Bearer t2"}, {"authorization", "t1"}, {"authorization", "Basic t1"}} {
+		_, err = call("/solaris.v1.Service/QueryRecords", md...)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), md)
+		assert.Equal(t, "", principal.ID)
+	}
+
+	// the health checks are exempted
+	res, err = call(HealthMethods + "Check")
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", res)
+}
+
+func TestInterceptor_Stream(t *testing.T) {
+	ai := NewInterceptor(NewStaticAuthenticator(map[string]string{"t1": "client1"}))
+	var principal Principal
+	handler := func(srv any, ss grpc.ServerStream) error {
+		principal, _ = PrincipalFromContext(ss.Context())
+		return nil
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/solaris.v1.Service/StreamRecords"}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer t1"))
+	assert.Nil(t, ai.Stream(nil, &testStream{ctx: ctx}, info, handler))
+	assert.Equal(t, "client1", principal.ID)
+
+	principal = Principal{}
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer t2"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(ai.Stream(nil, &testStream{ctx: ctx}, info, handler)))
+	assert.Equal(t, "", principal.ID)
+}
+
+func TestInterceptor_HTTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ai := NewInterceptor(NewStaticAuthenticator(map[string]string{"t1": "client1"}), "/healthz")
+	g := gin.New()
+	g.Use(ai.HTTP())
+	g.GET("/logs", func(c *gin.Context) {
+		p, _ := PrincipalFromContext(c.Request.Context())
+		c.String(http.StatusOK, p.ID)
+	})
+	g.GET("/healthz", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	call := func(path, header string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		g.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call("/logs", "Bearer t1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "client1", w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, call("/logs", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("/logs", "Bearer t2").Code)
+	assert.Equal(t, http.StatusOK, call("/healthz", "").Code)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	ja := NewJWTAuthenticator(func(ctx context.Context, token string) (map[string]string, error) {
+		switch token {
+		case "valid":
+			return map[string]string{"sub": "client1", "scope": "read"}, nil
+		case "nosub":
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("bad signature")
+	})
+	p, err := ja.Authenticate(context.Background(), "valid")
+	assert.Nil(t, err)
+	assert.Equal(t, Principal{ID: "client1", Claims: map[string]string{"sub": "client1", "scope": "read"}}, p)
+
+	_, err = ja.Authenticate(context.Background(), "nosub")
+	assert.True(t, errors.Is(err, errors.ErrNotAuthorized))
+	_, err = ja.Authenticate(context.Background(), "expired")
+	assert.True(t, errors.Is(err, errors.ErrNotAuthorized))
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ts *testStream) Context() context.Context {
+	return ts.ctx
+}
@@ -2,9 +2,20 @@ package db
 
 import "fmt"
 
+const (
+	// DriverPostgres is the Postgres driver
+	DriverPostgres = "postgres"
+	// DriverBuntDB is the embedded BuntDB driver, which needs no database server
+	DriverBuntDB = "buntdb"
+	// DriverSqlite is the SQLite driver, the DBName is the database file path
+	DriverSqlite = "sqlite"
+)
+
 // DBConn represents database connection parameters
 type DBConn struct {
-	// Driver is the db driver (e.g. postgres)
+	// Driver is the db driver (e.g. postgres). The "buntdb" driver stores the data in
+	// the embedded storage, in the DBName file, or in memory if DBName is empty. The "sqlite"
+	// driver stores the data in the DBName SQLite database file.
 	Driver string
 	// Host is the host address where the db reside
 	Host string
@@ -21,10 +21,14 @@ import (
 	"github.com/solarisdb/solaris/golibs/logging"
 	"github.com/solarisdb/solaris/golibs/transport"
 	"net"
+	"os"
+	"os/signal"
 	"sync/atomic"
+	"syscall"
 
 	"github.com/logrange/linker"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -34,6 +38,9 @@ type Config struct {
 	Transport transport.Config
 	// RegisterEndpoints allows to add gRPC endpoints into the server
 	RegisterEndpoints RegisterF
+	// UnaryInterceptors and StreamInterceptors are called (in the order provided) for every request
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
 }
 
 // RegisterF is a function which allows to add endpoints into the server. It is called in Init
@@ -49,6 +56,8 @@ type Server struct {
 	listnr net.Listener
 	closed int32
 	logger logging.Logger
+	// sighup receives the SIGHUP signals the TLS certificate is reloaded by
+	sighup chan os.Signal
 }
 
 // NewServer creates a new instance of the Server
@@ -70,8 +79,23 @@ func (s *Server) Init(ctx context.Context) error {
 		return fmt.Errorf("could not start listener for %v, err=%w", s.cfg.Transport, err)
 	}
 
+	var opts []grpc.ServerOption
+	if s.cfg.Transport.TLSEnabled() {
+		tc, cl, err := transport.NewServerTLSConfig(s.cfg.Transport)
+		if err != nil {
+			_ = lis.Close()
+			return fmt.Errorf("could not configure TLS: %w", err)
+		}
+		s.logger.Infof("TLS is enabled, the client auth is %q", s.cfg.Transport.TLSClientAuth)
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tc)))
+		s.sighup = make(chan os.Signal, 1)
+		signal.Notify(s.sighup, syscall.SIGHUP)
+		go s.reloadCert(cl)
+	}
+	opts = append(opts, grpc.ChainUnaryInterceptor(s.cfg.UnaryInterceptors...), grpc.ChainStreamInterceptor(s.cfg.StreamInterceptors...))
+
 	s.listnr = lis
-	gs := grpc.NewServer()
+	gs := grpc.NewServer(opts...)
 	err = s.cfg.RegisterEndpoints(gs)
 	if err != nil {
 		return fmt.Errorf("could not register endpoints: %w", err)
@@ -97,6 +121,21 @@ func (s *Server) Shutdown() {
 		atomic.StoreInt32(&s.closed, 1)
 		s.listnr.Close()
 	}
+	if s.sighup != nil {
+		signal.Stop(s.sighup)
+		close(s.sighup)
+	}
+}
+
+// reloadCert reloads the TLS certificate on every SIGHUP until the server is shut down
+func (s *Server) reloadCert(cl *transport.CertLoader) {
+	for range s.sighup {
+		if err := cl.Reload(); err != nil {
+			s.logger.Errorf("could not reload the TLS certificate, the previous one is used: %v", err)
+			continue
+		}
+		s.logger.Infof("the TLS certificate is reloaded")
+	}
 }
 
 // String implements fmt.Stringify
@@ -0,0 +1,139 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestServer_TLS(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestServer_TLS")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ca, caKey := newTestCert(t, dir, "ca", nil, nil)
+	newTestCert(t, dir, "server", ca, caKey)
+	newTestCert(t, dir, "client", ca, caKey)
+
+	tcfg := transport.Config{Network: "tcp", Address: "127.0.0.1", Port: freePort(t),
+		TLSCertFile: filepath.Join(dir, "server.crt"), TLSKeyFile: filepath.Join(dir, "server.key"),
+		TLSCAFile: filepath.Join(dir, "ca.crt"), TLSClientAuth: "require"}
+	s := NewServer(Config{Transport: tcfg, RegisterEndpoints: func(gs *grpc.Server) error {
+		grpc_health_v1.RegisterHealthServer(gs, health.NewServer())
+		return nil
+	}})
+	require.Nil(t, s.Init(context.Background()))
+	defer s.Shutdown()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	check := func(certs []tls.Certificate) error {
+		conn, err := grpc.Dial(tcfg.Addr(), grpc.WithTransportCredentials(credentials.NewTLS(
+			&tls.Config{RootCAs: roots, Certificates: certs, ServerName: "localhost"})))
+		require.Nil(t, err)
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	require.Nil(t, err)
+	assert.Nil(t, check([]tls.Certificate{cert}))
+	// the client certificate is required
+	assert.NotNil(t, check(nil))
+}
+
+func TestServer_TLSBadConfig(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestServer_TLSBadConfig")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	ca, caKey := newTestCert(t, dir, "ca", nil, nil)
+	newTestCert(t, dir, "server", ca, caKey)
+
+	for _, tcfg := range []transport.Config{
+		{TLSCertFile: filepath.Join(dir, "missing.crt"), TLSKeyFile: filepath.Join(dir, "server.key")},
+		{TLSCertFile: filepath.Join(dir, "server.crt")},
+		{TLSCertFile: filepath.Join(dir, "server.crt"), TLSKeyFile: filepath.Join(dir, "server.key"), TLSClientAuth: "require"},
+		{TLSCertFile: filepath.Join(dir, "server.crt"), TLSKeyFile: filepath.Join(dir, "server.key"), TLSClientAuth: "always"},
+	} {
+		tcfg.Network, tcfg.Address, tcfg.Port = "tcp", "127.0.0.1", freePort(t)
+		s := NewServer(Config{Transport: tcfg, RegisterEndpoints: func(gs *grpc.Server) error { return nil }})
+		err := s.Init(context.Background())
+		assert.True(t, errors.Is(err, errors.ErrInvalid), tcfg.String())
+		s.Shutdown()
+	}
+}
+
+// newTestCert writes the name.crt and name.key files of the certificate signed by the parent,
+// or the self-signed CA certificate if the parent is nil
+func newTestCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.Nil(t, err)
+	kder, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder}), 0600))
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	return cert, key
+}
+
+func freePort(t *testing.T) int {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port
+}
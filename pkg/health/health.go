@@ -0,0 +1,187 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health contains the liveness and readiness checks of the server, which are reported
+// by the standard gRPC health service and by the /healthz and /readyz HTTP endpoints.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/logrange/linker"
+	"github.com/solarisdb/solaris/golibs/logging"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type (
+	// Config defines settings for the Checker
+	Config struct {
+		// DataPath contains the path to the folder where the chunks are stored, it must be writable
+		// for the server to be ready
+		DataPath string
+		// CheckInterval defines how often the readiness is checked
+		CheckInterval time.Duration
+		// CheckTimeout limits the time of one readiness check
+		CheckTimeout time.Duration
+	}
+
+	// Pinger allows to check the database connection is reachable
+	Pinger interface {
+		PingContext(ctx context.Context) error
+	}
+
+	// Checker checks the server readiness periodically and reports it via the gRPC health service
+	// and the HTTP endpoints. The server is ready when the database is reachable and the data folder
+	// is writable. The liveness is not checked, the server is alive while it responds.
+	Checker struct {
+		logger logging.Logger
+		cfg    Config
+		db     Pinger
+		hs     *health.Server
+		// notReady contains the error of the last readiness check, if any
+		notReady atomic.Pointer[error]
+	}
+)
+
+var _ linker.Initializer = (*Checker)(nil)
+var _ linker.Shutdowner = (*Checker)(nil)
+var _ http.Handler = (*Checker)(nil)
+
+var errNotChecked = fmt.Errorf("the readiness is not checked yet")
+
+// GetDefaultConfig returns the default Config
+func GetDefaultConfig() Config {
+	return Config{
+		DataPath:      "slog",
+		CheckInterval: 5 * time.Second,
+		CheckTimeout:  2 * time.Second,
+	}
+}
+
+// NewChecker creates the new Checker, which pings the db. The server is not ready until
+// the first check is done.
+func NewChecker(cfg Config, db Pinger) *Checker {
+	c := &Checker{
+		logger: logging.NewLogger("health.Checker"),
+		cfg:    cfg,
+		db:     db,
+		hs:     health.NewServer(),
+	}
+	c.notReady.Store(&errNotChecked)
+	c.hs.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	return c
+}
+
+// String implements fmt.Stringer
+func (cfg Config) String() string {
+	b, _ := json.MarshalIndent(cfg, "", "  ")
+	return string(b)
+}
+
+// Init implements linker.Initializer
+func (c *Checker) Init(ctx context.Context) error {
+	c.logger.Infof("initializing cfg:\n%s", c.cfg)
+	c.check(ctx)
+	go c.watcher(ctx)
+	return nil
+}
+
+// Shutdown implements linker.Shutdowner. The gRPC health service reports NOT_SERVING after the call.
+func (c *Checker) Shutdown() {
+	c.logger.Infof("shutting down")
+	c.hs.Shutdown()
+}
+
+// HealthServer returns the gRPC health service to be registered in the gRPC server
+func (c *Checker) HealthServer() grpc_health_v1.HealthServer {
+	return c.hs
+}
+
+// Ready returns the error of the last readiness check, or nil if the server is ready
+func (c *Checker) Ready() error {
+	if err := c.notReady.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler. It serves /readyz by the last readiness check result,
+// and any other path (/healthz) as the liveness check.
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/readyz" {
+		if err := c.Ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+func (c *Checker) watcher(ctx context.Context) {
+	c.logger.Infof("starting watcher()")
+	defer c.logger.Infof("exiting from watcher()")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.cfg.CheckInterval):
+			c.check(ctx)
+		}
+	}
+}
+
+// check checks the readiness and updates the reported status if it is changed
+func (c *Checker) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.CheckTimeout)
+	defer cancel()
+	err := c.checkReady(ctx)
+	if err == nil {
+		if c.notReady.Swap(nil) != nil {
+			c.logger.Infof("the server is ready")
+			c.hs.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+		}
+		return
+	}
+	if prev := c.notReady.Swap(&err); prev == nil || (*prev).Error() != err.Error() {
+		c.logger.Warnf("the server is not ready: %v", err)
+	}
+	c.hs.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+func (c *Checker) checkReady(ctx context.Context) error {
+	if c.db != nil {
+		if err := c.db.PingContext(ctx); err != nil {
+			return fmt.Errorf("the database is not reachable: %w", err)
+		}
+	}
+	f, err := os.CreateTemp(c.cfg.DataPath, ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("the data folder %s is not writable: %w", c.cfg.DataPath, err)
+	}
+	_, err = f.Write([]byte("ok"))
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	if err != nil {
+		return fmt.Errorf("the data folder %s is not writable: %w", c.cfg.DataPath, err)
+	}
+	return nil
+}
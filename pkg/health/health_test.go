@@ -0,0 +1,90 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestChecker_Readiness(t *testing.T) {
+	dir := t.TempDir()
+	db := &testDB{}
+	cfg := GetDefaultConfig()
+	cfg.DataPath = dir
+	c := NewChecker(cfg, db)
+	assert.NotNil(t, c.Ready())
+	assertStatus(t, c, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	c.check(context.Background())
+	assert.Nil(t, c.Ready())
+	assertStatus(t, c, grpc_health_v1.HealthCheckResponse_SERVING)
+	assertHTTP(t, c, "/readyz", http.StatusOK)
+	// no files are left in the data folder
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+
+	// the database is not reachable, but the server is alive
+	db.err = errors.ErrInternal
+	c.check(context.Background())
+	assert.True(t, errors.Is(c.Ready(), errors.ErrInternal))
+	assertStatus(t, c, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	assertHTTP(t, c, "/readyz", http.StatusServiceUnavailable)
+	assertHTTP(t, c, "/healthz", http.StatusOK)
+
+	db.err = nil
+	c.check(context.Background())
+	assert.Nil(t, c.Ready())
+	assertStatus(t, c, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	// the data folder is not writable
+	c.cfg.DataPath = filepath.Join(dir, "missing")
+	c.check(context.Background())
+	assert.NotNil(t, c.Ready())
+	assertStatus(t, c, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	c.Shutdown()
+	assertStatus(t, c, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+func assertStatus(t *testing.T, c *Checker, exp grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	res, err := c.HealthServer().Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, exp, res.Status)
+}
+
+func assertHTTP(t *testing.T, c *Checker, path string, exp int) {
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, exp, w.Code)
+}
+
+// testDB returns err on ping
+type testDB struct {
+	err error
+}
+
+func (db *testDB) PingContext(ctx context.Context) error {
+	return db.err
+}
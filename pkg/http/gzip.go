@@ -0,0 +1,140 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"compress/gzip"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipResponseWriter compresses the response body, if it is not less than minSize bytes.
+// The body is buffered until its size reaches minSize, so the small responses are sent as is.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minSize int
+	buf     []byte
+	gz      *gzip.Writer
+	// plain is true if the response is decided to be sent not compressed
+	plain bool
+}
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// gzipHandler returns the middleware, which compresses the responses not less than minSize bytes
+// for the clients accepting the gzip content-encoding
+func gzipHandler(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+		c.Header("Vary", "Accept-Encoding")
+		gw := &gzipResponseWriter{ResponseWriter: c.Writer, minSize: minSize}
+		c.Writer = gw
+		defer func() {
+			gw.close()
+			c.Writer = gw.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// acceptsGzip returns true if the Accept-Encoding header value allows the gzip encoding
+func acceptsGzip(ae string) bool {
+	for _, e := range strings.Split(ae, ",") {
+		name, params, _ := strings.Cut(e, ";")
+		name = strings.TrimSpace(name)
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+		v, err := strconv.ParseFloat(q, 64)
+		return err == nil && v > 0
+	}
+	return false
+}
+
+// Write implements io.Writer
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.plain {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) < w.minSize {
+		return len(b), nil
+	}
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// WriteString implements io.StringWriter
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush implements http.Flusher, the buffered body is sent not compressed if the compression is not started yet
+func (w *gzipResponseWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	} else if !w.plain {
+		w.plain = true
+		_, _ = w.ResponseWriter.Write(w.buf)
+		w.buf = nil
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start writes the buffered body either compressed or not, depending on the response headers
+func (w *gzipResponseWriter) start() error {
+	buf := w.buf
+	w.buf = nil
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		w.plain = true
+		_, err := w.ResponseWriter.Write(buf)
+		return err
+	}
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.gz = gzipWriters.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+	_, err := w.gz.Write(buf)
+	return err
+}
+
+// close completes the response body
+func (w *gzipResponseWriter) close() {
+	if w.gz != nil {
+		_ = w.gz.Close()
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+		return
+	}
+	if !w.plain && len(w.buf) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGzipHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(gzipHandler(1024))
+	big := map[string]string{"payload": strings.Repeat("a", 10000)}
+	g.GET("/big", func(c *gin.Context) { c.JSON(http.StatusOK, big) })
+	g.GET("/small", func(c *gin.Context) { c.JSON(http.StatusOK, map[string]string{"payload": "a"}) })
+
+	get := func(path, ae string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ae != "" {
+			req.Header.Set("Accept-Encoding", ae)
+		}
+		g.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	// the big response is compressed
+	w := get("/big", "deflate, gzip;q=0.8")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.True(t, w.Body.Len() < 1000)
+	gr, err := gzip.NewReader(w.Body)
+	assert.Nil(t, err)
+	body, err := io.ReadAll(gr)
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), big["payload"])
+
+	// the small response is sent as is
+	w = get("/small", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, `{"payload":"a"}`, w.Body.String())
+
+	// the client doesn't accept gzip
+	for _, ae := range []string{"", "deflate", "gzip;q=0"} {
+		w = get("/big", ae)
+		assert.Empty(t, w.Header().Get("Content-Encoding"), ae)
+		assert.Contains(t, w.Body.String(), big["payload"], ae)
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	assert.True(t, acceptsGzip("gzip"))
+	assert.True(t, acceptsGzip("br, gzip"))
+	assert.True(t, acceptsGzip("gzip;q=0.5"))
+	assert.True(t, acceptsGzip("*"))
+	assert.False(t, acceptsGzip(""))
+	assert.False(t, acceptsGzip("identity"))
+	assert.False(t, acceptsGzip("gzip;q=0"))
+	assert.False(t, acceptsGzip("gzip; q=0.0"))
+}
@@ -32,6 +32,20 @@ type Config struct {
 	HttpPort int
 	// RestRegistrar is the endpoints registrar
 	RestRegistrar EndpointsRegistrar
+	// GzipResponses enables the gzip content-encoding of the responses for the clients accepting it
+	GzipResponses bool
+	// GzipMinSize defines the response size (in bytes), starting from which the response is compressed.
+	// The smaller responses are sent as is, cause their compression saves too little to spend CPU on it.
+	GzipMinSize int
+	// MetricsHandler serves the metrics on /metrics if it is provided
+	MetricsHandler http.Handler
+	// Auth authenticates the requests if it is provided
+	Auth gin.HandlerFunc
+	// Admission limits the number of the requests served at a time if it is provided. It is called
+	// after Auth, so the not authenticated requests don't wait for the admission.
+	Admission gin.HandlerFunc
+	// HealthHandler serves the liveness and readiness checks on /healthz and /readyz if it is provided
+	HealthHandler http.Handler
 }
 
 // EndpointsRegistrar is a component which provides a callback for registering REST endpoints in the Router server
@@ -66,6 +80,25 @@ func (r *Router) Init(_ context.Context) error {
 	r.r = gin.Default()
 	r.r.UseRawPath = true
 	r.r.UnescapePathValues = false
+	// the handlers pass the gin context to the API, so it must provide the request context values (the principal)
+	r.r.ContextWithFallback = true
+	if r.config.Auth != nil {
+		r.r.Use(r.config.Auth)
+	}
+	if r.config.Admission != nil {
+		r.r.Use(r.config.Admission)
+	}
+	if r.config.GzipResponses {
+		r.r.Use(gzipHandler(r.config.GzipMinSize))
+	}
+
+	if r.config.MetricsHandler != nil {
+		r.r.GET("/metrics", gin.WrapH(r.config.MetricsHandler))
+	}
+	if r.config.HealthHandler != nil {
+		r.r.GET("/healthz", gin.WrapH(r.config.HealthHandler))
+		r.r.GET("/readyz", gin.WrapH(r.config.HealthHandler))
+	}
 
 	if r.config.RestRegistrar == nil {
 		r.logger.Warnf("RestRegistrar is not provided, will register /ping only...")
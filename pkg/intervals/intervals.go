@@ -48,6 +48,9 @@ var (
 	// BasisInt is a default basis for type int
 	BasisInt = NewBasis(math.MinInt, math.MaxInt, cmp.Compare[int])
 
+	// BasisInt64 is a default basis for type int64
+	BasisInt64 = NewBasis(int64(math.MinInt64), int64(math.MaxInt64), cmp.Compare[int64])
+
 	// BasisString is a default basis for type string
 	BasisString = NewBasis("", string(utf8.MaxRune), cmp.Compare[string])
 
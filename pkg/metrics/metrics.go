@@ -0,0 +1,116 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics contains the Prometheus metrics of the Solaris internals. The metrics are
+// registered in the package registry once, so the instrumented code just updates them.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "solaris"
+
+var (
+	// Registry contains all the Solaris metrics and the Go runtime and the process metrics
+	Registry = prometheus.NewRegistry()
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_duration_seconds",
+		Help:      "The duration of the log records requests by the operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"op"})
+
+	// AppendDuration observes the AppendRecords duration in seconds
+	AppendDuration = requestDuration.WithLabelValues("append")
+	// QueryDuration observes the QueryRecords duration in seconds
+	QueryDuration = requestDuration.WithLabelValues("query")
+	// CountDuration observes the CountRecords duration in seconds
+	CountDuration = requestDuration.WithLabelValues("count")
+
+	// RecordsWritten counts the records appended to the logs
+	RecordsWritten = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "records_written_total",
+		Help:      "The number of records appended to the logs.",
+	})
+	// RecordsRead counts the records returned by the queries
+	RecordsRead = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "records_read_total",
+		Help:      "The number of records returned by the records queries.",
+	})
+	// ChunksOpened counts the chunks files opened
+	ChunksOpened = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chunks_opened_total",
+		Help:      "The number of chunks files opened.",
+	})
+
+	// DiskFreeBytes is the free space on the disk where the chunks are stored, as it was checked last time
+	DiskFreeBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "disk_free_bytes",
+		Help:      "The free space in bytes on the disk where the chunks are stored.",
+	})
+	// DiskDegraded is 1 when the appends are rejected, cause the free disk space is below the threshold, or 0 otherwise
+	DiskDegraded = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "disk_degraded",
+		Help:      "1 if the appends are rejected, cause the free disk space is below the threshold, 0 otherwise.",
+	})
+
+	chunkAccessWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "chunk_access_wait_seconds",
+		Help:      "The time spent waiting for the chunk access by the access mode.",
+		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, []string{"mode"})
+
+	// ChunkReadWait observes the time waiting for the chunk read access in seconds
+	ChunkReadWait = chunkAccessWait.WithLabelValues("read")
+	// ChunkWriteWait observes the time waiting for the chunk write access in seconds
+	ChunkWriteWait = chunkAccessWait.WithLabelValues("write")
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestDuration,
+		RecordsWritten,
+		RecordsRead,
+		ChunksOpened,
+		DiskFreeBytes,
+		DiskDegraded,
+		chunkAccessWait,
+	)
+}
+
+// Handler returns the http.Handler exposing the Registry metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveSince records the time passed since start in seconds by the observer provided.
+// It is intended to be deferred: defer metrics.ObserveSince(metrics.QueryDuration, time.Now())
+func ObserveSince(o prometheus.Observer, start time.Time) {
+	o.Observe(time.Since(start).Seconds())
+}
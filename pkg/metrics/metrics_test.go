@@ -0,0 +1,52 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveSince(AppendDuration, time.Now())
+	RecordsWritten.Add(3)
+	DiskFreeBytes.Set(1000)
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	for _, s := range []string{
+		`solaris_request_duration_seconds_count{op="append"} 1`,
+		`solaris_request_duration_seconds_count{op="query"} 0`,
+		`solaris_records_written_total 3`,
+		`solaris_records_read_total 0`,
+		`solaris_chunks_opened_total 0`,
+		`solaris_disk_free_bytes 1000`,
+		`solaris_disk_degraded 0`,
+		`solaris_chunk_access_wait_seconds_count{mode="read"} 0`,
+		`go_goroutines`,
+	} {
+		assert.Contains(t, string(body), s)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/errors"
+	"maps"
 	"strings"
 	"time"
 )
@@ -70,9 +71,10 @@ const (
 	VTTime    ValueType = 2
 	VTBool    ValueType = 3
 	VTStrings ValueType = 4
+	VTInt     ValueType = 5
 )
 
-var typeNames = []string{"unknown", "string", "time", "bool", "strings"}
+var typeNames = []string{"unknown", "string", "time", "bool", "strings", "int"}
 
 var (
 	LogsCondValueDialect = Dialect[*solaris.Log]{
@@ -102,19 +104,8 @@ var (
 			Type: VTString,
 		},
 		"tag": { // tag function is written the way -> 'tag("abc") in ["1", "2", "3"]' or 'tag("t1") = "aaa"'
-			Flags: PfLValue | PfComparable | PfRValue | PfInLike,
-			CheckF: func(p *Param) error {
-				if p.Function == nil {
-					return fmt.Errorf("tag must be a function: %w", errors.ErrInvalid)
-				}
-				if len(p.Function.Params) != 1 {
-					return fmt.Errorf("tag() function expects only one parameter - the name of the tag: %w", errors.ErrInvalid)
-				}
-				if p.Function.Params[0].ID() != StringParamID {
-					return fmt.Errorf("tag() function expects the tag name (string) as the parameter: %w", errors.ErrInvalid)
-				}
-				return nil
-			},
+			Flags:  PfLValue | PfComparable | PfRValue | PfInLike,
+			CheckF: checkTag,
 			ValueF: func(p *Param, log *solaris.Log) (any, error) {
 				if len(log.Tags) == 0 {
 					return "", nil
@@ -164,14 +155,8 @@ var (
 		"tag": { // tag function is written the way -> 'tag("abc") in ["1", "2", "3"]' or 'tag("t1") = "aaa"'
 			Flags: PfLValue | PfComparable | PfRValue | PfInLike,
 			TranslateF: func(tr Translator[*solaris.Log], sb *strings.Builder, p Param) error {
-				if p.Function == nil {
-					return fmt.Errorf("tag must be a function: %w", errors.ErrInvalid)
-				}
-				if len(p.Function.Params) != 1 {
-					return fmt.Errorf("tag() function expects only one parameter - the name of the tag: %w", errors.ErrInvalid)
-				}
-				if p.Function.Params[0].ID() != StringParamID {
-					return fmt.Errorf("tag() function expects the tag name (string) as the parameter: %w", errors.ErrInvalid)
+				if err := checkTag(&p); err != nil {
+					return err
 				}
 				sb.WriteString("tags ->> ")
 				_ = tr.Param2Sql(sb, p.Function.Params[0])
@@ -187,6 +172,17 @@ var (
 			},
 			Type: VTString,
 		},
+		ArrayParamID: { // arrays are rvalues only
+			Flags: PfRValue | PfConstValue,
+			ValueF: func(p *Param, _ *solaris.Record) (any, error) {
+				var strArr []string
+				for _, elem := range p.Array {
+					strArr = append(strArr, elem.Value())
+				}
+				return strArr, nil
+			},
+			Type: VTStrings,
+		},
 		"ctime": {
 			Flags: PfLValue | PfComparable,
 			ValueF: func(p *Param, r *solaris.Record) (any, error) {
@@ -197,9 +193,61 @@ var (
 			},
 			Type: VTTime,
 		},
+		"attr": { // attr function is written the way -> 'attr("severity") in ["error", "fatal"]' or 'attr("source") = "db"'
+			Flags: PfLValue | PfComparable | PfRValue | PfInLike,
+			CheckF: func(p *Param) error {
+				if p.Function == nil {
+					return fmt.Errorf("attr must be a function: %w", errors.ErrInvalid)
+				}
+				if len(p.Function.Params) != 1 {
+					return fmt.Errorf("attr() function expects only one parameter - the name of the attribute: %w", errors.ErrInvalid)
+				}
+				if p.Function.Params[0].ID() != StringParamID {
+					return fmt.Errorf("attr() function expects the attribute name (string) as the parameter: %w", errors.ErrInvalid)
+				}
+				return nil
+			},
+			ValueF: func(p *Param, r *solaris.Record) (any, error) {
+				return r.Attributes[p.Function.Params[0].Name(true)], nil
+			},
+			Type: VTString,
+		},
 	}
+	// LogsCondSqliteTranslateDialect is the LogsCondTranslateDialect for SQLite, where the tags are read by json_extract()
+	LogsCondSqliteTranslateDialect = logsCondSqliteTranslateDialect()
 )
 
+func logsCondSqliteTranslateDialect() Dialect[*solaris.Log] {
+	d := maps.Clone(LogsCondTranslateDialect)
+	tag := d["tag"]
+	tag.TranslateF = func(tr Translator[*solaris.Log], sb *strings.Builder, p Param) error {
+		if err := checkTag(&p); err != nil {
+			return err
+		}
+		// the tag name is quoted, so the names with dots are not the JSON paths
+		sb.WriteString(`json_extract(tags, '$."`)
+		sb.WriteString(p.Function.Params[0].Name(true))
+		sb.WriteString(`"')`)
+		return nil
+	}
+	d["tag"] = tag
+	return d
+}
+
+// checkTag checks the tag function, which is written the way -> tag("abc")
+func checkTag(p *Param) error {
+	if p.Function == nil {
+		return fmt.Errorf("tag must be a function: %w", errors.ErrInvalid)
+	}
+	if len(p.Function.Params) != 1 {
+		return fmt.Errorf("tag() function expects only one parameter - the name of the tag: %w", errors.ErrInvalid)
+	}
+	if p.Function.Params[0].ID() != StringParamID {
+		return fmt.Errorf("tag() function expects the tag name (string) as the parameter: %w", errors.ErrInvalid)
+	}
+	return nil
+}
+
 // check returns whether the parameter is ok or not. The function is used by the evaluator
 func (pd ParamDialect[T]) check(p *Param) error {
 	if pd.CheckF != nil {
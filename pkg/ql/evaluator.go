@@ -15,10 +15,13 @@
 package ql
 
 import (
+	"cmp"
 	"fmt"
 	"github.com/solarisdb/solaris/golibs/container"
 	"github.com/solarisdb/solaris/golibs/errors"
+	"math"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -156,6 +159,9 @@ func (eb *exprBuilder[T]) buildCond(cn *Condition) (err error) {
 	if d.Flags&PfNop != 0 {
 		return fmt.Errorf("parameter %s cannot be compared (%s) in the condition: %w", cn.FirstParam.Name(false), cn.Op, errors.ErrInvalid)
 	}
+	if cn.Range != nil {
+		return eb.between(cn, d, p1vf)
+	}
 
 	p2 := cn.SecondParam
 	if p2 == nil {
@@ -198,9 +204,9 @@ func (eb *exprBuilder[T]) buildCond(cn *Condition) (err error) {
 			return err
 		}
 		return eb.compare(p1vf, p2vf, d.Type, op)
-	case "IN":
+	case "IN", "NOT IN":
 		if d.Flags&PfInLike == 0 {
-			return fmt.Errorf("the first parameter %s is not applicable for the IN : %w", p1.Name(false), errors.ErrInvalid)
+			return fmt.Errorf("the first parameter %s is not applicable for the %s : %w", p1.Name(false), op, errors.ErrInvalid)
 		}
 		if p2.ID() != ArrayParamID {
 			return fmt.Errorf("the second parameter %s must be an array: %w", p2.Name(false), errors.ErrInvalid)
@@ -209,7 +215,16 @@ func (eb *exprBuilder[T]) buildCond(cn *Condition) (err error) {
 		if err != nil {
 			return err
 		}
-		return eb.in(p1vf, arr.([]string))
+		if err := eb.in(p1vf, arr.([]string)); err != nil {
+			return err
+		}
+		if op == "NOT IN" {
+			f := eb.f
+			eb.f = func(t T) bool {
+				return !f(t)
+			}
+		}
+		return nil
 	case "LIKE":
 		if d.Flags&PfInLike == 0 {
 			return fmt.Errorf("the first parameter %s is not applicable for the LIKE : %w", p1.Name(false), errors.ErrInvalid)
@@ -228,37 +243,64 @@ func (eb *exprBuilder[T]) buildCond(cn *Condition) (err error) {
 	panic("unreacheable")
 }
 
+// between builds the ExprF, which checks that the first parameter value is within the range bounds
+// (inclusive), or out of them for NOT BETWEEN
+func (eb *exprBuilder[T]) between(cn *Condition, d ParamDialect[T], p1vf valueF[T]) error {
+	p1 := &cn.FirstParam
+	if d.Flags&PfComparable == 0 {
+		return fmt.Errorf("the first parameter %s is not applicable for the operation %s: %w", p1.Name(false), cn.Op, errors.ErrInvalid)
+	}
+	var fs []ExprF[T]
+	for i, p := range []*Param{&cn.Range.From, &cn.Range.To} {
+		dp, ok := eb.dialect[p.ID()]
+		if !ok {
+			return fmt.Errorf("unknown range bound %s: %w", p.Name(false), errors.ErrInvalid)
+		}
+		if dp.Flags&PfRValue == 0 {
+			return fmt.Errorf("parameter %s cannot be the range bound: %w", p.Name(false), errors.ErrInvalid)
+		}
+		if dp.Flags&PfComparable == 0 || dp.Flags&PfNop != 0 {
+			return fmt.Errorf("the range bound %s is not applicable for the operation %s: %w", p.Name(false), cn.Op, errors.ErrInvalid)
+		}
+		pvf, err := eb.paramDialect2ValueF(dp, p, &d.Type)
+		if err != nil {
+			return err
+		}
+		op := ">="
+		if i > 0 {
+			op = "<="
+		}
+		if err := eb.compare(p1vf, pvf, d.Type, op); err != nil {
+			return err
+		}
+		fs = append(fs, eb.f)
+	}
+	if cn.Range.Not {
+		eb.f = func(t T) bool { return !fs[0](t) || !fs[1](t) }
+	} else {
+		eb.f = func(t T) bool { return fs[0](t) && fs[1](t) }
+	}
+	return nil
+}
+
 // compare builds the ExprF, which will build comparison of vf1 and vf2 results depending on the op
 func (eb *exprBuilder[T]) compare(vf1, vf2 valueF[T], tp ValueType, op string) error {
 	switch tp {
 	case VTTime:
-		switch op {
-		case "<":
-			eb.f = func(t T) bool {
-				v1, err := vf1(nil, t)
-				if err != nil {
-					return false
-				}
-				v2, err := vf2(nil, t)
-				if err != nil {
-					return false
-				}
-				return v1.(time.Time).Before(v2.(time.Time))
+		resF, err := cmpResultF(op)
+		if err != nil {
+			return fmt.Errorf("unsupport operation %s for the time comparision: %w", op, err)
+		}
+		eb.f = func(t T) bool {
+			v1, err := vf1(nil, t)
+			if err != nil {
+				return false
 			}
-		case ">":
-			eb.f = func(t T) bool {
-				v1, err := vf1(nil, t)
-				if err != nil {
-					return false
-				}
-				v2, err := vf2(nil, t)
-				if err != nil {
-					return false
-				}
-				return v1.(time.Time).After(v2.(time.Time))
+			v2, err := vf2(nil, t)
+			if err != nil {
+				return false
 			}
-		default:
-			return fmt.Errorf("unsupport operation %s for the time comparision: %w", op, errors.ErrInvalid)
+			return resF(v1.(time.Time).Compare(v2.(time.Time)))
 		}
 	case VTString:
 		switch op {
@@ -337,10 +379,45 @@ func (eb *exprBuilder[T]) compare(vf1, vf2 valueF[T], tp ValueType, op string) e
 		default:
 			return fmt.Errorf("unsupport operation %s for the string comparision: %w", op, errors.ErrInvalid)
 		}
+	case VTInt:
+		resF, err := cmpResultF(op)
+		if err != nil {
+			return fmt.Errorf("unsupport operation %s for the int comparision: %w", op, err)
+		}
+		eb.f = func(t T) bool {
+			v1, err := vf1(nil, t)
+			if err != nil {
+				return false
+			}
+			v2, err := vf2(nil, t)
+			if err != nil {
+				return false
+			}
+			return resF(cmp.Compare(v1.(int64), v2.(int64)))
+		}
 	}
 	return nil
 }
 
+// cmpResultF returns the function, which checks the comparison result (see cmp.Compare) for the op
+func cmpResultF(op string) (func(c int) bool, error) {
+	switch op {
+	case "<":
+		return func(c int) bool { return c < 0 }, nil
+	case ">":
+		return func(c int) bool { return c > 0 }, nil
+	case "<=":
+		return func(c int) bool { return c <= 0 }, nil
+	case ">=":
+		return func(c int) bool { return c >= 0 }, nil
+	case "=":
+		return func(c int) bool { return c == 0 }, nil
+	case "!=":
+		return func(c int) bool { return c != 0 }, nil
+	}
+	return nil, errors.ErrInvalid
+}
+
 // in create the IN operation in eb.f
 func (eb *exprBuilder[T]) in(vf valueF[T], arr []string) error {
 	if len(arr) == 0 {
@@ -465,7 +542,28 @@ func castValueF[T any](f valueF[T], from, to ValueType) (valueF[T], error) {
 				}
 				return parseDateTime(s.(string))
 			}, nil
+		case VTInt:
+			return func(p *Param, t T) (any, error) {
+				s, err := f(p, t)
+				if err != nil {
+					return s, err
+				}
+				return parseInt(s.(string))
+			}, nil
 		}
 	}
 	return f, fmt.Errorf("could not cast value of type %s to %s: %w", typeNames[from], typeNames[to], errors.ErrInvalid)
 }
+
+// parseInt parses the integer value, the numbers constants may be specified in the floating point form
+// (see Const.Value), but they must not have the fractional part.
+func parseInt(s string) (int64, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("the value %q is not an integer: %w", s, errors.ErrInvalid)
+	}
+	return int64(f), nil
+}
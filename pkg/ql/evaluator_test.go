@@ -28,6 +28,7 @@ import (
 type testRecord struct {
 	StringField string
 	TimeField   time.Time
+	IntField    int64
 }
 
 var (
@@ -39,6 +40,13 @@ var (
 			},
 			Type: VTString,
 		},
+		NumberParamID: { // numbers are rvalues only
+			Flags: PfRValue | PfComparable | PfConstValue,
+			ValueF: func(p *Param, _ testRecord) (any, error) {
+				return p.Const.Value(), nil
+			},
+			Type: VTString,
+		},
 		ArrayParamID: { // arrays are rvalues only
 			Flags: PfRValue | PfConstValue,
 			ValueF: func(p *Param, _ testRecord) (any, error) {
@@ -64,6 +72,13 @@ var (
 			},
 			Type: VTTime,
 		},
+		"IntField": {
+			Flags: PfLValue | PfComparable,
+			ValueF: func(p *Param, r testRecord) (any, error) {
+				return r.IntField, nil
+			},
+			Type: VTInt,
+		},
 		"ErrValue": {
 			Flags: PfLValue | PfComparable,
 			ValueF: func(p *Param, r testRecord) (any, error) {
@@ -144,6 +159,51 @@ func TestLogCondEval_EvalFalse(t *testing.T) {
 	assert.False(t, eval(log2))
 }
 
+func TestLogCondEval_NotIn(t *testing.T) {
+	expr, err := Parse("tag('tag1') NOT IN ('val1', 'val2')")
+	assert.Nil(t, err)
+	eval, err := BuildExprF(expr, LogsCondValueDialect)
+	assert.Nil(t, err)
+
+	assert.False(t, eval(&solaris.Log{Tags: map[string]string{"tag1": "val1"}}))
+	assert.False(t, eval(&solaris.Log{Tags: map[string]string{"tag1": "val2"}}))
+	assert.True(t, eval(&solaris.Log{Tags: map[string]string{"tag1": "val3"}}))
+}
+
+func TestLogCondEval_Between(t *testing.T) {
+	expr, err := Parse("tag('tag1') BETWEEN 'val1' AND 'val3' AND tag('tag2') NOT BETWEEN 'val1' AND 'val3'")
+	assert.Nil(t, err)
+	eval, err := BuildExprF(expr, LogsCondValueDialect)
+	assert.Nil(t, err)
+
+	assert.True(t, eval(&solaris.Log{Tags: map[string]string{"tag1": "val1", "tag2": "val0"}}))
+	assert.True(t, eval(&solaris.Log{Tags: map[string]string{"tag1": "val3", "tag2": "val4"}}))
+	assert.False(t, eval(&solaris.Log{Tags: map[string]string{"tag1": "val4", "tag2": "val4"}}))
+	assert.False(t, eval(&solaris.Log{Tags: map[string]string{"tag1": "val2", "tag2": "val3"}}))
+}
+
+func TestRecordCondEval_Attributes(t *testing.T) {
+	expr, err := Parse("attr('severity') IN ['error', 'fatal'] AND NOT attr('source') LIKE 'test%'")
+	assert.Nil(t, err)
+	assert.True(t, expr.Uses("attr"))
+	assert.False(t, expr.Uses("ctime"))
+	eval, err := BuildExprF(expr, RecordsCondValueDialect)
+	assert.Nil(t, err)
+
+	assert.True(t, eval(&solaris.Record{Attributes: map[string]string{"severity": "error"}}))
+	assert.True(t, eval(&solaris.Record{Attributes: map[string]string{"severity": "fatal", "source": "db"}}))
+	assert.False(t, eval(&solaris.Record{Attributes: map[string]string{"severity": "error", "source": "test1"}}))
+	assert.False(t, eval(&solaris.Record{Attributes: map[string]string{"severity": "info"}}))
+	assert.False(t, eval(&solaris.Record{}))
+
+	for _, cond := range []string{"attr = 'error'", "attr() = 'error'", "attr('a', 'b') = 'error'"} {
+		expr, err = Parse(cond)
+		assert.Nil(t, err)
+		_, err = BuildExprF(expr, RecordsCondValueDialect)
+		assert.True(t, errors.Is(err, errors.ErrInvalid), cond)
+	}
+}
+
 func TestBuildExprF(t *testing.T) {
 	f, err := BuildExprF(nil, testDialect)
 	assert.Nil(t, err)
@@ -172,3 +232,44 @@ func TestBuildExprF(t *testing.T) {
 	f, err = BuildExprF(expr, testDialect)
 	assert.False(t, f(testRecord{}))
 }
+
+func TestBuildExprF_Int(t *testing.T) {
+	expr, err := Parse("IntField >= 1024 AND IntField < '4096' AND IntField != 2000")
+	assert.Nil(t, err)
+	f, err := BuildExprF(expr, testDialect)
+	assert.Nil(t, err)
+	assert.True(t, f(testRecord{IntField: 1024}))
+	assert.True(t, f(testRecord{IntField: 4095}))
+	assert.False(t, f(testRecord{IntField: 1023}))
+	assert.False(t, f(testRecord{IntField: 2000}))
+	assert.False(t, f(testRecord{IntField: 4096}))
+
+	for _, cond := range []string{"IntField > 'abc'", "IntField > 1.5", "IntField > 9.223372036854775807e18"} {
+		expr, err = Parse(cond)
+		assert.Nil(t, err)
+		_, err = BuildExprF(expr, testDialect)
+		assert.True(t, errors.Is(err, errors.ErrInvalid))
+		assert.Contains(t, err.Error(), "is not an integer")
+	}
+}
+
+func TestBuildExprF_Time(t *testing.T) {
+	tm, err := time.Parse(time.RFC3339Nano, "2024-04-11T16:00:00.000000001Z")
+	assert.Nil(t, err)
+	expr, err := Parse("TimeField BETWEEN '2024-04-11T16:00:00.000000001Z' AND '2024-04-11T17:00:00Z' AND TimeField != '2024-04-11T16:30:00Z'")
+	assert.Nil(t, err)
+	f, err := BuildExprF(expr, testDialect)
+	assert.Nil(t, err)
+	assert.True(t, f(testRecord{TimeField: tm}))
+	assert.True(t, f(testRecord{TimeField: tm.Add(time.Hour - time.Nanosecond)}))
+	assert.False(t, f(testRecord{TimeField: tm.Add(-time.Nanosecond)}))
+	assert.False(t, f(testRecord{TimeField: tm.Add(time.Hour)}))
+	assert.False(t, f(testRecord{TimeField: tm.Add(30*time.Minute - time.Nanosecond)}))
+
+	expr, err = Parse("TimeField = '2024-04-11T16:00:00.000000001Z'")
+	assert.Nil(t, err)
+	f, err = BuildExprF(expr, testDialect)
+	assert.Nil(t, err)
+	assert.True(t, f(testRecord{TimeField: tm}))
+	assert.False(t, f(testRecord{TimeField: tm.Add(time.Nanosecond)}))
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/pkg/intervals"
 	"sort"
+	"strings"
 )
 
 // ParamIntervalBuilder allows to build value intervals from the AST expression
@@ -32,7 +33,7 @@ type ParamIntervalBuilder[T, K any] struct {
 }
 
 var (
-	OpsAll  = []string{"<", ">", "<=", ">=", "=", "!="}
+	OpsAll  = []string{"<", ">", "<=", ">=", "=", "!=", "IN", "NOT IN", "BETWEEN", "NOT BETWEEN", "LIKE"}
 	OpsGtLt = []string{"<", ">"}
 )
 
@@ -95,11 +96,7 @@ func (ib *ParamIntervalBuilder[T, K]) buildXCond(and *XCondition) ([]intervals.I
 	if !and.Not {
 		return res, nil
 	}
-	var negated []intervals.Interval[T]
-	for _, t := range res {
-		negated = append(negated, ib.basis.Negate(t)...)
-	}
-	return negated, nil
+	return ib.negate(res), nil
 }
 
 func (ib *ParamIntervalBuilder[T, K]) buildCond(cond *Condition) ([]intervals.Interval[T], error) {
@@ -115,9 +112,15 @@ func (ib *ParamIntervalBuilder[T, K]) buildCond(cond *Condition) ([]intervals.In
 	if dp1.Flags&PfNop != 0 {
 		return nil, fmt.Errorf("the parameter %s must allow operation (%s): %w", p1.Name(false), cond.Op, errors.ErrInvalid)
 	}
+	if cond.Op == "LIKE" && dp1.Flags&PfInLike == 0 {
+		return nil, fmt.Errorf("the parameter %s is not applicable for the LIKE: %w", p1.Name(false), errors.ErrInvalid)
+	}
 	if p1.Name(false) != ib.param { // skip not the param we look for
 		return nil, nil
 	}
+	if cond.Range != nil {
+		return ib.buildBetween(cond, dp1)
+	}
 
 	// param2
 	p2 := cond.SecondParam
@@ -134,14 +137,19 @@ func (ib *ParamIntervalBuilder[T, K]) buildCond(cond *Condition) ([]intervals.In
 	if dp2.Flags&PfNop != 0 {
 		return nil, fmt.Errorf("the second parameter %s must allow operation (%s): %w", p2.Name(false), cond.Op, errors.ErrInvalid)
 	}
-	if p2.Const == nil { // skip not a constant param
-		return nil, nil
-	}
-
 	// operation
 	if !ib.ops[cond.Op] { // skip not the ops we look for
 		return nil, nil
 	}
+	if cond.Op == "IN" || cond.Op == "NOT IN" {
+		return ib.buildIn(cond, dp1)
+	}
+	if cond.Op == "LIKE" {
+		return ib.buildLike(cond, dp1, dp2)
+	}
+	if p2.Const == nil { // skip not a constant param
+		return nil, nil
+	}
 	switch cond.Op {
 	case "<", ">":
 		if dp1.Flags&PfComparable == 0 && dp1.Flags&PfGreaterLess == 0 {
@@ -160,21 +168,185 @@ func (ib *ParamIntervalBuilder[T, K]) buildCond(cond *Condition) ([]intervals.In
 	}
 
 	// value
-	vf, err := castValueF(dp2.ValueF, dp2.Type, dp1.Type)
+	tVal, err := ib.value(p2, dp2, dp1)
 	if err != nil {
 		return nil, err
 	}
-	kVal, err := vf(cond.SecondParam, *new(K))
+
+	// intervals
+	return ib.getIntervals(cond.Op, tVal), nil
+}
+
+// buildIn returns the union of the point intervals for the IN operation constants, or its negation for NOT IN
+func (ib *ParamIntervalBuilder[T, K]) buildIn(cond *Condition, dp1 ParamDialect[K]) ([]intervals.Interval[T], error) {
+	p1, p2 := cond.FirstParam, cond.SecondParam
+	if dp1.Flags&PfComparable == 0 {
+		return nil, fmt.Errorf("the first parameter %s must be comparable for the operation %s: %w", p1.Name(false), cond.Op, errors.ErrInvalid)
+	}
+	if p2.ID() != ArrayParamID {
+		return nil, fmt.Errorf("the second parameter %s must be an array for the operation %s: %w", p2.Name(false), cond.Op, errors.ErrInvalid)
+	}
+	var res []intervals.Interval[T]
+	for _, c := range p2.Array {
+		p := &Param{Const: c}
+		dp, ok := ib.dialect[p.ID()]
+		if !ok {
+			return nil, fmt.Errorf("the array element %s must be known: %w", p.Name(false), errors.ErrInvalid)
+		}
+		tVal, err := ib.value(p, dp, dp1)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ib.basis.Closed(tVal, tVal))
+	}
+	res = ib.union(res)
+	if cond.Op == "NOT IN" {
+		return ib.negate(res), nil
+	}
+	return res, nil
+}
+
+// buildBetween returns the closed interval for the BETWEEN operation bounds, or its negation for NOT BETWEEN
+func (ib *ParamIntervalBuilder[T, K]) buildBetween(cond *Condition, dp1 ParamDialect[K]) ([]intervals.Interval[T], error) {
+	p1 := cond.FirstParam
+	var bounds []T
+	for _, p := range []*Param{&cond.Range.From, &cond.Range.To} {
+		dp, ok := ib.dialect[p.ID()]
+		if !ok {
+			return nil, fmt.Errorf("the range bound %s must be known: %w", p.Name(false), errors.ErrInvalid)
+		}
+		if dp.Flags&PfRValue == 0 {
+			return nil, fmt.Errorf("the range bound %s must be on the right side of the condition: %w", p.Name(false), errors.ErrInvalid)
+		}
+		if dp.Flags&PfNop != 0 {
+			return nil, fmt.Errorf("the range bound %s must allow operation (%s): %w", p.Name(false), cond.Op, errors.ErrInvalid)
+		}
+		if !ib.ops[cond.Op] || p.Const == nil { // skip not the ops we look for and not a constant bound
+			return nil, nil
+		}
+		if dp1.Flags&PfComparable == 0 {
+			return nil, fmt.Errorf("the first parameter %s must be comparable for the operation %s: %w", p1.Name(false), cond.Op, errors.ErrInvalid)
+		}
+		if dp.Flags&PfComparable == 0 {
+			return nil, fmt.Errorf("the range bound %s must be comparable for the operation %s: %w", p.Name(false), cond.Op, errors.ErrInvalid)
+		}
+		tVal, err := ib.value(p, dp, dp1)
+		if err != nil {
+			return nil, err
+		}
+		bounds = append(bounds, tVal)
+	}
+	if ib.basis.CmpF(bounds[0], bounds[1]) > 0 {
+		return nil, fmt.Errorf("the lower bound %s is greater than the upper bound %s for the parameter %s and the operation %s: %w",
+			cond.Range.From.Name(false), cond.Range.To.Name(false), p1.Name(false), cond.Op, errors.ErrInvalid)
+	}
+	res := []intervals.Interval[T]{ib.basis.Closed(bounds[0], bounds[1])}
+	if cond.Range.Not {
+		return ib.negate(res), nil
+	}
+	return res, nil
+}
+
+// buildLike returns the interval of the strings matching the LIKE pattern. Only the exact patterns
+// and the prefix ones ('abc%') are turned into the intervals, the others don't limit the parameter
+// and are checked by the expression evaluator.
+func (ib *ParamIntervalBuilder[T, K]) buildLike(cond *Condition, dp1, dp2 ParamDialect[K]) ([]intervals.Interval[T], error) {
+	p1, p2 := cond.FirstParam, cond.SecondParam
+	if p2.ID() != StringParamID {
+		return nil, fmt.Errorf("the second parameter %s must be a string for the LIKE: %w", p2.Name(false), errors.ErrInvalid)
+	}
+	prefix, exact, ok := likePrefix(p2.Const.Value())
+	if !ok {
+		return nil, nil
+	}
+	tVal, err := ib.value(p2, dp2, dp1)
 	if err != nil {
 		return nil, err
 	}
+	if exact {
+		return []intervals.Interval[T]{ib.basis.Closed(tVal, tVal)}, nil
+	}
+	next := nextPrefix(prefix)
+	l, lok := any(prefix).(T)
+	r, rok := any(next).(T)
+	if !lok || !rok {
+		return nil, fmt.Errorf("the LIKE prefix of the parameter %s cannot be turned into the interval(type=%T): %w", p1.Name(false), tVal, errors.ErrInvalid)
+	}
+	if next == "" {
+		return []intervals.Interval[T]{ib.basis.Closed(l, ib.basis.Max)}, nil
+	}
+	return []intervals.Interval[T]{ib.basis.OpenR(l, r)}, nil
+}
+
+// likePrefix returns the prefix of the LIKE pattern and whether the pattern doesn't contain the wildcards
+// at all. The ok is false if the pattern has a wildcard not at its end, so it is not the prefix one.
+func likePrefix(pat string) (prefix string, exact bool, ok bool) {
+	prefix = strings.TrimRight(pat, "%")
+	if strings.Contains(prefix, "%") {
+		return "", false, false
+	}
+	return prefix, len(prefix) == len(pat), true
+}
+
+// nextPrefix returns the least string, which is greater than all the strings with the prefix,
+// or the empty string if there is no such one.
+func nextPrefix(prefix string) string {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1])
+		}
+	}
+	return ""
+}
+
+// value returns the constant parameter p value as the interval point
+func (ib *ParamIntervalBuilder[T, K]) value(p *Param, dp, dp1 ParamDialect[K]) (T, error) {
+	var tVal T
+	vf, err := castValueF(dp.ValueF, dp.Type, dp1.Type)
+	if err != nil {
+		return tVal, err
+	}
+	kVal, err := vf(p, *new(K))
+	if err != nil {
+		return tVal, err
+	}
 	tVal, ok := kVal.(T)
 	if !ok {
-		return nil, fmt.Errorf("cannot cast the second parameter value(type=%T) to interval point(type=%T): %w", kVal, tVal, errors.ErrInvalid)
+		return tVal, fmt.Errorf("cannot cast the parameter %s value(type=%T) to interval point(type=%T): %w", p.Name(false), kVal, tVal, errors.ErrInvalid)
 	}
+	return tVal, nil
+}
 
-	// intervals
-	return ib.getIntervals(cond.Op, tVal), nil
+// limits returns true if the condition on the builder parameter is turned into the intervals by buildCond
+func (ib *ParamIntervalBuilder[T, K]) limits(cond *Condition) bool {
+	if cond.FirstParam.Name(false) != ib.param || !ib.ops[cond.Op] {
+		return false
+	}
+	if cond.Range != nil {
+		return cond.Range.From.Const != nil && cond.Range.To.Const != nil
+	}
+	if cond.Op == "IN" || cond.Op == "NOT IN" {
+		return true
+	}
+	if cond.Op == "LIKE" {
+		if cond.SecondParam == nil || cond.SecondParam.ID() != StringParamID {
+			return false
+		}
+		_, _, ok := likePrefix(cond.SecondParam.Const.Value())
+		return ok
+	}
+	return cond.SecondParam != nil && cond.SecondParam.Const != nil
+}
+
+// negate returns the intervals, which cover everything except the sorted not overlapping intervals ii
+func (ib *ParamIntervalBuilder[T, K]) negate(ii []intervals.Interval[T]) []intervals.Interval[T] {
+	var groups [][]intervals.Interval[T]
+	for _, t := range ii {
+		groups = append(groups, ib.basis.Negate(t))
+	}
+	return ib.union(ib.intersect(groups))
 }
 
 func (ib *ParamIntervalBuilder[T, K]) union(intervalsL []intervals.Interval[T]) []intervals.Interval[T] {
@@ -15,9 +15,13 @@
 package ql
 
 import (
+	"fmt"
+	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/pkg/intervals"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
+	"time"
 	"unicode/utf8"
 )
 
@@ -30,17 +34,58 @@ var (
 			},
 			Type: VTString,
 		},
+		NumberParamID: {
+			Flags: PfRValue | PfComparable | PfConstValue,
+			ValueF: func(p *Param, _ testRecord) (any, error) {
+				return p.Const.Value(), nil
+			},
+			Type: VTString,
+		},
+		ArrayParamID: {
+			Flags: PfRValue | PfConstValue,
+			ValueF: func(p *Param, _ testRecord) (any, error) {
+				var res []string
+				for _, c := range p.Array {
+					res = append(res, c.Value())
+				}
+				return res, nil
+			},
+			Type: VTStrings,
+		},
+		"size": {
+			Flags: PfLValue | PfComparable,
+			ValueF: func(p *Param, r testRecord) (any, error) {
+				return r.IntField, nil
+			},
+			Type: VTInt,
+		},
 		"t": {
+			Flags: PfLValue | PfComparable | PfInLike,
+			ValueF: func(p *Param, r testRecord) (any, error) {
+				return p.Const.Value(), nil
+			},
+			Type: VTString,
+		},
+		"s": {
 			Flags: PfLValue | PfComparable,
 			ValueF: func(p *Param, r testRecord) (any, error) {
 				return p.Const.Value(), nil
 			},
 			Type: VTString,
 		},
+		"ctime": {
+			Flags: PfLValue | PfComparable,
+			ValueF: func(p *Param, r testRecord) (any, error) {
+				return r.TimeField, nil
+			},
+			Type: VTTime,
+		},
 	}
 )
 
 var testIntervalBuilder = NewParamIntervalBuilder(intervals.BasisString, testIntervalDialect, "t", OpsAll)
+var testIntIntervalBuilder = NewParamIntervalBuilder(intervals.BasisInt64, testIntervalDialect, "size", OpsAll)
+var testTimeIntervalBuilder = NewParamIntervalBuilder(intervals.BasisTime, testIntervalDialect, "ctime", OpsAll)
 
 func TestIntervalBuilder_NoInterval(t *testing.T) {
 	expr, err := Parse("(t < 'b' AND t > 'c')")
@@ -74,3 +119,219 @@ func TestIntervalBuilder_TwoIntervals(t *testing.T) {
 	assert.Equal(t, "k", i2.L)
 	assert.Equal(t, string(utf8.MaxRune), i2.R)
 }
+
+func TestIntervalBuilder_In(t *testing.T) {
+	expr, err := Parse("t IN ('d', 'b')")
+	assert.Nil(t, err)
+	ii, err := testIntervalBuilder.Build(expr)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ii))
+	i1, i2 := ii[0], ii[1] // ['b', 'b'], ['d', 'd']
+	assert.True(t, i1.IsClosed())
+	assert.Equal(t, "b", i1.L)
+	assert.Equal(t, "b", i1.R)
+	assert.True(t, i2.IsClosed())
+	assert.Equal(t, "d", i2.L)
+	assert.Equal(t, "d", i2.R)
+
+	expr, err = Parse("t IN ('b', 'd') AND t > 'c'")
+	assert.Nil(t, err)
+	ii, err = testIntervalBuilder.Build(expr)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ii))
+	assert.Equal(t, "d", ii[0].L)
+	assert.Equal(t, "d", ii[0].R)
+
+	expr, err = Parse("t IN 'b'")
+	assert.Nil(t, err)
+	_, err = testIntervalBuilder.Build(expr)
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
+func TestIntervalBuilder_NotIn(t *testing.T) {
+	for _, cond := range []string{"t NOT IN ('d', 'b')", "NOT t IN ('d', 'b')"} {
+		expr, err := Parse(cond)
+		assert.Nil(t, err)
+		ii, err := testIntervalBuilder.Build(expr)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(ii))
+		i1, i2, i3 := ii[0], ii[1], ii[2] // ['', 'b'), ('b', 'd'), ('d', max]
+		assert.True(t, i1.IsOpenR())
+		assert.Equal(t, "", i1.L)
+		assert.Equal(t, "b", i1.R)
+		assert.True(t, i2.IsOpen())
+		assert.Equal(t, "b", i2.L)
+		assert.Equal(t, "d", i2.R)
+		assert.True(t, i3.IsOpenL())
+		assert.Equal(t, "d", i3.L)
+		assert.Equal(t, string(utf8.MaxRune), i3.R)
+	}
+}
+
+func TestIntervalBuilder_Between(t *testing.T) {
+	expr, err := Parse("(t BETWEEN 'b' AND 'd' AND t < 'c') OR (t between 'k' and 'm')")
+	assert.Nil(t, err)
+	ii, err := testIntervalBuilder.Build(expr)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ii))
+	i1, i2 := ii[0], ii[1] // ['b', 'c'), ['k', 'm']
+	assert.True(t, i1.IsOpenR())
+	assert.Equal(t, "b", i1.L)
+	assert.Equal(t, "c", i1.R)
+	assert.True(t, i2.IsClosed())
+	assert.Equal(t, "k", i2.L)
+	assert.Equal(t, "m", i2.R)
+
+	expr, err = Parse("t BETWEEN 'd' AND 'b'")
+	assert.Nil(t, err)
+	_, err = testIntervalBuilder.Build(expr)
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	assert.Contains(t, err.Error(), "parameter t")
+}
+
+func TestIntervalBuilder_NotBetween(t *testing.T) {
+	for _, cond := range []string{"t NOT BETWEEN 'b' AND 'd' OR t = 'c'", "NOT t BETWEEN 'b' AND 'd' OR t = 'c'"} {
+		expr, err := Parse(cond)
+		assert.Nil(t, err)
+		ii, err := testIntervalBuilder.Build(expr)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(ii))
+		i1, i2, i3 := ii[0], ii[1], ii[2] // ['', 'b'), ['c', 'c'], ('d', max]
+		assert.True(t, i1.IsOpenR())
+		assert.Equal(t, "", i1.L)
+		assert.Equal(t, "b", i1.R)
+		assert.True(t, i2.IsClosed())
+		assert.Equal(t, "c", i2.L)
+		assert.Equal(t, "c", i2.R)
+		assert.True(t, i3.IsOpenL())
+		assert.Equal(t, "d", i3.L)
+		assert.Equal(t, string(utf8.MaxRune), i3.R)
+	}
+}
+
+func TestIntervalBuilder_Like(t *testing.T) {
+	for _, tc := range []struct {
+		cond string
+		ii   []intervals.Interval[string]
+	}{
+		// the prefix patterns
+		{"t LIKE 'ab%'", []intervals.Interval[string]{intervals.BasisString.OpenR("ab", "ac")}},
+		{"t LIKE 'ab%%'", []intervals.Interval[string]{intervals.BasisString.OpenR("ab", "ac")}},
+		{"t LIKE '%'", []intervals.Interval[string]{intervals.BasisString.Closed("", string(utf8.MaxRune))}},
+		{"t LIKE 'ab%' AND t > 'abc'", []intervals.Interval[string]{intervals.BasisString.Open("abc", "ac")}},
+		{"NOT t LIKE 'ab%'", []intervals.Interval[string]{intervals.BasisString.OpenR("", "ab"),
+			intervals.BasisString.Closed("ac", string(utf8.MaxRune))}},
+		// the exact patterns
+		{"t LIKE 'ab'", []intervals.Interval[string]{intervals.BasisString.Closed("ab", "ab")}},
+		{"t LIKE ''", []intervals.Interval[string]{intervals.BasisString.Closed("", "")}},
+		// the contains patterns are checked by the evaluator only
+		{"t LIKE '%ab%'", nil},
+		{"t LIKE 'a%b'", nil},
+		{"t LIKE 'a%b' AND t < 'b'", []intervals.Interval[string]{intervals.BasisString.OpenR("", "b")}},
+	} {
+		expr, err := Parse(tc.cond)
+		assert.Nil(t, err)
+		ii, err := testIntervalBuilder.Build(expr)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.ii, ii, tc.cond)
+	}
+
+	assert.Equal(t, "b", nextPrefix("a\xff\xff"))
+	assert.Equal(t, "", nextPrefix("\xff"))
+
+	// the parameter doesn't allow LIKE
+	for _, cond := range []string{"s LIKE 'a%'", "t LIKE 'a%' AND s LIKE '%'"} {
+		expr, err := Parse(cond)
+		assert.Nil(t, err)
+		_, err = testIntervalBuilder.Build(expr)
+		assert.True(t, errors.Is(err, errors.ErrInvalid), cond)
+	}
+	expr, err := Parse("ctime LIKE '2024%'")
+	assert.Nil(t, err)
+	_, err = testTimeIntervalBuilder.Build(expr)
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+
+	// the pattern must be a string
+	expr, err = Parse("t LIKE 12")
+	assert.Nil(t, err)
+	_, err = testIntervalBuilder.Build(expr)
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
+func TestIntervalBuilder_IntOneInterval(t *testing.T) {
+	expr, err := Parse("size >= 1024 AND size < 4096")
+	assert.Nil(t, err)
+	ii, err := testIntIntervalBuilder.Build(expr)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ii))
+	assert.True(t, ii[0].IsOpenR())
+	assert.Equal(t, int64(1024), ii[0].L)
+	assert.Equal(t, int64(4096), ii[0].R)
+}
+
+func TestIntervalBuilder_IntTwoIntervals(t *testing.T) {
+	expr, err := Parse("((size > 1 AND size < 30) AND (size > '20' AND size < 50)) OR (size > 100)")
+	assert.Nil(t, err)
+	ii, err := testIntIntervalBuilder.Build(expr)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ii))
+	i1, i2 := ii[0], ii[1] // (20, 30), (100, max]
+	assert.True(t, i1.IsOpen())
+	assert.Equal(t, int64(20), i1.L)
+	assert.Equal(t, int64(30), i1.R)
+	assert.True(t, i2.IsOpenL())
+	assert.Equal(t, int64(100), i2.L)
+	assert.Equal(t, int64(math.MaxInt64), i2.R)
+
+	expr, err = Parse("size NOT IN (10, 20)")
+	assert.Nil(t, err)
+	ii, err = testIntIntervalBuilder.Build(expr)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ii))
+	assert.Equal(t, int64(math.MinInt64), ii[0].L)
+	assert.Equal(t, int64(20), ii[2].L)
+}
+
+func TestIntervalBuilder_IntNotNumber(t *testing.T) {
+	for _, cond := range []string{"size > 'abc'", "size < 1.5", "size IN (1, 'a')"} {
+		expr, err := Parse(cond)
+		assert.Nil(t, err)
+		_, err = testIntIntervalBuilder.Build(expr)
+		assert.True(t, errors.Is(err, errors.ErrInvalid), cond)
+		assert.Contains(t, err.Error(), "is not an integer", cond)
+	}
+}
+
+func TestIntervalBuilder_TimeIdenticalBounds(t *testing.T) {
+	ts := "2024-04-11T16:00:00.000000001Z"
+	tm, err := time.Parse(time.RFC3339Nano, ts)
+	assert.Nil(t, err)
+	build := func(cond string) []intervals.Interval[time.Time] {
+		expr, err := Parse(fmt.Sprintf(cond, ts))
+		assert.Nil(t, err)
+		ii, err := testTimeIntervalBuilder.Build(expr)
+		assert.Nil(t, err)
+		return ii
+	}
+
+	ii := build("ctime >= '%[1]s' AND ctime <= '%[1]s'")
+	assert.Equal(t, []intervals.Interval[time.Time]{intervals.BasisTime.Closed(tm, tm)}, ii)
+	assert.Equal(t, ii, build("ctime = '%[1]s'"))
+	assert.Equal(t, ii, build("ctime BETWEEN '%[1]s' AND '%[1]s'"))
+
+	assert.Empty(t, build("ctime > '%[1]s' AND ctime <= '%[1]s'"))
+	assert.Empty(t, build("ctime >= '%[1]s' AND ctime < '%[1]s'"))
+	assert.Empty(t, build("ctime > '%[1]s' AND ctime < '%[1]s'"))
+
+	// the intervals touching at the same time are not intersected, so they are not united
+	ii = build("ctime > '%[1]s' OR ctime = '%[1]s'")
+	assert.Equal(t, []intervals.Interval[time.Time]{intervals.BasisTime.Closed(tm, tm),
+		intervals.BasisTime.OpenL(tm, intervals.BasisTime.Max)}, ii)
+
+	ii = build("ctime != '%[1]s'")
+	assert.Equal(t, 2, len(ii))
+	assert.True(t, ii[0].IsOpenR())
+	assert.True(t, ii[0].R.Equal(tm))
+	assert.True(t, ii[1].IsOpenL())
+	assert.True(t, ii[1].L.Equal(tm))
+}
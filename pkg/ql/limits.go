@@ -0,0 +1,88 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ql
+
+import (
+	"fmt"
+	"github.com/solarisdb/solaris/golibs/errors"
+)
+
+// Limits defines the constraints for a condition to be accepted. Zero value of a field means no limit.
+type Limits struct {
+	// MaxLength defines the maximum length of the condition string
+	MaxLength int
+	// MaxNodes defines the maximum number of the conditions, parameters and array elements in the condition
+	MaxNodes int
+	// MaxDepth defines the maximum nesting level of the expressions in parentheses
+	MaxDepth int
+}
+
+// Check returns errors.ErrInvalid if the condition cond exceeds the limits. The condition length is checked before
+// parsing, so the oversized conditions are rejected without spending time to parse them.
+func (l Limits) Check(cond string) error {
+	if l.MaxLength > 0 && len(cond) > l.MaxLength {
+		return fmt.Errorf("the condition length %d exceeds the maximum %d: %w", len(cond), l.MaxLength, errors.ErrInvalid)
+	}
+	if l.MaxNodes <= 0 && l.MaxDepth <= 0 {
+		return nil
+	}
+	expr, err := Parse(cond)
+	if err != nil {
+		return err
+	}
+	nodes, depth := expr.complexity()
+	if l.MaxNodes > 0 && nodes > l.MaxNodes {
+		return fmt.Errorf("the condition contains %d nodes, which exceeds the maximum %d: %w", nodes, l.MaxNodes, errors.ErrInvalid)
+	}
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return fmt.Errorf("the condition depth %d exceeds the maximum %d: %w", depth, l.MaxDepth, errors.ErrInvalid)
+	}
+	return nil
+}
+
+// complexity returns the number of nodes and the nesting depth of the expression
+func (e *Expression) complexity() (int, int) {
+	nodes, depth := 0, 0
+	for _, oc := range e.Or {
+		for _, xc := range oc.And {
+			if xc.Expr != nil {
+				n, d := xc.Expr.complexity()
+				nodes += n
+				depth = max(depth, d)
+				continue
+			}
+			if xc.Cond != nil {
+				nodes += 1 + xc.Cond.FirstParam.nodes()
+				if xc.Cond.SecondParam != nil {
+					nodes += xc.Cond.SecondParam.nodes()
+				}
+				if xc.Cond.Range != nil {
+					nodes += xc.Cond.Range.From.nodes() + xc.Cond.Range.To.nodes()
+				}
+			}
+		}
+	}
+	return nodes, depth + 1
+}
+
+func (p *Param) nodes() int {
+	res := 1 + len(p.Array)
+	if p.Function != nil {
+		for _, fp := range p.Function.Params {
+			res += fp.nodes()
+		}
+	}
+	return res
+}
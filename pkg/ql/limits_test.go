@@ -0,0 +1,54 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ql
+
+import (
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLimits_Check(t *testing.T) {
+	assert.Nil(t, Limits{}.Check(strings.Repeat("tag('a') = 'b' OR ", 1000)+"tag('a') = 'b'"))
+
+	l := Limits{MaxLength: 100, MaxNodes: 10, MaxDepth: 2}
+	assert.Nil(t, l.Check(""))
+	assert.Nil(t, l.Check("tag('a') = 'b' AND (tag('c') = 'd')"))
+	assert.Nil(t, l.Check("logID IN ['1', '2', '3', '4', '5', '6', '7']"))
+
+	err := l.Check(strings.Repeat("a", 101))
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+
+	err = l.Check("tag('a') = 'b' AND tag('c') = 'd' AND tag('e') = 'f'")
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+
+	err = l.Check("logID IN ['1', '2', '3', '4', '5', '6', '7', '8']")
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+
+	err = l.Check("((logID = '1'))")
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+
+	err = l.Check("logID = ")
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
+func TestExpression_complexity(t *testing.T) {
+	expr, err := Parse("tag('a') = 'b' OR (logID IN ['1', '2'] AND NOT (lala))")
+	assert.Nil(t, err)
+	nodes, depth := expr.complexity()
+	assert.Equal(t, 4+5+2, nodes)
+	assert.Equal(t, 3, depth)
+}
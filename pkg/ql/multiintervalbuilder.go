@@ -0,0 +1,171 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ql
+
+import (
+	"slices"
+
+	"github.com/solarisdb/solaris/pkg/intervals"
+)
+
+type (
+	// MultiParamIntervalBuilder allows to build value intervals for several parameters from the AST
+	// expression in one pass. Unlike the ParamIntervalBuilder, it tracks the conditions, which don't limit
+	// a parameter (the conditions on other parameters, the ops not requested etc.), so the intervals of
+	// a parameter always cover all the values the expression may be true for.
+	MultiParamIntervalBuilder[T, K any] struct {
+		basis intervals.Basis[T]
+		ibs   map[string]ParamIntervalBuilder[T, K]
+		// vb checks the conditions on the parameters the intervals are not built for
+		vb ParamIntervalBuilder[T, K]
+	}
+
+	// paramsIntervals contains the intervals of the parameters limited by an expression, the parameters,
+	// which are not in the map, are not limited. The exact is true if the expression is the same as
+	// the conjunction of the parameters intervals conditions.
+	paramsIntervals[T any] struct {
+		ii    map[string][]intervals.Interval[T]
+		exact bool
+	}
+)
+
+// NewMultiParamIntervalBuilder returns new MultiParamIntervalBuilder for the params and the ops provided.
+func NewMultiParamIntervalBuilder[T, K any](basis intervals.Basis[T], dialect Dialect[K], params []string, ops []string) MultiParamIntervalBuilder[T, K] {
+	ibs := make(map[string]ParamIntervalBuilder[T, K])
+	for _, p := range params {
+		ibs[p] = NewParamIntervalBuilder(basis, dialect, p, ops)
+	}
+	return MultiParamIntervalBuilder[T, K]{basis: basis, ibs: ibs, vb: NewParamIntervalBuilder(basis, dialect, "", ops)}
+}
+
+// Build returns the intervals built from the AST expression by the parameter names. The intervals of
+// a parameter are sorted by the L border. The parameters, which are not limited by the expression,
+// have the [Min, Max] interval, so the result may be intersected with other intervals safely.
+func (mb *MultiParamIntervalBuilder[T, K]) Build(expr *Expression) (map[string][]intervals.Interval[T], error) {
+	pi, err := mb.buildExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]intervals.Interval[T], len(mb.ibs))
+	for p := range mb.ibs {
+		if ii, ok := pi.ii[p]; ok {
+			res[p] = ii
+			continue
+		}
+		res[p] = []intervals.Interval[T]{mb.basis.Closed(mb.basis.Min, mb.basis.Max)}
+	}
+	return res, nil
+}
+
+func (mb *MultiParamIntervalBuilder[T, K]) buildExpr(expr *Expression) (paramsIntervals[T], error) {
+	res := paramsIntervals[T]{ii: map[string][]intervals.Interval[T]{}, exact: true}
+	for i, or := range expr.Or {
+		pi, err := mb.buildOR(or)
+		if err != nil {
+			return paramsIntervals[T]{}, err
+		}
+		if i == 0 {
+			res = pi
+			continue
+		}
+		res = mb.or(res, pi)
+	}
+	return res, nil
+}
+
+func (mb *MultiParamIntervalBuilder[T, K]) buildOR(or *OrCondition) (paramsIntervals[T], error) {
+	res := paramsIntervals[T]{ii: map[string][]intervals.Interval[T]{}, exact: true}
+	for _, and := range or.And {
+		pi, err := mb.buildXCond(and)
+		if err != nil {
+			return paramsIntervals[T]{}, err
+		}
+		res = mb.and(res, pi)
+	}
+	return res, nil
+}
+
+func (mb *MultiParamIntervalBuilder[T, K]) buildXCond(xc *XCondition) (paramsIntervals[T], error) {
+	var pi paramsIntervals[T]
+	var err error
+	if xc.Expr != nil {
+		pi, err = mb.buildExpr(xc.Expr)
+	} else {
+		pi, err = mb.buildCond(xc.Cond)
+	}
+	if err != nil || !xc.Not {
+		return pi, err
+	}
+	// only the condition on one parameter may be negated exactly,
+	// the negation of others doesn't limit the parameters
+	if !pi.exact || len(pi.ii) != 1 {
+		return paramsIntervals[T]{ii: map[string][]intervals.Interval[T]{}}, nil
+	}
+	for p, ii := range pi.ii {
+		ib := mb.ibs[p]
+		pi.ii[p] = ib.negate(ii)
+	}
+	return pi, nil
+}
+
+func (mb *MultiParamIntervalBuilder[T, K]) buildCond(cond *Condition) (paramsIntervals[T], error) {
+	res := paramsIntervals[T]{ii: map[string][]intervals.Interval[T]{}}
+	p := cond.FirstParam.Name(false)
+	ib, ok := mb.ibs[p]
+	if !ok {
+		_, err := mb.vb.buildCond(cond)
+		return res, err
+	}
+	ii, err := ib.buildCond(cond)
+	if err != nil {
+		return res, err
+	}
+	if !ib.limits(cond) {
+		return res, nil
+	}
+	res.ii[p] = ii
+	res.exact = true
+	return res, nil
+}
+
+// and returns the intervals of the conjunction of the expressions
+func (mb *MultiParamIntervalBuilder[T, K]) and(pi1, pi2 paramsIntervals[T]) paramsIntervals[T] {
+	res := paramsIntervals[T]{ii: make(map[string][]intervals.Interval[T], len(pi1.ii)), exact: pi1.exact && pi2.exact}
+	for p, ii := range pi1.ii {
+		res.ii[p] = ii
+	}
+	for p, ii := range pi2.ii {
+		if ii1, ok := res.ii[p]; ok {
+			ib := mb.ibs[p]
+			ii = ib.intersect([][]intervals.Interval[T]{ii1, ii})
+		}
+		res.ii[p] = ii
+	}
+	return res
+}
+
+// or returns the intervals of the disjunction of the expressions. A parameter is limited by the disjunction
+// only if it is limited by the both expressions.
+func (mb *MultiParamIntervalBuilder[T, K]) or(pi1, pi2 paramsIntervals[T]) paramsIntervals[T] {
+	res := paramsIntervals[T]{ii: map[string][]intervals.Interval[T]{}}
+	for p, ii1 := range pi1.ii {
+		if ii2, ok := pi2.ii[p]; ok {
+			ib := mb.ibs[p]
+			res.ii[p] = ib.union(append(slices.Clone(ii1), ii2...))
+		}
+	}
+	res.exact = pi1.exact && pi2.exact && len(pi1.ii) == 1 && len(pi2.ii) == 1 && len(res.ii) == 1
+	return res
+}
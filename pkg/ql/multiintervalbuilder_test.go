@@ -0,0 +1,84 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ql
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/intervals"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMultiIntervalBuilder = NewMultiParamIntervalBuilder(intervals.BasisString, testIntervalDialect, []string{"t", "s"}, OpsAll)
+
+func TestMultiIntervalBuilder_Build(t *testing.T) {
+	full := []intervals.Interval[string]{intervals.BasisString.Closed("", string(utf8.MaxRune))}
+	for _, tc := range []struct {
+		expr string
+		t, s []intervals.Interval[string]
+	}{
+		{"", full, full},
+		{"t > 'b' AND s = 'a'", []intervals.Interval[string]{intervals.BasisString.OpenL("b", string(utf8.MaxRune))},
+			[]intervals.Interval[string]{intervals.BasisString.Closed("a", "a")}},
+		{"(t >= 'b' AND t < 'c') OR (t BETWEEN 'k' AND 'm' AND s != 'a')",
+			[]intervals.Interval[string]{intervals.BasisString.OpenR("b", "c"), intervals.BasisString.Closed("k", "m")}, full},
+		{"t IN ('a', 'c') AND s < 'c' AND s > 'a'", []intervals.Interval[string]{intervals.BasisString.Closed("a", "a"),
+			intervals.BasisString.Closed("c", "c")}, []intervals.Interval[string]{intervals.BasisString.Open("a", "c")}},
+		{"t LIKE 'ab%' AND s = 'a'", []intervals.Interval[string]{intervals.BasisString.OpenR("ab", "ac")},
+			[]intervals.Interval[string]{intervals.BasisString.Closed("a", "a")}},
+		// the patterns with the wildcards not at the end don't limit the parameter
+		{"t LIKE '%b' AND s = 'a'", full, []intervals.Interval[string]{intervals.BasisString.Closed("a", "a")}},
+		{"NOT t LIKE 'a%b'", full, full},
+		// the contradiction limits the parameter to nothing
+		{"t < 'b' AND t > 'c'", []intervals.Interval[string]{}, full},
+		// the negation of the conditions on the different parameters doesn't limit them
+		{"NOT (t < 'b' AND s = 'a')", full, full},
+		{"NOT (t < 'b' OR t = 'c') AND s = 'a'", []intervals.Interval[string]{intervals.BasisString.OpenR("b", "c"),
+			intervals.BasisString.OpenL("c", string(utf8.MaxRune))}, []intervals.Interval[string]{intervals.BasisString.Closed("a", "a")}},
+	} {
+		expr, err := Parse(tc.expr)
+		assert.Nil(t, err)
+		ii, err := testMultiIntervalBuilder.Build(expr)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(ii))
+		if len(tc.t) == 0 {
+			assert.Empty(t, ii["t"], tc.expr)
+		} else {
+			assert.Equal(t, tc.t, ii["t"], tc.expr)
+		}
+		assert.Equal(t, tc.s, ii["s"], tc.expr)
+	}
+
+	// the single param builder gives the same intervals for the limited param
+	expr, err := Parse("t > 'b' AND s = 'a' AND t != 'c'")
+	assert.Nil(t, err)
+	ii, err := testMultiIntervalBuilder.Build(expr)
+	assert.Nil(t, err)
+	tii, err := testIntervalBuilder.Build(expr)
+	assert.Nil(t, err)
+	assert.Equal(t, tii, ii["t"])
+
+	expr, err = Parse("s BETWEEN 'b' AND 'a'")
+	assert.Nil(t, err)
+	_, err = testMultiIntervalBuilder.Build(expr)
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+
+	expr, err = Parse("unknown = 'a'")
+	assert.Nil(t, err)
+	_, err = testMultiIntervalBuilder.Build(expr)
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
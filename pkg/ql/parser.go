@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/solarisdb/solaris/golibs/errors"
 	"strings"
 )
 
@@ -40,11 +41,38 @@ type (
 	}
 
 	// Condition is a unary or binary logical operation which has first mandatory param and
-	// optional operation and second param
+	// optional operation and second param. The IN and NOT IN operations expect the array of
+	// constants, which may be specified either in square brackets or in parentheses. The BETWEEN
+	// and NOT BETWEEN operations have the Range instead of the second param.
 	Condition struct {
 		FirstParam  Param  `  @@`
-		Op          string ` {@("<"|">"|">="|"<="|"!="|"="|"IN"|"LIKE")`
-		SecondParam *Param ` @@}`
+		Range       *Range ` [ @@`
+		Op          string ` | (@("<"|">"|">="|"<="|"!="|"="|"IN"|"LIKE"|"NOT" "IN")`
+		SecondParam *Param ` @@)+ ]`
+	}
+
+	// ParseError describes the position of the expression syntax error. The error is
+	// errors.ErrInvalid, so it may be checked with errors.Is(err, errors.ErrInvalid).
+	ParseError struct {
+		// Expr is the expression failed to parse
+		Expr string `json:"-"`
+		// Offset is the byte offset of the error in the expression
+		Offset int `json:"offset"`
+		// Line and Column are the error line and column, both start from 1
+		Line   int `json:"line"`
+		Column int `json:"column"`
+		// Token is the unexpected token text, it is empty if the expression ends unexpectedly
+		Token string `json:"token"`
+		// Msg describes the error
+		Msg string `json:"msg"`
+	}
+
+	// Range is an AST element which describes the bounds of the BETWEEN (or NOT BETWEEN) operation,
+	// both bounds are included.
+	Range struct {
+		Not  bool  ` @"NOT"? "BETWEEN"`
+		From Param ` @@ "AND"`
+		To   Param ` @@`
 	}
 
 	// Param describes a parameter either a constant (string or number), function, identifier or an array of constants
@@ -52,7 +80,7 @@ type (
 		Const      *Const    ` @@`
 		Function   *Function ` | @@`
 		Identifier string    ` | @Ident`
-		Array      []*Const  `|"[" (@@ {"," @@})?"]"`
+		Array      []*Const  `|"[" (@@ {"," @@})?"]" | "(" (@@ {"," @@})? ")"`
 	}
 
 	// Const contains the constant either string or float32 value
@@ -70,7 +98,7 @@ type (
 
 var (
 	sqlLexer = lexer.MustSimple([]lexer.SimpleRule{
-		{`Keyword`, `(?i)\b(AND|OR|NOT|IN|LIKE)\b`},
+		{`Keyword`, `(?i)\b(AND|OR|NOT|IN|LIKE|BETWEEN)\b`},
 		{`Ident`, `[a-zA-Z_][a-zA-Z0-9_]*`},
 		{`Number`, `[-+]?\d*\.?\d+([eE][-+]?\d+)?`},
 		{`String`, `'[^']*'|"[^"]*"`},
@@ -82,6 +110,8 @@ var (
 		participle.Lexer(sqlLexer),
 		participle.Unquote("String"),
 		participle.CaseInsensitive("Keyword"),
+		// the arrays in parentheses look like the grouped expressions, so the parser needs to look ahead
+		participle.UseLookahead(1024),
 	)
 )
 
@@ -149,15 +179,93 @@ func (c Const) Value() string {
 	return fmt.Sprintf("%f", *c.Number)
 }
 
-// Parse parses the expr and in case of success returns AST
+// Parse parses the expr and in case of success returns AST. The keyword operations in the AST are
+// in the upper case, the NOT IN operation is "NOT IN". The conditions with the Range have the
+// "BETWEEN" or "NOT BETWEEN" operation.
 func Parse(expr string) (*Expression, error) {
-	expr = strings.TrimSpace(expr)
-	if len(expr) == 0 {
+	if len(strings.TrimSpace(expr)) == 0 {
 		return &Expression{}, nil
 	}
 	e, err := parser.ParseString("", expr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse expression=%q: %w", expr, err)
+		return nil, newParseError(expr, err)
 	}
+	e.normalize()
 	return e, nil
 }
+
+// newParseError returns the ParseError for the participle parser error err
+func newParseError(expr string, err error) *ParseError {
+	pe := &ParseError{Expr: expr, Offset: len(expr), Line: 1, Column: len(expr) + 1, Msg: err.Error()}
+	var perr participle.Error
+	if !errors.As(err, &perr) {
+		return pe
+	}
+	pos := perr.Position()
+	pe.Offset, pe.Line, pe.Column, pe.Msg = pos.Offset, pos.Line, pos.Column, perr.Message()
+	pe.Token = tokenAt(expr, pos.Offset)
+	return pe
+}
+
+// tokenAt returns the text of the token, which starts at the offset of the expression. If the token
+// cannot be recognized, the rest of the word is returned.
+func tokenAt(expr string, offset int) string {
+	if offset >= len(expr) {
+		return ""
+	}
+	if l, err := sqlLexer.LexString("", expr[offset:]); err == nil {
+		if t, err := l.Next(); err == nil && !t.EOF() {
+			return t.Value
+		}
+	}
+	tok, _, _ := strings.Cut(expr[offset:], " ")
+	return tok
+}
+
+// Error implements error
+func (pe *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse expression=%q at %d:%d: %s", pe.Expr, pe.Line, pe.Column, pe.Msg)
+}
+
+// Unwrap makes the ParseError errors.ErrInvalid
+func (pe *ParseError) Unwrap() error {
+	return errors.ErrInvalid
+}
+
+// normalize brings the conditions operations to the upper case
+// Uses returns true if the expression has a condition with the parameter id (see Param.ID)
+func (e *Expression) Uses(id string) bool {
+	for _, or := range e.Or {
+		for _, xc := range or.And {
+			if xc.Expr != nil && xc.Expr.Uses(id) {
+				return true
+			}
+			if xc.Cond != nil && (xc.Cond.FirstParam.ID() == id || (xc.Cond.SecondParam != nil && xc.Cond.SecondParam.ID() == id)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e *Expression) normalize() {
+	for _, or := range e.Or {
+		for _, xc := range or.And {
+			if xc.Expr != nil {
+				xc.Expr.normalize()
+			}
+			if xc.Cond != nil && xc.Cond.Range != nil {
+				xc.Cond.Op = "BETWEEN"
+				if xc.Cond.Range.Not {
+					xc.Cond.Op = "NOT BETWEEN"
+				}
+			} else if xc.Cond != nil && xc.Cond.Op != "" {
+				op := strings.ToUpper(xc.Cond.Op)
+				if op == "NOTIN" {
+					op = "NOT IN"
+				}
+				xc.Cond.Op = op
+			}
+		}
+	}
+}
@@ -15,7 +15,9 @@
 package ql
 
 import (
+	"fmt"
 	"github.com/solarisdb/solaris/golibs/cast"
+	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -89,6 +91,80 @@ func TestExpressions(t *testing.T) {
 	testOk(t, "1234 != 1234 and f()")
 	testOk(t, "1234 != 1234 and (f(1234, var2, f2(34, f1())) or var1 = 'sdf')")
 	testOk(t, "f1('abc') in [1,2,3]")
+	testOk(t, "f1('abc') in (1,2,3)")
+	testOk(t, "('abc' = f1('abc')) and f1('abc') not in ('a', 'b')")
+	testOk(t, "f1('abc') between 1 and f2() and f1('abc') not between 'a' and 'b'")
+}
+
+func TestParseIn(t *testing.T) {
+	expr, err := Parse("t in ('a', 'b') AND t Not In ['c']")
+	assert.Nil(t, err)
+
+	cond := expr.Or[0].And[0].Cond
+	assert.Equal(t, "IN", cond.Op)
+	assert.Equal(t, ArrayParamID, cond.SecondParam.ID())
+	assert.Equal(t, "[a, b]", cond.SecondParam.Name(true))
+
+	cond = expr.Or[0].And[1].Cond
+	assert.Equal(t, "NOT IN", cond.Op)
+	assert.Equal(t, "[c]", cond.SecondParam.Name(true))
+
+	// the parentheses still group the expressions
+	expr, err = Parse("('a' = t) OR (t IN ())")
+	assert.Nil(t, err)
+	assert.Equal(t, "=", expr.Or[0].And[0].Expr.Or[0].And[0].Cond.Op)
+	assert.Empty(t, expr.Or[1].And[0].Expr.Or[0].And[0].Cond.SecondParam.Array)
+}
+
+func TestParseBetween(t *testing.T) {
+	expr, err := Parse("t between 'a' AND 'c' AND t NOT BETWEEN 'b' and 'b' OR t = 'd'")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(expr.Or))
+	assert.Equal(t, 2, len(expr.Or[0].And))
+
+	cond := expr.Or[0].And[0].Cond
+	assert.Equal(t, "BETWEEN", cond.Op)
+	assert.Nil(t, cond.SecondParam)
+	assert.Equal(t, "a", cond.Range.From.Name(false))
+	assert.Equal(t, "c", cond.Range.To.Name(false))
+
+	cond = expr.Or[0].And[1].Cond
+	assert.Equal(t, "NOT BETWEEN", cond.Op)
+	assert.True(t, cond.Range.Not)
+
+	_, err = Parse("t BETWEEN 'a'")
+	assert.NotNil(t, err)
+}
+
+func TestParseError(t *testing.T) {
+	for _, tc := range []struct {
+		expr                 string
+		offset, line, column int
+		token                string
+	}{
+		{"a = 'b' b", 8, 1, 9, "b"},
+		{"a = 'b' OR 'c' 'd'", 15, 1, 16, "'d'"},
+		{"a IN ('a',)", 10, 1, 11, ")"},
+		{"a = 'x'\nAND = 3", 12, 2, 5, "="},
+		// the lexer errors
+		{"a ~ b", 2, 1, 3, "~"},
+		{"a = 'abc", 4, 1, 5, "'abc"},
+		// the unexpected end of the expression
+		{"a =", 3, 1, 4, ""},
+		{"  (a = 'b'", 10, 1, 11, ""},
+	} {
+		_, err := Parse(tc.expr)
+		assert.True(t, errors.Is(err, errors.ErrInvalid), tc.expr)
+		var pe *ParseError
+		if !assert.True(t, errors.As(err, &pe), tc.expr) {
+			continue
+		}
+		assert.Equal(t, tc.offset, pe.Offset, tc.expr)
+		assert.Equal(t, tc.line, pe.Line, tc.expr)
+		assert.Equal(t, tc.column, pe.Column, tc.expr)
+		assert.Equal(t, tc.token, pe.Token, tc.expr)
+		assert.Contains(t, err.Error(), fmt.Sprintf("%d:%d", tc.line, tc.column), tc.expr)
+	}
 }
 
 func testOk(t *testing.T, e string) {
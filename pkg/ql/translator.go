@@ -27,7 +27,7 @@ func (tr Translator[T]) Translate(sb *strings.Builder, expr string) error {
 	}
 	e, err := Parse(expr)
 	if err != nil {
-		return fmt.Errorf("failed to parse expression=%q: %w", expr, err)
+		return err
 	}
 	if err = tr.Expression2Sql(sb, e); err != nil {
 		return fmt.Errorf("failed to translate expression=%q: %w", expr, err)
@@ -94,6 +94,9 @@ func (tr Translator[T]) Condition2Sql(sb *strings.Builder, c *Condition) error {
 	if dp1.Flags&PfNop != 0 {
 		return fmt.Errorf("parameter %s cannot be compared (%s) in the condition: %w", p1.Name(false), c.Op, errors.ErrInvalid)
 	}
+	if c.Range != nil {
+		return tr.between2Sql(sb, c, dp1)
+	}
 
 	// param2
 	p2 := c.SecondParam
@@ -121,9 +124,9 @@ func (tr Translator[T]) Condition2Sql(sb *strings.Builder, c *Condition) error {
 		if dp2.Flags&PfComparable == 0 {
 			return fmt.Errorf("the second parameter %s is not applicable for the operation %s: %w", p2.Name(false), c.Op, errors.ErrInvalid)
 		}
-	case "IN":
+	case "IN", "NOT IN":
 		if dp1.Flags&PfInLike == 0 {
-			return fmt.Errorf("the first parameter %s is not applicable for the IN : %w", p1.Name(false), errors.ErrInvalid)
+			return fmt.Errorf("the first parameter %s is not applicable for the %s : %w", p1.Name(false), op, errors.ErrInvalid)
 		}
 		if c.SecondParam.ID() != ArrayParamID {
 			return fmt.Errorf("the second parameter %s must be an array: %w", p2.Name(false), errors.ErrInvalid)
@@ -148,6 +151,39 @@ func (tr Translator[T]) Condition2Sql(sb *strings.Builder, c *Condition) error {
 	return tr.Param2Sql(sb, p2)
 }
 
+// between2Sql turns the condition c with the range to the "p1 [NOT] BETWEEN from AND to" query string
+func (tr Translator[T]) between2Sql(sb *strings.Builder, c *Condition, dp1 ParamDialect[T]) error {
+	p1 := c.FirstParam
+	if dp1.Flags&PfComparable == 0 {
+		return fmt.Errorf("the first parameter %s is not applicable for the operation %s: %w", p1.Name(false), c.Op, errors.ErrInvalid)
+	}
+	for _, p := range []Param{c.Range.From, c.Range.To} {
+		dp, ok := tr.dialect[p.ID()]
+		if !ok {
+			return fmt.Errorf("unknown range bound %s: %w", p.Name(false), errors.ErrInvalid)
+		}
+		if dp.Flags&PfRValue == 0 {
+			return fmt.Errorf("parameter %s cannot be the range bound: %w", p.Name(false), errors.ErrInvalid)
+		}
+		if dp.Flags&PfComparable == 0 || dp.Flags&PfNop != 0 {
+			return fmt.Errorf("the range bound %s is not applicable for the operation %s: %w", p.Name(false), c.Op, errors.ErrInvalid)
+		}
+	}
+
+	if err := tr.Param2Sql(sb, &p1); err != nil {
+		return err
+	}
+	if c.Range.Not {
+		sb.WriteString(" NOT")
+	}
+	sb.WriteString(" BETWEEN ")
+	if err := tr.Param2Sql(sb, &c.Range.From); err != nil {
+		return err
+	}
+	sb.WriteString(" AND ")
+	return tr.Param2Sql(sb, &c.Range.To)
+}
+
 // Param2Sql turns the AST object p to the query string according to the dialect of the translator
 func (tr Translator[T]) Param2Sql(sb *strings.Builder, p *Param) error {
 	dp, ok := tr.dialect[p.ID()]
@@ -13,4 +13,20 @@ func TestTranslateDialect(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Nil(t, tr.Expression2Sql(&sb, e))
 	assert.Equal(t, "tags ->> 'abc' = tags ->> 'def' AND (id = '123' OR id IN ('g', '88')) OR tags ->> 'f3' LIKE 'aaa%'", sb.String())
+
+	sb.Reset()
+	e, err = Parse("tag('abc') NOT BETWEEN 'a' AND 'c' or logID between 'g' and '88'")
+	assert.Nil(t, err)
+	assert.Nil(t, tr.Expression2Sql(&sb, e))
+	assert.Equal(t, "tags ->> 'abc' NOT BETWEEN 'a' AND 'c' OR id BETWEEN 'g' AND '88'", sb.String())
+}
+
+func TestTranslateSqliteDialect(t *testing.T) {
+	tr := NewTranslator(LogsCondSqliteTranslateDialect)
+	var sb strings.Builder
+	assert.Nil(t, tr.Translate(&sb, "tag('a.b') = 'c' and logID in ['g', '88']"))
+	assert.Equal(t, `json_extract(tags, '$."a.b"') = 'c' AND id IN ('g', '88')`, sb.String())
+
+	sb.Reset()
+	assert.NotNil(t, tr.Translate(&sb, "tag('a', 'b') = 'c'"))
 }
@@ -19,9 +19,18 @@ import (
 	"fmt"
 	"github.com/solarisdb/solaris/golibs/cast"
 	"github.com/solarisdb/solaris/golibs/config"
+	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/logging"
 	"github.com/solarisdb/solaris/golibs/transport"
+	"github.com/solarisdb/solaris/pkg/api"
 	"github.com/solarisdb/solaris/pkg/db"
+	"github.com/solarisdb/solaris/pkg/ql"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
+	"github.com/solarisdb/solaris/pkg/storage/logfs"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type (
@@ -29,8 +38,26 @@ type (
 	Config struct {
 		// GrpcTransport specifies grpc transport configuration
 		GrpcTransport *transport.Config
+		// MaxConcurrentRequests defines how many gRPC unary and HTTP requests may be served at a time,
+		// the requests above the limit wait in the per method queues. Zero value means no limit.
+		MaxConcurrentRequests int
+		// MaxConcurrentStreams defines how many gRPC streams may be served at a time. The streams may last
+		// long, so they are limited separately from the other requests. Zero value means no limit.
+		MaxConcurrentStreams int
+		// MaxQueuedRequests defines how many requests of one method may wait to be served, the requests
+		// above the limit are rejected with the ResourceExhausted code (429 for HTTP) right away
+		MaxQueuedRequests int
+		// RequestsQueueTimeout defines how long a request may wait to be served, the request is rejected
+		// with the ResourceExhausted code (429 for HTTP) when the time is over
+		RequestsQueueTimeout time.Duration
 		// HttpPort defines the port for listening incoming HTTP connections
 		HttpPort int
+		// HttpGzip enables the gzip compression of the HTTP responses for the clients, which accept it
+		HttpGzip bool
+		// HttpGzipMinSize defines the HTTP response size (in bytes), starting from which the response is compressed
+		HttpGzipMinSize int
+		// HttpMetrics enables the Prometheus metrics exposed on /metrics of the HttpPort
+		HttpMetrics bool
 		// DB specifies DBConn for storing the logs and chunks metadata
 		DB *db.DBConn
 		// LocalDBFilePath specifies where the logs data is stored
@@ -38,18 +65,171 @@ type (
 		// MaxOpenedLogFiles allows to control number of files opened at a time to work with the solaris data
 		// Increasing the number allows to increase the system performance for accessing to random group of logs
 		MaxOpenedLogFiles int
+		// OpenedLogFilesIdleTimeout defines how long a not used log file is kept opened. Zero value means
+		// the files are closed only when the MaxOpenedLogFiles limit is reached.
+		OpenedLogFilesIdleTimeout time.Duration
+		// SkipRecordsCRCCheck disables the records checksums verification on read, what saves some CPU for the reads
+		SkipRecordsCRCCheck bool
+		// LogFilesReadAheadSize defines how many bytes of a log file are requested from the disk ahead of
+		// the records scan position. Zero value disables the read-ahead.
+		LogFilesReadAheadSize int
+		// MinFreeDiskSpace defines the free space (in bytes) on the LocalDBFilePath disk, below which
+		// the appends are rejected. Reads and deletes are still allowed. Zero value means the appends are never
+		// rejected. The free space is exposed by the solaris_disk_free_bytes metric.
+		MinFreeDiskSpace int64
+		// MaxRecordsLimit defines the maximum number of records a records query may return at a time
+		MaxRecordsLimit int
+		// MaxBunchSize defines the maximum total size (in bytes) of the records payloads a records query
+		// may return at a time
+		MaxBunchSize int
+		// MaxLocks defines how many different logs may be read at a time, the different logs appends
+		// are not limited by the value
+		MaxLocks int
+		// MaxChunksPerLog defines the maximum number of chunks a log may have, the appends above the limit are
+		// rejected. Zero value means no limit.
+		MaxChunksPerLog int
+		// ChunksSoftLimitPct defines the percentage of MaxChunksPerLog, starting from which the appends
+		// results contain the warning that the log is close to the limit
+		ChunksSoftLimitPct int
+		// AtomicAppends defines whether the records batch is written completely or not written at all, if
+		// the AppendRecords request doesn't specify the mode. Otherwise, the records written before an error are kept.
+		AtomicAppends bool
+		// RecordsSequences enables the records sequence numbers, so the log records are numbered 1, 2, 3...
+		// in the order they are appended and could be read starting from a sequence number
+		RecordsSequences bool
+		// ChunksCompression defines the codec the new chunks records payloads are compressed with.
+		// The empty value means no compression, "zstd" is the only supported codec.
+		ChunksCompression string
+		// LogFilesSyncPolicy defines when the appended records are flushed to the disk: "none" leaves it to
+		// the OS, "per-append" flushes every append and "interval" flushes by LogFilesSyncInterval or
+		// LogFilesSyncBytes. The records are kept on the process crash with any policy, the policy defines
+		// what may be lost on the OS crash.
+		LogFilesSyncPolicy string
+		// LogFilesSyncInterval defines how long the appended records may stay not flushed for the "interval" policy
+		LogFilesSyncInterval time.Duration
+		// LogFilesSyncBytes defines how many bytes may be appended to a log file before the flush for
+		// the "interval" policy. Zero value means the files are flushed by the LogFilesSyncInterval only.
+		LogFilesSyncBytes int
+		// CompactMaxChunkSize defines the chunks size (in bytes), below which the adjacent log chunks are merged
+		// in background, when the log chunk is sealed by an append. Zero value disables the background compaction.
+		CompactMaxChunkSize int64
+		// CompactMaxChunks defines how many chunks may be merged by one background compaction run
+		CompactMaxChunks int
+		// MaxPruneIntervals defines how many records creation time intervals of a query condition may be used
+		// to skip the log chunks. The conditions with more intervals are checked against every record
+		// within the intervals bounds. Zero value means no limit.
+		MaxPruneIntervals int
+		// MetaCommitTimeout defines how long the written log chunks may be committed to the logs metadata storage.
+		// The append fails and its records are rolled back, if the chunks are not committed in time. Zero value
+		// means no timeout.
+		MetaCommitTimeout time.Duration
+		// MigrateWorkers defines how many logs may be migrated in parallel by the background job, which rewrites
+		// the chunks written in an outdated format (or with the compression other than ChunksCompression) into the
+		// chunks of the current format. Zero value disables the migration.
+		MigrateWorkers int
+		// MigrateChunksPerSecond limits the number of chunks rewritten by the migration per second. Zero value means no limit.
+		MigrateChunksPerSecond int
+		// MigrateInterval defines the pause between the migration passes over all the logs
+		MigrateInterval time.Duration
+		// RetentionSweepInterval defines the pause between the passes over all the logs, which remove the records
+		// exceeding the logs retention policies (see Log.RetentionMaxAge). Zero value disables the policies enforcement.
+		RetentionSweepInterval time.Duration
+		// RetentionSweepBatchSize defines how many logs are read from the DB at a time by the retention sweeper
+		RetentionSweepBatchSize int
+		// S3Bucket specifies the AWS S3 bucket the chunks are replicated to. If it is empty, the chunks are
+		// kept on the local file-system only. The credentials are taken from the AWS environment variables.
+		S3Bucket string
+		// S3Region specifies the AWS region of the S3Bucket
+		S3Region string
+		// S3Endpoint allows to use an S3 compatible storage (MinIO, for example) instead of AWS S3
+		S3Endpoint string
+		// ReplicaUploadWorkers defines how many sealed chunks may be uploaded to the remote storage in parallel.
+		// Zero value disables the sealed chunks uploads.
+		ReplicaUploadWorkers int
+		// ReplicaRetries defines how many times a failed chunk upload or download is retried
+		ReplicaRetries int
+		// ReplicaRetryBackoff defines the delay before the first retry, every next delay is doubled
+		ReplicaRetryBackoff time.Duration
+		// MetaCacheTTL defines how long the cached logs and their chunks lists are used before they are
+		// read from the DB again, so the changes made by other nodes are seen. Zero value means they don't expire.
+		MetaCacheTTL time.Duration
+		// MetaChunksCacheMaxBytes bounds the cached logs chunks lists by their approximate total size in bytes.
+		// Zero value means the fixed number of the lists is cached regardless of their size.
+		MetaChunksCacheMaxBytes int64
+		// MetaCacheNegativeTTL defines how long the log IDs, which are not found in the DB, are cached as not
+		// existing, so the requests to a missing log don't reach the DB every time. Zero value disables the caching.
+		MetaCacheNegativeTTL time.Duration
+		// LogsCondLimits defines the limits for the logs conditions length and complexity,
+		// the requests with the conditions exceeding the limits are rejected
+		LogsCondLimits ql.Limits
+		// MaxCompiledConditions defines how many records conditions compiled by CompileCondition may be kept at a time
+		MaxCompiledConditions int
+		// CompiledConditionTTL defines how long a compiled records condition is kept since it was compiled
+		CompiledConditionTTL time.Duration
+		// CheckLogsExist specifies that the records requests for the logs which don't exist fail with
+		// the not found error instead of returning the empty result
+		CheckLogsExist bool
+		// DefaultFieldStatsSample defines the number of records sampled by FieldStats if the request doesn't specify it
+		DefaultFieldStatsSample int
+		// MaxFieldStatsSample defines the maximum number of records FieldStats may sample
+		MaxFieldStatsSample int
+		// MaxFieldStatsTopN defines the maximum number of the most frequent values FieldStats may return per field
+		MaxFieldStatsTopN int
+		// MaxRecordsSlackPct defines how many records over the log maxRecords (in percents of it) the appends may
+		// leave in the capped log, so the oldest log chunk is not rewritten by every append
+		MaxRecordsSlackPct int
+		// RecordsMasterKey enables the records payloads and attributes encryption if specified. The per-log keys
+		// are derived from the master key, so the key must not be changed once the data is written.
+		RecordsMasterKey string
+		// AuthTokens enables the bearer tokens authentication of the gRPC and HTTP requests if specified. It contains
+		// the clients IDs by their tokens. The health checks are served without the authentication.
+		AuthTokens map[string]string
+		// AdminPrincipals contains the IDs of the clients (see AuthTokens), which may run the administrative
+		// gRPC requests: SetReadOnly and GetStorageLayout. Other clients are denied to run them.
+		AdminPrincipals []string
+		// ReadOnly starts the server in the read-only mode, when the requests changing the logs or their
+		// records are rejected. The mode may be changed at runtime by the SetReadOnly gRPC request.
+		ReadOnly bool
 	}
 )
 
 // getDefaultConfig returns the default server config
 func getDefaultConfig() *Config {
 	return &Config{
-		GrpcTransport:     transport.GetDefaultGRPCConfig(),
-		HttpPort:          8080,
-		LocalDBFilePath:   "slogs",
-		MaxOpenedLogFiles: 100,
+		GrpcTransport:           transport.GetDefaultGRPCConfig(),
+		MaxQueuedRequests:       100,
+		RequestsQueueTimeout:    5 * time.Second,
+		HttpPort:                8080,
+		HttpGzip:                true,
+		HttpGzipMinSize:         1024,
+		HttpMetrics:             true,
+		LocalDBFilePath:         "slogs",
+		MaxOpenedLogFiles:       100,
+		MinFreeDiskSpace:        100 * 1024 * 1024,
+		LogFilesSyncInterval:    chunkfs.GetDefaultConfig().SyncInterval,
+		MaxRecordsLimit:         logfs.GetDefaultConfig().MaxRecordsLimit,
+		MaxBunchSize:            logfs.GetDefaultConfig().MaxBunchSize,
+		MaxLocks:                logfs.GetDefaultConfig().MaxLocks,
+		ChunksSoftLimitPct:      logfs.GetDefaultConfig().ChunksSoftLimitPct,
+		CompactMaxChunks:        logfs.GetDefaultConfig().CompactMaxChunks,
+		MaxPruneIntervals:       logfs.GetDefaultConfig().MaxPruneIntervals,
+		MetaCommitTimeout:       logfs.GetDefaultConfig().MetaCommitTimeout,
+		MigrateChunksPerSecond:  logfs.GetDefaultMigratorConfig().ChunksPerSecond,
+		MigrateInterval:         logfs.GetDefaultMigratorConfig().Interval,
+		RetentionSweepInterval:  logfs.GetDefaultRetentionConfig().Interval,
+		RetentionSweepBatchSize: logfs.GetDefaultRetentionConfig().BatchSize,
+		ReplicaRetries:          chunkfs.GetDefaultReplicatorConfig().Retries,
+		ReplicaRetryBackoff:     chunkfs.GetDefaultReplicatorConfig().RetryBackoff,
+		LogsCondLimits:          api.GetDefaultConfig().LogsCondLimits,
+		MaxCompiledConditions:   api.GetDefaultConfig().MaxCompiledConditions,
+		CompiledConditionTTL:    api.GetDefaultConfig().CompiledConditionTTL,
+		CheckLogsExist:          api.GetDefaultConfig().CheckLogsExist,
+		DefaultFieldStatsSample: api.GetDefaultConfig().DefaultFieldStatsSample,
+		MaxFieldStatsSample:     api.GetDefaultConfig().MaxFieldStatsSample,
+		MaxFieldStatsTopN:       api.GetDefaultConfig().MaxFieldStatsTopN,
+		MaxRecordsSlackPct:      api.GetDefaultConfig().MaxRecordsSlackPct,
 		DB: &db.DBConn{
-			Driver:             "postgres",
+			Driver:             db.DriverPostgres,
 			Host:               "localhost",
 			Port:               "5432",
 			Username:           "postgres",
@@ -76,9 +256,93 @@ func BuildConfig(cfgFile string) (*Config, error) {
 	_ = e.ApplyOther(fe)
 	_ = e.ApplyEnvVariables("SOLARIS", "_")
 	cfg := e.Value()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
+// Validate checks the config values, it returns the errors.ErrInvalid listing all the problems found
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	validPort := func(p int) bool { return p > 0 && p <= math.MaxUint16 }
+
+	if c.GrpcTransport == nil {
+		problems = append(problems, "GrpcTransport must be specified")
+	} else {
+		check(validPort(c.GrpcTransport.Port), "GrpcTransport.Port=%d must be in the range [1..65535]", c.GrpcTransport.Port)
+		check(c.HttpPort != c.GrpcTransport.Port, "HttpPort=%d must differ from GrpcTransport.Port", c.HttpPort)
+	}
+	check(validPort(c.HttpPort), "HttpPort=%d must be in the range [1..65535]", c.HttpPort)
+	check(c.HttpGzipMinSize >= 0, "HttpGzipMinSize=%d must not be negative", c.HttpGzipMinSize)
+	check(c.LocalDBFilePath != "", "LocalDBFilePath must not be empty")
+	check(c.MaxOpenedLogFiles > 0, "MaxOpenedLogFiles=%d must be positive", c.MaxOpenedLogFiles)
+	check(c.MaxConcurrentRequests >= 0, "MaxConcurrentRequests=%d must not be negative", c.MaxConcurrentRequests)
+	check(c.MaxConcurrentStreams >= 0, "MaxConcurrentStreams=%d must not be negative", c.MaxConcurrentStreams)
+	check(c.MaxQueuedRequests >= 0, "MaxQueuedRequests=%d must not be negative", c.MaxQueuedRequests)
+	check(c.RequestsQueueTimeout >= 0, "RequestsQueueTimeout=%s must not be negative", c.RequestsQueueTimeout)
+	check(c.OpenedLogFilesIdleTimeout >= 0, "OpenedLogFilesIdleTimeout=%s must not be negative", c.OpenedLogFilesIdleTimeout)
+	check(c.LogFilesReadAheadSize >= 0, "LogFilesReadAheadSize=%d must not be negative", c.LogFilesReadAheadSize)
+	check(chunkfs.ValidSyncPolicy(c.LogFilesSyncPolicy), "LogFilesSyncPolicy=%q must be one of none, per-append or interval", c.LogFilesSyncPolicy)
+	check(c.LogFilesSyncInterval >= 0, "LogFilesSyncInterval=%s must not be negative", c.LogFilesSyncInterval)
+	check(c.LogFilesSyncBytes >= 0, "LogFilesSyncBytes=%d must not be negative", c.LogFilesSyncBytes)
+	check(c.MinFreeDiskSpace >= 0, "MinFreeDiskSpace=%d must not be negative", c.MinFreeDiskSpace)
+	check(c.MaxRecordsLimit > 0, "MaxRecordsLimit=%d must be positive", c.MaxRecordsLimit)
+	check(c.MaxBunchSize > 0, "MaxBunchSize=%d must be positive", c.MaxBunchSize)
+	check(c.MaxLocks > 0, "MaxLocks=%d must be positive", c.MaxLocks)
+	check(c.MaxChunksPerLog >= 0, "MaxChunksPerLog=%d must not be negative", c.MaxChunksPerLog)
+	check(c.ChunksSoftLimitPct >= 0 && c.ChunksSoftLimitPct <= 100, "ChunksSoftLimitPct=%d must be in the range [0..100]", c.ChunksSoftLimitPct)
+	check(c.CompactMaxChunkSize >= 0, "CompactMaxChunkSize=%d must not be negative", c.CompactMaxChunkSize)
+	check(c.CompactMaxChunks > 0, "CompactMaxChunks=%d must be positive", c.CompactMaxChunks)
+	check(c.MaxPruneIntervals >= 0, "MaxPruneIntervals=%d must not be negative", c.MaxPruneIntervals)
+	check(c.MetaCommitTimeout >= 0, "MetaCommitTimeout=%s must not be negative", c.MetaCommitTimeout)
+	check(c.MigrateWorkers >= 0, "MigrateWorkers=%d must not be negative", c.MigrateWorkers)
+	check(c.MigrateChunksPerSecond >= 0, "MigrateChunksPerSecond=%d must not be negative", c.MigrateChunksPerSecond)
+	check(c.MigrateInterval > 0, "MigrateInterval=%s must be positive", c.MigrateInterval)
+	check(c.RetentionSweepInterval >= 0, "RetentionSweepInterval=%s must not be negative", c.RetentionSweepInterval)
+	check(c.RetentionSweepBatchSize > 0, "RetentionSweepBatchSize=%d must be positive", c.RetentionSweepBatchSize)
+	check(c.ReplicaUploadWorkers >= 0, "ReplicaUploadWorkers=%d must not be negative", c.ReplicaUploadWorkers)
+	check(c.ReplicaRetries >= 0, "ReplicaRetries=%d must not be negative", c.ReplicaRetries)
+	check(c.ReplicaRetryBackoff >= 0, "ReplicaRetryBackoff=%s must not be negative", c.ReplicaRetryBackoff)
+	check(c.MetaCacheTTL >= 0, "MetaCacheTTL=%s must not be negative", c.MetaCacheTTL)
+	check(c.MetaChunksCacheMaxBytes >= 0, "MetaChunksCacheMaxBytes=%d must not be negative", c.MetaChunksCacheMaxBytes)
+	check(c.MetaCacheNegativeTTL >= 0, "MetaCacheNegativeTTL=%s must not be negative", c.MetaCacheNegativeTTL)
+	check(c.MaxCompiledConditions > 0, "MaxCompiledConditions=%d must be positive", c.MaxCompiledConditions)
+	check(c.CompiledConditionTTL >= 0, "CompiledConditionTTL=%s must not be negative", c.CompiledConditionTTL)
+	check(c.DefaultFieldStatsSample > 0, "DefaultFieldStatsSample=%d must be positive", c.DefaultFieldStatsSample)
+	check(c.MaxFieldStatsSample >= c.DefaultFieldStatsSample, "MaxFieldStatsSample=%d must not be less than DefaultFieldStatsSample=%d",
+		c.MaxFieldStatsSample, c.DefaultFieldStatsSample)
+	check(c.MaxFieldStatsTopN > 0, "MaxFieldStatsTopN=%d must be positive", c.MaxFieldStatsTopN)
+	check(c.MaxRecordsSlackPct >= 0, "MaxRecordsSlackPct=%d must not be negative", c.MaxRecordsSlackPct)
+
+	if c.DB == nil {
+		problems = append(problems, "DB must be specified")
+	} else {
+		check(c.DB.Driver == db.DriverPostgres || c.DB.Driver == db.DriverSqlite || c.DB.Driver == db.DriverBuntDB,
+			"DB.Driver=%q must be one of %s, %s or %s", c.DB.Driver, db.DriverPostgres, db.DriverSqlite, db.DriverBuntDB)
+		switch c.DB.Driver {
+		case db.DriverPostgres:
+			check(c.DB.Host != "", "DB.Host must not be empty")
+			port, err := strconv.Atoi(c.DB.Port)
+			check(err == nil && validPort(port), "DB.Port=%q must be a number in the range [1..65535]", c.DB.Port)
+			check(c.DB.Username != "", "DB.Username must not be empty")
+			check(c.DB.DBName != "", "DB.DBName must not be empty")
+		case db.DriverSqlite:
+			check(c.DB.DBName != "", "DB.DBName must not be empty")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("the config is not valid: %s: %w", strings.Join(problems, "; "), errors.ErrInvalid)
+	}
+	return nil
+}
+
 // String implements fmt.Stringify interface in a pretty console form
 func (c *Config) String() string {
 	b, _ := json.MarshalIndent(*c, "", "  ")
@@ -15,11 +15,15 @@
 package server
 
 import (
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/db"
+	"github.com/solarisdb/solaris/pkg/storage/logfs"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestBuildConfig_nofile(t *testing.T) {
@@ -43,6 +47,96 @@ grpctransport:
 	assert.Equal(t, "hoho", cfg.GrpcTransport.Network)
 }
 
+func TestBuildConfig_localLog(t *testing.T) {
+	cfg, err := BuildConfig("")
+	assert.Nil(t, err)
+	lcfg := localLogConfig(cfg)
+	assert.Equal(t, logfs.GetDefaultConfig().MaxRecordsLimit, lcfg.MaxRecordsLimit)
+	assert.Equal(t, logfs.GetDefaultConfig().MaxBunchSize, lcfg.MaxBunchSize)
+	assert.Equal(t, logfs.GetDefaultConfig().MaxLocks, lcfg.MaxLocks)
+	assert.Equal(t, logfs.GetDefaultConfig().MetaCommitTimeout, lcfg.MetaCommitTimeout)
+
+	t.Setenv("SOLARIS_MAXRECORDSLIMIT", "500")
+	t.Setenv("SOLARIS_MAXBUNCHSIZE", "65536")
+	t.Setenv("SOLARIS_MAXLOCKS", "100")
+	t.Setenv("SOLARIS_METACOMMITTIMEOUT", "10000000000")
+	cfg, err = BuildConfig("")
+	assert.Nil(t, err)
+	assert.Equal(t, 500, cfg.MaxRecordsLimit)
+	assert.Equal(t, 65536, cfg.MaxBunchSize)
+	assert.Equal(t, 100, cfg.MaxLocks)
+	lcfg = localLogConfig(cfg)
+	assert.Equal(t, 500, lcfg.MaxRecordsLimit)
+	assert.Equal(t, 65536, lcfg.MaxBunchSize)
+	assert.Equal(t, 100, lcfg.MaxLocks)
+	assert.Equal(t, 10*time.Second, lcfg.MetaCommitTimeout)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		errs   []string
+	}{
+		{name: "default", modify: func(c *Config) {}},
+		{name: "same ports", modify: func(c *Config) { c.HttpPort = c.GrpcTransport.Port },
+			errs: []string{"HttpPort=50051 must differ from GrpcTransport.Port"}},
+		{name: "bad ports", modify: func(c *Config) { c.HttpPort = 0; c.GrpcTransport.Port = 70000 },
+			errs: []string{"HttpPort=0 must be in the range", "GrpcTransport.Port=70000 must be in the range"}},
+		{name: "no grpc transport", modify: func(c *Config) { c.GrpcTransport = nil },
+			errs: []string{"GrpcTransport must be specified"}},
+		{name: "empty path", modify: func(c *Config) { c.LocalDBFilePath = "" },
+			errs: []string{"LocalDBFilePath must not be empty"}},
+		{name: "negative limits", modify: func(c *Config) { c.MaxOpenedLogFiles = -1; c.MaxChunksPerLog = -1; c.MetaCacheTTL = -time.Second },
+			errs: []string{"MaxOpenedLogFiles=-1 must be positive", "MaxChunksPerLog=-1 must not be negative", "MetaCacheTTL=-1s must not be negative"}},
+		{name: "zero limits", modify: func(c *Config) { c.MaxRecordsLimit = 0; c.MaxBunchSize = 0; c.MaxLocks = 0 },
+			errs: []string{"MaxRecordsLimit=0 must be positive", "MaxBunchSize=0 must be positive", "MaxLocks=0 must be positive"}},
+		{name: "concurrent requests", modify: func(c *Config) { c.MaxConcurrentRequests = -1; c.MaxConcurrentStreams = -2; c.MaxQueuedRequests = -3 },
+			errs: []string{"MaxConcurrentRequests=-1 must not be negative", "MaxConcurrentStreams=-2 must not be negative", "MaxQueuedRequests=-3 must not be negative"}},
+		{name: "migrate interval", modify: func(c *Config) { c.MigrateInterval = 0 },
+			errs: []string{"MigrateInterval=0s must be positive"}},
+		{name: "soft limit pct", modify: func(c *Config) { c.ChunksSoftLimitPct = 101 },
+			errs: []string{"ChunksSoftLimitPct=101 must be in the range [0..100]"}},
+		{name: "sync policy", modify: func(c *Config) { c.LogFilesSyncPolicy = "always" },
+			errs: []string{`LogFilesSyncPolicy="always" must be one of none, per-append or interval`}},
+		{name: "field stats sample", modify: func(c *Config) { c.MaxFieldStatsSample = c.DefaultFieldStatsSample - 1 },
+			errs: []string{"must not be less than DefaultFieldStatsSample"}},
+		{name: "field stats topN", modify: func(c *Config) { c.MaxFieldStatsTopN = 0 },
+			errs: []string{"MaxFieldStatsTopN=0 must be positive"}},
+		{name: "no db", modify: func(c *Config) { c.DB = nil },
+			errs: []string{"DB must be specified"}},
+		{name: "incomplete db", modify: func(c *Config) { c.DB.Host = ""; c.DB.Port = "pg"; c.DB.DBName = "" },
+			errs: []string{"DB.Host must not be empty", `DB.Port="pg" must be a number`, "DB.DBName must not be empty"}},
+		{name: "embedded db", modify: func(c *Config) { *c.DB = db.DBConn{Driver: db.DriverBuntDB} }},
+		{name: "sqlite db", modify: func(c *Config) { *c.DB = db.DBConn{Driver: db.DriverSqlite, DBName: "/tmp/solaris.db"} }},
+		{name: "sqlite no file", modify: func(c *Config) { *c.DB = db.DBConn{Driver: db.DriverSqlite} },
+			errs: []string{"DB.DBName must not be empty"}},
+		{name: "unknown db", modify: func(c *Config) { c.DB.Driver = "mysql" },
+			errs: []string{`DB.Driver="mysql" must be one of postgres, sqlite or buntdb`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := getDefaultConfig()
+			tt.modify(c)
+			err := c.Validate()
+			if len(tt.errs) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, errors.ErrInvalid))
+			for _, e := range tt.errs {
+				assert.Contains(t, err.Error(), e)
+			}
+		})
+	}
+}
+
+func TestBuildConfig_invalid(t *testing.T) {
+	t.Setenv("SOLARIS_HTTPPORT", "50051")
+	_, err := BuildConfig("")
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
 func createFile(name, data string) {
 	f, _ := os.Create(name)
 	f.WriteString(data)
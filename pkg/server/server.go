@@ -17,22 +17,30 @@ package server
 import (
 	"context"
 	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/files"
 	"github.com/solarisdb/solaris/golibs/logging"
 	"github.com/solarisdb/solaris/golibs/sss/inmem"
+	"github.com/solarisdb/solaris/golibs/sss/s3"
+	"github.com/solarisdb/solaris/pkg/admission"
 	"github.com/solarisdb/solaris/pkg/api"
 	"github.com/solarisdb/solaris/pkg/api/rest"
+	"github.com/solarisdb/solaris/pkg/auth"
+	"github.com/solarisdb/solaris/pkg/db"
 	"github.com/solarisdb/solaris/pkg/grpc"
+	"github.com/solarisdb/solaris/pkg/health"
 	"github.com/solarisdb/solaris/pkg/http"
+	"github.com/solarisdb/solaris/pkg/metrics"
+	"github.com/solarisdb/solaris/pkg/storage/buntdb"
 	"github.com/solarisdb/solaris/pkg/storage/cache"
 	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
 	"github.com/solarisdb/solaris/pkg/storage/logfs"
 	"github.com/solarisdb/solaris/pkg/storage/postgres"
 	"github.com/solarisdb/solaris/pkg/version"
-	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"path/filepath"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/logrange/linker"
@@ -51,10 +59,26 @@ func Run(ctx context.Context, cfg *Config) error {
 		return err
 	}
 
+	// Db
+	ms, mdb, err := newMetaStorage(ctx, cfg.DB)
+	if err != nil {
+		return err
+	}
+
+	// the readiness checks
+	hccfg := health.GetDefaultConfig()
+	hccfg.DataPath = cfg.LocalDBFilePath
+	hc := health.NewChecker(hccfg, mdb)
+
 	// gRPC server
-	gsvc := api.NewService()
+	gsvc := api.NewService(api.Config{LogsCondLimits: cfg.LogsCondLimits,
+		MaxCompiledConditions: cfg.MaxCompiledConditions, CompiledConditionTTL: cfg.CompiledConditionTTL,
+		CheckLogsExist: cfg.CheckLogsExist, DefaultFieldStatsSample: cfg.DefaultFieldStatsSample,
+		MaxFieldStatsSample: cfg.MaxFieldStatsSample, MaxFieldStatsTopN: cfg.MaxFieldStatsTopN,
+		MaxRecordsSlackPct: cfg.MaxRecordsSlackPct, AdminPrincipals: cfg.AdminPrincipals, ReadOnly: cfg.ReadOnly,
+		MaxRecordsLimit: cfg.MaxRecordsLimit, MaxBunchSize: cfg.MaxBunchSize})
 	var grpcRegF grpc.RegisterF = func(gs *ggrpc.Server) error {
-		grpc_health_v1.RegisterHealthServer(gs, health.NewServer())
+		grpc_health_v1.RegisterHealthServer(gs, hc.HealthServer())
 		solaris.RegisterServiceServer(gs, gsvc)
 		return nil
 	}
@@ -63,23 +87,83 @@ func Run(ctx context.Context, cfg *Config) error {
 	rst := rest.New(gsvc)
 
 	// chunkfs
-	provider := chunkfs.NewProvider(cfg.LocalDBFilePath, cfg.MaxOpenedLogFiles, chunkfs.GetDefaultConfig())
-	replicator := chunkfs.NewReplicator(provider.GetFileNameByID)
-
-	// Db
-	db := postgres.MustGetDb(ctx, cfg.DB)
+	ccfg := chunkfs.GetDefaultConfig()
+	ccfg.SkipCRCCheck = cfg.SkipRecordsCRCCheck
+	ccfg.Compression = cfg.ChunksCompression
+	ccfg.OpenedIdleTimeout = cfg.OpenedLogFilesIdleTimeout
+	ccfg.ReadAheadSize = cfg.LogFilesReadAheadSize
+	ccfg.SyncPolicy = cfg.LogFilesSyncPolicy
+	ccfg.SyncInterval = cfg.LogFilesSyncInterval
+	ccfg.SyncBytes = cfg.LogFilesSyncBytes
+	provider := chunkfs.NewProvider(cfg.LocalDBFilePath, cfg.MaxOpenedLogFiles, ccfg)
+	replicator := chunkfs.NewReplicator(provider.GetFileNameByID, chunkfs.ReplicatorConfig{
+		UploadWorkers: cfg.ReplicaUploadWorkers,
+		Retries:       cfg.ReplicaRetries,
+		RetryBackoff:  cfg.ReplicaRetryBackoff,
+	})
 
 	inj := linker.New()
-	inj.Register(linker.Component{Name: "", Value: cache.NewCachedStorage(postgres.NewStorage(db))})
+	inj.Register(linker.Component{Name: "", Value: cache.NewCachedStorageWithConfig(ms,
+		cache.Config{TTL: cfg.MetaCacheTTL, ChunksCacheMaxBytes: cfg.MetaChunksCacheMaxBytes, NegativeTTL: cfg.MetaCacheNegativeTTL})})
 	inj.Register(linker.Component{Name: "", Value: provider})
 	inj.Register(linker.Component{Name: "", Value: chunkfs.NewChunkAccessor()})
 	inj.Register(linker.Component{Name: "", Value: replicator})
 	inj.Register(linker.Component{Name: "", Value: chunkfs.NewScanner(replicator, chunkfs.GetDefaultScannerConfig())})
-	inj.Register(linker.Component{Name: "", Value: inmem.NewStorage()})
-	inj.Register(linker.Component{Name: "", Value: logfs.NewLocalLog(logfs.GetDefaultConfig())})
+	inj.Register(linker.Component{Name: "", Value: chunkfs.NewDiskMonitor(chunkfs.DiskMonitorConfig{
+		DataPath:      cfg.LocalDBFilePath,
+		MinFreeSpace:  cfg.MinFreeDiskSpace,
+		CheckInterval: chunkfs.GetDefaultDiskMonitorConfig().CheckInterval,
+	})})
+	if cfg.S3Bucket != "" {
+		inj.Register(linker.Component{Name: "", Value: &aws.Config{
+			Region:           aws.String(cfg.S3Region),
+			Endpoint:         aws.String(cfg.S3Endpoint),
+			S3ForcePathStyle: aws.Bool(cfg.S3Endpoint != ""),
+		}})
+		inj.Register(linker.Component{Name: "AwsS3Bucket", Value: cfg.S3Bucket})
+		inj.Register(linker.Component{Name: "", Value: &s3.Storage{}})
+	} else {
+		inj.Register(linker.Component{Name: "", Value: inmem.NewStorage()})
+	}
+	inj.Register(linker.Component{Name: "", Value: logfs.NewLocalLog(localLogConfig(cfg))})
+	inj.Register(linker.Component{Name: "", Value: logfs.NewMigrator(logfs.MigratorConfig{
+		Workers:         cfg.MigrateWorkers,
+		ChunksPerSecond: cfg.MigrateChunksPerSecond,
+		Interval:        cfg.MigrateInterval,
+		StateFile:       filepath.Join(cfg.LocalDBFilePath, logfs.GetDefaultMigratorConfig().StateFile),
+	})})
+	inj.Register(linker.Component{Name: "", Value: logfs.NewRetentionSweeper(logfs.RetentionConfig{
+		Interval:  cfg.RetentionSweepInterval,
+		BatchSize: cfg.RetentionSweepBatchSize,
+	})})
+	if cfg.RecordsMasterKey != "" {
+		inj.Register(linker.Component{Name: "", Value: logfs.NewMasterKeyring([]byte(cfg.RecordsMasterKey))})
+	}
 	inj.Register(linker.Component{Name: "", Value: gsvc})
-	inj.Register(linker.Component{Name: "", Value: grpc.NewServer(grpc.Config{Transport: *cfg.GrpcTransport, RegisterEndpoints: grpcRegF})})
-	inj.Register(linker.Component{Name: "", Value: http.NewRouter(http.Config{HttpPort: cfg.HttpPort, RestRegistrar: rst.RegisterEPs})})
+	inj.Register(linker.Component{Name: "", Value: hc})
+	gcfg := grpc.Config{Transport: *cfg.GrpcTransport, RegisterEndpoints: grpcRegF}
+	hcfg := http.Config{HttpPort: cfg.HttpPort, RestRegistrar: rst.RegisterEPs, GzipResponses: cfg.HttpGzip, GzipMinSize: cfg.HttpGzipMinSize,
+		HealthHandler: hc}
+	if len(cfg.AuthTokens) > 0 {
+		ai := auth.NewInterceptor(auth.NewStaticAuthenticator(cfg.AuthTokens), auth.HealthMethods, "/healthz", "/readyz", "/ping")
+		gcfg.UnaryInterceptors = append(gcfg.UnaryInterceptors, ai.Unary)
+		gcfg.StreamInterceptors = append(gcfg.StreamInterceptors, ai.Stream)
+		hcfg.Auth = ai.HTTP()
+	}
+	if cfg.MaxConcurrentRequests > 0 || cfg.MaxConcurrentStreams > 0 {
+		log.Infof("the concurrent requests are limited by %d, the streams by %d, up to %d requests of a method may wait for %s",
+			cfg.MaxConcurrentRequests, cfg.MaxConcurrentStreams, cfg.MaxQueuedRequests, cfg.RequestsQueueTimeout)
+		al := admission.NewLimiter(admission.Config{MaxConcurrent: cfg.MaxConcurrentRequests, MaxConcurrentStreams: cfg.MaxConcurrentStreams,
+			MaxQueued: cfg.MaxQueuedRequests, QueueTimeout: cfg.RequestsQueueTimeout}, auth.HealthMethods, "/healthz", "/readyz", "/ping", "/metrics")
+		gcfg.UnaryInterceptors = append(gcfg.UnaryInterceptors, al.Unary)
+		gcfg.StreamInterceptors = append(gcfg.StreamInterceptors, al.Stream)
+		hcfg.Admission = al.HTTP()
+	}
+	inj.Register(linker.Component{Name: "", Value: grpc.NewServer(gcfg)})
+	if cfg.HttpMetrics {
+		hcfg.MetricsHandler = metrics.Handler()
+	}
+	inj.Register(linker.Component{Name: "", Value: http.NewRouter(hcfg)})
 
 	inj.Init(ctx)
 	<-ctx.Done()
@@ -87,6 +171,38 @@ func Run(ctx context.Context, cfg *Config) error {
 	return nil
 }
 
+// newMetaStorage returns the logs and chunks metadata storage of the DB driver and
+// the connection the readiness of the storage is checked by. The embedded storage is
+// opened right away, so it is ready to be checked, and is closed by the cache.
+func newMetaStorage(ctx context.Context, dbConn *db.DBConn) (cache.LogsChunksMetaStorage, health.Pinger, error) {
+	if dbConn.Driver == db.DriverBuntDB {
+		s := buntdb.NewStorage(buntdb.Config{DBFilePath: dbConn.DBName})
+		if err := s.Init(ctx); err != nil {
+			return nil, nil, err
+		}
+		return s, s, nil
+	}
+	pdb := postgres.MustGetDb(ctx, dbConn)
+	return postgres.NewStorage(pdb), pdb, nil
+}
+
+// localLogConfig returns the logfs.Config built from the server config
+func localLogConfig(cfg *Config) logfs.Config {
+	lcfg := logfs.GetDefaultConfig()
+	lcfg.MaxRecordsLimit = cfg.MaxRecordsLimit
+	lcfg.MaxBunchSize = cfg.MaxBunchSize
+	lcfg.MaxLocks = cfg.MaxLocks
+	lcfg.MaxChunksPerLog = cfg.MaxChunksPerLog
+	lcfg.ChunksSoftLimitPct = cfg.ChunksSoftLimitPct
+	lcfg.AtomicAppends = cfg.AtomicAppends
+	lcfg.Sequences = cfg.RecordsSequences
+	lcfg.MaxChunkSize = cfg.CompactMaxChunkSize
+	lcfg.CompactMaxChunks = cfg.CompactMaxChunks
+	lcfg.MaxPruneIntervals = cfg.MaxPruneIntervals
+	lcfg.MetaCommitTimeout = cfg.MetaCommitTimeout
+	return lcfg
+}
+
 func checkConfig(cfg *Config) error {
 	if cfg.LocalDBFilePath == "" {
 		return fmt.Errorf("LocalDBFilePath must be provided: %w", errors.ErrInvalid)
@@ -30,6 +30,7 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"slices"
 	"strings"
+	"sync"
 )
 
 type (
@@ -41,9 +42,13 @@ type (
 		DBFilePath string
 	}
 
-	// Storage is the logs meta storage
+	// Storage is the logs meta storage, which implements storage.Logs, storage.RecordHashes and
+	// logfs.LogsMetaStorage, so it may be used instead of the database one when Solaris is embedded
+	// or runs on a single node. The Storage is safe for concurrent use, the data is kept in memory
+	// and is persisted to the DB file (if specified), so it survives the restart.
 	Storage struct {
 		cfg    *Config
+		lock   sync.RWMutex
 		db     *buntdb.DB
 		logger logging.Logger
 	}
@@ -63,8 +68,14 @@ func NewStorage(cfg Config) *Storage {
 	return &Storage{cfg: &cfg}
 }
 
-// Init implements linker.Initializer
+// Init implements linker.Initializer. The storage is opened once, so it may be initialized
+// before the components using it.
 func (s *Storage) Init(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.db != nil {
+		return nil
+	}
 	path := s.cfg.DBFilePath
 	if len(path) == 0 {
 		path = ":memory:"
@@ -81,11 +92,25 @@ func (s *Storage) Init(ctx context.Context) error {
 	return nil
 }
 
+// PingContext checks the storage is open, so the Storage may be used for the readiness checks.
+// It returns errors.ErrClosed if the storage is shut down.
+func (s *Storage) PingContext(ctx context.Context) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.db == nil {
+		return fmt.Errorf("the storage is shut down: %w", errors.ErrClosed)
+	}
+	return s.db.View(func(tx *buntdb.Tx) error { return nil })
+}
+
 // Shutdown implements linker.Shutdowner
 func (s *Storage) Shutdown() {
 	s.logger.Infof("Shutting down...")
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	if s.db != nil {
 		_ = s.db.Close()
+		s.db = nil
 	}
 }
 
@@ -112,6 +137,39 @@ func (s *Storage) CreateLog(ctx context.Context, log *solaris.Log) (*solaris.Log
 	return toLog(le), nil
 }
 
+// CreateLogs implements storage.Logs
+func (s *Storage) CreateLogs(ctx context.Context, logs []*solaris.Log) ([]*solaris.Log, error) {
+	tx := mustBeginTx(s.db, true)
+	defer mustRollback(tx)
+
+	now := timestamppb.Now()
+	res := make([]*solaris.Log, 0, len(logs))
+	for _, log := range logs {
+		le := toEntry(log)
+		if le.ID == "" {
+			le.ID = ulidutils.NewID()
+		}
+		le.CreatedAt = now
+		le.UpdatedAt = now
+
+		key := logKey(le.ID)
+		val := mustMarshal(le)
+
+		// the logs marked for deletion are not overwritten either, the transaction is rolled back
+		_, replaced, err := tx.Set(key, val, nil)
+		if err != nil {
+			return nil, fmt.Errorf("tx.Set(%s, %s) failed: %w", key, val, err)
+		}
+		if replaced {
+			return nil, fmt.Errorf("the log with ID=%s already exists: %w", le.ID, errors.ErrExist)
+		}
+		res = append(res, toLog(le))
+	}
+
+	mustCommit(tx)
+	return res, nil
+}
+
 // GetLogByID implements storage.Logs
 func (s *Storage) GetLogByID(ctx context.Context, id string) (*solaris.Log, error) {
 	if len(id) == 0 {
@@ -140,6 +198,13 @@ func (s *Storage) UpdateLog(ctx context.Context, log *solaris.Log) (*solaris.Log
 	}
 
 	le.Tags = log.Tags
+	le.ValidateUTF8 = log.ValidateUTF8
+	le.PayloadTypeURL = log.PayloadTypeURL
+	le.MaxRecords = log.MaxRecords
+	le.PayloadHash = log.PayloadHash
+	le.RetentionMaxAge = log.RetentionMaxAge
+	le.RetentionMaxRecords = log.RetentionMaxRecords
+	le.Grants = log.Grants
 	le.UpdatedAt = timestamppb.Now()
 
 	key := logKey(le.ID)
@@ -205,12 +270,19 @@ func (s *Storage) deleteLogsByIDs(ctx context.Context, req storage.DeleteLogsReq
 		if ctx.Err() != nil {
 			return nil, fmt.Errorf("context error: %w", ctx.Err())
 		}
+		// the logs, which don't exist or are already marked deleted, are skipped
 		if req.MarkOnly {
 			if err := s.markLogDeleted(tx, id); err != nil {
+				if errors.Is(err, errors.ErrNotExist) {
+					continue
+				}
 				return nil, fmt.Errorf("markLogDeleted(ID=%s) failed: %w", id, err)
 			}
 		} else {
-			if err := s.deleteLog(ctx, tx, id); err != nil && !errors.Is(err, errors.ErrNotExist) {
+			if err := s.deleteLog(ctx, tx, id); err != nil {
+				if errors.Is(err, errors.ErrNotExist) {
+					continue
+				}
 				return nil, fmt.Errorf("deleteLog(ID=%s) failed: %w", id, err)
 			}
 		}
@@ -232,6 +304,23 @@ func (s *Storage) deleteLog(ctx context.Context, tx *buntdb.Tx, logID string) er
 	if err != nil {
 		return fmt.Errorf("tx.Delete(key=%s) failed: %w", key, err)
 	}
+	for _, key = range []string{appendKeyKey(logID), logKeyIDKey(logID)} {
+		if _, err = tx.Delete(key); err != nil && !errors.Is(err, buntdb.ErrNotFound) {
+			return fmt.Errorf("tx.Delete(key=%s) failed: %w", key, err)
+		}
+	}
+	var hks []string
+	if err = tx.AscendRange("", hashKey(logID, "", ""), hashKey(logID, "~", ""), func(key, _ string) bool {
+		hks = append(hks, key)
+		return true
+	}); err != nil {
+		return fmt.Errorf("iteration failed: %w", err)
+	}
+	for _, key = range hks {
+		if _, err = tx.Delete(key); err != nil && !errors.Is(err, buntdb.ErrNotFound) {
+			return fmt.Errorf("tx.Delete(key=%s) failed: %w", key, err)
+		}
+	}
 	cis, err := getLogChunks(ctx, tx, logID)
 	if err != nil {
 		return fmt.Errorf("getLogChunks(ID=%s) failed: %w", logID, err)
@@ -292,10 +381,16 @@ func (s *Storage) queryLogsByIDs(ctx context.Context, qr storage.QueryLogsReques
 		limit = 50
 	}
 
-	logIDs := slices.Clone(qr.IDs)
+	from, to := qr.IDRange()
+	logIDs := slices.DeleteFunc(slices.Clone(qr.IDs), func(id string) bool {
+		return id < from || (to != "" && id > to)
+	})
 	slices.Sort(logIDs)
 
-	startIdx, _ := slices.BinarySearch(logIDs, qr.Page)
+	startIdx, found := slices.BinarySearch(logIDs, qr.Page)
+	if found {
+		startIdx++
+	}
 	if startIdx == len(logIDs) {
 		return &solaris.QueryLogsResult{
 			Logs:       nil,
@@ -329,8 +424,8 @@ func (s *Storage) queryLogsByIDs(ctx context.Context, qr storage.QueryLogsReques
 
 	var nextPageID string
 	if len(qLogs) > limit {
-		nextPageID = qLogs[limit].ID
 		qLogs = qLogs[:limit]
+		nextPageID = qLogs[limit-1].ID
 	}
 	return &solaris.QueryLogsResult{
 		Logs:       qLogs,
@@ -342,7 +437,7 @@ func (s *Storage) queryLogsByIDs(ctx context.Context, qr storage.QueryLogsReques
 func (s *Storage) queryLogsByCondition(ctx context.Context, qr storage.QueryLogsRequest, skipMarkedDeleted bool) (*solaris.QueryLogsResult, error) {
 	expr, err := ql.Parse(qr.Condition)
 	if err != nil {
-		return nil, fmt.Errorf("condition=%q parse error=%v: %w", qr.Condition, err, errors.ErrInvalid)
+		return nil, fmt.Errorf("condition=%q parse error: %w", qr.Condition, err)
 	}
 	tstF, err := ql.BuildExprF(expr, ql.LogsCondValueDialect)
 	if err != nil {
@@ -364,7 +459,8 @@ func (s *Storage) queryLogsByCondition(ctx context.Context, qr storage.QueryLogs
 			return false
 		}
 		le := mustUnmarshal[logEntry](val)
-		if skipMarkedDeleted && le.Deleted {
+		// the page starts after the last log of the previous one
+		if (skipMarkedDeleted && le.Deleted) || le.ID == qr.Page {
 			return true
 		}
 		if tstF(le.Log) {
@@ -379,7 +475,15 @@ func (s *Storage) queryLogsByCondition(ctx context.Context, qr storage.QueryLogs
 	tx := mustBeginTx(s.db, false)
 	defer mustRollback(tx)
 
-	if err = tx.AscendRange("", logKey(qr.Page), logKey(ulidutils.MaxULID.String()), iter); err != nil {
+	from, to := qr.IDRange()
+	from = max(from, qr.Page)
+	if to == "" {
+		to = ulidutils.MaxULID.String()
+	} else {
+		// the upper bound of AscendRange is exclusive
+		to = ulidutils.NextID(to)
+	}
+	if err = tx.AscendRange("", logKey(from), logKey(to), iter); err != nil {
 		return nil, fmt.Errorf("iteration failed: %w", err)
 	}
 	if iterErr != nil {
@@ -388,8 +492,8 @@ func (s *Storage) queryLogsByCondition(ctx context.Context, qr storage.QueryLogs
 
 	var nextPageID string
 	if len(qLogs) > limit {
-		nextPageID = qLogs[limit].ID
 		qLogs = qLogs[:limit]
+		nextPageID = qLogs[limit-1].ID
 	}
 	return &solaris.QueryLogsResult{
 		Logs:       qLogs,
@@ -437,12 +541,13 @@ func (s *Storage) GetLastChunk(ctx context.Context, logID string) (logfs.ChunkIn
 	return ce.ChunkInfo, nil
 }
 
-// GetChunks implements logfs.LogsMetaStorage
+// GetChunks implements logfs.LogsMetaStorage. The chunks are returned in the ascending order of their IDs.
+// The chunks of the logs marked for deletion are returned as well, so they could be deleted physically.
 func (s *Storage) GetChunks(ctx context.Context, logID string) ([]logfs.ChunkInfo, error) {
 	tx := mustBeginTx(s.db, false)
 	defer mustRollback(tx)
 
-	if _, err := s.getLogEntry(tx, logKey(logID), true); err != nil {
+	if _, err := s.getLogEntry(tx, logKey(logID), false); err != nil {
 		return nil, fmt.Errorf("getLogEntry(ID=%s) failed: %w", logID, err)
 	}
 
@@ -478,6 +583,22 @@ func (s *Storage) UpsertChunkInfos(ctx context.Context, logID string, cis []logf
 	return nil
 }
 
+// DeleteChunkInfos implements logfs.LogsMetaStorage
+func (s *Storage) DeleteChunkInfos(ctx context.Context, logID string, cIDs []string) error {
+	tx := mustBeginTx(s.db, true)
+	defer mustRollback(tx)
+
+	for _, cID := range cIDs {
+		key := chnkKey(logID, cID)
+		if _, err := tx.Delete(key); err != nil && !errors.Is(err, buntdb.ErrNotFound) {
+			return fmt.Errorf("tx.Delete(key=%s) failed: %w", key, err)
+		}
+	}
+
+	mustCommit(tx)
+	return nil
+}
+
 func getLogChunks(ctx context.Context, tx *buntdb.Tx, logID string) ([]logfs.ChunkInfo, error) {
 	var iterErr error
 	var cis []logfs.ChunkInfo
@@ -502,6 +623,146 @@ func chnkKey(logID, chnkID string) string {
 	return fmt.Sprintf("/chunks/%s/%s", logID, chnkID)
 }
 
+// GetLastAppendKey implements logfs.LogsMetaStorage
+func (s *Storage) GetLastAppendKey(ctx context.Context, logID string) (logfs.AppendKey, error) {
+	tx := mustBeginTx(s.db, false)
+	defer mustRollback(tx)
+
+	val, err := getValue(tx, appendKeyKey(logID))
+	if err != nil {
+		return logfs.AppendKey{}, err
+	}
+	return mustUnmarshal[logfs.AppendKey](val), nil
+}
+
+// SetLastAppendKey implements logfs.LogsMetaStorage
+func (s *Storage) SetLastAppendKey(ctx context.Context, logID string, ak logfs.AppendKey) error {
+	tx := mustBeginTx(s.db, true)
+	defer mustRollback(tx)
+
+	if _, err := s.getLogEntry(tx, logKey(logID), true); err != nil {
+		return fmt.Errorf("getLogEntry(ID=%s) failed: %w", logID, err)
+	}
+
+	key := appendKeyKey(logID)
+	val := mustMarshal(ak)
+	if _, _, err := tx.Set(key, val, nil); err != nil {
+		return fmt.Errorf("tx.Set(key=%s, val=%s) failed: %w", key, val, err)
+	}
+
+	mustCommit(tx)
+	return nil
+}
+
+func appendKeyKey(logID string) string {
+	return fmt.Sprintf("/appendKeys/%s", logID)
+}
+
+// GetLogKeyID implements logfs.LogsMetaStorage
+func (s *Storage) GetLogKeyID(ctx context.Context, logID string) (string, error) {
+	tx := mustBeginTx(s.db, false)
+	defer mustRollback(tx)
+
+	return getValue(tx, logKeyIDKey(logID))
+}
+
+// SetLogKeyID implements logfs.LogsMetaStorage
+func (s *Storage) SetLogKeyID(ctx context.Context, logID, keyID string) error {
+	tx := mustBeginTx(s.db, true)
+	defer mustRollback(tx)
+
+	if _, err := s.getLogEntry(tx, logKey(logID), true); err != nil {
+		return fmt.Errorf("getLogEntry(ID=%s) failed: %w", logID, err)
+	}
+
+	key := logKeyIDKey(logID)
+	if _, _, err := tx.Set(key, keyID, nil); err != nil {
+		return fmt.Errorf("tx.Set(key=%s, val=%s) failed: %w", key, keyID, err)
+	}
+
+	mustCommit(tx)
+	return nil
+}
+
+func logKeyIDKey(logID string) string {
+	return fmt.Sprintf("/keyIDs/%s", logID)
+}
+
+// ===================================== record hashes =====================================
+
+// AddRecordHashes implements storage.RecordHashes
+func (s *Storage) AddRecordHashes(ctx context.Context, logID string, rhs []storage.RecordHash) error {
+	tx := mustBeginTx(s.db, true)
+	defer mustRollback(tx)
+
+	if _, err := s.getLogEntry(tx, logKey(logID), true); err != nil {
+		return fmt.Errorf("getLogEntry(ID=%s) failed: %w", logID, err)
+	}
+
+	for _, rh := range rhs {
+		if rh.Hash == "" || rh.RecordID == "" {
+			return fmt.Errorf("invalid record hash=%q for record ID=%q: %w", rh.Hash, rh.RecordID, errors.ErrInvalid)
+		}
+		key := hashKey(logID, rh.Hash, rh.RecordID)
+		if _, _, err := tx.Set(key, "", nil); err != nil {
+			return fmt.Errorf("tx.Set(key=%s) failed: %w", key, err)
+		}
+	}
+
+	mustCommit(tx)
+	return nil
+}
+
+// GetRecordIDsByHash implements storage.RecordHashes
+func (s *Storage) GetRecordIDsByHash(ctx context.Context, logID, hash string) ([]string, error) {
+	tx := mustBeginTx(s.db, false)
+	defer mustRollback(tx)
+
+	if _, err := s.getLogEntry(tx, logKey(logID), true); err != nil {
+		return nil, fmt.Errorf("getLogEntry(ID=%s) failed: %w", logID, err)
+	}
+
+	prefix := hashKey(logID, hash, "")
+	var ids []string
+	if err := tx.AscendRange("", prefix, hashKey(logID, hash, "~"), func(key, _ string) bool {
+		ids = append(ids, strings.TrimPrefix(key, prefix))
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("iteration failed: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteRecordHashes implements storage.RecordHashes
+func (s *Storage) DeleteRecordHashes(ctx context.Context, logID, fromID, toID string) error {
+	tx := mustBeginTx(s.db, true)
+	defer mustRollback(tx)
+
+	// the keys are ordered by the hashes, so all the log keys are checked
+	var keys []string
+	if err := tx.AscendRange("", hashKey(logID, "", ""), hashKey(logID, "~", ""), func(key, _ string) bool {
+		id := key[strings.LastIndexByte(key, '/')+1:]
+		if (fromID == "" || id >= fromID) && (toID == "" || id <= toID) {
+			keys = append(keys, key)
+		}
+		return true
+	}); err != nil {
+		return fmt.Errorf("iteration failed: %w", err)
+	}
+	for _, key := range keys {
+		if _, err := tx.Delete(key); err != nil && !errors.Is(err, buntdb.ErrNotFound) {
+			return fmt.Errorf("tx.Delete(key=%s) failed: %w", key, err)
+		}
+	}
+
+	mustCommit(tx)
+	return nil
+}
+
+func hashKey(logID, hash, recordID string) string {
+	return fmt.Sprintf("/hashes/%s/%s/%s", logID, hash, recordID)
+}
+
 // ===================================== helpers =====================================
 
 func mustBeginTx(db *buntdb.DB, writable bool) *buntdb.Tx {
@@ -17,14 +17,21 @@ package buntdb
 import (
 	"context"
 	"fmt"
+	"github.com/oklog/ulid/v2"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/ulidutils"
 	"github.com/solarisdb/solaris/pkg/storage"
 	"github.com/solarisdb/solaris/pkg/storage/logfs"
+	"github.com/solarisdb/solaris/pkg/storage/logfs/logfstest"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"maps"
 	"math/rand"
+	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestStorage_CreateLog(t *testing.T) {
@@ -55,9 +62,22 @@ func TestStorage_UpdateLog(t *testing.T) {
 	assert.True(t, maps.Equal(log2.Tags, log1.Tags))
 
 	log1.Tags["tag5"] = "val5"
+	log1.ValidateUTF8 = true
+	log1.PayloadTypeURL = "type.googleapis.com/google.protobuf.Timestamp"
+	log1.MaxRecords = 100
+	log1.RetentionMaxAge = durationpb.New(time.Hour)
+	log1.RetentionMaxRecords = 1000
 	log2, err = s.UpdateLog(ctx, log1)
 	assert.Nil(t, err)
 	assert.True(t, maps.Equal(log2.Tags, log1.Tags))
+
+	log2, err = s.GetLogByID(ctx, log1.ID)
+	assert.Nil(t, err)
+	assert.True(t, log2.ValidateUTF8)
+	assert.Equal(t, log1.PayloadTypeURL, log2.PayloadTypeURL)
+	assert.Equal(t, log1.MaxRecords, log2.MaxRecords)
+	assert.Equal(t, time.Hour, log2.RetentionMaxAge.AsDuration())
+	assert.Equal(t, log1.RetentionMaxRecords, log2.RetentionMaxRecords)
 }
 
 func TestStorage_GetLogByID(t *testing.T) {
@@ -109,7 +129,7 @@ func TestStorage_QueryLogsByCondition(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(qr.Logs))
 	assert.Equal(t, int64(3), qr.Total)
-	assert.Equal(t, qr.NextPageID, log3.ID)
+	assert.Equal(t, qr.NextPageID, log2.ID)
 }
 
 func TestStorage_QueryLogsByIDs(t *testing.T) {
@@ -133,7 +153,83 @@ func TestStorage_QueryLogsByIDs(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(qr.Logs))
 	assert.Equal(t, int64(3), qr.Total)
-	assert.Equal(t, qr.NextPageID, log3.ID)
+	assert.Equal(t, qr.NextPageID, log2.ID)
+}
+
+func TestStorage_QueryLogsCreatedInWindow(t *testing.T) {
+	ctx := context.Background()
+	s, err := getStorage(ctx)
+	assert.Nil(t, err)
+
+	var logs []*solaris.Log
+	for i := 0; i < 5; i++ {
+		log, err := s.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"tag": "val"}})
+		assert.Nil(t, err)
+		logs = append(logs, log)
+		time.Sleep(2 * time.Millisecond)
+	}
+	after := ulid.Time(ulid.MustParse(logs[1].ID).Time())
+	before := ulid.Time(ulid.MustParse(logs[3].ID).Time())
+
+	qr, err := s.QueryLogs(ctx, storage.QueryLogsRequest{Condition: "tag('tag') = 'val'", CreatedAfter: after, CreatedBefore: before, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(qr.Logs))
+	assert.Equal(t, int64(3), qr.Total)
+	assert.Equal(t, logs[1].ID, qr.Logs[0].ID)
+	assert.Equal(t, logs[2].ID, qr.Logs[1].ID)
+	assert.Equal(t, logs[2].ID, qr.NextPageID)
+
+	qr, err = s.QueryLogs(ctx, storage.QueryLogsRequest{Condition: "tag('tag') = 'val'", CreatedAfter: after, CreatedBefore: before, Page: qr.NextPageID, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(qr.Logs))
+	assert.Equal(t, logs[3].ID, qr.Logs[0].ID)
+	assert.Empty(t, qr.NextPageID)
+
+	ids := []string{logs[0].ID, logs[1].ID, logs[2].ID, logs[3].ID, logs[4].ID}
+	qr, err = s.QueryLogs(ctx, storage.QueryLogsRequest{IDs: ids, CreatedAfter: after, Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(qr.Logs))
+	assert.Equal(t, logs[1].ID, qr.Logs[0].ID)
+}
+
+func TestStorage_QueryLogsPagesStable(t *testing.T) {
+	ctx := context.Background()
+	s, err := getStorage(ctx)
+	assert.Nil(t, err)
+
+	var logs []*solaris.Log
+	for i := 0; i < 5; i++ {
+		log, err := s.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"pages": "val"}})
+		assert.Nil(t, err)
+		logs = append(logs, log)
+	}
+
+	var read []string
+	page := ""
+	for i := 0; ; i++ {
+		qr, err := s.QueryLogs(ctx, storage.QueryLogsRequest{Condition: "tag('pages') = 'val'", Page: page, Limit: 2})
+		assert.Nil(t, err)
+		for _, l := range qr.Logs {
+			read = append(read, l.ID)
+		}
+		if i == 0 {
+			// the logs created between the pages, including the one right after the last returned
+			created, err := s.CreateLogs(ctx, []*solaris.Log{{ID: ulidutils.NextID(qr.NextPageID), Tags: map[string]string{"pages": "val"}},
+				{Tags: map[string]string{"pages": "val"}}})
+			assert.Nil(t, err)
+			logs = append(logs, created...)
+		}
+		if qr.NextPageID == "" {
+			break
+		}
+		page = qr.NextPageID
+	}
+	var expected []string
+	for _, l := range logs {
+		expected = append(expected, l.ID)
+	}
+	slices.Sort(expected)
+	assert.Equal(t, expected, read)
 }
 
 func TestStorage_DeleteLogsByCondition(t *testing.T) {
@@ -188,6 +284,36 @@ func TestStorage_DeleteLogsByConditionMarkOnly(t *testing.T) {
 	assert.Equal(t, 0, len(dr.DeletedIDs))
 }
 
+func TestStorage_CreateLogs(t *testing.T) {
+	ctx := context.Background()
+	s, err := getStorage(ctx)
+	assert.Nil(t, err)
+
+	logs, err := s.CreateLogs(ctx, []*solaris.Log{{ID: "l1", Tags: map[string]string{"a": "b"}}, {Tags: map[string]string{"c": "d"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, "l1", logs[0].ID)
+	assert.NotEmpty(t, logs[1].ID)
+	for _, l := range logs {
+		l1, err := s.GetLogByID(ctx, l.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, l.Tags, l1.Tags)
+	}
+
+	// nothing is created if an ID exists already or is repeated
+	_, err = s.CreateLogs(ctx, []*solaris.Log{{ID: "l2"}, {ID: "l1"}})
+	assert.True(t, errors.Is(err, errors.ErrExist))
+	_, err = s.CreateLogs(ctx, []*solaris.Log{{ID: "l3"}, {ID: "l3"}})
+	assert.True(t, errors.Is(err, errors.ErrExist))
+	for _, id := range []string{"l2", "l3"} {
+		_, err = s.GetLogByID(ctx, id)
+		assert.True(t, errors.Is(err, errors.ErrNotExist))
+	}
+	l1, err := s.GetLogByID(ctx, "l1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "b"}, l1.Tags)
+}
+
 func TestStorage_DeleteLogsByIDs(t *testing.T) {
 	ctx := context.Background()
 	s, err := getStorage(ctx)
@@ -208,6 +334,17 @@ func TestStorage_DeleteLogsByIDs(t *testing.T) {
 	dr, err := s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log2.ID, log3.ID}})
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(dr.DeletedIDs))
+
+	// the deleted and not existing logs are skipped
+	dr, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log1.ID, log2.ID, "unknown"}, MarkOnly: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{log1.ID}, dr.DeletedIDs)
+	dr, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log1.ID, log2.ID}, MarkOnly: true})
+	assert.Nil(t, err)
+	assert.Empty(t, dr.DeletedIDs)
+	dr, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log1.ID, log3.ID}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{log1.ID}, dr.DeletedIDs)
 }
 
 func TestStorage_DeleteLogByIDsWithChunks(t *testing.T) {
@@ -296,6 +433,77 @@ func TestStorage_GetChunks(t *testing.T) {
 	assert.ErrorIs(t, err, errors.ErrNotExist)
 }
 
+func TestStorage_ChunksOrder(t *testing.T) {
+	ctx := context.Background()
+	s, err := getStorage(ctx)
+	assert.Nil(t, err)
+
+	log, err := s.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+
+	var ids []string
+	for i := 0; i < 100; i++ {
+		ids = append(ids, ulidutils.NewID())
+	}
+	// the chunks are upserted in the random order by several calls
+	shuffled := slices.Clone(ids)
+	rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	for i := 0; i < len(shuffled); i += 10 {
+		var cis []logfs.ChunkInfo
+		for _, id := range shuffled[i : i+10] {
+			cis = append(cis, logfs.ChunkInfo{ID: id})
+		}
+		assert.Nil(t, s.UpsertChunkInfos(ctx, log.ID, cis))
+	}
+
+	slices.Sort(ids)
+	cis, err := s.GetChunks(ctx, log.ID)
+	assert.Nil(t, err)
+	var got []string
+	for _, ci := range cis {
+		got = append(got, ci.ID)
+	}
+	assert.Equal(t, ids, got)
+
+	ci, err := s.GetLastChunk(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, ids[len(ids)-1], ci.ID)
+}
+
+func TestStorage_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "meta.db")
+	s := NewStorage(Config{DBFilePath: path})
+	assert.Nil(t, s.Init(ctx))
+
+	log, err := s.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"t": "1"}})
+	assert.Nil(t, err)
+	assert.Nil(t, s.UpsertChunkInfos(ctx, log.ID, []logfs.ChunkInfo{{ID: "1", Min: ulid.Make(), RecordsCount: 10}}))
+	assert.Nil(t, s.PingContext(ctx))
+	s.Shutdown()
+	assert.True(t, errors.Is(s.PingContext(ctx), errors.ErrClosed))
+
+	// the data survives the restart
+	s = NewStorage(Config{DBFilePath: path})
+	assert.Nil(t, s.Init(ctx))
+	defer s.Shutdown()
+	log2, err := s.GetLogByID(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, log.Tags, log2.Tags)
+	cis, err := s.GetChunks(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cis))
+	assert.Equal(t, 10, cis[0].RecordsCount)
+}
+
+func TestStorage_Conformance(t *testing.T) {
+	logfstest.RunLogsMetaStorageConformance(t, func() logfs.LogsMetaStorage {
+		s, err := getStorage(context.Background())
+		assert.Nil(t, err)
+		return s
+	})
+}
+
 func TestStorage_UpsertChunkInfos(t *testing.T) {
 	ctx := context.Background()
 	s, err := getStorage(ctx)
@@ -348,6 +556,136 @@ func TestStorage_DeleteLogChunks(t *testing.T) {
 	assert.Equal(t, 1, len(dr.DeletedIDs))
 }
 
+func TestStorage_DeleteChunkInfos(t *testing.T) {
+	ctx := context.Background()
+	s, err := getStorage(ctx)
+	assert.Nil(t, err)
+
+	log := &solaris.Log{}
+	log, err = s.CreateLog(ctx, log)
+	assert.Nil(t, err)
+
+	err = s.UpsertChunkInfos(ctx, log.ID, []logfs.ChunkInfo{{ID: "1"}, {ID: "2"}, {ID: "3"}})
+	assert.Nil(t, err)
+
+	err = s.DeleteChunkInfos(ctx, log.ID, []string{"1", "3", "4"})
+	assert.Nil(t, err)
+
+	cis, err := s.GetChunks(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cis))
+	assert.Equal(t, "2", cis[0].ID)
+}
+
+func TestStorage_LastAppendKey(t *testing.T) {
+	ctx := context.Background()
+	s, err := getStorage(ctx)
+	assert.Nil(t, err)
+
+	log, err := s.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+
+	_, err = s.GetLastAppendKey(ctx, log.ID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+
+	ak := logfs.AppendKey{Key: "k1", Added: 2, BytesWritten: 10, StartID: "s1", LastID: "l1"}
+	assert.Nil(t, s.SetLastAppendKey(ctx, log.ID, ak))
+	ak2, err := s.GetLastAppendKey(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, ak, ak2)
+
+	ak.Key = "k2"
+	assert.Nil(t, s.SetLastAppendKey(ctx, log.ID, ak))
+	ak2, err = s.GetLastAppendKey(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, ak, ak2)
+
+	_, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log.ID}})
+	assert.Nil(t, err)
+	_, err = s.GetLastAppendKey(ctx, log.ID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+}
+
+func TestStorage_LogKeyID(t *testing.T) {
+	ctx := context.Background()
+	s, err := getStorage(ctx)
+	assert.Nil(t, err)
+
+	log, err := s.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+
+	_, err = s.GetLogKeyID(ctx, log.ID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	assert.True(t, errors.Is(s.SetLogKeyID(ctx, "unknown", "k1"), errors.ErrNotExist))
+
+	assert.Nil(t, s.SetLogKeyID(ctx, log.ID, "k1"))
+	keyID, err := s.GetLogKeyID(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "k1", keyID)
+
+	assert.Nil(t, s.SetLogKeyID(ctx, log.ID, "k2"))
+	keyID, err = s.GetLogKeyID(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "k2", keyID)
+
+	_, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log.ID}})
+	assert.Nil(t, err)
+	_, err = s.GetLogKeyID(ctx, log.ID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+}
+
+func TestStorage_RecordHashes(t *testing.T) {
+	ctx := context.Background()
+	s, err := getStorage(ctx)
+	assert.Nil(t, err)
+
+	log, err := s.CreateLog(ctx, &solaris.Log{PayloadHash: "fnv64a"})
+	assert.Nil(t, err)
+	assert.Equal(t, "fnv64a", log.PayloadHash)
+
+	ids, err := s.GetRecordIDsByHash(ctx, log.ID, "h1")
+	assert.Nil(t, err)
+	assert.Empty(t, ids)
+
+	// the same hash of several records (duplicates or collisions) returns all of them
+	assert.Nil(t, s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h1", RecordID: "r3"},
+		{Hash: "h2", RecordID: "r2"}, {Hash: "h1", RecordID: "r1"}}))
+	assert.Nil(t, s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h1", RecordID: "r1"}}))
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"r1", "r3"}, ids)
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h2")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"r2"}, ids)
+
+	err = s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h1"}})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+
+	// the removed records are deleted from the index by the IDs range
+	assert.Nil(t, s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h2", RecordID: "r4"}}))
+	assert.Nil(t, s.DeleteRecordHashes(ctx, log.ID, "r2", "r3"))
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"r1"}, ids)
+	assert.Nil(t, s.DeleteRecordHashes(ctx, log.ID, "", "r1"))
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h1")
+	assert.Nil(t, err)
+	assert.Empty(t, ids)
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h2")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"r4"}, ids)
+	assert.Nil(t, s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h1", RecordID: "r1"}}))
+
+	// the index is deleted with the log
+	_, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log.ID}})
+	assert.Nil(t, err)
+	tx := mustBeginTx(s.db, false)
+	defer mustRollback(tx)
+	n, err := tx.Len()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+}
+
 func BenchmarkCache_GetLastChunk(b *testing.B) {
 	ctx := context.Background()
 	s, _ := getStorage(ctx)
@@ -19,49 +19,164 @@ import (
 	"github.com/logrange/linker"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/container/lru"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/logging"
 	"github.com/solarisdb/solaris/pkg/storage"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
 	"github.com/solarisdb/solaris/pkg/storage/logfs"
 	"sort"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
 type (
-	// LogsChunksMetaStorage combines storage.Logs and
+	// LogsChunksMetaStorage combines storage.Logs, storage.RecordHashes and
 	// logfs.LogsMetaStorage interfaces
 	LogsChunksMetaStorage interface {
 		storage.Logs
+		storage.RecordHashes
 		logfs.LogsMetaStorage
 	}
 
 	// CachedStorage wraps LogsChunksMetaStorage
 	// with caches for logs and chunks
 	CachedStorage struct {
+		// ChnkProvider is used to delete the chunks files when the logs are deleted
+		// physically. If it is not provided, only the logs metadata is deleted.
+		ChnkProvider *chunkfs.Provider `inject:",optional"`
+
 		storage     LogsChunksMetaStorage
-		logsCache   *lru.Cache[string, *solaris.Log]
-		chunksCache *lru.Cache[string, []logfs.ChunkInfo]
+		logger      logging.Logger
+		logsCache   *lru.Cache[string, lru.ExpirableItem[*solaris.Log]]
+		chunksCache *lru.Cache[string, lru.ExpirableItem[[]logfs.ChunkInfo]]
+		// ttl defines how long a cached entry is used before it is read from the storage again,
+		// zero value means the entries don't expire
+		ttl time.Duration
+		now func() time.Time
+		// logsExpired and chunksExpired count the expired entries found in the caches
+		logsExpired   atomic.Int64
+		chunksExpired atomic.Int64
+	}
+
+	// CacheStats contains the counters of a CachedStorage cache
+	CacheStats struct {
+		// Hits is the number of the requests served from the cache
+		Hits int64
+		// Misses is the number of the requests, which read the storage, including the expired entries reads
+		Misses int64
+		// Evictions is the number of the least recently used entries deleted when the cache is full
+		Evictions int64
+		// Expirations is the number of the expired entries read from the storage again
+		Expirations int64
+	}
+
+	// Config defines the CachedStorage caches settings
+	Config struct {
+		// TTL defines how long a cached entry is used before it is read from the storage again,
+		// zero value means the entries don't expire
+		TTL time.Duration
+		// ChunksCacheMaxBytes bounds the chunks cache by the approximate total size of the cached chunks
+		// lists instead of their number, so the logs with many chunks don't take too much memory.
+		// Zero value means the cache keeps a fixed number of the lists regardless of their size.
+		ChunksCacheMaxBytes int64
+		// NegativeTTL defines how long the logs, which are not found in the storage, are known as not existing,
+		// so the repeated lookups of a missing log ID don't reach the storage. Zero value disables the caching.
+		NegativeTTL time.Duration
+	}
+
+	// Stats contains the CachedStorage caches counters
+	Stats struct {
+		Logs   CacheStats
+		Chunks CacheStats
 	}
 )
 
 const cacheSize = 1000
 
-// NewCachedStorage wraps LogsChunksMetaStorage into cache
+// chunkInfoSize is the approximate size of a logfs.ChunkInfo in the chunks cache
+var chunkInfoSize = int64(unsafe.Sizeof(logfs.ChunkInfo{}))
+
+// NewCachedStorage wraps LogsChunksMetaStorage into cache, the cached entries don't expire
+// and stay in the cache until they are evicted or removed on the changes
 func NewCachedStorage(storage LogsChunksMetaStorage) *CachedStorage {
-	cache := &CachedStorage{storage: storage}
-	cache.logsCache, _ = lru.NewCache(cacheSize, func(logID string) (*solaris.Log, error) {
-		return storage.GetLogByID(context.Background(), logID)
+	return NewCachedStorageWithTTL(storage, 0)
+}
+
+// NewCachedStorageWithTTL wraps LogsChunksMetaStorage into cache, the cached entries are read
+// from the storage again when ttl passes since they were read. So the changes made by other nodes
+// are seen not later than in ttl. Zero ttl means the entries don't expire.
+func NewCachedStorageWithTTL(storage LogsChunksMetaStorage, ttl time.Duration) *CachedStorage {
+	return NewCachedStorageWithConfig(storage, Config{TTL: ttl})
+}
+
+// NewCachedStorageWithConfig wraps LogsChunksMetaStorage into cache with the settings provided
+func NewCachedStorageWithConfig(storage LogsChunksMetaStorage, cfg Config) *CachedStorage {
+	cache := &CachedStorage{storage: storage, logger: logging.NewLogger("cache.CachedStorage"), ttl: cfg.TTL, now: time.Now}
+	cache.logsCache, _ = lru.NewCache(cacheSize, func(logID string) (lru.ExpirableItem[*solaris.Log], error) {
+		log, err := storage.GetLogByID(context.Background(), logID)
+		if cfg.NegativeTTL > 0 && errors.Is(err, errors.ErrNotExist) {
+			// the missing log is cached as nil
+			return lru.NewCacheItem[*solaris.Log](nil, cache.now().Add(cfg.NegativeTTL)), nil
+		}
+		return newItem(cache, log), err
 	}, nil)
-	cache.chunksCache, _ = lru.NewCache(cacheSize, func(logID string) ([]logfs.ChunkInfo, error) {
+	getChunks := func(logID string) (lru.ExpirableItem[[]logfs.ChunkInfo], error) {
 		cis, err := storage.GetChunks(context.Background(), logID)
 		if err != nil {
-			return nil, err
+			return lru.ExpirableItem[[]logfs.ChunkInfo]{}, err
 		}
 		sort.Slice(cis, func(i, j int) bool {
 			return cis[i].ID < cis[j].ID
 		})
-		return cis, nil
-	}, nil)
+		return newItem(cache, cis), nil
+	}
+	if cfg.ChunksCacheMaxBytes > 0 {
+		cache.chunksCache, _ = lru.NewSizedCache(cfg.ChunksCacheMaxBytes, chunksSize, getChunks, nil)
+	} else {
+		cache.chunksCache, _ = lru.NewCache(cacheSize, getChunks, nil)
+	}
 	return cache
 }
 
+// chunksSize returns the approximate size of the cached chunks list, the empty
+// list is counted as one chunk, so the number of the cached lists is limited anyway
+func chunksSize(item lru.ExpirableItem[[]logfs.ChunkInfo]) int64 {
+	return int64(max(len(item.Value), 1)) * chunkInfoSize
+}
+
+// newItem returns the cache item for the value v read from the storage now
+func newItem[V any](s *CachedStorage, v V) lru.ExpirableItem[V] {
+	var expiresAt time.Time
+	if s.ttl > 0 {
+		expiresAt = s.now().Add(s.ttl)
+	}
+	return lru.NewCacheItem(v, expiresAt)
+}
+
+// getOrCreate returns the cached value by the key, the expired value is removed from the cache
+// and read from the storage again
+func getOrCreate[V any](s *CachedStorage, c *lru.Cache[string, lru.ExpirableItem[V]], expired *atomic.Int64, key string) (V, error) {
+	item, err := c.GetOrCreate(key)
+	if err == nil && !item.ExpiresAt.IsZero() && !s.now().Before(item.ExpiresAt) {
+		expired.Add(1)
+		c.Remove(key)
+		item, err = c.GetOrCreate(key)
+	}
+	return item.Value, err
+}
+
+// Stats returns the logs and chunks caches counters
+func (s *CachedStorage) Stats() Stats {
+	return Stats{Logs: cacheStats(s.logsCache.Stats(), s.logsExpired.Load()),
+		Chunks: cacheStats(s.chunksCache.Stats(), s.chunksExpired.Load())}
+}
+
+// cacheStats returns the cache counters, the expired entries found in the cache are not hits
+func cacheStats(cs lru.CacheStats, expired int64) CacheStats {
+	return CacheStats{Hits: cs.Hits - expired, Misses: cs.Misses, Evictions: cs.Evictions, Expirations: expired}
+}
+
 // Init implements linker.Initializer
 func (s *CachedStorage) Init(ctx context.Context) error {
 	if init, ok := s.storage.(linker.Initializer); ok {
@@ -79,12 +194,35 @@ func (s *CachedStorage) Shutdown() {
 
 // CreateLog implements storage.Logs
 func (s *CachedStorage) CreateLog(ctx context.Context, log *solaris.Log) (*solaris.Log, error) {
-	return s.storage.CreateLog(ctx, log)
+	res, err := s.storage.CreateLog(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+	// the log could be cached as not existing
+	s.logsCache.Remove(res.ID)
+	return res, nil
+}
+
+// CreateLogs implements storage.Logs
+func (s *CachedStorage) CreateLogs(ctx context.Context, logs []*solaris.Log) ([]*solaris.Log, error) {
+	res, err := s.storage.CreateLogs(ctx, logs)
+	if err != nil {
+		return nil, err
+	}
+	// the logs could be cached as not existing
+	for _, l := range res {
+		s.logsCache.Remove(l.ID)
+	}
+	return res, nil
 }
 
 // GetLogByID implements storage.Logs
 func (s *CachedStorage) GetLogByID(ctx context.Context, id string) (*solaris.Log, error) {
-	return s.logsCache.GetOrCreate(id)
+	log, err := getOrCreate(s, s.logsCache, &s.logsExpired, id)
+	if err == nil && log == nil {
+		return nil, errors.ErrNotExist
+	}
+	return log, err
 }
 
 // UpdateLog implements storage.Logs
@@ -102,34 +240,87 @@ func (s *CachedStorage) QueryLogs(ctx context.Context, qr storage.QueryLogsReque
 	return s.storage.QueryLogs(ctx, qr)
 }
 
-// DeleteLogs implements storage.Logs
+// DeleteLogs implements storage.Logs. If the request is not MarkOnly, the logs are marked
+// for deletion first, then their chunks are deleted, and the logs are deleted physically after
+// that. A log, which chunks could not be deleted (they are used at the time, for instance),
+// stays marked for deletion.
 func (s *CachedStorage) DeleteLogs(ctx context.Context, request storage.DeleteLogsRequest) (*solaris.DeleteLogsResult, error) {
+	if request.MarkOnly || s.ChnkProvider == nil {
+		dr, err := s.storage.DeleteLogs(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		s.purge(dr.DeletedIDs)
+		return dr, nil
+	}
+
+	request.MarkOnly = true
 	dr, err := s.storage.DeleteLogs(ctx, request)
 	if err != nil {
 		return nil, err
 	}
+	s.purge(dr.DeletedIDs)
+
+	var freed int64
+	deleted := make([]string, 0, len(dr.DeletedIDs))
 	for _, id := range dr.DeletedIDs {
+		n, err := s.deleteChunks(ctx, id)
+		freed += n
+		if err != nil {
+			s.logger.Warnf("could not delete the chunks of the log ID=%s, the log stays marked for deletion: %v", id, err)
+			continue
+		}
+		deleted = append(deleted, id)
+	}
+	if len(deleted) > 0 {
+		if _, err := s.storage.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: deleted}); err != nil {
+			return nil, err
+		}
+		s.purge(deleted)
+	}
+	dr.FreedBytes = freed
+	return dr, nil
+}
+
+// deleteChunks deletes all the log chunks and returns the number of the freed bytes
+func (s *CachedStorage) deleteChunks(ctx context.Context, logID string) (int64, error) {
+	cis, err := s.storage.GetChunks(ctx, logID)
+	if err != nil {
+		return 0, err
+	}
+	var freed int64
+	for _, ci := range cis {
+		n, err := s.ChnkProvider.DeleteChunk(ctx, ci.ID)
+		if err != nil {
+			return freed, err
+		}
+		freed += n
+	}
+	return freed, nil
+}
+
+func (s *CachedStorage) purge(logIDs []string) {
+	for _, id := range logIDs {
 		s.logsCache.Remove(id)
 		s.chunksCache.Remove(id)
 	}
-	return dr, nil
 }
 
 // GetLastChunk implements logfs.LogsMetaStorage
 func (s *CachedStorage) GetLastChunk(ctx context.Context, logID string) (logfs.ChunkInfo, error) {
-	cis, err := s.chunksCache.GetOrCreate(logID)
+	cis, err := getOrCreate(s, s.chunksCache, &s.chunksExpired, logID)
 	if err != nil {
 		return logfs.ChunkInfo{}, err
 	}
 	if len(cis) == 0 {
-		return logfs.ChunkInfo{}, nil
+		return logfs.ChunkInfo{}, errors.ErrNotExist
 	}
 	return cis[len(cis)-1], nil
 }
 
 // GetChunks implements logfs.LogsMetaStorage
 func (s *CachedStorage) GetChunks(ctx context.Context, logID string) ([]logfs.ChunkInfo, error) {
-	return s.chunksCache.GetOrCreate(logID)
+	return getOrCreate(s, s.chunksCache, &s.chunksExpired, logID)
 }
 
 // UpsertChunkInfos implements logfs.LogsMetaStorage
@@ -140,3 +331,47 @@ func (s *CachedStorage) UpsertChunkInfos(ctx context.Context, logID string, cis
 	s.chunksCache.Remove(logID)
 	return nil
 }
+
+// DeleteChunkInfos implements logfs.LogsMetaStorage
+func (s *CachedStorage) DeleteChunkInfos(ctx context.Context, logID string, cIDs []string) error {
+	if err := s.storage.DeleteChunkInfos(ctx, logID, cIDs); err != nil {
+		return err
+	}
+	s.chunksCache.Remove(logID)
+	return nil
+}
+
+// GetLastAppendKey implements logfs.LogsMetaStorage
+func (s *CachedStorage) GetLastAppendKey(ctx context.Context, logID string) (logfs.AppendKey, error) {
+	return s.storage.GetLastAppendKey(ctx, logID)
+}
+
+// SetLastAppendKey implements logfs.LogsMetaStorage
+func (s *CachedStorage) SetLastAppendKey(ctx context.Context, logID string, ak logfs.AppendKey) error {
+	return s.storage.SetLastAppendKey(ctx, logID, ak)
+}
+
+// GetLogKeyID implements logfs.LogsMetaStorage
+func (s *CachedStorage) GetLogKeyID(ctx context.Context, logID string) (string, error) {
+	return s.storage.GetLogKeyID(ctx, logID)
+}
+
+// SetLogKeyID implements logfs.LogsMetaStorage
+func (s *CachedStorage) SetLogKeyID(ctx context.Context, logID, keyID string) error {
+	return s.storage.SetLogKeyID(ctx, logID, keyID)
+}
+
+// AddRecordHashes implements storage.RecordHashes
+func (s *CachedStorage) AddRecordHashes(ctx context.Context, logID string, rhs []storage.RecordHash) error {
+	return s.storage.AddRecordHashes(ctx, logID, rhs)
+}
+
+// GetRecordIDsByHash implements storage.RecordHashes
+func (s *CachedStorage) GetRecordIDsByHash(ctx context.Context, logID, hash string) ([]string, error) {
+	return s.storage.GetRecordIDsByHash(ctx, logID, hash)
+}
+
+// DeleteRecordHashes implements storage.RecordHashes
+func (s *CachedStorage) DeleteRecordHashes(ctx context.Context, logID, fromID, toID string) error {
+	return s.storage.DeleteRecordHashes(ctx, logID, fromID, toID)
+}
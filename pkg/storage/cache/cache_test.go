@@ -0,0 +1,312 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"github.com/oklog/ulid/v2"
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/sss/inmem"
+	"github.com/solarisdb/solaris/pkg/storage"
+	"github.com/solarisdb/solaris/pkg/storage/buntdb"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
+	"github.com/solarisdb/solaris/pkg/storage/logfs"
+	"github.com/solarisdb/solaris/pkg/storage/logfs/logfstest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCachedStorage_DeleteLogs(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestCachedStorage_DeleteLogs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	bs := buntdb.NewStorage(buntdb.Config{})
+	assert.Nil(t, bs.Init(ctx))
+	defer bs.Shutdown()
+
+	p := chunkfs.NewProvider(dir, 10, chunkfs.GetDefaultConfig())
+	p.Replicator = chunkfs.NewReplicator(p.GetFileNameByID, chunkfs.GetDefaultReplicatorConfig())
+	p.Replicator.Storage = inmem.NewStorage()
+	p.CA = chunkfs.NewChunkAccessor()
+	p.Replicator.CA = p.CA
+	defer p.Close()
+
+	s := NewCachedStorage(bs)
+	s.ChnkProvider = p
+
+	newLog := func() (string, string) {
+		log, err := s.CreateLog(ctx, &solaris.Log{})
+		assert.Nil(t, err)
+		cID := ulid.Make().String()
+		rc, err := p.GetOpenedChunk(ctx, cID, true)
+		assert.Nil(t, err)
+		_, err = rc.Value().AppendRecords([]*solaris.Record{{Payload: []byte("hello")}})
+		assert.Nil(t, err)
+		p.ReleaseChunk(&rc)
+		assert.Nil(t, s.UpsertChunkInfos(ctx, log.ID, []logfs.ChunkInfo{{ID: cID, RecordsCount: 1}}))
+		_, err = s.GetChunks(ctx, log.ID)
+		assert.Nil(t, err)
+		return log.ID, cID
+	}
+
+	// the marked only log keeps its chunks
+	lID, cID := newLog()
+	dr, err := s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{lID}, MarkOnly: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{lID}, dr.DeletedIDs)
+	assert.Equal(t, int64(0), dr.FreedBytes)
+	_, err = os.Stat(p.GetFileNameByID(cID))
+	assert.Nil(t, err)
+
+	// the deleted log chunks are deleted
+	lID, cID = newLog()
+	dr, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{lID}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{lID}, dr.DeletedIDs)
+	assert.True(t, dr.FreedBytes > 0)
+	_, err = os.Stat(p.GetFileNameByID(cID))
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	_, err = s.GetChunks(ctx, lID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+
+	// the log which chunk is read at the time stays marked for deletion
+	lID, cID = newLog()
+	rc, err := p.GetOpenedChunk(ctx, cID, false)
+	assert.Nil(t, err)
+	dr, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{lID}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{lID}, dr.DeletedIDs)
+	assert.Equal(t, int64(0), dr.FreedBytes)
+	_, err = os.Stat(p.GetFileNameByID(cID))
+	assert.Nil(t, err)
+	_, err = s.GetLogByID(ctx, lID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	cis, err := s.GetChunks(ctx, lID)
+	assert.Nil(t, err)
+	assert.Len(t, cis, 1)
+	p.ReleaseChunk(&rc)
+}
+
+func TestCachedStorage_TTL(t *testing.T) {
+	ctx := context.Background()
+	bs := buntdb.NewStorage(buntdb.Config{})
+	assert.Nil(t, bs.Init(ctx))
+	defer bs.Shutdown()
+
+	now := time.Now()
+	s := NewCachedStorageWithTTL(bs, time.Minute)
+	s.now = func() time.Time { return now }
+
+	log, err := s.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"v": "1"}})
+	assert.Nil(t, err)
+	assert.Nil(t, s.UpsertChunkInfos(ctx, log.ID, []logfs.ChunkInfo{{ID: ulid.Make().String()}}))
+	checkCached := func(v string, chunks int) {
+		l, err := s.GetLogByID(ctx, log.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, v, l.Tags["v"])
+		cis, err := s.GetChunks(ctx, log.ID)
+		assert.Nil(t, err)
+		assert.Len(t, cis, chunks)
+	}
+	// the changes made by another node, bypassing the cache
+	updateStorage := func(v string) {
+		_, err = bs.UpdateLog(ctx, &solaris.Log{ID: log.ID, Tags: map[string]string{"v": v}})
+		assert.Nil(t, err)
+		assert.Nil(t, bs.UpsertChunkInfos(ctx, log.ID, []logfs.ChunkInfo{{ID: ulid.Make().String()}}))
+	}
+	checkCached("1", 1)
+
+	// the entries are stale until they expire
+	updateStorage("2")
+	now = now.Add(59 * time.Second)
+	checkCached("1", 1)
+	now = now.Add(time.Second)
+	checkCached("2", 2)
+
+	// the entries removed on the changes are read again and expire in ttl since then
+	now = now.Add(30 * time.Second)
+	_, err = s.UpdateLog(ctx, &solaris.Log{ID: log.ID, Tags: map[string]string{"v": "3"}})
+	assert.Nil(t, err)
+	assert.Nil(t, s.UpsertChunkInfos(ctx, log.ID, []logfs.ChunkInfo{{ID: ulid.Make().String()}}))
+	checkCached("3", 3)
+	updateStorage("4")
+	now = now.Add(45 * time.Second)
+	checkCached("3", 3)
+	now = now.Add(15 * time.Second)
+	checkCached("4", 4)
+
+	// the entries don't expire with zero ttl
+	s = NewCachedStorage(bs)
+	s.now = func() time.Time { return now }
+	checkCached("4", 4)
+	updateStorage("5")
+	now = now.Add(24 * time.Hour)
+	checkCached("4", 4)
+}
+
+func TestCachedStorage_Stats(t *testing.T) {
+	ctx := context.Background()
+	bs := buntdb.NewStorage(buntdb.Config{})
+	assert.Nil(t, bs.Init(ctx))
+	defer bs.Shutdown()
+
+	now := time.Now()
+	s := NewCachedStorageWithTTL(bs, time.Minute)
+	s.now = func() time.Time { return now }
+
+	log, err := bs.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = s.GetLogByID(ctx, log.ID)
+		assert.Nil(t, err)
+	}
+	_, err = s.GetChunks(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, Stats{Logs: CacheStats{Hits: 2, Misses: 1}, Chunks: CacheStats{Misses: 1}}, s.Stats())
+
+	// the expired entries are misses
+	now = now.Add(time.Minute)
+	_, err = s.GetLogByID(ctx, log.ID)
+	assert.Nil(t, err)
+	_, err = s.GetChunks(ctx, log.ID)
+	assert.Nil(t, err)
+	_, err = s.GetChunks(ctx, log.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, Stats{Logs: CacheStats{Hits: 2, Misses: 2, Expirations: 1},
+		Chunks: CacheStats{Hits: 1, Misses: 2, Expirations: 1}}, s.Stats())
+}
+
+func TestCachedStorage_ChunksCacheMaxBytes(t *testing.T) {
+	ctx := context.Background()
+	bs := buntdb.NewStorage(buntdb.Config{})
+	assert.Nil(t, bs.Init(ctx))
+	defer bs.Shutdown()
+
+	const chunksPerLog = 100
+	maxBytes := 3 * chunksPerLog * chunkInfoSize
+	s := NewCachedStorageWithConfig(bs, Config{ChunksCacheMaxBytes: maxBytes})
+	var logIDs []string
+	for i := 0; i < 10; i++ {
+		log, err := bs.CreateLog(ctx, &solaris.Log{})
+		assert.Nil(t, err)
+		cis := make([]logfs.ChunkInfo, chunksPerLog)
+		for j := range cis {
+			cis[j] = logfs.ChunkInfo{ID: ulid.Make().String()}
+		}
+		assert.Nil(t, bs.UpsertChunkInfos(ctx, log.ID, cis))
+		logIDs = append(logIDs, log.ID)
+	}
+
+	for _, lid := range logIDs {
+		cis, err := s.GetChunks(ctx, lid)
+		assert.Nil(t, err)
+		assert.Len(t, cis, chunksPerLog)
+		assert.LessOrEqual(t, s.chunksCache.TotalSize(), maxBytes)
+	}
+	assert.Equal(t, maxBytes, s.chunksCache.TotalSize())
+	assert.Equal(t, int64(len(logIDs)-3), s.Stats().Chunks.Evictions)
+
+	// the last 3 lists are cached
+	for _, lid := range logIDs[len(logIDs)-3:] {
+		_, err := s.GetChunks(ctx, lid)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int64(3), s.Stats().Chunks.Hits)
+
+	// the count-based cache is not bounded by the lists size
+	s = NewCachedStorage(bs)
+	for _, lid := range logIDs {
+		_, err := s.GetChunks(ctx, lid)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int64(0), s.Stats().Chunks.Evictions)
+	assert.Equal(t, int64(0), s.chunksCache.TotalSize())
+}
+
+func TestCachedStorage_NegativeTTL(t *testing.T) {
+	ctx := context.Background()
+	bs := buntdb.NewStorage(buntdb.Config{})
+	assert.Nil(t, bs.Init(ctx))
+	defer bs.Shutdown()
+
+	ts := &testLogsStorage{Storage: bs, logs: map[string]*solaris.Log{}}
+	now := time.Now()
+	s := NewCachedStorageWithConfig(ts, Config{NegativeTTL: time.Second})
+	s.now = func() time.Time { return now }
+
+	// the missing log is looked up in the storage once
+	for i := 0; i < 3; i++ {
+		_, err := s.GetLogByID(ctx, "l1")
+		assert.True(t, errors.Is(err, errors.ErrNotExist))
+	}
+	assert.Equal(t, 1, ts.gets)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, s.Stats().Logs)
+
+	// the negative entry expires
+	now = now.Add(time.Second)
+	_, err := s.GetLogByID(ctx, "l1")
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	assert.Equal(t, 2, ts.gets)
+
+	// the negative entry is removed when the log is created
+	_, err = s.CreateLog(ctx, &solaris.Log{ID: "l1"})
+	assert.Nil(t, err)
+	log, err := s.GetLogByID(ctx, "l1")
+	assert.Nil(t, err)
+	assert.Equal(t, "l1", log.ID)
+	assert.Equal(t, 3, ts.gets)
+
+	// the missing logs are not cached with zero NegativeTTL
+	s = NewCachedStorage(ts)
+	for i := 0; i < 3; i++ {
+		_, err := s.GetLogByID(ctx, "l2")
+		assert.True(t, errors.Is(err, errors.ErrNotExist))
+	}
+	assert.Equal(t, 6, ts.gets)
+}
+
+// testLogsStorage keeps the logs with the IDs they are created with and counts the GetLogByID calls
+type testLogsStorage struct {
+	*buntdb.Storage
+	logs map[string]*solaris.Log
+	gets int
+}
+
+func (s *testLogsStorage) CreateLog(_ context.Context, log *solaris.Log) (*solaris.Log, error) {
+	s.logs[log.ID] = log
+	return log, nil
+}
+
+func (s *testLogsStorage) GetLogByID(_ context.Context, id string) (*solaris.Log, error) {
+	s.gets++
+	if log, ok := s.logs[id]; ok {
+		return log, nil
+	}
+	return nil, errors.ErrNotExist
+}
+
+func TestCachedStorage_Conformance(t *testing.T) {
+	ctx := context.Background()
+	bs := buntdb.NewStorage(buntdb.Config{})
+	assert.Nil(t, bs.Init(ctx))
+	defer bs.Shutdown()
+
+	logfstest.RunLogsMetaStorageConformance(t, func() logfs.LogsMetaStorage { return NewCachedStorage(bs) })
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkfs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+)
+
+// The chunks of the current format store the record attributes right before the record payload, so a stored record
+// is the attributes, the payload (encoded by the chunk codec) and the checksum of both. The attributes are the uvarint
+// number of the attributes followed by the uvarint length prefixed keys and values sorted by the keys, so the records
+// without attributes take one byte more only.
+
+// noAttrs is the stored attributes of the record without attributes, it must never be modified
+var noAttrs = []byte{0}
+
+// encodeAttrs returns the attributes as they are stored in the chunk
+func encodeAttrs(attrs map[string]string) []byte {
+	if len(attrs) == 0 {
+		return noAttrs
+	}
+	keys := make([]string, 0, len(attrs))
+	size := binary.MaxVarintLen64
+	for k, v := range attrs {
+		keys = append(keys, k)
+		size += len(k) + len(v) + 2*binary.MaxVarintLen64
+	}
+	sort.Strings(keys)
+	res := make([]byte, 0, size)
+	res = binary.AppendUvarint(res, uint64(len(keys)))
+	for _, k := range keys {
+		res = appendString(res, k)
+		res = appendString(res, attrs[k])
+	}
+	return res
+}
+
+// decodeAttrs returns the attributes and the rest of the stored record, which is the payload. The attributes
+// are nil if the record has no attributes. The attributes keys and values are copied, so they outlive the reader.
+func decodeAttrs(stored []byte) (map[string]string, []byte, error) {
+	n, l := binary.Uvarint(stored)
+	if l <= 0 || n > uint64(len(stored)) {
+		return nil, nil, fmt.Errorf("wrong number of the record attributes: %w", errors.ErrCorrupted)
+	}
+	stored = stored[l:]
+	if n == 0 {
+		return nil, stored, nil
+	}
+	res := make(map[string]string, n)
+	for i := uint64(0); i < n; i++ {
+		var k, v string
+		var err error
+		if k, stored, err = readString(stored); err != nil {
+			return nil, nil, err
+		}
+		if v, stored, err = readString(stored); err != nil {
+			return nil, nil, err
+		}
+		res[k] = v
+	}
+	return res, stored, nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func readString(buf []byte) (string, []byte, error) {
+	ln, l := binary.Uvarint(buf)
+	if l <= 0 || ln > uint64(len(buf)-l) {
+		return "", nil, fmt.Errorf("wrong length of the record attribute: %w", errors.ErrCorrupted)
+	}
+	end := l + int(ln)
+	return string(buf[l:end]), buf[end:], nil
+}
+
+// AppendAttributes appends the attributes to buf encoded the same way they are stored in the chunk, so
+// the records stored in a custom way (e.g. encrypted) could keep their attributes, see DecodeAttributes
+func AppendAttributes(buf []byte, attrs map[string]string) []byte {
+	return append(buf, encodeAttrs(attrs)...)
+}
+
+// DecodeAttributes returns the attributes appended by AppendAttributes and the rest of the buf
+func DecodeAttributes(buf []byte) (map[string]string, []byte, error) {
+	return decodeAttrs(buf)
+}
@@ -19,7 +19,9 @@ import (
 	"fmt"
 	"github.com/logrange/linker"
 	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/metrics"
 	"sync"
+	"time"
 )
 
 type (
@@ -66,6 +68,7 @@ func (cc *ChunkAccessor) Shutdown() {
 }
 
 func (cc *ChunkAccessor) openChunk(ctx context.Context, cID string) error {
+	defer metrics.ObserveSince(metrics.ChunkReadWait, time.Now())
 	for {
 		cc.lock.Lock()
 		if cc.closed {
@@ -110,6 +113,7 @@ func (cc *ChunkAccessor) openChunk(ctx context.Context, cID string) error {
 
 // SetWriting requests writing access to the chunk. The function must followed by SetIdle() call to release the write access
 func (cc *ChunkAccessor) SetWriting(ctx context.Context, cID string) error {
+	defer metrics.ObserveSince(metrics.ChunkWriteWait, time.Now())
 	for {
 		cc.lock.Lock()
 		if cc.closed {
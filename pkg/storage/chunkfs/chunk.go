@@ -18,11 +18,14 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/cast"
 	"github.com/solarisdb/solaris/golibs/container/iterable"
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/files"
@@ -41,8 +44,31 @@ type (
 		// freeOffset points to the first available byte for write
 		freeOffset int
 		// total contains number of records
-		total  int
-		logger logging.Logger
+		total int
+		// version is the chunk format version read from the header
+		version int
+		// codec defines how the records payloads are stored, it is read from the header
+		codec codec
+		// crcSize is the size of the checksum stored after every record payload, it is 0 for the chunks
+		// written in the format without the checksums
+		crcSize int
+		// hasAttrs is true if the records attributes are stored in the chunk
+		hasAttrs bool
+		// unsynced is the number of bytes appended since the last flush to the disk
+		unsynced int
+		// syncTimer flushes the chunk for the SyncInterval policy, it is nil if nothing to flush
+		syncTimer *time.Timer
+		logger    logging.Logger
+	}
+
+	// chunkFormat describes the records layout of a chunk format version
+	chunkFormat struct {
+		// crcSize is the size of the checksum stored after every record payload
+		crcSize int
+		// hasCodec specifies the header contains the records payloads codec
+		hasCodec bool
+		// hasAttrs specifies the records attributes are stored before the records payloads
+		hasAttrs bool
 	}
 
 	// ChunkReader is a helper structure which allows to read records from a chunk. The ChunkReader
@@ -53,13 +79,29 @@ type (
 		inc int
 		idx int
 		mb  metaBuf
+		err error
+		// raStart and raEnd is the payloads region [raStart..raEnd) requested to be read ahead
+		raStart int
+		raEnd   int
 	}
 
 	// UnsafeRecord represent a chunk record. This is a short-life object which may be used ONLY when ChunkReader is open.
 	// If the record time should be longer, the UnsafePayload MUST be copied to another memory.
+	//
+	// The chunk file is memory mapped, and the UnsafePayload of a not compressed chunk points right into the mapped
+	// region, no copy is made on read. The region is remapped when the chunk grows, what happens under the chunk
+	// write lock only, so the UnsafePayload stays valid until the ChunkReader is closed. It must never be modified.
 	UnsafeRecord struct {
 		ID            ulid.ULID
 		UnsafePayload []byte
+		// Idx is the record index in the chunk
+		Idx int
+		// Size is the record payload size as it is stored in the chunk. It differs from
+		// the UnsafePayload length if the chunk payloads are compressed.
+		Size int
+		// Attributes are the record attributes, it is nil if the record has no attributes. Unlike
+		// the UnsafePayload, the attributes are copied from the chunk, so they may be kept.
+		Attributes map[string]string
 	}
 
 	// AppendRecordsResult is used to report the append records operation result
@@ -84,6 +126,28 @@ type (
 		NewSize             int64
 		MaxChunkSize        int64
 		MaxGrowIncreaseSize int64
+		// SkipCRCCheck disables the records checksums verification on read. The checksums are
+		// still written, so the verification may be turned on later.
+		SkipCRCCheck bool
+		// Compression defines the codec of the records payloads for the new chunks (see CompressionNone and
+		// CompressionZstd). The codec is stored in the chunk header, so the chunks written with another codec
+		// are still read and appended with their own one.
+		Compression string
+		// OpenedIdleTimeout defines how long the Provider keeps an opened chunk, which is not used. Zero
+		// value means the not used chunks are closed only when the opened chunks limit is reached.
+		OpenedIdleTimeout time.Duration
+		// ReadAheadSize defines how many bytes of the records payloads ahead of the ChunkReader position
+		// are requested from the disk at once, so a sequential scan of a big chunk, which is not in the page
+		// cache, doesn't wait for the disk on every page. Zero value disables the read-ahead.
+		ReadAheadSize int
+		// SyncPolicy defines when the appended records are flushed to the disk (see SyncNone, SyncPerAppend
+		// and SyncInterval), the records durability on the OS crash depends on the policy
+		SyncPolicy string
+		// SyncInterval defines how long the appended records may stay not flushed for the SyncInterval policy
+		SyncInterval time.Duration
+		// SyncBytes defines how many bytes may be appended before the flush for the SyncInterval policy.
+		// Zero value means the records are flushed by the SyncInterval only.
+		SyncBytes int
 	}
 )
 
@@ -97,20 +161,65 @@ const (
 	cHeaderSize   = 32
 	// cMetaRecordSize is the size of one meta-record
 	cMetaRecordSize = 24
+	// cCRCSize is the size of the record payload checksum (CRC32C)
+	cCRCSize = 4
 )
 
-var hdrVersion = []byte{'S', 'O', 'L', 'A', 'R', 'I', 'S', 1}
+const (
+	// cVersionNoCRC is the format of the chunks written before the checksums were introduced, the chunks
+	// are still read and appended in their own format
+	cVersionNoCRC = 1
+	// cVersionNoCodec is the format, where every record payload is followed by its CRC32C checksum
+	cVersionNoCodec = 2
+	// cVersionNoAttrs is cVersionNoCodec with the records payloads codec in the header
+	cVersionNoAttrs = 3
+	// cVersion is the current chunk format, it is cVersionNoAttrs, where every record payload is preceded by
+	// the record attributes (see encodeAttrs). The new chunks are always written in the current format.
+	cVersion = 4
+	// cCodecOffset is the offset of the codec byte in the header
+	cCodecOffset = 12
+)
+
+// hdrVersion is the header prefix of the current chunk format, the last byte is the format version
+var hdrVersion = []byte{'S', 'O', 'L', 'A', 'R', 'I', 'S', cVersion}
+var hdrVersionNoCRC = []byte{'S', 'O', 'L', 'A', 'R', 'I', 'S', cVersionNoCRC}
+
+// formats contains the supported chunk formats by their versions
+var formats = map[int]chunkFormat{
+	cVersionNoCRC:   {crcSize: 0},
+	cVersionNoCodec: {crcSize: cCRCSize},
+	cVersionNoAttrs: {crcSize: cCRCSize, hasCodec: true},
+	cVersion:        {crcSize: cCRCSize, hasCodec: true, hasAttrs: true},
+}
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
 var _ iterable.Iterator[UnsafeRecord] = (*ChunkReader)(nil)
-var errCorrupted = fmt.Errorf("file chunk corrupted")
+var errCorrupted = fmt.Errorf("file chunk corrupted: %w", errors.ErrCorrupted)
 
 func GetDefaultConfig() Config {
 	return Config{
 		NewSize:             cNewSize,
 		MaxChunkSize:        cMaxChunkSize,
 		MaxGrowIncreaseSize: cMaxGrowIncreaseSize,
+		SyncInterval:        cDefaultSyncInterval,
 	}
 }
 
+// MaxRecordSize returns the maximum size of the record (see RecordSize), which could be written into an empty
+// chunk of the current format. The stored size of the compressed payload is known after the payload is encoded
+// only, so the function returns 0 (the size is not limited in advance) if the payloads are compressed.
+func (c Config) MaxRecordSize() int {
+	if c.Compression != CompressionNone {
+		return 0
+	}
+	return int(c.MaxChunkSize) - cHeaderSize - cCRCSize - cMetaRecordSize
+}
+
+// RecordSize returns the size of the record payload with its attributes, as they are stored in the chunk
+// before the payload is compressed
+func RecordSize(r *solaris.Record) int {
+	return len(encodeAttrs(r.Attributes)) + len(r.Payload)
+}
+
 func (mb metaBuf) get(idx int) metaRec {
 	off := len(mb) - (idx+1)*cMetaRecordSize
 	var mr metaRec
@@ -150,8 +259,32 @@ func NewChunk(fileName, id string, cfg Config) *Chunk {
 func (c *Chunk) String() string {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
-	return fmt.Sprintf("Chunk{id:%s, total:%d, freeOffset:%d}",
-		c.id, c.total, c.freeOffset)
+	return fmt.Sprintf("Chunk{id:%s, version:%d, total:%d, freeOffset:%d}",
+		c.id, c.version, c.total, c.freeOffset)
+}
+
+// Version returns the format version of the opened chunk. The chunks of the previous versions
+// are read and appended in their own format, the new chunks get the current version.
+func (c *Chunk) Version() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.version
+}
+
+// Outdated returns true if the opened chunk records have no checksums, or its records payloads codec is not
+// the configured one, so the chunk should be rewritten. The chunks of the other previous formats are not
+// outdated, cause they differ from the current one by the records attributes only, which are never written there.
+func (c *Chunk) Outdated() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return outdated(c.crcSize, c.codec, c.cfg.Compression)
+}
+
+// outdated returns true if the chunk with the records checksums size and the records payloads codec
+// should be rewritten, when the new chunks are written with the compression
+func outdated(crcSize int, cd codec, compression string) bool {
+	ccd, err := codecByName(compression)
+	return crcSize == 0 || (err == nil && ccd != cd)
 }
 
 // Open allows to map the chunk file context to the memory and start working with the chunk
@@ -163,6 +296,9 @@ func (c *Chunk) Open(fullCheck bool) error {
 		return nil
 	}
 	c.logger.Debugf("opening, fullCheck=%t", fullCheck)
+	if err := checkSyncPolicy(c.cfg.SyncPolicy); err != nil {
+		return err
+	}
 	mmf, err := files.NewMMFile(c.fn, c.cfg.NewSize)
 	if err != nil {
 		return err
@@ -183,11 +319,33 @@ func (c *Chunk) init(fullCheck bool) error {
 		return err
 	}
 	vLen := len(hdrVersion)
-	if !bytes.Equal(hdr[:vLen], hdrVersion) {
+	if bytes.Equal(hdr[:vLen-1], hdrVersion[:vLen-1]) {
+		c.version = int(hdr[vLen-1])
+	} else {
+		cd, err := codecByName(c.cfg.Compression)
+		if err != nil {
+			return err
+		}
 		// makes everything empty
 		copy(hdr[:vLen], hdrVersion)
 		// total count
 		binary.BigEndian.PutUint32(hdr[vLen:vLen+4], uint32(0))
+		hdr[cCodecOffset] = byte(cd)
+		c.version = cVersion
+	}
+	f, ok := formats[c.version]
+	if !ok {
+		// the chunk could be written by a newer version, so don't touch it
+		return fmt.Errorf("the chunk format version=%d is not supported: %w", c.version, errors.ErrUnimplemented)
+	}
+	c.crcSize = f.crcSize
+	c.hasAttrs = f.hasAttrs
+	c.codec = codecNone
+	if f.hasCodec {
+		c.codec = codec(hdr[cCodecOffset])
+		if c.codec > codecZstd {
+			return fmt.Errorf("the chunk codec=%d is not supported: %w", c.codec, errors.ErrUnimplemented)
+		}
 	}
 	c.total = int(binary.BigEndian.Uint32(hdr[vLen : vLen+4]))
 	if c.total < 0 {
@@ -251,6 +409,9 @@ func (c *Chunk) close() error {
 	var err error
 	if c.mmf != nil {
 		c.logger.Debugf("closing")
+		if err = c.sync(); err != nil {
+			c.logger.Errorf("%v", err)
+		}
 		err = c.mmf.Close()
 		c.mmf = nil
 	}
@@ -261,6 +422,17 @@ func (c *Chunk) close() error {
 // the existing chunk. If the chunk reaches its maximum capacity it will not grow anymore. Only some records, that
 // fit into the chunk will be written. The result will contain the number of records actually written
 func (c *Chunk) AppendRecords(recs []*solaris.Record) (AppendRecordsResult, error) {
+	return c.appendRecords(recs, false)
+}
+
+// CopyRecords is the same as AppendRecords, but the records keep their IDs. The function allows to move the
+// records from one chunk to another, so the records IDs must be valid and sorted in the ascending order after
+// the last record ID of the chunk.
+func (c *Chunk) CopyRecords(recs []*solaris.Record) (AppendRecordsResult, error) {
+	return c.appendRecords(recs, true)
+}
+
+func (c *Chunk) appendRecords(recs []*solaris.Record, keepIDs bool) (AppendRecordsResult, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -268,7 +440,8 @@ func (c *Chunk) AppendRecords(recs []*solaris.Record) (AppendRecordsResult, erro
 		// chunk is closed
 		return AppendRecordsResult{}, fmt.Errorf("the chunk %s is closed: %w ", c.fn, errors.ErrClosed)
 	}
-	n, size := c.writable(recs)
+	attrs, payloads, size := c.writable(recs)
+	n := len(payloads)
 	if n == 0 {
 		return AppendRecordsResult{}, nil
 	}
@@ -285,16 +458,24 @@ func (c *Chunk) AppendRecords(recs []*solaris.Record) (AppendRecordsResult, erro
 
 	pOffset := c.freeOffset
 	var startID, lastID ulid.ULID
-	for i, r := range recs {
-		lastID = ulidutils.New()
-		recs[i].ID = lastID.String()
+	for i := range recs {
+		if keepIDs {
+			if err := lastID.UnmarshalText(cast.StringToByteArray(recs[i].ID)); err != nil {
+				return AppendRecordsResult{}, fmt.Errorf("wrong record ID=%q: %w", recs[i].ID, errors.ErrInvalid)
+			}
+		} else {
+			lastID = ulidutils.New()
+			recs[i].ID = lastID.String()
+		}
 		if i == 0 {
 			startID = lastID
 		}
-		mb.put(i, metaRec{ID: lastID, offset: int32(pOffset), size: int32(len(r.Payload))})
-		pOffset += len(r.Payload)
+		size := len(attrs[i]) + len(payloads[i]) + c.crcSize
+		mb.put(i, metaRec{ID: lastID, offset: int32(pOffset), size: int32(size)})
+		pOffset += size
 	}
 
+	total := c.total
 	pSize := pOffset - c.freeOffset
 	pBuf, err := c.mmf.Buffer(int64(c.freeOffset), pSize)
 	if err != nil {
@@ -302,9 +483,14 @@ func (c *Chunk) AppendRecords(recs []*solaris.Record) (AppendRecordsResult, erro
 		return AppendRecordsResult{}, fmt.Errorf("could not write data: %w", fmt.Errorf("could not map payload-buffer with offset %d for size=%d: %w", c.freeOffset, pSize, errors.ErrInternal))
 	}
 	pOffset = 0
-	for _, r := range recs {
-		copy(pBuf[pOffset:int(pOffset)+len(r.Payload)], r.Payload)
-		pOffset += len(r.Payload)
+	for i, p := range payloads {
+		start := pOffset
+		pOffset += copy(pBuf[pOffset:], attrs[i])
+		pOffset += copy(pBuf[pOffset:], p)
+		if c.crcSize > 0 {
+			binary.BigEndian.PutUint32(pBuf[pOffset:pOffset+c.crcSize], crc32.Checksum(pBuf[start:pOffset], crcTable))
+			pOffset += c.crcSize
+		}
 	}
 
 	c.freeOffset += pOffset
@@ -317,9 +503,50 @@ func (c *Chunk) AppendRecords(recs []*solaris.Record) (AppendRecordsResult, erro
 	}
 	binary.BigEndian.PutUint32(hdr, uint32(c.total))
 
+	if err := c.appended(pOffset + len(recs)*cMetaRecordSize); err != nil {
+		// the records are not durable, so they are not reported as written
+		_ = c.truncate(total)
+		return AppendRecordsResult{}, err
+	}
 	return AppendRecordsResult{Written: n, StartID: startID, LastID: lastID}, nil
 }
 
+// Truncate drops the chunk records starting from the index total, so only the first total records stay
+// in the chunk. The function allows to roll back the records appended by AppendRecords.
+func (c *Chunk) Truncate(total int) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.truncate(total)
+}
+
+func (c *Chunk) truncate(total int) error {
+	if c.mmf == nil {
+		// chunk is closed
+		return fmt.Errorf("the chunk %s is closed: %w ", c.fn, errors.ErrClosed)
+	}
+	if total < 0 || total > c.total {
+		return fmt.Errorf("could not truncate the chunk with %d records to %d: %w", c.total, total, errors.ErrInvalid)
+	}
+	freeOffset := cHeaderSize
+	if total > 0 {
+		mb, err := c.getMetaBuf(total-1, 1)
+		if err != nil {
+			return err
+		}
+		mr := mb.get(0)
+		freeOffset = int(mr.offset + mr.size)
+	}
+	hdr, err := c.mmf.Buffer(int64(len(hdrVersion)), 4)
+	if err != nil {
+		c.logger.Errorf("could not map records counter buffer with offset %d for size=4: %v", len(hdrVersion), err)
+		return fmt.Errorf("could not map records counter buffer with offset %d for size=4: %w", len(hdrVersion), errors.ErrInternal)
+	}
+	binary.BigEndian.PutUint32(hdr, uint32(total))
+	c.total = total
+	c.freeOffset = freeOffset
+	return nil
+}
+
 // getMetaBuf maps the meta-buffer for the index startIdx with ln number of meta-records
 func (c *Chunk) getMetaBuf(startIdx, ln int) (metaBuf, error) {
 	offs := c.mmf.Size() - int64(startIdx+1)*cMetaRecordSize
@@ -416,40 +643,113 @@ func (c *Chunk) available() int64 {
 	return c.mmf.Size() - int64(c.freeOffset+c.total*cMetaRecordSize)
 }
 
-// writable returns the number of records and the total size of the records, that can fit into the
-// chunk, even if it will grow.
-func (c *Chunk) writable(recs []*solaris.Record) (int, int) {
-	maxAvaialbe := int(c.cfg.MaxChunkSize) - c.freeOffset + c.total*cMetaRecordSize
+// writable returns the attributes and the payloads of the records as they will be stored, and the total size of
+// the records, that can fit into the chunk, even if it will grow. The payloads are encoded one by one until the chunk
+// is full, so the records, which don't fit, are not compressed in vain. The chunks of the formats without
+// the attributes take the records up to the first one with the attributes, so the attributes are never lost,
+// but the record and the following ones are written into a new chunk of the current format.
+func (c *Chunk) writable(recs []*solaris.Record) ([][]byte, [][]byte, int) {
+	maxAvaialbe := int(c.cfg.MaxChunkSize) - c.freeOffset - c.total*cMetaRecordSize
 	totalSize := 0
-	for i, r := range recs {
-		recSize := len(r.Payload) + cMetaRecordSize
+	attrs := make([][]byte, 0, len(recs))
+	payloads := make([][]byte, 0, len(recs))
+	for _, r := range recs {
+		var a []byte
+		if c.hasAttrs {
+			a = encodeAttrs(r.Attributes)
+		} else if len(r.Attributes) > 0 {
+			break
+		}
+		p := c.codec.encode(r.Payload)
+		recSize := len(a) + len(p) + c.crcSize + cMetaRecordSize
 		if totalSize+recSize > maxAvaialbe {
-			return i, totalSize
+			break
 		}
 		totalSize += recSize
+		attrs = append(attrs, a)
+		payloads = append(payloads, p)
 	}
-	return len(recs), totalSize
+	return attrs, payloads, totalSize
 }
 
 func (cr *ChunkReader) HasNext() bool {
-	return cr.idx < cr.c.total && cr.idx > -1
+	return cr.err == nil && cr.idx < cr.c.total && cr.idx > -1
 }
 
+// Next implements iterable.Iterator. The compressed payloads are decompressed, so the record UnsafePayload is
+// always the original one. If the record checksum doesn't match its payload, the function returns false and
+// the reader stops, the error is reported by Err() then.
 func (cr *ChunkReader) Next() (UnsafeRecord, bool) {
 	if cr.HasNext() {
 		mr := cr.mb.get(cr.idx)
+		if cr.c.cfg.ReadAheadSize > 0 {
+			cr.readAhead(mr)
+		}
 		buf, err := cr.c.mmf.Buffer(int64(mr.offset), int(mr.size))
 		if err != nil {
 			cr.c.logger.Errorf("could not read payload for offset=%d for len=%d: %v", mr.offset, mr.size, err)
 			panic(err)
 		}
-		res := UnsafeRecord{ID: mr.ID, UnsafePayload: buf}
+		if cs := cr.c.crcSize; cs > 0 {
+			if int(mr.size) < cs {
+				cr.err = fmt.Errorf("the record ID=%s in the chunk %s is too short=%d for the checksum: %w", mr.ID, cr.c.id, mr.size, errors.ErrCorrupted)
+				return UnsafeRecord{}, false
+			}
+			payload := buf[:len(buf)-cs]
+			if !cr.c.cfg.SkipCRCCheck && crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(buf[len(buf)-cs:]) {
+				cr.err = fmt.Errorf("the record ID=%s in the chunk %s checksum mismatch: %w", mr.ID, cr.c.id, errors.ErrCorrupted)
+				cr.c.logger.Errorf("%v", cr.err)
+				return UnsafeRecord{}, false
+			}
+			buf = payload
+		}
+		var attrs map[string]string
+		if cr.c.hasAttrs {
+			if attrs, buf, err = decodeAttrs(buf); err != nil {
+				cr.err = fmt.Errorf("the record ID=%s in the chunk %s attributes could not be decoded: %w", mr.ID, cr.c.id, err)
+				cr.c.logger.Errorf("%v", cr.err)
+				return UnsafeRecord{}, false
+			}
+		}
+		size := len(buf)
+		if cr.c.codec != codecNone {
+			if buf, err = cr.c.codec.decode(buf); err != nil {
+				cr.err = fmt.Errorf("the record ID=%s in the chunk %s could not be decoded: %w", mr.ID, cr.c.id, err)
+				cr.c.logger.Errorf("%v", cr.err)
+				return UnsafeRecord{}, false
+			}
+		}
+		res := UnsafeRecord{ID: mr.ID, UnsafePayload: buf, Idx: cr.idx, Size: size, Attributes: attrs}
 		cr.idx += cr.inc
 		return res, true
 	}
 	return UnsafeRecord{}, false
 }
 
+// readAhead requests the next ReadAheadSize bytes of the payloads in the reader direction, if
+// the record mr is out of the region requested before
+func (cr *ChunkReader) readAhead(mr metaRec) {
+	start, end := int(mr.offset), int(mr.offset+mr.size)
+	if start >= cr.raStart && end <= cr.raEnd {
+		return
+	}
+	if cr.inc > 0 {
+		end = min(max(end, start+cr.c.cfg.ReadAheadSize), cr.c.freeOffset)
+	} else {
+		start = max(min(start, end-cr.c.cfg.ReadAheadSize), cHeaderSize)
+	}
+	cr.raStart, cr.raEnd = start, end
+	if err := cr.c.mmf.WillNeed(int64(start), end-start); err != nil {
+		cr.c.logger.Debugf("could not read ahead offset=%d for len=%d: %v", start, end-start, err)
+	}
+}
+
+// Err returns the error, which stopped the reader, if any. The errors.ErrCorrupted is returned if a record
+// payload doesn't match its checksum.
+func (cr *ChunkReader) Err() error {
+	return cr.err
+}
+
 // Close implements io.Closer
 func (cr *ChunkReader) Close() error {
 	cr.c.lock.RUnlock()
@@ -457,9 +757,50 @@ func (cr *ChunkReader) Close() error {
 	return nil
 }
 
+// IDAt returns the ID of the record with the index idx in the chunk. The false is returned
+// if the chunk has no record with the index.
+func (cr *ChunkReader) IDAt(idx int) (ulid.ULID, bool) {
+	if idx < 0 || idx >= cr.c.total {
+		return ulid.ULID{}, false
+	}
+	return cr.mb.get(idx).ID, true
+}
+
+// CountIDs returns the number of the chunk records with the IDs in the range [from..to] (inclusive). Zero
+// to value means the range is not limited from above. The meta-records are sorted by the records IDs and
+// have the fixed size, so they are the index of the chunk records, and the records are counted by two
+// binary searches without reading the payloads.
+func (cr *ChunkReader) CountIDs(from, to ulid.ULID) int {
+	l := sort.Search(cr.c.total, func(i int) bool {
+		return cr.mb.get(i).ID.Compare(from) >= 0
+	})
+	r := cr.c.total
+	if to.Compare(ulidutils.ZeroULID) != 0 {
+		r = sort.Search(cr.c.total, func(i int) bool {
+			return cr.mb.get(i).ID.Compare(to) > 0
+		})
+	}
+	return max(r-l, 0)
+}
+
+// Skip moves the iterator n records forward in its direction without reading them. The function returns the
+// number of records skipped, which is less than n if the iterator has fewer records left.
+func (cr *ChunkReader) Skip(n int) int {
+	left := cr.c.total - cr.idx
+	if cr.inc == -1 {
+		left = cr.idx + 1
+	}
+	n = max(min(n, left), 0)
+	cr.idx += n * cr.inc
+	cr.raStart, cr.raEnd = 0, 0
+	return n
+}
+
 // SetStartID moves the iterator offset to the position startID. The function returns the number of records
 // which will be available for read after the call taking into account the direction of the iterator.
+// The region read ahead before is dropped, so the next read requests the region from the new position.
 func (cr *ChunkReader) SetStartID(startID ulid.ULID) int {
+	cr.raStart, cr.raEnd = 0, 0
 	res := 0
 	if cr.inc == -1 {
 		cr.idx = sort.Search(cr.c.total, func(i int) bool {
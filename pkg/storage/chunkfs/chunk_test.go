@@ -15,6 +15,7 @@ package chunkfs
 
 import (
 	"crypto/rand"
+	"fmt"
 	"github.com/oklog/ulid/v2"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/cast"
@@ -25,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -136,7 +138,8 @@ func TestChunk_AppendGrowth(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, cfg.NewSize, fi.Size())
 
-	recs2 := generateRecords(100, 30)
+	// every record takes 1 byte of the attributes + 25 bytes of payload + 4 bytes of the checksum + the meta-record
+	recs2 := generateRecords(100, 25)
 	recs = append(recs, recs2...)
 	_, err = c.AppendRecords(recs2)
 	assert.Nil(t, err)
@@ -170,13 +173,21 @@ func TestChunk_AppendGrowth(t *testing.T) {
 	assert.Equal(t, 4*cfg.NewSize, fi.Size())
 	recs = append(recs, recs2...)
 
-	before := c.freeOffset
+	// only the records, which fit into the maximum chunk size, are written
 	assert.Equal(t, len(recs), int(c.total))
-	_, err = c.AppendRecords(generateRecords(1000, 30))
-	assert.NotNil(t, err)
-	assert.True(t, errors.Is(err, errors.ErrExhausted))
-	assert.Equal(t, before, c.freeOffset)
+	recs3 := generateRecords(1000, 25)
+	arr, err = c.AppendRecords(recs3)
+	assert.Nil(t, err)
+	assert.True(t, arr.Written > 0 && arr.Written < len(recs3))
+	recs = append(recs, recs3[:arr.Written]...)
 	assert.Equal(t, len(recs), int(c.total))
+	assert.Equal(t, int64(c.cfg.MaxChunkSize), c.mmf.Size())
+
+	before := c.freeOffset
+	arr, err = c.AppendRecords(recs3[arr.Written:])
+	assert.Nil(t, err)
+	assert.Equal(t, 0, arr.Written)
+	assert.Equal(t, before, c.freeOffset)
 
 	cr1, err = c.OpenChunkReader(false)
 	assert.Nil(t, err)
@@ -196,13 +207,352 @@ func TestChunk_AppendGrowth2(t *testing.T) {
 	c := NewChunk(fn, "c1", cfg)
 	assert.Nil(t, c.Open(false))
 	defer c.Close()
-	recs := generateRecords(3000, 512)
+	// every record takes 1 byte of the attributes + 507 bytes of payload + 4 bytes of the checksum + the meta-record
+	recs := generateRecords(3000, 507)
 	arr, err := c.AppendRecords(recs)
 	assert.Nil(t, err)
 	assert.Equal(t, 38, arr.Written)
 	assert.True(t, arr.StartID.Compare(arr.LastID) < 0)
 }
 
+func TestChunk_Truncate(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_Truncate")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	recs := generateRecords(5, 10)
+	_, err = c.AppendRecords(recs)
+	assert.Nil(t, err)
+
+	assert.True(t, errors.Is(c.Truncate(6), errors.ErrInvalid))
+	assert.Nil(t, c.Truncate(2))
+	more := generateRecords(2, 20)
+	arr, err := c.AppendRecords(more)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, arr.Written)
+
+	// the truncated state survives the chunk reopening
+	assert.Nil(t, c.Close())
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	checkRecords(t, cr, append(recs[:2], more...))
+	assert.Nil(t, cr.Close())
+
+	assert.Nil(t, c.Truncate(0))
+	more = generateRecords(1, 30)
+	_, err = c.AppendRecords(more)
+	assert.Nil(t, err)
+	cr, err = c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	defer cr.Close()
+	checkRecords(t, cr, more)
+}
+
+func TestChunk_CRC(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_CRC")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	recs := generateRecords(3, 10)
+	_, err = c.AppendRecords(recs)
+	assert.Nil(t, err)
+
+	// corrupt the second record payload
+	mb, err := c.getMetaBuf(1, 1)
+	assert.Nil(t, err)
+	mr := mb.get(0)
+	buf, err := c.mmf.Buffer(int64(mr.offset+mr.size)-cCRCSize-1, 1)
+	assert.Nil(t, err)
+	buf[0]++
+
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	_, ok := cr.Next()
+	assert.True(t, ok)
+	_, ok = cr.Next()
+	assert.False(t, ok)
+	assert.False(t, cr.HasNext())
+	assert.True(t, errors.Is(cr.Err(), errors.ErrCorrupted))
+	assert.True(t, errors.Is(cr.Err(), errors.ErrDataLoss))
+	assert.Contains(t, cr.Err().Error(), recs[1].ID)
+	assert.Contains(t, cr.Err().Error(), "c1")
+	cr.Close()
+
+	// the verification is disabled
+	c.cfg.SkipCRCCheck = true
+	cr, err = c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	for range recs {
+		ur, ok := cr.Next()
+		assert.True(t, ok)
+		assert.Len(t, ur.UnsafePayload, 10)
+	}
+	assert.Nil(t, cr.Err())
+	cr.Close()
+}
+
+func TestChunk_NoCRCFormat(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_NoCRCFormat")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	// the chunk written in the format without the checksums
+	fn := filepath.Join(dir, "c1")
+	hdr := make([]byte, files.BlockSize)
+	copy(hdr, hdrVersionNoCRC)
+	assert.Nil(t, os.WriteFile(fn, hdr, 0640))
+
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	assert.Equal(t, cVersionNoCRC, c.Version())
+	recs := generateRecords(3, 10)
+	_, err = c.AppendRecords(recs)
+	assert.Nil(t, err)
+	assert.Equal(t, cHeaderSize+3*10, c.freeOffset)
+
+	assert.Nil(t, c.Close())
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	defer cr.Close()
+	checkRecords(t, cr, recs)
+	assert.Nil(t, cr.Err())
+	assert.Equal(t, cVersionNoCRC, c.Version())
+}
+
+func TestChunk_Attributes(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_Attributes")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	recs := generateRecords(3, 10)
+	recs[0].Attributes = map[string]string{"severity": "error", "host": "h1", "": ""}
+	recs[2].Attributes = map[string]string{"severity": "info"}
+	_, err = c.AppendRecords(recs)
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.Close())
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	defer cr.Close()
+	for _, rec := range recs {
+		r, ok := cr.Next()
+		assert.True(t, ok)
+		assert.Equal(t, rec.Payload, r.UnsafePayload)
+		assert.Equal(t, len(rec.Payload), r.Size)
+		assert.Equal(t, rec.Attributes, r.Attributes)
+	}
+	assert.False(t, cr.HasNext())
+	assert.Nil(t, cr.Err())
+}
+
+func TestChunk_NoAttrsFormat(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_NoAttrsFormat")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	// the chunk written in the format without the attributes, it is not outdated, but it takes
+	// the records without the attributes only
+	fn := filepath.Join(dir, "c1")
+	hdr := make([]byte, files.BlockSize)
+	copy(hdr, hdrVersion)
+	hdr[len(hdrVersion)-1] = cVersionNoAttrs
+	assert.Nil(t, os.WriteFile(fn, hdr, 0640))
+
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	assert.Equal(t, cVersionNoAttrs, c.Version())
+	assert.False(t, c.Outdated())
+	recs := generateRecords(4, 10)
+	recs[2].Attributes = map[string]string{"severity": "error"}
+	res, err := c.AppendRecords(recs)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res.Written)
+	res, err = c.AppendRecords(recs[2:])
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res.Written)
+	recs = recs[:2]
+	assert.Equal(t, cHeaderSize+2*(10+cCRCSize), c.freeOffset)
+
+	assert.Nil(t, c.Close())
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	defer cr.Close()
+	for _, rec := range recs {
+		r, ok := cr.Next()
+		assert.True(t, ok)
+		assert.Equal(t, rec.Payload, r.UnsafePayload)
+		assert.Nil(t, r.Attributes)
+	}
+	assert.False(t, cr.HasNext())
+	assert.Nil(t, cr.Err())
+	assert.Equal(t, cVersionNoAttrs, c.Version())
+}
+
+func TestChunk_Version(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_Version")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	// the new chunks are written in the current format
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	assert.Equal(t, cVersion, c.Version())
+	assert.Nil(t, c.Close())
+
+	// the chunk of an unknown (newer) version is not opened and stays intact
+	fn = filepath.Join(dir, "c2")
+	hdr := make([]byte, files.BlockSize)
+	copy(hdr, hdrVersion)
+	hdr[len(hdrVersion)-1] = cVersion + 1
+	hdr[len(hdrVersion)+3] = 5
+	assert.Nil(t, os.WriteFile(fn, hdr, 0640))
+	c = NewChunk(fn, "c2", cfg)
+	err = c.Open(false)
+	assert.True(t, errors.Is(err, errors.ErrUnimplemented))
+	buf, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.Equal(t, hdr, buf)
+}
+
+func TestChunk_Outdated(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_Outdated")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	fn := filepath.Join(dir, "c1")
+	hdr := make([]byte, files.BlockSize)
+	copy(hdr, hdrVersionNoCRC)
+	assert.Nil(t, os.WriteFile(fn, hdr, 0640))
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	assert.True(t, c.Outdated())
+	assert.Nil(t, c.Close())
+
+	fn = filepath.Join(dir, "c2")
+	files.EnsureFileExists(fn)
+	c = NewChunk(fn, "c2", cfg)
+	assert.Nil(t, c.Open(false))
+	assert.False(t, c.Outdated())
+	assert.Nil(t, c.Close())
+
+	// the compression is turned on, so the chunk without the compression is outdated
+	cfg.Compression = CompressionZstd
+	c = NewChunk(fn, "c2", cfg)
+	assert.Nil(t, c.Open(false))
+	assert.True(t, c.Outdated())
+	assert.Nil(t, c.Close())
+}
+
+func TestChunk_Compression(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_Compression")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize,
+		Compression: CompressionZstd}
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	var recs []*solaris.Record
+	for i := 0; i < 20; i++ {
+		recs = append(recs, &solaris.Record{Payload: []byte(strings.Repeat(fmt.Sprintf(`{"level":"info","msg":"message %d"}`, i), 30))})
+	}
+	// the random payloads are not compressible, so they are stored as is
+	recs = append(recs, generateRecords(2, 100)...)
+	arr, err := c.AppendRecords(recs)
+	assert.Nil(t, err)
+	assert.Equal(t, len(recs), arr.Written)
+	rawSize := 0
+	for _, r := range recs {
+		rawSize += len(r.Payload)
+	}
+	assert.Less(t, c.freeOffset-cHeaderSize, rawSize/4)
+
+	// the codec is read from the chunk header, regardless of the config
+	assert.Nil(t, c.Close())
+	c = NewChunk(fn, "c1", Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize})
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	assert.Equal(t, codecZstd, c.codec)
+	more := generateRecords(1, 100)
+	_, err = c.AppendRecords(more)
+	assert.Nil(t, err)
+	recs = append(recs, more...)
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	for i, rec := range recs {
+		ur, ok := cr.Next()
+		assert.True(t, ok)
+		assert.Equal(t, rec.Payload, ur.UnsafePayload)
+		if i < 20 {
+			assert.Less(t, ur.Size, len(ur.UnsafePayload))
+		} else {
+			assert.Equal(t, len(ur.UnsafePayload)+1, ur.Size)
+		}
+	}
+	assert.False(t, cr.HasNext())
+	assert.Nil(t, cr.Err())
+	assert.Nil(t, cr.Close())
+
+	// the not compressed chunk stays not compressed
+	fn = filepath.Join(dir, "c2")
+	files.EnsureFileExists(fn)
+	c2 := NewChunk(fn, "c2", Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize})
+	assert.Nil(t, c2.Open(false))
+	assert.Nil(t, c2.Close())
+	c2 = NewChunk(fn, "c2", cfg)
+	assert.Nil(t, c2.Open(false))
+	defer c2.Close()
+	assert.Equal(t, codecNone, c2.codec)
+	_, err = c2.AppendRecords(recs[:1])
+	assert.Nil(t, err)
+	assert.Equal(t, cHeaderSize+len(noAttrs)+len(recs[0].Payload)+cCRCSize, c2.freeOffset)
+
+	fn = filepath.Join(dir, "c3")
+	files.EnsureFileExists(fn)
+	c3 := NewChunk(fn, "c3", Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, Compression: "lz5"})
+	assert.True(t, errors.Is(c3.Open(false), errors.ErrInvalid))
+}
+
 func checkRecords(t *testing.T, it *ChunkReader, recs []*solaris.Record) {
 	for _, rec := range recs {
 		assert.True(t, it.HasNext())
@@ -223,3 +573,248 @@ func generateRecords(count, size int) []*solaris.Record {
 	}
 	return res
 }
+
+func TestChunk_IDAt(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_IDAt")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	recs := generateRecords(5, 10)
+	_, err = c.AppendRecords(recs)
+	assert.Nil(t, err)
+
+	cr, err := c.OpenChunkReader(true)
+	assert.Nil(t, err)
+	defer cr.Close()
+	for i, r := range recs {
+		id, ok := cr.IDAt(i)
+		assert.True(t, ok)
+		assert.Equal(t, r.ID, id.String())
+	}
+	_, ok := cr.IDAt(5)
+	assert.False(t, ok)
+	_, ok = cr.IDAt(-1)
+	assert.False(t, ok)
+
+	// the descending reader reports the records indexes in the chunk
+	for i := len(recs) - 1; cr.HasNext(); i-- {
+		ur, ok := cr.Next()
+		assert.True(t, ok)
+		assert.Equal(t, i, ur.Idx)
+	}
+}
+
+func TestChunk_CopyRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_CopyRecords")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	recs := generateRecords(5, 10)
+	for _, r := range recs {
+		r.ID = ulidutils.NewID()
+	}
+	res, err := c.CopyRecords(recs)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, res.Written)
+	assert.Equal(t, recs[0].ID, res.StartID.String())
+	assert.Equal(t, recs[4].ID, res.LastID.String())
+
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	for i := 0; cr.HasNext(); i++ {
+		ur, ok := cr.Next()
+		assert.True(t, ok)
+		assert.Equal(t, recs[i].ID, ur.ID.String())
+		assert.Equal(t, recs[i].Payload, ur.UnsafePayload)
+	}
+	cr.Close()
+
+	// the wrong IDs are not written
+	_, err = c.CopyRecords([]*solaris.Record{{ID: "abc", Payload: []byte("abc")}})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	cr, err = c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	defer cr.Close()
+	_, ok := cr.IDAt(5)
+	assert.False(t, ok)
+}
+
+func TestChunk_ReadAhead(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_ReadAhead")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 100 * files.BlockSize, MaxGrowIncreaseSize: 10 * files.BlockSize,
+		ReadAheadSize: 3 * files.BlockSize}
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	recs := generateRecords(50, 1000)
+	_, err = c.AppendRecords(recs)
+	assert.Nil(t, err)
+
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	checkRecords(t, cr, recs)
+	assert.Equal(t, c.freeOffset, cr.raEnd)
+	cr.Close()
+
+	cr, err = c.OpenChunkReader(true)
+	assert.Nil(t, err)
+	for i := len(recs) - 1; cr.HasNext(); i-- {
+		ur, ok := cr.Next()
+		assert.True(t, ok)
+		assert.Equal(t, recs[i].Payload, ur.UnsafePayload)
+	}
+	assert.Equal(t, cHeaderSize, cr.raStart)
+
+	// the jump drops the region read ahead, so the records are read from the new position
+	id, _ := cr.IDAt(40)
+	assert.Equal(t, 41, cr.SetStartID(id))
+	assert.Equal(t, 0, cr.raEnd)
+	ur, ok := cr.Next()
+	assert.True(t, ok)
+	assert.Equal(t, recs[40].Payload, ur.UnsafePayload)
+	assert.Equal(t, int(cr.mb.get(40).offset+cr.mb.get(40).size), cr.raEnd)
+	assert.Equal(t, cr.raEnd-cfg.ReadAheadSize, cr.raStart)
+	cr.Close()
+}
+
+func TestChunkReader_CountIDs(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunkReader_CountIDs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", GetDefaultConfig())
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	_, err = c.AppendRecords(generateRecords(100, 10))
+	assert.Nil(t, err)
+
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	defer cr.Close()
+	id10, _ := cr.IDAt(10)
+	id20, _ := cr.IDAt(20)
+	assert.Equal(t, 100, cr.CountIDs(ulidutils.ZeroULID, ulidutils.ZeroULID))
+	assert.Equal(t, 11, cr.CountIDs(id10, id20))
+	assert.Equal(t, 90, cr.CountIDs(id10, ulidutils.ZeroULID))
+	assert.Equal(t, 21, cr.CountIDs(ulidutils.ZeroULID, id20))
+	assert.Equal(t, 1, cr.CountIDs(id10, id10))
+	assert.Equal(t, 0, cr.CountIDs(id20, id10))
+	assert.Equal(t, 0, cr.CountIDs(ulidutils.New(), ulidutils.ZeroULID))
+}
+
+// BenchmarkChunkReader_Scan reads all the records of a 64MB chunk with and without the read-ahead. The chunk
+// file is in the page cache after the first run, so the difference shows up for the cold chunks only.
+func BenchmarkChunkReader_Scan(b *testing.B) {
+	dir, err := os.MkdirTemp("", "BenchmarkChunkReader_Scan")
+	assert.Nil(b, err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", GetDefaultConfig())
+	assert.Nil(b, c.Open(false))
+	for i := 0; i < 64; i++ {
+		_, err := c.AppendRecords(generateRecords(1000, 1024))
+		assert.Nil(b, err)
+	}
+	assert.Nil(b, c.Close())
+
+	for _, ra := range []int{0, 1024 * 1024} {
+		b.Run(fmt.Sprintf("readAhead%d", ra), func(b *testing.B) {
+			cfg := GetDefaultConfig()
+			cfg.ReadAheadSize = ra
+			b.SetBytes(64 * 1000 * 1024)
+			for i := 0; i < b.N; i++ {
+				c := NewChunk(fn, "c1", cfg)
+				assert.Nil(b, c.Open(false))
+				cr, err := c.OpenChunkReader(false)
+				assert.Nil(b, err)
+				for cr.HasNext() {
+					cr.Next()
+				}
+				assert.Nil(b, cr.Err())
+				cr.Close()
+				assert.Nil(b, c.Close())
+			}
+		})
+	}
+}
+
+// BenchmarkChunk_AppendRecords appends 10k records of 100 bytes either one by one or by one batch. The records
+// are copied into the memory mapped file, so no write syscalls are made per record in both cases.
+func BenchmarkChunk_AppendRecords(b *testing.B) {
+	dir, err := os.MkdirTemp("", "BenchmarkChunk_AppendRecords")
+	assert.Nil(b, err)
+	defer os.RemoveAll(dir)
+
+	recs := generateRecords(10000, 100)
+	for _, bs := range []int{1, len(recs)} {
+		b.Run(fmt.Sprintf("batch%d", bs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				fn := filepath.Join(dir, fmt.Sprintf("c%d_%d", bs, i))
+				files.EnsureFileExists(fn)
+				c := NewChunk(fn, "c1", GetDefaultConfig())
+				assert.Nil(b, c.Open(false))
+				for j := 0; j < len(recs); j += bs {
+					_, err := c.AppendRecords(recs[j : j+bs])
+					assert.Nil(b, err)
+				}
+				assert.Nil(b, c.Close())
+				os.Remove(fn)
+			}
+		})
+	}
+}
+
+func TestChunkReader_Skip(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunkReader_Skip")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", GetDefaultConfig())
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	_, err = c.AppendRecords(generateRecords(10, 10))
+	assert.Nil(t, err)
+
+	for _, desc := range []bool{false, true} {
+		cr, err := c.OpenChunkReader(desc)
+		assert.Nil(t, err)
+		exp, _ := cr.IDAt(3)
+		if desc {
+			exp, _ = cr.IDAt(6)
+		}
+		assert.Equal(t, 3, cr.Skip(3))
+		r, ok := cr.Next()
+		assert.True(t, ok)
+		assert.Equal(t, exp, r.ID)
+		assert.Equal(t, 6, cr.Skip(10))
+		assert.False(t, cr.HasNext())
+		assert.Equal(t, 0, cr.Skip(1))
+		cr.Close()
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkfs
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/solarisdb/solaris/golibs/errors"
+)
+
+// codec defines how the records payloads are stored in a chunk. The codec is chosen when
+// the chunk is created and it is kept in the chunk header, so the chunks of one log may
+// have different codecs.
+type codec byte
+
+const (
+	// CompressionNone specifies the records payloads are stored as is
+	CompressionNone = ""
+	// CompressionZstd specifies the records payloads are compressed by zstd
+	CompressionZstd = "zstd"
+)
+
+const (
+	codecNone codec = 0
+	codecZstd codec = 1
+)
+
+// every compressed chunk record starts from the flag, which tells whether the payload is
+// compressed or stored as is, cause it could not be compressed (encrypted payloads, for example)
+const (
+	recRaw        byte = 0
+	recCompressed byte = 1
+)
+
+var (
+	zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic(fmt.Sprintf("could not create zstd encoder: %v", err))
+		}
+		return enc
+	})
+	zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+		if err != nil {
+			panic(fmt.Sprintf("could not create zstd decoder: %v", err))
+		}
+		return dec
+	})
+)
+
+// codecByName returns the codec for the Config.Compression value
+func codecByName(name string) (codec, error) {
+	switch name {
+	case CompressionNone:
+		return codecNone, nil
+	case CompressionZstd:
+		return codecZstd, nil
+	}
+	return codecNone, fmt.Errorf("unknown compression=%q: %w", name, errors.ErrInvalid)
+}
+
+// encode returns the payload as it should be stored in the chunk
+func (cd codec) encode(payload []byte) []byte {
+	if cd == codecNone {
+		return payload
+	}
+	res := zstdEncoder().EncodeAll(payload, append(make([]byte, 0, len(payload)+1), recCompressed))
+	if len(res) > len(payload) {
+		res = append(res[:0], recRaw)
+		res = append(res, payload...)
+	}
+	return res
+}
+
+// decode returns the original payload by the stored one
+func (cd codec) decode(stored []byte) ([]byte, error) {
+	if cd == codecNone {
+		return stored, nil
+	}
+	if len(stored) == 0 {
+		return nil, fmt.Errorf("the record has no compression flag: %w", errors.ErrCorrupted)
+	}
+	switch stored[0] {
+	case recRaw:
+		return stored[1:], nil
+	case recCompressed:
+		res, err := zstdDecoder().DecodeAll(stored[1:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not decompress the record: %v: %w", err, errors.ErrCorrupted)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unknown record compression flag=%d: %w", stored[0], errors.ErrCorrupted)
+}
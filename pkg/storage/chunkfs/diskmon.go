@@ -0,0 +1,151 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package chunkfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/logrange/linker"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/files"
+	"github.com/solarisdb/solaris/golibs/logging"
+	"github.com/solarisdb/solaris/pkg/metrics"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// DiskMonitorConfig defines settings for the DiskMonitor
+	DiskMonitorConfig struct {
+		// DataPath contains the path to the folder where the chunks are stored
+		DataPath string
+		// MinFreeSpace defines the free space (in bytes) threshold for the disk where the DataPath
+		// is located. If the free space goes below the value, the new writes are rejected. Zero
+		// or negative value means the writes are never rejected, but the free space is still
+		// checked to be exposed by the metrics.
+		MinFreeSpace int64
+		// CheckInterval defines how often the free space is checked. Zero value means the space
+		// is checked once on Init.
+		CheckInterval time.Duration
+	}
+
+	// DiskMonitor watches the free space on the disk where the chunks are stored. When the free space is below
+	// the threshold the monitor turns into the degraded mode, which means that appends must be rejected before
+	// they try to write to the chunks, but reads and deletes are still allowed.
+	DiskMonitor struct {
+		logger    logging.Logger
+		cfg       DiskMonitorConfig
+		freeSpace atomic.Int64
+		degraded  atomic.Bool
+		getFree   func(path string) (int64, error)
+	}
+)
+
+var _ linker.Initializer = (*DiskMonitor)(nil)
+
+// GetDefaultDiskMonitorConfig returns the default DiskMonitorConfig
+func GetDefaultDiskMonitorConfig() DiskMonitorConfig {
+	return DiskMonitorConfig{
+		DataPath:      "slog",
+		MinFreeSpace:  0, // the writes are never rejected
+		CheckInterval: 10 * time.Second,
+	}
+}
+
+// NewDiskMonitor creates the new DiskMonitor
+func NewDiskMonitor(cfg DiskMonitorConfig) *DiskMonitor {
+	return &DiskMonitor{
+		logger:  logging.NewLogger("chunkfs.DiskMonitor"),
+		cfg:     cfg,
+		getFree: files.FreeSpace,
+	}
+}
+
+// String implements fmt.Stringer
+func (dc DiskMonitorConfig) String() string {
+	b, _ := json.MarshalIndent(dc, "", "  ")
+	return string(b)
+}
+
+// Init implements linker.Initializer
+func (dm *DiskMonitor) Init(ctx context.Context) error {
+	dm.logger.Infof("initializing cfg:\n%s", dm.cfg)
+	if err := dm.check(); err != nil {
+		if dm.cfg.MinFreeSpace > 0 {
+			return err
+		}
+		// the free space is checked for the metrics only, so the server could work without it
+		dm.logger.Warnf("could not check the free space for %s: %v", dm.cfg.DataPath, err)
+	}
+	if dm.cfg.MinFreeSpace <= 0 {
+		dm.logger.Infof("the MinFreeSpace is zero or negative, the appends will not be rejected by the free space")
+	}
+	if dm.cfg.CheckInterval > 0 {
+		go dm.watcher(ctx)
+	}
+	return nil
+}
+
+// FreeSpace returns the free space (in bytes) on the disk as it was checked last time
+func (dm *DiskMonitor) FreeSpace() int64 {
+	return dm.freeSpace.Load()
+}
+
+// CheckWritable returns the ErrExhausted error if the free space on the disk is below the threshold
+func (dm *DiskMonitor) CheckWritable() error {
+	if dm.degraded.Load() {
+		return fmt.Errorf("the free disk space %d bytes is below the threshold %d bytes: %w",
+			dm.freeSpace.Load(), dm.cfg.MinFreeSpace, errors.ErrExhausted)
+	}
+	return nil
+}
+
+func (dm *DiskMonitor) watcher(ctx context.Context) {
+	dm.logger.Infof("starting watcher()")
+	defer dm.logger.Infof("exiting from watcher()")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(dm.cfg.CheckInterval):
+			if err := dm.check(); err != nil {
+				dm.logger.Warnf("could not check the free space for %s: %v", dm.cfg.DataPath, err)
+			}
+		}
+	}
+}
+
+func (dm *DiskMonitor) check() error {
+	free, err := dm.getFree(dm.cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	dm.freeSpace.Store(free)
+	metrics.DiskFreeBytes.Set(float64(free))
+	degraded := dm.cfg.MinFreeSpace > 0 && free < dm.cfg.MinFreeSpace
+	if degraded {
+		metrics.DiskDegraded.Set(1)
+	} else {
+		metrics.DiskDegraded.Set(0)
+	}
+	if dm.degraded.Swap(degraded) != degraded {
+		if degraded {
+			dm.logger.Warnf("the free space %d bytes is below the threshold %d bytes, the appends will be rejected", free, dm.cfg.MinFreeSpace)
+		} else {
+			dm.logger.Infof("the free space %d bytes is above the threshold %d bytes, the appends are allowed again", free, dm.cfg.MinFreeSpace)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package chunkfs
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiskMonitor_CheckWritable(t *testing.T) {
+	dm := NewDiskMonitor(DiskMonitorConfig{DataPath: t.TempDir(), MinFreeSpace: 1000})
+	free := int64(2000)
+	dm.getFree = func(path string) (int64, error) {
+		return free, nil
+	}
+	assert.Nil(t, dm.check())
+	assert.Nil(t, dm.CheckWritable())
+	assert.Equal(t, int64(2000), dm.FreeSpace())
+	assert.Equal(t, float64(2000), testutil.ToFloat64(metrics.DiskFreeBytes))
+
+	free = 999
+	assert.Nil(t, dm.check())
+	assert.True(t, errors.Is(dm.CheckWritable(), errors.ErrExhausted))
+	assert.Equal(t, int64(999), dm.FreeSpace())
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.DiskDegraded))
+
+	free = 1000
+	assert.Nil(t, dm.check())
+	assert.Nil(t, dm.CheckWritable())
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.DiskDegraded))
+}
+
+func TestDiskMonitor_Disabled(t *testing.T) {
+	dm := NewDiskMonitor(DiskMonitorConfig{DataPath: t.TempDir()})
+	dm.getFree = func(path string) (int64, error) {
+		return 10, nil
+	}
+	assert.Nil(t, dm.Init(context.Background()))
+	assert.Nil(t, dm.CheckWritable())
+	// the free space is exposed even if the appends are not limited by it
+	assert.Equal(t, int64(10), dm.FreeSpace())
+	assert.Equal(t, float64(10), testutil.ToFloat64(metrics.DiskFreeBytes))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.DiskDegraded))
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkfs
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/solarisdb/solaris/golibs/errors"
+)
+
+// ScanReport describes the result of the chunk integrity scan
+type ScanReport struct {
+	// Total is the number of records the chunk header reports
+	Total int
+	// Recoverable is the number of the first chunk records, which are read ok. All the chunk
+	// records are recoverable, if the chunk is not corrupted.
+	Recoverable int
+	// LastID is the ID of the last recoverable record, it is zero if there are no such records
+	LastID ulid.ULID
+	// BadOffset is the offset of the first bad record payload in the chunk file, it is -1 if
+	// the chunk is not corrupted
+	BadOffset int
+	// Err describes why the first bad record is considered corrupted, it is nil if the chunk is not corrupted
+	Err error
+}
+
+// Corrupted returns true if the scan found a bad record in the chunk
+func (sr ScanReport) Corrupted() bool {
+	return sr.Err != nil
+}
+
+// String implements fmt.Stringer
+func (sr ScanReport) String() string {
+	return fmt.Sprintf("ScanReport{Total:%d, Recoverable:%d, LastID:%s, BadOffset:%d, Err:%v}",
+		sr.Total, sr.Recoverable, sr.LastID, sr.BadOffset, sr.Err)
+}
+
+// ScanChunk walks all the records of the chunk with the ID and verifies the records framing and checksums.
+// The chunk is read as any other chunk, so the scan doesn't block the chunk readers, but the writers wait
+// until the scan is over. The chunks, which header is corrupted, could not be opened, so the error of the
+// chunk opening is returned for them.
+func (p *Provider) ScanChunk(ctx context.Context, cID string) (ScanReport, error) {
+	rc, err := p.GetOpenedChunk(ctx, cID, false)
+	if err != nil {
+		return ScanReport{}, err
+	}
+	defer p.ReleaseChunk(&rc)
+	return rc.Value().Scan()
+}
+
+// RepairChunk scans the chunk with the ID (see ScanChunk) and truncates it at the last good record, if the
+// chunk is corrupted, so the recoverable records stay in the chunk only. The write access to the chunk is
+// requested from the ChunkAccessor, so the repair doesn't race with the chunk writers and the Replicator, and
+// the chunk is not deleted while it is repaired. The function returns the report of the scan made before
+// the truncation.
+func (p *Provider) RepairChunk(ctx context.Context, cID string) (ScanReport, error) {
+	rc, err := p.GetOpenedChunk(ctx, cID, false)
+	if err != nil {
+		return ScanReport{}, err
+	}
+	defer p.ReleaseChunk(&rc)
+
+	if err := p.CA.SetWriting(ctx, cID); err != nil {
+		return ScanReport{}, err
+	}
+	defer p.CA.SetIdle(cID)
+
+	sr, err := rc.Value().Repair()
+	if err == nil && sr.Corrupted() {
+		p.logger.Warnf("the chunk %s is truncated from %d to %d records: %s", cID, sr.Total, sr.Recoverable, sr)
+	}
+	return sr, err
+}
+
+// Scan walks all the chunk records and verifies the records framing and checksums. The checksums are
+// verified even if Config.SkipCRCCheck is set. The Write operations to the chunk are blocked while the chunk
+// is scanned.
+func (c *Chunk) Scan() (ScanReport, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if c.mmf == nil {
+		// chunk is closed
+		return ScanReport{}, fmt.Errorf("the chunk %s is closed: %w ", c.fn, errors.ErrClosed)
+	}
+	return c.scan(), nil
+}
+
+// Repair scans the chunk (see Scan) and truncates it at the last good record, if a bad record is found.
+// The truncated chunk is flushed to the disk regardless of the sync policy. The function returns the report
+// of the scan made before the truncation.
+func (c *Chunk) Repair() (ScanReport, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.mmf == nil {
+		// chunk is closed
+		return ScanReport{}, fmt.Errorf("the chunk %s is closed: %w ", c.fn, errors.ErrClosed)
+	}
+	sr := c.scan()
+	if !sr.Corrupted() {
+		return sr, nil
+	}
+	if err := c.truncate(sr.Recoverable); err != nil {
+		return sr, err
+	}
+	c.unsynced++
+	return sr, c.sync()
+}
+
+// scan must be called under the chunk lock
+func (c *Chunk) scan() ScanReport {
+	sr := ScanReport{Total: c.total, BadOffset: -1}
+	pMax := c.mmf.Size() - int64(c.total*cMetaRecordSize)
+	if pMax < cHeaderSize {
+		sr.BadOffset = cHeaderSize
+		sr.Err = fmt.Errorf("the chunk meta-records of %d records don't fit the chunk size=%d: %w", c.total, c.mmf.Size(), errors.ErrCorrupted)
+		return sr
+	}
+	if c.total == 0 {
+		return sr
+	}
+	mb, err := c.getMetaBuf(c.total-1, c.total)
+	if err != nil {
+		sr.BadOffset = cHeaderSize
+		sr.Err = err
+		return sr
+	}
+	offset := cHeaderSize
+	for i := 0; i < c.total; i++ {
+		mr := mb.get(i)
+		if err := c.checkRecord(mr, i, offset, sr.LastID, int(pMax)); err != nil {
+			sr.BadOffset = offset
+			sr.Err = err
+			return sr
+		}
+		offset = int(mr.offset + mr.size)
+		sr.LastID = mr.ID
+		sr.Recoverable++
+	}
+	return sr
+}
+
+// checkRecord verifies the record idx with the meta-record mr is placed right at the offset after the previous
+// record with the prevID and within the payloads region [offset..pMax), and its payload matches the checksum
+func (c *Chunk) checkRecord(mr metaRec, idx, offset int, prevID ulid.ULID, pMax int) error {
+	if mr.ID.Compare(prevID) < 0 {
+		return fmt.Errorf("the record #%d ID=%s is less than the previous one %s: %w", idx, mr.ID, prevID, errors.ErrCorrupted)
+	}
+	if int(mr.offset) != offset {
+		return fmt.Errorf("the record #%d offset=%d is not what expected %d: %w", idx, mr.offset, offset, errors.ErrCorrupted)
+	}
+	if mr.size < int32(c.crcSize) || int(mr.offset)+int(mr.size) > pMax {
+		return fmt.Errorf("the record #%d size=%d is out of the payloads region [%d..%d): %w", idx, mr.size, offset, pMax, errors.ErrCorrupted)
+	}
+	buf, err := c.mmf.Buffer(int64(mr.offset), int(mr.size))
+	if err != nil {
+		return fmt.Errorf("could not read the record #%d payload for offset=%d for len=%d: %v: %w", idx, mr.offset, mr.size, err, errors.ErrInternal)
+	}
+	if cs := c.crcSize; cs > 0 {
+		payload := buf[:len(buf)-cs]
+		if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(buf[len(buf)-cs:]) {
+			return fmt.Errorf("the record #%d ID=%s checksum mismatch: %w", idx, mr.ID, errors.ErrCorrupted)
+		}
+		buf = payload
+	}
+	if c.hasAttrs {
+		var err error
+		if _, buf, err = decodeAttrs(buf); err != nil {
+			return fmt.Errorf("the record #%d ID=%s attributes could not be decoded: %v: %w", idx, mr.ID, err, errors.ErrCorrupted)
+		}
+	}
+	if c.codec != codecNone {
+		if _, err := c.codec.decode(buf); err != nil {
+			return fmt.Errorf("the record #%d ID=%s could not be decoded: %v: %w", idx, mr.ID, err, errors.ErrCorrupted)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkfs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/files"
+	"github.com/solarisdb/solaris/golibs/sss/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunk_ScanRepair(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_ScanRepair")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize,
+		SkipCRCCheck: true}
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	recs := generateRecords(5, 100)
+	_, err = c.AppendRecords(recs)
+	assert.Nil(t, err)
+
+	sr, err := c.Scan()
+	assert.Nil(t, err)
+	assert.False(t, sr.Corrupted())
+	assert.Equal(t, ScanReport{Total: 5, Recoverable: 5, LastID: ulid.MustParse(recs[4].ID), BadOffset: -1}, sr)
+
+	// corrupt the third record payload, the checksum is verified even if the check is skipped on read
+	mb, err := c.getMetaBuf(2, 1)
+	assert.Nil(t, err)
+	offset := int(mb.get(0).offset)
+	buf, err := c.mmf.Buffer(int64(offset), 1)
+	assert.Nil(t, err)
+	buf[0]++
+
+	sr, err = c.Scan()
+	assert.Nil(t, err)
+	assert.True(t, sr.Corrupted())
+	assert.True(t, errors.Is(sr.Err, errors.ErrCorrupted))
+	assert.Equal(t, 5, sr.Total)
+	assert.Equal(t, 2, sr.Recoverable)
+	assert.Equal(t, ulid.MustParse(recs[1].ID), sr.LastID)
+	assert.Equal(t, offset, sr.BadOffset)
+
+	sr2, err := c.Repair()
+	assert.Nil(t, err)
+	assert.Equal(t, sr, sr2)
+	sr, err = c.Scan()
+	assert.Nil(t, err)
+	assert.False(t, sr.Corrupted())
+	assert.Equal(t, 2, sr.Total)
+
+	// the truncated chunk is reopened and appended
+	assert.Nil(t, c.Close())
+	assert.Nil(t, c.Open(false))
+	res, err := c.AppendRecords(recs[2:])
+	assert.Nil(t, err)
+	assert.Equal(t, 3, res.Written)
+	cr, err := c.OpenChunkReader(false)
+	assert.Nil(t, err)
+	checkRecords(t, cr, recs)
+	cr.Close()
+}
+
+func TestChunk_ScanFraming(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_ScanFraming")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize}
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+	_, err = c.AppendRecords(generateRecords(3, 100))
+	assert.Nil(t, err)
+
+	// the second record size overlaps the third record
+	mb, err := c.getMetaBuf(1, 1)
+	assert.Nil(t, err)
+	mr := mb.get(0)
+	mr.size += 10
+	mb.put(0, mr)
+
+	sr, err := c.Scan()
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(sr.Err, errors.ErrCorrupted))
+	assert.Equal(t, 1, sr.Recoverable)
+	assert.Equal(t, int(mr.offset), sr.BadOffset)
+
+	assert.Nil(t, c.Close())
+	_, err = c.Scan()
+	assert.True(t, errors.Is(err, errors.ErrClosed))
+	_, err = c.Repair()
+	assert.True(t, errors.Is(err, errors.ErrClosed))
+}
+
+func TestProvider_RepairChunk(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestProvider_RepairChunk")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	p := NewProvider(dir, 1, GetDefaultConfig())
+	defer p.Close()
+	p.Replicator = NewReplicator(p.GetFileNameByID, GetDefaultReplicatorConfig())
+	p.Replicator.Storage = inmem.NewStorage()
+	p.CA = NewChunkAccessor()
+	p.Replicator.CA = p.CA
+
+	ctx := context.Background()
+	rc, err := p.GetOpenedChunk(ctx, "c1", true)
+	assert.Nil(t, err)
+	c := rc.Value()
+	_, err = c.AppendRecords(generateRecords(3, 100))
+	assert.Nil(t, err)
+	mb, err := c.getMetaBuf(0, 1)
+	assert.Nil(t, err)
+	buf, err := c.mmf.Buffer(int64(mb.get(0).offset), 1)
+	assert.Nil(t, err)
+	buf[0]++
+	p.ReleaseChunk(&rc)
+
+	sr, err := p.ScanChunk(ctx, "c1")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, sr.Recoverable)
+	assert.Equal(t, cHeaderSize, sr.BadOffset)
+
+	// the repair waits for the writer
+	assert.Nil(t, p.CA.SetWriting(ctx, "c1"))
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = p.RepairChunk(cctx, "c1")
+	assert.True(t, errors.Is(err, context.Canceled))
+	p.CA.SetIdle("c1")
+
+	sr, err = p.RepairChunk(ctx, "c1")
+	assert.Nil(t, err)
+	assert.True(t, sr.Corrupted())
+	sr, err = p.ScanChunk(ctx, "c1")
+	assert.Nil(t, err)
+	assert.Equal(t, ScanReport{BadOffset: -1}, sr)
+
+	_, err = p.ScanChunk(ctx, "c2")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkfs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/files"
+)
+
+type (
+	// Layout describes the chunks format versions found in the local storage
+	Layout struct {
+		// Current is the format version the new chunks are written in
+		Current int
+		// Versions contains the chunks stats by their format versions
+		Versions map[int]VersionStats
+	}
+
+	// VersionStats contains the number and the size of the chunks of one format version
+	VersionStats struct {
+		Chunks int64
+		Size   int64
+	}
+)
+
+// CurrentVersion returns the chunk format version the new chunks are written in
+func CurrentVersion() int {
+	return cVersion
+}
+
+// Layout scans the local chunks files and reads their format versions from the chunks headers.
+// The files, which are not initialized yet (empty), are not counted.
+func (p *Provider) Layout(ctx context.Context) (Layout, error) {
+	res := Layout{Current: cVersion, Versions: map[int]VersionStats{}}
+	for _, di := range files.ListDir(p.dir) {
+		if !di.IsDir() {
+			continue
+		}
+		dir := filepath.Join(p.dir, di.Name())
+		for _, fi := range files.ListDir(dir) {
+			if ctx.Err() != nil {
+				return Layout{}, ctx.Err()
+			}
+			if fi.IsDir() || !doesLookLikeID(fi.Name()) {
+				continue
+			}
+			v, _, err := readHeader(filepath.Join(dir, fi.Name()))
+			if err != nil {
+				p.logger.Debugf("could not read the chunk %s version: %v", fi.Name(), err)
+				continue
+			}
+			if v == 0 {
+				continue
+			}
+			vs := res.Versions[v]
+			vs.Chunks++
+			vs.Size += fi.Size()
+			res.Versions[v] = vs
+		}
+	}
+	return res, nil
+}
+
+// Outdated reads the chunk format from the local chunk file header and returns true if the chunk should be
+// rewritten (see Chunk.Outdated). The chunk is not opened, so the chunks stored remotely only are not downloaded,
+// errors.ErrNotExist is returned for them. The chunks, which headers are not written yet, are not outdated.
+func (p *Provider) Outdated(cID string) (bool, error) {
+	v, cd, err := readHeader(p.GetFileNameByID(cID))
+	if os.IsNotExist(err) {
+		return false, fmt.Errorf("the chunk %s is not stored locally: %w", cID, errors.ErrNotExist)
+	}
+	if err != nil || v == 0 {
+		return false, err
+	}
+	f, ok := formats[v]
+	if !ok {
+		// the chunk could be written by a newer version, so don't touch it
+		return false, nil
+	}
+	return outdated(f.crcSize, cd, p.ccfg.Compression), nil
+}
+
+// readHeader returns the format version and the records payloads codec from the chunk file header.
+// It returns 0 version if the file header is not written yet.
+func readHeader(fn string) (int, codec, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return 0, codecNone, err
+	}
+	defer f.Close()
+	hdr := make([]byte, cCodecOffset+1)
+	if _, err := io.ReadFull(f, hdr); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, codecNone, nil
+		}
+		return 0, codecNone, err
+	}
+	vLen := len(hdrVersion)
+	if !bytes.Equal(hdr[:vLen-1], hdrVersion[:vLen-1]) {
+		return 0, codecNone, nil
+	}
+	v := int(hdr[vLen-1])
+	if f, ok := formats[v]; !ok || !f.hasCodec {
+		return v, codecNone, nil
+	}
+	return v, codec(hdr[cCodecOffset]), nil
+}
@@ -15,14 +15,19 @@ package chunkfs
 
 import (
 	"context"
+	"fmt"
 	"github.com/logrange/linker"
 	"github.com/solarisdb/solaris/golibs/container/lru"
+	gctx "github.com/solarisdb/solaris/golibs/context"
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/files"
 	"github.com/solarisdb/solaris/golibs/logging"
+	"github.com/solarisdb/solaris/pkg/metrics"
 	"os"
 	"path/filepath"
 	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 // Provider manages a pull of opened chunks and allows to return a Chunk object by request.
@@ -37,8 +42,16 @@ type Provider struct {
 	ccfg   Config
 	closed atomic.Bool
 	chunks *lru.ReleasableCache[string, *Chunk]
+	// done is closed when the Provider is closed to stop the idle chunks sweeping
+	done chan struct{}
 }
 
+// cDeleteAttempts and cDeleteBackoff define how long DeleteChunk waits for the chunk to be released by the readers
+const (
+	cDeleteAttempts = 20
+	cDeleteBackoff  = 50 * time.Millisecond
+)
+
 // NewProvider creates the new Provider instance
 func NewProvider(dir string, maxOpenedChunks int, cfg Config) *Provider {
 	p := new(Provider)
@@ -50,6 +63,10 @@ func NewProvider(dir string, maxOpenedChunks int, cfg Config) *Provider {
 	if err != nil {
 		panic(err)
 	}
+	p.done = make(chan struct{})
+	if cfg.OpenedIdleTimeout > 0 {
+		go p.sweepIdle(cfg.OpenedIdleTimeout)
+	}
 	return p
 }
 
@@ -71,29 +88,89 @@ func (p *Provider) GetOpenedChunk(ctx context.Context, cID string, newFile bool)
 	return p.chunks.GetOrCreate(ctx, cID)
 }
 
-// DeleteFileIfEmpty deletes the file chunk if it is empty
-func (p *Provider) DeleteFileIfEmpty(cID string) {
+// DeleteFileIfEmpty deletes the file chunk if it is empty. The chunk is closed, if it is opened, and the file
+// is deleted only when no one holds the chunk, so the readers never lose the file they work with. If the chunk
+// is used at the time, the function waits until it is released, and leaves the file in place if the chunk is not
+// released in time or the ctx is closed.
+func (p *Provider) DeleteFileIfEmpty(ctx context.Context, cID string) {
 	if len(cID) == 0 {
 		return
 	}
-	if !p.CA.setDeleting(cID) {
-		p.logger.Errorf("DeleteFileIfEmpty(): something goes wrong, cannot mark chunk %s for deleting", cID)
+	for i := 0; !p.closed.Load() && p.isFileEmpty(cID); i++ {
+		if p.chunks.Remove(cID) && p.CA.setDeleting(cID) {
+			p.deleteFileIfEmpty(cID)
+			return
+		}
+		if i >= cDeleteAttempts-1 {
+			p.logger.Warnf("DeleteFileIfEmpty(): the chunk %s is used, its empty file is not deleted", cID)
+			return
+		}
+		if err := gctx.Sleep(ctx, cDeleteBackoff); err != nil {
+			return
+		}
 	}
-	defer p.CA.SetIdle(cID)
-	fn := p.GetFileNameByID(cID)
-	fi, err := os.Stat(fn)
-	if err == nil && fi.Size() == 0 {
-		os.Remove(fn)
+}
+
+// DeleteChunk deletes the chunk file locally and remotely. The chunk is closed, if it is opened, and the readers
+// are fenced while the chunk is deleted. If the chunk is used at the time, the function waits until it is released,
+// and returns errors.ErrConflict if it is not released in time. The function returns the size of the deleted local file.
+func (p *Provider) DeleteChunk(ctx context.Context, cID string) (int64, error) {
+	var size int64
+	if fi, err := os.Stat(p.GetFileNameByID(cID)); err == nil {
+		size = fi.Size()
+	}
+	for i := 0; ; i++ {
+		if p.chunks.Remove(cID) {
+			err := p.Replicator.DeleteChunk(ctx, cID, RFRemoteDelete)
+			if err == nil || errors.Is(err, errors.ErrNotExist) {
+				// the chunk could be not replicated yet
+				return size, nil
+			}
+			if !errors.Is(err, errors.ErrConflict) {
+				return 0, err
+			}
+		}
+		if i >= cDeleteAttempts-1 {
+			return 0, fmt.Errorf("the chunk %s is used and could not be deleted: %w", cID, errors.ErrConflict)
+		}
+		if err := gctx.Sleep(ctx, cDeleteBackoff); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// IsTransient returns true if the err returned by GetOpenedChunk is caused by a temporary condition, like
+// running out of the file descriptors or memory, so the call may succeed if it is retried later.
+// The missing or corrupted chunks errors are never transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, errors.ErrNotExist) || errors.Is(err, errCorrupted) {
+		return false
 	}
+	return errors.Is(err, syscall.EMFILE) || errors.Is(err, syscall.ENFILE) ||
+		errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) || errors.Is(err, syscall.ENOMEM)
 }
 
 // Close implements the io.Closer
 func (p *Provider) Close() error {
-	p.closed.Store(true)
+	if !p.closed.Swap(true) {
+		close(p.done)
+	}
 	p.logger.Infof("Close() called")
 	return p.chunks.Close()
 }
 
+// Stats returns the opened chunks cache counters, the hits are the requests for the chunks,
+// which were opened already, and the misses are the requests, which opened the chunks.
+func (p *Provider) Stats() lru.ReleasableCacheStats {
+	return p.chunks.Stats()
+}
+
+// MaxRecordSize returns the maximum size of the record, which could be written into a new chunk,
+// or 0 if the size is checked when the record is written only, see Config.MaxRecordSize
+func (p *Provider) MaxRecordSize() int {
+	return p.ccfg.MaxRecordSize()
+}
+
 // ReleaseChunk must be called as soon as the chunk is not needed anymore
 func (p *Provider) ReleaseChunk(r *lru.Releasable[*Chunk]) {
 	p.chunks.Release(r)
@@ -132,12 +209,26 @@ func (p *Provider) openChunk(ctx context.Context, cID string) (*Chunk, error) {
 		p.logger.Errorf("could not open the chunk=%v. Unrecoverable error, will give up with the chunk for awhile: %v", c, err)
 		p.CA.closeChunk(cID)
 	} else {
+		metrics.ChunksOpened.Inc()
 		p.logger.Infof("the chunk=%v is opened ok", c)
 	}
 
 	return c, err
 }
 
+// deleteFileIfEmpty deletes the empty file of the chunk marked for deleting (see ChunkAccessor.setDeleting)
+func (p *Provider) deleteFileIfEmpty(cID string) {
+	defer p.CA.SetIdle(cID)
+	if p.isFileEmpty(cID) {
+		_ = os.Remove(p.GetFileNameByID(cID))
+	}
+}
+
+func (p *Provider) isFileEmpty(cID string) bool {
+	fi, err := os.Stat(p.GetFileNameByID(cID))
+	return err == nil && fi.Size() == 0
+}
+
 func (p *Provider) downloadFileIfNotExists(ctx context.Context, cID, fn string) (bool, error) {
 	if _, err := os.Stat(fn); err != nil {
 		p.logger.Infof("downloadFileIfNotExists - no file for cID=%s on the local file system: %s", cID, err)
@@ -158,6 +249,22 @@ func (p *Provider) closeChunk(cID string, c *Chunk) {
 	}
 }
 
+// sweepIdle closes the chunks, which are not used for longer than timeout, until the Provider is closed
+func (p *Provider) sweepIdle(timeout time.Duration) {
+	ticker := time.NewTicker(max(timeout/2, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			if n := p.chunks.SweepIdle(timeout); n > 0 {
+				p.logger.Debugf("%d chunks not used for %s are closed", n, timeout)
+			}
+		}
+	}
+}
+
 func (p *Provider) getPathByID(id string) string {
 	ln := len(id)
 	return filepath.Join(p.dir, id[ln-2:ln])
@@ -16,12 +16,18 @@ package chunkfs
 import (
 	context2 "context"
 	"fmt"
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/container/lru"
 	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/files"
 	"github.com/solarisdb/solaris/golibs/sss/inmem"
+	"github.com/solarisdb/solaris/golibs/ulidutils"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -43,7 +49,7 @@ func TestProvider_lifeCycle(t *testing.T) {
 	defer os.RemoveAll(dir)
 
 	p := NewProvider(dir, 1, GetDefaultConfig())
-	p.Replicator = NewReplicator(p.GetFileNameByID)
+	p.Replicator = NewReplicator(p.GetFileNameByID, GetDefaultReplicatorConfig())
 	p.Replicator.Storage = inmem.NewStorage()
 	p.CA = NewChunkAccessor()
 	p.Replicator.CA = p.CA
@@ -91,7 +97,7 @@ func TestProvider_contextClosed(t *testing.T) {
 	defer os.RemoveAll(dir)
 
 	p := NewProvider(dir, 1, GetDefaultConfig())
-	p.Replicator = NewReplicator(p.GetFileNameByID)
+	p.Replicator = NewReplicator(p.GetFileNameByID, GetDefaultReplicatorConfig())
 	p.Replicator.Storage = inmem.NewStorage()
 	p.CA = NewChunkAccessor()
 	p.Replicator.CA = p.CA
@@ -114,3 +120,272 @@ func TestProvider_contextClosed(t *testing.T) {
 	p.ReleaseChunk(&c)
 	time.Sleep(time.Millisecond * 100)
 }
+
+func TestProvider_DeleteChunk(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestProvider_DeleteChunk")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	p := NewProvider(dir, 2, GetDefaultConfig())
+	p.Replicator = NewReplicator(p.GetFileNameByID, GetDefaultReplicatorConfig())
+	p.Replicator.Storage = inmem.NewStorage()
+	p.CA = NewChunkAccessor()
+	p.Replicator.CA = p.CA
+	defer p.Close()
+
+	rc, err := p.GetOpenedChunk(context2.Background(), "lala", true)
+	assert.Nil(t, err)
+	_, err = rc.Value().AppendRecords([]*solaris.Record{{Payload: []byte("hello")}})
+	assert.Nil(t, err)
+
+	ctx, cancel := context2.WithTimeout(context2.Background(), 100*time.Millisecond)
+	_, err = p.DeleteChunk(ctx, "lala")
+	cancel()
+	assert.NotNil(t, err)
+	p.ReleaseChunk(&rc)
+
+	go func() {
+		rc, err := p.GetOpenedChunk(context2.Background(), "lala", false)
+		assert.Nil(t, err)
+		time.Sleep(100 * time.Millisecond)
+		p.ReleaseChunk(&rc)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	size, err := p.DeleteChunk(context2.Background(), "lala")
+	assert.Nil(t, err)
+	assert.True(t, size > 0)
+	_, err = os.Stat(p.GetFileNameByID("lala"))
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+
+	size, err = p.DeleteChunk(context2.Background(), "lala")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), size)
+}
+
+func TestProvider_DeleteFileIfEmptyRace(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestProvider_DeleteFileIfEmptyRace")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	p := NewProvider(dir, 2, GetDefaultConfig())
+	p.Replicator = NewReplicator(p.GetFileNameByID, GetDefaultReplicatorConfig())
+	p.Replicator.Storage = inmem.NewStorage()
+	p.CA = NewChunkAccessor()
+	p.Replicator.CA = p.CA
+	defer p.Close()
+
+	// the reader holds the chunk, but its file is not mapped yet, so the file is deleted after the chunk is released
+	cID := ulidutils.NewID()
+	fn := p.GetFileNameByID(cID)
+	assert.Nil(t, files.EnsureFileExists(fn))
+	assert.Nil(t, p.CA.openChunk(context2.Background(), cID))
+	done := make(chan struct{})
+	go func() {
+		p.DeleteFileIfEmpty(context2.Background(), cID)
+		close(done)
+	}()
+	time.Sleep(2 * cDeleteBackoff)
+	_, err = os.Stat(fn)
+	assert.Nil(t, err, "the file of the chunk held by the reader is deleted")
+	assert.Nil(t, p.CA.closeChunk(cID))
+	<-done
+	_, err = os.Stat(fn)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+
+	// every round the empty chunk is created, and it is read and deleted in parallel. The reader either
+	// opens the chunk, so its file is not deleted anymore, or the file is deleted before, so the chunk doesn't exist
+	for i := 0; i < 300; i++ {
+		cID = ulidutils.NewID()
+		fn = p.GetFileNameByID(cID)
+		assert.Nil(t, files.EnsureFileExists(fn))
+
+		var wg sync.WaitGroup
+		var opened atomic.Int32
+		for r := 0; r < 3; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rc, err := p.GetOpenedChunk(context2.Background(), cID, false)
+				if err != nil {
+					assert.True(t, errors.Is(err, errors.ErrNotExist), "unexpected error: %v", err)
+					return
+				}
+				opened.Add(1)
+				_, err = os.Stat(fn)
+				assert.Nil(t, err, "the file of the opened chunk is deleted")
+				cr, err := rc.Value().OpenChunkReader(false)
+				assert.Nil(t, err)
+				cr.Close()
+				p.ReleaseChunk(&rc)
+			}()
+		}
+		p.DeleteFileIfEmpty(context2.Background(), cID)
+		wg.Wait()
+
+		_, err = os.Stat(fn)
+		if opened.Load() > 0 {
+			assert.Nil(t, err, "the file of the chunk, which was read, is deleted")
+		} else {
+			assert.True(t, errors.Is(err, errors.ErrNotExist))
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.False(t, IsTransient(nil))
+	assert.False(t, IsTransient(fmt.Errorf("no chunk: %w", errors.ErrNotExist)))
+	assert.False(t, IsTransient(fmt.Errorf("bad chunk: %w", errCorrupted)))
+	assert.False(t, IsTransient(errors.ErrInternal))
+	assert.True(t, IsTransient(&os.PathError{Op: "open", Path: "abc", Err: syscall.EMFILE}))
+	assert.True(t, IsTransient(fmt.Errorf("could not open: %w", syscall.ENFILE)))
+}
+
+func TestProvider_Layout(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestProvider_Layout")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	p := NewProvider(dir, 2, GetDefaultConfig())
+	p.Replicator = NewReplicator(p.GetFileNameByID, GetDefaultReplicatorConfig())
+	p.Replicator.Storage = inmem.NewStorage()
+	p.CA = NewChunkAccessor()
+	p.Replicator.CA = p.CA
+	defer p.Close()
+
+	l, err := p.Layout(context2.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, Layout{Current: cVersion, Versions: map[int]VersionStats{}}, l)
+
+	// two chunks in the current format
+	for i := 0; i < 2; i++ {
+		rc, err := p.GetOpenedChunk(context2.Background(), ulidutils.NewID(), true)
+		assert.Nil(t, err)
+		_, err = rc.Value().AppendRecords([]*solaris.Record{{Payload: []byte("hello")}})
+		assert.Nil(t, err)
+		p.ReleaseChunk(&rc)
+	}
+	// the old format chunk
+	hdr := make([]byte, files.BlockSize)
+	copy(hdr, hdrVersionNoCRC)
+	fn := p.GetFileNameByID(ulidutils.NewID())
+	assert.Nil(t, files.EnsureFileExists(fn))
+	assert.Nil(t, os.WriteFile(fn, hdr, 0640))
+	// the chunk file which is not initialized yet
+	assert.Nil(t, files.EnsureFileExists(p.GetFileNameByID(ulidutils.NewID())))
+
+	l, err = p.Layout(context2.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, cVersion, l.Current)
+	assert.Len(t, l.Versions, 2)
+	assert.Equal(t, int64(2), l.Versions[cVersion].Chunks)
+	assert.True(t, l.Versions[cVersion].Size > 0)
+	assert.Equal(t, VersionStats{Chunks: 1, Size: files.BlockSize}, l.Versions[cVersionNoCRC])
+}
+
+func TestProvider_Outdated(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestProvider_Outdated")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := GetDefaultConfig()
+	cfg.Compression = CompressionZstd
+	p := NewProvider(dir, 2, cfg)
+	p.Replicator = NewReplicator(p.GetFileNameByID, GetDefaultReplicatorConfig())
+	p.Replicator.Storage = inmem.NewStorage()
+	p.CA = NewChunkAccessor()
+	p.Replicator.CA = p.CA
+	defer p.Close()
+
+	// the chunk in the configured format
+	cID := ulidutils.NewID()
+	rc, err := p.GetOpenedChunk(context2.Background(), cID, true)
+	assert.Nil(t, err)
+	_, err = rc.Value().AppendRecords([]*solaris.Record{{Payload: []byte("hello")}})
+	assert.Nil(t, err)
+	p.ReleaseChunk(&rc)
+	o, err := p.Outdated(cID)
+	assert.Nil(t, err)
+	assert.False(t, o)
+
+	// the chunks written in the old format and with another codec
+	for _, v := range [][]byte{hdrVersionNoCRC, hdrVersion} {
+		hdr := make([]byte, files.BlockSize)
+		copy(hdr, v)
+		hdr[cCodecOffset] = byte(codecNone)
+		fn := p.GetFileNameByID(ulidutils.NewID())
+		assert.Nil(t, files.EnsureFileExists(fn))
+		assert.Nil(t, os.WriteFile(fn, hdr, 0640))
+		o, err = p.Outdated(filepath.Base(fn))
+		assert.Nil(t, err)
+		assert.True(t, o)
+	}
+
+	// the chunk file which is not initialized yet
+	eID := ulidutils.NewID()
+	assert.Nil(t, files.EnsureFileExists(p.GetFileNameByID(eID)))
+	o, err = p.Outdated(eID)
+	assert.Nil(t, err)
+	assert.False(t, o)
+
+	// the chunk stored remotely only is not downloaded
+	assert.Nil(t, p.Replicator.UploadChunk(context2.Background(), cID))
+	assert.Nil(t, os.Remove(p.GetFileNameByID(cID)))
+	_, err = p.Outdated(cID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	_, err = os.Stat(p.GetFileNameByID(cID))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// BenchmarkProvider_HotSet reads a small hot set of chunks amidst the scans of cold chunks,
+// every chunk is read with one record
+func BenchmarkProvider_HotSet(b *testing.B) {
+	dir, err := os.MkdirTemp("", "BenchmarkProvider_HotSet")
+	assert.Nil(b, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context2.Background()
+	p := NewProvider(dir, 16, GetDefaultConfig())
+	p.Replicator = NewReplicator(p.GetFileNameByID, GetDefaultReplicatorConfig())
+	p.Replicator.Storage = inmem.NewStorage()
+	p.CA = NewChunkAccessor()
+	p.Replicator.CA = p.CA
+	defer p.Close()
+
+	read := func(cID string, newFile bool) {
+		rc, err := p.GetOpenedChunk(ctx, cID, newFile)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if newFile {
+			_, err = rc.Value().AppendRecords([]*solaris.Record{{Payload: []byte("hello")}})
+		} else {
+			var cr *ChunkReader
+			if cr, err = rc.Value().OpenChunkReader(false); err == nil {
+				cr.Next()
+				cr.Close()
+			}
+		}
+		p.ReleaseChunk(&rc)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	hot := make([]string, 8)
+	cold := make([]string, 256)
+	for _, ids := range [][]string{hot, cold} {
+		for i := range ids {
+			ids[i] = ulidutils.NewID()
+			read(ids[i], true)
+		}
+	}
+
+	misses := p.Stats().Misses
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// every hot chunk is read between two cold chunks
+		read(hot[i%len(hot)], false)
+		read(cold[(2*i)%len(cold)], false)
+		read(cold[(2*i+1)%len(cold)], false)
+	}
+	b.ReportMetric(float64(p.Stats().Misses-misses)/float64(b.N), "opens/op")
+}
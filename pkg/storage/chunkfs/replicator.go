@@ -16,7 +16,10 @@ package chunkfs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/logrange/linker"
+	gctx "github.com/solarisdb/solaris/golibs/context"
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/files"
 	"github.com/solarisdb/solaris/golibs/logging"
@@ -24,6 +27,21 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
+)
+
+type (
+	// ReplicatorConfig defines the settings of the chunks replication to the remote Storage
+	ReplicatorConfig struct {
+		// UploadWorkers defines how many sealed chunks (the chunks the log doesn't write to anymore) may be
+		// uploaded to the remote Storage in parallel. Zero value disables the sealed chunks uploads.
+		UploadWorkers int
+		// Retries defines how many times a failed remote Storage operation is retried
+		Retries int
+		// RetryBackoff defines the delay before the first retry, every next delay is doubled
+		RetryBackoff time.Duration
+	}
 )
 
 // Replicator struct implements the object which controls the state of the local file-system and allows to move
@@ -34,6 +52,10 @@ type Replicator struct {
 
 	fileNameByID func(id string) string
 	logger       logging.Logger
+	cfg          ReplicatorConfig
+	sealed       chan string
+	wg           sync.WaitGroup
+	cancel       context.CancelFunc
 }
 
 const (
@@ -41,14 +63,91 @@ const (
 	RFRemoteSync   = 1 << 1
 )
 
+// cSealedQueueSize defines how many sealed chunks may wait for the upload, the chunks above the
+// limit are not queued and left to the Scanner
+const cSealedQueueSize = 1024
+
+var _ linker.Initializer = (*Replicator)(nil)
+var _ linker.Shutdowner = (*Replicator)(nil)
+
 // NewReplicator creates new instance of Replicator
-func NewReplicator(fileNameByID func(id string) string) *Replicator {
+func NewReplicator(fileNameByID func(id string) string, cfg ReplicatorConfig) *Replicator {
 	r := new(Replicator)
 	r.fileNameByID = fileNameByID
 	r.logger = logging.NewLogger("chunkfs.Replicator")
+	r.cfg = cfg
+	r.sealed = make(chan string, cSealedQueueSize)
 	return r
 }
 
+// GetDefaultReplicatorConfig returns the default Replicator config
+func GetDefaultReplicatorConfig() ReplicatorConfig {
+	return ReplicatorConfig{
+		UploadWorkers: 0, // the sealed chunks are not uploaded
+		Retries:       3,
+		RetryBackoff:  time.Second,
+	}
+}
+
+// String implements fmt.Stringer
+func (rc ReplicatorConfig) String() string {
+	b, _ := json.MarshalIndent(rc, "", "  ")
+	return string(b)
+}
+
+// Init implements linker.Initializer
+func (r *Replicator) Init(_ context.Context) error {
+	r.logger.Infof("initializing cfg:\n%s", r.cfg)
+	// the uploads are not bound to the Init ctx, they are stopped by Shutdown
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(context.Background())
+	for i := 0; i < r.cfg.UploadWorkers; i++ {
+		r.wg.Add(1)
+		go r.uploader(ctx)
+	}
+	return nil
+}
+
+// Shutdown implements linker.Shutdowner
+func (r *Replicator) Shutdown() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// ChunkSealed lets the Replicator know that the chunk with ID will not be written anymore, so it could be
+// uploaded to the remote Storage. The chunk is uploaded asynchronously and marked as synced, so the Scanner
+// doesn't upload it again.
+func (r *Replicator) ChunkSealed(cID string) {
+	if r.cfg.UploadWorkers <= 0 {
+		return
+	}
+	select {
+	case r.sealed <- cID:
+	default:
+		r.logger.Warnf("the sealed chunks queue is full, the chunk cID=%s is left for the Scanner", cID)
+	}
+}
+
+func (r *Replicator) uploader(ctx context.Context) {
+	defer r.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cID := <-r.sealed:
+			if err := r.UploadChunk(ctx, cID); err != nil {
+				r.logger.Warnf("could not upload the sealed chunk cID=%s: %s", cID, err)
+				continue
+			}
+			if err := createScanInfo(cID, r.fileNameByID(cID)); err != nil {
+				r.logger.Warnf("the sealed chunk cID=%s is uploaded, but could not be marked as synced: %s", cID, err)
+			}
+		}
+	}
+}
+
 // UploadChunk moves the chunk with ID from the local FS to the remote Storage.
 func (r *Replicator) UploadChunk(ctx context.Context, cID string) error {
 	if err := r.CA.SetWriting(ctx, cID); err != nil {
@@ -145,14 +244,36 @@ func (r *Replicator) zipAndUploadChunk(ctx context.Context, cID string) error {
 		return err
 	}
 
-	// now the zip file itself
-	zf, err := os.Open(zfn)
-	if err != nil {
-		return err
+	return r.withRetries(ctx, "upload", cID, func() error {
+		zf, err := os.Open(zfn)
+		if err != nil {
+			return err
+		}
+		defer zf.Close()
+		return r.Storage.Put(ctx, getStorageKey(cID), zf)
+	})
+}
+
+// withRetries runs f until it succeeds or r.cfg.Retries are made. The errors, which will not disappear
+// on the next attempt (the object doesn't exist, for instance), are returned immediately.
+func (r *Replicator) withRetries(ctx context.Context, op, cID string, f func() error) error {
+	backoff := r.cfg.RetryBackoff
+	for i := 0; ; i++ {
+		err := f()
+		if err == nil || i >= r.cfg.Retries || !isRetryable(err) {
+			return err
+		}
+		r.logger.Warnf("could not %s the chunk cID=%s (attempt %d), will retry in %s: %v", op, cID, i+1, backoff, err)
+		if err := gctx.Sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
 	}
-	defer zf.Close()
+}
 
-	return r.Storage.Put(ctx, getStorageKey(cID), zf)
+func isRetryable(err error) bool {
+	return !errors.Is(err, errors.ErrNotExist) && !errors.Is(err, errors.ErrInvalid) &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
 }
 
 func zipFile(cID, fn, zfn string) error {
@@ -185,6 +306,12 @@ func getStorageKey(cID string) string {
 }
 
 func (r *Replicator) downloadZip(ctx context.Context, cID, zfn string) error {
+	return r.withRetries(ctx, "download", cID, func() error {
+		return r.downloadZipOnce(ctx, cID, zfn)
+	})
+}
+
+func (r *Replicator) downloadZipOnce(ctx context.Context, cID, zfn string) error {
 	rdr, err := r.Storage.Get(ctx, getStorageKey(cID))
 	if err != nil {
 		return err
@@ -16,14 +16,18 @@ package chunkfs
 
 import (
 	"context"
+	"fmt"
 	"github.com/solarisdb/solaris/golibs/cast"
 	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/sss"
 	"github.com/solarisdb/solaris/golibs/sss/inmem"
 	"github.com/solarisdb/solaris/golibs/strutil"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestReplicator_SimpleUploadDownload(t *testing.T) {
@@ -33,7 +37,7 @@ func TestReplicator_SimpleUploadDownload(t *testing.T) {
 
 	r := NewReplicator(func(v string) string {
 		return filepath.Join(dir, v)
-	})
+	}, GetDefaultReplicatorConfig())
 	r.Storage = inmem.NewStorage()
 	r.CA = NewChunkAccessor()
 
@@ -79,7 +83,7 @@ func TestReplicator_SimpleDelete(t *testing.T) {
 
 	r := NewReplicator(func(v string) string {
 		return filepath.Join(dir, v)
-	})
+	}, GetDefaultReplicatorConfig())
 	r.Storage = inmem.NewStorage()
 	r.CA = NewChunkAccessor()
 
@@ -128,6 +132,99 @@ func TestReplicator_SimpleDelete(t *testing.T) {
 	assert.NotNil(t, r.DeleteChunk(context.Background(), cID, RFRemoteDelete))
 }
 
+func TestReplicator_Retries(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestReplicator_Retries")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	r := NewReplicator(func(v string) string {
+		return filepath.Join(dir, v)
+	}, ReplicatorConfig{Retries: 2, RetryBackoff: time.Millisecond})
+	fs := &flakyStorage{Storage: inmem.NewStorage(), fails: 2}
+	r.Storage = fs
+	r.CA = NewChunkAccessor()
+
+	cID := "1234"
+	fn := r.fileNameByID(cID)
+	payload := createRandomFile(t, fn)
+	assert.Nil(t, r.UploadChunk(context.Background(), cID))
+	assert.Equal(t, 3, fs.calls)
+
+	// not enough retries
+	os.Remove(fn)
+	fs.fails, fs.calls = 3, 0
+	assert.NotNil(t, r.DownloadChunk(context.Background(), cID, 0))
+	assert.Equal(t, 3, fs.calls)
+
+	fs.fails, fs.calls = 1, 0
+	assert.Nil(t, r.DownloadChunk(context.Background(), cID, 0))
+	buf, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.Equal(t, buf, cast.StringToByteArray(payload))
+
+	// not existing chunks are not retried
+	fs.calls = 0
+	assert.True(t, errors.Is(r.DownloadChunk(context.Background(), "lslsl", 0), errors.ErrNotExist))
+	assert.Equal(t, 1, fs.calls)
+}
+
+func TestReplicator_ChunkSealed(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestReplicator_ChunkSealed")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	r := NewReplicator(func(v string) string {
+		return filepath.Join(dir, v)
+	}, ReplicatorConfig{UploadWorkers: 2})
+	r.Storage = inmem.NewStorage()
+	r.CA = NewChunkAccessor()
+	assert.Nil(t, r.Init(context.Background()))
+	defer r.Shutdown()
+
+	cID := "1234"
+	fn := r.fileNameByID(cID)
+	payload := createRandomFile(t, fn)
+	r.ChunkSealed(cID)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(fn + cChunkInfoExt)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	si, err := readScanInfo(fn + cChunkInfoExt)
+	assert.Nil(t, err)
+	assert.NotNil(t, si.SyncTime)
+
+	os.Remove(fn)
+	assert.Nil(t, r.DownloadChunk(context.Background(), cID, 0))
+	buf, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.Equal(t, buf, cast.StringToByteArray(payload))
+}
+
+// flakyStorage fails the Put and Get calls the fails number of times
+type flakyStorage struct {
+	sss.Storage
+	fails int
+	calls int
+}
+
+func (fs *flakyStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	fs.calls++
+	if fs.fails > 0 {
+		fs.fails--
+		return nil, fmt.Errorf("get failed: %w", errors.ErrInternal)
+	}
+	return fs.Storage.Get(ctx, key)
+}
+
+func (fs *flakyStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	fs.calls++
+	if fs.fails > 0 {
+		fs.fails--
+		return fmt.Errorf("put failed: %w", errors.ErrInternal)
+	}
+	return fs.Storage.Put(ctx, key, r)
+}
+
 func createRandomFile(t *testing.T, fn string) string {
 	f, err := os.Create(fn)
 	assert.Nil(t, err)
@@ -225,7 +225,7 @@ func Test_CreateAndReadScanInfo(t *testing.T) {
 func testNewScanner(dir string, cfg ScannerConfig) *Scanner {
 	r := NewReplicator(func(v string) string {
 		return filepath.Join(dir, v)
-	})
+	}, GetDefaultReplicatorConfig())
 	r.Storage = inmem.NewStorage()
 	r.CA = NewChunkAccessor()
 	s := NewScanner(r, cfg)
@@ -0,0 +1,108 @@
+// Copyright 2023 The acquirecloud Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkfs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+)
+
+// The sync policies define when the records appended to a chunk are flushed to the disk. The chunk file is
+// memory mapped, so the appended records are in the kernel page cache right after the append, and they
+// survive the process crash with any policy. The policies differ by what may be lost on the OS crash or
+// the power loss.
+const (
+	// SyncNone leaves the flushing to the kernel, which writes the modified pages back on its own (in 30
+	// seconds on Linux by default). The records appended since the last write-back may be lost on the OS
+	// crash. This is the fastest policy and the default one.
+	SyncNone = ""
+	// SyncPerAppend flushes the chunk before every append is reported, so no reported records are lost
+	// on the OS crash. This is the slowest policy, every append waits for the disk.
+	SyncPerAppend = "per-append"
+	// SyncInterval flushes the chunk when the SyncBytes are appended since the last flush, or in the
+	// SyncInterval after the first not flushed append, whatever comes first. The records appended
+	// within the last SyncInterval or SyncBytes may be lost on the OS crash.
+	SyncInterval = "interval"
+)
+
+var syncPolicies = map[string]bool{
+	SyncNone:      true,
+	"none":        true,
+	SyncPerAppend: true,
+	SyncInterval:  true,
+}
+
+const cDefaultSyncInterval = time.Second
+
+// ValidSyncPolicy returns true if the policy is one of the supported sync policies
+func ValidSyncPolicy(policy string) bool {
+	return syncPolicies[policy]
+}
+
+func checkSyncPolicy(policy string) error {
+	if !ValidSyncPolicy(policy) {
+		return fmt.Errorf("unknown sync policy %q, expected one of none, %s or %s: %w", policy, SyncPerAppend, SyncInterval, errors.ErrInvalid)
+	}
+	return nil
+}
+
+// Sync flushes the records appended to the chunk to the disk
+func (c *Chunk) Sync() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.sync()
+}
+
+func (c *Chunk) sync() error {
+	if c.syncTimer != nil {
+		c.syncTimer.Stop()
+		c.syncTimer = nil
+	}
+	if c.mmf == nil || c.unsynced == 0 {
+		return nil
+	}
+	if err := c.mmf.Flush(); err != nil {
+		return fmt.Errorf("could not flush the chunk %s: %v: %w", c.fn, err, errors.ErrInternal)
+	}
+	c.unsynced = 0
+	return nil
+}
+
+// appended counts the size bytes appended to the chunk and flushes the chunk according to its sync policy
+func (c *Chunk) appended(size int) error {
+	c.unsynced += size
+	switch c.cfg.SyncPolicy {
+	case SyncPerAppend:
+		return c.sync()
+	case SyncInterval:
+		if c.cfg.SyncBytes > 0 && c.unsynced >= c.cfg.SyncBytes {
+			return c.sync()
+		}
+		if c.syncTimer == nil {
+			interval := c.cfg.SyncInterval
+			if interval <= 0 {
+				interval = cDefaultSyncInterval
+			}
+			c.syncTimer = time.AfterFunc(interval, func() {
+				if err := c.Sync(); err != nil {
+					c.logger.Errorf("%v", err)
+				}
+			})
+		}
+	}
+	return nil
+}
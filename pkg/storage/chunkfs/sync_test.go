@@ -0,0 +1,127 @@
+// Copyright 2023 The acquirecloud Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/files"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunk_SyncPerAppend(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_SyncPerAppend")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize,
+		SyncPolicy: SyncPerAppend}
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	recs := generateRecords(10, 500)
+	_, err = c.AppendRecords(recs)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, c.unsynced)
+
+	// the process "crashes": the chunk is not closed, and the file is opened by another chunk
+	c2 := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c2.Open(false))
+	defer c2.Close()
+	cr, err := c2.OpenChunkReader(false)
+	assert.Nil(t, err)
+	checkRecords(t, cr, recs)
+	cr.Close()
+	c.mmf.Close()
+}
+
+func TestChunk_SyncInterval(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_SyncInterval")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: 2 * files.BlockSize,
+		SyncPolicy: SyncInterval, SyncInterval: 50 * time.Millisecond, SyncBytes: 2000}
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", cfg)
+	assert.Nil(t, c.Open(false))
+	defer c.Close()
+
+	// flushed by the timer
+	_, err = c.AppendRecords(generateRecords(1, 500))
+	assert.Nil(t, err)
+	assert.Equal(t, len(noAttrs)+500+cCRCSize+cMetaRecordSize, c.unsynced)
+	assert.Eventually(t, func() bool {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		return c.unsynced == 0 && c.syncTimer == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// flushed by the bytes threshold
+	_, err = c.AppendRecords(generateRecords(4, 500))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, c.unsynced)
+	assert.Nil(t, c.syncTimer)
+}
+
+func TestChunk_SyncPolicyInvalid(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestChunk_SyncPolicyInvalid")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "c1")
+	files.EnsureFileExists(fn)
+	c := NewChunk(fn, "c1", Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, SyncPolicy: "always"})
+	assert.True(t, errors.Is(c.Open(false), errors.ErrInvalid))
+	assert.False(t, c.isOpened())
+}
+
+// BenchmarkChunk_AppendSync appends the records of 100 bytes by the batches of 10 records with the different
+// sync policies. The per-append policy waits for the disk on every append, so it is the slowest one.
+func BenchmarkChunk_AppendSync(b *testing.B) {
+	dir, err := os.MkdirTemp("", "BenchmarkChunk_AppendSync")
+	assert.Nil(b, err)
+	defer os.RemoveAll(dir)
+
+	recs := generateRecords(10, 100)
+	for _, sp := range []string{"none", SyncInterval, SyncPerAppend} {
+		b.Run(fmt.Sprintf("sync-%s", sp), func(b *testing.B) {
+			cfg := GetDefaultConfig()
+			cfg.SyncPolicy = sp
+			fn := filepath.Join(dir, "c-"+sp)
+			files.EnsureFileExists(fn)
+			defer os.Remove(fn)
+			c := NewChunk(fn, "c1", cfg)
+			assert.Nil(b, c.Open(false))
+			defer c.Close()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				res, err := c.AppendRecords(recs)
+				assert.Nil(b, err)
+				if res.Written < len(recs) {
+					// the chunk is full, start from the scratch
+					assert.Nil(b, c.Truncate(0))
+				}
+			}
+		})
+	}
+}
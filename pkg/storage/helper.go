@@ -19,7 +19,9 @@ import (
 	"time"
 
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/ulidutils"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -27,6 +29,12 @@ type (
 	LogHelper struct {
 		m map[string][]*solaris.Record
 	}
+
+	// recordsIterator implements RecordIterator over the records read already
+	recordsIterator struct {
+		recs []*solaris.Record
+		idx  int
+	}
 )
 
 var _ Log = (*LogHelper)(nil)
@@ -58,23 +66,25 @@ func (l *LogHelper) QueryRecords(ctx context.Context, request QueryRecordsReques
 	if request.Descending {
 		idx = len(recs) - 1
 		if request.StartID != "" {
-			for idx >= 0 && recs[idx].ID > request.StartID {
+			for idx >= 0 && (recs[idx].ID > request.StartID || (request.StartExclusive && recs[idx].ID == request.StartID)) {
 				idx--
 			}
 		}
+		idx -= int(max(request.Offset, 0))
 		for idx >= 0 && request.Limit > 0 {
-			res = append(res, recs[idx])
+			res = append(res, proto.Clone(recs[idx]).(*solaris.Record))
 			idx--
 			request.Limit--
 		}
 	} else {
 		if request.StartID != "" {
-			for idx < len(recs) && recs[idx].ID < request.StartID {
+			for idx < len(recs) && (recs[idx].ID < request.StartID || (request.StartExclusive && recs[idx].ID == request.StartID)) {
 				idx++
 			}
 		}
+		idx += int(max(request.Offset, 0))
 		for idx < len(recs) && request.Limit > 0 {
-			res = append(res, recs[idx])
+			res = append(res, proto.Clone(recs[idx]).(*solaris.Record))
 			idx++
 			request.Limit--
 		}
@@ -82,7 +92,7 @@ func (l *LogHelper) QueryRecords(ctx context.Context, request QueryRecordsReques
 	return res, idx >= 0 && idx < len(recs), nil
 }
 
-func (l *LogHelper) CountRecords(ctx context.Context, request QueryRecordsRequest) (uint64, uint64, error) {
+func (l *LogHelper) CountRecords(ctx context.Context, request QueryRecordsRequest) (uint64, uint64, bool, error) {
 	recs := l.m[request.LogID]
 	var count uint64
 	total := uint64(len(recs))
@@ -91,7 +101,7 @@ func (l *LogHelper) CountRecords(ctx context.Context, request QueryRecordsReques
 	if request.Descending {
 		idx = len(recs) - 1
 		if request.StartID != "" {
-			for idx >= 0 && recs[idx].ID > request.StartID {
+			for idx >= 0 && (recs[idx].ID > request.StartID || (request.StartExclusive && recs[idx].ID == request.StartID)) {
 				idx--
 			}
 		}
@@ -99,12 +109,81 @@ func (l *LogHelper) CountRecords(ctx context.Context, request QueryRecordsReques
 		count = uint64(idx)
 	} else {
 		if request.StartID != "" {
-			for idx < len(recs) && recs[idx].ID < request.StartID {
+			for idx < len(recs) && (recs[idx].ID < request.StartID || (request.StartExclusive && recs[idx].ID == request.StartID)) {
 				idx++
 			}
 		}
 
 		count = uint64(len(recs) - idx)
 	}
-	return total, count, nil
+	return total, count, true, nil
+}
+
+func (l *LogHelper) TruncateRecords(ctx context.Context, logID string, before time.Time) (int64, error) {
+	recs := l.m[logID]
+	idx := 0
+	for idx < len(recs) && recs[idx].CreatedAt.AsTime().Before(before) {
+		idx++
+	}
+	l.m[logID] = recs[idx:]
+	return int64(idx), nil
+}
+
+func (l *LogHelper) TrimRecords(ctx context.Context, logID string, maxRecords, slack int64) (int64, error) {
+	recs := l.m[logID]
+	if maxRecords <= 0 || int64(len(recs)) <= maxRecords+slack {
+		return 0, nil
+	}
+	removed := int64(len(recs)) - maxRecords
+	l.m[logID] = recs[removed:]
+	return removed, nil
+}
+
+func (l *LogHelper) DeleteRecords(ctx context.Context, logID, fromID, toID string) (int64, error) {
+	recs := l.m[logID]
+	kept := make([]*solaris.Record, 0, len(recs))
+	for _, r := range recs {
+		if (fromID == "" || r.ID >= fromID) && (toID == "" || r.ID <= toID) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	l.m[logID] = kept
+	return int64(len(recs) - len(kept)), nil
+}
+
+func (l *LogHelper) GetRecordByID(ctx context.Context, logID, recordID string) (*solaris.Record, error) {
+	for _, r := range l.m[logID] {
+		if r.ID == recordID {
+			return r, nil
+		}
+	}
+	return nil, errors.ErrNotExist
+}
+
+func (l *LogHelper) OpenRecordIterator(ctx context.Context, request QueryRecordsRequest) (RecordIterator, error) {
+	if request.Limit <= 0 {
+		request.Limit = int64(len(l.m[request.LogID]))
+	}
+	recs, _, err := l.QueryRecords(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &recordsIterator{recs: recs}, nil
+}
+
+func (it *recordsIterator) HasNext() bool {
+	return it.idx < len(it.recs)
+}
+
+func (it *recordsIterator) Next() (*solaris.Record, error) {
+	if !it.HasNext() {
+		return nil, errors.ErrNotExist
+	}
+	it.idx++
+	return it.recs[it.idx-1], nil
+}
+
+func (it *recordsIterator) Close() {
+	it.recs = nil
 }
@@ -16,13 +16,47 @@ package logfs
 
 import (
 	"github.com/solarisdb/solaris/golibs/files"
+	"time"
 )
 
 type Config struct {
 	MaxRecordsLimit int
 	MaxBunchSize    int
-	// MaxLocks defines how many different logs may be managed at a time
+	// MaxLocks defines how many different logs may be read at a time. The appends and other logs modifications
+	// are not limited by the value, so the different logs are modified in parallel.
 	MaxLocks int
+	// OpenChunkRetries defines how many times opening a chunk for read is retried if it fails
+	// with a transient error (see chunkfs.IsTransient). Zero value disables the retries.
+	OpenChunkRetries int
+	// OpenChunkBackoff defines the delay before the first retry, every next delay is doubled
+	OpenChunkBackoff time.Duration
+	// MaxChunksPerLog defines the maximum number of chunks a log may have. The appends, which need a new
+	// chunk above the limit, are rejected with errors.ErrExhausted. Zero value means no limit.
+	MaxChunksPerLog int
+	// ChunksSoftLimitPct defines the percentage of MaxChunksPerLog, starting from which AppendRecords
+	// warns that the log is close to the limit. Zero value disables the warnings.
+	ChunksSoftLimitPct int
+	// AtomicAppends defines the AppendRecords mode, when the request doesn't specify it. If true, the batch is written
+	// completely or not written at all, otherwise the records written before an error are kept.
+	AtomicAppends bool
+	// Sequences enables the records sequence numbers. The log records are numbered 1, 2, 3... in the order they are
+	// appended, the numbering starts from the first record appended after the setting is enabled. Once a log has
+	// the numbered records, its numbering is continued regardless of the setting.
+	Sequences bool
+	// MaxChunkSize defines the chunks size, below which the adjacent sealed chunks of a log are merged by CompactLog.
+	// The log is compacted in background, when its chunk is sealed by an append. Zero value disables the compaction.
+	MaxChunkSize int64
+	// CompactMaxChunks defines how many chunks may be merged by one CompactLog call. Zero value means no limit.
+	CompactMaxChunks int
+	// MaxPruneIntervals defines how many ctime intervals of a records condition may be used to skip the chunks
+	// and the records out of the intervals. If the condition has more intervals, the chunks are read within
+	// the intervals bounds and the records are checked one by one. Zero value means no limit.
+	MaxPruneIntervals int
+	// MetaCommitTimeout defines how long the written or replaced chunks may be committed to the meta-storage. The
+	// chunks are committed regardless of the request cancellation, so the written data is not orphaned. If the
+	// appended chunks are not committed in time, the append fails and its records are rolled back.
+	// Zero value means no timeout.
+	MetaCommitTimeout time.Duration
 }
 
 const (
@@ -32,8 +66,14 @@ const (
 
 func GetDefaultConfig() Config {
 	return Config{
-		MaxRecordsLimit: maxRecordsLimit,
-		MaxBunchSize:    maxBunchSize,
-		MaxLocks:        20000,
+		MaxRecordsLimit:    maxRecordsLimit,
+		MaxBunchSize:       maxBunchSize,
+		MaxLocks:           20000,
+		OpenChunkRetries:   3,
+		OpenChunkBackoff:   10 * time.Millisecond,
+		ChunksSoftLimitPct: 90,
+		CompactMaxChunks:   100,
+		MaxPruneIntervals:  1000,
+		MetaCommitTimeout:  time.Minute,
 	}
 }
@@ -23,21 +23,25 @@ import (
 )
 
 type testLogsMetaStorage struct {
-	lock sync.Mutex
-	logs map[string][]ChunkInfo
+	lock       sync.Mutex
+	logs       map[string][]ChunkInfo
+	appendKeys map[string]AppendKey
+	keyIDs     map[string]string
 }
 
 func newTestLogsMetaStorage() *testLogsMetaStorage {
 	lms := new(testLogsMetaStorage)
 	lms.logs = make(map[string][]ChunkInfo)
+	lms.appendKeys = make(map[string]AppendKey)
+	lms.keyIDs = make(map[string]string)
 	return lms
 }
 
 func (lms *testLogsMetaStorage) GetLastChunk(_ context.Context, logID string) (ChunkInfo, error) {
 	lms.lock.Lock()
 	defer lms.lock.Unlock()
-	cis, ok := lms.logs[logID]
-	if !ok {
+	cis := lms.logs[logID]
+	if len(cis) == 0 {
 		return ChunkInfo{}, errors.ErrNotExist
 	}
 	return cis[len(cis)-1], nil
@@ -50,7 +54,7 @@ func (lms *testLogsMetaStorage) GetChunks(ctx context.Context, logID string) ([]
 	if !ok {
 		return nil, errors.ErrNotExist
 	}
-	return cis, nil
+	return slices.Clone(cis), nil
 }
 
 func (lms *testLogsMetaStorage) UpsertChunkInfos(ctx context.Context, logID string, cis []ChunkInfo) error {
@@ -85,6 +89,52 @@ func (lms *testLogsMetaStorage) UpsertChunkInfos(ctx context.Context, logID stri
 			ecis = append(ecis, ci)
 		}
 	}
+	sort.Slice(ecis, func(i, j int) bool {
+		return ecis[i].ID < ecis[j].ID
+	})
 	lms.logs[logID] = ecis
 	return nil
 }
+
+func (lms *testLogsMetaStorage) DeleteChunkInfos(ctx context.Context, logID string, cIDs []string) error {
+	lms.lock.Lock()
+	defer lms.lock.Unlock()
+	lms.logs[logID] = slices.DeleteFunc(slices.Clone(lms.logs[logID]), func(ci ChunkInfo) bool {
+		return slices.Contains(cIDs, ci.ID)
+	})
+	return nil
+}
+
+func (lms *testLogsMetaStorage) GetLastAppendKey(ctx context.Context, logID string) (AppendKey, error) {
+	lms.lock.Lock()
+	defer lms.lock.Unlock()
+	ak, ok := lms.appendKeys[logID]
+	if !ok {
+		return AppendKey{}, errors.ErrNotExist
+	}
+	return ak, nil
+}
+
+func (lms *testLogsMetaStorage) SetLastAppendKey(ctx context.Context, logID string, ak AppendKey) error {
+	lms.lock.Lock()
+	defer lms.lock.Unlock()
+	lms.appendKeys[logID] = ak
+	return nil
+}
+
+func (lms *testLogsMetaStorage) GetLogKeyID(ctx context.Context, logID string) (string, error) {
+	lms.lock.Lock()
+	defer lms.lock.Unlock()
+	keyID, ok := lms.keyIDs[logID]
+	if !ok {
+		return "", errors.ErrNotExist
+	}
+	return keyID, nil
+}
+
+func (lms *testLogsMetaStorage) SetLogKeyID(ctx context.Context, logID, keyID string) error {
+	lms.lock.Lock()
+	defer lms.lock.Unlock()
+	lms.keyIDs[logID] = keyID
+	return nil
+}
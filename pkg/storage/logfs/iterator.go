@@ -0,0 +1,318 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"context"
+	"crypto/cipher"
+	"fmt"
+	"sync"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/container/lru"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/ulidutils"
+	"github.com/solarisdb/solaris/pkg/metrics"
+	"github.com/solarisdb/solaris/pkg/storage"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
+)
+
+// recordIterator implements storage.RecordIterator, it reads the chunks of the queryPlan one by one in the same
+// order as QueryRecords does, so only one chunk is opened at a time. The iterator holds the log limiter slot
+// while it is open, so it is released as soon as the ctx is closed, even if the iterator is not read or closed
+// by the caller anymore.
+type recordIterator struct {
+	l       *localLog
+	ctx     context.Context
+	request storage.QueryRecordsRequest
+	ll      lru.Releasable[struct{}]
+	qp      queryPlan
+	// lock serializes the reading and the release of the iterator by the closed ctx
+	lock sync.Mutex
+	// stopRelease stops the release of the iterator by the closed ctx, see context.AfterFunc
+	stopRelease func() bool
+	// idx is the index of the next chunk to be read
+	idx int
+	// skip is the number of the matched records left to skip before the returned ones
+	skip int64
+	// left is the number of the records left to return, negative value means no limit
+	left int64
+	// lastID is the last returned record ID, the reading is continued after it if the chunks are replaced
+	lastID ulid.ULID
+	// replans is the number of times the plan was made again, cause the chunks were replaced
+	replans int
+	// read is the number of the returned records
+	read int
+
+	// ci is the chunk read now, the cr is nil if no chunk is read
+	ci     ChunkInfo
+	rc     lru.Releasable[*chunkfs.Chunk]
+	cr     *chunkfs.ChunkReader
+	aead   cipher.AEAD
+	ranges []idRange
+	// rIdx is the index of the range read, inRange tells the reader is positioned within the range
+	rIdx    int
+	inRange bool
+
+	rec    *solaris.Record
+	err    error
+	done   bool
+	closed bool
+}
+
+var _ storage.RecordIterator = (*recordIterator)(nil)
+
+// OpenRecordIterator returns the storage.RecordIterator over the records selected by the request. The records are
+// returned in the same order as QueryRecords returns them, respecting the Descending, StartID (StartSeq),
+// Offset and Condition of the request, but the request Limit is not bounded by the MaxRecordsLimit and zero
+// Limit means no limit. The chunks are opened lazily one by one, so the reading could be stopped any time
+// without the rest chunks being touched. If a chunk is replaced (compacted or migrated) before it is read,
+// the reading is continued after the last returned record.
+func (l *localLog) OpenRecordIterator(ctx context.Context, request storage.QueryRecordsRequest) (storage.RecordIterator, error) {
+	ll, err := l.limiter.GetOrCreate(ctx, request.LogID)
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain the log locker for id=%s: %w", request.LogID, err)
+	}
+	qp, err := l.planQuery(ctx, request)
+	if err != nil {
+		l.limiter.Release(&ll)
+		return nil, err
+	}
+	it := &recordIterator{l: l, ctx: ctx, request: request, ll: ll, qp: qp, idx: qp.fromIdx,
+		skip: max(request.Offset, 0), left: request.Limit}
+	if it.left <= 0 {
+		it.left = -1
+	}
+	it.stopRelease = context.AfterFunc(ctx, it.cancel)
+	return it, nil
+}
+
+// HasNext implements storage.RecordIterator
+func (it *recordIterator) HasNext() bool {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+	return it.hasNext()
+}
+
+// Next implements storage.RecordIterator
+func (it *recordIterator) Next() (*solaris.Record, error) {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+	if !it.hasNext() {
+		return nil, fmt.Errorf("no more records in logID=%s: %w", it.request.LogID, errors.ErrNotExist)
+	}
+	r, err := it.rec, it.err
+	it.rec, it.err = nil, nil
+	return r, err
+}
+
+// Close implements storage.RecordIterator
+func (it *recordIterator) Close() {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+	it.stopRelease()
+	it.release()
+	it.done = true
+	it.rec, it.err = nil, nil
+}
+
+func (it *recordIterator) hasNext() bool {
+	if it.rec == nil && it.err == nil && !it.done {
+		it.rec, it.err = it.fetch()
+		it.done = it.rec == nil
+	}
+	return it.rec != nil || it.err != nil
+}
+
+// cancel releases the iterator, when its ctx is closed. The record fetched already is still returned,
+// but the next HasNext returns the ctx error to be returned by Next.
+func (it *recordIterator) cancel() {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+	it.release()
+}
+
+// release releases the chunk read and the log limiter slot once
+func (it *recordIterator) release() {
+	if it.closed {
+		return
+	}
+	it.closed = true
+	it.closeChunk()
+	it.l.limiter.Release(&it.ll)
+	metrics.RecordsRead.Add(float64(it.read))
+}
+
+// fetch returns the next record, or nil if there are no more records
+func (it *recordIterator) fetch() (*solaris.Record, error) {
+	if it.closed {
+		// the iterator is released by the closed ctx, see cancel
+		return nil, it.ctx.Err()
+	}
+	for it.left != 0 {
+		if it.cr == nil {
+			ok, err := it.openChunk()
+			if err != nil || !ok {
+				return nil, err
+			}
+		}
+		r, err := it.readChunk()
+		if err != nil {
+			if !it.request.SkipCorrupted || !errors.Is(err, errors.ErrCorrupted) {
+				it.closeChunk()
+				return nil, err
+			}
+			// the reading is continued from the next chunk
+			it.l.logger.Warnf("skipping the rest of the corrupted chunk %s of logID=%s: %v", it.ci.ID, it.request.LogID, err)
+		}
+		if r == nil {
+			it.closeChunk()
+			continue
+		}
+		if it.left > 0 {
+			it.left--
+		}
+		it.lastID = ulid.MustParse(r.ID)
+		it.read++
+		return r, nil
+	}
+	it.closeChunk()
+	return nil, nil
+}
+
+// openChunk opens the next chunk of the plan with the records to be read, returns false if there are no more chunks
+func (it *recordIterator) openChunk() (bool, error) {
+	for it.idx >= 0 && it.idx < len(it.qp.cis) {
+		// the request could be canceled while the chunks are read
+		if err := it.ctx.Err(); err != nil {
+			return false, err
+		}
+		ci := it.qp.cis[it.idx]
+		it.idx += it.qp.inc
+		ranges := getRanges(it.qp.tis, ci)
+		if it.qp.limited && len(ranges) == 0 {
+			continue
+		}
+		ranges = considerSIDAndDesc(ranges, it.qp.sid, it.request.Descending)
+		if err := it.open(ci, ranges); err != nil {
+			if errors.Is(err, errors.ErrNotExist) && !it.l.hasChunk(it.ctx, it.request.LogID, ci.ID) && it.replans < cReplacedRetries {
+				it.l.logger.Debugf("the chunk %s is removed from logID=%s, continuing after the recordID=%s", ci.ID, it.request.LogID, it.lastID)
+				if err = it.replan(); err != nil {
+					return false, err
+				}
+				continue
+			}
+			return false, err
+		}
+		it.qp.sid = ulidutils.ZeroULID
+		return true, nil
+	}
+	return false, nil
+}
+
+// replan makes the plan of the rest of the request again, so the reading is continued after the last returned record
+func (it *recordIterator) replan() error {
+	it.replans++
+	request := it.request
+	if it.lastID.Compare(ulidutils.ZeroULID) != 0 {
+		request.StartID = it.lastID.String()
+		request.StartExclusive = true
+		request.StartSeq = 0
+	}
+	qp, err := it.l.planQuery(it.ctx, request)
+	if err != nil {
+		return err
+	}
+	it.qp = qp
+	it.idx = qp.fromIdx
+	return nil
+}
+
+func (it *recordIterator) open(ci ChunkInfo, ranges []idRange) error {
+	aead, err := it.l.chunkAEAD(it.ctx, it.request.LogID, ci)
+	if err != nil {
+		return err
+	}
+	rc, err := it.l.getOpenedChunkForRead(it.ctx, ci.ID)
+	if err != nil {
+		return err
+	}
+	cr, err := rc.Value().OpenChunkReader(it.request.Descending)
+	if err != nil {
+		it.l.ChnkProvider.ReleaseChunk(&rc)
+		return err
+	}
+	it.ci, it.rc, it.cr, it.aead, it.ranges = ci, rc, cr, aead, ranges
+	it.rIdx, it.inRange = -1, false
+	return nil
+}
+
+func (it *recordIterator) closeChunk() {
+	if it.cr == nil {
+		return
+	}
+	it.cr.Close()
+	it.l.ChnkProvider.ReleaseChunk(&it.rc)
+	it.cr = nil
+	it.aead = nil
+	it.ranges = nil
+}
+
+// readChunk returns the next record of the chunk read, which matches the request, or nil if there are no more ones
+func (it *recordIterator) readChunk() (*solaris.Record, error) {
+	desc := it.request.Descending
+	for {
+		if !it.inRange {
+			if it.rIdx+1 >= len(it.ranges) {
+				return nil, it.cr.Err()
+			}
+			it.rIdx++
+			it.inRange = true
+			if start := it.ranges[it.rIdx].start; start.Compare(ulidutils.ZeroULID) != 0 {
+				it.cr.SetStartID(start)
+			}
+		}
+		if !it.cr.HasNext() {
+			it.inRange = false
+			continue
+		}
+		ur, ok := it.cr.Next()
+		if !ok {
+			if err := it.cr.Err(); err != nil {
+				return nil, err
+			}
+			it.inRange = false
+			continue
+		}
+		end := it.ranges[it.rIdx].end
+		if end.Compare(ulidutils.ZeroULID) != 0 && ((desc && ur.ID.Compare(end) < 0) || (!desc && ur.ID.Compare(end) > 0)) {
+			it.inRange = false
+			continue
+		}
+		ur, ok, err := matchRecord(ur, it.aead, it.request.PayloadLen, it.qp.tf, it.qp.rf)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if it.skip > 0 {
+			it.skip--
+			continue
+		}
+		return newRecord(it.request.LogID, it.ci, ur), nil
+	}
+}
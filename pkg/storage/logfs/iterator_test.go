@@ -0,0 +1,196 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/files"
+	"github.com/solarisdb/solaris/pkg/storage"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordIterator(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestRecordIterator")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 10, chunkfs.Config{NewSize: files.BlockSize, MaxChunkSize: 2 * files.BlockSize, MaxGrowIncreaseSize: files.BlockSize})
+	defer p.Close()
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 12; i++ {
+		batch := generateRecords(2, 3000)
+		batch[1].Attributes = map[string]string{"odd": "true"}
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 5)
+
+	ctime := ulid.Time(ulid.MustParse(recs[15].ID).Time()).Format(time.RFC3339Nano)
+	for _, qr := range []storage.QueryRecordsRequest{
+		{LogID: "l1"},
+		{LogID: "l1", Descending: true},
+		{LogID: "l1", StartID: recs[9].ID},
+		{LogID: "l1", StartID: recs[9].ID, StartExclusive: true, Descending: true},
+		{LogID: "l1", Offset: 5, Limit: 11},
+		{LogID: "l1", Condition: "attr('odd') = 'true'", Descending: true, Offset: 3},
+		{LogID: "l1", Condition: fmt.Sprintf("ctime >= '%s'", ctime)},
+		{LogID: "l1", Condition: "ctime < '2000-01-01T00:00:00Z'"},
+	} {
+		t.Run(fmt.Sprintf("%+v", qr), func(t *testing.T) {
+			req := qr
+			if req.Limit == 0 {
+				req.Limit = 1000
+			}
+			expected, _, err := ll.QueryRecords(ctx, req)
+			require.Nil(t, err)
+
+			it, err := ll.OpenRecordIterator(ctx, qr)
+			require.Nil(t, err)
+			defer it.Close()
+			var read []*solaris.Record
+			for it.HasNext() {
+				r, err := it.Next()
+				require.Nil(t, err)
+				read = append(read, r)
+			}
+			assert.Equal(t, expected, read)
+			_, err = it.Next()
+			assert.True(t, errors.Is(err, errors.ErrNotExist))
+		})
+	}
+
+	_, err = ll.OpenRecordIterator(ctx, storage.QueryRecordsRequest{LogID: "l1", StartID: "wrong"})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
+func TestRecordIterator_Close(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 3000), LogID: "l1"})
+		require.Nil(t, err)
+	}
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 10), LogID: "l2"})
+	require.Nil(t, err)
+
+	// the reading is stopped after the first record, so the rest chunks are not read
+	it, err := ll.OpenRecordIterator(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	require.Nil(t, err)
+	assert.True(t, it.HasNext())
+	_, err = it.Next()
+	assert.Nil(t, err)
+	it.Close()
+	it.Close()
+	assert.False(t, it.HasNext())
+
+	// the log is released by the closed iterator, so the other log could be read (MaxLocks=1)
+	tctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, _, err = ll.QueryRecords(tctx, storage.QueryRecordsRequest{LogID: "l2", Limit: 1})
+	assert.Nil(t, err)
+
+	// the canceled request stops the reading
+	cctx, ccancel := context.WithCancel(ctx)
+	it, err = ll.OpenRecordIterator(cctx, storage.QueryRecordsRequest{LogID: "l1"})
+	require.Nil(t, err)
+	defer it.Close()
+	ccancel()
+	assert.True(t, it.HasNext())
+	_, err = it.Next()
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, it.HasNext())
+
+	// the canceled request releases the log, even if the iterator is not read and closed anymore
+	cctx, ccancel = context.WithCancel(ctx)
+	it, err = ll.OpenRecordIterator(cctx, storage.QueryRecordsRequest{LogID: "l1"})
+	require.Nil(t, err)
+	assert.True(t, it.HasNext())
+	ccancel()
+	tctx, cancel = context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, _, err = ll.QueryRecords(tctx, storage.QueryRecordsRequest{LogID: "l2", Limit: 1})
+	assert.Nil(t, err)
+	_, err = it.Next()
+	assert.Nil(t, err)
+	assert.True(t, it.HasNext())
+	_, err = it.Next()
+	assert.True(t, errors.Is(err, context.Canceled))
+	it.Close()
+}
+
+func TestRecordIterator_ChunksReplaced(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestRecordIterator_ChunksReplaced")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 10, chunkfs.Config{NewSize: files.BlockSize, MaxChunkSize: 2 * files.BlockSize, MaxGrowIncreaseSize: files.BlockSize})
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 12; i++ {
+		batch := generateRecords(2, 3000)
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+
+	it, err := ll.OpenRecordIterator(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	require.Nil(t, err)
+	defer it.Close()
+
+	// the chunks are merged before they are read
+	p.Close()
+	p = testProvider(dir, 10, chunkfs.Config{NewSize: files.BlockSize, MaxChunkSize: 16 * files.BlockSize, MaxGrowIncreaseSize: files.BlockSize})
+	defer p.Close()
+	ll.ChnkProvider = p
+	ll.cfg.MaxChunkSize = 16 * files.BlockSize
+	n, err := ll.CompactLog(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, n > 0)
+
+	var read []*solaris.Record
+	for it.HasNext() {
+		r, err := it.Next()
+		require.Nil(t, err)
+		read = append(read, r)
+	}
+	comparePayloads(t, recs, read)
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/cast"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
+)
+
+type (
+	// Keyring provides the keys for encrypting the log records payloads. Every log has its own current key ID,
+	// which is kept in the log metadata (see LogsMetaStorage.GetLogKeyID), and the new chunks are encrypted by
+	// the key with the ID. The previous keys remain available for reading the chunks written before the key rotation.
+	Keyring interface {
+		// GetKey returns the key of the logID by the keyID
+		GetKey(ctx context.Context, logID, keyID string) ([]byte, error)
+	}
+
+	// masterKeyring implements Keyring. The keys are derived from the master key, the log ID and the key ID,
+	// so no keys are stored anywhere, and any key could be got by its ID while the master key is the same.
+	masterKeyring struct {
+		masterKey []byte
+	}
+)
+
+var _ Keyring = (*masterKeyring)(nil)
+
+// NewMasterKeyring creates the new Keyring which derives the keys from the masterKey
+func NewMasterKeyring(masterKey []byte) Keyring {
+	if len(masterKey) == 0 {
+		panic("the master key must not be empty")
+	}
+	return &masterKeyring{masterKey: masterKey}
+}
+
+// GetKey implements Keyring
+func (mk *masterKeyring) GetKey(_ context.Context, logID, keyID string) ([]byte, error) {
+	if len(keyID) == 0 {
+		return nil, fmt.Errorf("the key ID must be specified: %w", errors.ErrInvalid)
+	}
+	mac := hmac.New(sha256.New, mk.masterKey)
+	mac.Write(cast.StringToByteArray(logID))
+	mac.Write([]byte{'/'})
+	mac.Write(cast.StringToByteArray(keyID))
+	return mac.Sum(nil), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the cipher: %w", errors.ErrInternal)
+	}
+	return cipher.NewGCM(b)
+}
+
+// sealRecords returns the copy of recs with the payloads and the attributes encrypted by aead. The attributes
+// are encoded before the payload (see chunkfs.AppendAttributes), so the sealed records have no attributes.
+// Every encrypted payload is prefixed by the random nonce used for its encryption.
+func sealRecords(aead cipher.AEAD, recs []*solaris.Record) ([]*solaris.Record, error) {
+	res := make([]*solaris.Record, len(recs))
+	for i, r := range recs {
+		plain := chunkfs.AppendAttributes(nil, r.Attributes)
+		plain = append(plain, r.Payload...)
+		buf := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		res[i] = &solaris.Record{Payload: aead.Seal(buf, buf, plain, nil)}
+	}
+	return res, nil
+}
+
+// openRecord returns the record sealed by sealRecords with the payload and the attributes decrypted.
+// The payload of the returned record doesn't point into the chunk, so it may be kept.
+func openRecord(aead cipher.AEAD, ur chunkfs.UnsafeRecord) (chunkfs.UnsafeRecord, error) {
+	ns := aead.NonceSize()
+	if len(ur.UnsafePayload) < ns {
+		return ur, fmt.Errorf("the encrypted record ID=%s is too short: %w", ur.ID, errors.ErrDataLoss)
+	}
+	plain, err := aead.Open(nil, ur.UnsafePayload[:ns], ur.UnsafePayload[ns:], nil)
+	if err != nil {
+		return ur, fmt.Errorf("could not decrypt the record ID=%s: %v: %w", ur.ID, err, errors.ErrDataLoss)
+	}
+	if ur.Attributes, ur.UnsafePayload, err = chunkfs.DecodeAttributes(plain); err != nil {
+		return ur, fmt.Errorf("could not decode the encrypted record ID=%s attributes: %v: %w", ur.ID, err, errors.ErrDataLoss)
+	}
+	return ur, nil
+}
@@ -16,7 +16,10 @@ package logfs
 
 import (
 	"context"
+	"crypto/cipher"
 	"fmt"
+	"math"
+	"slices"
 	"sort"
 	"sync"
 
@@ -28,10 +31,12 @@ import (
 	"github.com/solarisdb/solaris/golibs/logging"
 	"github.com/solarisdb/solaris/golibs/ulidutils"
 	"github.com/solarisdb/solaris/pkg/intervals"
+	"github.com/solarisdb/solaris/pkg/metrics"
 	"github.com/solarisdb/solaris/pkg/ql"
 	"github.com/solarisdb/solaris/pkg/storage"
 	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"os"
 	"strings"
 	"time"
 )
@@ -41,24 +46,57 @@ type (
 	localLog struct {
 		LMStorage    LogsMetaStorage   `inject:""`
 		ChnkProvider *chunkfs.Provider `inject:""`
+		// Keyring is optional, if provided the records payloads are stored encrypted
+		Keyring Keyring `inject:",optional"`
+		// DiskMonitor is optional, if provided the appends are rejected when the disk is near full
+		DiskMonitor *chunkfs.DiskMonitor `inject:",optional"`
+		// RecordHashes is optional, if provided the removed records are removed from the payloads hashes index
+		RecordHashes storage.RecordHashes `inject:",optional"`
 
-		cfg     Config
-		logger  logging.Logger
-		lockers *lru.ReleasableCache[string, *logLocker]
+		cfg    Config
+		logger logging.Logger
+		// limiter limits the number of the logs read at a time, see Config.MaxLocks
+		limiter *lru.ReleasableCache[string, struct{}]
+		// logLocks keeps the logs lockers, which serialize the logs modifications
+		logLocks *logLocks
+		// chunkCounts caches the numbers of the logs chunks for the MaxChunksPerLog checks, so the appends don't
+		// read the log chunks list every time, see chunksCount
+		chunkCounts *lru.Cache[string, *int]
+
+		// compacting contains the IDs of the logs compacted in background
+		compacting sync.Map
+		ctx        context.Context
+		cancel     context.CancelFunc
+		wg         sync.WaitGroup
 	}
 
 	logLocker struct {
 		lock sync.Mutex
+		// refs is the number of the logLocker users, see logLocks
+		refs int
 	}
 
 	// LogsMetaStorage interface describes a log meata storage for the log chunks info
 	LogsMetaStorage interface {
 		// GetLastChunk returns the chunk with the biggest chunkID
 		GetLastChunk(ctx context.Context, logID string) (ChunkInfo, error)
-		// GetChunks returns the list of chunks associated with the logID
+		// GetChunks returns the list of chunks associated with the logID. The chunks must be
+		// sorted in the ascending order of their IDs, so the chunks could be binary searched
 		GetChunks(ctx context.Context, logID string) ([]ChunkInfo, error)
 		// UpsertChunkInfos update or insert new records associated with logID into the meta-storage
 		UpsertChunkInfos(ctx context.Context, logID string, cis []ChunkInfo) error
+		// DeleteChunkInfos removes the chunks with the IDs provided from the logID chunks list
+		DeleteChunkInfos(ctx context.Context, logID string, cIDs []string) error
+		// GetLastAppendKey returns the last committed append made with an idempotency key to the logID.
+		// It returns errors.ErrNotExist if there is no such append
+		GetLastAppendKey(ctx context.Context, logID string) (AppendKey, error)
+		// SetLastAppendKey stores the last committed append made with an idempotency key to the logID
+		SetLastAppendKey(ctx context.Context, logID string, ak AppendKey) error
+		// GetLogKeyID returns the ID of the current key the logID new chunks are encrypted with (see Keyring).
+		// It returns errors.ErrNotExist if the log has no key yet
+		GetLogKeyID(ctx context.Context, logID string) (string, error)
+		// SetLogKeyID stores the ID of the current key the logID new chunks are encrypted with
+		SetLogKeyID(ctx context.Context, logID, keyID string) error
 	}
 
 	// ChunkInfo is the descriptor which describes a chunk information in the log meta-storage
@@ -71,12 +109,55 @@ type (
 		Max ulid.ULID `json:"max"`
 		// RecordsCount is the number of records stored in the chunk
 		RecordsCount int `json:"recordsCount"`
+		// KeyID is the ID of the key the chunk records payloads are encrypted with.
+		// Empty value means the payloads are not encrypted.
+		KeyID string `json:"keyID,omitempty"`
+		// FirstSeq is the sequence number of the first record in the chunk, the next chunk records
+		// are numbered one by one. Zero value means the chunk records have no sequence numbers.
+		FirstSeq int64 `json:"firstSeq,omitempty"`
+	}
+
+	// AppendKey describes the result of an append made with an idempotency key, so the append
+	// retries could be answered without writing the records again
+	AppendKey struct {
+		// Key is the idempotency key of the append
+		Key string `json:"key"`
+		// Added is the number of records added
+		Added int64 `json:"added"`
+		// BytesWritten is the total size of the added records payloads
+		BytesWritten int64 `json:"bytesWritten"`
+		// StartID is the first added record ID
+		StartID string `json:"startID"`
+		// LastID is the last added record ID
+		LastID string `json:"lastID"`
 	}
 
 	idRange struct {
 		start ulid.ULID
 		end   ulid.ULID
 	}
+
+	// tiFilter contains the sorted not intersected records creation time intervals, the records are
+	// checked against one by one. The nil filter matches all the records.
+	tiFilter []intervals.Interval[time.Time]
+
+	// recFilter checks the records against the request condition, which refers the records attributes. It is nil
+	// if the condition selects the records by their creation time only, so the time intervals are enough.
+	recFilter ql.ExprF[*solaris.Record]
+
+	// queryPlan describes how the records selected by a query request are read: the log chunks are read
+	// one by one from the fromIdx in the inc direction, starting from the sid record in the first chunk read,
+	// and the records are selected within the time intervals tis (if limited) and by the filters tf and rf.
+	queryPlan struct {
+		cis     []ChunkInfo
+		fromIdx int
+		inc     int
+		sid     ulid.ULID
+		tis     []intervals.Interval[time.Time]
+		limited bool
+		tf      tiFilter
+		rf      recFilter
+	}
 )
 
 const (
@@ -84,13 +165,22 @@ const (
 	ChunkMinID = ""
 	// ChunkMaxID defines the upper boundary for chunk ID (exclusive)
 	ChunkMaxID = "~"
+	// cReplacedRetries defines how many times QueryRecords is repeated, if the chunks are replaced while they are read
+	cReplacedRetries = 3
+	// cChunkCountsCacheSize defines how many logs chunks counts are cached for the MaxChunksPerLog checks
+	cChunkCountsCacheSize = 10000
 )
 
+// errChunkReplaced is reported when the log chunk is removed from the log while it is read
+var errChunkReplaced = fmt.Errorf("the chunk is replaced: %w", errors.ErrConflict)
+
 var _ storage.Log = (*localLog)(nil)
+var _ storage.LogKeys = (*localLog)(nil)
+var _ storage.LogsActivity = (*localLog)(nil)
 
 var (
 	tiBasis   = intervals.BasisTime
-	tiBuilder = ql.NewParamIntervalBuilder(tiBasis, ql.RecordsCondValueDialect, "ctime", ql.OpsAll)
+	tiBuilder = ql.NewMultiParamIntervalBuilder(tiBasis, ql.RecordsCondValueDialect, []string{"ctime"}, ql.OpsAll)
 )
 
 // NewLocalLog creates the new localLog object for the cfg provided
@@ -98,34 +188,78 @@ func NewLocalLog(cfg Config) *localLog {
 	l := new(localLog)
 	l.cfg = cfg
 	l.logger = logging.NewLogger("localLog")
+	l.ctx, l.cancel = context.WithCancel(context.Background())
 	var err error
-	l.lockers, err = lru.NewReleasableCache[string, *logLocker](cfg.MaxLocks,
-		func(ctx context.Context, lid string) (*logLocker, error) {
-			return &logLocker{}, nil
+	l.limiter, err = lru.NewReleasableCache[string, struct{}](cfg.MaxLocks,
+		func(ctx context.Context, lid string) (struct{}, error) {
+			return struct{}{}, nil
 		}, nil)
 	if err != nil {
 		panic(err)
 	}
+	l.chunkCounts, err = lru.NewCache[string, *int](cChunkCountsCacheSize, func(lid string) (*int, error) {
+		unknown := -1
+		return &unknown, nil
+	}, nil)
+	if err != nil {
+		panic(err)
+	}
+	l.logLocks = newLogLocks()
 	return l
 }
 
 // Shutdown implements linker.Shutdowner
 func (l *localLog) Shutdown() {
 	l.logger.Infof("Shutting down.")
-	l.lockers.Close()
+	l.cancel()
+	l.wg.Wait()
+	l.logLocks.close()
+	l.limiter.Close()
 }
 
 // AppendRecords allows to write reocrds into the chunks on the local FS and update the Logs catalog with the new
 // chunks created
-func (l *localLog) AppendRecords(ctx context.Context, request *solaris.AppendRecordsRequest) (*solaris.AppendRecordsResult, error) {
+func (l *localLog) AppendRecords(ctx context.Context, request *solaris.AppendRecordsRequest) (res *solaris.AppendRecordsResult, err error) {
+	defer func(start time.Time) {
+		metrics.ObserveSince(metrics.AppendDuration, start)
+		if res != nil {
+			metrics.RecordsWritten.Add(float64(res.Added))
+		}
+	}(time.Now())
 	lid := request.LogID
-	ll, err := l.lockers.GetOrCreate(ctx, lid)
+	if l.DiskMonitor != nil {
+		if err := l.DiskMonitor.CheckWritable(); err != nil {
+			return nil, err
+		}
+	}
+	ll, err := l.logLocks.acquire(lid)
 	if err != nil {
 		return nil, fmt.Errorf("could not obtain the log locker for id=%s: %w", lid, err)
 	}
-	defer l.lockers.Release(&ll)
-	ll.Value().lock.Lock()
-	defer ll.Value().lock.Unlock()
+	defer l.logLocks.release(lid)
+	// emptyIDs contains the new chunks left empty, their files are deleted after the log lock is released,
+	// cause the deletion waits for the chunks readers
+	var emptyIDs []string
+	defer func() {
+		l.deleteEmptyFiles(ctx, emptyIDs)
+	}()
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+
+	if request.IdempotencyKey != "" {
+		ak, err := l.LMStorage.GetLastAppendKey(ctx, lid)
+		if err != nil && !errors.Is(err, errors.ErrNotExist) {
+			return nil, fmt.Errorf("could not get the last append key for logID=%s: %w", lid, err)
+		}
+		if err == nil && request.IdempotencyKey <= ak.Key {
+			if request.IdempotencyKey == ak.Key {
+				l.logger.Debugf("the append with key=%s to logID=%s is already committed, returning its result", ak.Key, lid)
+				return ak.result(), nil
+			}
+			l.logger.Debugf("the append key=%s to logID=%s is older than the last committed one=%s, ignoring it", request.IdempotencyKey, lid, ak.Key)
+			return &solaris.AppendRecordsResult{}, nil
+		}
+	}
 
 	cis := []ChunkInfo{}
 
@@ -133,13 +267,99 @@ func (l *localLog) AppendRecords(ctx context.Context, request *solaris.AppendRec
 	if err != nil && !errors.Is(err, errors.ErrNotExist) {
 		return nil, err
 	}
+	// lastID is the chunk the records were written to last, it is sealed when a new chunk is created
+	lastID := ci.ID
+	// seq is the sequence number of the next written record, 0 if the log records are not numbered
+	seq := ci.nextSeq()
+	if seq == 0 && l.cfg.Sequences {
+		seq = 1
+		if ci.RecordsCount > 0 {
+			// the last chunk records are not numbered, so the numbered records start in a new chunk
+			ci = ChunkInfo{}
+		}
+	}
 
 	recs := request.Records
+	keyID := ""
+	if l.Keyring != nil {
+		if keyID, err = l.currentKeyID(ctx, lid); err != nil {
+			return nil, err
+		}
+		key, err := l.Keyring.GetKey(ctx, lid, keyID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the key ID=%s for logID=%s: %w", keyID, lid, err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		if recs, err = sealRecords(aead, recs); err != nil {
+			return nil, err
+		}
+	}
+	if ci.KeyID != keyID {
+		// the log key was rotated, so the records will be written into a new chunk
+		ci = ChunkInfo{}
+	}
+	sealed := recs
+
+	atomic := l.cfg.AtomicAppends
+	switch request.Mode {
+	case solaris.AppendMode_APPEND_MODE_ATOMIC:
+		atomic = true
+	case solaris.AppendMode_APPEND_MODE_PARTIAL:
+		atomic = false
+	}
+
+	// the records, which could not be written into any chunk or read in a bunch, are rejected before the chunks
+	// are touched, so no empty chunk is created for them. The records before the first rejected one are written
+	// if the append is not atomic. The size of the compressed records is checked when they are written only.
+	maxSize := l.ChnkProvider.MaxRecordSize()
+	var tooLarge error
+	for i, r := range recs {
+		if l.cfg.MaxBunchSize > 0 && len(r.Payload) > l.cfg.MaxBunchSize {
+			tooLarge = fmt.Errorf("the record #%d payload size=%d exceeds the maximum bunch size=%d: %w", i, len(r.Payload), l.cfg.MaxBunchSize, errors.ErrInvalid)
+		} else if size := chunkfs.RecordSize(r); maxSize > 0 && size > maxSize {
+			tooLarge = fmt.Errorf("the record #%d size=%d exceeds the maximum record size=%d: %w", i, size, maxSize, errors.ErrInvalid)
+		}
+		if tooLarge != nil {
+			if atomic {
+				return &solaris.AppendRecordsResult{}, tooLarge
+			}
+			recs = recs[:i]
+			break
+		}
+	}
+
+	chunks := 0
+	var committed *int
+	if l.cfg.MaxChunksPerLog > 0 {
+		if committed, err = l.chunksCount(ctx, lid); err != nil {
+			return nil, err
+		}
+		chunks = *committed
+	}
+
 	added := 0
+	// prevCounts contains the records counts of the cis chunks before the append, to roll the append back if needed
+	var prevCounts []int
+	// sealedIDs contains the chunks IDs, which will not be written anymore, cause the new chunks follow them
+	var sealedIDs []string
 	var gerr error
+	// reason tells why the records were not written, if the loop is stopped by the gerr
+	reason := solaris.RejectReason_REJECT_REASON_ERROR
 	for len(recs) > 0 {
 		if ci.RecordsCount == 0 {
-			ci = ChunkInfo{ID: ulidutils.NewID()}
+			if l.cfg.MaxChunksPerLog > 0 && chunks >= l.cfg.MaxChunksPerLog {
+				gerr = fmt.Errorf("the logID=%s reached the maximum number of chunks %d: %w", lid, l.cfg.MaxChunksPerLog, errors.ErrExhausted)
+				reason = solaris.RejectReason_REJECT_REASON_EXHAUSTED
+				break
+			}
+			if lastID != "" {
+				sealedIDs = append(sealedIDs, lastID)
+			}
+			chunks++
+			ci = ChunkInfo{ID: ulidutils.NewID(), KeyID: keyID, FirstSeq: seq}
 			l.logger.Infof("creating new chunk id=%s for the logID=%s", ci.ID, lid)
 		}
 		arr, err := l.appendRecords(ctx, ci.ID, ci.RecordsCount == 0, recs)
@@ -151,51 +371,174 @@ func (l *localLog) AppendRecords(ctx context.Context, request *solaris.AppendRec
 			if ci.RecordsCount == 0 {
 				ci.Min = arr.StartID
 			}
+			prevCounts = append(prevCounts, ci.RecordsCount)
 			ci.Max = arr.LastID
 			ci.RecordsCount += arr.Written
 			cis = append(cis, ci)
 			recs = recs[arr.Written:]
 			added += arr.Written
+			if seq > 0 {
+				seq += int64(arr.Written)
+			}
+			lastID = ci.ID
 			ci.ID = ""
 		} else if ci.RecordsCount == 0 {
 			// the chunk was just created and its capacity is not enough to write at least one record!
 			gerr = fmt.Errorf("it seems the maximum chunk size is less than the record size payload=%d: %w", len(recs[0].Payload), errors.ErrInvalid)
+			reason = solaris.RejectReason_REJECT_REASON_TOO_LARGE
 			break
 		}
 		ci.RecordsCount = 0
 	}
+	if gerr == nil && tooLarge != nil {
+		gerr = tooLarge
+		reason = solaris.RejectReason_REJECT_REASON_TOO_LARGE
+	}
+
+	if ci.RecordsCount == 0 && ci.ID != "" {
+		emptyIDs = append(emptyIDs, ci.ID)
+	}
 
-	if ci.RecordsCount == 0 {
-		l.ChnkProvider.DeleteFileIfEmpty(ci.ID)
+	if gerr != nil && added > 0 && atomic {
+		kept, eIDs := l.rollbackChunks(ctx, lid, cis, prevCounts)
+		emptyIDs = append(emptyIDs, eIDs...)
+		if kept > 0 {
+			l.logger.Errorf("AppendRecords: could not roll back the partial write to logID=%s, %d chunk(s) keep the records", lid, kept)
+		}
+		cis = cis[:kept]
+		added = 0
+		for i, ci := range cis {
+			added += ci.RecordsCount - prevCounts[i]
+		}
 	}
 
+	partial := false
 	if added > 0 {
-		// use context.Background instead of ctx to avoid some unrecoverable error in case of the ctx is closed, but we have some
-		// data written
-		if err := l.LMStorage.UpsertChunkInfos(ctx, lid, cis); err != nil {
-			// well, now it is unrecoverable!
-			l.logger.Errorf("could not write chunk IDs=%v for logID=%s, but the data is written into chunk. The data is corrupted now: %v", cis, lid, err)
-			panic("unrecoverable error, data is corrupted")
+		// the data is written already, so the chunks are committed even if the request is cancelled
+		if err := l.commitChunks(ctx, lid, cis); err != nil {
+			// the written records are not in the meta-storage, so they are removed from the chunks
+			kept, eIDs := l.rollbackChunks(context.WithoutCancel(ctx), lid, cis, prevCounts)
+			emptyIDs = append(emptyIDs, eIDs...)
+			if kept > 0 {
+				l.logger.Errorf("AppendRecords: could not commit chunk IDs=%v for logID=%s and roll back the records in %d chunk(s): %v", cis, lid, kept, err)
+			}
+			l.chunkCounts.Remove(lid)
+			return nil, fmt.Errorf("could not commit the written chunks of logID=%s: %w", lid, err)
+		}
+		if committed != nil {
+			// the chunks without records before the append are the new ones
+			for i := range cis {
+				if prevCounts[i] == 0 {
+					*committed++
+				}
+			}
 		}
 		if gerr != nil {
 			l.logger.Warnf("AppendRecords: got the error=%v, but would be able to write some data for logID=%s, added=%d", gerr, lid, added)
+			partial = true
+		}
+		for _, cID := range sealedIDs {
+			l.ChnkProvider.Replicator.ChunkSealed(cID)
+		}
+		if len(sealedIDs) > 0 {
+			l.compactInBackground(lid)
 		}
-		gerr = nil // disregard the error, cause we could write something
 	}
 
 	response := &solaris.AppendRecordsResult{Added: int64(added)}
+	if added > 0 {
+		for _, r := range sealed[:added] {
+			response.BytesWritten += int64(len(r.Payload))
+		}
+		response.StartID = sealed[0].ID
+		response.LastID = sealed[added-1].ID
+	}
+	if partial {
+		// disregard the error, cause we could write something, but let the client know the write is partial
+		response.Partial = true
+		response.PartialError = gerr.Error()
+		response.Rejected = &solaris.AppendRejected{Count: int64(len(sealed) - added), Reason: reason}
+		gerr = nil
+	} else if gerr != nil && !atomic && reason == solaris.RejectReason_REJECT_REASON_TOO_LARGE {
+		// nothing is written, cause the first record is too large, so the error is returned, but the client
+		// still can tell the whole batch is rejected by its size
+		response.Rejected = &solaris.AppendRejected{Count: int64(len(sealed)), Reason: reason}
+	}
+	if added > 0 && request.IdempotencyKey != "" {
+		ak := AppendKey{Key: request.IdempotencyKey, Added: response.Added, BytesWritten: response.BytesWritten,
+			StartID: response.StartID, LastID: response.LastID}
+		if err := l.LMStorage.SetLastAppendKey(ctx, lid, ak); err != nil {
+			// the records are written already, so report the success, but the retry would add the records again
+			l.logger.Errorf("could not store the append key=%s for logID=%s: %v", ak.Key, lid, err)
+		}
+	}
 	if request.ExpandIDs {
 		ids := make([]string, added)
 		for idx := 0; idx < added; idx++ {
-			ids[idx] = request.Records[idx].ID
+			ids[idx] = sealed[idx].ID
 		}
 		response.RecordIDs = ids
 	}
+	if l.cfg.MaxChunksPerLog > 0 && l.cfg.ChunksSoftLimitPct > 0 && chunks*100 >= l.cfg.MaxChunksPerLog*l.cfg.ChunksSoftLimitPct {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("the log is at %d%% of the chunks quota (%d of %d chunks)",
+			chunks*100/l.cfg.MaxChunksPerLog, chunks, l.cfg.MaxChunksPerLog))
+	}
 
 	return response, gerr
 }
 
+// RotateLogKey implements storage.LogKeys
+func (l *localLog) RotateLogKey(ctx context.Context, logID string) (string, error) {
+	if l.Keyring == nil {
+		return "", fmt.Errorf("the records encryption is not enabled: %w", errors.ErrUnimplemented)
+	}
+	ll, err := l.logLocks.acquire(logID)
+	if err != nil {
+		return "", fmt.Errorf("could not obtain the log locker for id=%s: %w", logID, err)
+	}
+	defer l.logLocks.release(logID)
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+
+	keyID := ulidutils.NewID()
+	if err := l.LMStorage.SetLogKeyID(ctx, logID, keyID); err != nil {
+		return "", fmt.Errorf("could not set the key ID=%s for logID=%s: %w", keyID, logID, err)
+	}
+	l.logger.Infof("the key of logID=%s is rotated, the new key ID=%s", logID, keyID)
+	return keyID, nil
+}
+
+// currentKeyID returns the ID of the key the new chunks of the log are encrypted with. The first key ID of the
+// log is made on its first append, so the log lock must be held by the caller.
+func (l *localLog) currentKeyID(ctx context.Context, logID string) (string, error) {
+	keyID, err := l.LMStorage.GetLogKeyID(ctx, logID)
+	if err == nil {
+		return keyID, nil
+	}
+	if !errors.Is(err, errors.ErrNotExist) {
+		return "", fmt.Errorf("could not get the key ID for logID=%s: %w", logID, err)
+	}
+	keyID = ulidutils.NewID()
+	if err = l.LMStorage.SetLogKeyID(ctx, logID, keyID); err != nil {
+		return "", fmt.Errorf("could not set the key ID=%s for logID=%s: %w", keyID, logID, err)
+	}
+	return keyID, nil
+}
+
 func (l *localLog) appendRecords(ctx context.Context, cID string, newFile bool, recs []*solaris.Record) (chunkfs.AppendRecordsResult, error) {
+	return l.writeChunk(ctx, cID, newFile, func(c *chunkfs.Chunk) (chunkfs.AppendRecordsResult, error) {
+		return c.AppendRecords(recs)
+	})
+}
+
+func (l *localLog) copyRecords(ctx context.Context, cID string, newFile bool, recs []*solaris.Record) (chunkfs.AppendRecordsResult, error) {
+	return l.writeChunk(ctx, cID, newFile, func(c *chunkfs.Chunk) (chunkfs.AppendRecordsResult, error) {
+		return c.CopyRecords(recs)
+	})
+}
+
+func (l *localLog) writeChunk(ctx context.Context, cID string, newFile bool,
+	f func(c *chunkfs.Chunk) (chunkfs.AppendRecordsResult, error)) (chunkfs.AppendRecordsResult, error) {
 	rc, err := l.ChnkProvider.GetOpenedChunk(ctx, cID, newFile)
 	if err != nil {
 		return chunkfs.AppendRecordsResult{}, err
@@ -208,321 +551,1587 @@ func (l *localLog) appendRecords(ctx context.Context, cID string, newFile bool,
 	}
 	defer l.ChnkProvider.CA.SetIdle(cID)
 
-	return rc.Value().AppendRecords(recs)
+	return f(rc.Value())
 }
 
 // QueryRecords allows to retrieve records from the Log by its ID. The function will control the limit of the result. If
 // the number of records or the cumulative payload size hit the limits the function may return fewer records than requested
 // or available. The second return parameters returns whether there are potentially more records than requested.
+// If a chunk is replaced (compacted or migrated) while the records are read, the request is repeated.
 func (l *localLog) QueryRecords(ctx context.Context, request storage.QueryRecordsRequest) ([]*solaris.Record, bool, error) {
+	start := time.Now()
+	for i := 0; ; i++ {
+		res, more, err := l.queryRecords(ctx, request)
+		if !errors.Is(err, errChunkReplaced) || i >= cReplacedRetries {
+			metrics.ObserveSince(metrics.QueryDuration, start)
+			metrics.RecordsRead.Add(float64(len(res)))
+			return res, more, err
+		}
+		l.logger.Debugf("repeating the query for logID=%s: %v", request.LogID, err)
+	}
+}
+
+func (l *localLog) queryRecords(ctx context.Context, request storage.QueryRecordsRequest) ([]*solaris.Record, bool, error) {
 	lid := request.LogID
 
-	// the l.lockers plays a role of limiter as well, it doesn't allow to have more than N locks available,
-	// so the l.lockers.GetOrCreate(ctx, lid) will be blocked if number of requested locks (not the number of requests!)
-	// exceeds the maximum (N) capacity.
-	// We will request the lock for supporting the limited number of logs in a work a time, but will not to Lock it for
-	// the read operation. Only AppendRecords does this to support its atomicy.
-	ll, err := l.lockers.GetOrCreate(ctx, lid)
+	// the l.limiter doesn't allow to read more than N logs at a time, so the l.limiter.GetOrCreate(ctx, lid)
+	// will be blocked if number of the logs read (not the number of requests!) exceeds the maximum (N) capacity.
+	// The reads don't lock the log, only the log modifications are serialized by the l.logLocks.
+	ll, err := l.limiter.GetOrCreate(ctx, lid)
 	if err != nil {
 		return nil, false, fmt.Errorf("could not obtain the log locker for id=%s: %w", lid, err)
 	}
-	defer l.lockers.Release(&ll)
-
-	cis, err := l.LMStorage.GetChunks(ctx, lid)
-	if err != nil {
-		return nil, false, err
-	}
-	if len(cis) == 0 {
-		return nil, false, nil
-	}
-
-	var fromIdx int
-	inc := 1
-	if request.Descending {
-		inc = -1
-		fromIdx = len(cis) - 1
-	}
-
-	var sid ulid.ULID
-	if request.StartID != "" {
-		if err = sid.UnmarshalText(cast.StringToByteArray(request.StartID)); err != nil {
-			l.logger.Warnf("could not unmarshal startID=%s: %v", request.StartID, err)
-			return nil, false, fmt.Errorf("wrong startID=%q: %w", request.StartID, errors.ErrInvalid)
-		}
-		if request.Descending {
-			fromIdx = sort.Search(len(cis), func(i int) bool {
-				return cis[i].Min.Compare(sid) > 0
-			})
-			fromIdx--
-			inc = -1
-		} else {
-			fromIdx = sort.Search(len(cis), func(i int) bool {
-				return cis[i].Max.Compare(sid) >= 0
-			})
-		}
-	}
+	defer l.limiter.Release(&ll)
 
-	tis, err := getIntervals(request.Condition)
-	if err != nil {
+	qp, err := l.planQuery(ctx, request)
+	if err != nil || len(qp.cis) == 0 {
 		return nil, false, err
 	}
-	if len(request.Condition) > 0 && len(tis) == 0 {
-		return nil, false, nil
-	}
 
 	limit := int(request.Limit)
 	if limit > l.cfg.MaxRecordsLimit {
 		limit = l.cfg.MaxRecordsLimit
 	}
 	totalSize := 0
+	// skip is the number of the matched records left to skip before the result ones
+	skip := max(request.Offset, 0)
 
 	var res []*solaris.Record
-	for idx := fromIdx; idx >= 0 && idx < len(cis) && limit > len(res); idx += inc {
-		ci := cis[idx]
-		idRanges := getRanges(tis, ci)
-		if len(request.Condition) > 0 && len(idRanges) == 0 {
+	for idx := qp.fromIdx; idx >= 0 && idx < len(qp.cis) && limit > len(res); idx += qp.inc {
+		// the request could be canceled while the chunks are read
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		ci := qp.cis[idx]
+		idRanges := getRanges(qp.tis, ci)
+		if qp.limited && len(idRanges) == 0 {
+			continue
+		}
+		// the whole chunks are skipped by their RecordsCount, if all their records match the request
+		if skip > 0 && qp.sid.Compare(ulidutils.ZeroULID) == 0 && request.PayloadLen.IsAny() && qp.tf == nil && qp.rf == nil &&
+			(len(idRanges) == 0 || coversChunk(idRanges, ci)) && int64(ci.RecordsCount) <= skip {
+			skip -= int64(ci.RecordsCount)
 			continue
 		}
-		srecs, err := l.readRecords(ctx, lid, ci, request.Descending, considerSIDAndDesc(idRanges, sid, request.Descending), limit-len(res), &totalSize)
+		srecs, err := l.readRecords(ctx, lid, ci, request.Descending, considerSIDAndDesc(idRanges, qp.sid, request.Descending), request.PayloadLen, qp.tf, qp.rf, &skip, limit-len(res), &totalSize)
 		if err != nil {
-			return nil, false, err
+			if errors.Is(err, errors.ErrNotExist) && !l.hasChunk(ctx, lid, ci.ID) {
+				return nil, false, fmt.Errorf("the chunk %s is removed from logID=%s: %w", ci.ID, lid, errChunkReplaced)
+			}
+			if !request.SkipCorrupted || !errors.Is(err, errors.ErrCorrupted) {
+				return nil, false, err
+			}
+			// the records read before the corrupted one are kept, and the reading is continued from the next chunk
+			l.logger.Warnf("skipping the rest of the corrupted chunk %s of logID=%s after %d records read: %v", ci.ID, lid, len(srecs), err)
 		}
 		res = append(res, srecs...)
-		sid = ulidutils.ZeroULID
+		qp.sid = ulidutils.ZeroULID
 	}
 	return res, len(res) >= limit || totalSize >= l.cfg.MaxBunchSize, nil
 }
 
-// CountRecords count total number for records in the log and number of records after (before)
-// specified record ID which match the request condition. Returned values are (total, count, error).
-func (l *localLog) CountRecords(ctx context.Context, request storage.QueryRecordsRequest) (uint64, uint64, error) {
-	lid := request.LogID
-
-	// the l.lockers plays a role of limiter as well, it doesn't allow to have more than N locks available,
-	// so the l.lockers.GetOrCreate(ctx, lid) will be blocked if number of requested locks (not the number of requests!)
-	// exceeds the maximum (N) capacity.
-	// We will request the lock for supporting the limited number of logs in a work a time, but will not to Lock it for
-	// the read operation. Only AppendRecords does this to support its atomicy.
-	ll, err := l.lockers.GetOrCreate(ctx, lid)
-	if err != nil {
-		return 0, 0, fmt.Errorf("could not obtain the log locker for id=%s: %w", lid, err)
+// planQuery returns the queryPlan of the request. The plan has no chunks if the request selects no records.
+func (l *localLog) planQuery(ctx context.Context, request storage.QueryRecordsRequest) (queryPlan, error) {
+	cis, err := l.getChunks(ctx, request.LogID)
+	if err != nil || len(cis) == 0 {
+		return queryPlan{}, err
 	}
-	defer l.lockers.Release(&ll)
 
-	cis, err := l.LMStorage.GetChunks(ctx, lid)
-	if err != nil {
-		return 0, 0, err
-	}
-	if len(cis) == 0 {
-		return 0, 0, nil
+	qp := queryPlan{cis: cis, inc: 1}
+	if request.Descending {
+		qp.inc = -1
+		qp.fromIdx = len(cis) - 1
 	}
 
-	var initIdx int
-	var fromIdx int
-
-	inc := 1
-	if request.Descending {
-		inc = -1
-		initIdx = len(cis) - 1
-		fromIdx = len(cis) - 1
+	if request.StartSeq > 0 {
+		if request.StartID, err = l.startIDBySeq(ctx, cis, request.StartSeq, request.Descending); err != nil {
+			return queryPlan{}, err
+		}
 	}
 
-	var sid ulid.ULID
 	if request.StartID != "" {
-		if err = sid.UnmarshalText(cast.StringToByteArray(request.StartID)); err != nil {
+		if err = qp.sid.UnmarshalText(cast.StringToByteArray(request.StartID)); err != nil {
 			l.logger.Warnf("could not unmarshal startID=%s: %v", request.StartID, err)
-			return 0, 0, fmt.Errorf("wrong startID=%q: %w", request.StartID, errors.ErrInvalid)
+			return queryPlan{}, fmt.Errorf("wrong startID=%q: %w", request.StartID, errors.ErrInvalid)
+		}
+		if request.StartExclusive && request.StartSeq == 0 {
+			qp.sid = skipID(qp.sid, request.Descending)
 		}
 		if request.Descending {
-			fromIdx = sort.Search(len(cis), func(i int) bool {
-				return cis[i].Min.Compare(sid) > 0
+			qp.fromIdx = sort.Search(len(cis), func(i int) bool {
+				return cis[i].Min.Compare(qp.sid) > 0
 			})
-			fromIdx--
+			qp.fromIdx--
 		} else {
-			fromIdx = sort.Search(len(cis), func(i int) bool {
-				return cis[i].Max.Compare(sid) >= 0
+			qp.fromIdx = sort.Search(len(cis), func(i int) bool {
+				return cis[i].Max.Compare(qp.sid) >= 0
 			})
 		}
 	}
 
-	tis, err := getIntervals(request.Condition)
+	tis, limited, err := getIntervals(request)
+	if err != nil {
+		return queryPlan{}, err
+	}
+	if limited && len(tis) == 0 {
+		return queryPlan{}, nil
+	}
+	qp.limited = limited
+	qp.tis, qp.tf = l.pruneIntervals(request.LogID, tis)
+	if qp.rf, err = getRecFilter(request); err != nil {
+		return queryPlan{}, err
+	}
+	return qp, nil
+}
+
+// CountRecords count total number for records in the log and number of records after (before)
+// specified record ID which match the request condition. Returned values are (total, count, exact, error).
+func (l *localLog) CountRecords(ctx context.Context, request storage.QueryRecordsRequest) (uint64, uint64, bool, error) {
+	defer metrics.ObserveSince(metrics.CountDuration, time.Now())
+	for i := 0; ; i++ {
+		total, count, exact, err := l.countLogRecords(ctx, request)
+		if !errors.Is(err, errChunkReplaced) || i >= cReplacedRetries {
+			return total, count, exact, err
+		}
+		l.logger.Debugf("repeating the count for logID=%s: %v", request.LogID, err)
+	}
+}
+
+func (l *localLog) countLogRecords(ctx context.Context, request storage.QueryRecordsRequest) (uint64, uint64, bool, error) {
+	lid := request.LogID
+
+	// the l.limiter doesn't allow to read more than N logs at a time, so the l.limiter.GetOrCreate(ctx, lid)
+	// will be blocked if number of the logs read (not the number of requests!) exceeds the maximum (N) capacity.
+	// The reads don't lock the log, only the log modifications are serialized by the l.logLocks.
+	ll, err := l.limiter.GetOrCreate(ctx, lid)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("could not obtain the log locker for id=%s: %w", lid, err)
+	}
+	defer l.limiter.Release(&ll)
+
+	qp, err := l.planQuery(ctx, request)
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, false, err
 	}
-	if len(request.Condition) > 0 && len(tis) == 0 {
-		return 0, 0, nil
+	if len(qp.cis) == 0 {
+		return 0, 0, true, nil
 	}
 
 	var total uint64
 	var count uint64
+	// scanned and matched are the numbers of the records in the scanned chunks and the records matched
+	// the request there, they are used to estimate the counts of the chunks not scanned after the deadline
+	var scanned uint64
+	var matched uint64
+	var expired bool
+	exact := true
 
-	for idx := initIdx; idx >= 0 && idx < len(cis); idx += inc {
-		ci := cis[idx]
+	for _, ci := range qp.cis {
 		total += uint64(ci.RecordsCount)
-		if (request.Descending && idx <= fromIdx) || (!request.Descending && idx >= fromIdx) {
-			idRanges := getRanges(tis, ci)
-			if len(request.Condition) > 0 && len(idRanges) == 0 {
-				continue
-			}
-			recCnt := uint64(ci.RecordsCount)
-			if sid.Compare(ulidutils.ZeroULID) != 0 || len(idRanges) > 0 {
-				recCnt, err = l.countRecords(ctx, ci, request.Descending, considerSIDAndDesc(idRanges, sid, request.Descending))
+	}
+	for idx := qp.fromIdx; idx >= 0 && idx < len(qp.cis); idx += qp.inc {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, false, err
+		}
+		ci := qp.cis[idx]
+		idRanges := getRanges(qp.tis, ci)
+		if qp.limited && len(idRanges) == 0 {
+			continue
+		}
+		recCnt := uint64(ci.RecordsCount)
+		// the chunks, which are fully inside the requested range, are counted by their RecordsCount
+		if qp.sid.Compare(ulidutils.ZeroULID) != 0 || !request.PayloadLen.IsAny() || qp.tf != nil || qp.rf != nil ||
+			(len(idRanges) > 0 && !coversChunk(idRanges, ci)) {
+			if expired {
+				recCnt = estimateCount(recCnt, scanned, matched)
+				exact = false
+			} else {
+				recCnt, err = l.countRecords(ctx, lid, ci, request.Descending, considerSIDAndDesc(idRanges, qp.sid, request.Descending), request.PayloadLen, qp.tf, qp.rf)
 				if err != nil {
-					return 0, 0, nil
+					if errors.Is(err, errors.ErrNotExist) && !l.hasChunk(ctx, lid, ci.ID) {
+						return 0, 0, false, fmt.Errorf("the chunk %s is removed from logID=%s: %w", ci.ID, lid, errChunkReplaced)
+					}
+					return 0, 0, false, err
 				}
+				scanned += uint64(ci.RecordsCount)
+				matched += recCnt
+				expired = !request.CountDeadline.IsZero() && time.Now().After(request.CountDeadline)
 			}
-			count += recCnt
-			sid = ulidutils.ZeroULID
 		}
+		count += recCnt
+		qp.sid = ulidutils.ZeroULID
+	}
+	if !exact {
+		l.logger.Debugf("the count of logID=%s is estimated after %d records scanned", lid, scanned)
 	}
 
-	return total, count, nil
+	return total, count, exact, nil
 }
 
-func (l *localLog) readRecords(
-	ctx context.Context,
-	lid string,
-	ci ChunkInfo,
-	desc bool,
-	idRanges []idRange,
-	limit int,
-	totalSize *int) ([]*solaris.Record, error) {
-	rc, err := l.ChnkProvider.GetOpenedChunk(ctx, ci.ID, false)
+// coversChunk returns true if the ascending ID ranges select all the chunk records
+func coversChunk(irs []idRange, ci ChunkInfo) bool {
+	return len(irs) == 1 && irs[0].start.Compare(ci.Min) <= 0 && irs[0].end.Compare(ci.Max) >= 0
+}
+
+// estimateCount returns the estimated number of the records matched the request out of the total
+// chunk records, considering the share of the matched records among the scanned ones
+func estimateCount(total, scanned, matched uint64) uint64 {
+	if scanned == 0 {
+		return total
+	}
+	return uint64(math.Round(float64(total) * float64(matched) / float64(scanned)))
+}
+
+// GetRecordByID returns the record by its ID. Only the chunk which IDs range contains the record ID
+// is read, and the record is looked up in the chunk by the ID directly.
+func (l *localLog) GetRecordByID(ctx context.Context, logID, recordID string) (*solaris.Record, error) {
+	var id ulid.ULID
+	if err := id.UnmarshalText(cast.StringToByteArray(recordID)); err != nil {
+		return nil, fmt.Errorf("wrong record ID=%q: %w", recordID, errors.ErrInvalid)
+	}
+
+	ll, err := l.limiter.GetOrCreate(ctx, logID)
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain the log locker for id=%s: %w", logID, err)
+	}
+	defer l.limiter.Release(&ll)
+
+	cis, err := l.getChunks(ctx, logID)
 	if err != nil {
 		return nil, err
 	}
-	defer l.ChnkProvider.ReleaseChunk(&rc)
+	idx := sort.Search(len(cis), func(i int) bool {
+		return cis[i].Max.Compare(id) >= 0
+	})
+	if idx == len(cis) || cis[idx].Min.Compare(id) > 0 {
+		return nil, fmt.Errorf("the record ID=%s is not found in the log ID=%s: %w", recordID, logID, errors.ErrNotExist)
+	}
 
-	cr, err := rc.Value().OpenChunkReader(desc)
+	totalSize := 0
+	recs, err := l.readRecords(ctx, logID, cis[idx], false, []idRange{{start: id, end: id}}, storage.PayloadLenRange{}, nil, nil, new(int64), 1, &totalSize)
 	if err != nil {
 		return nil, err
 	}
-	defer cr.Close()
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("the record ID=%s is not found in the log ID=%s: %w", recordID, logID, errors.ErrNotExist)
+	}
+	return recs[0], nil
+}
 
-	var res []*solaris.Record
-	for _, ir := range idRanges {
-		if ir.start.Compare(ulidutils.ZeroULID) != 0 {
-			cr.SetStartID(ir.start)
+// HasRecords implements storage.LogsActivity. The first and the last records of every chunk are known from the
+// chunk metadata, so the chunk is read only if the window is between two records of the chunk.
+func (l *localLog) HasRecords(ctx context.Context, logID string, after, before time.Time) (bool, error) {
+	qr := storage.QueryRecordsRequest{LogID: logID, Limit: 1, CreatedAfter: after, CreatedBefore: before}
+	cis, err := l.getChunks(ctx, logID)
+	if err != nil {
+		return false, err
+	}
+	from, to := storage.QueryLogsRequest{CreatedAfter: after, CreatedBefore: before}.IDRange()
+	inside := false
+	for _, ci := range cis {
+		if ci.RecordsCount == 0 {
+			continue
 		}
-		for cr.HasNext() && len(res) < limit && *totalSize < l.cfg.MaxBunchSize {
-			ur, _ := cr.Next()
-			if ir.end.Compare(ulidutils.ZeroULID) != 0 &&
-				((desc && ur.ID.Compare(ir.end) < 0) || (!desc && ur.ID.Compare(ir.end) > 0)) {
-				break
-			}
-			r := new(solaris.Record)
-			r.ID = ur.ID.String()
-			r.LogID = lid
-			r.Payload = make([]byte, len(ur.UnsafePayload))
-			copy(r.Payload, ur.UnsafePayload)
-			r.CreatedAt = timestamppb.New(ulid.Time(ur.ID.Time()))
-			*totalSize += len(ur.UnsafePayload)
-			res = append(res, r)
+		minID, maxID := ci.Min.String(), ci.Max.String()
+		if qr.InWindow(minID) || qr.InWindow(maxID) {
+			return true, nil
 		}
+		inside = inside || (from != "" && to != "" && minID < from && maxID > to)
+	}
+	if !inside {
+		return false, nil
 	}
-	return res, nil
+	l.logger.Debugf("the window is inside a chunk of logID=%s, reading the chunk records", logID)
+	recs, _, err := l.QueryRecords(ctx, qr)
+	return len(recs) > 0, err
 }
 
-func (l *localLog) countRecords(ctx context.Context,
-	ci ChunkInfo,
-	desc bool,
-	idRanges []idRange) (uint64, error) {
-
-	rc, err := l.ChnkProvider.GetOpenedChunk(ctx, ci.ID, false)
+// TruncateRecords removes the chunks with all the records created before the time provided. A chunk which
+// contains records created both before and after the time is kept intact, so the chunks payloads are never
+// rewritten. The removed chunks files are deleted when the requests reading them release them. The last chunk
+// of the log with numbered records is never removed, cause the log sequence is continued from it.
+func (l *localLog) TruncateRecords(ctx context.Context, logID string, before time.Time) (int64, error) {
+	ll, err := l.logLocks.acquire(logID)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("could not obtain the log locker for id=%s: %w", logID, err)
 	}
-	defer l.ChnkProvider.ReleaseChunk(&rc)
+	defer l.logLocks.release(logID)
 
-	cr, err := rc.Value().OpenChunkReader(desc)
-	if err != nil {
+	removed, cIDs, err := l.truncateChunks(ctx, ll, logID, before)
+	if err != nil || len(cIDs) == 0 {
 		return 0, err
 	}
-	defer cr.Close()
+	// the files are deleted without the lock, cause the deletion waits for the chunks readers
+	for _, cID := range cIDs {
+		if _, err := l.ChnkProvider.DeleteChunk(ctx, cID); err != nil {
+			l.logger.Warnf("could not delete the truncated chunk %s of logID=%s: %v", cID, logID, err)
+		}
+	}
+	l.logger.Infof("%d records in %d chunks were truncated in logID=%s before %s", removed, len(cIDs), logID, before)
+	return removed, nil
+}
 
-	var count uint64
-	for _, ir := range idRanges {
-		if ir.start.Compare(ulidutils.ZeroULID) != 0 {
-			cr.SetStartID(ir.start)
+// truncateChunks removes the log chunks with all the records created before the time provided from the
+// meta-storage. It returns the number of records removed and the IDs of the removed chunks.
+func (l *localLog) truncateChunks(ctx context.Context, ll *logLocker, lid string, before time.Time) (int64, []string, error) {
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+
+	cis, err := l.getChunks(ctx, lid)
+	if err != nil {
+		return 0, nil, err
+	}
+	var removed int64
+	var cIDs []string
+	var last ChunkInfo
+	for i, ci := range cis {
+		if i == len(cis)-1 && ci.FirstSeq > 0 {
+			break
 		}
-		for cr.HasNext() {
-			ur, _ := cr.Next()
-			if ir.end.Compare(ulidutils.ZeroULID) != 0 &&
-				((desc && ur.ID.Compare(ir.end) < 0) || (!desc && ur.ID.Compare(ir.end) > 0)) {
-				break
-			}
-			count++
+		if ci.RecordsCount > 0 && ulid.Time(ci.Max.Time()).Before(before) {
+			cIDs = append(cIDs, ci.ID)
+			removed += int64(ci.RecordsCount)
+			last = ci
 		}
 	}
-	return count, nil
+	if len(cIDs) == 0 {
+		return 0, nil, nil
+	}
+	l.chunkCounts.Remove(lid)
+	if err := l.LMStorage.DeleteChunkInfos(ctx, lid, cIDs); err != nil {
+		return 0, nil, err
+	}
+	// the chunks are removed from the beginning of the log, so the records before the last removed one are gone
+	l.deleteRecordHashes(ctx, lid, "", last.Max.String())
+	return removed, cIDs, nil
 }
 
-func getIntervals(cond string) ([]intervals.Interval[time.Time], error) {
-	if len(strings.TrimSpace(cond)) == 0 {
-		return nil, nil
+// TrimRecords removes the oldest log records, so the log keeps the last maxRecords records only. The chunks
+// containing the removed records only are removed from the log, and the chunk containing both the removed and
+// the kept records is replaced by the new one with the kept records, if the log keeps more than slack extra
+// records otherwise. So the positive slack allows to trim the log by whole chunks mostly, without rewriting the
+// chunk on every call. The removed chunks files are deleted when the requests reading them release them.
+// The function returns the number of records removed.
+func (l *localLog) TrimRecords(ctx context.Context, logID string, maxRecords, slack int64) (int64, error) {
+	if maxRecords <= 0 {
+		return 0, nil
 	}
-	expr, err := ql.Parse(cond)
+	ll, err := l.logLocks.acquire(logID)
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("could not obtain the log locker for id=%s: %w", logID, err)
 	}
-	tis, err := tiBuilder.Build(expr)
+	defer l.logLocks.release(logID)
+
+	removed, cIDs, err := l.trimChunks(ctx, ll, logID, maxRecords, slack)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	return tis, nil
-}
-
-func getRanges(tis []intervals.Interval[time.Time], ci ChunkInfo) []idRange {
-	cti := tiBasis.Closed(ulid.Time(ci.Min.Time()), ulid.Time(ci.Max.Time()))
-	var ranges []idRange
-	for _, ti := range tis {
-		if ri, ok := tiBasis.Intersect(cti, ti); ok {
-			ranges = append(ranges, toRange(ri))
+	// the files are deleted without the lock, cause the deletion waits for the chunks readers
+	for _, cID := range cIDs {
+		if _, err := l.ChnkProvider.DeleteChunk(ctx, cID); err != nil {
+			l.logger.Warnf("could not delete the trimmed chunk %s of logID=%s: %v", cID, logID, err)
 		}
 	}
-	return ranges
-}
-
-func toRange(ti intervals.Interval[time.Time]) idRange {
-	if ti.IsClosed() {
-		return idRange{start: minULIDForTime(ti.L), end: maxULIDForTime(ti.R)}
-	} else if ti.IsOpenL() {
-		return idRange{start: minULIDForTime(ti.L.Add(time.Millisecond)), end: maxULIDForTime(ti.R)}
-	} else if ti.IsOpenR() {
-		return idRange{start: minULIDForTime(ti.L), end: maxULIDForTime(ti.R.Add(-time.Millisecond))}
-	} else { // open
-		return idRange{start: minULIDForTime(ti.L.Add(time.Millisecond)), end: maxULIDForTime(ti.R.Add(-time.Millisecond))}
+	if removed > 0 {
+		l.logger.Debugf("%d records in %d chunks were trimmed in logID=%s to keep %d records", removed, len(cIDs), logID, maxRecords)
 	}
+	return removed, nil
 }
 
-func minULIDForTime(t time.Time) ulid.ULID {
-	var id ulid.ULID
-	_ = id.SetTime(uint64(t.UnixMilli()))
-	return id
-}
+// trimChunks removes the log chunks with the records over the maxRecords (see TrimRecords) from the meta-storage.
+// It returns the number of records removed and the IDs of the removed chunks.
+func (l *localLog) trimChunks(ctx context.Context, ll *logLocker, lid string, maxRecords, slack int64) (int64, []string, error) {
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
 
-func maxULIDForTime(t time.Time) ulid.ULID {
-	maxBytes := make([]byte, 10)
-	for i := 0; i < len(maxBytes); i++ {
-		maxBytes[i] = 0xff
+	cis, err := l.getChunks(ctx, lid)
+	if err != nil {
+		return 0, nil, err
+	}
+	extra := -maxRecords
+	for _, ci := range cis {
+		extra += int64(ci.RecordsCount)
+	}
+	if extra <= 0 {
+		return 0, nil, nil
 	}
-	var id ulid.ULID
-	_ = id.SetTime(uint64(t.UnixMilli()))
-	_ = id.SetEntropy(maxBytes)
-	return id
-}
 
-func considerSIDAndDesc(irs []idRange, sid ulid.ULID, desc bool) []idRange {
-	if len(irs) == 0 {
-		return []idRange{{start: sid}}
+	var cIDs []string
+	var removed int64
+	i := 0
+	// the last chunk is never removed, cause the appends and the log sequence are continued from it
+	for ; i < len(cis)-1 && removed+int64(cis[i].RecordsCount) <= extra; i++ {
+		cIDs = append(cIDs, cis[i].ID)
+		removed += int64(cis[i].RecordsCount)
 	}
-	if sid.Compare(ulidutils.ZeroULID) == 0 {
-		if desc {
-			return reverseRanges(irs)
+
+	var res []ChunkInfo
+	if skip := int(extra - removed); skip > 0 && int64(skip) > slack {
+		nextID := ChunkMaxID
+		if i < len(cis)-1 {
+			nextID = cis[i+1].ID
+		}
+		// the chunks before are removed, so the new chunk ID is not limited from below
+		ci, err := l.rewriteChunk(ctx, lid, cis[i], skip, ChunkMinID, nextID)
+		if err != nil {
+			l.logger.Warnf("could not rewrite the chunk %s of logID=%s without %d oldest records: %v", cis[i].ID, lid, skip, err)
+		} else {
+			res = append(res, ci)
+			cIDs = append(cIDs, cis[i].ID)
+			removed += int64(skip)
 		}
-		return irs
 	}
-	if desc {
+	if len(cIDs) == 0 {
+		return 0, nil, nil
+	}
+
+	if err := l.swapChunks(ctx, lid, cIDs, res); err != nil {
+		if derr := l.discardChunks(ctx, lid, res, make([]int, len(res))); derr != nil {
+			l.logger.Warnf("could not discard the rewritten chunk of logID=%s: %v", lid, derr)
+		}
+		return 0, nil, err
+	}
+	if len(res) > 0 && i < len(cis)-1 {
+		l.ChnkProvider.Replicator.ChunkSealed(res[0].ID)
+	}
+	// the records before the first kept one are removed
+	if len(res) > 0 {
+		l.deleteRecordHashes(ctx, lid, "", ulidutils.PrevID(res[0].Min.String()))
+	} else {
+		l.deleteRecordHashes(ctx, lid, "", cis[i-1].Max.String())
+	}
+	return removed, cIDs, nil
+}
+
+// DeleteRecords removes the log records with IDs in the inclusive range [fromID, toID], an empty ID means the
+// range is not limited from the corresponding side. The chunks containing the removed records only are removed
+// from the log, and the chunks containing both the removed and the kept records are replaced by the new ones with
+// the kept records, so the kept records IDs and sequence numbers are not changed. The last record of the log with
+// numbered records is never removed, cause the log sequence is continued from it. The removed chunks files are
+// deleted when the requests reading them release them. The function returns the number of records removed, which
+// is 0 if the range is already deleted.
+func (l *localLog) DeleteRecords(ctx context.Context, logID, fromID, toID string) (int64, error) {
+	from, to := ulidutils.ZeroULID, ulidutils.MaxULID
+	var err error
+	if fromID != "" {
+		if from, err = ulid.Parse(fromID); err != nil {
+			return 0, fmt.Errorf("wrong fromID=%q: %w", fromID, errors.ErrInvalid)
+		}
+	}
+	if toID != "" {
+		if to, err = ulid.Parse(toID); err != nil {
+			return 0, fmt.Errorf("wrong toID=%q: %w", toID, errors.ErrInvalid)
+		}
+	}
+	if from.Compare(to) > 0 {
+		return 0, nil
+	}
+	ll, err := l.logLocks.acquire(logID)
+	if err != nil {
+		return 0, fmt.Errorf("could not obtain the log locker for id=%s: %w", logID, err)
+	}
+	defer l.logLocks.release(logID)
+
+	removed, cIDs, err := l.deleteChunksRecords(ctx, ll, logID, from, to)
+	if err != nil {
+		return 0, err
+	}
+	// the files are deleted without the lock, cause the deletion waits for the chunks readers
+	for _, cID := range cIDs {
+		if _, err := l.ChnkProvider.DeleteChunk(ctx, cID); err != nil {
+			l.logger.Warnf("could not delete the chunk %s of logID=%s with the deleted records: %v", cID, logID, err)
+		}
+	}
+	if removed > 0 {
+		l.logger.Infof("%d records in %d chunks were deleted in logID=%s from %s to %s", removed, len(cIDs), logID, from, to)
+	}
+	return removed, nil
+}
+
+// deleteChunksRecords removes the log records in the [from, to] range and replaces the chunks containing them
+// in the meta-storage. It returns the number of records removed and the IDs of the replaced chunks.
+func (l *localLog) deleteChunksRecords(ctx context.Context, ll *logLocker, lid string, from, to ulid.ULID) (int64, []string, error) {
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+
+	cis, err := l.getChunks(ctx, lid)
+	if err != nil {
+		return 0, nil, err
+	}
+	var removed int64
+	var cIDs []string
+	var res []ChunkInfo
+	// lastCut is true if the last chunk of the log is replaced, so the new last chunk is appended then
+	lastCut := false
+	prevID := ChunkMinID
+	// hto is the last removed record ID, which is less than the to if the last log record is kept
+	hto := to
+	for i, ci := range cis {
+		nextID := ChunkMaxID
+		if i < len(cis)-1 {
+			nextID = cis[i+1].ID
+		}
+		cto := to
+		if i == len(cis)-1 && ci.FirstSeq > 0 && ci.Max.Compare(cto) <= 0 {
+			cto = skipID(ci.Max, true)
+			hto = cto
+		}
+		if ci.RecordsCount == 0 || ci.Max.Compare(from) < 0 || ci.Min.Compare(cto) > 0 {
+			prevID = ci.ID
+			continue
+		}
+		if ci.Min.Compare(from) >= 0 && ci.Max.Compare(cto) <= 0 {
+			cIDs = append(cIDs, ci.ID)
+			removed += int64(ci.RecordsCount)
+			continue
+		}
+		ncis, n, err := l.cutChunk(ctx, lid, ci, from, cto, prevID, nextID)
+		if err != nil {
+			if derr := l.discardChunks(ctx, lid, res, make([]int, len(res))); derr != nil {
+				l.logger.Warnf("could not discard the rewritten chunks of logID=%s: %v", lid, derr)
+			}
+			return 0, nil, fmt.Errorf("could not rewrite the chunk %s without the deleted records: %w", ci.ID, err)
+		}
+		if n == 0 {
+			prevID = ci.ID
+			continue
+		}
+		res = append(res, ncis...)
+		cIDs = append(cIDs, ci.ID)
+		removed += n
+		prevID = ncis[len(ncis)-1].ID
+		lastCut = i == len(cis)-1
+	}
+	if len(cIDs) == 0 {
+		return 0, nil, nil
+	}
+
+	if err := l.swapChunks(ctx, lid, cIDs, res); err != nil {
+		if derr := l.discardChunks(ctx, lid, res, make([]int, len(res))); derr != nil {
+			l.logger.Warnf("could not discard the rewritten chunks of logID=%s: %v", lid, derr)
+		}
+		return 0, nil, err
+	}
+	for i, ci := range res {
+		if i < len(res)-1 || !lastCut {
+			l.ChnkProvider.Replicator.ChunkSealed(ci.ID)
+		}
+	}
+	l.deleteRecordHashes(ctx, lid, from.String(), hto.String())
+	return removed, cIDs, nil
+}
+
+// cutChunk copies the ci chunk records, except the ones in the [from, to] range, into the new chunks with the
+// records before and after the range, which IDs are between the prevID and the nextID. It returns the new chunks
+// infos and the number of records skipped, the chunks are not created if no records are skipped.
+func (l *localLog) cutChunk(ctx context.Context, lid string, ci ChunkInfo, from, to ulid.ULID, prevID, nextID string) ([]ChunkInfo, int64, error) {
+	recs, err := l.chunkRecords(ctx, ci)
+	if err != nil {
+		return nil, 0, err
+	}
+	fromID, toID := from.String(), to.String()
+	start := sort.Search(len(recs), func(i int) bool {
+		return recs[i].ID >= fromID
+	})
+	end := sort.Search(len(recs), func(i int) bool {
+		return recs[i].ID > toID
+	})
+	if start >= end {
+		return nil, 0, nil
+	}
+
+	var res []ChunkInfo
+	for _, r := range []idxRange{{start: 0, end: start}, {start: end, end: len(recs)}} {
+		if r.start == r.end {
+			continue
+		}
+		var seq int64
+		if ci.FirstSeq > 0 {
+			seq = ci.FirstSeq + int64(r.start)
+		}
+		nci, err := l.copyChunk(ctx, lid, ci, recs[r.start:r.end], seq, prevID, nextID)
+		if err != nil {
+			if derr := l.discardChunks(ctx, lid, res, make([]int, len(res))); derr != nil {
+				l.logger.Warnf("could not discard the new chunks of logID=%s: %v", lid, derr)
+			}
+			return nil, 0, err
+		}
+		res = append(res, nci)
+		prevID = nci.ID
+	}
+	return res, int64(end - start), nil
+}
+
+// rewriteChunk copies the ci chunk records, except the skip first ones, into the new chunk, which ID is between
+// the prevID and the nextID. It returns the new chunk info.
+func (l *localLog) rewriteChunk(ctx context.Context, lid string, ci ChunkInfo, skip int, prevID, nextID string) (ChunkInfo, error) {
+	recs, err := l.chunkRecords(ctx, ci)
+	if err != nil {
+		return ChunkInfo{}, err
+	}
+	if skip >= len(recs) {
+		return ChunkInfo{}, fmt.Errorf("the chunk has %d records only: %w", len(recs), errors.ErrInternal)
+	}
+	var seq int64
+	if ci.FirstSeq > 0 {
+		seq = ci.FirstSeq + int64(skip)
+	}
+	return l.copyChunk(ctx, lid, ci, recs[skip:], seq, prevID, nextID)
+}
+
+// copyChunk copies the ci chunk records recs into the new chunk, which ID is between the prevID and the nextID,
+// and which records are numbered from the firstSeq. It returns the new chunk info.
+func (l *localLog) copyChunk(ctx context.Context, lid string, ci ChunkInfo, recs []*solaris.Record, firstSeq int64, prevID, nextID string) (ChunkInfo, error) {
+	nci := ChunkInfo{ID: ulidutils.PrevID(recs[0].ID), KeyID: ci.KeyID, FirstSeq: firstSeq}
+	if nci.ID == ci.ID {
+		nci.ID = ulidutils.PrevID(nci.ID)
+	}
+	if nci.ID <= prevID || nci.ID >= nextID {
+		return ChunkInfo{}, fmt.Errorf("the new chunk ID=%s breaks the chunks order: %w", nci.ID, errors.ErrConflict)
+	}
+	arr, err := l.copyRecords(ctx, nci.ID, true, recs)
+	if err == nil && arr.Written < len(recs) {
+		err = fmt.Errorf("only %d records of %d are copied: %w", arr.Written, len(recs), errors.ErrExhausted)
+	}
+	if err != nil {
+		if derr := l.discardChunks(ctx, lid, []ChunkInfo{nci}, []int{0}); derr != nil {
+			l.logger.Warnf("could not discard the new chunk %s of logID=%s: %v", nci.ID, lid, derr)
+		}
+		return ChunkInfo{}, err
+	}
+	nci.Min = arr.StartID
+	nci.Max = arr.LastID
+	nci.RecordsCount = arr.Written
+	return nci, nil
+}
+
+// CompactLog merges the adjacent sealed chunks of the log, which total size is less than Config.MaxChunkSize,
+// into the new chunks, keeping the records order, IDs and sequence numbers. The sealed chunks are not written
+// anymore, so the records are copied without holding the log lock. The lock is taken only to replace the chunks
+// in the meta-storage, so the appends are not blocked by the compaction. If the merged chunks are changed by
+// another operation (TruncateRecords, for example) in the meantime, the merge is discarded. The requests, which
+// read the merged chunks at the time, are not broken, the chunks files are deleted when the chunks are released.
+// One call merges not more than Config.CompactMaxChunks chunks, so the log could be compacted incrementally.
+// The function returns the number of chunks removed from the log.
+func (l *localLog) CompactLog(ctx context.Context, logID string) (int, error) {
+	if l.cfg.MaxChunkSize <= 0 {
+		return 0, nil
+	}
+	ll, err := l.logLocks.acquire(logID)
+	if err != nil {
+		return 0, fmt.Errorf("could not obtain the log locker for id=%s: %w", logID, err)
+	}
+	defer l.logLocks.release(logID)
+
+	cis, err := l.getChunks(ctx, logID)
+	if err != nil {
+		return 0, err
+	}
+	sizes := make([]int64, len(cis))
+	for i, ci := range cis {
+		// the chunks not available locally are not compacted
+		sizes[i] = -1
+		if fi, err := os.Stat(l.ChnkProvider.GetFileNameByID(ci.ID)); err == nil {
+			sizes[i] = fi.Size()
+		}
+	}
+	removed := 0
+	for _, r := range sizeCompactionRuns(cis, sizes, l.cfg.MaxChunkSize, l.cfg.CompactMaxChunks) {
+		n, err := l.compactChunks(ctx, ll, logID, cis, r, true)
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	if removed > 0 {
+		l.logger.Infof("%d chunks were removed by the compaction of logID=%s", removed, logID)
+	}
+	return removed, nil
+}
+
+// RepairChunk truncates the log chunk with the cID at the last good record, if the chunk is corrupted (see
+// chunkfs.Provider.RepairChunk), and updates the chunk RecordsCount and Max in the meta-storage. The log is locked
+// while the chunk is repaired, so the log appends wait, and the chunk readers are waited by the chunk truncation.
+// The function returns the report of the chunk scan made before the truncation.
+func (l *localLog) RepairChunk(ctx context.Context, logID, cID string) (chunkfs.ScanReport, error) {
+	ll, err := l.logLocks.acquire(logID)
+	if err != nil {
+		return chunkfs.ScanReport{}, fmt.Errorf("could not obtain the log locker for id=%s: %w", logID, err)
+	}
+	defer l.logLocks.release(logID)
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+
+	cis, err := l.getChunks(ctx, logID)
+	if err != nil {
+		return chunkfs.ScanReport{}, err
+	}
+	idx := slices.IndexFunc(cis, func(ci ChunkInfo) bool { return ci.ID == cID })
+	if idx < 0 {
+		return chunkfs.ScanReport{}, fmt.Errorf("the chunk id=%s is not found in the log ID=%s: %w", cID, logID, errors.ErrNotExist)
+	}
+	sr, err := l.ChnkProvider.RepairChunk(ctx, cID)
+	if err != nil || !sr.Corrupted() {
+		return sr, err
+	}
+	ci := cis[idx]
+	ci.RecordsCount = sr.Recoverable
+	ci.Max = sr.LastID
+	if sr.Recoverable == 0 {
+		// the chunk stays in the log, so its IDs range is kept in the place for the chunks order
+		ci.Max = ci.Min
+	}
+	// the chunk is truncated already, so the meta-storage must be updated even if the request is cancelled
+	if err := l.LMStorage.UpsertChunkInfos(context.WithoutCancel(ctx), logID, []ChunkInfo{ci}); err != nil {
+		return sr, err
+	}
+	l.logger.Warnf("the chunk id=%s of logID=%s is repaired, %d of %d records are kept", cID, logID, sr.Recoverable, sr.Total)
+	return sr, nil
+}
+
+// compactInBackground runs CompactLog for the log in a separate goroutine, if the log is not compacted already
+func (l *localLog) compactInBackground(lid string) {
+	if l.cfg.MaxChunkSize <= 0 {
+		return
+	}
+	if _, ok := l.compacting.LoadOrStore(lid, struct{}{}); ok {
+		return
+	}
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer l.compacting.Delete(lid)
+		if _, err := l.CompactLog(l.ctx, lid); err != nil {
+			l.logger.Warnf("could not compact logID=%s: %v", lid, err)
+		}
+	}()
+}
+
+// migrateLog rewrites the sealed log chunks written in an outdated format (see chunkfs.Chunk.Outdated) into
+// the new chunks of the current format, keeping the records IDs and sequence numbers. The last log chunk could
+// be written, so it is never migrated. The chunks stored remotely only are not downloaded to be checked, they are
+// migrated after they are read into the local storage. The chunks are rewritten without holding the log lock and
+// replaced in the meta-storage the same way the compacted chunks are, so the log may be read and written while
+// it is migrated. The throttle is called before every chunk is rewritten. The function returns the number of
+// chunks migrated.
+func (l *localLog) migrateLog(ctx context.Context, lid string, throttle func(ctx context.Context) error) (int, error) {
+	ll, err := l.logLocks.acquire(lid)
+	if err != nil {
+		return 0, fmt.Errorf("could not obtain the log locker for id=%s: %w", lid, err)
+	}
+	defer l.logLocks.release(lid)
+
+	cis, err := l.getChunks(ctx, lid)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	prevID := ChunkMinID
+	for i := 0; i < len(cis)-1; i++ {
+		ci := cis[i]
+		outdated, err := l.isOutdated(ci.ID)
+		if errors.Is(err, errors.ErrNotExist) {
+			l.logger.Debugf("the chunk %s of logID=%s is not stored locally, skipping it", ci.ID, lid)
+			outdated, err = false, nil
+		}
+		if err != nil {
+			return migrated, err
+		}
+		if !outdated {
+			prevID = ci.ID
+			continue
+		}
+		if err := throttle(ctx); err != nil {
+			return migrated, err
+		}
+		nci, err := l.rewriteChunk(ctx, lid, ci, 0, prevID, cis[i+1].ID)
+		if err != nil {
+			return migrated, fmt.Errorf("could not rewrite the chunk %s: %w", ci.ID, err)
+		}
+		ok, err := l.replaceChunks(ctx, ll, lid, []ChunkInfo{ci}, []ChunkInfo{nci})
+		if err != nil || !ok {
+			if derr := l.discardChunks(ctx, lid, []ChunkInfo{nci}, []int{0}); derr != nil {
+				l.logger.Warnf("could not discard the migrated chunk %s of logID=%s: %v", nci.ID, lid, derr)
+			}
+			// the log chunks are changed, so the rest of them will be migrated next time
+			return migrated, err
+		}
+		l.ChnkProvider.Replicator.ChunkSealed(nci.ID)
+		if _, err := l.ChnkProvider.DeleteChunk(ctx, ci.ID); err != nil {
+			l.logger.Warnf("could not delete the migrated chunk %s of logID=%s: %v", ci.ID, lid, err)
+		}
+		prevID = nci.ID
+		migrated++
+	}
+	if migrated > 0 {
+		l.logger.Infof("%d chunks of logID=%s were migrated to the current format", migrated, lid)
+	}
+	return migrated, nil
+}
+
+// hasChunk returns true if the chunk is still in the log. It returns true, if the log chunks could not be read,
+// so the original error is reported then.
+func (l *localLog) hasChunk(ctx context.Context, lid, cID string) bool {
+	cis, err := l.getChunks(ctx, lid)
+	if err != nil {
+		return true
+	}
+	return slices.ContainsFunc(cis, func(ci ChunkInfo) bool {
+		return ci.ID == cID
+	})
+}
+
+// isOutdated returns true if the chunk is written in an outdated format. The format is read from the local chunk
+// file header, so the chunk is not opened and the chunks stored remotely only are not downloaded (errors.ErrNotExist
+// is returned for them, see chunkfs.Provider.Outdated).
+func (l *localLog) isOutdated(cID string) (bool, error) {
+	return l.ChnkProvider.Outdated(cID)
+}
+
+// compactChunks merges the cis[r.start:r.end] chunks into the new ones and replaces them in the meta-storage.
+// If deleteSrc is true, the merged chunks files are deleted then. It returns the number of chunks removed from
+// the log.
+func (l *localLog) compactChunks(ctx context.Context, ll *logLocker, lid string, cis []ChunkInfo, r idxRange, deleteSrc bool) (int, error) {
+	src := cis[r.start:r.end]
+	// the new chunks IDs must keep the chunks order, so they are between the neighbour chunks IDs
+	prevID := ""
+	if r.start > 0 {
+		prevID = cis[r.start-1].ID
+	}
+	nextID := cis[r.end].ID
+
+	var res []ChunkInfo
+	var prevCounts []int
+	ci := ChunkInfo{}
+	seq := src[0].FirstSeq
+	var gerr error
+	for _, sci := range src {
+		recs, err := l.chunkRecords(ctx, sci)
+		if err != nil {
+			gerr = err
+			break
+		}
+		for len(recs) > 0 && gerr == nil {
+			if ci.RecordsCount == 0 {
+				ci = ChunkInfo{ID: ulidutils.PrevID(recs[0].ID), KeyID: sci.KeyID, FirstSeq: seq}
+				if ci.ID <= prevID || ci.ID >= nextID || ci.ID == sci.ID {
+					l.logger.Warnf("could not compact chunks of logID=%s, the new chunk ID=%s breaks the chunks order", lid, ci.ID)
+					break
+				}
+				prevCounts = append(prevCounts, 0)
+				res = append(res, ci)
+			}
+			arr, err := l.copyRecords(ctx, ci.ID, ci.RecordsCount == 0, recs)
+			if err != nil {
+				gerr = err
+				break
+			}
+			if arr.Written == 0 {
+				if ci.RecordsCount == 0 {
+					gerr = fmt.Errorf("could not copy the record %s into the new chunk %s: %w", recs[0].ID, ci.ID, errors.ErrInternal)
+				}
+				prevID = ci.ID
+				ci.RecordsCount = 0
+				continue
+			}
+			if ci.RecordsCount == 0 {
+				ci.Min = arr.StartID
+			}
+			ci.Max = arr.LastID
+			ci.RecordsCount += arr.Written
+			res[len(res)-1] = ci
+			recs = recs[arr.Written:]
+			if seq > 0 {
+				seq += int64(arr.Written)
+			}
+		}
+		if gerr != nil {
+			break
+		}
+		if len(recs) > 0 {
+			// the chunks order is broken
+			return 0, l.discardChunks(ctx, lid, res, prevCounts)
+		}
+	}
+	if gerr != nil || len(res) >= len(src) {
+		// the error or no chunks would be removed
+		if err := l.discardChunks(ctx, lid, res, prevCounts); err != nil {
+			return 0, err
+		}
+		return 0, gerr
+	}
+
+	ok, err := l.replaceChunks(ctx, ll, lid, src, res)
+	if err != nil || !ok {
+		if derr := l.discardChunks(ctx, lid, res, prevCounts); derr != nil {
+			l.logger.Warnf("could not discard the compacted chunks of logID=%s: %v", lid, derr)
+		}
+		return 0, err
+	}
+	for _, ci := range res {
+		l.ChnkProvider.Replicator.ChunkSealed(ci.ID)
+	}
+	if deleteSrc {
+		for _, ci := range src {
+			// the chunk is not in the log anymore, so the file is just left on the disk if it cannot be deleted
+			if _, err := l.ChnkProvider.DeleteChunk(ctx, ci.ID); err != nil {
+				l.logger.Warnf("could not delete the compacted chunk %s of logID=%s: %v", ci.ID, lid, err)
+			}
+		}
+	}
+	return len(src) - len(res), nil
+}
+
+// replaceChunks replaces the src chunks by the res ones in the meta-storage, if the src chunks are still in the log
+// and not changed. It returns false, if the src chunks are changed.
+func (l *localLog) replaceChunks(ctx context.Context, ll *logLocker, lid string, src, res []ChunkInfo) (bool, error) {
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+
+	cis, err := l.getChunks(ctx, lid)
+	if err != nil {
+		return false, err
+	}
+	idx := sort.Search(len(cis), func(i int) bool {
+		return cis[i].ID >= src[0].ID
+	})
+	// the src chunks must be followed by another chunk, so they are not written
+	if idx+len(src) >= len(cis) {
+		l.logger.Infof("the compacted chunks of logID=%s are changed, the compaction is discarded", lid)
+		return false, nil
+	}
+	for i, ci := range src {
+		if cis[idx+i] != ci {
+			l.logger.Infof("the compacted chunk %s of logID=%s is changed, the compaction is discarded", ci.ID, lid)
+			return false, nil
+		}
+	}
+
+	cIDs := make([]string, len(src))
+	for i, ci := range src {
+		cIDs[i] = ci.ID
+	}
+	if err := l.swapChunks(ctx, lid, cIDs, res); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// chunksCount returns the number of the log chunks in the meta-storage. The count is read from the meta-storage
+// once and then kept by the appends, the other changes of the log chunks list drop it (see swapChunks), so the log
+// lock must be held by the caller.
+func (l *localLog) chunksCount(ctx context.Context, lid string) (*int, error) {
+	cnt, err := l.chunkCounts.GetOrCreate(lid)
+	if err != nil {
+		return nil, err
+	}
+	if *cnt < 0 {
+		cis, err := l.getChunks(ctx, lid)
+		if err != nil && !errors.Is(err, errors.ErrNotExist) {
+			return nil, err
+		}
+		*cnt = len(cis)
+	}
+	return cnt, nil
+}
+
+// commitChunks upserts the chunks cis the records are written to. The chunks are committed regardless of the
+// ctx cancellation (see metaContext), so the written records are not left out of the meta-storage.
+func (l *localLog) commitChunks(ctx context.Context, lid string, cis []ChunkInfo) error {
+	cctx, cancel := l.metaContext(ctx)
+	defer cancel()
+	return l.LMStorage.UpsertChunkInfos(cctx, lid, cis)
+}
+
+// swapChunks replaces the chunks cIDs by the res ones in the meta-storage. The chunks are swapped regardless of
+// the ctx cancellation (see metaContext), so the cancelled request doesn't leave the log half-replaced. If the cIDs
+// chunks could not be removed, the res chunks are removed back, so the log records are not duplicated.
+func (l *localLog) swapChunks(ctx context.Context, lid string, cIDs []string, res []ChunkInfo) error {
+	cctx, cancel := l.metaContext(ctx)
+	defer cancel()
+	defer l.chunkCounts.Remove(lid)
+	if len(res) > 0 {
+		if err := l.LMStorage.UpsertChunkInfos(cctx, lid, res); err != nil {
+			return err
+		}
+	}
+	if err := l.LMStorage.DeleteChunkInfos(cctx, lid, cIDs); err != nil {
+		l.logger.Errorf("could not delete the replaced chunks %v of logID=%s: %v", cIDs, lid, err)
+		if len(res) == 0 {
+			return err
+		}
+		rIDs := make([]string, len(res))
+		for i, ci := range res {
+			rIDs[i] = ci.ID
+		}
+		if derr := l.LMStorage.DeleteChunkInfos(cctx, lid, rIDs); derr != nil {
+			// the new chunks are discarded by the caller, so their records are not read twice at least
+			l.logger.Errorf("could not delete the new chunks %v of logID=%s, they duplicate the replaced records: %v", rIDs, lid, derr)
+			return fmt.Errorf("could not swap the chunks %v of logID=%s, the new chunks %v are left: %v: %w", cIDs, lid, rIDs, derr, errors.ErrInternal)
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteRecordHashes removes the removed log records in the inclusive range [fromID, toID] from the payloads
+// hashes index, if it is provided. The records are removed already, so the error is only logged, and the
+// index readers check the records found anyway.
+func (l *localLog) deleteRecordHashes(ctx context.Context, lid, fromID, toID string) {
+	if l.RecordHashes == nil {
+		return
+	}
+	cctx, cancel := l.metaContext(ctx)
+	defer cancel()
+	if err := l.RecordHashes.DeleteRecordHashes(cctx, lid, fromID, toID); err != nil {
+		l.logger.Warnf("could not remove the records [%s, %s] of logID=%s from the payloads hashes index: %v", fromID, toID, lid, err)
+	}
+}
+
+// metaContext returns the context for the meta-storage calls, which follow the chunks files changes. The calls
+// ignore the ctx cancellation, so the files and the meta-storage are not left inconsistent by a cancelled request,
+// and they are bounded by the MetaCommitTimeout instead.
+func (l *localLog) metaContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	cctx := context.WithoutCancel(ctx)
+	if l.cfg.MetaCommitTimeout > 0 {
+		return context.WithTimeout(cctx, l.cfg.MetaCommitTimeout)
+	}
+	return cctx, func() {}
+}
+
+// discardChunks removes the records from the chunks cis, which are not added to the log, see rollbackChunks.
+// The chunks may be discarded under the log lock, so their files are deleted in a separate goroutine.
+func (l *localLog) discardChunks(ctx context.Context, lid string, cis []ChunkInfo, prevCounts []int) error {
+	kept, emptyIDs := l.rollbackChunks(ctx, lid, cis, prevCounts)
+	if len(emptyIDs) > 0 {
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			l.deleteEmptyFiles(l.ctx, emptyIDs)
+		}()
+	}
+	if kept > 0 {
+		return fmt.Errorf("could not discard %d new chunks of logID=%s: %w", kept, lid, errors.ErrInternal)
+	}
+	return nil
+}
+
+// deleteEmptyFiles deletes the files of the chunks cIDs, if they are empty (see chunkfs.Provider.DeleteFileIfEmpty).
+// The deletion waits for the chunks readers, so the function must not be called under the log lock.
+func (l *localLog) deleteEmptyFiles(ctx context.Context, cIDs []string) {
+	for _, cID := range cIDs {
+		l.ChnkProvider.DeleteFileIfEmpty(ctx, cID)
+	}
+}
+
+// chunkRecords returns all the chunk records with the payloads as they are stored, so the encrypted payloads are
+// not decrypted
+func (l *localLog) chunkRecords(ctx context.Context, ci ChunkInfo) ([]*solaris.Record, error) {
+	rc, err := l.getOpenedChunkForRead(ctx, ci.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer l.ChnkProvider.ReleaseChunk(&rc)
+
+	cr, err := rc.Value().OpenChunkReader(false)
+	if err != nil {
+		return nil, err
+	}
+	defer cr.Close()
+
+	res := make([]*solaris.Record, 0, ci.RecordsCount)
+	for cr.HasNext() {
+		ur, ok := cr.Next()
+		if !ok {
+			break
+		}
+		r := &solaris.Record{ID: ur.ID.String(), Payload: make([]byte, len(ur.UnsafePayload)), Attributes: ur.Attributes}
+		copy(r.Payload, ur.UnsafePayload)
+		res = append(res, r)
+	}
+	return res, cr.Err()
+}
+
+type idxRange struct {
+	start, end int
+}
+
+// sizeCompactionRuns returns the ranges of the adjacent sealed chunks, which may be merged into one chunk. The chunks
+// of a range have the total size less than maxSize, the same key and the continuous sequence numbers. The chunks with
+// the negative sizes are not merged. If maxChunks is positive, the ranges contain not more than maxChunks chunks
+// in total.
+func sizeCompactionRuns(cis []ChunkInfo, sizes []int64, maxSize int64, maxChunks int) []idxRange {
+	ok := func(i int) bool {
+		// the last chunk is written by the appends, so it is never compacted
+		return i < len(cis)-1 && cis[i].RecordsCount > 0 && sizes[i] >= 0 && sizes[i] < maxSize
+	}
+	var res []idxRange
+	total := 0
+	for i := 0; i < len(cis) && (maxChunks <= 0 || total < maxChunks-1); {
+		if !ok(i) {
+			i++
+			continue
+		}
+		size := sizes[i]
+		j := i + 1
+		for ok(j) && size+sizes[j] < maxSize && cis[j].KeyID == cis[j-1].KeyID && cis[j].FirstSeq == cis[j-1].nextSeq() &&
+			(maxChunks <= 0 || total+j-i < maxChunks) {
+			size += sizes[j]
+			j++
+		}
+		if j-i > 1 {
+			res = append(res, idxRange{start: i, end: j})
+			total += j - i
+		}
+		i = j
+	}
+	return res
+}
+
+func (l *localLog) readRecords(
+	ctx context.Context,
+	lid string,
+	ci ChunkInfo,
+	desc bool,
+	idRanges []idRange,
+	plr storage.PayloadLenRange,
+	tf tiFilter,
+	rf recFilter,
+	skip *int64,
+	limit int,
+	totalSize *int) ([]*solaris.Record, error) {
+	aead, err := l.chunkAEAD(ctx, lid, ci)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := l.getOpenedChunkForRead(ctx, ci.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer l.ChnkProvider.ReleaseChunk(&rc)
+
+	cr, err := rc.Value().OpenChunkReader(desc)
+	if err != nil {
+		return nil, err
+	}
+	defer cr.Close()
+
+	// every record in the ranges matches, so the skipped records are counted by their IDs (see countRecords)
+	countable := plr.IsAny() && tf == nil && rf == nil
+	var res []*solaris.Record
+	for _, ir := range idRanges {
+		if countable && *skip > 0 {
+			n := cr.CountIDs(ir.start, ir.end)
+			if desc {
+				n = cr.CountIDs(ir.end, ir.start)
+			}
+			if int64(n) <= *skip {
+				*skip -= int64(n)
+				continue
+			}
+		}
+		if ir.start.Compare(ulidutils.ZeroULID) != 0 {
+			cr.SetStartID(ir.start)
+		}
+		if countable && *skip > 0 {
+			*skip -= int64(cr.Skip(int(*skip)))
+		}
+		for cr.HasNext() && len(res) < limit && *totalSize < l.cfg.MaxBunchSize {
+			ur, ok := cr.Next()
+			if !ok {
+				break
+			}
+			if ir.end.Compare(ulidutils.ZeroULID) != 0 &&
+				((desc && ur.ID.Compare(ir.end) < 0) || (!desc && ur.ID.Compare(ir.end) > 0)) {
+				break
+			}
+			ur, ok, err := matchRecord(ur, aead, plr, tf, rf)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if *skip > 0 {
+				*skip--
+				continue
+			}
+			*totalSize += ur.Size
+			res = append(res, newRecord(lid, ci, ur))
+		}
+	}
+	return res, cr.Err()
+}
+
+// chunkAEAD returns the AEAD the chunk records payloads are encrypted with, or nil if they are not encrypted
+func (l *localLog) chunkAEAD(ctx context.Context, lid string, ci ChunkInfo) (cipher.AEAD, error) {
+	if ci.KeyID == "" {
+		return nil, nil
+	}
+	if l.Keyring == nil {
+		return nil, fmt.Errorf("the chunk %s is encrypted, but no keyring is provided: %w", ci.ID, errors.ErrInternal)
+	}
+	key, err := l.Keyring.GetKey(ctx, lid, ci.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the key=%s for the chunk %s: %w", ci.KeyID, ci.ID, err)
+	}
+	return newAEAD(key)
+}
+
+// newRecord returns the record read from the ci chunk and matched by matchRecord
+func newRecord(lid string, ci ChunkInfo, ur chunkfs.UnsafeRecord) *solaris.Record {
+	r := new(solaris.Record)
+	r.ID = ur.ID.String()
+	r.LogID = lid
+	if ci.FirstSeq > 0 {
+		r.Seq = ci.FirstSeq + int64(ur.Idx)
+	}
+	if ci.KeyID != "" {
+		// the payload is decrypted into its own memory already (see openRecord)
+		r.Payload = ur.UnsafePayload
+	} else {
+		// the UnsafePayload points into the memory mapped chunk, so it is copied to outlive the reader
+		r.Payload = make([]byte, len(ur.UnsafePayload))
+		copy(r.Payload, ur.UnsafePayload)
+	}
+	r.CreatedAt = timestamppb.New(ulid.Time(ur.ID.Time()))
+	r.Attributes = ur.Attributes
+	return r
+}
+
+// matchRecord returns whether the record read from the chunk matches the filters. The records of the encrypted
+// chunks are decrypted by the aead (see openRecord) before their payloads and attributes are matched, so the
+// returned record must be used instead of ur. The records are filtered by their IDs first, so the records
+// out of the time intervals are not decrypted.
+func matchRecord(ur chunkfs.UnsafeRecord, aead cipher.AEAD, plr storage.PayloadLenRange, tf tiFilter, rf recFilter) (chunkfs.UnsafeRecord, bool, error) {
+	if !tf.match(ur.ID) {
+		return ur, false, nil
+	}
+	if aead != nil {
+		var err error
+		if ur, err = openRecord(aead, ur); err != nil {
+			return ur, false, err
+		}
+	}
+	return ur, plr.Contains(len(ur.UnsafePayload)) && rf.match(ur), nil
+}
+
+// skipID returns the ID next to the id in the reading direction
+func skipID(id ulid.ULID, desc bool) ulid.ULID {
+	if desc {
+		return ulid.MustParse(ulidutils.PrevID(id.String()))
+	}
+	return ulid.MustParse(ulidutils.NextID(id.String()))
+}
+
+// getChunks returns the logID chunks sorted in the ascending order of their IDs. The chunks are searched
+// by sort.Search, which silently returns wrong results for the unsorted chunks, so the order is ensured
+// even if the LMStorage breaks the GetChunks contract.
+func (l *localLog) getChunks(ctx context.Context, logID string) ([]ChunkInfo, error) {
+	cis, err := l.LMStorage.GetChunks(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	cmp := func(a, b ChunkInfo) int { return strings.Compare(a.ID, b.ID) }
+	if !slices.IsSortedFunc(cis, cmp) {
+		// the chunks may be cached by the storage, so they are not sorted in place
+		cis = slices.Clone(cis)
+		slices.SortFunc(cis, cmp)
+	}
+	return cis, nil
+}
+
+// startIDBySeq returns the ID of the record with the sequence number seq. If the log has no such record (it is
+// truncated or not written yet), the ID, which the records next to the seq in the reading direction start from, is returned.
+func (l *localLog) startIDBySeq(ctx context.Context, cis []ChunkInfo, seq int64, desc bool) (string, error) {
+	// the not numbered chunks precede the numbered ones, so the next sequence numbers are not decreasing
+	idx := sort.Search(len(cis), func(i int) bool {
+		return cis[i].nextSeq() > seq
+	})
+	if idx == len(cis) {
+		if desc {
+			return cis[len(cis)-1].Max.String(), nil
+		}
+		return ulidutils.NextID(cis[len(cis)-1].Max.String()), nil
+	}
+	ci := cis[idx]
+	if ci.FirstSeq > seq {
+		if desc {
+			return ulidutils.PrevID(ci.Min.String()), nil
+		}
+		return ci.Min.String(), nil
+	}
+
+	rc, err := l.getOpenedChunkForRead(ctx, ci.ID)
+	if err != nil {
+		return "", err
+	}
+	defer l.ChnkProvider.ReleaseChunk(&rc)
+
+	cr, err := rc.Value().OpenChunkReader(desc)
+	if err != nil {
+		return "", err
+	}
+	defer cr.Close()
+
+	id, ok := cr.IDAt(int(seq - ci.FirstSeq))
+	if !ok {
+		return "", fmt.Errorf("the chunk %s has no record with seq=%d, but it is expected: %w", ci.ID, seq, errors.ErrInternal)
+	}
+	return id.String(), nil
+}
+
+// getOpenedChunkForRead returns the opened chunk by its ID. The transient failures are retried with
+// the exponential backoff up to l.cfg.OpenChunkRetries times, other errors are returned immediately.
+func (l *localLog) getOpenedChunkForRead(ctx context.Context, cID string) (lru.Releasable[*chunkfs.Chunk], error) {
+	backoff := l.cfg.OpenChunkBackoff
+	for i := 0; ; i++ {
+		rc, err := l.ChnkProvider.GetOpenedChunk(ctx, cID, false)
+		if err == nil || i >= l.cfg.OpenChunkRetries || !chunkfs.IsTransient(err) {
+			return rc, err
+		}
+		l.logger.Warnf("could not open the chunk %s (attempt %d), will retry in %s: %v", cID, i+1, backoff, err)
+		select {
+		case <-ctx.Done():
+			return rc, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (l *localLog) countRecords(ctx context.Context,
+	lid string,
+	ci ChunkInfo,
+	desc bool,
+	idRanges []idRange,
+	plr storage.PayloadLenRange,
+	tf tiFilter,
+	rf recFilter) (uint64, error) {
+
+	aead, err := l.chunkAEAD(ctx, lid, ci)
+	if err != nil {
+		return 0, err
+	}
+
+	rc, err := l.getOpenedChunkForRead(ctx, ci.ID)
+	if err != nil {
+		return 0, err
+	}
+	defer l.ChnkProvider.ReleaseChunk(&rc)
+
+	cr, err := rc.Value().OpenChunkReader(desc)
+	if err != nil {
+		return 0, err
+	}
+	defer cr.Close()
+
+	var count uint64
+	for _, ir := range idRanges {
+		if plr.IsAny() && tf == nil && rf == nil {
+			// every record in the range matches, so the records are counted by their IDs only,
+			// the descending ranges are reversed (see considerSIDAndDesc)
+			if desc {
+				count += uint64(cr.CountIDs(ir.end, ir.start))
+			} else {
+				count += uint64(cr.CountIDs(ir.start, ir.end))
+			}
+			continue
+		}
+		if ir.start.Compare(ulidutils.ZeroULID) != 0 {
+			cr.SetStartID(ir.start)
+		}
+		for cr.HasNext() {
+			ur, ok := cr.Next()
+			if !ok {
+				break
+			}
+			if ir.end.Compare(ulidutils.ZeroULID) != 0 &&
+				((desc && ur.ID.Compare(ir.end) < 0) || (!desc && ur.ID.Compare(ir.end) > 0)) {
+				break
+			}
+			if _, ok, err := matchRecord(ur, aead, plr, tf, rf); err != nil {
+				return 0, err
+			} else if !ok {
+				continue
+			}
+			count++
+		}
+	}
+	return count, cr.Err()
+}
+
+// getRecFilter returns the filter of the records by the request condition, if the condition refers the
+// records attributes, or nil otherwise
+func getRecFilter(request storage.QueryRecordsRequest) (recFilter, error) {
+	expr := request.Expr
+	if expr == nil {
+		if len(strings.TrimSpace(request.Condition)) == 0 {
+			return nil, nil
+		}
+		var err error
+		if expr, err = ql.Parse(request.Condition); err != nil {
+			return nil, err
+		}
+	}
+	if !expr.Uses("attr") {
+		return nil, nil
+	}
+	f, err := ql.BuildExprF(expr, ql.RecordsCondValueDialect)
+	return recFilter(f), err
+}
+
+// match returns true if the record matches the filter, the nil filter matches all the records
+func (rf recFilter) match(ur chunkfs.UnsafeRecord) bool {
+	if rf == nil {
+		return true
+	}
+	return rf(&solaris.Record{CreatedAt: timestamppb.New(ulid.Time(ur.ID.Time())), Attributes: ur.Attributes})
+}
+
+// getIntervals returns the records creation time intervals selected by the request condition and
+// the request window. The second returned value is false if the request doesn't limit the time at all.
+func getIntervals(request storage.QueryRecordsRequest) ([]intervals.Interval[time.Time], bool, error) {
+	var tis []intervals.Interval[time.Time]
+	if len(request.Condition) > 0 {
+		expr := request.Expr
+		if expr == nil && len(strings.TrimSpace(request.Condition)) > 0 {
+			var err error
+			if expr, err = ql.Parse(request.Condition); err != nil {
+				return nil, false, err
+			}
+		}
+		if expr != nil {
+			pis, err := tiBuilder.Build(expr)
+			if err != nil {
+				return nil, false, err
+			}
+			tis = pis["ctime"]
+		}
+	}
+	if !request.HasWindow() {
+		return tis, len(request.Condition) > 0, nil
+	}
+
+	from, to := tiBasis.Min, tiBasis.Max
+	if !request.CreatedAfter.IsZero() {
+		from = request.CreatedAfter
+	}
+	if !request.CreatedBefore.IsZero() {
+		to = request.CreatedBefore
+	}
+	if tiBasis.CmpF(from, to) > 0 {
+		return nil, true, nil
+	}
+	wi := tiBasis.Closed(from, to)
+	if len(request.Condition) == 0 {
+		return []intervals.Interval[time.Time]{wi}, true, nil
+	}
+	var res []intervals.Interval[time.Time]
+	for _, ti := range tis {
+		if ri, ok := tiBasis.Intersect(ti, wi); ok {
+			res = append(res, ri)
+		}
+	}
+	return res, true, nil
+}
+
+// pruneIntervals returns the intervals the log chunks are skipped by. If there are more than
+// l.cfg.MaxPruneIntervals intervals, the only interval covering all of them is returned with the filter,
+// the records are checked against, so the chunks pruning cost doesn't depend on the condition.
+func (l *localLog) pruneIntervals(lid string, tis []intervals.Interval[time.Time]) ([]intervals.Interval[time.Time], tiFilter) {
+	if l.cfg.MaxPruneIntervals <= 0 || len(tis) <= l.cfg.MaxPruneIntervals {
+		if len(tis) > 0 {
+			l.logger.Debugf("pruning the chunks of logID=%s by %d ctime intervals", lid, len(tis))
+		}
+		return tis, nil
+	}
+	l.logger.Debugf("%d ctime intervals exceed MaxPruneIntervals=%d, the records of logID=%s are filtered one by one",
+		len(tis), l.cfg.MaxPruneIntervals, lid)
+	first, last := tis[0], tis[len(tis)-1]
+	ti := intervals.Interval[time.Time]{L: first.L, LIn: first.LIn, R: last.R, RIn: last.RIn}
+	return []intervals.Interval[time.Time]{ti}, tis
+}
+
+// match returns true if the record with the id is created within one of the filter intervals
+func (tf tiFilter) match(id ulid.ULID) bool {
+	if tf == nil {
+		return true
+	}
+	tm := ulid.Time(id.Time())
+	// the first interval, which doesn't end before tm
+	idx := sort.Search(len(tf), func(i int) bool {
+		c := tiBasis.CmpF(tf[i].R, tm)
+		return c > 0 || (c == 0 && tf[i].RIn)
+	})
+	if idx == len(tf) {
+		return false
+	}
+	c := tiBasis.CmpF(tf[idx].L, tm)
+	return c < 0 || (c == 0 && tf[idx].LIn)
+}
+
+func getRanges(tis []intervals.Interval[time.Time], ci ChunkInfo) []idRange {
+	cti := tiBasis.Closed(ulid.Time(ci.Min.Time()), ulid.Time(ci.Max.Time()))
+	var ranges []idRange
+	for _, ti := range tis {
+		if ri, ok := tiBasis.Intersect(cti, ti); ok {
+			// the interval may contain no milliseconds at all, e.g. (10.1ms, 10.9ms)
+			if r, ok := toRange(ri); ok {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	return ranges
+}
+
+// toRange returns the range of the records IDs created within the time interval. The records creation
+// time has the milliseconds precision (see ULID), so the interval borders are rounded to the first
+// and the last milliseconds within the interval. Returns false if there are no such milliseconds.
+func toRange(ti intervals.Interval[time.Time]) (idRange, bool) {
+	first := ti.L.Truncate(time.Millisecond)
+	if !ti.LIn || first.Before(ti.L) {
+		first = first.Add(time.Millisecond)
+	}
+	last := ti.R.Truncate(time.Millisecond)
+	if !ti.RIn && last.Equal(ti.R) {
+		last = last.Add(-time.Millisecond)
+	}
+	if first.After(last) {
+		return idRange{}, false
+	}
+	return idRange{start: minULIDForTime(first), end: maxULIDForTime(last)}, true
+}
+
+func minULIDForTime(t time.Time) ulid.ULID {
+	var id ulid.ULID
+	_ = id.SetTime(uint64(t.UnixMilli()))
+	return id
+}
+
+func maxULIDForTime(t time.Time) ulid.ULID {
+	maxBytes := make([]byte, 10)
+	for i := 0; i < len(maxBytes); i++ {
+		maxBytes[i] = 0xff
+	}
+	var id ulid.ULID
+	_ = id.SetTime(uint64(t.UnixMilli()))
+	_ = id.SetEntropy(maxBytes)
+	return id
+}
+
+func considerSIDAndDesc(irs []idRange, sid ulid.ULID, desc bool) []idRange {
+	if len(irs) == 0 {
+		return []idRange{{start: sid}}
+	}
+	if sid.Compare(ulidutils.ZeroULID) == 0 {
+		if desc {
+			return reverseRanges(irs)
+		}
+		return irs
+	}
+	if desc {
 		irs = reverseRanges(irs)
 		if sid.Compare(irs[0].start) >= 0 {
 			return irs
@@ -577,3 +2186,50 @@ func reverseRanges(irs []idRange) []idRange {
 	}
 	return irs
 }
+
+// rollbackChunks truncates the chunks cis back to their prevCounts records in the reverse order, so the records
+// appended to the chunks are removed. The function returns the number of the first cis chunks, which keep the
+// appended records, because they could not be rolled back, and the IDs of the chunks, which become empty, so
+// their files may be deleted when the log lock is released (see deleteEmptyFiles).
+func (l *localLog) rollbackChunks(ctx context.Context, lid string, cis []ChunkInfo, prevCounts []int) (int, []string) {
+	var emptyIDs []string
+	for i := len(cis) - 1; i >= 0; i-- {
+		if err := l.truncateChunk(ctx, cis[i].ID, prevCounts[i]); err != nil {
+			l.logger.Errorf("could not roll back the chunk id=%s of logID=%s to %d records: %v", cis[i].ID, lid, prevCounts[i], err)
+			return i + 1, emptyIDs
+		}
+		if prevCounts[i] == 0 {
+			emptyIDs = append(emptyIDs, cis[i].ID)
+		}
+	}
+	return 0, emptyIDs
+}
+
+func (l *localLog) truncateChunk(ctx context.Context, cID string, total int) error {
+	rc, err := l.ChnkProvider.GetOpenedChunk(ctx, cID, false)
+	if err != nil {
+		return err
+	}
+	defer l.ChnkProvider.ReleaseChunk(&rc)
+
+	if err := l.ChnkProvider.CA.SetWriting(ctx, cID); err != nil {
+		return err
+	}
+	defer l.ChnkProvider.CA.SetIdle(cID)
+
+	return rc.Value().Truncate(total)
+}
+
+// nextSeq returns the sequence number of the record, which follows the last one in the chunk, or 0
+// if the chunk records are not numbered
+func (ci ChunkInfo) nextSeq() int64 {
+	if ci.FirstSeq == 0 {
+		return 0
+	}
+	return ci.FirstSeq + int64(ci.RecordsCount)
+}
+
+// result returns the append result the key was stored with
+func (ak AppendKey) result() *solaris.AppendRecordsResult {
+	return &solaris.AppendRecordsResult{Added: ak.Added, BytesWritten: ak.BytesWritten, StartID: ak.StartID, LastID: ak.LastID}
+}
@@ -15,13 +15,21 @@
 package logfs
 
 import (
+	"bytes"
 	"context"
 	rand2 "crypto/rand"
 	"fmt"
 	"github.com/oklog/ulid/v2"
+	"io"
+	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -30,6 +38,11 @@ import (
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/files"
 	"github.com/solarisdb/solaris/golibs/logging"
+	"github.com/solarisdb/solaris/golibs/sss"
+	"github.com/solarisdb/solaris/golibs/sss/inmem"
+	"github.com/solarisdb/solaris/golibs/ulidutils"
+	"github.com/solarisdb/solaris/pkg/intervals"
+	"github.com/solarisdb/solaris/pkg/ql"
 	"github.com/solarisdb/solaris/pkg/storage"
 	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
 	"github.com/stretchr/testify/assert"
@@ -215,13 +228,13 @@ func TestCountRecords_SingleRecord(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), res.Added)
 
-	total, count, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100000})
+	total, count, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100000})
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), total)
 	assert.Equal(t, uint64(1), count)
 
-	total, count, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100000, Descending: true})
+	total, count, _, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100000, Descending: true})
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), total)
@@ -244,21 +257,21 @@ func TestCountRecords_SingleChunk(t *testing.T) {
 	lastId := records[2].ID
 
 	// No preconditions
-	total, count, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100000})
+	total, count, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100000})
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(5), total)
 	assert.Equal(t, uint64(5), count)
 
 	// Since some point
-	total, count, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: lastId})
+	total, count, _, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: lastId})
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(5), total)
 	assert.Equal(t, uint64(3), count)
 
 	// Since some point in descending order
-	total, count, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: lastId, Descending: true})
+	total, count, _, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: lastId, Descending: true})
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(5), total)
@@ -299,6 +312,13 @@ func TestQueryCountRecordsWithCondition(t *testing.T) {
 	require.Len(t, records, 4)
 	require.False(t, more)
 
+	// the already parsed condition gives the same result
+	expr, err := ql.Parse(cond)
+	require.NoError(t, err)
+	parsed, _, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: startIDAsc, Condition: cond, Expr: expr, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, records, parsed)
+
 	records, more, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: startIDDesc, Condition: cond, Limit: 10, Descending: true})
 	require.NoError(t, err)
 	require.Len(t, records, 4)
@@ -316,17 +336,265 @@ func TestQueryCountRecordsWithCondition(t *testing.T) {
 	require.True(t, more)
 
 	// count
-	total, count, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: startIDAsc, Condition: cond})
+	total, count, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: startIDAsc, Condition: cond})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(4), count)
 	assert.Equal(t, uint64(10), total)
 
-	total, count, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: startIDDesc, Condition: cond, Descending: true})
+	total, count, _, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: startIDDesc, Condition: cond, Descending: true})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(4), count)
 	assert.Equal(t, uint64(10), total)
 }
 
+func TestToRange(t *testing.T) {
+	ms := time.UnixMilli(1712851200000).UTC()
+	sub := ms.Add(500 * time.Microsecond)
+	for _, tc := range []struct {
+		ti          intervals.Interval[time.Time]
+		first, last time.Time
+		ok          bool
+	}{
+		{ti: tiBasis.Closed(ms, ms), first: ms, last: ms, ok: true},
+		{ti: tiBasis.OpenL(ms, ms.Add(time.Millisecond)), first: ms.Add(time.Millisecond), last: ms.Add(time.Millisecond), ok: true},
+		{ti: tiBasis.OpenR(ms, ms.Add(time.Millisecond)), first: ms, last: ms, ok: true},
+		{ti: tiBasis.Open(ms, ms.Add(time.Millisecond))},
+		{ti: tiBasis.Open(ms, ms.Add(2*time.Millisecond)), first: ms.Add(time.Millisecond), last: ms.Add(time.Millisecond), ok: true},
+		// the records of the ms millisecond are created before the sub time
+		{ti: tiBasis.Closed(sub, sub)},
+		{ti: tiBasis.Closed(sub, sub.Add(time.Millisecond)), first: ms.Add(time.Millisecond), last: ms.Add(time.Millisecond), ok: true},
+		{ti: tiBasis.OpenL(sub, sub.Add(time.Millisecond)), first: ms.Add(time.Millisecond), last: ms.Add(time.Millisecond), ok: true},
+		{ti: tiBasis.OpenR(ms.Add(-time.Millisecond), sub), first: ms.Add(-time.Millisecond), last: ms, ok: true},
+		{ti: tiBasis.Open(ms.Add(-time.Millisecond), sub), first: ms, last: ms, ok: true},
+	} {
+		r, ok := toRange(tc.ti)
+		assert.Equal(t, tc.ok, ok, tc.ti)
+		if ok {
+			assert.Equal(t, minULIDForTime(tc.first), r.start, tc.ti)
+			assert.Equal(t, maxULIDForTime(tc.last), r.end, tc.ti)
+		}
+	}
+}
+
+func TestQueryRecordsMaxPruneIntervals(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 10; i++ {
+		recs = append(recs, generateRecords(1, 100)...)
+		_, err := ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs[len(recs)-1:], LogID: "l1"})
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond) // ULIDs have time in millis
+	}
+	var excluded []string
+	for _, i := range []int{1, 4, 5, 8} {
+		excluded = append(excluded, fmt.Sprintf("ctime != '%s'", ulid.Time(ulid.MustParse(recs[i].ID).Time()).Format(time.RFC3339Nano)))
+	}
+	// 5 intervals
+	cond := strings.Join(excluded, " AND ")
+	tis, limited, err := getIntervals(storage.QueryRecordsRequest{Condition: cond})
+	require.NoError(t, err)
+	require.True(t, limited)
+	require.Len(t, tis, 5)
+
+	query := func(desc bool) ([]string, uint64) {
+		records, _, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Condition: cond,
+			Limit: 100, Descending: desc})
+		require.NoError(t, err)
+		var ids []string
+		for _, r := range records {
+			ids = append(ids, r.ID)
+		}
+		_, count, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Condition: cond,
+			Descending: desc})
+		require.NoError(t, err)
+		return ids, count
+	}
+	for _, desc := range []bool{false, true} {
+		ll.cfg.MaxPruneIntervals = 0
+		ids, count := query(desc)
+		assert.Len(t, ids, 6)
+		assert.Equal(t, uint64(6), count)
+		for _, i := range []int{1, 4, 5, 8} {
+			assert.NotContains(t, ids, recs[i].ID)
+		}
+
+		// the intervals exceed the limit, so the records are filtered one by one
+		ll.cfg.MaxPruneIntervals = 2
+		pis, tf := ll.pruneIntervals("l1", tis)
+		assert.Len(t, pis, 1)
+		assert.Len(t, tf, 5)
+		fids, fcount := query(desc)
+		assert.Equal(t, ids, fids)
+		assert.Equal(t, count, fcount)
+	}
+}
+
+func TestQueryRecordsChunksPruning(t *testing.T) {
+	p, ll := setupTestDB(t)
+	ll.cfg.MaxRecordsLimit = 100
+	ll.cfg.MaxBunchSize = 100 * files.BlockSize
+	defer p.Close()
+	defer ll.Shutdown()
+
+	// every record is created in its own millisecond, so the chunks time ranges don't intersect
+	for i := 0; i < 30; i++ {
+		_, err := ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: generateRecords(1, 1000), LogID: "l1"})
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+	lms := ll.LMStorage.(*testLogsMetaStorage)
+	cis, err := lms.GetChunks(context.Background(), "l1")
+	require.NoError(t, err)
+	require.True(t, len(cis) > 2)
+
+	// the chunks out of the condition time range are not opened, so they could be missed
+	mi := len(cis) / 2
+	lms.lock.Lock()
+	for i := range lms.logs["l1"] {
+		if i != mi {
+			lms.logs["l1"][i].ID = ulidutils.NextID(lms.logs["l1"][i].ID)
+		}
+	}
+	lms.lock.Unlock()
+	cond := fmt.Sprintf("ctime BETWEEN '%s' AND '%s'", ulid.Time(cis[mi].Min.Time()).Format(time.RFC3339Nano),
+		ulid.Time(cis[mi].Max.Time()).Format(time.RFC3339Nano))
+	for _, desc := range []bool{false, true} {
+		records, more, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Condition: cond,
+			Limit: 100, Descending: desc})
+		require.NoError(t, err)
+		assert.False(t, more)
+		assert.Equal(t, cis[mi].RecordsCount, len(records))
+
+		_, count, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Condition: cond, Descending: desc})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(cis[mi].RecordsCount), count)
+	}
+
+	// the query without the condition reads all the chunks
+	_, _, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100})
+	assert.NotNil(t, err)
+}
+
+func TestQueryRecordsStartExclusive(t *testing.T) {
+	p, ll := setupTestDB(t)
+	ll.cfg.MaxRecordsLimit = 100
+	ll.cfg.MaxBunchSize = 100 * files.BlockSize
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(30, 1000), LogID: "l1"})
+	require.NoError(t, err)
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.NoError(t, err)
+	require.True(t, len(cis) > 1)
+	all, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, all, 30)
+
+	first := func(sid ulid.ULID, desc, exclusive bool) string {
+		recs, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartID: sid.String(),
+			StartExclusive: exclusive, Descending: desc, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, recs, 1)
+		return recs[0].ID
+	}
+	// the chunk boundary
+	assert.Equal(t, cis[0].Max.String(), first(cis[0].Max, false, false))
+	assert.Equal(t, cis[1].Min.String(), first(cis[0].Max, false, true))
+	assert.Equal(t, cis[1].Min.String(), first(cis[1].Min, true, false))
+	assert.Equal(t, cis[0].Max.String(), first(cis[1].Min, true, true))
+
+	_, count, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartID: cis[0].Max.String()})
+	require.NoError(t, err)
+	_, countEx, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartID: cis[0].Max.String(), StartExclusive: true})
+	require.NoError(t, err)
+	assert.Equal(t, count-1, countEx)
+
+	// the pages are read by the last record IDs of the previous ones
+	for _, desc := range []bool{false, true} {
+		var ids []string
+		qr := storage.QueryRecordsRequest{LogID: "l1", Descending: desc, Limit: 7}
+		for {
+			recs, more, err := ll.QueryRecords(ctx, qr)
+			require.NoError(t, err)
+			for _, r := range recs {
+				ids = append(ids, r.ID)
+			}
+			if !more {
+				break
+			}
+			qr.StartID = recs[len(recs)-1].ID
+			qr.StartExclusive = true
+		}
+		require.Len(t, ids, len(all))
+		for i, r := range all {
+			if desc {
+				i = len(all) - 1 - i
+			}
+			assert.Equal(t, r.ID, ids[i])
+		}
+	}
+}
+
+func TestQueryRecordsUnsortedChunks(t *testing.T) {
+	p, ll := setupTestDB(t)
+	ll.cfg.MaxRecordsLimit = 100
+	ll.cfg.MaxBunchSize = 100 * files.BlockSize
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(30, 1000), LogID: "l1"})
+	require.NoError(t, err)
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.NoError(t, err)
+	require.True(t, len(cis) > 2)
+	all, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, all, 30)
+
+	// the storage breaks the GetChunks contract, but the results are the same
+	ll.LMStorage = &reversedLogsMetaStorage{testLogsMetaStorage: ll.LMStorage.(*testLogsMetaStorage)}
+	for _, desc := range []bool{false, true} {
+		sid := cis[1].Min.String()
+		recs, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartID: sid, Descending: desc, Limit: 100})
+		require.NoError(t, err)
+		_, count, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartID: sid, Descending: desc})
+		require.NoError(t, err)
+
+		idx := slices.IndexFunc(all, func(r *solaris.Record) bool { return r.ID == sid })
+		expected := slices.Clone(all[idx:])
+		if desc {
+			expected = slices.Clone(all[:idx+1])
+			slices.Reverse(expected)
+		}
+		assert.Equal(t, len(expected), len(recs), "desc=%t", desc)
+		assert.Equal(t, uint64(len(expected)), count, "desc=%t", desc)
+		for i := range min(len(expected), len(recs)) {
+			assert.Equal(t, expected[i].ID, recs[i].ID)
+		}
+	}
+	// the chunks of the storage are not sorted in place
+	rcis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.NoError(t, err)
+	assert.Equal(t, cis[len(cis)-1].ID, rcis[0].ID)
+}
+
+// reversedLogsMetaStorage returns the chunks in the descending order
+type reversedLogsMetaStorage struct {
+	*testLogsMetaStorage
+}
+
+func (lms *reversedLogsMetaStorage) GetChunks(ctx context.Context, logID string) ([]ChunkInfo, error) {
+	cis, err := lms.testLogsMetaStorage.GetChunks(ctx, logID)
+	slices.Reverse(cis)
+	return cis, err
+}
+
 func TestCountRecords_ManyChunks(t *testing.T) {
 	p, ll := setupTestDB(t)
 	ll.cfg.MaxRecordsLimit = 100
@@ -358,25 +626,168 @@ func TestCountRecords_ManyChunks(t *testing.T) {
 	}
 
 	require.Len(t, addedRecords, 100)
-	total, count, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100000})
+	total, count, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100000})
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(100), total)
 	assert.Equal(t, uint64(100), count)
 
-	total, count, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: addedRecords[80].ID, Limit: 100000})
+	total, count, _, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: addedRecords[80].ID, Limit: 100000})
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(100), total)
 	assert.Equal(t, uint64(20), count)
 
-	total, count, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: addedRecords[19].ID, Limit: 100000, Descending: true})
+	total, count, _, err = ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", StartID: addedRecords[19].ID, Limit: 100000, Descending: true})
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(100), total)
 	assert.Equal(t, uint64(20), count)
 }
 
+func TestCountRecords_Deadline(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	// the records of 100 and 1000 bytes are interleaved, so every chunk contains both
+	for i := 0; i < 20; i++ {
+		_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: append(generateRecords(1, 100), generateRecords(1, 1000)...), LogID: "l1"})
+		require.NoError(t, err)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.NoError(t, err)
+	require.Greater(t, len(cis), 2)
+
+	total, count, exact, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", PayloadLen: storage.PayloadLenRange{Min: 500}})
+	assert.NoError(t, err)
+	assert.True(t, exact)
+	assert.Equal(t, uint64(40), total)
+	assert.Equal(t, uint64(20), count)
+
+	// the deadline is passed, so the first chunk is scanned only, and the rest are estimated
+	total, count, exact, err = ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", PayloadLen: storage.PayloadLenRange{Min: 500},
+		CountDeadline: time.Now().Add(-time.Minute)})
+	assert.NoError(t, err)
+	assert.False(t, exact)
+	assert.Equal(t, uint64(40), total)
+	assert.InDelta(t, 20, count, 4)
+
+	// the chunks fully inside the window are not scanned, so the count is exact
+	total, count, exact, err = ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", CreatedAfter: time.Now().Add(-time.Hour),
+		CountDeadline: time.Now().Add(-time.Minute)})
+	assert.NoError(t, err)
+	assert.True(t, exact)
+	assert.Equal(t, uint64(40), total)
+	assert.Equal(t, uint64(40), count)
+}
+
+func TestCountRecords_ChunkReplaced(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	// every chunk fits 2 records only
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(6, 3000), LogID: "l1"})
+	require.NoError(t, err)
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.NoError(t, err)
+	require.Equal(t, 3, len(cis))
+
+	// the first chunk is removed after its info is read, so the count is repeated
+	_, err = ll.TrimRecords(ctx, "l1", 4, 0)
+	require.NoError(t, err)
+	ll.LMStorage = &staleChunksMetaStorage{LogsMetaStorage: ll.LMStorage, stale: cis}
+	total, count, exact, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", PayloadLen: storage.PayloadLenRange{Min: 1}})
+	assert.NoError(t, err)
+	assert.True(t, exact)
+	assert.Equal(t, uint64(4), total)
+	assert.Equal(t, uint64(4), count)
+
+	// the chunk which is not removed, but cannot be read, fails the count
+	ll.LMStorage = &staleChunksMetaStorage{LogsMetaStorage: ll.LMStorage.(*staleChunksMetaStorage).LogsMetaStorage}
+	cis, err = ll.LMStorage.GetChunks(ctx, "l1")
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(p.GetFileNameByID(cis[0].ID)))
+	_, _, _, err = ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", PayloadLen: storage.PayloadLenRange{Min: 1}})
+	assert.Error(t, err)
+}
+
+func TestCountRecords_WindowByIDs(t *testing.T) {
+	p, ll := setupTestDB(t)
+	ll.cfg.MaxRecordsLimit = 1000
+	ll.cfg.MaxBunchSize = 100 * files.BlockSize
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(10, 100), LogID: "l1"})
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+	recs, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1000})
+	require.NoError(t, err)
+	require.Equal(t, 100, len(recs))
+	after, before := recs[25].CreatedAt.AsTime(), recs[75].CreatedAt.AsTime()
+
+	// the records counted by their IDs and by reading the payloads are the same
+	for _, desc := range []bool{false, true} {
+		for _, sid := range []string{"", recs[50].ID} {
+			req := storage.QueryRecordsRequest{LogID: "l1", Descending: desc, StartID: sid, CreatedAfter: after, CreatedBefore: before}
+			_, byIDs, _, err := ll.CountRecords(ctx, req)
+			require.NoError(t, err)
+			req.PayloadLen = storage.PayloadLenRange{Min: 1}
+			_, byPayloads, _, err := ll.CountRecords(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, byPayloads, byIDs, "desc=%t, startID=%s", desc, sid)
+			assert.Greater(t, byIDs, uint64(0))
+		}
+	}
+}
+
+// BenchmarkCountRecords_Window counts the records of a large single chunk created within a window. The records are
+// counted by their IDs, if the request selects them by the creation time only, and the chunk payloads are read,
+// if the payload length is checked too.
+func BenchmarkCountRecords_Window(b *testing.B) {
+	dir, err := os.MkdirTemp("", "BenchmarkCountRecords_Window")
+	require.NoError(b, err)
+	defer os.RemoveAll(dir)
+
+	p := testProvider(dir, 1, chunkfs.GetDefaultConfig())
+	defer p.Close()
+	ll := NewLocalLog(Config{MaxRecordsLimit: 10, MaxBunchSize: files.BlockSize, MaxLocks: 1})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1000, 100), LogID: "l1"})
+		require.NoError(b, err)
+		time.Sleep(time.Millisecond)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.NoError(b, err)
+	require.Len(b, cis, 1)
+	from, to := ulid.Time(cis[0].Min.Time()), ulid.Time(cis[0].Max.Time())
+	after := from.Add(to.Sub(from) / 4)
+	before := to.Add(-to.Sub(from) / 4)
+
+	for _, plr := range []storage.PayloadLenRange{{}, {Min: 1}} {
+		b.Run(fmt.Sprintf("minPayloadLen%d", plr.Min), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, count, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", CreatedAfter: after,
+					CreatedBefore: before, PayloadLen: plr})
+				require.NoError(b, err)
+				require.Greater(b, count, uint64(0))
+			}
+		})
+	}
+}
+
 func TestConcurrentMess(t *testing.T) {
 	dir, err := os.MkdirTemp("", "TestConcurrentMess2")
 	assert.Nil(t, err)
@@ -427,11 +838,1674 @@ func TestConcurrentMess(t *testing.T) {
 	wg.Wait()
 }
 
-func comparePayloads(t *testing.T, a, b []*solaris.Record) {
-	assert.Equal(t, len(a), len(b))
-	for i, v := range a {
-		assert.Equal(t, v.Payload, b[i].Payload)
+func TestAppendRecordsDifferentLogsInParallel(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	// the appends meet in the meta-storage, what is possible only if they are not serialized by MaxLocks=1
+	const logs = 10
+	lms := &parallelLogsMetaStorage{testLogsMetaStorage: newTestLogsMetaStorage(), n: logs, all: make(chan struct{})}
+	ll.LMStorage = lms
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, logs)
+	for i := 0; i < logs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 100), LogID: fmt.Sprintf("l%d", i)})
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < logs; i++ {
+		require.Nil(t, errs[i])
+		cis, err := ll.LMStorage.GetChunks(ctx, fmt.Sprintf("l%d", i))
+		require.Nil(t, err)
+		assert.Equal(t, 2, cis[0].RecordsCount)
+	}
+	assert.Equal(t, 0, ll.logLocks.size())
+
+	// the appends to the same log are still serialized
+	ll.LMStorage = lms.testLogsMetaStorage
+	for i := 0; i < logs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 100), LogID: "l0"})
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	total, _, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l0"})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(2+2*logs), total)
+}
+
+// parallelLogsMetaStorage blocks GetLastChunk until it is called n times
+type parallelLogsMetaStorage struct {
+	*testLogsMetaStorage
+	arrived atomic.Int32
+	n       int32
+	all     chan struct{}
+}
+
+func (lms *parallelLogsMetaStorage) GetLastChunk(ctx context.Context, logID string) (ChunkInfo, error) {
+	if lms.arrived.Add(1) == lms.n {
+		close(lms.all)
+	}
+	select {
+	case <-lms.all:
+	case <-ctx.Done():
+		return ChunkInfo{}, ctx.Err()
+	}
+	return lms.testLogsMetaStorage.GetLastChunk(ctx, logID)
+}
+
+func TestAppendRecordsCanceledCommit(t *testing.T) {
+	p, ll := setupTestDB(t)
+	ll.cfg.MetaCommitTimeout = time.Minute
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lms := &cancelingLogsMetaStorage{testLogsMetaStorage: ll.LMStorage.(*testLogsMetaStorage), cancel: cancel}
+	ll.LMStorage = lms
+
+	// the request is cancelled after the records are written, but before the chunks are committed
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(5, 100), LogID: "l1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), res.Added)
+	assert.True(t, lms.hasDeadline)
+	assert.NotNil(t, ctx.Err())
+
+	cis, err := ll.LMStorage.GetChunks(context.Background(), "l1")
+	require.NoError(t, err)
+	require.Len(t, cis, 1)
+	assert.Equal(t, 5, cis[0].RecordsCount)
+	recs, _, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, recs, 5)
+}
+
+func TestAppendRecordsCommitFailed(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(5, 100), LogID: "l1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), res.Added)
+
+	// the meta-storage is not available, so the append fails and the written records are rolled back
+	lms := ll.LMStorage
+	ll.LMStorage = &failingUpsertMetaStorage{LogsMetaStorage: lms}
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(5, 100), LogID: "l1"})
+	assert.True(t, errors.Is(err, errors.ErrInternal))
+	ll.LMStorage = lms
+
+	recs, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 20})
+	require.NoError(t, err)
+	assert.Len(t, recs, 5)
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(5, 100), LogID: "l1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), res.Added)
+	recs, _, err = ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 20})
+	require.NoError(t, err)
+	assert.Len(t, recs, 10)
+}
+
+// failingUpsertMetaStorage fails the chunks upserts
+type failingUpsertMetaStorage struct {
+	LogsMetaStorage
+}
+
+func (lms *failingUpsertMetaStorage) UpsertChunkInfos(ctx context.Context, logID string, cis []ChunkInfo) error {
+	return fmt.Errorf("the meta-storage is not available: %w", errors.ErrInternal)
+}
+
+// testRecordHashes collects the records IDs ranges removed from the index
+type testRecordHashes struct {
+	storage.RecordHashes
+	deleted [][2]string
+}
+
+func (rh *testRecordHashes) DeleteRecordHashes(ctx context.Context, logID, fromID, toID string) error {
+	rh.deleted = append(rh.deleted, [2]string{fromID, toID})
+	return nil
+}
+
+// staleChunksMetaStorage returns the stale chunks once, as if they are read before the log is changed
+type staleChunksMetaStorage struct {
+	LogsMetaStorage
+	stale []ChunkInfo
+}
+
+func (lms *staleChunksMetaStorage) GetChunks(ctx context.Context, logID string) ([]ChunkInfo, error) {
+	if cis := lms.stale; cis != nil {
+		lms.stale = nil
+		return cis, nil
+	}
+	return lms.LogsMetaStorage.GetChunks(ctx, logID)
+}
+
+// cancelingLogsMetaStorage cancels the request before the chunks are upserted, and fails the
+// upsert and the deletion with the cancelled context, as the database storages do
+type cancelingLogsMetaStorage struct {
+	*testLogsMetaStorage
+	cancel      context.CancelFunc
+	hasDeadline bool
+}
+
+func (lms *cancelingLogsMetaStorage) UpsertChunkInfos(ctx context.Context, logID string, cis []ChunkInfo) error {
+	lms.cancel()
+	_, lms.hasDeadline = ctx.Deadline()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return lms.testLogsMetaStorage.UpsertChunkInfos(ctx, logID, cis)
+}
+
+func (lms *cancelingLogsMetaStorage) DeleteChunkInfos(ctx context.Context, logID string, cIDs []string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return lms.testLogsMetaStorage.DeleteChunkInfos(ctx, logID, cIDs)
+}
+
+func TestTrimRecordsCanceled(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestTrimRecordsCanceled")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	p := testProvider(dir, 10, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        4 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	defer p.Close()
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10, MetaCommitTimeout: time.Minute})
+	tlms := newTestLogsMetaStorage()
+	ll.LMStorage = tlms
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+	for i := 0; i < 3; i++ {
+		_, err = ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: generateRecords(10, 1000), LogID: "l1"})
+		require.Nil(t, err)
+	}
+
+	// the request is cancelled when the boundary chunk is replaced, but the chunks are swapped anyway
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lms := &cancelingLogsMetaStorage{testLogsMetaStorage: tlms, cancel: cancel}
+	ll.LMStorage = lms
+	n, err := ll.TrimRecords(ctx, "l1", 13, 0)
+	require.Nil(t, err)
+	assert.Equal(t, int64(17), n)
+	assert.True(t, lms.hasDeadline)
+	total, _, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(13), total)
+
+	// the replaced chunks could not be removed, so the new ones are removed back and the error is returned
+	fms := &failingDeleteMetaStorage{testLogsMetaStorage: tlms}
+	ll.LMStorage = fms
+	_, err = ll.TrimRecords(context.Background(), "l1", 5, 0)
+	assert.NotNil(t, err)
+	fms.failAll = true
+	_, err = ll.TrimRecords(context.Background(), "l1", 5, 0)
+	assert.True(t, errors.Is(err, errors.ErrInternal))
+}
+
+// failingDeleteMetaStorage fails the first DeleteChunkInfos call, or all of them if failAll is set
+type failingDeleteMetaStorage struct {
+	*testLogsMetaStorage
+	failed  bool
+	failAll bool
+}
+
+func (lms *failingDeleteMetaStorage) DeleteChunkInfos(ctx context.Context, logID string, cIDs []string) error {
+	if !lms.failed || lms.failAll {
+		lms.failed = true
+		return fmt.Errorf("could not delete the chunks: %w", errors.ErrInternal)
+	}
+	return lms.testLogsMetaStorage.DeleteChunkInfos(ctx, logID, cIDs)
+}
+
+func TestAppendRecordsDiskFull(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	recs := generateRecords(10, 100)
+	res, err := ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), res.Added)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ll.DiskMonitor = chunkfs.NewDiskMonitor(chunkfs.DiskMonitorConfig{DataPath: os.TempDir(), MinFreeSpace: math.MaxInt64, CheckInterval: time.Minute})
+	require.Nil(t, ll.DiskMonitor.Init(ctx))
+
+	_, err = ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: "l1"})
+	assert.True(t, errors.Is(err, errors.ErrExhausted))
+
+	qrecs, _, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100})
+	assert.Nil(t, err)
+	comparePayloads(t, qrecs, recs)
+}
+
+func TestQueryRecordsByPayloadLen(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 10; i++ {
+		recs = append(recs, generateRecords(1, 10*(i+1))...)
+	}
+	res, err := ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), res.Added)
+
+	qrecs, more, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 10,
+		PayloadLen: storage.PayloadLenRange{Min: 50}})
+	assert.Nil(t, err)
+	assert.False(t, more)
+	comparePayloads(t, qrecs, recs[4:])
+
+	qrecs, _, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 10,
+		PayloadLen: storage.PayloadLenRange{Max: 30}})
+	assert.Nil(t, err)
+	comparePayloads(t, qrecs, recs[:3])
+
+	qrecs, _, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 10, Descending: true,
+		PayloadLen: storage.PayloadLenRange{Min: 20, Max: 40}})
+	assert.Nil(t, err)
+	comparePayloads(t, qrecs, []*solaris.Record{recs[3], recs[2], recs[1]})
+
+	qrecs, _, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 10,
+		PayloadLen: storage.PayloadLenRange{Min: 1000}})
+	assert.Nil(t, err)
+	assert.Len(t, qrecs, 0)
+
+	total, count, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1",
+		PayloadLen: storage.PayloadLenRange{Min: 20, Max: 40}})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), total)
+	assert.Equal(t, uint64(3), count)
+}
+
+func TestAppendRecordsAttributesOldFormat(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestAppendRecordsAttributesOldFormat")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	cfg := chunkfs.Config{NewSize: files.BlockSize, MaxChunkSize: 10 * files.BlockSize, MaxGrowIncreaseSize: files.BlockSize}
+	p := testProvider(dir, 10, cfg)
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 10), LogID: "l1"})
+	require.Nil(t, err)
+	ci, err := ll.LMStorage.GetLastChunk(ctx, "l1")
+	require.Nil(t, err)
+
+	// the last chunk is turned into the format without the attributes
+	p.Close()
+	f, err := os.OpenFile(p.GetFileNameByID(ci.ID), os.O_WRONLY, 0)
+	require.Nil(t, err)
+	_, err = f.WriteAt([]byte{3}, 7)
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+	p = testProvider(dir, 10, cfg)
+	defer p.Close()
+	ll.ChnkProvider = p
+
+	// the records without the attributes are still appended to the chunk, but the ones with the attributes
+	// are written into a new chunk
+	recs := generateRecords(3, 10)
+	recs[1].Attributes = map[string]string{"severity": "error"}
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: recs, LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), res.Added)
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(cis))
+	assert.Equal(t, 3, cis[0].RecordsCount)
+	assert.Equal(t, 2, cis[1].RecordsCount)
+
+	qrecs, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 10, Condition: "attr('severity') = 'error'"})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(qrecs))
+	assert.Equal(t, recs[1].ID, qrecs[0].ID)
+	assert.Equal(t, recs[1].Attributes, qrecs[0].Attributes)
+}
+
+func TestQueryRecordsByAttributes(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	recs := generateRecords(6, 10)
+	for i, r := range recs {
+		r.Attributes = map[string]string{"host": fmt.Sprintf("h%d", i)}
+		if i%2 == 0 {
+			r.Attributes["severity"] = "error"
+		}
+	}
+	res, err := ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), res.Added)
+
+	qrecs, more, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 10,
+		Condition: "attr('severity') = 'error'"})
+	assert.Nil(t, err)
+	assert.False(t, more)
+	comparePayloads(t, qrecs, []*solaris.Record{recs[0], recs[2], recs[4]})
+	for i, r := range qrecs {
+		assert.Equal(t, recs[2*i].Attributes, r.Attributes)
+	}
+
+	// the attributes condition doesn't narrow the records selected by the creation time in the other branch
+	cond := fmt.Sprintf("attr('host') = 'h1' OR ctime > '%s'", ulid.Time(ulid.MustParse(recs[4].ID).Time()).Format(time.RFC3339Nano))
+	qrecs, _, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 10, Condition: cond})
+	assert.Nil(t, err)
+	var expected []*solaris.Record
+	for _, r := range recs {
+		if r.Attributes["host"] == "h1" || ulid.MustParse(r.ID).Time() > ulid.MustParse(recs[4].ID).Time() {
+			expected = append(expected, r)
+		}
+	}
+	comparePayloads(t, qrecs, expected)
+
+	total, count, exact, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1",
+		Condition: "attr('severity') = 'error' AND attr('host') != 'h0'"})
+	assert.Nil(t, err)
+	assert.True(t, exact)
+	assert.Equal(t, uint64(6), total)
+	assert.Equal(t, uint64(2), count)
+}
+
+func TestQueryRecordsOffset(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestQueryRecordsOffset")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 10, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        4 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	defer p.Close()
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 5; i++ {
+		batch := generateRecords(10, 1000)
+		for j, r := range batch {
+			if j%2 == 0 {
+				r.Attributes = map[string]string{"even": "true"}
+			}
+		}
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 2)
+	rev := slices.Clone(recs)
+	slices.Reverse(rev)
+
+	check := func(req storage.QueryRecordsRequest, expected []*solaris.Record) {
+		req.LogID = "l1"
+		req.Limit = 5
+		read, _, err := ll.QueryRecords(ctx, req)
+		require.Nil(t, err)
+		expected = expected[:min(len(expected), 5)]
+		require.Equal(t, len(expected), len(read), "offset=%d", req.Offset)
+		for i, r := range read {
+			assert.Equal(t, expected[i].ID, r.ID, "offset=%d", req.Offset)
+		}
+	}
+
+	first, second := cis[0].RecordsCount, cis[0].RecordsCount+cis[1].RecordsCount
+	// the offsets inside and on the chunks boundaries
+	for _, off := range []int{0, 1, first - 1, first, first + 1, second, len(recs) - 1, len(recs), len(recs) + 10} {
+		check(storage.QueryRecordsRequest{Offset: int64(off)}, recs[min(off, len(recs)):])
+		check(storage.QueryRecordsRequest{Offset: int64(off), Descending: true}, rev[min(off, len(recs)):])
+		check(storage.QueryRecordsRequest{Offset: int64(off), StartID: recs[3].ID}, recs[min(off+3, len(recs)):])
+		check(storage.QueryRecordsRequest{Offset: int64(off), StartID: recs[3].ID, StartExclusive: true}, recs[min(off+4, len(recs)):])
+		check(storage.QueryRecordsRequest{Offset: int64(off), StartID: recs[len(recs)-4].ID, Descending: true}, rev[min(off+3, len(recs)):])
+	}
+
+	// the matched records are skipped only, so the records are scanned
+	var even []*solaris.Record
+	for i := 0; i < len(recs); i += 2 {
+		even = append(even, recs[i])
+	}
+	for _, off := range []int{0, 3, first / 2, len(even) - 1} {
+		check(storage.QueryRecordsRequest{Offset: int64(off), Condition: "attr('even') = 'true'"}, even[off:])
+	}
+
+	// the ctime condition selects the records by their IDs, so they are counted without the scan
+	cond := fmt.Sprintf("ctime >= '%s'", ulid.Time(ulid.MustParse(recs[first+2].ID).Time()).Format(time.RFC3339Nano))
+	var after []*solaris.Record
+	for _, r := range recs {
+		if ulid.MustParse(r.ID).Time() >= ulid.MustParse(recs[first+2].ID).Time() {
+			after = append(after, r)
+		}
+	}
+	for _, off := range []int{0, 2, cis[1].RecordsCount, len(after)} {
+		check(storage.QueryRecordsRequest{Offset: int64(off), Condition: cond}, after[off:])
+	}
+}
+
+func TestEncryptedRecordsKeyRotation(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestEncryptedRecordsKeyRotation")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	p := testProvider(dir, 1, chunkfs.GetDefaultConfig())
+	defer p.Close()
+
+	ll := NewLocalLog(GetDefaultConfig())
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	ll.Keyring = NewMasterKeyring([]byte("master"))
+	defer ll.Shutdown()
+
+	recs := generateRecords(20, 100)
+	for i, r := range recs {
+		r.Attributes = map[string]string{"secret": fmt.Sprintf("s%d", i%2)}
+	}
+	res, err := ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs[:10], LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), res.Added)
+
+	firstID, err := ll.LMStorage.GetLogKeyID(context.Background(), "l1")
+	assert.Nil(t, err)
+	keyID, err := ll.RotateLogKey(context.Background(), "l1")
+	assert.Nil(t, err)
+	curID, err := ll.LMStorage.GetLogKeyID(context.Background(), "l1")
+	assert.Nil(t, err)
+	assert.Equal(t, keyID, curID)
+	res, err = ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs[10:], LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), res.Added)
+
+	cis, err := ll.LMStorage.GetChunks(context.Background(), "l1")
+	assert.Nil(t, err)
+	require.Equal(t, 2, len(cis))
+	assert.Equal(t, firstID, cis[0].KeyID)
+	assert.NotEqual(t, keyID, cis[0].KeyID)
+	assert.Equal(t, keyID, cis[1].KeyID)
+
+	qrecs, more, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100})
+	assert.Nil(t, err)
+	assert.False(t, more)
+	comparePayloads(t, qrecs, recs)
+
+	qrecs, _, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100,
+		PayloadLen: storage.PayloadLenRange{Min: 100, Max: 100}})
+	assert.Nil(t, err)
+	comparePayloads(t, qrecs, recs)
+
+	// the attributes are decrypted before the records are filtered by them
+	qrecs, _, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100,
+		Condition: "attr('secret') = 's1'"})
+	assert.Nil(t, err)
+	require.Equal(t, 10, len(qrecs))
+	for i, r := range qrecs {
+		assert.Equal(t, recs[2*i+1].Payload, r.Payload)
+		assert.Equal(t, recs[2*i+1].Attributes, r.Attributes)
+	}
+	_, n, _, err := ll.CountRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Condition: "attr('secret') = 's1'"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), n)
+
+	// the payloads and the attributes are not stored in plain
+	rc, err := p.GetOpenedChunk(context.Background(), cis[0].ID, false)
+	require.Nil(t, err)
+	it, err := rc.Value().OpenChunkReader(false)
+	require.Nil(t, err)
+	ur, ok := it.Next()
+	assert.True(t, ok)
+	assert.NotEqual(t, recs[0].Payload, ur.UnsafePayload)
+	assert.Nil(t, ur.Attributes)
+	assert.False(t, bytes.Contains(ur.UnsafePayload, []byte("secret")))
+	it.Close()
+	p.ReleaseChunk(&rc)
+}
+
+func TestQueryRecordsOpenChunkRetries(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestQueryRecordsOpenChunkRetries")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// write the chunk and upload it to the remote storage
+	st := inmem.NewStorage()
+	p := testProvider(dir, 1, chunkfs.GetDefaultConfig())
+	p.Replicator.Storage = st
+	cfg := GetDefaultConfig()
+	cfg.OpenChunkBackoff = time.Millisecond
+	ll := NewLocalLog(cfg)
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	recs := generateRecords(10, 100)
+	_, err = ll.AppendRecords(context.Background(), &solaris.AppendRecordsRequest{Records: recs, LogID: "l1"})
+	assert.Nil(t, err)
+	p.Close()
+	cis, err := ll.LMStorage.GetChunks(context.Background(), "l1")
+	assert.Nil(t, err)
+	require.Nil(t, p.Replicator.UploadChunk(context.Background(), cis[0].ID))
+	require.Nil(t, os.Remove(p.GetFileNameByID(cis[0].ID)))
+
+	// the transient failures are retried
+	fs := &flakyStorage{Storage: st, failures: 2, err: &os.PathError{Op: "open", Path: "zip", Err: syscall.EMFILE}}
+	p = testProvider(dir, 1, chunkfs.GetDefaultConfig())
+	p.Replicator.Storage = fs
+	ll.ChnkProvider = p
+	qrecs, _, err := ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100})
+	assert.Nil(t, err)
+	comparePayloads(t, qrecs, recs)
+	assert.Equal(t, 3, fs.calls)
+	p.Close()
+	require.Nil(t, os.Remove(p.GetFileNameByID(cis[0].ID)))
+
+	// the permanent ones are not
+	fs = &flakyStorage{Storage: st, failures: 2, err: fmt.Errorf("no data: %w", errors.ErrNotExist)}
+	p = testProvider(dir, 1, chunkfs.GetDefaultConfig())
+	p.Replicator.Storage = fs
+	ll.ChnkProvider = p
+	_, _, err = ll.QueryRecords(context.Background(), storage.QueryRecordsRequest{LogID: "l1", Limit: 100})
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	assert.Equal(t, 1, fs.calls)
+	p.Close()
+}
+
+// flakyStorage returns the err for the first failures Get calls
+type flakyStorage struct {
+	sss.Storage
+	failures int
+	calls    int
+	err      error
+}
+
+func (fs *flakyStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	fs.calls++
+	if fs.calls <= fs.failures {
+		return nil, fs.err
+	}
+	return fs.Storage.Get(ctx, key)
+}
+
+func comparePayloads(t *testing.T, a, b []*solaris.Record) {
+	assert.Equal(t, len(a), len(b))
+	for i, v := range a {
+		assert.Equal(t, v.Payload, b[i].Payload)
+	}
+}
+
+func TestAppendRecordsResultRange(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(5, 3000), LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, int64(5), res.Added)
+	assert.Equal(t, int64(5*3000), res.BytesWritten)
+	assert.Empty(t, res.RecordIDs)
+	first, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1})
+	require.Nil(t, err)
+	assert.Equal(t, first[0].ID, res.StartID)
+	last, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1, Descending: true})
+	require.Nil(t, err)
+	assert.Equal(t, last[0].ID, res.LastID)
+
+	// nothing is written, so the fields are empty, but the rejected record
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 3*files.BlockSize), LogID: "l1"})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	assert.Equal(t, &solaris.AppendRecordsResult{Rejected: &solaris.AppendRejected{Count: 1, Reason: solaris.RejectReason_REJECT_REASON_TOO_LARGE}}, res)
+}
+
+func TestAppendRecordsIdempotencyKey(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	results := make([]*solaris.AppendRecordsResult, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(3, 100), LogID: "l1", IdempotencyKey: "k1"})
+			assert.Nil(t, err)
+			results[i] = res
+		}(i)
+	}
+	wg.Wait()
+	for _, res := range results {
+		assert.Equal(t, results[0], res)
+	}
+	assert.Equal(t, int64(3), results[0].Added)
+	assert.Equal(t, int64(300), results[0].BytesWritten)
+	total, _, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), total)
+
+	// the newer key is written
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 100), LogID: "l1", IdempotencyKey: "k2"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), res.Added)
+	assert.NotEqual(t, results[0].StartID, res.StartID)
+
+	// the older key is ignored
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 100), LogID: "l1", IdempotencyKey: "k1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), res.Added)
+
+	// no key, no check
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 100), LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), res.Added)
+	total, _, _, err = ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), total)
+}
+
+func TestAppendRecordsModes(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 3000), LogID: "l1"})
+	assert.Nil(t, err)
+	// the last record is too big for any chunk
+	batch := func() []*solaris.Record {
+		return append(generateRecords(3, 3000), generateRecords(1, 3*files.BlockSize)...)
+	}
+
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch(), LogID: "l1", Mode: solaris.AppendMode_APPEND_MODE_ATOMIC})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	assert.Equal(t, int64(0), res.Added)
+	assert.False(t, res.Partial)
+	total, _, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), total)
+
+	// the log is consistent after the rollback
+	recs := generateRecords(2, 3000)
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: recs, LogID: "l1"})
+	assert.Nil(t, err)
+	res2, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1, Descending: true})
+	assert.Nil(t, err)
+	assert.Equal(t, recs[1].Payload, res2[0].Payload)
+
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch(), LogID: "l1", Mode: solaris.AppendMode_APPEND_MODE_PARTIAL})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), res.Added)
+	assert.True(t, res.Partial)
+	assert.Contains(t, res.PartialError, "the record #3 payload size=12288")
+	assert.Equal(t, int64(1), res.Rejected.Count)
+	assert.Equal(t, solaris.RejectReason_REJECT_REASON_TOO_LARGE, res.Rejected.Reason)
+	total, _, _, err = ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6), total)
+
+	// the default mode is defined by the config
+	ll.cfg.AtomicAppends = true
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch(), LogID: "l1"})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	total, _, _, err = ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6), total)
+}
+
+func TestAppendRecordsTooLarge(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestAppendRecordsTooLarge")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 1, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        2 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	defer p.Close()
+	ll := NewLocalLog(Config{MaxRecordsLimit: 10, MaxBunchSize: 100 * files.BlockSize, MaxLocks: 1})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	countFiles := func() int {
+		n := 0
+		assert.Nil(t, filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				n++
+			}
+			return err
+		}))
+		return n
+	}
+
+	// the record doesn't fit the chunk, so it is rejected before the chunk is created
+	recs := append(generateRecords(1, 100), generateRecords(1, 2*files.BlockSize)...)
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: recs, LogID: "l1", Mode: solaris.AppendMode_APPEND_MODE_ATOMIC})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	assert.Contains(t, err.Error(), fmt.Sprintf("the record #1 size=%d", 2*files.BlockSize+1))
+	assert.Equal(t, int64(0), res.Added)
+	assert.Equal(t, 0, countFiles())
+	_, err = ll.LMStorage.GetLastChunk(ctx, "l1")
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+
+	// nothing is written in the partial mode, but the whole batch is reported as rejected
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: recs[1:], LogID: "l1", Mode: solaris.AppendMode_APPEND_MODE_PARTIAL})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	assert.Equal(t, int64(0), res.Added)
+	assert.Equal(t, &solaris.AppendRejected{Count: 1, Reason: solaris.RejectReason_REJECT_REASON_TOO_LARGE}, res.Rejected)
+	assert.Equal(t, 0, countFiles())
+
+	// the record is too large to be read in a bunch
+	ll.cfg.MaxBunchSize = 1000
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 1001), LogID: "l1"})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	assert.Contains(t, err.Error(), "the record #0 payload size=1001")
+	assert.Equal(t, 0, countFiles())
+}
+
+func TestAppendRecordsTooLargeCompressed(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestAppendRecordsTooLargeCompressed")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 1, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        2 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+		Compression:         chunkfs.CompressionZstd,
+	})
+	defer p.Close()
+	ll := NewLocalLog(Config{MaxRecordsLimit: 10, MaxBunchSize: 100 * files.BlockSize, MaxLocks: 1})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	// the payload is larger than the chunk, but it is compressed well, so it fits
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: []*solaris.Record{{Payload: make([]byte, 4*files.BlockSize)}}, LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), res.Added)
+
+	// the random payload doesn't fit even compressed, it is rejected when it is written
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 4*files.BlockSize), LogID: "l1", Mode: solaris.AppendMode_APPEND_MODE_PARTIAL})
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+	assert.Equal(t, &solaris.AppendRejected{Count: 1, Reason: solaris.RejectReason_REJECT_REASON_TOO_LARGE}, res.Rejected)
+	total, _, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1), total)
+}
+
+func TestAppendRecordsChunksLimit(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+	ll.cfg.MaxChunksPerLog = 5
+	ll.cfg.ChunksSoftLimitPct = 60
+	lms := &countingLogsMetaStorage{testLogsMetaStorage: ll.LMStorage.(*testLogsMetaStorage)}
+	ll.LMStorage = lms
+
+	ctx := context.Background()
+	// every chunk fits 2 records only
+	for i := 1; i <= 5; i++ {
+		res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 3000), LogID: "l1"})
+		require.Nil(t, err)
+		assert.Equal(t, int64(2), res.Added)
+		if i < 3 {
+			assert.Empty(t, res.Warnings)
+		} else {
+			assert.Equal(t, []string{fmt.Sprintf("the log is at %d%% of the chunks quota (%d of 5 chunks)", i*20, i)}, res.Warnings)
+		}
+	}
+
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 3000), LogID: "l1"})
+	assert.True(t, errors.Is(err, errors.ErrExhausted))
+	// the chunks are read once, the appends keep the count then
+	assert.Equal(t, 1, lms.getChunks)
+	total, _, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(10), total)
+
+	// the removed chunks are not counted anymore
+	removed, err := ll.TruncateRecords(ctx, "l1", time.Now().Add(time.Second))
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), removed)
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 3000), LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), res.Added)
+	assert.Empty(t, res.Warnings)
+
+	// other logs are not affected
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 3000), LogID: "l2"})
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), res.Added)
+}
+
+// countingLogsMetaStorage counts the GetChunks calls
+type countingLogsMetaStorage struct {
+	*testLogsMetaStorage
+	getChunks int
+}
+
+func (c *countingLogsMetaStorage) GetChunks(ctx context.Context, logID string) ([]ChunkInfo, error) {
+	c.getChunks++
+	return c.testLogsMetaStorage.GetChunks(ctx, logID)
+}
+
+func TestAppendRecordsRejected(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+	ll.cfg.MaxChunksPerLog = 2
+
+	ctx := context.Background()
+	// every chunk fits 2 records only, so the batch is split and the last records are dropped
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(5, 3000), LogID: "l1", Mode: solaris.AppendMode_APPEND_MODE_PARTIAL})
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), res.Added)
+	assert.True(t, res.Partial)
+	assert.Equal(t, &solaris.AppendRejected{Count: 1, Reason: solaris.RejectReason_REJECT_REASON_EXHAUSTED}, res.Rejected)
+
+	// nothing is written, so the error is returned
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 3000), LogID: "l1", Mode: solaris.AppendMode_APPEND_MODE_PARTIAL})
+	assert.True(t, errors.Is(err, errors.ErrExhausted))
+	assert.Nil(t, res.Rejected)
+
+	// the full writes have no rejected section
+	res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(2, 3000), LogID: "l2", Mode: solaris.AppendMode_APPEND_MODE_PARTIAL})
+	require.Nil(t, err)
+	assert.False(t, res.Partial)
+	assert.Nil(t, res.Rejected)
+}
+
+func TestAppendRecordsFillChunks(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	// the big batch takes most of the chunk, the small batches following it are written into
+	// the same chunk until it is full, so the chunk is not sealed before that
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(5, 1500), LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, int64(5), res.Added)
+	for i := 0; i < 20; i++ {
+		res, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 100), LogID: "l1"})
+		require.Nil(t, err)
+		assert.Equal(t, int64(1), res.Added)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.Len(t, cis, 2)
+	assert.Greater(t, cis[0].RecordsCount, 5)
+	assert.Equal(t, 25, cis[0].RecordsCount+cis[1].RecordsCount)
+
+	// the first chunk has no space for one more small record
+	rc, err := p.GetOpenedChunk(ctx, cis[0].ID, false)
+	require.Nil(t, err)
+	defer p.ReleaseChunk(&rc)
+	arr, err := rc.Value().AppendRecords(generateRecords(1, 100))
+	require.Nil(t, err)
+	assert.Equal(t, 0, arr.Written)
+}
+
+func TestGetRecordByID(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	recs := generateRecords(10, 1000)
+	res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: recs, LogID: "l1", ExpandIDs: true})
+	require.Nil(t, err)
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 1)
+
+	for i, id := range res.RecordIDs {
+		r, err := ll.GetRecordByID(ctx, "l1", id)
+		require.Nil(t, err)
+		assert.Equal(t, id, r.ID)
+		assert.Equal(t, "l1", r.LogID)
+		assert.Equal(t, recs[i].Payload, r.Payload)
+	}
+
+	// the ID is in the chunk range, but there is no such record
+	_, err = ll.GetRecordByID(ctx, "l1", ulidutils.NextID(res.RecordIDs[0]))
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	// the ID is out of the chunks ranges
+	_, err = ll.GetRecordByID(ctx, "l1", ulidutils.NewID())
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	_, err = ll.GetRecordByID(ctx, "l1", ulidutils.PrevID(res.RecordIDs[0]))
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+	_, err = ll.GetRecordByID(ctx, "l1", "wrong ID")
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
+func TestHasRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestHasRecords")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	p := testProvider(dir, 1, chunkfs.GetDefaultConfig())
+	ll := NewLocalLog(GetDefaultConfig())
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	var tms []time.Time
+	for i := 0; i < 3; i++ {
+		time.Sleep(5 * time.Millisecond)
+		res, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 100), LogID: "l1"})
+		require.Nil(t, err)
+		tms = append(tms, ulid.Time(ulid.MustParse(res.StartID).Time()))
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(cis))
+
+	// the chunk is not read, if its first or last record decides
+	p.Close()
+	for _, tc := range []struct {
+		after, before time.Time
+		exp           bool
+	}{
+		{after: tms[0], before: tms[0], exp: true},
+		{after: tms[2], exp: true},
+		{before: tms[0].Add(-time.Millisecond), exp: false},
+		{after: tms[2].Add(time.Millisecond), exp: false},
+		{exp: true},
+	} {
+		ok, err := ll.HasRecords(ctx, "l1", tc.after, tc.before)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.exp, ok)
+	}
+	// the window is between the chunk records, so it is read
+	_, err = ll.HasRecords(ctx, "l1", tms[0].Add(time.Millisecond), tms[1].Add(-time.Millisecond))
+	assert.NotNil(t, err)
+
+	p = testProvider(dir, 1, chunkfs.GetDefaultConfig())
+	defer p.Close()
+	ll.ChnkProvider = p
+	ok, err := ll.HasRecords(ctx, "l1", tms[0].Add(time.Millisecond), tms[1].Add(-time.Millisecond))
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	ok, err = ll.HasRecords(ctx, "l1", tms[1], tms[1])
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestRemovedRecordHashes(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+	rh := &testRecordHashes{}
+	ll.RecordHashes = rh
+
+	ctx := context.Background()
+	// every chunk fits 2 records only
+	batch := generateRecords(6, 3000)
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+	require.Nil(t, err)
+
+	// the records removed from the middle of the log
+	_, err = ll.DeleteRecords(ctx, "l1", batch[2].ID, batch[2].ID)
+	require.Nil(t, err)
+	assert.Equal(t, [][2]string{{batch[2].ID, batch[2].ID}}, rh.deleted)
+	// nothing is removed
+	_, err = ll.DeleteRecords(ctx, "l1", batch[2].ID, batch[2].ID)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(rh.deleted))
+
+	// the records removed from the beginning of the log by whole chunks, and with the chunk rewritten
+	rh.deleted = nil
+	_, err = ll.TrimRecords(ctx, "l1", 3, 0)
+	require.Nil(t, err)
+	assert.Equal(t, [][2]string{{"", batch[1].ID}}, rh.deleted)
+	_, err = ll.TrimRecords(ctx, "l1", 1, 0)
+	require.Nil(t, err)
+	assert.Equal(t, [2]string{"", ulidutils.PrevID(batch[5].ID)}, rh.deleted[1])
+	_, err = ll.TruncateRecords(ctx, "l1", time.Now().Add(time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, [2]string{"", batch[5].ID}, rh.deleted[2])
+}
+
+func TestTruncateRecords(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	// every chunk fits 2 records only
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(4, 3000), LogID: "l1"})
+	require.Nil(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 3000), LogID: "l1"})
+	require.Nil(t, err)
+	time.Sleep(2 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 3000), LogID: "l1"})
+	require.Nil(t, err)
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(cis))
+
+	// the last chunk has records before and after the cutoff, so it is kept
+	removed, err := ll.TruncateRecords(ctx, "l1", cutoff)
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), removed)
+	ncis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(ncis))
+	// the truncated chunks files are deleted
+	for _, ci := range cis[:2] {
+		_, err = os.Stat(p.GetFileNameByID(ci.ID))
+		assert.True(t, errors.Is(err, errors.ErrNotExist))
+	}
+	_, err = os.Stat(p.GetFileNameByID(cis[2].ID))
+	assert.Nil(t, err)
+
+	recs, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 10})
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(recs))
+
+	removed, err = ll.TruncateRecords(ctx, "l1", cutoff)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), removed)
+
+	removed, err = ll.TruncateRecords(ctx, "l1", time.Now().Add(time.Millisecond))
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), removed)
+	total, _, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(0), total)
+}
+
+func TestRepairChunk(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	recs := generateRecords(3, 500)
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: recs, LogID: "l1"})
+	require.Nil(t, err)
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(cis))
+
+	sr, err := ll.RepairChunk(ctx, "l1", cis[0].ID)
+	require.Nil(t, err)
+	assert.False(t, sr.Corrupted())
+
+	// corrupt the second record payload, which follows the 32 bytes header and the first record with its
+	// attributes and checksum
+	f, err := os.OpenFile(p.GetFileNameByID(cis[0].ID), os.O_RDWR, 0)
+	require.Nil(t, err)
+	_, err = f.WriteAt([]byte{0xff, 0xff}, 32+1+500+4+1)
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+
+	sr, err = ll.RepairChunk(ctx, "l1", cis[0].ID)
+	require.Nil(t, err)
+	assert.True(t, sr.Corrupted())
+	assert.Equal(t, 1, sr.Recoverable)
+	ci, err := ll.LMStorage.GetLastChunk(ctx, "l1")
+	require.Nil(t, err)
+	assert.Equal(t, 1, ci.RecordsCount)
+	assert.Equal(t, recs[0].ID, ci.Max.String())
+
+	res, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 10})
+	require.Nil(t, err)
+	comparePayloads(t, recs[:1], res)
+
+	_, err = ll.RepairChunk(ctx, "l1", "unknown")
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+}
+
+func TestQueryRecordsSkipCorrupted(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ll.cfg.MaxRecordsLimit = 100
+	ll.cfg.MaxBunchSize = 100 * files.BlockSize
+	ctx := context.Background()
+	var recs []*solaris.Record
+	for i := 0; i < 3; i++ {
+		batch := generateRecords(5, 1000)
+		_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 2)
+	require.True(t, cis[1].RecordsCount > 2)
+
+	// the tail of the second chunk payloads is lost after its second record, which follows the 32 bytes
+	// header and the first record with its attributes and checksum
+	lost := cis[1].RecordsCount - 1
+	f, err := os.OpenFile(p.GetFileNameByID(cis[1].ID), os.O_RDWR, 0)
+	require.Nil(t, err)
+	_, err = f.WriteAt(make([]byte, lost*1005), 32+1005)
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+	first := cis[0].RecordsCount
+	expected := append(slices.Clone(recs[:first+1]), recs[first+1+lost:]...)
+
+	_, _, err = ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 100})
+	assert.True(t, errors.Is(err, errors.ErrCorrupted))
+
+	res, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 100, SkipCorrupted: true})
+	require.Nil(t, err)
+	comparePayloads(t, expected, res)
+
+	// the pages skip the corrupted records as well
+	res = nil
+	q := storage.QueryRecordsRequest{LogID: "l1", Limit: 3, SkipCorrupted: true, StartExclusive: true}
+	for {
+		page, more, err := ll.QueryRecords(ctx, q)
+		require.Nil(t, err)
+		res = append(res, page...)
+		if !more {
+			break
+		}
+		q.StartID = page[len(page)-1].ID
+	}
+	comparePayloads(t, expected, res)
+}
+
+func TestRecordsSequences(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	// the records written before the sequences are enabled are not numbered
+	_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(3, 1000), LogID: "l1"})
+	require.Nil(t, err)
+	ll.cfg.Sequences = true
+	var recs []*solaris.Record
+	for i := 0; i < 3; i++ {
+		batch := generateRecords(10, 1000)
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 4)
+
+	// the numbers are dense across the chunks and the reading is resumed from the next number
+	seq := int64(1)
+	var read []*solaris.Record
+	for {
+		res, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartSeq: seq, Limit: 4})
+		require.Nil(t, err)
+		if len(res) == 0 {
+			break
+		}
+		for _, r := range res {
+			require.Equal(t, seq, r.Seq)
+			seq++
+		}
+		read = append(read, res...)
+	}
+	require.Equal(t, len(recs), len(read))
+	for i := range recs {
+		assert.Equal(t, recs[i].Payload, read[i].Payload)
+	}
+
+	res, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartSeq: 15, Descending: true, Limit: 3})
+	require.Nil(t, err)
+	require.Equal(t, 3, len(res))
+	assert.Equal(t, []int64{15, 14, 13}, []int64{res[0].Seq, res[1].Seq, res[2].Seq})
+	// the descending read goes to the not numbered records after the first numbered one
+	res, _, err = ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartSeq: 1, Descending: true, Limit: 10})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(res))
+	assert.Equal(t, int64(1), res[0].Seq)
+	assert.Equal(t, int64(0), res[1].Seq)
+
+	_, count, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartSeq: 21})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(10), count)
+	_, count, _, err = ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartSeq: 31})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(0), count)
+
+	// the numbering is continued after the log is truncated, even if the sequences are disabled
+	ll.cfg.Sequences = false
+	removed, err := ll.TruncateRecords(ctx, "l1", time.Now().Add(time.Millisecond))
+	require.Nil(t, err)
+	assert.True(t, removed > 0)
+	ar, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(1, 1000), LogID: "l1", ExpandIDs: true})
+	require.Nil(t, err)
+	r, err := ll.GetRecordByID(ctx, "l1", ar.RecordIDs[0])
+	require.Nil(t, err)
+	assert.Equal(t, int64(31), r.Seq)
+
+	// the truncated numbers are skipped
+	res, _, err = ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", StartSeq: 1, Limit: 1})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(res))
+	assert.True(t, res[0].Seq > 1)
+}
+
+func TestTrimRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestTrimRecords")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 10, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        4 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	defer p.Close()
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10, Sequences: true})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 5; i++ {
+		batch := generateRecords(10, 1000)
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 3)
+
+	n, err := ll.TrimRecords(ctx, "l1", 100, 0)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), n)
+
+	checkLast := func(cnt int) {
+		read, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1000})
+		require.Nil(t, err)
+		require.Equal(t, cnt, len(read))
+		for i, r := range read {
+			exp := recs[len(recs)-cnt+i]
+			assert.Equal(t, exp.ID, r.ID)
+			assert.Equal(t, exp.Payload, r.Payload)
+			assert.Equal(t, int64(len(recs)-cnt+i+1), r.Seq)
+		}
+		total, _, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: "l1"})
+		require.Nil(t, err)
+		assert.Equal(t, uint64(cnt), total)
+	}
+
+	// the first chunk is read at the time, so its file is deleted when it is released
+	rc, err := p.GetOpenedChunk(ctx, cis[0].ID, false)
+	require.Nil(t, err)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		p.ReleaseChunk(&rc)
+	}()
+	n, err = ll.TrimRecords(ctx, "l1", 23, 0)
+	require.Nil(t, err)
+	assert.Equal(t, int64(len(recs)-23), n)
+	checkLast(23)
+	ncis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+
+	// the slack allows to remove the whole chunks only
+	n, err = ll.TrimRecords(ctx, "l1", 23-int64(ncis[0].RecordsCount)-1, int64(ncis[0].RecordsCount))
+	require.Nil(t, err)
+	assert.Equal(t, int64(ncis[0].RecordsCount), n)
+	checkLast(23 - ncis[0].RecordsCount)
+	scis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	assert.Equal(t, ncis[1:], scis)
+	_, err = os.Stat(p.GetFileNameByID(cis[0].ID))
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+
+	// the last chunk is rewritten, and the appends are continued
+	n, err = ll.TrimRecords(ctx, "l1", 1, 0)
+	require.Nil(t, err)
+	assert.Equal(t, int64(22-ncis[0].RecordsCount), n)
+	checkLast(1)
+	batch := generateRecords(10, 1000)
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+	require.Nil(t, err)
+	recs = append(recs, batch...)
+	checkLast(11)
+}
+
+func TestDeleteRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestDeleteRecords")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 10, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        4 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	defer p.Close()
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10, Sequences: true})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 5; i++ {
+		batch := generateRecords(10, 1000)
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	for i, r := range recs {
+		r.Seq = int64(i + 1)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 3)
+
+	check := func(expected []*solaris.Record) {
+		read, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1000})
+		require.Nil(t, err)
+		require.Equal(t, len(expected), len(read))
+		for i, r := range read {
+			assert.Equal(t, expected[i].ID, r.ID)
+			assert.Equal(t, expected[i].Payload, r.Payload)
+			assert.Equal(t, expected[i].Seq, r.Seq)
+		}
+	}
+
+	// the range covers the whole chunk and the parts of its neighbours
+	n, err := ll.DeleteRecords(ctx, "l1", recs[5].ID, recs[30].ID)
+	require.Nil(t, err)
+	assert.Equal(t, int64(26), n)
+	expected := append(slices.Clone(recs[:5]), recs[31:]...)
+	check(expected)
+	for _, ci := range cis[1 : len(cis)-1] {
+		if ci.Min.String() >= recs[5].ID && ci.Max.String() <= recs[30].ID {
+			_, err = os.Stat(p.GetFileNameByID(ci.ID))
+			assert.True(t, errors.Is(err, errors.ErrNotExist))
+		}
+	}
+	_, err = ll.GetRecordByID(ctx, "l1", recs[10].ID)
+	assert.True(t, errors.Is(err, errors.ErrNotExist))
+
+	// the range is already deleted
+	n, err = ll.DeleteRecords(ctx, "l1", recs[5].ID, recs[30].ID)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), n)
+	check(expected)
+
+	// the last record is kept, so the appends continue the log sequence
+	n, err = ll.DeleteRecords(ctx, "l1", recs[40].ID, "")
+	require.Nil(t, err)
+	assert.Equal(t, int64(9), n)
+	expected = append(expected[:len(expected)-10], recs[49])
+	check(expected)
+	batch := generateRecords(2, 1000)
+	_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+	require.Nil(t, err)
+	batch[0].Seq, batch[1].Seq = 51, 52
+	expected = append(expected, batch...)
+	check(expected)
+
+	n, err = ll.DeleteRecords(ctx, "l1", "", recs[2].ID)
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), n)
+	check(expected[3:])
+
+	_, err = ll.DeleteRecords(ctx, "l1", "wrong", "")
+	assert.True(t, errors.Is(err, errors.ErrInvalid))
+}
+
+func TestCompactLogAppends(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestCompactLogAppends")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 10, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        2 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10, Sequences: true})
+	lms := &blockingMetaStorage{testLogsMetaStorage: newTestLogsMetaStorage()}
+	ll.LMStorage = lms
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 5; i++ {
+		batch := generateRecords(10, 1000)
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	cis, err := lms.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 5)
+
+	// the compaction is disabled
+	n, err := ll.CompactLog(ctx, "l1")
+	require.Nil(t, err)
+	assert.Equal(t, 0, n)
+
+	// the chunks are bigger now, so the existing ones may be merged
+	p.Close()
+	p = testProvider(dir, 10, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        16 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	defer p.Close()
+	ll.ChnkProvider = p
+	ll.cfg.MaxChunkSize = 16 * files.BlockSize
+
+	// the compaction is paused after it reads the chunks, the appends must not wait for it
+	lms.block = make(chan struct{})
+	lms.blocked = make(chan struct{}, 1)
+	type compactRes struct {
+		n   int
+		err error
+	}
+	resCh := make(chan compactRes, 1)
+	go func() {
+		n, err := ll.CompactLog(ctx, "l1")
+		resCh <- compactRes{n: n, err: err}
+	}()
+	<-lms.blocked
+	for i := 0; i < 5; i++ {
+		batch := generateRecords(10, 1000)
+		start := time.Now()
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+		recs = append(recs, batch...)
+	}
+	close(lms.block)
+	res := <-resCh
+	require.Nil(t, res.err)
+	assert.True(t, res.n > 0)
+
+	// the records, their order and numbers are kept
+	ncis, err := lms.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	total := 0
+	for i, ci := range ncis {
+		total += ci.RecordsCount
+		if i > 0 {
+			assert.True(t, ncis[i-1].Max.Compare(ci.Min) < 0)
+		}
+	}
+	assert.Equal(t, len(recs), total)
+	read, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1000})
+	require.Nil(t, err)
+	require.Equal(t, len(recs), len(read))
+	for i, r := range read {
+		assert.Equal(t, recs[i].ID, r.ID)
+		assert.Equal(t, recs[i].Payload, r.Payload)
+		assert.Equal(t, int64(i+1), r.Seq)
+	}
+
+	// nothing to compact anymore
+	n, err = ll.CompactLog(ctx, "l1")
+	require.Nil(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestCompactLog(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestCompactLog")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := testProvider(dir, 10, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        2 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10, Sequences: true})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 5; i++ {
+		batch := generateRecords(10, 1000)
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 5)
+
+	// the compaction is disabled
+	n, err := ll.CompactLog(ctx, "l1")
+	require.Nil(t, err)
+	assert.Equal(t, 0, n)
+
+	// the chunks are bigger now, so the existing ones may be merged
+	p.Close()
+	p = testProvider(dir, 10, chunkfs.Config{
+		NewSize:             files.BlockSize,
+		MaxChunkSize:        16 * files.BlockSize,
+		MaxGrowIncreaseSize: files.BlockSize,
+	})
+	defer p.Close()
+	ll.ChnkProvider = p
+	ll.cfg.MaxChunkSize = 16 * files.BlockSize
+	ll.cfg.CompactMaxChunks = 3
+
+	// the first chunk is read at the time, so its file is deleted when it is released
+	rc, err := p.GetOpenedChunk(ctx, cis[0].ID, false)
+	require.Nil(t, err)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		p.ReleaseChunk(&rc)
+	}()
+	n, err = ll.CompactLog(ctx, "l1")
+	require.Nil(t, err)
+	assert.Equal(t, 2, n)
+	for _, ci := range cis[:3] {
+		_, err = os.Stat(p.GetFileNameByID(ci.ID))
+		assert.True(t, errors.Is(err, errors.ErrNotExist))
+	}
+	_, err = os.Stat(p.GetFileNameByID(cis[3].ID))
+	assert.Nil(t, err)
+
+	// the log is compacted incrementally
+	for n > 0 {
+		n, err = ll.CompactLog(ctx, "l1")
+		require.Nil(t, err)
+	}
+	ncis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	assert.True(t, len(ncis) < len(cis))
+
+	// the records, their order and numbers are kept
+	read, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1000})
+	require.Nil(t, err)
+	require.Equal(t, len(recs), len(read))
+	for i, r := range read {
+		assert.Equal(t, recs[i].ID, r.ID)
+		assert.Equal(t, recs[i].Payload, r.Payload)
+		assert.Equal(t, int64(i+1), r.Seq)
+	}
+}
+
+func TestSizeCompactionRuns(t *testing.T) {
+	cis := []ChunkInfo{
+		{ID: "1", RecordsCount: 5},
+		{ID: "2", RecordsCount: 5},
+		{ID: "3", RecordsCount: 5},
+		{ID: "4", RecordsCount: 5},
+		{ID: "5", RecordsCount: 5},
+		{ID: "6", RecordsCount: 5, KeyID: "k"},
+		{ID: "7", RecordsCount: 5, KeyID: "k"},
+		{ID: "8", RecordsCount: 5, KeyID: "k"},
+	}
+	sizes := []int64{10, 50, 10, 10, -1, 10, 10, 10}
+	assert.Equal(t, []idxRange{{start: 2, end: 4}, {start: 5, end: 7}}, sizeCompactionRuns(cis, sizes, 40, 0))
+	assert.Equal(t, []idxRange{{start: 0, end: 4}, {start: 5, end: 7}}, sizeCompactionRuns(cis, sizes, 100, 0))
+	assert.Equal(t, []idxRange{{start: 0, end: 3}}, sizeCompactionRuns(cis, sizes, 100, 3))
+	assert.Nil(t, sizeCompactionRuns(cis, sizes, 10, 0))
+}
+
+// blockingMetaStorage blocks the GetChunks calls until the block channel is closed, if it is set
+type blockingMetaStorage struct {
+	*testLogsMetaStorage
+	block   chan struct{}
+	blocked chan struct{}
+}
+
+func (bms *blockingMetaStorage) GetChunks(ctx context.Context, logID string) ([]ChunkInfo, error) {
+	cis, err := bms.testLogsMetaStorage.GetChunks(ctx, logID)
+	if bms.block != nil {
+		select {
+		case bms.blocked <- struct{}{}:
+		default:
+		}
+		<-bms.block
 	}
+	return cis, err
 }
 
 func generateRecords(count, size int) []*solaris.Record {
@@ -447,8 +2521,9 @@ func generateRecords(count, size int) []*solaris.Record {
 func testProvider(dir string, maxOpenedChunks int, cfg chunkfs.Config) *chunkfs.Provider {
 	p := chunkfs.NewProvider(dir, maxOpenedChunks, cfg)
 	p.CA = chunkfs.NewChunkAccessor()
-	p.Replicator = chunkfs.NewReplicator(p.GetFileNameByID)
+	p.Replicator = chunkfs.NewReplicator(p.GetFileNameByID, chunkfs.GetDefaultReplicatorConfig())
 	p.Replicator.CA = p.CA
+	p.Replicator.Storage = inmem.NewStorage()
 	return p
 }
 
@@ -0,0 +1,161 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logfstest contains the tests shared by the logfs.LogsMetaStorage implementations
+package logfstest
+
+import (
+	"context"
+	"github.com/oklog/ulid/v2"
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/ulidutils"
+	"github.com/solarisdb/solaris/pkg/storage/logfs"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// logCreator is implemented by the storages, which keep the chunks of the existing logs only
+type logCreator interface {
+	CreateLog(ctx context.Context, log *solaris.Log) (*solaris.Log, error)
+}
+
+// RunLogsMetaStorageConformance runs the tests checking the LogsMetaStorage contract, so all the
+// implementations behave the same way. The factory is called for every test, the tests use their own
+// logs, so the storage returned doesn't have to be empty. If the storage keeps the chunks of the existing
+// logs only, it must implement CreateLog (see storage.Logs), so the tests create the logs first.
+func RunLogsMetaStorageConformance(t *testing.T, factory func() logfs.LogsMetaStorage) {
+	t.Run("UpsertChunkInfosOrder", func(t *testing.T) {
+		ctx := context.Background()
+		lms := factory()
+		logID := conformanceLog(t, lms)
+
+		// the chunks are upserted in the random order by several calls
+		ids := conformanceChunkIDs(30)
+		shuffled := slices.Clone(ids)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		for i := 0; i < len(shuffled); i += 10 {
+			var cis []logfs.ChunkInfo
+			for _, id := range shuffled[i : i+10] {
+				cis = append(cis, logfs.ChunkInfo{ID: id})
+			}
+			assert.Nil(t, lms.UpsertChunkInfos(ctx, logID, cis))
+		}
+
+		cis, err := lms.GetChunks(ctx, logID)
+		assert.Nil(t, err)
+		assert.Equal(t, ids, chunkIDs(cis), "GetChunks must return the chunks in the ascending order of IDs")
+	})
+
+	t.Run("GetLastChunk", func(t *testing.T) {
+		ctx := context.Background()
+		lms := factory()
+		logID := conformanceLog(t, lms)
+
+		ids := conformanceChunkIDs(10)
+		var last string
+		for _, i := range rand.Perm(len(ids)) {
+			assert.Nil(t, lms.UpsertChunkInfos(ctx, logID, []logfs.ChunkInfo{{ID: ids[i]}}))
+			last = max(last, ids[i])
+			ci, err := lms.GetLastChunk(ctx, logID)
+			assert.Nil(t, err)
+			assert.Equal(t, last, ci.ID, "GetLastChunk must return the chunk with the biggest ID")
+		}
+	})
+
+	t.Run("EmptyLog", func(t *testing.T) {
+		ctx := context.Background()
+		lms := factory()
+		logID := conformanceLog(t, lms)
+
+		_, err := lms.GetLastChunk(ctx, logID)
+		assert.True(t, errors.Is(err, errors.ErrNotExist), "GetLastChunk of the log without chunks: %v", err)
+		_, err = lms.GetLastChunk(ctx, ulidutils.NewID())
+		assert.True(t, errors.Is(err, errors.ErrNotExist), "GetLastChunk of the unknown log: %v", err)
+
+		// the log without chunks either has no chunks or is not known yet
+		cis, err := lms.GetChunks(ctx, logID)
+		assert.True(t, err == nil || errors.Is(err, errors.ErrNotExist), "GetChunks of the log without chunks: %v", err)
+		assert.Empty(t, cis)
+
+		// the chunks are deleted
+		ids := conformanceChunkIDs(3)
+		assert.Nil(t, lms.UpsertChunkInfos(ctx, logID, []logfs.ChunkInfo{{ID: ids[0]}, {ID: ids[1]}, {ID: ids[2]}}))
+		assert.Nil(t, lms.DeleteChunkInfos(ctx, logID, ids))
+		cis, err = lms.GetChunks(ctx, logID)
+		assert.Nil(t, err)
+		assert.Empty(t, cis)
+		_, err = lms.GetLastChunk(ctx, logID)
+		assert.True(t, errors.Is(err, errors.ErrNotExist), "GetLastChunk of the log with the chunks deleted: %v", err)
+	})
+
+	t.Run("ReupsertChunkInfos", func(t *testing.T) {
+		ctx := context.Background()
+		lms := factory()
+		logID := conformanceLog(t, lms)
+
+		var cis []logfs.ChunkInfo
+		for _, id := range conformanceChunkIDs(3) {
+			cis = append(cis, logfs.ChunkInfo{ID: id, Min: ulid.Make(), Max: ulid.Make(), RecordsCount: 10, KeyID: "k1", FirstSeq: 1})
+		}
+		assert.Nil(t, lms.UpsertChunkInfos(ctx, logID, slices.Clone(cis)))
+		assert.Nil(t, lms.UpsertChunkInfos(ctx, logID, slices.Clone(cis)))
+		res, err := lms.GetChunks(ctx, logID)
+		assert.Nil(t, err)
+		assert.Equal(t, cis, res, "the same chunks upserted again must not be duplicated")
+
+		// the chunk is updated
+		cis[1].RecordsCount = 20
+		cis[1].Max = ulid.Make()
+		assert.Nil(t, lms.UpsertChunkInfos(ctx, logID, []logfs.ChunkInfo{cis[1]}))
+		res, err = lms.GetChunks(ctx, logID)
+		assert.Nil(t, err)
+		assert.Equal(t, cis, res)
+		ci, err := lms.GetLastChunk(ctx, logID)
+		assert.Nil(t, err)
+		assert.Equal(t, cis[2], ci)
+	})
+}
+
+func conformanceLog(t *testing.T, lms logfs.LogsMetaStorage) string {
+	lc, ok := lms.(logCreator)
+	if !ok {
+		return ulidutils.NewID()
+	}
+	log, err := lc.CreateLog(context.Background(), &solaris.Log{})
+	if err != nil {
+		t.Fatalf("could not create the log: %v", err)
+	}
+	return log.ID
+}
+
+// conformanceChunkIDs returns n chunk IDs in the ascending order
+func conformanceChunkIDs(n int) []string {
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		ids = append(ids, ulidutils.NewID())
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func chunkIDs(cis []logfs.ChunkInfo) []string {
+	ids := make([]string, 0, len(cis))
+	for _, ci := range cis {
+		ids = append(ids, ci.ID)
+	}
+	return ids
+}
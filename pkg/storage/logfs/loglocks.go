@@ -0,0 +1,78 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"sync"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+)
+
+// logLocks keeps the logLocker of every log, which is used at the moment. The number of the lockers is not
+// limited, so the logs modifications never wait for the modifications of other logs. The logLocker is dropped
+// as soon as it is released by all the users.
+type logLocks struct {
+	lock    sync.Mutex
+	lockers map[string]*logLocker
+	closed  bool
+}
+
+func newLogLocks() *logLocks {
+	return &logLocks{lockers: make(map[string]*logLocker)}
+}
+
+// acquire returns the logLocker for the log ID, the logLocker must be released by the release() call.
+// The function returns errors.ErrClosed if the logLocks is closed.
+func (ls *logLocks) acquire(lid string) (*logLocker, error) {
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+	if ls.closed {
+		return nil, errors.ErrClosed
+	}
+	ll, ok := ls.lockers[lid]
+	if !ok {
+		ll = &logLocker{}
+		ls.lockers[lid] = ll
+	}
+	ll.refs++
+	return ll, nil
+}
+
+// release returns the logLocker acquired for the log ID before
+func (ls *logLocks) release(lid string) {
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+	ll, ok := ls.lockers[lid]
+	if !ok {
+		return
+	}
+	if ll.refs--; ll.refs <= 0 {
+		delete(ls.lockers, lid)
+	}
+}
+
+// close makes the logLocks not to return the logLockers anymore, the acquired ones stay valid until released
+func (ls *logLocks) close() {
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+	ls.closed = true
+}
+
+// size returns the number of the logLockers used at the moment
+func (ls *logLocks) size() int {
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+	return len(ls.lockers)
+}
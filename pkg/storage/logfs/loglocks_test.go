@@ -0,0 +1,52 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"testing"
+
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLocks(t *testing.T) {
+	ls := newLogLocks()
+	l1, err := ls.acquire("l1")
+	assert.Nil(t, err)
+	l2, err := ls.acquire("l2")
+	assert.Nil(t, err)
+	assert.NotSame(t, l1, l2)
+	l11, err := ls.acquire("l1")
+	assert.Nil(t, err)
+	assert.Same(t, l1, l11)
+	assert.Equal(t, 2, ls.size())
+
+	// the different logs are locked independently
+	l1.lock.Lock()
+	assert.True(t, l2.lock.TryLock())
+	l2.lock.Unlock()
+	l1.lock.Unlock()
+
+	ls.release("l1")
+	assert.Equal(t, 2, ls.size())
+	ls.release("l1")
+	ls.release("l2")
+	assert.Equal(t, 0, ls.size())
+	ls.release("l3")
+
+	ls.close()
+	_, err = ls.acquire("l1")
+	assert.True(t, errors.Is(err, errors.ErrClosed))
+}
@@ -0,0 +1,284 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/logrange/linker"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/logging"
+	"github.com/solarisdb/solaris/pkg/storage"
+)
+
+type (
+	// MigratorConfig defines the settings for the Migrator
+	MigratorConfig struct {
+		// Workers defines how many logs may be migrated in parallel. Zero value disables the migration.
+		Workers int
+		// ChunksPerSecond limits the number of chunks rewritten per second by all the workers.
+		// Zero value means no limit.
+		ChunksPerSecond int
+		// Interval defines the pause between the passes over all the logs
+		Interval time.Duration
+		// StateFile is the file, where the migration position is kept, so the pass interrupted by
+		// the server restart is resumed from the position
+		StateFile string
+	}
+
+	// Migrator rewrites the sealed chunks of all the logs, which are written in an outdated format, into
+	// the chunks of the current format. So, when the chunks format (or the chunks compression) is changed,
+	// the whole data set converges to the new format over time. The logs are passed in the order of their IDs,
+	// the position is stored after every bunch of logs, so the migration is resumed after the restart.
+	Migrator struct {
+		Logs     storage.Logs `inject:""`
+		LocalLog *localLog    `inject:""`
+
+		cfg    MigratorConfig
+		logger logging.Logger
+		ctx    context.Context
+		cancel context.CancelFunc
+		wg     sync.WaitGroup
+		ticker *time.Ticker
+
+		lock   sync.Mutex
+		status MigrationStatus
+	}
+
+	// MigrationStatus describes the Migrator progress
+	MigrationStatus struct {
+		// Enabled is true if the migration is turned on by the config
+		Enabled bool
+		// Running is true if a pass over the logs is in progress
+		Running bool
+		// NextLogID is the ID of the log the current pass continues after
+		NextLogID string
+		// Migrated is the number of chunks migrated by the current (or the last) pass
+		Migrated int64
+		// FailedLogs is the number of logs, which could not be migrated by the current (or the last) pass
+		FailedLogs int64
+		// FinishedAt is the time the last pass was finished
+		FinishedAt time.Time
+	}
+
+	migrationState struct {
+		NextLogID string `json:"nextLogID"`
+	}
+)
+
+// cMigrationPageSize is the number of the logs the migration position is stored after
+const cMigrationPageSize = 100
+
+var _ linker.Initializer = (*Migrator)(nil)
+var _ linker.Shutdowner = (*Migrator)(nil)
+
+// GetDefaultMigratorConfig returns the default MigratorConfig
+func GetDefaultMigratorConfig() MigratorConfig {
+	return MigratorConfig{
+		Workers:         0, // the migration is off
+		ChunksPerSecond: 10,
+		Interval:        time.Hour,
+		StateFile:       "migration.json",
+	}
+}
+
+// NewMigrator creates the new Migrator
+func NewMigrator(cfg MigratorConfig) *Migrator {
+	m := &Migrator{cfg: cfg, logger: logging.NewLogger("logfs.Migrator")}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	return m
+}
+
+// String implements fmt.Stringer
+func (mc MigratorConfig) String() string {
+	b, _ := json.MarshalIndent(mc, "", "  ")
+	return string(b)
+}
+
+// Init implements linker.Initializer
+func (m *Migrator) Init(_ context.Context) error {
+	m.logger.Infof("initializing cfg:\n%s", m.cfg)
+	if m.cfg.Workers <= 0 {
+		m.logger.Infof("the number of Workers in the config is zero or negative, the chunks will not be migrated")
+		return nil
+	}
+	if m.cfg.Interval <= 0 {
+		return fmt.Errorf("the Interval=%s must be positive: %w", m.cfg.Interval, errors.ErrInvalid)
+	}
+	if m.cfg.ChunksPerSecond > 0 {
+		m.ticker = time.NewTicker(time.Second / time.Duration(m.cfg.ChunksPerSecond))
+	}
+	m.lock.Lock()
+	m.status.Enabled = true
+	m.lock.Unlock()
+	m.wg.Add(1)
+	go m.watcher()
+	return nil
+}
+
+// Shutdown implements linker.Shutdowner
+func (m *Migrator) Shutdown() {
+	m.cancel()
+	m.wg.Wait()
+	if m.ticker != nil {
+		m.ticker.Stop()
+	}
+}
+
+// Status returns the current migration status
+func (m *Migrator) Status() MigrationStatus {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.status
+}
+
+func (m *Migrator) watcher() {
+	m.logger.Infof("starting watcher()")
+	defer m.logger.Infof("exiting from watcher()")
+	defer m.wg.Done()
+	for {
+		if err := m.migrate(m.ctx); err != nil && m.ctx.Err() == nil {
+			m.logger.Warnf("the migration pass is interrupted: %v", err)
+		}
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(m.cfg.Interval):
+		}
+	}
+}
+
+// migrate makes one pass over the logs starting from the stored position
+func (m *Migrator) migrate(ctx context.Context) error {
+	st, err := m.readState()
+	if err != nil {
+		m.logger.Warnf("could not read the migration state from %s, starting from the beginning: %v", m.cfg.StateFile, err)
+	}
+	m.updateStatus(func(s *MigrationStatus) {
+		s.Running = true
+		s.NextLogID = st.NextLogID
+		if st.NextLogID == "" {
+			s.Migrated = 0
+			s.FailedLogs = 0
+		}
+	})
+	defer m.updateStatus(func(s *MigrationStatus) {
+		s.Running = false
+	})
+
+	m.logger.Infof("starting the migration pass from logID=%q", st.NextLogID)
+	for {
+		res, err := m.Logs.QueryLogs(ctx, storage.QueryLogsRequest{Page: st.NextLogID, Limit: cMigrationPageSize})
+		if err != nil {
+			return err
+		}
+		ids := make(chan string)
+		var wg sync.WaitGroup
+		for i := 0; i < m.cfg.Workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for lid := range ids {
+					m.migrateLog(ctx, lid)
+				}
+			}()
+		}
+		for _, l := range res.Logs {
+			ids <- l.ID
+		}
+		close(ids)
+		wg.Wait()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		st.NextLogID = res.NextPageID
+		if err := m.writeState(st); err != nil {
+			m.logger.Warnf("could not write the migration state to %s: %v", m.cfg.StateFile, err)
+		}
+		m.updateStatus(func(s *MigrationStatus) {
+			s.NextLogID = st.NextLogID
+		})
+		if st.NextLogID == "" {
+			break
+		}
+	}
+	status := m.updateStatus(func(s *MigrationStatus) {
+		s.FinishedAt = time.Now()
+	})
+	m.logger.Infof("the migration pass is finished, %d chunks migrated, %d logs failed", status.Migrated, status.FailedLogs)
+	return nil
+}
+
+func (m *Migrator) migrateLog(ctx context.Context, lid string) {
+	n, err := m.LocalLog.migrateLog(ctx, lid, m.throttle)
+	// the log could be deleted in the meantime
+	failed := err != nil && ctx.Err() == nil && !errors.Is(err, errors.ErrNotExist)
+	if failed {
+		m.logger.Warnf("could not migrate logID=%s: %v", lid, err)
+	}
+	m.updateStatus(func(s *MigrationStatus) {
+		s.Migrated += int64(n)
+		if failed {
+			s.FailedLogs++
+		}
+	})
+}
+
+// throttle waits until the next chunk may be migrated
+func (m *Migrator) throttle(ctx context.Context) error {
+	if m.ticker == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-m.ticker.C:
+		return nil
+	}
+}
+
+func (m *Migrator) updateStatus(f func(s *MigrationStatus)) MigrationStatus {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	f(&m.status)
+	return m.status
+}
+
+func (m *Migrator) readState() (migrationState, error) {
+	var st migrationState
+	buf, err := os.ReadFile(m.cfg.StateFile)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotExist) {
+			return st, nil
+		}
+		return st, err
+	}
+	err = json.Unmarshal(buf, &st)
+	return st, err
+}
+
+func (m *Migrator) writeState(st migrationState) error {
+	buf, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.cfg.StateFile, buf, 0640)
+}
@@ -0,0 +1,243 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/files"
+	"github.com/solarisdb/solaris/pkg/storage"
+	"github.com/solarisdb/solaris/pkg/storage/chunkfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLogs lists the logs of the testLogsMetaStorage
+type testLogs struct {
+	storage.Logs
+	lms *testLogsMetaStorage
+}
+
+func (tl testLogs) QueryLogs(_ context.Context, qr storage.QueryLogsRequest) (*solaris.QueryLogsResult, error) {
+	tl.lms.lock.Lock()
+	var ids []string
+	for id := range tl.lms.logs {
+		ids = append(ids, id)
+	}
+	tl.lms.lock.Unlock()
+	sort.Strings(ids)
+
+	res := &solaris.QueryLogsResult{Total: int64(len(ids))}
+	for _, id := range ids {
+		if id <= qr.Page {
+			continue
+		}
+		if len(res.Logs) == int(qr.Limit) {
+			res.NextPageID = res.Logs[len(res.Logs)-1].ID
+			break
+		}
+		res.Logs = append(res.Logs, &solaris.Log{ID: id})
+	}
+	return res, nil
+}
+
+func TestMigrator(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestMigrator")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	cfg := chunkfs.Config{NewSize: files.BlockSize, MaxChunkSize: 2 * files.BlockSize, MaxGrowIncreaseSize: files.BlockSize}
+	p := testProvider(dir, 10, cfg)
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10, Sequences: true})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	lids := []string{"l1", "l2", "l3"}
+	recs := map[string][]*solaris.Record{}
+	for _, lid := range lids {
+		for i := 0; i < 3; i++ {
+			batch := generateRecords(10, 1000)
+			_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: lid})
+			require.Nil(t, err)
+			recs[lid] = append(recs[lid], batch...)
+		}
+	}
+
+	// the compression is turned on, so the existing chunks are in the outdated format now
+	p.Close()
+	cfg.Compression = chunkfs.CompressionZstd
+	p = testProvider(dir, 10, cfg)
+	defer p.Close()
+	ll.ChnkProvider = p
+
+	outdated := func(lid string) int {
+		cis, err := ll.LMStorage.GetChunks(ctx, lid)
+		require.Nil(t, err)
+		require.True(t, len(cis) > 2)
+		res := 0
+		for _, ci := range cis[:len(cis)-1] {
+			o, err := ll.isOutdated(ci.ID)
+			require.Nil(t, err)
+			if o {
+				res++
+			}
+		}
+		return res
+	}
+	checkRecords := func(lid string) {
+		read, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: lid, Limit: 1000})
+		assert.Nil(t, err)
+		if !assert.Equal(t, len(recs[lid]), len(read)) {
+			return
+		}
+		for i, r := range read {
+			assert.Equal(t, recs[lid][i].ID, r.ID)
+			assert.Equal(t, recs[lid][i].Payload, r.Payload)
+			assert.Equal(t, int64(i+1), r.Seq)
+		}
+	}
+	for _, lid := range lids {
+		assert.True(t, outdated(lid) > 0)
+	}
+
+	m := NewMigrator(MigratorConfig{Workers: 2, StateFile: filepath.Join(dir, "migration.json")})
+	m.Logs = testLogs{lms: ll.LMStorage.(*testLogsMetaStorage)}
+	m.LocalLog = ll
+
+	// the records are read while the logs are migrated
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for _, lid := range lids {
+				checkRecords(lid)
+			}
+		}
+	}()
+
+	// the interrupted pass is resumed after the stored position
+	require.Nil(t, m.writeState(migrationState{NextLogID: "l1"}))
+	require.Nil(t, m.migrate(ctx))
+	assert.True(t, outdated("l1") > 0)
+	assert.Equal(t, 0, outdated("l2"))
+	assert.Equal(t, 0, outdated("l3"))
+	st := m.Status()
+	assert.False(t, st.Running)
+	assert.Equal(t, "", st.NextLogID)
+	assert.True(t, st.Migrated > 0)
+	assert.Equal(t, int64(0), st.FailedLogs)
+	assert.False(t, st.FinishedAt.IsZero())
+	ms, err := m.readState()
+	assert.Nil(t, err)
+	assert.Equal(t, "", ms.NextLogID)
+
+	// the next pass migrates all the logs
+	require.Nil(t, m.migrate(ctx))
+	assert.Equal(t, 0, outdated("l1"))
+	close(done)
+	wg.Wait()
+
+	// the last chunks are not migrated, cause they could be written
+	for _, lid := range lids {
+		cis, err := ll.LMStorage.GetChunks(ctx, lid)
+		require.Nil(t, err)
+		o, err := ll.isOutdated(cis[len(cis)-1].ID)
+		require.Nil(t, err)
+		assert.True(t, o)
+		checkRecords(lid)
+	}
+
+	// nothing to migrate anymore
+	require.Nil(t, m.migrate(ctx))
+	assert.Equal(t, int64(0), m.Status().Migrated)
+}
+
+func TestMigrator_RemoteChunks(t *testing.T) {
+	dir, err := os.MkdirTemp("", "TestMigrator_RemoteChunks")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	cfg := chunkfs.Config{NewSize: files.BlockSize, MaxChunkSize: 2 * files.BlockSize, MaxGrowIncreaseSize: files.BlockSize}
+	p := testProvider(dir, 10, cfg)
+	ll := NewLocalLog(Config{MaxRecordsLimit: 1000, MaxBunchSize: 1024 * 1024, MaxLocks: 10})
+	ll.LMStorage = newTestLogsMetaStorage()
+	ll.ChnkProvider = p
+	defer ll.Shutdown()
+
+	var recs []*solaris.Record
+	for i := 0; i < 3; i++ {
+		batch := generateRecords(10, 1000)
+		_, err = ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: batch, LogID: "l1"})
+		require.Nil(t, err)
+		recs = append(recs, batch...)
+	}
+	cis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	require.True(t, len(cis) > 2)
+
+	// the first chunk is stored remotely only, and the compression is turned on
+	require.Nil(t, p.Replicator.UploadChunk(ctx, cis[0].ID))
+	st := p.Replicator.Storage
+	p.Close()
+	require.Nil(t, os.Remove(p.GetFileNameByID(cis[0].ID)))
+	cfg.Compression = chunkfs.CompressionZstd
+	p = testProvider(dir, 10, cfg)
+	p.Replicator.Storage = st
+	defer p.Close()
+	ll.ChnkProvider = p
+
+	m := NewMigrator(MigratorConfig{Workers: 1, StateFile: filepath.Join(dir, "migration.json")})
+	m.Logs = testLogs{lms: ll.LMStorage.(*testLogsMetaStorage)}
+	m.LocalLog = ll
+
+	// the remote chunk is neither downloaded nor migrated
+	require.Nil(t, m.migrate(ctx))
+	assert.Equal(t, int64(len(cis)-2), m.Status().Migrated)
+	assert.Equal(t, int64(0), m.Status().FailedLogs)
+	_, err = os.Stat(p.GetFileNameByID(cis[0].ID))
+	assert.True(t, os.IsNotExist(err))
+	ncis, err := ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	assert.Equal(t, cis[0], ncis[0])
+
+	// it is migrated after it is read
+	read, _, err := ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1000})
+	require.Nil(t, err)
+	comparePayloads(t, read, recs)
+	require.Nil(t, m.migrate(ctx))
+	assert.Equal(t, int64(1), m.Status().Migrated)
+	ncis, err = ll.LMStorage.GetChunks(ctx, "l1")
+	require.Nil(t, err)
+	assert.NotEqual(t, cis[0].ID, ncis[0].ID)
+	read, _, err = ll.QueryRecords(ctx, storage.QueryRecordsRequest{LogID: "l1", Limit: 1000})
+	require.Nil(t, err)
+	comparePayloads(t, read, recs)
+}
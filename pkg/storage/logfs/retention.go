@@ -0,0 +1,175 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/logrange/linker"
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/logging"
+	"github.com/solarisdb/solaris/pkg/storage"
+)
+
+type (
+	// RetentionConfig defines the settings for the RetentionSweeper
+	RetentionConfig struct {
+		// Interval defines the pause between the sweeps over all the logs. Zero value disables the sweeper.
+		Interval time.Duration
+		// BatchSize defines how many logs are read from the logs storage at a time
+		BatchSize int
+	}
+
+	// RetentionSweeper enforces the logs retention policies (see solaris.Log.RetentionMaxAge and
+	// solaris.Log.RetentionMaxRecords). It periodically passes over all the logs and removes the records,
+	// which exceed the log policy. The policies are read on every sweep, so a policy changed by UpdateLog
+	// takes effect on the next sweep. The logs with the zero policy are kept forever. The solaris.Log.MaxRecords
+	// is enforced by the appends, which may leave a few extra records, so the sweeper trims the log to the lesser
+	// of the MaxRecords and the RetentionMaxRecords exactly.
+	RetentionSweeper struct {
+		Logs     storage.Logs `inject:""`
+		LocalLog *localLog    `inject:""`
+
+		cfg    RetentionConfig
+		logger logging.Logger
+		ctx    context.Context
+		cancel context.CancelFunc
+		wg     sync.WaitGroup
+	}
+)
+
+var _ linker.Initializer = (*RetentionSweeper)(nil)
+var _ linker.Shutdowner = (*RetentionSweeper)(nil)
+
+// GetDefaultRetentionConfig returns the default RetentionConfig
+func GetDefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		Interval:  10 * time.Minute,
+		BatchSize: 100,
+	}
+}
+
+// NewRetentionSweeper creates the new RetentionSweeper
+func NewRetentionSweeper(cfg RetentionConfig) *RetentionSweeper {
+	rs := &RetentionSweeper{cfg: cfg, logger: logging.NewLogger("logfs.RetentionSweeper")}
+	if rs.cfg.BatchSize <= 0 {
+		rs.cfg.BatchSize = GetDefaultRetentionConfig().BatchSize
+	}
+	rs.ctx, rs.cancel = context.WithCancel(context.Background())
+	return rs
+}
+
+// String implements fmt.Stringer
+func (rc RetentionConfig) String() string {
+	b, _ := json.MarshalIndent(rc, "", "  ")
+	return string(b)
+}
+
+// Init implements linker.Initializer
+func (rs *RetentionSweeper) Init(_ context.Context) error {
+	rs.logger.Infof("initializing cfg:\n%s", rs.cfg)
+	if rs.cfg.Interval <= 0 {
+		rs.logger.Infof("the Interval in the config is zero or negative, the retention policies will not be enforced")
+		return nil
+	}
+	rs.wg.Add(1)
+	go rs.watcher()
+	return nil
+}
+
+// Shutdown implements linker.Shutdowner
+func (rs *RetentionSweeper) Shutdown() {
+	rs.cancel()
+	rs.wg.Wait()
+}
+
+func (rs *RetentionSweeper) watcher() {
+	rs.logger.Infof("starting watcher()")
+	defer rs.logger.Infof("exiting from watcher()")
+	defer rs.wg.Done()
+	for {
+		select {
+		case <-rs.ctx.Done():
+			return
+		case <-time.After(rs.cfg.Interval):
+		}
+		if _, err := rs.sweep(rs.ctx); err != nil && rs.ctx.Err() == nil {
+			rs.logger.Warnf("the retention sweep is interrupted: %v", err)
+		}
+	}
+}
+
+// sweep makes one pass over the logs and returns the number of records removed
+func (rs *RetentionSweeper) sweep(ctx context.Context) (int64, error) {
+	var removed int64
+	page := ""
+	for {
+		res, err := rs.Logs.QueryLogs(ctx, storage.QueryLogsRequest{Page: page, Limit: int64(rs.cfg.BatchSize)})
+		if err != nil {
+			return removed, err
+		}
+		for _, l := range res.Logs {
+			if ctx.Err() != nil {
+				return removed, ctx.Err()
+			}
+			n, err := rs.sweepLog(ctx, l)
+			// the log could be deleted in the meantime
+			if err != nil && ctx.Err() == nil && !errors.Is(err, errors.ErrNotExist) {
+				rs.logger.Warnf("could not apply the retention policy to logID=%s: %v", l.ID, err)
+			}
+			removed += n
+		}
+		if page = res.NextPageID; page == "" {
+			break
+		}
+	}
+	if removed > 0 {
+		rs.logger.Infof("the retention sweep is finished, %d records removed", removed)
+	}
+	return removed, nil
+}
+
+// sweepLog removes the log records exceeding the log retention policy
+func (rs *RetentionSweeper) sweepLog(ctx context.Context, log *solaris.Log) (int64, error) {
+	var removed int64
+	if maxAge := log.RetentionMaxAge.AsDuration(); maxAge > 0 {
+		n, err := rs.LocalLog.TruncateRecords(ctx, log.ID, time.Now().Add(-maxAge))
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	if maxRecords := retentionMaxRecords(log); maxRecords > 0 {
+		n, err := rs.LocalLog.TrimRecords(ctx, log.ID, maxRecords, 0)
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+// retentionMaxRecords returns the maximum number of the log records, which is the lesser of the positive
+// MaxRecords and RetentionMaxRecords, or 0 if the number is not limited
+func retentionMaxRecords(log *solaris.Log) int64 {
+	if log.MaxRecords > 0 && (log.RetentionMaxRecords <= 0 || log.MaxRecords < log.RetentionMaxRecords) {
+		return log.MaxRecords
+	}
+	return max(log.RetentionMaxRecords, 0)
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfs
+
+import (
+	"context"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// testPolicyLogs lists the logs with their retention policies
+type testPolicyLogs struct {
+	storage.Logs
+	logs map[string]*solaris.Log
+}
+
+func (tl testPolicyLogs) QueryLogs(_ context.Context, qr storage.QueryLogsRequest) (*solaris.QueryLogsResult, error) {
+	var ids []string
+	for id := range tl.logs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	res := &solaris.QueryLogsResult{Total: int64(len(ids))}
+	for _, id := range ids {
+		if id <= qr.Page {
+			continue
+		}
+		if len(res.Logs) == int(qr.Limit) {
+			res.NextPageID = res.Logs[len(res.Logs)-1].ID
+			break
+		}
+		res.Logs = append(res.Logs, tl.logs[id])
+	}
+	return res, nil
+}
+
+func TestRetentionSweeper(t *testing.T) {
+	p, ll := setupTestDB(t)
+	defer p.Close()
+	defer ll.Shutdown()
+
+	ctx := context.Background()
+	tl := testPolicyLogs{logs: map[string]*solaris.Log{
+		"l1": {ID: "l1"},
+		"l2": {ID: "l2", RetentionMaxRecords: 3},
+		"l3": {ID: "l3", RetentionMaxAge: durationpb.New(time.Hour)},
+		"l4": {ID: "l4", MaxRecords: 2, RetentionMaxRecords: 4},
+	}}
+	rs := NewRetentionSweeper(RetentionConfig{BatchSize: 2})
+	rs.Logs = tl
+	rs.LocalLog = ll
+
+	// every chunk fits 2 records only
+	for lid := range tl.logs {
+		_, err := ll.AppendRecords(ctx, &solaris.AppendRecordsRequest{Records: generateRecords(6, 3000), LogID: lid})
+		require.Nil(t, err)
+	}
+	count := func(lid string) uint64 {
+		total, _, _, err := ll.CountRecords(ctx, storage.QueryRecordsRequest{LogID: lid})
+		require.Nil(t, err)
+		return total
+	}
+
+	removed, err := rs.sweep(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), removed)
+	assert.Equal(t, uint64(6), count("l1"))
+	assert.Equal(t, uint64(3), count("l2"))
+	assert.Equal(t, uint64(6), count("l3"))
+	// the lesser limit wins
+	assert.Equal(t, uint64(2), count("l4"))
+
+	// the updated policy takes effect on the next sweep, and the removed chunks files are deleted
+	cis, err := ll.LMStorage.GetChunks(ctx, "l3")
+	require.Nil(t, err)
+	tl.logs["l3"] = &solaris.Log{ID: "l3", RetentionMaxAge: durationpb.New(time.Millisecond)}
+	time.Sleep(2 * time.Millisecond)
+	removed, err = rs.sweep(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), removed)
+	assert.Equal(t, uint64(6), count("l1"))
+	assert.Equal(t, uint64(3), count("l2"))
+	assert.Equal(t, uint64(0), count("l3"))
+	for _, ci := range cis {
+		_, err = os.Stat(p.GetFileNameByID(ci.ID))
+		assert.True(t, errors.Is(err, errors.ErrNotExist))
+	}
+}
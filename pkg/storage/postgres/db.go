@@ -13,7 +13,8 @@ type (
 	// Db exposes db operations
 	Db struct {
 		*sqlx.DB
-		logger logging.Logger
+		dialect dialect
+		logger  logging.Logger
 	}
 )
 
@@ -28,7 +29,11 @@ func MustGetDb(ctx context.Context, dbConn *db.DBConn) *Db {
 
 // GetDb returns the Db object built for the given configuration
 func GetDb(ctx context.Context, dbConn *db.DBConn) (*Db, error) {
-	db, err := sqlx.ConnectContext(ctx, "postgres", dbConn.SourceName())
+	d, err := getDialect(dbConn.Driver)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sqlx.ConnectContext(ctx, d.driver, d.dataSource(dbConn))
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to the database: %w", err)
 	}
@@ -41,17 +46,17 @@ func GetDb(ctx context.Context, dbConn *db.DBConn) (*Db, error) {
 	if dbConn.MaxConnIdleTimeSec != nil {
 		db.SetConnMaxIdleTime(time.Duration(*dbConn.MaxConnIdleTimeSec) * time.Second)
 	}
-	if err = migrateUp(ctx, db.DB); err != nil {
+	if err = migrateUp(ctx, db.DB, d); err != nil {
 		return nil, fmt.Errorf("migration failed: %w", err)
 	}
-	return &Db{DB: db}, nil
+	return &Db{DB: db, dialect: d}, nil
 }
 
 // Init implements linker.Initializer
 func (s *Db) Init(ctx context.Context) error {
-	s.logger = logging.NewLogger("db.postgres")
+	s.logger = logging.NewLogger("db." + s.dialect.driver)
 	s.logger.Infof("Initializing...")
-	if err := migrateUp(ctx, s.DB.DB); err != nil {
+	if err := migrateUp(ctx, s.DB.DB, s.dialect); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 	return nil
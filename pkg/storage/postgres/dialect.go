@@ -0,0 +1,107 @@
+// Copyright 2024 The Solaris Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/pkg/db"
+	"github.com/solarisdb/solaris/pkg/ql"
+	"strings"
+)
+
+type (
+	// dialect describes the differences of the SQL databases the Storage may work with
+	dialect struct {
+		// driver is the database/sql driver name
+		driver string
+		// migrateDialect is the sql-migrate dialect name
+		migrateDialect string
+		// dataSource returns the data source name for the connection
+		dataSource func(dbConn *db.DBConn) string
+		// migrations returns the schema migrations of the database
+		migrations func() []*migrate.Migration
+		// translator translates the logs conditions to the SQL where clause
+		translator ql.Translator[*solaris.Log]
+		// rowValues specifies whether the columns may be updated by the row value: set (a, b) = (x, y)
+		rowValues bool
+		// maxInsertRows is the number of the rows inserted by one statement, so the statement parameters
+		// number is within the database limit
+		maxInsertRows int
+	}
+)
+
+var (
+	postgresDialect = dialect{
+		driver:         "postgres",
+		migrateDialect: "postgres",
+		dataSource:     func(dbConn *db.DBConn) string { return dbConn.SourceName() },
+		migrations:     migrations,
+		translator:     ql.NewTranslator(ql.LogsCondTranslateDialect),
+		rowValues:      true,
+		maxInsertRows:  10000,
+	}
+
+	sqliteDialect = dialect{
+		// the pure Go driver is used, so the server is built without CGO
+		driver:         "sqlite",
+		migrateDialect: "sqlite3",
+		// the foreign keys are required to delete the log chunks by cascade, and the busy timeout allows
+		// the concurrent transactions to wait for each other instead of failing right away
+		dataSource: func(dbConn *db.DBConn) string {
+			return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"+
+				"&_pragma=case_sensitive_like(1)&_txlock=immediate&_time_format=sqlite", dbConn.DBName)
+		},
+		migrations: sqliteMigrations,
+		translator: ql.NewTranslator(ql.LogsCondSqliteTranslateDialect),
+		// the driver matches every statement parameter against all the arguments, so the large statements
+		// are slow to bind, though the SQLite limit is 32766 parameters
+		maxInsertRows: 500,
+	}
+)
+
+// getDialect returns the dialect of the DB driver
+func getDialect(driver string) (dialect, error) {
+	switch driver {
+	case db.DriverPostgres:
+		return postgresDialect, nil
+	case db.DriverSqlite:
+		return sqliteDialect, nil
+	}
+	return dialect{}, fmt.Errorf("unsupported DB driver=%q: %w", driver, errors.ErrInvalid)
+}
+
+// upsert returns the "on conflict" clause, which updates the cols of the row with the conflicting keys
+func (d dialect) upsert(keys, cols []string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(" on conflict (%s) do update set ", strings.Join(keys, ", ")))
+	if d.rowValues {
+		excluded := make([]string, 0, len(cols))
+		for _, c := range cols {
+			excluded = append(excluded, "excluded."+c)
+		}
+		sb.WriteString(fmt.Sprintf("(%s) = (%s)", strings.Join(cols, ", "), strings.Join(excluded, ", ")))
+		return sb.String()
+	}
+	for i, c := range cols {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fmt.Sprintf("%s = excluded.%s", c, c))
+	}
+	return sb.String()
+}
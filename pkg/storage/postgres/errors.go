@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"github.com/lib/pq"
 	"github.com/solarisdb/solaris/golibs/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 const (
@@ -33,6 +35,9 @@ func MapError(err error) error {
 	if errors.Is(err, sql.ErrNoRows) {
 		return errors.ErrNotExist
 	}
+	if sqErr, ok := err.(*sqlite.Error); ok {
+		return MapSqliteError(sqErr)
+	}
 	return MapPqError(err)
 }
 
@@ -47,3 +52,14 @@ func MapPqError(err error) error {
 	}
 	return err
 }
+
+// MapSqliteError maps the SQLite constraint violations the same way as the Postgres ones
+func MapSqliteError(err *sqlite.Error) error {
+	switch err.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return fmt.Errorf("%v: %w", err, errors.ErrConflict)
+	case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		return fmt.Errorf("%v: %w", err, errors.ErrExist)
+	}
+	return err
+}
@@ -48,6 +48,161 @@ create table if not exists "chunk"
 	initSchemaDown = `
 drop table if exists "log";
 drop table if exists "chunk";
+`
+
+	chunkKeyIDUp = `
+alter table "chunk" add column if not exists "key_id" varchar(32) not null default '';
+`
+	chunkKeyIDDown = `
+alter table "chunk" drop column if exists "key_id";
+`
+
+	logValidateUTF8Up = `
+alter table "log" add column if not exists "validate_utf8" boolean not null default false;
+`
+	logValidateUTF8Down = `
+alter table "log" drop column if exists "validate_utf8";
+`
+
+	appendKeyUp = `
+create table if not exists "append_key"
+(
+    "log_id"        varchar(32) references "log" ("id") on delete cascade,
+    "key"           varchar(256)             not null default '',
+    "added"         bigint                   not null default 0,
+    "bytes_written" bigint                   not null default 0,
+    "start_id"      varchar(32)              not null default '',
+    "last_id"       varchar(32)              not null default '',
+    primary key ("log_id")
+);
+`
+	appendKeyDown = `
+drop table if exists "append_key";
+`
+
+	chunkFirstSeqUp = `
+alter table "chunk" add column if not exists "first_seq" bigint not null default 0;
+`
+	chunkFirstSeqDown = `
+alter table "chunk" drop column if exists "first_seq";
+`
+
+	logPayloadTypeURLUp = `
+alter table "log" add column if not exists "payload_type_url" varchar(256) not null default '';
+`
+	logPayloadTypeURLDown = `
+alter table "log" drop column if exists "payload_type_url";
+`
+
+	logMaxRecordsUp = `
+alter table "log" add column if not exists "max_records" bigint not null default 0;
+`
+	logMaxRecordsDown = `
+alter table "log" drop column if exists "max_records";
+`
+
+	recordHashUp = `
+alter table "log" add column if not exists "payload_hash" varchar(32) not null default '';
+
+create table if not exists "record_hash"
+(
+    "log_id"    varchar(32) references "log" ("id") on delete cascade,
+    "hash"      varchar(128)             not null,
+    "record_id" varchar(32)              not null,
+    primary key ("log_id", "hash", "record_id")
+);
+`
+	recordHashDown = `
+drop table if exists "record_hash";
+alter table "log" drop column if exists "payload_hash";
+`
+
+	logRetentionUp = `
+alter table "log" add column if not exists "retention_max_age_ms" bigint not null default 0;
+alter table "log" add column if not exists "retention_max_records" bigint not null default 0;
+`
+	logRetentionDown = `
+alter table "log" drop column if exists "retention_max_records";
+alter table "log" drop column if exists "retention_max_age_ms";
+`
+
+	logAccessUp = `
+alter table "log" add column if not exists "owner" varchar(256) not null default '';
+alter table "log" add column if not exists "grants" jsonb not null default '[]'::jsonb;
+`
+	logAccessDown = `
+alter table "log" drop column if exists "grants";
+alter table "log" drop column if exists "owner";
+`
+
+	logKeyUp = `
+create table if not exists "log_key"
+(
+    "log_id" varchar(32) references "log" ("id") on delete cascade,
+    "key_id" varchar(32) not null default '',
+    primary key ("log_id")
+);
+`
+	logKeyDown = `
+drop table if exists "log_key";
+`
+
+	sqliteInitSchemaUp = `
+create table if not exists "log"
+(
+    "id"                    varchar(32)  not null,
+    "tags"                  text         not null default '{}',
+    "records"               integer      not null default 0,
+    "deleted"               boolean      not null default false,
+    "created_at"            timestamp    not null default current_timestamp,
+    "updated_at"            timestamp    not null default current_timestamp,
+    "validate_utf8"         boolean      not null default false,
+    "payload_type_url"      varchar(256) not null default '',
+    "max_records"           bigint       not null default 0,
+    "payload_hash"          varchar(32)  not null default '',
+    "retention_max_age_ms"  bigint       not null default 0,
+    "retention_max_records" bigint       not null default 0,
+    "owner"                 varchar(256) not null default '',
+    "grants"                text         not null default '[]',
+    primary key ("id")
+);
+
+create table if not exists "chunk"
+(
+    "id"        varchar(32) not null,
+    "log_id"    varchar(32) references "log" ("id") on delete cascade,
+    "min"       varchar(32) not null default '',
+    "max"       varchar(32) not null default '',
+    "records"   integer     not null default 0,
+    "key_id"    varchar(32) not null default '',
+    "first_seq" bigint      not null default 0,
+    primary key ("log_id", "id")
+);
+
+create table if not exists "append_key"
+(
+    "log_id"        varchar(32) references "log" ("id") on delete cascade,
+    "key"           varchar(256) not null default '',
+    "added"         bigint       not null default 0,
+    "bytes_written" bigint       not null default 0,
+    "start_id"      varchar(32)  not null default '',
+    "last_id"       varchar(32)  not null default '',
+    primary key ("log_id")
+);
+
+create table if not exists "record_hash"
+(
+    "log_id"    varchar(32) references "log" ("id") on delete cascade,
+    "hash"      varchar(128) not null,
+    "record_id" varchar(32)  not null,
+    primary key ("log_id", "hash", "record_id")
+);
+`
+	sqliteInitSchemaDown = `
+drop table if exists "record_hash";
+drop table if exists "append_key";
+drop table if exists "chunk";
+drop table if exists "log";
 `
 )
 
@@ -59,23 +214,122 @@ func initSchema(id string) *migrate.Migration {
 	}
 }
 
+func chunkKeyID(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{chunkKeyIDUp},
+		Down: []string{chunkKeyIDDown},
+	}
+}
+
+func logValidateUTF8(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{logValidateUTF8Up},
+		Down: []string{logValidateUTF8Down},
+	}
+}
+
+func appendKey(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{appendKeyUp},
+		Down: []string{appendKeyDown},
+	}
+}
+
+func chunkFirstSeq(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{chunkFirstSeqUp},
+		Down: []string{chunkFirstSeqDown},
+	}
+}
+
+func logPayloadTypeURL(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{logPayloadTypeURLUp},
+		Down: []string{logPayloadTypeURLDown},
+	}
+}
+
+func logMaxRecords(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{logMaxRecordsUp},
+		Down: []string{logMaxRecordsDown},
+	}
+}
+
+func recordHash(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{recordHashUp},
+		Down: []string{recordHashDown},
+	}
+}
+
+func logRetention(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{logRetentionUp},
+		Down: []string{logRetentionDown},
+	}
+}
+
+func logAccess(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{logAccessUp},
+		Down: []string{logAccessDown},
+	}
+}
+
+func logKey(id string) *migrate.Migration {
+	return &migrate.Migration{
+		Id:   id,
+		Up:   []string{logKeyUp},
+		Down: []string{logKeyDown},
+	}
+}
+
 func migrations() []*migrate.Migration {
 	return []*migrate.Migration{
 		initSchema("0"),
+		chunkKeyID("1"),
+		logValidateUTF8("2"),
+		appendKey("3"),
+		chunkFirstSeq("4"),
+		logPayloadTypeURL("5"),
+		logMaxRecords("6"),
+		recordHash("7"),
+		logRetention("8"),
+		logAccess("9"),
+		logKey("10"),
+	}
+}
+
+// sqliteMigrations returns the SQLite schema migrations. The SQLite schema starts from
+// the current Postgres one, so the migrations are not the same.
+func sqliteMigrations() []*migrate.Migration {
+	return []*migrate.Migration{
+		{Id: "0", Up: []string{sqliteInitSchemaUp}, Down: []string{sqliteInitSchemaDown}},
+		logKey("1"),
 	}
 }
 
-func migrateUp(ctx context.Context, db *sql.DB) error {
-	mms := migrate.MemoryMigrationSource{Migrations: migrations()}
-	if _, err := migrate.ExecContext(ctx, db, "postgres", mms, migrate.Up); err != nil {
+func migrateUp(ctx context.Context, db *sql.DB, d dialect) error {
+	mms := migrate.MemoryMigrationSource{Migrations: d.migrations()}
+	if _, err := migrate.ExecContext(ctx, db, d.migrateDialect, mms, migrate.Up); err != nil {
 		return err
 	}
 	return nil
 }
 
-func migrateDown(ctx context.Context, db *sql.DB) error {
-	mms := migrate.MemoryMigrationSource{Migrations: migrations()}
-	if _, err := migrate.ExecContext(ctx, db, "postgres", mms, migrate.Down); err != nil {
+func migrateDown(ctx context.Context, db *sql.DB, d dialect) error {
+	mms := migrate.MemoryMigrationSource{Migrations: d.migrations()}
+	if _, err := migrate.ExecContext(ctx, db, d.migrateDialect, mms, migrate.Down); err != nil {
 		return err
 	}
 	return nil
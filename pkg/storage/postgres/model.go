@@ -24,22 +24,45 @@ import (
 
 type (
 	Log struct {
-		ID        string    `db:"id"`
-		Tags      Tags      `db:"tags"`
-		Records   int64     `db:"records"`
-		Deleted   bool      `db:"deleted"`
-		CreatedAt time.Time `db:"created_at"`
-		UpdatedAt time.Time `db:"updated_at"`
+		ID             string    `db:"id"`
+		Tags           Tags      `db:"tags"`
+		Records        int64     `db:"records"`
+		Deleted        bool      `db:"deleted"`
+		CreatedAt      time.Time `db:"created_at"`
+		UpdatedAt      time.Time `db:"updated_at"`
+		ValidateUTF8   bool      `db:"validate_utf8"`
+		PayloadTypeURL string    `db:"payload_type_url"`
+		MaxRecords     int64     `db:"max_records"`
+		PayloadHash    string    `db:"payload_hash"`
+		// RetentionMaxAgeMs is the Log.RetentionMaxAge in milliseconds
+		RetentionMaxAgeMs   int64  `db:"retention_max_age_ms"`
+		RetentionMaxRecords int64  `db:"retention_max_records"`
+		Owner               string `db:"owner"`
+		Grants              Grants `db:"grants"`
 	}
 
 	Tags map[string]string
 
+	// Grants is the list of the clients IDs stored as a JSON array
+	Grants []string
+
 	Chunk struct {
 		ID           string `db:"id"`
 		LogID        string `db:"log_id"`
 		Min          string `db:"min"`
 		Max          string `db:"max"`
 		RecordsCount int    `db:"records"`
+		KeyID        string `db:"key_id"`
+		FirstSeq     int64  `db:"first_seq"`
+	}
+
+	AppendKey struct {
+		LogID        string `db:"log_id"`
+		Key          string `db:"key"`
+		Added        int64  `db:"added"`
+		BytesWritten int64  `db:"bytes_written"`
+		StartID      string `db:"start_id"`
+		LastID       string `db:"last_id"`
 	}
 )
 
@@ -48,13 +71,38 @@ func (t Tags) Value() (value driver.Value, err error) {
 }
 
 func (t *Tags) Scan(value any) error {
-	buf, ok := value.([]byte)
-	if !ok {
-		return fmt.Errorf("not a []byte value in scan")
+	buf, err := jsonBytes(value)
+	if err != nil {
+		return err
 	}
 	return json.Unmarshal(buf, &t)
 }
 
+func (g Grants) Value() (value driver.Value, err error) {
+	if g == nil {
+		return "[]", nil
+	}
+	buf, err := json.Marshal([]string(g))
+	return string(buf), err
+}
+
+func (g *Grants) Scan(value any) error {
+	buf, err := jsonBytes(value)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	if err = json.Unmarshal(buf, &ids); err != nil {
+		return err
+	}
+	// no grants are nil, as they are in the API logs
+	*g = nil
+	if len(ids) > 0 {
+		*g = ids
+	}
+	return nil
+}
+
 func (t Tags) JSON() string {
 	var sb strings.Builder
 	sb.WriteString("{")
@@ -67,3 +115,14 @@ func (t Tags) JSON() string {
 	sb.WriteString("}")
 	return sb.String()
 }
+
+// jsonBytes returns the JSON column value, which is []byte for Postgres, but string for SQLite
+func jsonBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("not a []byte value in scan")
+}
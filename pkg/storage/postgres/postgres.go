@@ -21,22 +21,19 @@ import (
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/errors"
 	"github.com/solarisdb/solaris/golibs/ulidutils"
-	"github.com/solarisdb/solaris/pkg/ql"
 	"github.com/solarisdb/solaris/pkg/storage"
 	"github.com/solarisdb/solaris/pkg/storage/logfs"
 	"strings"
 	"time"
 )
 
-// Storage is the logs meta storage
+// Storage is the logs meta storage, which keeps the data in the SQL database (Postgres or SQLite)
 type Storage struct {
 	db *Db
 }
 
-// qlToPqTranslator is the logs condition translator from solaris QL to the Postgres dialect
-var qlToPqTranslator = ql.NewTranslator(ql.LogsCondTranslateDialect)
-
-// NewStorage creates new logs meta storage based on Postgres
+// NewStorage creates new logs meta storage based on the db, the SQL the storage
+// issues depends on the db dialect
 func NewStorage(db *Db) *Storage {
 	return &Storage{db: db}
 }
@@ -50,14 +47,49 @@ func (s *Storage) CreateLog(ctx context.Context, log *solaris.Log) (*solaris.Log
 	newLog.CreatedAt = time.Now()
 	newLog.UpdatedAt = newLog.CreatedAt
 
-	_, err := s.db.ExecContext(ctx, "insert into log (id, tags, records, created_at, updated_at) values ($1, $2, $3, $4, $5)",
-		newLog.ID, newLog.Tags.JSON(), newLog.Records, newLog.CreatedAt, newLog.UpdatedAt)
+	_, err := s.db.ExecContext(ctx, "insert into log (id, tags, records, created_at, updated_at, validate_utf8, payload_type_url, max_records, payload_hash, retention_max_age_ms, retention_max_records, owner, grants) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)",
+		newLog.ID, newLog.Tags.JSON(), newLog.Records, newLog.CreatedAt, newLog.UpdatedAt, newLog.ValidateUTF8, newLog.PayloadTypeURL, newLog.MaxRecords, newLog.PayloadHash,
+		newLog.RetentionMaxAgeMs, newLog.RetentionMaxRecords, newLog.Owner, newLog.Grants)
 	if err != nil {
 		return nil, MapError(err)
 	}
 	return logToAPI(newLog), nil
 }
 
+// CreateLogs implements storage.Logs
+func (s *Storage) CreateLogs(ctx context.Context, logs []*solaris.Log) ([]*solaris.Log, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now()
+	res := make([]*solaris.Log, 0, len(logs))
+	for _, log := range logs {
+		newLog := logToModel(log)
+		if newLog.ID == "" {
+			newLog.ID = ulidutils.NewID()
+		}
+		newLog.CreatedAt = now
+		newLog.UpdatedAt = now
+
+		_, err = tx.ExecContext(ctx, "insert into log (id, tags, records, created_at, updated_at, validate_utf8, payload_type_url, max_records, payload_hash, retention_max_age_ms, retention_max_records, owner, grants) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)",
+			newLog.ID, newLog.Tags.JSON(), newLog.Records, newLog.CreatedAt, newLog.UpdatedAt, newLog.ValidateUTF8, newLog.PayloadTypeURL, newLog.MaxRecords, newLog.PayloadHash,
+			newLog.RetentionMaxAgeMs, newLog.RetentionMaxRecords, newLog.Owner, newLog.Grants)
+		if err != nil {
+			return nil, MapError(err)
+		}
+		res = append(res, logToAPI(newLog))
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, MapError(err)
+	}
+	return res, nil
+}
+
 // GetLogByID implements storage.Logs
 func (s *Storage) GetLogByID(ctx context.Context, id string) (*solaris.Log, error) {
 	if len(id) == 0 {
@@ -75,8 +107,9 @@ func (s *Storage) UpdateLog(ctx context.Context, log *solaris.Log) (*solaris.Log
 	if len(log.ID) == 0 {
 		return nil, fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
 	}
-	rows, err := s.db.QueryxContext(ctx, "update log set tags = $1, updated_at = $2 where id = $3 and deleted = false returning *",
-		Tags(log.Tags).JSON(), time.Now(), log.ID)
+	rows, err := s.db.QueryxContext(ctx, "update log set tags = $1, validate_utf8 = $2, payload_type_url = $3, max_records = $4, payload_hash = $5, retention_max_age_ms = $6, retention_max_records = $7, grants = $8, updated_at = $9 where id = $10 and deleted = false returning *",
+		Tags(log.Tags).JSON(), log.ValidateUTF8, log.PayloadTypeURL, log.MaxRecords, log.PayloadHash, log.RetentionMaxAge.AsDuration().Milliseconds(),
+		log.RetentionMaxRecords, Grants(log.Grants), time.Now(), log.ID)
 	if err != nil {
 		return nil, MapError(err)
 	}
@@ -109,9 +142,37 @@ func (s *Storage) QueryLogs(ctx context.Context, qr storage.QueryLogsRequest) (*
 		}
 		sb.WriteString(")")
 	} else if len(qr.Condition) > 0 {
-		if err := qlToPqTranslator.Translate(&sb, qr.Condition); err != nil {
-			return nil, fmt.Errorf("condition=%q translate error=%v: %w", qr.Condition, err, errors.ErrInvalid)
+		// the parentheses keep the condition ORs from mixing up with the conditions below
+		sb.WriteString("(")
+		if err := s.db.dialect.translator.Translate(&sb, qr.Condition); err != nil {
+			return nil, fmt.Errorf("condition=%q translate error: %w", qr.Condition, err)
 		}
+		sb.WriteString(")")
+	}
+
+	// the log IDs are ULIDs, so the creation window and the page are the id (primary key) range
+	from, to := qr.IDRange()
+	if from != "" {
+		if sb.Len() > 0 {
+			sb.WriteString(" and ")
+		}
+		args = append(args, from)
+		sb.WriteString(fmt.Sprintf("id >= $%d", len(args)))
+	}
+	// the page starts after the last log of the previous one
+	if qr.Page != "" {
+		if sb.Len() > 0 {
+			sb.WriteString(" and ")
+		}
+		args = append(args, qr.Page)
+		sb.WriteString(fmt.Sprintf("id > $%d", len(args)))
+	}
+	if to != "" {
+		if sb.Len() > 0 {
+			sb.WriteString(" and ")
+		}
+		args = append(args, to)
+		sb.WriteString(fmt.Sprintf("id <= $%d", len(args)))
 	}
 
 	if sb.Len() > 0 {
@@ -154,8 +215,10 @@ func (s *Storage) QueryLogs(ctx context.Context, qr storage.QueryLogsRequest) (*
 
 	var nextPageID string
 	if len(logs) > limit {
-		nextPageID = logs[limit].ID
 		logs = logs[:limit]
+		if limit > 0 {
+			nextPageID = logs[limit-1].ID
+		}
 	}
 	return &solaris.QueryLogsResult{
 		Logs:       logsToAPI(logs),
@@ -180,8 +243,8 @@ func (s *Storage) DeleteLogs(ctx context.Context, req storage.DeleteLogsRequest)
 		}
 		sb.WriteString(")")
 	} else if len(req.Condition) > 0 {
-		if err := qlToPqTranslator.Translate(&sb, req.Condition); err != nil {
-			return nil, fmt.Errorf("condition=%q translate error=%v: %w", req.Condition, err, errors.ErrInvalid)
+		if err := s.db.dialect.translator.Translate(&sb, req.Condition); err != nil {
+			return nil, fmt.Errorf("condition=%q translate error: %w", req.Condition, err)
 		}
 	}
 	if sb.Len() == 0 {
@@ -261,7 +324,7 @@ func (s *Storage) UpsertChunkInfos(ctx context.Context, logID string, cis []logf
 	var args []any
 
 	firstIdx := 1
-	sb.WriteString("insert into chunk (id, log_id, min, max, records) values ")
+	sb.WriteString("insert into chunk (id, log_id, min, max, records, key_id, first_seq) values ")
 
 	for i, ci := range cis {
 		if len(ci.ID) == 0 {
@@ -270,20 +333,162 @@ func (s *Storage) UpsertChunkInfos(ctx context.Context, logID string, cis []logf
 		if i > 0 {
 			sb.WriteString(",")
 		}
-		sb.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", firstIdx, firstIdx+1, firstIdx+2, firstIdx+3, firstIdx+4))
-		firstIdx += 5
+		sb.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", firstIdx, firstIdx+1, firstIdx+2, firstIdx+3, firstIdx+4, firstIdx+5, firstIdx+6))
+		firstIdx += 7
 		args = append(args, ci.ID)
 		args = append(args, logID)
 		args = append(args, ci.Min.String())
 		args = append(args, ci.Max.String())
 		args = append(args, ci.RecordsCount)
+		args = append(args, ci.KeyID)
+		args = append(args, ci.FirstSeq)
 	}
 
-	sb.WriteString(" on conflict (id, log_id) do update set (min, max, records) = (excluded.min, excluded.max, excluded.records)")
+	sb.WriteString(s.db.dialect.upsert([]string{"id", "log_id"}, []string{"min", "max", "records", "key_id", "first_seq"}))
 	_, err := s.db.ExecContext(ctx, sb.String(), args...)
 	return MapError(err)
 }
 
+// DeleteChunkInfos implements logfs.LogsMetaStorage
+func (s *Storage) DeleteChunkInfos(ctx context.Context, logID string, cIDs []string) error {
+	if len(logID) == 0 {
+		return fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
+	}
+	if len(cIDs) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	args := []any{logID}
+	sb.WriteString("delete from chunk where log_id = $1 and id in (")
+	for i, cID := range cIDs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		args = append(args, cID)
+		sb.WriteString(fmt.Sprintf("$%d", len(args)))
+	}
+	sb.WriteString(")")
+	_, err := s.db.ExecContext(ctx, sb.String(), args...)
+	return MapError(err)
+}
+
+// GetLastAppendKey implements logfs.LogsMetaStorage
+func (s *Storage) GetLastAppendKey(ctx context.Context, logID string) (logfs.AppendKey, error) {
+	if len(logID) == 0 {
+		return logfs.AppendKey{}, fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
+	}
+	var ak AppendKey
+	if err := s.db.GetContext(ctx, &ak, "select * from append_key where log_id=$1", logID); err != nil {
+		return logfs.AppendKey{}, MapError(err)
+	}
+	return appendKeyToInfo(ak), nil
+}
+
+// SetLastAppendKey implements logfs.LogsMetaStorage
+func (s *Storage) SetLastAppendKey(ctx context.Context, logID string, ak logfs.AppendKey) error {
+	if len(logID) == 0 {
+		return fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
+	}
+	_, err := s.db.ExecContext(ctx, "insert into append_key (log_id, key, added, bytes_written, start_id, last_id) values ($1, $2, $3, $4, $5, $6)"+
+		s.db.dialect.upsert([]string{"log_id"}, []string{"key", "added", "bytes_written", "start_id", "last_id"}),
+		logID, ak.Key, ak.Added, ak.BytesWritten, ak.StartID, ak.LastID)
+	return MapError(err)
+}
+
+// GetLogKeyID implements logfs.LogsMetaStorage
+func (s *Storage) GetLogKeyID(ctx context.Context, logID string) (string, error) {
+	if len(logID) == 0 {
+		return "", fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
+	}
+	var keyID string
+	if err := s.db.GetContext(ctx, &keyID, "select key_id from log_key where log_id=$1", logID); err != nil {
+		return "", MapError(err)
+	}
+	return keyID, nil
+}
+
+// SetLogKeyID implements logfs.LogsMetaStorage
+func (s *Storage) SetLogKeyID(ctx context.Context, logID, keyID string) error {
+	if len(logID) == 0 {
+		return fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
+	}
+	_, err := s.db.ExecContext(ctx, "insert into log_key (log_id, key_id) values ($1, $2)"+
+		s.db.dialect.upsert([]string{"log_id"}, []string{"key_id"}), logID, keyID)
+	return MapError(err)
+}
+
+// AddRecordHashes implements storage.RecordHashes
+func (s *Storage) AddRecordHashes(ctx context.Context, logID string, rhs []storage.RecordHash) error {
+	if len(logID) == 0 {
+		return fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
+	}
+	for i, rh := range rhs {
+		if len(rh.Hash) == 0 || len(rh.RecordID) == 0 {
+			return fmt.Errorf("hash and record ID for item=%d must be specified: %w", i, errors.ErrInvalid)
+		}
+	}
+
+	// the hashes are inserted by batches, so the number of the statement parameters is within the limit
+	for len(rhs) > 0 {
+		n := min(len(rhs), s.db.dialect.maxInsertRows)
+		var sb strings.Builder
+		args := []any{logID}
+		sb.WriteString("insert into record_hash (log_id, hash, record_id) values ")
+		for i, rh := range rhs[:n] {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			args = append(args, rh.Hash, rh.RecordID)
+			sb.WriteString(fmt.Sprintf("($1, $%d, $%d)", len(args)-1, len(args)))
+		}
+		sb.WriteString(" on conflict (log_id, hash, record_id) do nothing")
+		if _, err := s.db.ExecContext(ctx, sb.String(), args...); err != nil {
+			return MapError(err)
+		}
+		rhs = rhs[n:]
+	}
+	return nil
+}
+
+// GetRecordIDsByHash implements storage.RecordHashes
+func (s *Storage) GetRecordIDsByHash(ctx context.Context, logID, hash string) ([]string, error) {
+	if len(logID) == 0 {
+		return nil, fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
+	}
+	rows, err := s.db.QueryxContext(ctx, "select record_id from record_hash where log_id = $1 and hash = $2 order by record_id", logID, hash)
+	if err != nil {
+		return nil, MapError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	ids, err := scanRows[string](rows)
+	if err != nil {
+		return nil, MapError(err)
+	}
+	return ids, nil
+}
+
+// DeleteRecordHashes implements storage.RecordHashes
+func (s *Storage) DeleteRecordHashes(ctx context.Context, logID, fromID, toID string) error {
+	if len(logID) == 0 {
+		return fmt.Errorf("log ID must be specified: %w", errors.ErrInvalid)
+	}
+	query := "delete from record_hash where log_id = $1"
+	args := []any{logID}
+	if fromID != "" {
+		args = append(args, fromID)
+		query += fmt.Sprintf(" and record_id >= $%d", len(args))
+	}
+	if toID != "" {
+		args = append(args, toID)
+		query += fmt.Sprintf(" and record_id <= $%d", len(args))
+	}
+	_, err := s.db.ExecContext(ctx, query, args...)
+	return MapError(err)
+}
+
 // ===================================== helpers =====================================
 
 func scan[T any](rows *sqlx.Rows) (T, error) {
@@ -2,22 +2,34 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"github.com/oklog/ulid/v2"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/golibs/errors"
+	"github.com/solarisdb/solaris/golibs/ulidutils"
+	"github.com/solarisdb/solaris/pkg/db"
 	"github.com/solarisdb/solaris/pkg/storage"
 	"github.com/solarisdb/solaris/pkg/storage/logfs"
+	"github.com/solarisdb/solaris/pkg/storage/logfs/logfstest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"maps"
+	"slices"
 	"testing"
+	"time"
 )
 
 type testSuite struct {
-	pgTestSuite
+	dbTestSuite
 }
 
 func TestRunTestSuite(t *testing.T) {
-	suite.Run(t, &testSuite{pgTestSuite{}})
+	suite.Run(t, &testSuite{dbTestSuite{driver: db.DriverPostgres}})
+}
+
+func TestRunSqliteTestSuite(t *testing.T) {
+	suite.Run(t, &testSuite{dbTestSuite{driver: db.DriverSqlite}})
 }
 
 func (ts *testSuite) Test_CreateLog() {
@@ -46,9 +58,25 @@ func (ts *testSuite) Test_UpdateLog() {
 	assert.True(ts.T(), maps.Equal(log2.Tags, log1.Tags))
 
 	log1.Tags["tag5"] = "val5"
+	log1.ValidateUTF8 = true
+	log1.PayloadTypeURL = "type.googleapis.com/google.protobuf.Timestamp"
+	log1.MaxRecords = 100
+	log1.RetentionMaxAge = durationpb.New(time.Hour)
+	log1.RetentionMaxRecords = 1000
 	log2, err = s.UpdateLog(ctx, log1)
 	assert.Nil(ts.T(), err)
 	assert.True(ts.T(), maps.Equal(log2.Tags, log1.Tags))
+	assert.True(ts.T(), log2.ValidateUTF8)
+	assert.Equal(ts.T(), log1.PayloadTypeURL, log2.PayloadTypeURL)
+	assert.Equal(ts.T(), log1.MaxRecords, log2.MaxRecords)
+
+	log2, err = s.GetLogByID(ctx, log1.ID)
+	assert.Nil(ts.T(), err)
+	assert.True(ts.T(), log2.ValidateUTF8)
+	assert.Equal(ts.T(), log1.PayloadTypeURL, log2.PayloadTypeURL)
+	assert.Equal(ts.T(), log1.MaxRecords, log2.MaxRecords)
+	assert.Equal(ts.T(), time.Hour, log2.RetentionMaxAge.AsDuration())
+	assert.Equal(ts.T(), log1.RetentionMaxRecords, log2.RetentionMaxRecords)
 }
 
 func (ts *testSuite) Test_GetLogByID() {
@@ -98,7 +126,7 @@ func (ts *testSuite) Test_QueryLogsByCondition() {
 	assert.Nil(ts.T(), err)
 	assert.Equal(ts.T(), 2, len(qr.Logs))
 	assert.Equal(ts.T(), int64(3), qr.Total)
-	assert.Equal(ts.T(), qr.NextPageID, log3.ID)
+	assert.Equal(ts.T(), qr.NextPageID, log2.ID)
 }
 
 func (ts *testSuite) Test_QueryLogsByIDs() {
@@ -121,7 +149,81 @@ func (ts *testSuite) Test_QueryLogsByIDs() {
 	assert.Nil(ts.T(), err)
 	assert.Equal(ts.T(), 2, len(qr.Logs))
 	assert.Equal(ts.T(), int64(3), qr.Total)
-	assert.Equal(ts.T(), qr.NextPageID, log3.ID)
+	assert.Equal(ts.T(), qr.NextPageID, log2.ID)
+}
+
+func (ts *testSuite) Test_QueryLogsCreatedInWindow() {
+	ctx := context.Background()
+	s := NewStorage(ts.db)
+
+	var logs []*solaris.Log
+	for i := 0; i < 5; i++ {
+		log, err := s.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"tag": "val"}})
+		assert.Nil(ts.T(), err)
+		logs = append(logs, log)
+		time.Sleep(2 * time.Millisecond)
+	}
+	after := ulid.Time(ulid.MustParse(logs[1].ID).Time())
+	before := ulid.Time(ulid.MustParse(logs[3].ID).Time())
+
+	qr, err := s.QueryLogs(ctx, storage.QueryLogsRequest{Condition: "tag('tag') = 'val'", CreatedAfter: after, CreatedBefore: before, Limit: 2})
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), 2, len(qr.Logs))
+	assert.Equal(ts.T(), int64(3), qr.Total)
+	assert.Equal(ts.T(), logs[1].ID, qr.Logs[0].ID)
+	assert.Equal(ts.T(), logs[2].ID, qr.Logs[1].ID)
+	assert.Equal(ts.T(), logs[2].ID, qr.NextPageID)
+
+	qr, err = s.QueryLogs(ctx, storage.QueryLogsRequest{Condition: "tag('tag') = 'val'", CreatedAfter: after, CreatedBefore: before, Page: qr.NextPageID, Limit: 2})
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), 1, len(qr.Logs))
+	assert.Equal(ts.T(), logs[3].ID, qr.Logs[0].ID)
+	assert.Empty(ts.T(), qr.NextPageID)
+
+	ids := []string{logs[0].ID, logs[1].ID, logs[2].ID, logs[3].ID, logs[4].ID}
+	qr, err = s.QueryLogs(ctx, storage.QueryLogsRequest{IDs: ids, CreatedAfter: after, Limit: 10})
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), 4, len(qr.Logs))
+	assert.Equal(ts.T(), logs[1].ID, qr.Logs[0].ID)
+}
+
+func (ts *testSuite) Test_QueryLogsPagesStable() {
+	ctx := context.Background()
+	s := NewStorage(ts.db)
+
+	var logs []*solaris.Log
+	for i := 0; i < 5; i++ {
+		log, err := s.CreateLog(ctx, &solaris.Log{Tags: map[string]string{"pages": "val"}})
+		assert.Nil(ts.T(), err)
+		logs = append(logs, log)
+	}
+
+	var read []string
+	page := ""
+	for i := 0; ; i++ {
+		qr, err := s.QueryLogs(ctx, storage.QueryLogsRequest{Condition: "tag('pages') = 'val'", Page: page, Limit: 2})
+		assert.Nil(ts.T(), err)
+		for _, l := range qr.Logs {
+			read = append(read, l.ID)
+		}
+		if i == 0 {
+			// the logs created between the pages, including the one right after the last returned
+			created, err := s.CreateLogs(ctx, []*solaris.Log{{ID: ulidutils.NextID(qr.NextPageID), Tags: map[string]string{"pages": "val"}},
+				{Tags: map[string]string{"pages": "val"}}})
+			assert.Nil(ts.T(), err)
+			logs = append(logs, created...)
+		}
+		if qr.NextPageID == "" {
+			break
+		}
+		page = qr.NextPageID
+	}
+	var expected []string
+	for _, l := range logs {
+		expected = append(expected, l.ID)
+	}
+	slices.Sort(expected)
+	assert.Equal(ts.T(), expected, read)
 }
 
 func (ts *testSuite) Test_DeleteLogsByCondition() {
@@ -173,6 +275,29 @@ func (ts *testSuite) Test_DeleteLogsByConditionMarkOnly() {
 	assert.Equal(ts.T(), 0, len(dr.DeletedIDs))
 }
 
+func (ts *testSuite) Test_CreateLogs() {
+	ctx := context.Background()
+	s := NewStorage(ts.db)
+
+	id := ulidutils.NewID()
+	logs, err := s.CreateLogs(ctx, []*solaris.Log{{ID: id, Tags: map[string]string{"a": "b"}}, {Tags: map[string]string{"c": "d"}}})
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), 2, len(logs))
+	assert.Equal(ts.T(), id, logs[0].ID)
+	for _, l := range logs {
+		l1, err := s.GetLogByID(ctx, l.ID)
+		assert.Nil(ts.T(), err)
+		assert.Equal(ts.T(), l.Tags, l1.Tags)
+	}
+
+	// nothing is created if an ID exists already
+	id2 := ulidutils.NewID()
+	_, err = s.CreateLogs(ctx, []*solaris.Log{{ID: id2}, {ID: id}})
+	assert.True(ts.T(), errors.Is(err, errors.ErrExist))
+	_, err = s.GetLogByID(ctx, id2)
+	assert.True(ts.T(), errors.Is(err, errors.ErrNotExist))
+}
+
 func (ts *testSuite) Test_DeleteLogsByIDs() {
 	ctx := context.Background()
 	s := NewStorage(ts.db)
@@ -290,13 +415,148 @@ func (ts *testSuite) Test_UpsertChunkInfos() {
 	assert.Nil(ts.T(), err)
 	assert.Equal(ts.T(), len(cis1), len(cis2))
 
-	cis3 := []logfs.ChunkInfo{{ID: "2"}, {ID: "1"}, {ID: "3"}}
+	cis3 := []logfs.ChunkInfo{{ID: "2"}, {ID: "1"}, {ID: "3", FirstSeq: 7}}
 	err = s.UpsertChunkInfos(ctx, log.ID, cis3)
 	assert.Nil(ts.T(), err)
 
 	cis4, err := s.GetChunks(ctx, log.ID)
 	assert.Nil(ts.T(), err)
 	assert.Equal(ts.T(), len(cis3), len(cis4))
+	assert.Equal(ts.T(), int64(7), cis4[2].FirstSeq)
+}
+
+func (ts *testSuite) Test_DeleteChunkInfos() {
+	ctx := context.Background()
+	s := NewStorage(ts.db)
+
+	log := &solaris.Log{}
+	log, err := s.CreateLog(ctx, log)
+	assert.Nil(ts.T(), err)
+
+	err = s.UpsertChunkInfos(ctx, log.ID, []logfs.ChunkInfo{{ID: "1"}, {ID: "2"}, {ID: "3"}})
+	assert.Nil(ts.T(), err)
+
+	err = s.DeleteChunkInfos(ctx, log.ID, []string{"1", "3", "4"})
+	assert.Nil(ts.T(), err)
+
+	cis, err := s.GetChunks(ctx, log.ID)
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), 1, len(cis))
+	assert.Equal(ts.T(), "2", cis[0].ID)
+}
+
+func (ts *testSuite) Test_LastAppendKey() {
+	ctx := context.Background()
+	s := NewStorage(ts.db)
+
+	log, err := s.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(ts.T(), err)
+
+	_, err = s.GetLastAppendKey(ctx, log.ID)
+	assert.True(ts.T(), errors.Is(err, errors.ErrNotExist))
+
+	ak := logfs.AppendKey{Key: "k1", Added: 2, BytesWritten: 10, StartID: "s1", LastID: "l1"}
+	assert.Nil(ts.T(), s.SetLastAppendKey(ctx, log.ID, ak))
+	ak2, err := s.GetLastAppendKey(ctx, log.ID)
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), ak, ak2)
+
+	ak.Key = "k2"
+	assert.Nil(ts.T(), s.SetLastAppendKey(ctx, log.ID, ak))
+	ak2, err = s.GetLastAppendKey(ctx, log.ID)
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), ak, ak2)
+
+	_, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log.ID}})
+	assert.Nil(ts.T(), err)
+	_, err = s.GetLastAppendKey(ctx, log.ID)
+	assert.True(ts.T(), errors.Is(err, errors.ErrNotExist))
+}
+
+func (ts *testSuite) Test_LogKeyID() {
+	ctx := context.Background()
+	s := NewStorage(ts.db)
+
+	log, err := s.CreateLog(ctx, &solaris.Log{})
+	assert.Nil(ts.T(), err)
+
+	_, err = s.GetLogKeyID(ctx, log.ID)
+	assert.True(ts.T(), errors.Is(err, errors.ErrNotExist))
+
+	assert.Nil(ts.T(), s.SetLogKeyID(ctx, log.ID, "k1"))
+	keyID, err := s.GetLogKeyID(ctx, log.ID)
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), "k1", keyID)
+
+	assert.Nil(ts.T(), s.SetLogKeyID(ctx, log.ID, "k2"))
+	keyID, err = s.GetLogKeyID(ctx, log.ID)
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), "k2", keyID)
+
+	_, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log.ID}})
+	assert.Nil(ts.T(), err)
+	_, err = s.GetLogKeyID(ctx, log.ID)
+	assert.True(ts.T(), errors.Is(err, errors.ErrNotExist))
+}
+
+func (ts *testSuite) Test_RecordHashes() {
+	ctx := context.Background()
+	s := NewStorage(ts.db)
+
+	log, err := s.CreateLog(ctx, &solaris.Log{PayloadHash: "fnv64a"})
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), "fnv64a", log.PayloadHash)
+
+	ids, err := s.GetRecordIDsByHash(ctx, log.ID, "h1")
+	assert.Nil(ts.T(), err)
+	assert.Empty(ts.T(), ids)
+
+	// the same hash of several records (duplicates or collisions) returns all of them
+	assert.Nil(ts.T(), s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h1", RecordID: "r3"},
+		{Hash: "h2", RecordID: "r2"}, {Hash: "h1", RecordID: "r1"}}))
+	assert.Nil(ts.T(), s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h1", RecordID: "r1"}}))
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h1")
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), []string{"r1", "r3"}, ids)
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h2")
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), []string{"r2"}, ids)
+
+	err = s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h1"}})
+	assert.True(ts.T(), errors.Is(err, errors.ErrInvalid))
+
+	// the removed records are deleted from the index by the IDs range
+	assert.Nil(ts.T(), s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h2", RecordID: "r4"}}))
+	assert.Nil(ts.T(), s.DeleteRecordHashes(ctx, log.ID, "r2", "r3"))
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h1")
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), []string{"r1"}, ids)
+	assert.Nil(ts.T(), s.DeleteRecordHashes(ctx, log.ID, "", "r1"))
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h2")
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), []string{"r4"}, ids)
+
+	// the hashes exceeding the statement parameters limit are inserted by batches
+	rhs := make([]storage.RecordHash, 40000)
+	for i := range rhs {
+		rhs[i] = storage.RecordHash{Hash: "h3", RecordID: fmt.Sprintf("r%05d", i)}
+	}
+	assert.Nil(ts.T(), s.AddRecordHashes(ctx, log.ID, rhs))
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h3")
+	assert.Nil(ts.T(), err)
+	assert.Equal(ts.T(), len(rhs), len(ids))
+	assert.Nil(ts.T(), s.AddRecordHashes(ctx, log.ID, []storage.RecordHash{{Hash: "h1", RecordID: "r1"}}))
+
+	// the index is deleted with the log
+	_, err = s.DeleteLogs(ctx, storage.DeleteLogsRequest{IDs: []string{log.ID}})
+	assert.Nil(ts.T(), err)
+	ids, err = s.GetRecordIDsByHash(ctx, log.ID, "h1")
+	assert.Nil(ts.T(), err)
+	assert.Empty(ts.T(), ids)
+}
+
+func (ts *testSuite) Test_Conformance() {
+	logfstest.RunLogsMetaStorageConformance(ts.T(), func() logfs.LogsMetaStorage { return NewStorage(ts.db) })
 }
 
 func (ts *testSuite) Test_DeleteLogChunks() {
@@ -21,18 +21,24 @@ import (
 	"github.com/solarisdb/solaris/pkg/db"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"path/filepath"
 	"time"
 )
 
 type (
-	pgTestSuite struct {
+	// dbTestSuite runs the tests against the new database of the driver for every test
+	dbTestSuite struct {
 		suite.Suite
+		driver string
 		dbCont DbContainer
 		db     *Db
 	}
 )
 
-func (ts *pgTestSuite) SetupSuite() {
+func (ts *dbTestSuite) SetupSuite() {
+	if ts.driver == db.DriverSqlite {
+		return
+	}
 	ctx, cancelFn := context.WithTimeout(context.Background(), time.Minute)
 	defer cancelFn()
 	dbCont, err := NewPgDbContainer(ctx, "postgres:16-alpine", WithDbName("solaris_test"))
@@ -52,7 +58,7 @@ func (ts *pgTestSuite) SetupSuite() {
 	ts.dbCont = dbCont
 }
 
-func (ts *pgTestSuite) TearDownSuite() {
+func (ts *dbTestSuite) TearDownSuite() {
 	if ts.db != nil {
 		_ = ts.db.Close()
 	}
@@ -61,27 +67,32 @@ func (ts *pgTestSuite) TearDownSuite() {
 	}
 }
 
-func (ts *pgTestSuite) BeforeTest(suiteName, testName string) {
+func (ts *dbTestSuite) BeforeTest(suiteName, testName string) {
 	ctx, cancelFn := context.WithTimeout(context.Background(), time.Minute)
 	defer cancelFn()
 
-	dbCfg := ts.dbCont.DbConfig()
-	assert.Nil(ts.T(), ts.dropCreatePgDb(ctx))
+	var dbConn *db.DBConn
+	if ts.driver == db.DriverSqlite {
+		dbConn = &db.DBConn{Driver: db.DriverSqlite, DBName: filepath.Join(ts.T().TempDir(), "solaris_test.db")}
+	} else {
+		assert.Nil(ts.T(), ts.dropCreatePgDb(ctx))
+		dbConn = toDbConn(ts.dbCont.DbConfig())
+	}
 
 	var err error
-	ts.db, err = GetDb(ctx, toDbConn(dbCfg))
+	ts.db, err = GetDb(ctx, dbConn)
 	assert.Nil(ts.T(), err)
 	assert.Nil(ts.T(), ts.db.Init(ctx))
 }
 
-func (ts *pgTestSuite) AfterTest(suiteName, testName string) {
+func (ts *dbTestSuite) AfterTest(suiteName, testName string) {
 	if ts.db != nil {
 		ts.db.Shutdown()
 		ts.db = nil
 	}
 }
 
-func (ts *pgTestSuite) dropCreatePgDb(ctx context.Context) error {
+func (ts *dbTestSuite) dropCreatePgDb(ctx context.Context) error {
 	dbCfg := ts.dbCont.DbConfig()
 	dbConn, err := sqlx.ConnectContext(ctx, "postgres", dbCfg.DataSourceNoDb())
 	if err != nil {
@@ -101,7 +112,7 @@ func (ts *pgTestSuite) dropCreatePgDb(ctx context.Context) error {
 
 func toDbConn(cfg DbConfig) *db.DBConn {
 	return &db.DBConn{
-		Driver:   "postgres",
+		Driver:   db.DriverPostgres,
 		DBName:   cfg.DbName,
 		Host:     cfg.Host,
 		Port:     cfg.Port,
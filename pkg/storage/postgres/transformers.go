@@ -18,13 +18,23 @@ import (
 	"github.com/oklog/ulid/v2"
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
 	"github.com/solarisdb/solaris/pkg/storage/logfs"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
 )
 
 func logToModel(l *solaris.Log) Log {
 	ml := Log{
-		ID:   l.ID,
-		Tags: l.Tags,
+		ID:                  l.ID,
+		Tags:                l.Tags,
+		ValidateUTF8:        l.ValidateUTF8,
+		PayloadTypeURL:      l.PayloadTypeURL,
+		MaxRecords:          l.MaxRecords,
+		PayloadHash:         l.PayloadHash,
+		RetentionMaxAgeMs:   l.RetentionMaxAge.AsDuration().Milliseconds(),
+		RetentionMaxRecords: l.RetentionMaxRecords,
+		Owner:               l.Owner,
+		Grants:              l.Grants,
 	}
 	if l.CreatedAt != nil {
 		ml.CreatedAt = l.CreatedAt.AsTime()
@@ -37,13 +47,28 @@ func logToModel(l *solaris.Log) Log {
 
 func logToAPI(l Log) *solaris.Log {
 	return &solaris.Log{
-		ID:        l.ID,
-		Tags:      l.Tags,
-		CreatedAt: timestamppb.New(l.CreatedAt),
-		UpdatedAt: timestamppb.New(l.UpdatedAt),
+		ID:                  l.ID,
+		Tags:                l.Tags,
+		CreatedAt:           timestamppb.New(l.CreatedAt),
+		UpdatedAt:           timestamppb.New(l.UpdatedAt),
+		ValidateUTF8:        l.ValidateUTF8,
+		PayloadTypeURL:      l.PayloadTypeURL,
+		MaxRecords:          l.MaxRecords,
+		PayloadHash:         l.PayloadHash,
+		RetentionMaxAge:     retentionMaxAgeToAPI(l.RetentionMaxAgeMs),
+		RetentionMaxRecords: l.RetentionMaxRecords,
+		Owner:               l.Owner,
+		Grants:              l.Grants,
 	}
 }
 
+func retentionMaxAgeToAPI(ms int64) *durationpb.Duration {
+	if ms == 0 {
+		return nil
+	}
+	return durationpb.New(time.Duration(ms) * time.Millisecond)
+}
+
 func logsToAPI(ll []Log) []*solaris.Log {
 	var all []*solaris.Log
 	for _, l := range ll {
@@ -58,6 +83,8 @@ func chunkToModel(c *logfs.ChunkInfo) Chunk {
 		Min:          c.Min.String(),
 		Max:          c.Max.String(),
 		RecordsCount: c.RecordsCount,
+		KeyID:        c.KeyID,
+		FirstSeq:     c.FirstSeq,
 	}
 }
 
@@ -69,6 +96,8 @@ func chunkToInfo(c Chunk) logfs.ChunkInfo {
 		Min:          minVal,
 		Max:          maxVal,
 		RecordsCount: c.RecordsCount,
+		KeyID:        c.KeyID,
+		FirstSeq:     c.FirstSeq,
 	}
 }
 
@@ -79,3 +108,13 @@ func chunksToInfo(cc []Chunk) []logfs.ChunkInfo {
 	}
 	return cis
 }
+
+func appendKeyToInfo(ak AppendKey) logfs.AppendKey {
+	return logfs.AppendKey{
+		Key:          ak.Key,
+		Added:        ak.Added,
+		BytesWritten: ak.BytesWritten,
+		StartID:      ak.StartID,
+		LastID:       ak.LastID,
+	}
+}
@@ -16,8 +16,11 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/solarisdb/solaris/api/gen/solaris/v1"
+	"github.com/solarisdb/solaris/golibs/ulidutils"
+	"github.com/solarisdb/solaris/pkg/ql"
 )
 
 type (
@@ -25,6 +28,10 @@ type (
 	Logs interface {
 		// CreateLog creates a new log and returns its descriptor with the new ID
 		CreateLog(ctx context.Context, log *solaris.Log) (*solaris.Log, error)
+		// CreateLogs creates the logs in one transaction, so either all the logs are created or none of them.
+		// Unlike CreateLog, the IDs specified are kept, the empty ones are generated. It returns
+		// the errors.ErrExist if a log with the same ID exists already or the ID is repeated in the list.
+		CreateLogs(ctx context.Context, logs []*solaris.Log) ([]*solaris.Log, error)
 		// GetLogByID returns Log by its ID. It returns the errors.ErrNotExist if the log is marked for delete,
 		// or it doesn't exist
 		GetLogByID(ctx context.Context, id string) (*solaris.Log, error)
@@ -43,14 +50,22 @@ type (
 		IDs []string
 		// Deleted search between deleted
 		Deleted bool
-		Page    string
-		Limit   int64
+		// Page is the ID of the last log of the previous page (see QueryLogsResult.NextPageID), the logs are
+		// selected after it. The logs are ordered by their IDs, so the logs created while the pages are read
+		// don't shift the pages, and the logs are neither skipped nor repeated.
+		Page  string
+		Limit int64
+		// CreatedAfter and CreatedBefore define the window (inclusive) the selected logs were created in.
+		// The log IDs are ULIDs, so the window is translated into the log IDs range. Zero value means no limit.
+		CreatedAfter  time.Time
+		CreatedBefore time.Time
 	}
 
 	// DeleteLogsRequest specifies the DeleteLogs parameters
 	DeleteLogsRequest struct {
 		Condition string
-		// IDs is the list of Log IDs should be deleted.
+		// IDs is the list of Log IDs should be deleted, the IDs of the logs, which
+		// don't exist or are already deleted, are not returned in the result.
 		IDs []string
 		// MarkOnly allows not to delete the records physically, but mark it for deletion
 		MarkOnly bool
@@ -64,20 +79,158 @@ type (
 		// that more records potentially available for the read
 		QueryRecords(ctx context.Context, request QueryRecordsRequest) ([]*solaris.Record, bool, error)
 		// CountRecords count total number for records in the log and number of records after (before)
-		// specified record ID which match the request condition. Returned values are (total, count, error).
-		CountRecords(ctx context.Context, request QueryRecordsRequest) (uint64, uint64, error)
+		// specified record ID which match the request condition. Returned values are (total, count, exact, error),
+		// the exact is false if the count is estimated because the request CountDeadline is reached.
+		CountRecords(ctx context.Context, request QueryRecordsRequest) (uint64, uint64, bool, error)
+		// TruncateRecords removes the log records created before the time provided. The records are removed by
+		// whole chunks, so some records created before the time may stay in the log. The function returns the
+		// number of records removed.
+		TruncateRecords(ctx context.Context, logID string, before time.Time) (int64, error)
+		// TrimRecords removes the oldest log records, so the log keeps the last maxRecords records only. The records
+		// are removed by whole chunks first, the rest of them are removed only if there are more than slack of them,
+		// so the log may keep up to maxRecords+slack records. The function returns the number of records removed.
+		TrimRecords(ctx context.Context, logID string, maxRecords, slack int64) (int64, error)
+		// DeleteRecords removes the log records with IDs in the inclusive range [fromID, toID], an empty ID means
+		// the range is not limited from the corresponding side. The last record of the log with numbered records
+		// (see solaris.Record.Seq) is never removed, cause the log sequence is continued from it, so the range
+		// including it is deleted except that record. The function returns the number of records removed,
+		// so it returns 0 if the range is already deleted.
+		DeleteRecords(ctx context.Context, logID, fromID, toID string) (int64, error)
+		// GetRecordByID returns the log record by its ID. It returns errors.ErrNotExist if there is no such record
+		GetRecordByID(ctx context.Context, logID, recordID string) (*solaris.Record, error)
+		// OpenRecordIterator returns the RecordIterator over the records selected by the request. The records are
+		// returned in the same order as QueryRecords returns them, but the request Limit is not bounded by the
+		// storage, and zero Limit means no limit. The iterator resources are released by its Close, or as soon
+		// as the ctx is closed, so the iterator stops returning the records after that.
+		OpenRecordIterator(ctx context.Context, request QueryRecordsRequest) (RecordIterator, error)
+	}
+
+	// RecordIterator allows to read the records selected by a query one by one, so the records of a large
+	// query are not kept in memory all together. The iterator must be closed when it is not needed anymore.
+	RecordIterator interface {
+		// HasNext returns true if the iterator has the next record or the error to be returned by Next
+		HasNext() bool
+		// Next returns the next record. The iterator is over after the error is returned.
+		Next() (*solaris.Record, error)
+		// Close releases the iterator resources, the iterator returns no records after that
+		Close()
+	}
+
+	// RecordHashes provides an interface to manage the index of the log records by their payloads hashes
+	RecordHashes interface {
+		// AddRecordHashes adds the records to the log index by their payloads hashes
+		AddRecordHashes(ctx context.Context, logID string, rhs []RecordHash) error
+		// GetRecordIDsByHash returns the IDs of the log records with the payload hash in ascending order.
+		// The records removed from the log may be still returned, so the IDs should be checked.
+		GetRecordIDsByHash(ctx context.Context, logID, hash string) ([]string, error)
+		// DeleteRecordHashes removes the log records with IDs in the inclusive range [fromID, toID] from the
+		// index, an empty ID means the range is not limited from the corresponding side
+		DeleteRecordHashes(ctx context.Context, logID, fromID, toID string) error
+	}
+
+	// LogsActivity provides an interface to check whether the logs have records in a time window
+	LogsActivity interface {
+		// HasRecords returns true if the log has at least one record created in the window (inclusive) defined by
+		// after and before, a zero value means the window is not limited from the corresponding side. The log parts
+		// out of the window are skipped by their metadata, so the records are read only if it could not be decided.
+		HasRecords(ctx context.Context, logID string, after, before time.Time) (bool, error)
+	}
+
+	// LogKeys provides an interface to manage the keys the log records are encrypted with
+	LogKeys interface {
+		// RotateLogKey makes a new key for the log records encryption and returns its ID. The new chunks of the log
+		// are encrypted with the new key, the existing chunks are still read with the keys they were written with.
+		RotateLogKey(ctx context.Context, logID string) (string, error)
+	}
+
+	// RecordHash is the entry of the records index by the payloads hashes
+	RecordHash struct {
+		// Hash is the record payload hash
+		Hash string
+		// RecordID is the record ID
+		RecordID string
 	}
 
 	QueryRecordsRequest struct {
 		// Condition defines the filtering constrains
 		Condition string
+		// Expr is the parsed Condition. If it is not nil, the Condition is not parsed again
+		Expr *ql.Expression
 		// LogID where records should be read
 		LogID string
 		// descending specifies that the result should be sorted in the descending order
 		Descending bool
 		// StartID provides the first record ID it can be read (inclusive)
 		StartID string
+		// StartExclusive specifies that the StartID record itself is not read, so the next page could be
+		// requested by the last record ID of the previous one in both directions. It is not applied to the StartSeq.
+		StartExclusive bool
+		// StartSeq provides the first record sequence number it can be read (inclusive). If it is
+		// not zero, the StartID is disregarded
+		StartSeq int64
 		// limit contains the number of records to be returned
 		Limit int64
+		// Offset is the number of the records matching the request, which are skipped before the returned ones.
+		// The records are counted from the StartID in the reading direction.
+		Offset int64
+		// PayloadLen allows to select the records by their payload length
+		PayloadLen PayloadLenRange
+		// CreatedAfter and CreatedBefore define the window (inclusive) the selected records were created in.
+		// The window works in both directions together with the StartID, so the pages are read within the
+		// window only. Zero value means no limit.
+		CreatedAfter  time.Time
+		CreatedBefore time.Time
+		// SkipCorrupted specifies that the records stored after a corrupted one in the same part of the log are
+		// skipped, and the records read before it are returned, instead of failing the request with the
+		// errors.ErrCorrupted. The more result flag is not affected, so the next page starts after the last
+		// returned record and skips the corrupted records again.
+		SkipCorrupted bool
+		// CountDeadline limits the time CountRecords spends on scanning the log parts the records are stored in.
+		// When the deadline is reached, the parts left are not scanned, but their counts are estimated, so
+		// the approximate count is returned. Zero value means no limit.
+		CountDeadline time.Time
+	}
+
+	// PayloadLenRange defines the closed range of the record payload length in bytes.
+	// Zero Min or Max value means the range is not limited from the corresponding side.
+	PayloadLenRange struct {
+		Min int64
+		Max int64
 	}
 )
+
+// HasWindow returns true if the records window is limited at least from one side
+func (qr QueryRecordsRequest) HasWindow() bool {
+	return !qr.CreatedAfter.IsZero() || !qr.CreatedBefore.IsZero()
+}
+
+// InWindow returns true if the record ID could be created in the request window.
+func (qr QueryRecordsRequest) InWindow(id string) bool {
+	if !qr.CreatedAfter.IsZero() && id < ulidutils.MinIDAt(qr.CreatedAfter) {
+		return false
+	}
+	return qr.CreatedBefore.IsZero() || id <= ulidutils.MaxIDAt(qr.CreatedBefore)
+}
+
+// IDRange returns the inclusive range of the log IDs, which could be created in the request window.
+// An empty value means the range is not limited from the corresponding side.
+func (qr QueryLogsRequest) IDRange() (string, string) {
+	var from, to string
+	if !qr.CreatedAfter.IsZero() {
+		from = ulidutils.MinIDAt(qr.CreatedAfter)
+	}
+	if !qr.CreatedBefore.IsZero() {
+		to = ulidutils.MaxIDAt(qr.CreatedBefore)
+	}
+	return from, to
+}
+
+// IsAny returns true if the range matches a payload of any length
+func (pr PayloadLenRange) IsAny() bool {
+	return pr.Min <= 0 && pr.Max <= 0
+}
+
+// Contains returns true if the payload length n is in the range
+func (pr PayloadLenRange) Contains(n int) bool {
+	return int64(n) >= pr.Min && (pr.Max <= 0 || int64(n) <= pr.Max)
+}